@@ -5,28 +5,47 @@ import (
 	"fmt"
 	"os"
 	"os/signal"
+	"sync"
 	"syscall"
 	"time"
 
-	"github.com/maypok86/payment-api/internal/cache"
 	"github.com/maypok86/payment-api/internal/config"
 	"github.com/maypok86/payment-api/internal/domain"
+	"github.com/maypok86/payment-api/internal/domain/account"
+	"github.com/maypok86/payment-api/internal/domain/outbox"
+	grpchandler "github.com/maypok86/payment-api/internal/handler/grpc"
 	httphandler "github.com/maypok86/payment-api/internal/handler/http"
+	"github.com/maypok86/payment-api/internal/pkg/grpcserver"
 	"github.com/maypok86/payment-api/internal/pkg/postgres"
 	"github.com/maypok86/payment-api/internal/pkg/server"
+	"github.com/maypok86/payment-api/internal/publisher"
+	"github.com/maypok86/payment-api/internal/rate"
 	"github.com/maypok86/payment-api/internal/repository/psql"
+	"github.com/maypok86/payment-api/internal/storage"
+	"github.com/maypok86/payment-api/internal/worker"
 	"go.uber.org/zap"
 )
 
 type App struct {
-	logger     *zap.Logger
-	db         *postgres.Client
-	httpServer *server.Server
+	logger          *zap.Logger
+	db              *postgres.Client
+	httpServer      *server.Server
+	grpcServer      *grpcserver.Server
+	orderExpiry     *worker.OrderExpiry
+	outboxRelay     *worker.OutboxRelay
+	webhookDelivery *worker.WebhookDelivery
+	reportJobs      *worker.ReportJobs
+	reportRetention *worker.ReportRetention
 }
 
 func New(ctx context.Context, logger *zap.Logger) (*App, error) {
 	cfg := config.Get()
 
+	txIsoLevel, err := postgres.ParseIsoLevel(cfg.Postgres.TxIsoLevel)
+	if err != nil {
+		return nil, fmt.Errorf("parse postgres config: %w", err)
+	}
+
 	db, err := postgres.NewClient(
 		ctx,
 		postgres.NewConnectionConfig(
@@ -38,15 +57,40 @@ func New(ctx context.Context, logger *zap.Logger) (*App, error) {
 			cfg.Postgres.SSLMode,
 		),
 		postgres.WithMaxPoolSize(cfg.Postgres.MaxPoolSize),
+		postgres.WithTxIsoLevel(txIsoLevel),
+		postgres.WithTxAttempts(cfg.Postgres.TxAttempts),
+		postgres.WithTxBackoff(cfg.Postgres.TxBackoff, cfg.Postgres.TxMaxBackoff),
 	)
 	if err != nil {
 		return nil, fmt.Errorf("connect to postgres: %w", err)
 	}
 
+	rateProvider, err := newRateProvider(cfg.Rate)
+	if err != nil {
+		return nil, fmt.Errorf("create rate provider: %w", err)
+	}
+
+	reportStorage, err := newReportStorage(ctx, cfg.Report)
+	if err != nil {
+		return nil, fmt.Errorf("create report storage: %w", err)
+	}
+
 	postgresTransactor := postgres.NewTransactor(db)
-	reportCache := cache.NewReportCache()
 	repositories := psql.NewRepositories(db, logger)
-	services := domain.NewServices(postgresTransactor, repositories, reportCache, logger)
+	services := domain.NewServices(
+		postgresTransactor,
+		repositories,
+		reportStorage,
+		cfg.Report.JobTimeout,
+		cfg.Report.SnapshotGrace,
+		cfg.Order.ReservationTTL,
+		cfg.Outbox.ClaimTimeout,
+		cfg.Webhook.ClaimTimeout,
+		rateProvider,
+		newPublisher(cfg.Outbox, logger),
+		publisher.NewHTTPSender(cfg.Webhook.Timeout),
+		logger,
+	)
 
 	router := httphandler.NewRouter(services, logger)
 
@@ -61,15 +105,133 @@ func New(ctx context.Context, logger *zap.Logger) (*App, error) {
 			server.WithReadTimeout(cfg.HTTP.ReadTimeout),
 			server.WithWriteTimeout(cfg.HTTP.WriteTimeout),
 		),
+		grpcServer: grpcserver.New(
+			grpchandler.NewServer(services, logger),
+			grpcserver.WithHost(cfg.GRPC.Host),
+			grpcserver.WithPort(cfg.GRPC.Port),
+		),
+		orderExpiry: worker.NewOrderExpiry(
+			services.Order,
+			logger,
+			worker.WithInterval(cfg.Order.ExpiryInterval),
+			worker.WithBatchSize(cfg.Order.ExpiryBatchSize),
+		),
+		outboxRelay: worker.NewOutboxRelay(
+			services.Outbox,
+			logger,
+			worker.WithInterval(cfg.Outbox.RelayInterval),
+			worker.WithBatchSize(cfg.Outbox.RelayBatchSize),
+		),
+		webhookDelivery: worker.NewWebhookDelivery(
+			services.Webhook,
+			logger,
+			worker.WithInterval(cfg.Webhook.DeliveryInterval),
+			worker.WithBatchSize(cfg.Webhook.DeliveryBatchSize),
+		),
+		// every goroutine of the pool runs one report at a time.
+		reportJobs: worker.NewReportJobs(
+			services.Report,
+			logger,
+			worker.WithInterval(cfg.Report.JobInterval),
+			worker.WithBatchSize(1),
+			worker.WithConcurrency(cfg.Report.JobWorkers),
+		),
+		reportRetention: worker.NewReportRetention(
+			reportStorage,
+			logger,
+			worker.WithInterval(cfg.Report.RetentionInterval),
+		),
 	}, nil
 }
 
+func newReportStorage(ctx context.Context, cfg config.Report) (storage.ReportStorage, error) {
+	switch cfg.Storage {
+	case "file":
+		return storage.NewFileStorage(cfg.StorageDir, cfg.Retention)
+	case "s3":
+		return storage.NewS3Storage(ctx, storage.S3Config{
+			Endpoint:  cfg.S3Endpoint,
+			Region:    cfg.S3Region,
+			Bucket:    cfg.S3Bucket,
+			Prefix:    cfg.S3Prefix,
+			AccessKey: cfg.S3AccessKey,
+			SecretKey: cfg.S3SecretKey,
+			UseSSL:    cfg.S3UseSSL,
+			Timeout:   cfg.S3Timeout,
+		}, cfg.Retention)
+	default:
+		return nil, fmt.Errorf("unknown report storage %q", cfg.Storage)
+	}
+}
+
+func newRateProvider(cfg config.Rate) (account.RateProvider, error) {
+	if cfg.File != "" {
+		return rate.NewFileProvider(cfg.File)
+	}
+
+	rates, err := rate.ParseTable(cfg.Table)
+	if err != nil {
+		return nil, err
+	}
+
+	return rate.NewStaticProvider(rates), nil
+}
+
+func newPublisher(cfg config.Outbox, logger *zap.Logger) outbox.Publisher {
+	if cfg.WebhookURL != "" {
+		return publisher.NewWebhookPublisher(cfg.WebhookURL, cfg.WebhookTimeout)
+	}
+
+	if cfg.File != "" {
+		return publisher.NewFilePublisher(cfg.File)
+	}
+
+	return publisher.NewLogPublisher(logger)
+}
+
 func (a *App) Run(ctx context.Context) error {
 	defer a.db.Close()
 
-	eChan := make(chan error)
+	// Both servers may fail, and neither sender must block once Run has stopped receiving.
+	eChan := make(chan error, 2)
 	interrupt := make(chan os.Signal, 1)
 
+	// Workers are stopped and awaited before the pool is closed, so none of them is left
+	// in the middle of a transaction.
+	var workers sync.WaitGroup
+	defer workers.Wait()
+
+	workerCtx, cancelWorker := context.WithCancel(ctx)
+	defer cancelWorker()
+
+	runWorker := func(run func(ctx context.Context)) {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			run(workerCtx)
+		}()
+	}
+
+	a.logger.Info("Order expiry worker is starting")
+
+	runWorker(a.orderExpiry.Run)
+
+	a.logger.Info("Outbox relay is starting")
+
+	runWorker(a.outboxRelay.Run)
+
+	a.logger.Info("Webhook delivery worker is starting")
+
+	runWorker(a.webhookDelivery.Run)
+
+	a.logger.Info("Report job workers are starting")
+
+	runWorker(a.reportJobs.Run)
+
+	a.logger.Info("Report retention worker is starting")
+
+	runWorker(a.reportRetention.Run)
+
 	a.logger.Info("Http server is starting")
 
 	go func() {
@@ -78,17 +240,41 @@ func (a *App) Run(ctx context.Context) error {
 		}
 	}()
 
+	a.logger.Info("Grpc server is starting")
+
+	go func() {
+		if err := a.grpcServer.Start(); err != nil {
+			eChan <- fmt.Errorf("serve grpc: %w", err)
+		}
+	}()
+
+	var runErr error
+
 	signal.Notify(interrupt, syscall.SIGINT, syscall.SIGTERM)
 	select {
 	case err := <-eChan:
-		return fmt.Errorf("payment-api started failed: %w", err)
+		runErr = fmt.Errorf("payment-api started failed: %w", err)
 	case <-interrupt:
 	}
 
-	const httpShutdownTimeout = 5 * time.Second
-	if err := a.httpServer.Stop(ctx, httpShutdownTimeout); err != nil {
-		return fmt.Errorf("stop http server: %w", err)
+	// The servers are stopped even if one of them failed, so the other one does not outlive Run.
+	const shutdownTimeout = 5 * time.Second
+	if err := a.httpServer.Stop(ctx, shutdownTimeout); err != nil {
+		runErr = joinErrors(runErr, fmt.Errorf("stop http server: %w", err))
+	}
+
+	if err := a.grpcServer.Stop(ctx, shutdownTimeout); err != nil {
+		runErr = joinErrors(runErr, fmt.Errorf("stop grpc server: %w", err))
+	}
+
+	return runErr
+}
+
+// joinErrors keeps err matchable with errors.Is and appends the message of next to it.
+func joinErrors(err, next error) error {
+	if err == nil {
+		return next
 	}
 
-	return nil
+	return fmt.Errorf("%w; %v", err, next)
 }
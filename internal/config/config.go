@@ -4,10 +4,12 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
+	"path/filepath"
 	"sync"
 	"time"
 
 	"github.com/kelseyhightower/envconfig"
+	"github.com/maypok86/payment-api/internal/domain/order"
 )
 
 type EnvType string
@@ -22,8 +24,13 @@ type (
 	Config struct {
 		Environment EnvType `envconfig:"ENVIRONMENT" required:"true"`
 		HTTP        HTTP
+		GRPC        GRPC
 		Postgres    Postgres
 		Report      Report
+		Order       Order
+		Rate        Rate
+		Outbox      Outbox
+		Webhook     Webhook
 		Logger      Logger
 	}
 
@@ -35,19 +42,74 @@ type (
 		WriteTimeout   time.Duration `envconfig:"HTTP_WRITE_TIMEOUT"                    default:"10s"`
 	}
 
+	GRPC struct {
+		Host string `envconfig:"GRPC_HOST" default:"0.0.0.0"`
+		Port string `envconfig:"GRPC_PORT" default:"9090"`
+	}
+
 	Postgres struct {
-		Host        string `envconfig:"POSTGRES_HOST"          required:"true"`
-		Port        string `envconfig:"POSTGRES_PORT"          required:"true"`
-		DBName      string `envconfig:"POSTGRES_DBNAME"        required:"true"`
-		User        string `envconfig:"POSTGRES_USER"          required:"true"`
-		Password    string `envconfig:"POSTGRES_PASSWORD"      required:"true" json:"-"`
-		SSLMode     string `envconfig:"POSTGRES_SSLMODE"                                default:"disable"`
-		MaxPoolSize int    `envconfig:"POSTGRES_MAX_POOL_SIZE"                          default:"4"`
+		Host         string        `envconfig:"POSTGRES_HOST"           required:"true"`
+		Port         string        `envconfig:"POSTGRES_PORT"           required:"true"`
+		DBName       string        `envconfig:"POSTGRES_DBNAME"         required:"true"`
+		User         string        `envconfig:"POSTGRES_USER"           required:"true"`
+		Password     string        `envconfig:"POSTGRES_PASSWORD"       required:"true" json:"-"`
+		SSLMode      string        `envconfig:"POSTGRES_SSLMODE"                                 default:"disable"`
+		MaxPoolSize  int           `envconfig:"POSTGRES_MAX_POOL_SIZE"                           default:"4"`
+		TxIsoLevel   string        `envconfig:"POSTGRES_TX_ISO_LEVEL"                            default:"read committed"`
+		TxAttempts   int           `envconfig:"POSTGRES_TX_ATTEMPTS"                             default:"3"`
+		TxBackoff    time.Duration `envconfig:"POSTGRES_TX_BACKOFF"                              default:"10ms"`
+		TxMaxBackoff time.Duration `envconfig:"POSTGRES_TX_MAX_BACKOFF"                          default:"200ms"`
 	}
 
 	Report struct {
-		Host string `envconfig:"REPORT_HOST" required:"true"`
-		Port string `envconfig:"REPORT_PORT" required:"true"`
+		Host              string        `envconfig:"REPORT_HOST"               required:"true"`
+		Port              string        `envconfig:"REPORT_PORT"               required:"true"`
+		LinkScheme        string        `envconfig:"REPORT_LINK_SCHEME"        default:"http"`
+		LinkSecret        string        `envconfig:"REPORT_LINK_SECRET"        required:"true" json:"-"`
+		LinkTTL           time.Duration `envconfig:"REPORT_LINK_TTL"           default:"1h"`
+		JobInterval       time.Duration `envconfig:"REPORT_JOB_INTERVAL"       default:"1s"`
+		JobWorkers        int           `envconfig:"REPORT_JOB_WORKERS"        default:"2"`
+		JobTimeout        time.Duration `envconfig:"REPORT_JOB_TIMEOUT"        default:"10m"`
+		SnapshotGrace     time.Duration `envconfig:"REPORT_SNAPSHOT_GRACE"     default:"720h"`
+		Storage           string        `envconfig:"REPORT_STORAGE"            default:"file"`
+		StorageDir        string        `envconfig:"REPORT_STORAGE_DIR"        default:"/app/reports"`
+		Retention         time.Duration `envconfig:"REPORT_RETENTION"          default:"720h"`
+		RetentionInterval time.Duration `envconfig:"REPORT_RETENTION_INTERVAL" default:"1h"`
+		S3Endpoint        string        `envconfig:"REPORT_S3_ENDPOINT"`
+		S3Region          string        `envconfig:"REPORT_S3_REGION"          default:"us-east-1"`
+		S3Bucket          string        `envconfig:"REPORT_S3_BUCKET"          default:"reports"`
+		S3Prefix          string        `envconfig:"REPORT_S3_PREFIX"`
+		S3AccessKey       string        `envconfig:"REPORT_S3_ACCESS_KEY"      json:"-"`
+		S3SecretKey       string        `envconfig:"REPORT_S3_SECRET_KEY"      json:"-"`
+		S3UseSSL          bool          `envconfig:"REPORT_S3_USE_SSL"         default:"true"`
+		S3Timeout         time.Duration `envconfig:"REPORT_S3_TIMEOUT"         default:"10s"`
+	}
+
+	Order struct {
+		ReservationTTL  time.Duration `envconfig:"ORDER_RESERVATION_TTL"   default:"24h"`
+		ExpiryInterval  time.Duration `envconfig:"ORDER_EXPIRY_INTERVAL"   default:"1m"`
+		ExpiryBatchSize uint64        `envconfig:"ORDER_EXPIRY_BATCH_SIZE" default:"100"`
+	}
+
+	Rate struct {
+		File  string            `envconfig:"RATE_FILE"`
+		Table map[string]string `envconfig:"RATE_TABLE"`
+	}
+
+	Outbox struct {
+		RelayInterval  time.Duration `envconfig:"OUTBOX_RELAY_INTERVAL"   default:"1s"`
+		RelayBatchSize uint64        `envconfig:"OUTBOX_RELAY_BATCH_SIZE" default:"100"`
+		ClaimTimeout   time.Duration `envconfig:"OUTBOX_CLAIM_TIMEOUT"    default:"10m"`
+		File           string        `envconfig:"OUTBOX_FILE"`
+		WebhookURL     string        `envconfig:"OUTBOX_WEBHOOK_URL"`
+		WebhookTimeout time.Duration `envconfig:"OUTBOX_WEBHOOK_TIMEOUT"  default:"5s"`
+	}
+
+	Webhook struct {
+		DeliveryInterval  time.Duration `envconfig:"WEBHOOK_DELIVERY_INTERVAL"   default:"5s"`
+		DeliveryBatchSize uint64        `envconfig:"WEBHOOK_DELIVERY_BATCH_SIZE" default:"100"`
+		ClaimTimeout      time.Duration `envconfig:"WEBHOOK_CLAIM_TIMEOUT"       default:"10m"`
+		Timeout           time.Duration `envconfig:"WEBHOOK_TIMEOUT"             default:"5s"`
 	}
 
 	Logger struct {
@@ -83,6 +145,52 @@ func Get() *Config {
 		default:
 			log.Fatal("config environment should be test, prod or dev")
 		}
+		switch instance.Report.LinkScheme {
+		case "http", "https":
+		default:
+			log.Fatal("config report link scheme should be http or https")
+		}
+		for name, interval := range map[string]time.Duration{
+			"ORDER_EXPIRY_INTERVAL":     instance.Order.ExpiryInterval,
+			"OUTBOX_RELAY_INTERVAL":     instance.Outbox.RelayInterval,
+			"WEBHOOK_DELIVERY_INTERVAL": instance.Webhook.DeliveryInterval,
+			"REPORT_JOB_INTERVAL":       instance.Report.JobInterval,
+			"REPORT_RETENTION_INTERVAL": instance.Report.RetentionInterval,
+		} {
+			if interval <= 0 {
+				log.Fatalf("config %s should be positive", name)
+			}
+		}
+		for name, size := range map[string]uint64{
+			"ORDER_EXPIRY_BATCH_SIZE":     instance.Order.ExpiryBatchSize,
+			"OUTBOX_RELAY_BATCH_SIZE":     instance.Outbox.RelayBatchSize,
+			"WEBHOOK_DELIVERY_BATCH_SIZE": instance.Webhook.DeliveryBatchSize,
+		} {
+			if size == 0 {
+				log.Fatalf("config %s should be positive", name)
+			}
+		}
+		if instance.Order.ReservationTTL <= 0 || instance.Order.ReservationTTL > order.MaxReservationTTL {
+			log.Fatalf("config ORDER_RESERVATION_TTL should be positive and at most %s", order.MaxReservationTTL)
+		}
+		if instance.Outbox.ClaimTimeout <= 0 {
+			log.Fatal("config OUTBOX_CLAIM_TIMEOUT should be positive")
+		}
+		if instance.Webhook.ClaimTimeout <= 0 {
+			log.Fatal("config WEBHOOK_CLAIM_TIMEOUT should be positive")
+		}
+		if instance.Report.LinkTTL <= 0 {
+			log.Fatal("config REPORT_LINK_TTL should be positive")
+		}
+		if instance.Report.SnapshotGrace < 0 {
+			log.Fatal("config REPORT_SNAPSHOT_GRACE should not be negative")
+		}
+		if instance.Report.Storage == "file" && !filepath.IsAbs(instance.Report.StorageDir) {
+			log.Fatal("config REPORT_STORAGE_DIR should be an absolute path")
+		}
+		if instance.Report.JobWorkers <= 0 {
+			log.Fatal("config REPORT_JOB_WORKERS should be positive")
+		}
 		if instance.IsDev() {
 			configBytes, err := json.MarshalIndent(instance, "", " ")
 			if err != nil {
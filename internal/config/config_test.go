@@ -16,6 +16,7 @@ type env struct {
 	httpPort         string
 	reportHost       string
 	reportPort       string
+	reportLinkSecret string
 	postgresHost     string
 	postgresPort     string
 	postgresDBName   string
@@ -31,6 +32,7 @@ func setEnv(t *testing.T, env env) {
 	require.NoError(t, os.Setenv("HTTP_PORT", env.httpPort))
 	require.NoError(t, os.Setenv("REPORT_HOST", env.reportHost))
 	require.NoError(t, os.Setenv("REPORT_PORT", env.reportPort))
+	require.NoError(t, os.Setenv("REPORT_LINK_SECRET", env.reportLinkSecret))
 	require.NoError(t, os.Setenv("POSTGRES_HOST", env.postgresHost))
 	require.NoError(t, os.Setenv("POSTGRES_PORT", env.postgresPort))
 	require.NoError(t, os.Setenv("POSTGRES_DBNAME", env.postgresDBName))
@@ -47,6 +49,7 @@ func TestGet(t *testing.T) {
 		httpPort:         "8080",
 		reportHost:       "localhost",
 		reportPort:       "8080",
+		reportLinkSecret: "secret",
 		postgresHost:     "postgres",
 		postgresPort:     "5431",
 		postgresDBName:   "test_payment-api",
@@ -63,18 +66,58 @@ func TestGet(t *testing.T) {
 			ReadTimeout:    10 * time.Second,
 			WriteTimeout:   10 * time.Second,
 		},
+		GRPC: config.GRPC{
+			Host: "0.0.0.0",
+			Port: "9090",
+		},
 		Postgres: config.Postgres{
-			Host:        "postgres",
-			Port:        "5431",
-			DBName:      "test_payment-api",
-			User:        "test_payment-api",
-			Password:    "test",
-			SSLMode:     "disable",
-			MaxPoolSize: 4,
+			Host:         "postgres",
+			Port:         "5431",
+			DBName:       "test_payment-api",
+			User:         "test_payment-api",
+			Password:     "test",
+			SSLMode:      "disable",
+			MaxPoolSize:  4,
+			TxIsoLevel:   "read committed",
+			TxAttempts:   3,
+			TxBackoff:    10 * time.Millisecond,
+			TxMaxBackoff: 200 * time.Millisecond,
 		},
 		Report: config.Report{
-			Host: "localhost",
-			Port: "8080",
+			Host:              "localhost",
+			Port:              "8080",
+			LinkScheme:        "http",
+			LinkSecret:        "secret",
+			LinkTTL:           time.Hour,
+			JobInterval:       time.Second,
+			JobWorkers:        2,
+			JobTimeout:        10 * time.Minute,
+			SnapshotGrace:     720 * time.Hour,
+			Storage:           "file",
+			StorageDir:        "/app/reports",
+			Retention:         720 * time.Hour,
+			RetentionInterval: time.Hour,
+			S3Region:          "us-east-1",
+			S3Bucket:          "reports",
+			S3UseSSL:          true,
+			S3Timeout:         10 * time.Second,
+		},
+		Order: config.Order{
+			ReservationTTL:  24 * time.Hour,
+			ExpiryInterval:  time.Minute,
+			ExpiryBatchSize: 100,
+		},
+		Outbox: config.Outbox{
+			RelayInterval:  time.Second,
+			RelayBatchSize: 100,
+			ClaimTimeout:   10 * time.Minute,
+			WebhookTimeout: 5 * time.Second,
+		},
+		Webhook: config.Webhook{
+			DeliveryInterval:  5 * time.Second,
+			DeliveryBatchSize: 100,
+			ClaimTimeout:      10 * time.Minute,
+			Timeout:           5 * time.Second,
 		},
 		Logger: config.Logger{
 			Level: "info",
@@ -1,22 +1,82 @@
 package account
 
+import "github.com/maypok86/payment-api/internal/domain/currency"
+
 type AddBalanceDTO struct {
 	AccountID int64
 	Amount    int64
+	Currency  currency.Currency
+}
+
+func (dto AddBalanceDTO) Validate() error {
+	return dto.Currency.ValidateAmount(dto.Amount)
 }
 
 type TransferBalanceDTO struct {
-	SenderID   int64
-	ReceiverID int64
-	Amount     int64
+	SenderID         int64
+	ReceiverID       int64
+	Amount           int64
+	Currency         currency.Currency
+	ReceiverAmount   int64
+	ReceiverCurrency currency.Currency
+}
+
+func (dto TransferBalanceDTO) Validate() error {
+	return dto.Currency.ValidateAmount(dto.Amount)
+}
+
+type TransferBatchDTO struct {
+	Transfers []TransferBalanceDTO
+}
+
+// TransferResult holds the balances of the sender and the receiver right after a transfer of a batch.
+type TransferResult struct {
+	SenderBalance   int64
+	ReceiverBalance int64
+}
+
+// BalanceKey identifies the balance of an account in one currency.
+type BalanceKey struct {
+	AccountID int64
+	Currency  currency.Currency
 }
 
 type ReserveBalanceDTO struct {
 	AccountID int64
 	Amount    int64
+	Currency  currency.Currency
 }
 
 type ReturnBalanceDTO struct {
 	AccountID int64
 	Amount    int64
+	Currency  currency.Currency
+}
+
+type CaptureBalanceDTO struct {
+	AccountID int64
+	Amount    int64
+	Currency  currency.Currency
+}
+
+type RefundBalanceDTO struct {
+	AccountID int64
+	Amount    int64
+	Currency  currency.Currency
+}
+
+type SetCreditLimitDTO struct {
+	AccountID   int64
+	Currency    currency.Currency
+	CreditLimit int64
+}
+
+func (dto SetCreditLimitDTO) Validate() error {
+	return dto.Currency.ValidateAmount(dto.CreditLimit)
+}
+
+type UpdateStatusDTO struct {
+	AccountID int64
+	From      Status
+	To        Status
 }
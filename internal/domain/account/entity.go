@@ -1,13 +1,202 @@
 package account
 
-import "errors"
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/maypok86/payment-api/internal/domain/currency"
+)
 
 var (
-	ErrAlreadyExist = errors.New("account with given fields already exist")
-	ErrNotFound     = errors.New("account not found")
+	ErrAlreadyExist      = errors.New("account with given fields already exist")
+	ErrNotFound          = errors.New("account not found")
+	ErrFrozen            = errors.New("account is frozen")
+	ErrClosed            = errors.New("account is closed")
+	ErrIllegalTransition = errors.New("illegal account status transition")
+	ErrNonZeroBalance    = errors.New("account balance is not zero")
+	ErrOpenReservations  = errors.New("account has open reservations")
+	ErrInsufficientFunds = errors.New("insufficient funds")
+	ErrCreditLimitTooLow = errors.New("credit limit is lower than the current overdraft")
+	ErrEmptyBatch        = errors.New("transfer batch is empty")
+	ErrBatchTooLarge     = errors.New("transfer batch is too large")
 )
 
-type Account struct {
+// MaxBatchSize limits the number of transfers in a batch, so a batch does not hold its locks for too long.
+const MaxBatchSize = 1000
+
+type Status struct {
+	string
+}
+
+var (
+	Active = Status{"active"}
+	Frozen = Status{"frozen"}
+	Closed = Status{"closed"}
+)
+
+var statusToString = map[Status]string{
+	Active: "active",
+	Frozen: "frozen",
+	Closed: "closed",
+}
+
+var stringToStatus = map[string]Status{
+	"active": Active,
+	"frozen": Frozen,
+	"closed": Closed,
+}
+
+// transitions lists the statuses each status can move to. Statuses without an entry are final.
+var transitions = map[Status][]Status{
+	Active: {Frozen, Closed},
+	Frozen: {Active, Closed},
+}
+
+func (s Status) String() string {
+	return statusToString[s]
+}
+
+func (s *Status) Scan(value interface{}) error {
+	str, ok := value.(string)
+	if !ok {
+		return errors.New("scan source is not string")
+	}
+
+	if v, ok := stringToStatus[str]; ok {
+		*s = v
+		return nil
+	}
+
+	return errors.New("wrong value for Status")
+}
+
+func (s Status) Value() (driver.Value, error) {
+	str, ok := statusToString[s]
+	if !ok {
+		return nil, errors.New("wrong value for Status")
+	}
+
+	return str, nil
+}
+
+func (s Status) CanTransitionTo(to Status) bool {
+	for _, status := range transitions[s] {
+		if status == to {
+			return true
+		}
+	}
+
+	return false
+}
+
+type TransitionError struct {
 	AccountID int64
-	Balance   int64
+	From      Status
+	To        Status
+}
+
+func (e *TransitionError) Error() string {
+	return fmt.Sprintf("account with id = %d can not move from %s to %s", e.AccountID, e.From, e.To)
+}
+
+func (e *TransitionError) Unwrap() error {
+	return ErrIllegalTransition
+}
+
+type InsufficientFundsError struct {
+	AccountID   int64
+	Currency    currency.Currency
+	Available   int64
+	CreditLimit int64
+}
+
+func (e *InsufficientFundsError) Error() string {
+	return fmt.Sprintf(
+		"account with id = %d has %s available and %s credit limit",
+		e.AccountID,
+		e.Currency.Format(e.Available),
+		e.Currency.Format(e.CreditLimit),
+	)
+}
+
+func (e *InsufficientFundsError) Unwrap() error {
+	return ErrInsufficientFunds
+}
+
+// BatchItemError is the error of a single transfer in a batch. Index is the position of the transfer in the batch.
+type BatchItemError struct {
+	Index int
+	Err   error
+}
+
+func (e BatchItemError) Error() string {
+	return fmt.Sprintf("transfer #%d: %v", e.Index, e.Err)
+}
+
+func (e BatchItemError) Unwrap() error {
+	return e.Err
+}
+
+// BatchError lists the transfers that made a batch fail. Nothing from the batch is committed when it is returned.
+type BatchError struct {
+	Items []BatchItemError
+}
+
+func (e *BatchError) Error() string {
+	messages := make([]string, 0, len(e.Items))
+	for _, item := range e.Items {
+		messages = append(messages, item.Error())
+	}
+
+	return strings.Join(messages, "; ")
+}
+
+// Unwrap returns the first failed transfer, so errors.Is and errors.As match its error.
+func (e *BatchError) Unwrap() error {
+	if len(e.Items) == 0 {
+		return nil
+	}
+
+	return e.Items[0]
+}
+
+// Account holds the balance of an account in one currency. Available is the money that is not held by orders,
+// it may go down to -CreditLimit. Reserved is the money held by reserved orders until they are paid or cancelled.
+type Account struct {
+	AccountID   int64
+	Status      Status
+	Currency    currency.Currency
+	Available   int64
+	Reserved    int64
+	CreditLimit int64
+}
+
+func (a Account) Total() int64 {
+	return a.Available + a.Reserved
+}
+
+func (a Account) CheckTransition(to Status) error {
+	if !a.Status.CanTransitionTo(to) {
+		return &TransitionError{
+			AccountID: a.AccountID,
+			From:      a.Status,
+			To:        to,
+		}
+	}
+
+	return nil
+}
+
+// CheckActive reports whether money can be moved to or from the account.
+func (a Account) CheckActive() error {
+	switch a.Status {
+	case Frozen:
+		return fmt.Errorf("account with id = %d: %w", a.AccountID, ErrFrozen)
+	case Closed:
+		return fmt.Errorf("account with id = %d: %w", a.AccountID, ErrClosed)
+	}
+
+	return nil
 }
@@ -0,0 +1,184 @@
+package account_test
+
+import (
+	"testing"
+
+	"github.com/maypok86/payment-api/internal/domain/account"
+	"github.com/maypok86/payment-api/internal/domain/currency"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStatus_Scan(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		value   interface{}
+		want    account.Status
+		wantErr bool
+	}{
+		{
+			name:  "scan active",
+			value: "active",
+			want:  account.Active,
+		},
+		{
+			name:  "scan closed",
+			value: "closed",
+			want:  account.Closed,
+		},
+		{
+			name:    "scan unknown status",
+			value:   "unknown",
+			wantErr: true,
+		},
+		{
+			name:    "scan not string",
+			value:   1,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var got account.Status
+			err := got.Scan(tt.value)
+			require.True(t, (err != nil) == tt.wantErr)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestStatus_CanTransitionTo(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		from account.Status
+		to   account.Status
+		want bool
+	}{
+		{
+			name: "active to frozen",
+			from: account.Active,
+			to:   account.Frozen,
+			want: true,
+		},
+		{
+			name: "frozen to active",
+			from: account.Frozen,
+			to:   account.Active,
+			want: true,
+		},
+		{
+			name: "active to closed",
+			from: account.Active,
+			to:   account.Closed,
+			want: true,
+		},
+		{
+			name: "frozen to closed",
+			from: account.Frozen,
+			to:   account.Closed,
+			want: true,
+		},
+		{
+			name: "active to active",
+			from: account.Active,
+			to:   account.Active,
+			want: false,
+		},
+		{
+			name: "frozen to frozen",
+			from: account.Frozen,
+			to:   account.Frozen,
+			want: false,
+		},
+		{
+			name: "closed is final",
+			from: account.Closed,
+			to:   account.Active,
+			want: false,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			require.Equal(t, tt.want, tt.from.CanTransitionTo(tt.to))
+		})
+	}
+}
+
+func TestAccount_CheckTransition(t *testing.T) {
+	t.Parallel()
+
+	entity := account.Account{
+		AccountID: 1,
+		Status:    account.Closed,
+	}
+
+	err := entity.CheckTransition(account.Active)
+	require.ErrorIs(t, err, account.ErrIllegalTransition)
+
+	var transitionErr *account.TransitionError
+	require.ErrorAs(t, err, &transitionErr)
+	require.Equal(t, account.Closed, transitionErr.From)
+	require.Equal(t, account.Active, transitionErr.To)
+	require.Equal(t, "account with id = 1 can not move from closed to active", err.Error())
+}
+
+func TestAccount_CheckActive(t *testing.T) {
+	t.Parallel()
+
+	require.NoError(t, account.Account{AccountID: 1, Status: account.Active}.CheckActive())
+	require.ErrorIs(t, account.Account{AccountID: 1, Status: account.Frozen}.CheckActive(), account.ErrFrozen)
+	require.ErrorIs(t, account.Account{AccountID: 1, Status: account.Closed}.CheckActive(), account.ErrClosed)
+}
+
+func TestAccount_Total(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, int64(300), account.Account{Available: 100, Reserved: 200}.Total())
+	require.Equal(t, int64(50), account.Account{Available: -150, Reserved: 200, CreditLimit: 200}.Total())
+}
+
+func TestInsufficientFundsError(t *testing.T) {
+	t.Parallel()
+
+	var err error = &account.InsufficientFundsError{
+		AccountID:   1,
+		Currency:    currency.USD,
+		Available:   -150,
+		CreditLimit: 200,
+	}
+
+	require.ErrorIs(t, err, account.ErrInsufficientFunds)
+	require.Equal(t, "account with id = 1 has -1.50 USD available and 2.00 USD credit limit", err.Error())
+}
+
+func TestBatchError(t *testing.T) {
+	t.Parallel()
+
+	var err error = &account.BatchError{
+		Items: []account.BatchItemError{
+			{Index: 2, Err: &account.InsufficientFundsError{AccountID: 1, Currency: currency.RUB}},
+			{Index: 5, Err: account.ErrRateNotFound},
+		},
+	}
+
+	require.ErrorIs(t, err, account.ErrInsufficientFunds)
+	require.Equal(
+		t,
+		"transfer #2: account with id = 1 has 0.00 RUB available and 0.00 RUB credit limit; "+
+			"transfer #5: exchange rate not found",
+		err.Error(),
+	)
+}
@@ -10,6 +10,9 @@ import (
 
 	gomock "github.com/golang/mock/gomock"
 	account "github.com/maypok86/payment-api/internal/domain/account"
+	currency "github.com/maypok86/payment-api/internal/domain/currency"
+	ledger "github.com/maypok86/payment-api/internal/domain/ledger"
+	outbox "github.com/maypok86/payment-api/internal/domain/outbox"
 	transaction "github.com/maypok86/payment-api/internal/domain/transaction"
 )
 
@@ -88,19 +91,108 @@ func (mr *MockRepositoryMockRecorder) AddBalance(ctx, dto interface{}) *gomock.C
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddBalance", reflect.TypeOf((*MockRepository)(nil).AddBalance), ctx, dto)
 }
 
+// CreateAccount mocks base method.
+func (m *MockRepository) CreateAccount(ctx context.Context, accountID int64) (account.Account, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAccount", ctx, accountID)
+	ret0, _ := ret[0].(account.Account)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateAccount indicates an expected call of CreateAccount.
+func (mr *MockRepositoryMockRecorder) CreateAccount(ctx, accountID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAccount", reflect.TypeOf((*MockRepository)(nil).CreateAccount), ctx, accountID)
+}
+
 // GetAccountByID mocks base method.
-func (m *MockRepository) GetAccountByID(ctx context.Context, accountID int64) (account.Account, error) {
+func (m *MockRepository) GetAccountByID(ctx context.Context, accountID int64, cur currency.Currency) (account.Account, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetAccountByID", ctx, accountID)
+	ret := m.ctrl.Call(m, "GetAccountByID", ctx, accountID, cur)
 	ret0, _ := ret[0].(account.Account)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetAccountByID indicates an expected call of GetAccountByID.
-func (mr *MockRepositoryMockRecorder) GetAccountByID(ctx, accountID interface{}) *gomock.Call {
+func (mr *MockRepositoryMockRecorder) GetAccountByID(ctx, accountID, cur interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccountByID", reflect.TypeOf((*MockRepository)(nil).GetAccountByID), ctx, accountID, cur)
+}
+
+// GetAccountForUpdate mocks base method.
+func (m *MockRepository) GetAccountForUpdate(ctx context.Context, accountID int64) (account.Account, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAccountForUpdate", ctx, accountID)
+	ret0, _ := ret[0].(account.Account)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAccountForUpdate indicates an expected call of GetAccountForUpdate.
+func (mr *MockRepositoryMockRecorder) GetAccountForUpdate(ctx, accountID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccountForUpdate", reflect.TypeOf((*MockRepository)(nil).GetAccountForUpdate), ctx, accountID)
+}
+
+// HasBalance mocks base method.
+func (m *MockRepository) HasBalance(ctx context.Context, accountID int64) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "HasBalance", ctx, accountID)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// HasBalance indicates an expected call of HasBalance.
+func (mr *MockRepositoryMockRecorder) HasBalance(ctx, accountID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HasBalance", reflect.TypeOf((*MockRepository)(nil).HasBalance), ctx, accountID)
+}
+
+// HasOpenReservations mocks base method.
+func (m *MockRepository) HasOpenReservations(ctx context.Context, accountID int64) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "HasOpenReservations", ctx, accountID)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// HasOpenReservations indicates an expected call of HasOpenReservations.
+func (mr *MockRepositoryMockRecorder) HasOpenReservations(ctx, accountID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HasOpenReservations", reflect.TypeOf((*MockRepository)(nil).HasOpenReservations), ctx, accountID)
+}
+
+// LockBalances mocks base method.
+func (m *MockRepository) LockBalances(ctx context.Context, balances []account.BalanceKey) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LockBalances", ctx, balances)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// LockBalances indicates an expected call of LockBalances.
+func (mr *MockRepositoryMockRecorder) LockBalances(ctx, balances interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LockBalances", reflect.TypeOf((*MockRepository)(nil).LockBalances), ctx, balances)
+}
+
+// SetCreditLimit mocks base method.
+func (m *MockRepository) SetCreditLimit(ctx context.Context, dto account.SetCreditLimitDTO) (account.Account, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetCreditLimit", ctx, dto)
+	ret0, _ := ret[0].(account.Account)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetCreditLimit indicates an expected call of SetCreditLimit.
+func (mr *MockRepositoryMockRecorder) SetCreditLimit(ctx, dto interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccountByID", reflect.TypeOf((*MockRepository)(nil).GetAccountByID), ctx, accountID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetCreditLimit", reflect.TypeOf((*MockRepository)(nil).SetCreditLimit), ctx, dto)
 }
 
 // TransferBalance mocks base method.
@@ -119,6 +211,20 @@ func (mr *MockRepositoryMockRecorder) TransferBalance(ctx, dto interface{}) *gom
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TransferBalance", reflect.TypeOf((*MockRepository)(nil).TransferBalance), ctx, dto)
 }
 
+// UpdateStatus mocks base method.
+func (m *MockRepository) UpdateStatus(ctx context.Context, dto account.UpdateStatusDTO) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateStatus", ctx, dto)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateStatus indicates an expected call of UpdateStatus.
+func (mr *MockRepositoryMockRecorder) UpdateStatus(ctx, dto interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateStatus", reflect.TypeOf((*MockRepository)(nil).UpdateStatus), ctx, dto)
+}
+
 // MockTransactionRepository is a mock of TransactionRepository interface.
 type MockTransactionRepository struct {
 	ctrl     *gomock.Controller
@@ -155,3 +261,115 @@ func (mr *MockTransactionRepositoryMockRecorder) CreateTransaction(ctx, dto inte
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateTransaction", reflect.TypeOf((*MockTransactionRepository)(nil).CreateTransaction), ctx, dto)
 }
+
+// MockLedgerRepository is a mock of LedgerRepository interface.
+type MockLedgerRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockLedgerRepositoryMockRecorder
+}
+
+// MockLedgerRepositoryMockRecorder is the mock recorder for MockLedgerRepository.
+type MockLedgerRepositoryMockRecorder struct {
+	mock *MockLedgerRepository
+}
+
+// NewMockLedgerRepository creates a new mock instance.
+func NewMockLedgerRepository(ctrl *gomock.Controller) *MockLedgerRepository {
+	mock := &MockLedgerRepository{ctrl: ctrl}
+	mock.recorder = &MockLedgerRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockLedgerRepository) EXPECT() *MockLedgerRepositoryMockRecorder {
+	return m.recorder
+}
+
+// CreatePosting mocks base method.
+func (m *MockLedgerRepository) CreatePosting(ctx context.Context, dto ledger.CreatePostingDTO) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreatePosting", ctx, dto)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreatePosting indicates an expected call of CreatePosting.
+func (mr *MockLedgerRepositoryMockRecorder) CreatePosting(ctx, dto interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePosting", reflect.TypeOf((*MockLedgerRepository)(nil).CreatePosting), ctx, dto)
+}
+
+// MockOutboxRepository is a mock of OutboxRepository interface.
+type MockOutboxRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockOutboxRepositoryMockRecorder
+}
+
+// MockOutboxRepositoryMockRecorder is the mock recorder for MockOutboxRepository.
+type MockOutboxRepositoryMockRecorder struct {
+	mock *MockOutboxRepository
+}
+
+// NewMockOutboxRepository creates a new mock instance.
+func NewMockOutboxRepository(ctrl *gomock.Controller) *MockOutboxRepository {
+	mock := &MockOutboxRepository{ctrl: ctrl}
+	mock.recorder = &MockOutboxRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockOutboxRepository) EXPECT() *MockOutboxRepositoryMockRecorder {
+	return m.recorder
+}
+
+// CreateEvent mocks base method.
+func (m *MockOutboxRepository) CreateEvent(ctx context.Context, dto outbox.CreateDTO) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateEvent", ctx, dto)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateEvent indicates an expected call of CreateEvent.
+func (mr *MockOutboxRepositoryMockRecorder) CreateEvent(ctx, dto interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateEvent", reflect.TypeOf((*MockOutboxRepository)(nil).CreateEvent), ctx, dto)
+}
+
+// MockRateProvider is a mock of RateProvider interface.
+type MockRateProvider struct {
+	ctrl     *gomock.Controller
+	recorder *MockRateProviderMockRecorder
+}
+
+// MockRateProviderMockRecorder is the mock recorder for MockRateProvider.
+type MockRateProviderMockRecorder struct {
+	mock *MockRateProvider
+}
+
+// NewMockRateProvider creates a new mock instance.
+func NewMockRateProvider(ctrl *gomock.Controller) *MockRateProvider {
+	mock := &MockRateProvider{ctrl: ctrl}
+	mock.recorder = &MockRateProviderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockRateProvider) EXPECT() *MockRateProviderMockRecorder {
+	return m.recorder
+}
+
+// GetRate mocks base method.
+func (m *MockRateProvider) GetRate(ctx context.Context, from, to currency.Currency) (account.Rate, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRate", ctx, from, to)
+	ret0, _ := ret[0].(account.Rate)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRate indicates an expected call of GetRate.
+func (mr *MockRateProviderMockRecorder) GetRate(ctx, from, to interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRate", reflect.TypeOf((*MockRateProvider)(nil).GetRate), ctx, from, to)
+}
@@ -0,0 +1,86 @@
+package account
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/maypok86/payment-api/internal/domain/currency"
+)
+
+// rateScale is the maximum number of decimal digits in a rate.
+const rateScale = 10
+
+var (
+	ErrRateNotFound   = errors.New("exchange rate not found")
+	ErrInvalidRate    = errors.New("exchange rate is not valid")
+	ErrAmountTooSmall = errors.New("converted amount is too small")
+)
+
+// Rate is the number of units of the target currency for one unit of the source currency.
+type Rate struct {
+	value *big.Rat
+}
+
+func ParseRate(s string) (Rate, error) {
+	value, ok := new(big.Rat).SetString(s)
+	if !ok || value.Sign() <= 0 {
+		return Rate{}, fmt.Errorf("%w: %q", ErrInvalidRate, s)
+	}
+
+	if !new(big.Rat).Mul(value, pow10(rateScale)).IsInt() {
+		return Rate{}, fmt.Errorf("%w: %q has more than %d decimal digits", ErrInvalidRate, s, rateScale)
+	}
+
+	return Rate{value: value}, nil
+}
+
+func (r Rate) IsZero() bool {
+	return r.value == nil
+}
+
+func (r Rate) String() string {
+	if r.IsZero() {
+		return "0"
+	}
+
+	return trimDecimal(r.value.FloatString(rateScale))
+}
+
+// Convert converts an amount in minor units of from into minor units of to. The converted amount is rounded down,
+// the returned remainder is the dropped fraction of the minor unit of to.
+func (r Rate) Convert(amount int64, from, to currency.Currency) (int64, string, error) {
+	if r.IsZero() {
+		return 0, "", ErrInvalidRate
+	}
+
+	exact := new(big.Rat).SetInt64(amount)
+	exact.Mul(exact, r.value)
+	exact.Mul(exact, pow10(to.MinorUnits()))
+	exact.Quo(exact, pow10(from.MinorUnits()))
+
+	converted := new(big.Int).Quo(exact.Num(), exact.Denom())
+	if !converted.IsInt64() {
+		return 0, "", fmt.Errorf("%w: converted amount overflows", ErrInvalidRate)
+	}
+	if converted.Sign() <= 0 {
+		return 0, "", ErrAmountTooSmall
+	}
+
+	remainder := new(big.Rat).Sub(exact, new(big.Rat).SetInt(converted))
+
+	return converted.Int64(), trimDecimal(remainder.FloatString(rateScale + from.MinorUnits())), nil
+}
+
+func pow10(n int) *big.Rat {
+	return new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n)), nil))
+}
+
+func trimDecimal(s string) string {
+	if !strings.Contains(s, ".") {
+		return s
+	}
+
+	return strings.TrimSuffix(strings.TrimRight(s, "0"), ".")
+}
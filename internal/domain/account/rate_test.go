@@ -0,0 +1,147 @@
+package account_test
+
+import (
+	"testing"
+
+	"github.com/maypok86/payment-api/internal/domain/account"
+	"github.com/maypok86/payment-api/internal/domain/currency"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		rate      string
+		want      string
+		wantedErr error
+	}{
+		{
+			name: "success parse",
+			rate: "61.50",
+			want: "61.5",
+		},
+		{
+			name: "integer rate",
+			rate: "2",
+			want: "2",
+		},
+		{
+			name:      "zero rate",
+			rate:      "0",
+			wantedErr: account.ErrInvalidRate,
+		},
+		{
+			name:      "negative rate",
+			rate:      "-1.5",
+			wantedErr: account.ErrInvalidRate,
+		},
+		{
+			name:      "too many decimal digits",
+			rate:      "0.00000000001",
+			wantedErr: account.ErrInvalidRate,
+		},
+		{
+			name:      "not a number",
+			rate:      "abc",
+			wantedErr: account.ErrInvalidRate,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := account.ParseRate(tt.rate)
+			if tt.wantedErr != nil {
+				require.ErrorIs(t, err, tt.wantedErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got.String())
+		})
+	}
+}
+
+func TestRate_Convert(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name          string
+		rate          string
+		amount        int64
+		from          currency.Currency
+		to            currency.Currency
+		want          int64
+		wantRemainder string
+		wantedErr     error
+	}{
+		{
+			name:          "exact conversion",
+			rate:          "61.5",
+			amount:        100,
+			from:          currency.USD,
+			to:            currency.RUB,
+			want:          6150,
+			wantRemainder: "0",
+		},
+		{
+			name:          "rounded down conversion",
+			rate:          "61.5",
+			amount:        101,
+			from:          currency.USD,
+			to:            currency.RUB,
+			want:          6211,
+			wantRemainder: "0.5",
+		},
+		{
+			name:          "to currency without minor units",
+			rate:          "151.237",
+			amount:        1050,
+			from:          currency.USD,
+			to:            currency.JPY,
+			want:          1587,
+			wantRemainder: "0.9885",
+		},
+		{
+			name:          "to currency with three minor units",
+			rate:          "0.0033",
+			amount:        10000,
+			from:          currency.RUB,
+			to:            currency.KWD,
+			want:          330,
+			wantRemainder: "0",
+		},
+		{
+			name:      "converted amount is too small",
+			rate:      "0.001",
+			amount:    1,
+			from:      currency.RUB,
+			to:        currency.USD,
+			wantedErr: account.ErrAmountTooSmall,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			rate, err := account.ParseRate(tt.rate)
+			require.NoError(t, err)
+
+			got, gotRemainder, err := rate.Convert(tt.amount, tt.from, tt.to)
+			if tt.wantedErr != nil {
+				require.ErrorIs(t, err, tt.wantedErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+			require.Equal(t, tt.wantRemainder, gotRemainder)
+		})
+	}
+}
@@ -3,7 +3,11 @@ package account
 import (
 	"context"
 	"fmt"
+	"sort"
 
+	"github.com/maypok86/payment-api/internal/domain/currency"
+	"github.com/maypok86/payment-api/internal/domain/ledger"
+	"github.com/maypok86/payment-api/internal/domain/outbox"
 	"github.com/maypok86/payment-api/internal/domain/transaction"
 	"go.uber.org/zap"
 )
@@ -15,19 +19,41 @@ type Transactor interface {
 }
 
 type Repository interface {
-	GetAccountByID(ctx context.Context, accountID int64) (Account, error)
+	CreateAccount(ctx context.Context, accountID int64) (Account, error)
+	GetAccountByID(ctx context.Context, accountID int64, cur currency.Currency) (Account, error)
+	GetAccountForUpdate(ctx context.Context, accountID int64) (Account, error)
+	UpdateStatus(ctx context.Context, dto UpdateStatusDTO) error
+	SetCreditLimit(ctx context.Context, dto SetCreditLimitDTO) (Account, error)
+	HasBalance(ctx context.Context, accountID int64) (bool, error)
+	HasOpenReservations(ctx context.Context, accountID int64) (bool, error)
 	AddBalance(ctx context.Context, dto AddBalanceDTO) (int64, error)
 	TransferBalance(ctx context.Context, dto TransferBalanceDTO) (int64, int64, error)
+	LockBalances(ctx context.Context, balances []BalanceKey) error
 }
 
 type TransactionRepository interface {
 	CreateTransaction(ctx context.Context, dto transaction.CreateDTO) error
 }
 
+type LedgerRepository interface {
+	CreatePosting(ctx context.Context, dto ledger.CreatePostingDTO) error
+}
+
+type OutboxRepository interface {
+	CreateEvent(ctx context.Context, dto outbox.CreateDTO) error
+}
+
+type RateProvider interface {
+	GetRate(ctx context.Context, from, to currency.Currency) (Rate, error)
+}
+
 type Service struct {
 	transactor            Transactor
 	repository            Repository
 	transactionRepository TransactionRepository
+	ledgerRepository      LedgerRepository
+	outboxRepository      OutboxRepository
+	rateProvider          RateProvider
 	logger                *zap.Logger
 }
 
@@ -35,41 +61,144 @@ func NewService(
 	transactor Transactor,
 	repository Repository,
 	transactionRepository TransactionRepository,
+	ledgerRepository LedgerRepository,
+	outboxRepository OutboxRepository,
+	rateProvider RateProvider,
 	logger *zap.Logger,
 ) *Service {
 	return &Service{
 		transactor:            transactor,
 		repository:            repository,
 		transactionRepository: transactionRepository,
+		ledgerRepository:      ledgerRepository,
+		outboxRepository:      outboxRepository,
+		rateProvider:          rateProvider,
 		logger:                logger,
 	}
 }
 
-func (s *Service) GetBalanceByID(ctx context.Context, accountID int64) (int64, error) {
-	account, err := s.repository.GetAccountByID(ctx, accountID)
+func (s *Service) CreateAccount(ctx context.Context, accountID int64) (Account, error) {
+	account, err := s.repository.CreateAccount(ctx, accountID)
+	if err != nil {
+		return Account{}, fmt.Errorf("create account: %w", err)
+	}
+
+	return account, nil
+}
+
+func (s *Service) FreezeAccount(ctx context.Context, accountID int64) (Account, error) {
+	account, err := s.moveAccount(ctx, accountID, Frozen)
+	if err != nil {
+		return Account{}, fmt.Errorf("freeze account: %w", err)
+	}
+
+	return account, nil
+}
+
+func (s *Service) UnfreezeAccount(ctx context.Context, accountID int64) (Account, error) {
+	account, err := s.moveAccount(ctx, accountID, Active)
 	if err != nil {
-		return 0, fmt.Errorf("get balance by id: %w", err)
+		return Account{}, fmt.Errorf("unfreeze account: %w", err)
 	}
 
-	return account.Balance, nil
+	return account, nil
+}
+
+// CloseAccount closes the account for good. The account must have a zero balance in every currency
+// and no reserved orders, so no money is left behind.
+func (s *Service) CloseAccount(ctx context.Context, accountID int64) (Account, error) {
+	account, err := s.moveAccount(ctx, accountID, Closed)
+	if err != nil {
+		return Account{}, fmt.Errorf("close account: %w", err)
+	}
+
+	return account, nil
+}
+
+// SetCreditLimit lets the balance in the currency go down to -CreditLimit. The limit can not be set
+// below the current overdraft.
+func (s *Service) SetCreditLimit(ctx context.Context, dto SetCreditLimitDTO) (account Account, err error) {
+	if err := dto.Validate(); err != nil {
+		return Account{}, fmt.Errorf("set credit limit: %w", err)
+	}
+
+	err = s.transactor.WithTx(ctx, func(ctx context.Context) error {
+		current, err := s.repository.GetAccountForUpdate(ctx, dto.AccountID)
+		if err != nil {
+			return err
+		}
+
+		if current.Status == Closed {
+			return fmt.Errorf("account with id = %d: %w", dto.AccountID, ErrClosed)
+		}
+
+		account, err = s.repository.SetCreditLimit(ctx, dto)
+		if err != nil {
+			return err
+		}
+
+		account.Status = current.Status
+
+		return nil
+	})
+	if err != nil {
+		return Account{}, fmt.Errorf("set credit limit: %w", err)
+	}
+
+	return account, nil
+}
+
+func (s *Service) GetBalanceByID(ctx context.Context, accountID int64, cur currency.Currency) (Account, error) {
+	account, err := s.repository.GetAccountByID(ctx, accountID, cur)
+	if err != nil {
+		return Account{}, fmt.Errorf("get balance by id: %w", err)
+	}
+
+	return account, nil
 }
 
 func (s *Service) AddBalance(ctx context.Context, dto AddBalanceDTO) (balance int64, err error) {
+	if err := dto.Validate(); err != nil {
+		return 0, fmt.Errorf("add balance: %w", err)
+	}
+
 	err = s.transactor.WithTx(ctx, func(ctx context.Context) error {
 		balance, err = s.repository.AddBalance(ctx, dto)
 		if err != nil {
 			return err
 		}
 
+		// A zero enrollment only opens the account, it moves no money to record.
+		if dto.Amount == 0 {
+			return nil
+		}
+
 		transactionDTO := transaction.CreateDTO{
 			Type:        transaction.Enrollment,
 			SenderID:    dto.AccountID,
 			ReceiverID:  dto.AccountID,
 			Amount:      dto.Amount,
-			Description: fmt.Sprintf("Add %d kopecks to account with id = %d", dto.Amount, dto.AccountID),
+			Currency:    dto.Currency,
+			Description: fmt.Sprintf("Add %s to account with id = %d", dto.Currency.Format(dto.Amount), dto.AccountID),
+		}
+
+		if err := s.transactionRepository.CreateTransaction(ctx, transactionDTO); err != nil {
+			return err
 		}
 
-		return s.transactionRepository.CreateTransaction(ctx, transactionDTO)
+		if err := s.ledgerRepository.CreatePosting(
+			ctx,
+			ledger.NewEnrollmentDTO(dto.AccountID, dto.Amount, dto.Currency, transactionDTO.Description),
+		); err != nil {
+			return err
+		}
+
+		return s.outboxRepository.CreateEvent(ctx, outbox.NewBalanceCreditedDTO(outbox.BalanceCreditedPayload{
+			AccountID: dto.AccountID,
+			Amount:    dto.Amount,
+			Currency:  dto.Currency,
+			Balance:   balance,
+		}))
 	})
 	if err != nil {
 		return 0, fmt.Errorf("add balance: %w", err)
@@ -82,30 +211,292 @@ func (s *Service) TransferBalance(
 	ctx context.Context,
 	dto TransferBalanceDTO,
 ) (senderBalance int64, receiverBalance int64, err error) {
+	dto, conversion, err := s.prepareTransfer(ctx, dto)
+	if err != nil {
+		return 0, 0, fmt.Errorf("transfer balance: %w", err)
+	}
+
 	err = s.transactor.WithTx(ctx, func(ctx context.Context) error {
-		senderBalance, receiverBalance, err = s.repository.TransferBalance(ctx, dto)
+		senderBalance, receiverBalance, err = s.transfer(ctx, dto, conversion)
+
+		return err
+	})
+	if err != nil {
+		return 0, 0, fmt.Errorf("transfer balance: %w", err)
+	}
+
+	return senderBalance, receiverBalance, nil
+}
+
+type preparedTransfer struct {
+	dto        TransferBalanceDTO
+	conversion *transaction.Conversion
+}
+
+// TransferBatch runs all transfers in one transaction, so either all of them are committed or none.
+// Transfers that can not be converted are reported together before anything is written. The balances of
+// the batch are locked in a fixed order before the first transfer, so concurrent batches over the same
+// accounts wait for each other instead of deadlocking.
+func (s *Service) TransferBatch(ctx context.Context, dto TransferBatchDTO) (results []TransferResult, err error) {
+	switch {
+	case len(dto.Transfers) == 0:
+		return nil, fmt.Errorf("transfer batch: %w", ErrEmptyBatch)
+	case len(dto.Transfers) > MaxBatchSize:
+		return nil, fmt.Errorf("transfer batch: %w", ErrBatchTooLarge)
+	}
+
+	transfers := make([]preparedTransfer, len(dto.Transfers))
+	batchErr := &BatchError{}
+	for i, transfer := range dto.Transfers {
+		transfer, conversion, err := s.prepareTransfer(ctx, transfer)
 		if err != nil {
+			batchErr.Items = append(batchErr.Items, BatchItemError{Index: i, Err: err})
+			continue
+		}
+
+		transfers[i] = preparedTransfer{dto: transfer, conversion: conversion}
+	}
+	if len(batchErr.Items) > 0 {
+		return nil, fmt.Errorf("transfer batch: %w", batchErr)
+	}
+
+	err = s.transactor.WithTx(ctx, func(ctx context.Context) error {
+		if err := s.repository.LockBalances(ctx, batchBalances(transfers)); err != nil {
 			return err
 		}
 
-		transactionDTO := transaction.CreateDTO{
-			Type:       transaction.Transfer,
-			SenderID:   dto.SenderID,
-			ReceiverID: dto.ReceiverID,
-			Amount:     dto.Amount,
-			Description: fmt.Sprintf(
-				"Transfer %d kopecks from account with id = %d to account with id = %d",
-				dto.Amount,
-				dto.SenderID,
-				dto.ReceiverID,
-			),
-		}
-
-		return s.transactionRepository.CreateTransaction(ctx, transactionDTO)
+		results = make([]TransferResult, 0, len(transfers))
+		for i, transfer := range transfers {
+			senderBalance, receiverBalance, err := s.transfer(ctx, transfer.dto, transfer.conversion)
+			if err != nil {
+				return &BatchError{Items: []BatchItemError{{Index: i, Err: err}}}
+			}
+
+			results = append(results, TransferResult{
+				SenderBalance:   senderBalance,
+				ReceiverBalance: receiverBalance,
+			})
+		}
+
+		return nil
 	})
 	if err != nil {
-		return 0, 0, fmt.Errorf("transfer balance: %w", err)
+		return nil, fmt.Errorf("transfer batch: %w", err)
+	}
+
+	return results, nil
+}
+
+// batchBalances returns every balance touched by the transfers once, sorted by account id and currency.
+func batchBalances(transfers []preparedTransfer) []BalanceKey {
+	seen := make(map[BalanceKey]struct{}, 2*len(transfers))
+	balances := make([]BalanceKey, 0, 2*len(transfers))
+	for _, transfer := range transfers {
+		for _, balance := range []BalanceKey{
+			{AccountID: transfer.dto.SenderID, Currency: transfer.dto.Currency},
+			{AccountID: transfer.dto.ReceiverID, Currency: transfer.dto.ReceiverCurrency},
+		} {
+			if _, ok := seen[balance]; ok {
+				continue
+			}
+
+			seen[balance] = struct{}{}
+			balances = append(balances, balance)
+		}
+	}
+
+	sort.Slice(balances, func(i, j int) bool {
+		if balances[i].AccountID != balances[j].AccountID {
+			return balances[i].AccountID < balances[j].AccountID
+		}
+
+		return balances[i].Currency.String() < balances[j].Currency.String()
+	})
+
+	return balances
+}
+
+// prepareTransfer fills the receiver currency and amount of the transfer, converting the amount if needed.
+func (s *Service) prepareTransfer(
+	ctx context.Context,
+	dto TransferBalanceDTO,
+) (TransferBalanceDTO, *transaction.Conversion, error) {
+	if err := dto.Validate(); err != nil {
+		return TransferBalanceDTO{}, nil, err
+	}
+
+	if dto.ReceiverCurrency.IsZero() {
+		dto.ReceiverCurrency = dto.Currency
+	}
+
+	dto.ReceiverAmount = dto.Amount
+	if dto.ReceiverCurrency == dto.Currency {
+		return dto, nil, nil
+	}
+
+	conversion, err := s.convert(ctx, dto.Amount, dto.Currency, dto.ReceiverCurrency)
+	if err != nil {
+		return TransferBalanceDTO{}, nil, err
+	}
+
+	dto.ReceiverAmount = conversion.Amount
+
+	return dto, conversion, nil
+}
+
+// transfer moves the money and writes the transaction, the postings and the event of a prepared transfer.
+// It must be called inside a transaction.
+func (s *Service) transfer(
+	ctx context.Context,
+	dto TransferBalanceDTO,
+	conversion *transaction.Conversion,
+) (int64, int64, error) {
+	senderBalance, receiverBalance, err := s.repository.TransferBalance(ctx, dto)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	description := fmt.Sprintf(
+		"Transfer %s from account with id = %d to account with id = %d",
+		dto.Currency.Format(dto.Amount),
+		dto.SenderID,
+		dto.ReceiverID,
+	)
+	if conversion != nil {
+		description = fmt.Sprintf(
+			"%s as %s at rate %s",
+			description,
+			conversion.Currency.Format(conversion.Amount),
+			conversion.Rate,
+		)
+	}
+
+	transactionDTO := transaction.CreateDTO{
+		Type:        transaction.Transfer,
+		SenderID:    dto.SenderID,
+		ReceiverID:  dto.ReceiverID,
+		Amount:      dto.Amount,
+		Currency:    dto.Currency,
+		Description: description,
+		Conversion:  conversion,
+	}
+
+	if err := s.transactionRepository.CreateTransaction(ctx, transactionDTO); err != nil {
+		return 0, 0, err
+	}
+
+	postings := []ledger.CreatePostingDTO{
+		ledger.NewTransferDTO(dto.SenderID, dto.ReceiverID, dto.Amount, dto.Currency, description),
+	}
+	if conversion != nil {
+		postings = ledger.NewExchangeDTOs(
+			dto.SenderID,
+			dto.Amount,
+			dto.Currency,
+			dto.ReceiverID,
+			dto.ReceiverAmount,
+			dto.ReceiverCurrency,
+			description,
+		)
+	}
+
+	for _, posting := range postings {
+		if err := s.ledgerRepository.CreatePosting(ctx, posting); err != nil {
+			return 0, 0, err
+		}
+	}
+
+	if err := s.outboxRepository.CreateEvent(ctx, outbox.NewTransferCompletedDTO(outbox.TransferCompletedPayload{
+		SenderID:         dto.SenderID,
+		ReceiverID:       dto.ReceiverID,
+		Amount:           dto.Amount,
+		Currency:         dto.Currency,
+		ReceiverAmount:   dto.ReceiverAmount,
+		ReceiverCurrency: dto.ReceiverCurrency,
+		SenderBalance:    senderBalance,
+		ReceiverBalance:  receiverBalance,
+	})); err != nil {
+		return 0, 0, err
 	}
 
 	return senderBalance, receiverBalance, nil
 }
+
+func (s *Service) convert(
+	ctx context.Context,
+	amount int64,
+	from, to currency.Currency,
+) (*transaction.Conversion, error) {
+	rate, err := s.rateProvider.GetRate(ctx, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("get rate: %w", err)
+	}
+
+	converted, remainder, err := rate.Convert(amount, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("convert %s to %s: %w", from, to, err)
+	}
+
+	return &transaction.Conversion{
+		Rate:      rate.String(),
+		Amount:    converted,
+		Currency:  to,
+		Remainder: remainder,
+	}, nil
+}
+
+func (s *Service) moveAccount(ctx context.Context, accountID int64, to Status) (account Account, err error) {
+	err = s.transactor.WithTx(ctx, func(ctx context.Context) error {
+		account, err = s.repository.GetAccountForUpdate(ctx, accountID)
+		if err != nil {
+			return err
+		}
+
+		if err := account.CheckTransition(to); err != nil {
+			return err
+		}
+
+		if to == Closed {
+			if err := s.checkEmpty(ctx, accountID); err != nil {
+				return err
+			}
+		}
+
+		if err := s.repository.UpdateStatus(ctx, UpdateStatusDTO{
+			AccountID: accountID,
+			From:      account.Status,
+			To:        to,
+		}); err != nil {
+			return err
+		}
+
+		account.Status = to
+
+		return nil
+	})
+	if err != nil {
+		return Account{}, err
+	}
+
+	return account, nil
+}
+
+func (s *Service) checkEmpty(ctx context.Context, accountID int64) error {
+	hasBalance, err := s.repository.HasBalance(ctx, accountID)
+	if err != nil {
+		return err
+	}
+	if hasBalance {
+		return fmt.Errorf("account with id = %d: %w", accountID, ErrNonZeroBalance)
+	}
+
+	hasReservations, err := s.repository.HasOpenReservations(ctx, accountID)
+	if err != nil {
+		return err
+	}
+	if hasReservations {
+		return fmt.Errorf("account with id = %d: %w", accountID, ErrOpenReservations)
+	}
+
+	return nil
+}
@@ -10,6 +10,9 @@ import (
 
 	"github.com/golang/mock/gomock"
 	"github.com/maypok86/payment-api/internal/domain/account"
+	"github.com/maypok86/payment-api/internal/domain/currency"
+	"github.com/maypok86/payment-api/internal/domain/ledger"
+	"github.com/maypok86/payment-api/internal/domain/outbox"
 	"github.com/maypok86/payment-api/internal/domain/transaction"
 	"github.com/maypok86/payment-api/internal/pkg/logger"
 	"github.com/stretchr/testify/require"
@@ -33,7 +36,17 @@ func (ft fakeTransactor) WithTx(ctx context.Context, fn func(ctx context.Context
 	return err
 }
 
-func mockService(t *testing.T, txErr error) (*account.Service, *MockRepository, *MockTransactionRepository) {
+func mockService(
+	t *testing.T,
+	txErr error,
+) (
+	*account.Service,
+	*MockRepository,
+	*MockTransactionRepository,
+	*MockLedgerRepository,
+	*MockOutboxRepository,
+	*MockRateProvider,
+) {
 	t.Helper()
 
 	mockCtrl := gomock.NewController(t)
@@ -45,9 +58,20 @@ func mockService(t *testing.T, txErr error) (*account.Service, *MockRepository,
 
 	transactor := newFakeTransactor(txErr)
 	transactionRepository := NewMockTransactionRepository(mockCtrl)
-	service := account.NewService(transactor, repository, transactionRepository, l)
+	ledgerRepository := NewMockLedgerRepository(mockCtrl)
+	outboxRepository := NewMockOutboxRepository(mockCtrl)
+	rateProvider := NewMockRateProvider(mockCtrl)
+	service := account.NewService(
+		transactor,
+		repository,
+		transactionRepository,
+		ledgerRepository,
+		outboxRepository,
+		rateProvider,
+		l,
+	)
 
-	return service, repository, transactionRepository
+	return service, repository, transactionRepository, ledgerRepository, outboxRepository, rateProvider
 }
 
 func TestService_GetBalanceByID(t *testing.T) {
@@ -57,7 +81,9 @@ func TestService_GetBalanceByID(t *testing.T) {
 
 	fakeAccount := account.Account{
 		AccountID: 1,
-		Balance:   100,
+		Currency:  currency.USD,
+		Available: 100,
+		Reserved:  50,
 	}
 
 	type mockBehavior func(r *MockRepository)
@@ -70,30 +96,30 @@ func TestService_GetBalanceByID(t *testing.T) {
 		name    string
 		mock    mockBehavior
 		args    args
-		want    int64
+		want    account.Account
 		wantErr bool
 	}{
 		{
 			name: "success get balance by id",
 			mock: func(repository *MockRepository) {
-				repository.EXPECT().GetAccountByID(ctx, fakeAccount.AccountID).Return(fakeAccount, nil)
+				repository.EXPECT().GetAccountByID(ctx, fakeAccount.AccountID, fakeAccount.Currency).Return(fakeAccount, nil)
 			},
 			args: args{
 				accountID: fakeAccount.AccountID,
 			},
-			want: fakeAccount.Balance,
+			want: fakeAccount,
 		},
 		{
 			name: "repository error",
 			mock: func(repository *MockRepository) {
 				repository.EXPECT().
-					GetAccountByID(ctx, fakeAccount.AccountID).
+					GetAccountByID(ctx, fakeAccount.AccountID, fakeAccount.Currency).
 					Return(account.Account{}, errors.New("get account by id repository error"))
 			},
 			args: args{
 				accountID: fakeAccount.AccountID,
 			},
-			want:    0,
+			want:    account.Account{},
 			wantErr: true,
 		},
 	}
@@ -104,11 +130,11 @@ func TestService_GetBalanceByID(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			t.Parallel()
 
-			service, repository, _ := mockService(t, nil)
+			service, repository, _, _, _, _ := mockService(t, nil)
 
 			tt.mock(repository)
 
-			got, err := service.GetBalanceByID(ctx, tt.args.accountID)
+			got, err := service.GetBalanceByID(ctx, tt.args.accountID, fakeAccount.Currency)
 			require.True(t, (err != nil) == tt.wantErr)
 			require.True(t, reflect.DeepEqual(tt.want, got))
 		})
@@ -122,29 +148,49 @@ func TestService_AddBalance(t *testing.T) {
 
 	fakeAccount := account.Account{
 		AccountID: 1,
-		Balance:   1,
+		Available: 1,
 	}
 	dto := account.AddBalanceDTO{
 		AccountID: 1,
 		Amount:    100,
+		Currency:  currency.RUB,
 	}
 	transactionDTO := transaction.CreateDTO{
 		Type:        transaction.Enrollment,
 		SenderID:    dto.AccountID,
 		ReceiverID:  dto.AccountID,
 		Amount:      dto.Amount,
-		Description: fmt.Sprintf("Add %d kopecks to account with id = %d", dto.Amount, dto.AccountID),
+		Currency:    dto.Currency,
+		Description: fmt.Sprintf("Add %s to account with id = %d", dto.Currency.Format(dto.Amount), dto.AccountID),
+	}
+	postingDTO := ledger.NewEnrollmentDTO(dto.AccountID, dto.Amount, dto.Currency, transactionDTO.Description)
+	eventDTO := outbox.NewBalanceCreditedDTO(outbox.BalanceCreditedPayload{
+		AccountID: dto.AccountID,
+		Amount:    dto.Amount,
+		Currency:  dto.Currency,
+		Balance:   fakeAccount.Available + dto.Amount,
+	})
+	zeroDTO := account.AddBalanceDTO{
+		AccountID: 2,
+		Currency:  currency.RUB,
 	}
 	txErr := errors.New("transaction error")
 	repositoryErr := errors.New("repository error")
 	transactionRepositoryErr := errors.New("transaction repository error")
+	ledgerRepositoryErr := errors.New("ledger repository error")
+	outboxRepositoryErr := errors.New("outbox repository error")
 
 	type args struct {
 		dto            account.AddBalanceDTO
 		transactionDTO transaction.CreateDTO
 	}
 
-	type mockBehavior func(r *MockRepository, tr *MockTransactionRepository)
+	type mockBehavior func(
+		r *MockRepository,
+		tr *MockTransactionRepository,
+		lr *MockLedgerRepository,
+		or *MockOutboxRepository,
+	)
 
 	tests := []struct {
 		name      string
@@ -156,21 +202,70 @@ func TestService_AddBalance(t *testing.T) {
 	}{
 		{
 			name: "success add balance",
-			mock: func(repository *MockRepository, transactionRepository *MockTransactionRepository) {
-				repository.EXPECT().AddBalance(ctx, dto).Return(fakeAccount.Balance+dto.Amount, nil)
+			mock: func(
+				repository *MockRepository,
+				transactionRepository *MockTransactionRepository,
+				ledgerRepository *MockLedgerRepository,
+				outboxRepository *MockOutboxRepository,
+			) {
+				repository.EXPECT().AddBalance(ctx, dto).Return(fakeAccount.Available+dto.Amount, nil)
 				transactionRepository.EXPECT().CreateTransaction(ctx, transactionDTO).Return(nil)
+				ledgerRepository.EXPECT().CreatePosting(ctx, postingDTO).Return(nil)
+				outboxRepository.EXPECT().CreateEvent(ctx, eventDTO).Return(nil)
 			},
 			args: args{
 				dto:            dto,
 				transactionDTO: transactionDTO,
 			},
-			want:      fakeAccount.Balance + dto.Amount,
+			want:      fakeAccount.Available + dto.Amount,
+			wantedErr: nil,
+			txErr:     nil,
+		},
+		{
+			name: "zero amount only opens account",
+			mock: func(
+				repository *MockRepository,
+				transactionRepository *MockTransactionRepository,
+				ledgerRepository *MockLedgerRepository,
+				outboxRepository *MockOutboxRepository,
+			) {
+				repository.EXPECT().AddBalance(ctx, zeroDTO).Return(int64(0), nil)
+			},
+			args: args{
+				dto: zeroDTO,
+			},
+			want:      0,
 			wantedErr: nil,
 			txErr:     nil,
 		},
+		{
+			name: "amount does not fit currency",
+			mock: func(
+				repository *MockRepository,
+				transactionRepository *MockTransactionRepository,
+				ledgerRepository *MockLedgerRepository,
+				outboxRepository *MockOutboxRepository,
+			) {
+			},
+			args: args{
+				dto: account.AddBalanceDTO{
+					AccountID: 1,
+					Amount:    currency.JPY.MaxAmount() + 1,
+					Currency:  currency.JPY,
+				},
+			},
+			want:      0,
+			wantedErr: currency.ErrInvalidAmount,
+			txErr:     nil,
+		},
 		{
 			name: "repository error",
-			mock: func(repository *MockRepository, transactionRepository *MockTransactionRepository) {
+			mock: func(
+				repository *MockRepository,
+				transactionRepository *MockTransactionRepository,
+				ledgerRepository *MockLedgerRepository,
+				outboxRepository *MockOutboxRepository,
+			) {
 				repository.EXPECT().AddBalance(ctx, dto).Return(int64(0), repositoryErr)
 			},
 			args: args{
@@ -183,8 +278,13 @@ func TestService_AddBalance(t *testing.T) {
 		},
 		{
 			name: "transaction repository error",
-			mock: func(repository *MockRepository, transactionRepository *MockTransactionRepository) {
-				repository.EXPECT().AddBalance(ctx, dto).Return(fakeAccount.Balance+dto.Amount, nil)
+			mock: func(
+				repository *MockRepository,
+				transactionRepository *MockTransactionRepository,
+				ledgerRepository *MockLedgerRepository,
+				outboxRepository *MockOutboxRepository,
+			) {
+				repository.EXPECT().AddBalance(ctx, dto).Return(fakeAccount.Available+dto.Amount, nil)
 				transactionRepository.EXPECT().CreateTransaction(ctx, transactionDTO).Return(transactionRepositoryErr)
 			},
 			args: args{
@@ -195,11 +295,59 @@ func TestService_AddBalance(t *testing.T) {
 			wantedErr: transactionRepositoryErr,
 			txErr:     nil,
 		},
+		{
+			name: "ledger repository error",
+			mock: func(
+				repository *MockRepository,
+				transactionRepository *MockTransactionRepository,
+				ledgerRepository *MockLedgerRepository,
+				outboxRepository *MockOutboxRepository,
+			) {
+				repository.EXPECT().AddBalance(ctx, dto).Return(fakeAccount.Available+dto.Amount, nil)
+				transactionRepository.EXPECT().CreateTransaction(ctx, transactionDTO).Return(nil)
+				ledgerRepository.EXPECT().CreatePosting(ctx, postingDTO).Return(ledgerRepositoryErr)
+			},
+			args: args{
+				dto:            dto,
+				transactionDTO: transactionDTO,
+			},
+			want:      0,
+			wantedErr: ledgerRepositoryErr,
+			txErr:     nil,
+		},
+		{
+			name: "outbox repository error",
+			mock: func(
+				repository *MockRepository,
+				transactionRepository *MockTransactionRepository,
+				ledgerRepository *MockLedgerRepository,
+				outboxRepository *MockOutboxRepository,
+			) {
+				repository.EXPECT().AddBalance(ctx, dto).Return(fakeAccount.Available+dto.Amount, nil)
+				transactionRepository.EXPECT().CreateTransaction(ctx, transactionDTO).Return(nil)
+				ledgerRepository.EXPECT().CreatePosting(ctx, postingDTO).Return(nil)
+				outboxRepository.EXPECT().CreateEvent(ctx, eventDTO).Return(outboxRepositoryErr)
+			},
+			args: args{
+				dto:            dto,
+				transactionDTO: transactionDTO,
+			},
+			want:      0,
+			wantedErr: outboxRepositoryErr,
+			txErr:     nil,
+		},
 		{
 			name: "transaction error",
-			mock: func(repository *MockRepository, transactionRepository *MockTransactionRepository) {
-				repository.EXPECT().AddBalance(ctx, dto).Return(fakeAccount.Balance+dto.Amount, nil)
+			mock: func(
+				repository *MockRepository,
+				transactionRepository *MockTransactionRepository,
+				ledgerRepository *MockLedgerRepository,
+				outboxRepository *MockOutboxRepository,
+			) {
+				repository.EXPECT().AddBalance(ctx, dto).Return(fakeAccount.Available+dto.Amount, nil)
 				transactionRepository.EXPECT().CreateTransaction(ctx, transactionDTO).Return(nil)
+				ledgerRepository.EXPECT().CreatePosting(ctx, postingDTO).Return(nil)
+				outboxRepository.EXPECT().CreateEvent(ctx, eventDTO).Return(nil)
 			},
 			args: args{
 				dto:            dto,
@@ -217,9 +365,9 @@ func TestService_AddBalance(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			t.Parallel()
 
-			service, repository, transactionRepository := mockService(t, tt.txErr)
+			service, repository, transactionRepository, ledgerRepository, outboxRepository, _ := mockService(t, tt.txErr)
 
-			tt.mock(repository, transactionRepository)
+			tt.mock(repository, transactionRepository, ledgerRepository, outboxRepository)
 			got, err := service.AddBalance(ctx, tt.args.dto)
 			if tt.wantedErr != nil {
 				require.ErrorIs(t, err, tt.wantedErr)
@@ -239,39 +387,62 @@ func TestService_TransferBalance(t *testing.T) {
 
 	senderAccount := account.Account{
 		AccountID: 1,
-		Balance:   100,
+		Available: 100,
 	}
 	receiverAccount := account.Account{
 		AccountID: 2,
-		Balance:   10,
+		Available: 10,
 	}
 	dto := account.TransferBalanceDTO{
 		SenderID:   senderAccount.AccountID,
 		ReceiverID: receiverAccount.AccountID,
 		Amount:     50,
+		Currency:   currency.RUB,
 	}
+	repositoryDTO := dto
+	repositoryDTO.ReceiverAmount = dto.Amount
+	repositoryDTO.ReceiverCurrency = dto.Currency
 	transactionDTO := transaction.CreateDTO{
 		Type:       transaction.Transfer,
 		SenderID:   dto.SenderID,
 		ReceiverID: dto.ReceiverID,
 		Amount:     dto.Amount,
+		Currency:   dto.Currency,
 		Description: fmt.Sprintf(
-			"Transfer %d kopecks from account with id = %d to account with id = %d",
-			dto.Amount,
+			"Transfer %s from account with id = %d to account with id = %d",
+			dto.Currency.Format(dto.Amount),
 			dto.SenderID,
 			dto.ReceiverID,
 		),
 	}
+	postingDTO := ledger.NewTransferDTO(dto.SenderID, dto.ReceiverID, dto.Amount, dto.Currency, transactionDTO.Description)
+	eventDTO := outbox.NewTransferCompletedDTO(outbox.TransferCompletedPayload{
+		SenderID:         dto.SenderID,
+		ReceiverID:       dto.ReceiverID,
+		Amount:           dto.Amount,
+		Currency:         dto.Currency,
+		ReceiverAmount:   dto.Amount,
+		ReceiverCurrency: dto.Currency,
+		SenderBalance:    senderAccount.Available - dto.Amount,
+		ReceiverBalance:  receiverAccount.Available + dto.Amount,
+	})
 	txErr := errors.New("transaction error")
 	repositoryErr := errors.New("repository error")
 	transactionRepositoryErr := errors.New("transaction repository error")
+	ledgerRepositoryErr := errors.New("ledger repository error")
+	outboxRepositoryErr := errors.New("outbox repository error")
 
 	type args struct {
 		dto            account.TransferBalanceDTO
 		transactionDTO transaction.CreateDTO
 	}
 
-	type mockBehavior func(r *MockRepository, tr *MockTransactionRepository)
+	type mockBehavior func(
+		r *MockRepository,
+		tr *MockTransactionRepository,
+		lr *MockLedgerRepository,
+		or *MockOutboxRepository,
+	)
 
 	type balancies struct {
 		senderBalance   int64
@@ -288,27 +459,39 @@ func TestService_TransferBalance(t *testing.T) {
 	}{
 		{
 			name: "success transfer balance",
-			mock: func(repository *MockRepository, transactionRepository *MockTransactionRepository) {
+			mock: func(
+				repository *MockRepository,
+				transactionRepository *MockTransactionRepository,
+				ledgerRepository *MockLedgerRepository,
+				outboxRepository *MockOutboxRepository,
+			) {
 				repository.EXPECT().
-					TransferBalance(ctx, dto).
-					Return(senderAccount.Balance-dto.Amount, receiverAccount.Balance+dto.Amount, nil)
+					TransferBalance(ctx, repositoryDTO).
+					Return(senderAccount.Available-dto.Amount, receiverAccount.Available+dto.Amount, nil)
 				transactionRepository.EXPECT().CreateTransaction(ctx, transactionDTO).Return(nil)
+				ledgerRepository.EXPECT().CreatePosting(ctx, postingDTO).Return(nil)
+				outboxRepository.EXPECT().CreateEvent(ctx, eventDTO).Return(nil)
 			},
 			args: args{
 				dto:            dto,
 				transactionDTO: transactionDTO,
 			},
 			want: balancies{
-				senderBalance:   senderAccount.Balance - dto.Amount,
-				receiverBalance: receiverAccount.Balance + dto.Amount,
+				senderBalance:   senderAccount.Available - dto.Amount,
+				receiverBalance: receiverAccount.Available + dto.Amount,
 			},
 			wantedErr: nil,
 			txErr:     nil,
 		},
 		{
 			name: "repository error",
-			mock: func(repository *MockRepository, transactionRepository *MockTransactionRepository) {
-				repository.EXPECT().TransferBalance(ctx, dto).Return(int64(0), int64(0), repositoryErr)
+			mock: func(
+				repository *MockRepository,
+				transactionRepository *MockTransactionRepository,
+				ledgerRepository *MockLedgerRepository,
+				outboxRepository *MockOutboxRepository,
+			) {
+				repository.EXPECT().TransferBalance(ctx, repositoryDTO).Return(int64(0), int64(0), repositoryErr)
 			},
 			args: args{
 				dto:            dto,
@@ -320,10 +503,15 @@ func TestService_TransferBalance(t *testing.T) {
 		},
 		{
 			name: "transaction repository error",
-			mock: func(repository *MockRepository, transactionRepository *MockTransactionRepository) {
+			mock: func(
+				repository *MockRepository,
+				transactionRepository *MockTransactionRepository,
+				ledgerRepository *MockLedgerRepository,
+				outboxRepository *MockOutboxRepository,
+			) {
 				repository.EXPECT().
-					TransferBalance(ctx, dto).
-					Return(senderAccount.Balance-dto.Amount, receiverAccount.Balance+dto.Amount, nil)
+					TransferBalance(ctx, repositoryDTO).
+					Return(senderAccount.Available-dto.Amount, receiverAccount.Available+dto.Amount, nil)
 				transactionRepository.EXPECT().CreateTransaction(ctx, transactionDTO).Return(transactionRepositoryErr)
 			},
 			args: args{
@@ -334,13 +522,65 @@ func TestService_TransferBalance(t *testing.T) {
 			wantedErr: transactionRepositoryErr,
 			txErr:     nil,
 		},
+		{
+			name: "ledger repository error",
+			mock: func(
+				repository *MockRepository,
+				transactionRepository *MockTransactionRepository,
+				ledgerRepository *MockLedgerRepository,
+				outboxRepository *MockOutboxRepository,
+			) {
+				repository.EXPECT().
+					TransferBalance(ctx, repositoryDTO).
+					Return(senderAccount.Available-dto.Amount, receiverAccount.Available+dto.Amount, nil)
+				transactionRepository.EXPECT().CreateTransaction(ctx, transactionDTO).Return(nil)
+				ledgerRepository.EXPECT().CreatePosting(ctx, postingDTO).Return(ledgerRepositoryErr)
+			},
+			args: args{
+				dto:            dto,
+				transactionDTO: transactionDTO,
+			},
+			want:      balancies{},
+			wantedErr: ledgerRepositoryErr,
+			txErr:     nil,
+		},
+		{
+			name: "outbox repository error",
+			mock: func(
+				repository *MockRepository,
+				transactionRepository *MockTransactionRepository,
+				ledgerRepository *MockLedgerRepository,
+				outboxRepository *MockOutboxRepository,
+			) {
+				repository.EXPECT().
+					TransferBalance(ctx, repositoryDTO).
+					Return(senderAccount.Available-dto.Amount, receiverAccount.Available+dto.Amount, nil)
+				transactionRepository.EXPECT().CreateTransaction(ctx, transactionDTO).Return(nil)
+				ledgerRepository.EXPECT().CreatePosting(ctx, postingDTO).Return(nil)
+				outboxRepository.EXPECT().CreateEvent(ctx, eventDTO).Return(outboxRepositoryErr)
+			},
+			args: args{
+				dto:            dto,
+				transactionDTO: transactionDTO,
+			},
+			want:      balancies{},
+			wantedErr: outboxRepositoryErr,
+			txErr:     nil,
+		},
 		{
 			name: "transaction error",
-			mock: func(repository *MockRepository, transactionRepository *MockTransactionRepository) {
+			mock: func(
+				repository *MockRepository,
+				transactionRepository *MockTransactionRepository,
+				ledgerRepository *MockLedgerRepository,
+				outboxRepository *MockOutboxRepository,
+			) {
 				repository.EXPECT().
-					TransferBalance(ctx, dto).
-					Return(senderAccount.Balance-dto.Amount, receiverAccount.Balance+dto.Amount, nil)
+					TransferBalance(ctx, repositoryDTO).
+					Return(senderAccount.Available-dto.Amount, receiverAccount.Available+dto.Amount, nil)
 				transactionRepository.EXPECT().CreateTransaction(ctx, transactionDTO).Return(nil)
+				ledgerRepository.EXPECT().CreatePosting(ctx, postingDTO).Return(nil)
+				outboxRepository.EXPECT().CreateEvent(ctx, eventDTO).Return(nil)
 			},
 			args: args{
 				dto:            dto,
@@ -358,9 +598,9 @@ func TestService_TransferBalance(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			t.Parallel()
 
-			service, repository, transactionRepository := mockService(t, tt.txErr)
+			service, repository, transactionRepository, ledgerRepository, outboxRepository, _ := mockService(t, tt.txErr)
 
-			tt.mock(repository, transactionRepository)
+			tt.mock(repository, transactionRepository, ledgerRepository, outboxRepository)
 			gotSenderBalance, gotReceiverBalance, err := service.TransferBalance(ctx, tt.args.dto)
 			if tt.wantedErr != nil {
 				require.ErrorIs(t, err, tt.wantedErr)
@@ -373,3 +613,713 @@ func TestService_TransferBalance(t *testing.T) {
 		})
 	}
 }
+
+func TestService_TransferBalanceWithConversion(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	rate, err := account.ParseRate("61.5")
+	require.NoError(t, err)
+
+	dto := account.TransferBalanceDTO{
+		SenderID:         1,
+		ReceiverID:       2,
+		Amount:           101,
+		Currency:         currency.USD,
+		ReceiverCurrency: currency.RUB,
+	}
+	repositoryDTO := dto
+	repositoryDTO.ReceiverAmount = 6211
+	conversion := &transaction.Conversion{
+		Rate:      "61.5",
+		Amount:    6211,
+		Currency:  currency.RUB,
+		Remainder: "0.5",
+	}
+	transactionDTO := transaction.CreateDTO{
+		Type:       transaction.Transfer,
+		SenderID:   dto.SenderID,
+		ReceiverID: dto.ReceiverID,
+		Amount:     dto.Amount,
+		Currency:   dto.Currency,
+		Description: "Transfer 1.01 USD from account with id = 1 to account with id = 2 " +
+			"as 62.11 RUB at rate 61.5",
+		Conversion: conversion,
+	}
+	postingDTOs := ledger.NewExchangeDTOs(
+		dto.SenderID,
+		dto.Amount,
+		dto.Currency,
+		dto.ReceiverID,
+		repositoryDTO.ReceiverAmount,
+		dto.ReceiverCurrency,
+		transactionDTO.Description,
+	)
+	eventDTO := outbox.NewTransferCompletedDTO(outbox.TransferCompletedPayload{
+		SenderID:         dto.SenderID,
+		ReceiverID:       dto.ReceiverID,
+		Amount:           dto.Amount,
+		Currency:         dto.Currency,
+		ReceiverAmount:   repositoryDTO.ReceiverAmount,
+		ReceiverCurrency: dto.ReceiverCurrency,
+		ReceiverBalance:  6211,
+	})
+	ledgerRepositoryErr := errors.New("ledger repository error")
+
+	type mockBehavior func(
+		r *MockRepository,
+		tr *MockTransactionRepository,
+		lr *MockLedgerRepository,
+		or *MockOutboxRepository,
+		rp *MockRateProvider,
+	)
+
+	tests := []struct {
+		name      string
+		mock      mockBehavior
+		dto       account.TransferBalanceDTO
+		want      int64
+		wantedErr error
+	}{
+		{
+			name: "success transfer with conversion",
+			mock: func(
+				repository *MockRepository,
+				transactionRepository *MockTransactionRepository,
+				ledgerRepository *MockLedgerRepository,
+				outboxRepository *MockOutboxRepository,
+				rateProvider *MockRateProvider,
+			) {
+				rateProvider.EXPECT().GetRate(ctx, currency.USD, currency.RUB).Return(rate, nil)
+				repository.EXPECT().TransferBalance(ctx, repositoryDTO).Return(int64(0), int64(6211), nil)
+				transactionRepository.EXPECT().CreateTransaction(ctx, transactionDTO).Return(nil)
+				ledgerRepository.EXPECT().CreatePosting(ctx, postingDTOs[0]).Return(nil)
+				ledgerRepository.EXPECT().CreatePosting(ctx, postingDTOs[1]).Return(nil)
+				outboxRepository.EXPECT().CreateEvent(ctx, eventDTO).Return(nil)
+			},
+			dto:  dto,
+			want: 6211,
+		},
+		{
+			name: "rate not found",
+			mock: func(
+				repository *MockRepository,
+				transactionRepository *MockTransactionRepository,
+				ledgerRepository *MockLedgerRepository,
+				outboxRepository *MockOutboxRepository,
+				rateProvider *MockRateProvider,
+			) {
+				rateProvider.EXPECT().
+					GetRate(ctx, currency.USD, currency.RUB).
+					Return(account.Rate{}, account.ErrRateNotFound)
+			},
+			dto:       dto,
+			wantedErr: account.ErrRateNotFound,
+		},
+		{
+			name: "converted amount is too small",
+			mock: func(
+				repository *MockRepository,
+				transactionRepository *MockTransactionRepository,
+				ledgerRepository *MockLedgerRepository,
+				outboxRepository *MockOutboxRepository,
+				rateProvider *MockRateProvider,
+			) {
+				smallRate, err := account.ParseRate("0.001")
+				require.NoError(t, err)
+
+				rateProvider.EXPECT().GetRate(ctx, currency.USD, currency.RUB).Return(smallRate, nil)
+			},
+			dto:       dto,
+			wantedErr: account.ErrAmountTooSmall,
+		},
+		{
+			name: "ledger repository error",
+			mock: func(
+				repository *MockRepository,
+				transactionRepository *MockTransactionRepository,
+				ledgerRepository *MockLedgerRepository,
+				outboxRepository *MockOutboxRepository,
+				rateProvider *MockRateProvider,
+			) {
+				rateProvider.EXPECT().GetRate(ctx, currency.USD, currency.RUB).Return(rate, nil)
+				repository.EXPECT().TransferBalance(ctx, repositoryDTO).Return(int64(0), int64(6211), nil)
+				transactionRepository.EXPECT().CreateTransaction(ctx, transactionDTO).Return(nil)
+				ledgerRepository.EXPECT().CreatePosting(ctx, postingDTOs[0]).Return(ledgerRepositoryErr)
+			},
+			dto:       dto,
+			wantedErr: ledgerRepositoryErr,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			service, repository, transactionRepository, ledgerRepository, outboxRepository, rateProvider := mockService(
+				t,
+				nil,
+			)
+
+			tt.mock(repository, transactionRepository, ledgerRepository, outboxRepository, rateProvider)
+			_, gotReceiverBalance, err := service.TransferBalance(ctx, tt.dto)
+			if tt.wantedErr != nil {
+				require.ErrorIs(t, err, tt.wantedErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, gotReceiverBalance)
+		})
+	}
+}
+
+func TestService_TransferBatch(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	rate, err := account.ParseRate("61.5")
+	require.NoError(t, err)
+
+	dto := account.TransferBatchDTO{
+		Transfers: []account.TransferBalanceDTO{
+			{SenderID: 3, ReceiverID: 2, Amount: 100, Currency: currency.USD, ReceiverCurrency: currency.RUB},
+			{SenderID: 3, ReceiverID: 1, Amount: 50, Currency: currency.USD},
+		},
+	}
+	legs := []account.TransferBalanceDTO{
+		{
+			SenderID:         3,
+			ReceiverID:       2,
+			Amount:           100,
+			Currency:         currency.USD,
+			ReceiverAmount:   6150,
+			ReceiverCurrency: currency.RUB,
+		},
+		{
+			SenderID:         3,
+			ReceiverID:       1,
+			Amount:           50,
+			Currency:         currency.USD,
+			ReceiverAmount:   50,
+			ReceiverCurrency: currency.USD,
+		},
+	}
+	balances := []account.BalanceKey{
+		{AccountID: 1, Currency: currency.USD},
+		{AccountID: 2, Currency: currency.RUB},
+		{AccountID: 3, Currency: currency.USD},
+	}
+	insufficientFundsErr := &account.InsufficientFundsError{AccountID: 3, Currency: currency.USD}
+	repositoryErr := errors.New("repository error")
+	txErr := errors.New("transaction error")
+
+	type mockBehavior func(
+		r *MockRepository,
+		tr *MockTransactionRepository,
+		lr *MockLedgerRepository,
+		or *MockOutboxRepository,
+		rp *MockRateProvider,
+	)
+
+	tests := []struct {
+		name      string
+		mock      mockBehavior
+		dto       account.TransferBatchDTO
+		want      []account.TransferResult
+		wantItems []int
+		wantedErr error
+		txErr     error
+	}{
+		{
+			name: "success transfer batch",
+			mock: func(
+				repository *MockRepository,
+				transactionRepository *MockTransactionRepository,
+				ledgerRepository *MockLedgerRepository,
+				outboxRepository *MockOutboxRepository,
+				rateProvider *MockRateProvider,
+			) {
+				rateProvider.EXPECT().GetRate(ctx, currency.USD, currency.RUB).Return(rate, nil)
+				gomock.InOrder(
+					repository.EXPECT().LockBalances(ctx, balances).Return(nil),
+					repository.EXPECT().TransferBalance(ctx, legs[0]).Return(int64(900), int64(6150), nil),
+					repository.EXPECT().TransferBalance(ctx, legs[1]).Return(int64(850), int64(50), nil),
+				)
+				transactionRepository.EXPECT().CreateTransaction(ctx, gomock.Any()).Return(nil).Times(2)
+				ledgerRepository.EXPECT().CreatePosting(ctx, gomock.Any()).Return(nil).Times(3)
+				outboxRepository.EXPECT().CreateEvent(ctx, gomock.Any()).Return(nil).Times(2)
+			},
+			dto: dto,
+			want: []account.TransferResult{
+				{SenderBalance: 900, ReceiverBalance: 6150},
+				{SenderBalance: 850, ReceiverBalance: 50},
+			},
+		},
+		{
+			name: "empty batch",
+			mock: func(
+				repository *MockRepository,
+				transactionRepository *MockTransactionRepository,
+				ledgerRepository *MockLedgerRepository,
+				outboxRepository *MockOutboxRepository,
+				rateProvider *MockRateProvider,
+			) {
+			},
+			dto:       account.TransferBatchDTO{},
+			wantedErr: account.ErrEmptyBatch,
+		},
+		{
+			name: "batch is too large",
+			mock: func(
+				repository *MockRepository,
+				transactionRepository *MockTransactionRepository,
+				ledgerRepository *MockLedgerRepository,
+				outboxRepository *MockOutboxRepository,
+				rateProvider *MockRateProvider,
+			) {
+			},
+			dto: account.TransferBatchDTO{
+				Transfers: make([]account.TransferBalanceDTO, account.MaxBatchSize+1),
+			},
+			wantedErr: account.ErrBatchTooLarge,
+		},
+		{
+			name: "rate not found",
+			mock: func(
+				repository *MockRepository,
+				transactionRepository *MockTransactionRepository,
+				ledgerRepository *MockLedgerRepository,
+				outboxRepository *MockOutboxRepository,
+				rateProvider *MockRateProvider,
+			) {
+				rateProvider.EXPECT().
+					GetRate(ctx, currency.USD, currency.RUB).
+					Return(account.Rate{}, account.ErrRateNotFound)
+			},
+			dto:       dto,
+			wantItems: []int{0},
+			wantedErr: account.ErrRateNotFound,
+		},
+		{
+			name: "insufficient funds",
+			mock: func(
+				repository *MockRepository,
+				transactionRepository *MockTransactionRepository,
+				ledgerRepository *MockLedgerRepository,
+				outboxRepository *MockOutboxRepository,
+				rateProvider *MockRateProvider,
+			) {
+				rateProvider.EXPECT().GetRate(ctx, currency.USD, currency.RUB).Return(rate, nil)
+				repository.EXPECT().LockBalances(ctx, balances).Return(nil)
+				repository.EXPECT().TransferBalance(ctx, legs[0]).Return(int64(900), int64(6150), nil)
+				repository.EXPECT().TransferBalance(ctx, legs[1]).Return(int64(0), int64(0), insufficientFundsErr)
+				transactionRepository.EXPECT().CreateTransaction(ctx, gomock.Any()).Return(nil)
+				ledgerRepository.EXPECT().CreatePosting(ctx, gomock.Any()).Return(nil).Times(2)
+				outboxRepository.EXPECT().CreateEvent(ctx, gomock.Any()).Return(nil)
+			},
+			dto:       dto,
+			wantItems: []int{1},
+			wantedErr: account.ErrInsufficientFunds,
+		},
+		{
+			name: "lock balances error",
+			mock: func(
+				repository *MockRepository,
+				transactionRepository *MockTransactionRepository,
+				ledgerRepository *MockLedgerRepository,
+				outboxRepository *MockOutboxRepository,
+				rateProvider *MockRateProvider,
+			) {
+				rateProvider.EXPECT().GetRate(ctx, currency.USD, currency.RUB).Return(rate, nil)
+				repository.EXPECT().LockBalances(ctx, balances).Return(repositoryErr)
+			},
+			dto:       dto,
+			wantedErr: repositoryErr,
+		},
+		{
+			name: "transaction error",
+			mock: func(
+				repository *MockRepository,
+				transactionRepository *MockTransactionRepository,
+				ledgerRepository *MockLedgerRepository,
+				outboxRepository *MockOutboxRepository,
+				rateProvider *MockRateProvider,
+			) {
+				rateProvider.EXPECT().GetRate(ctx, currency.USD, currency.RUB).Return(rate, nil)
+				repository.EXPECT().LockBalances(ctx, balances).Return(nil)
+				repository.EXPECT().TransferBalance(ctx, gomock.Any()).Return(int64(0), int64(0), nil).Times(2)
+				transactionRepository.EXPECT().CreateTransaction(ctx, gomock.Any()).Return(nil).Times(2)
+				ledgerRepository.EXPECT().CreatePosting(ctx, gomock.Any()).Return(nil).Times(3)
+				outboxRepository.EXPECT().CreateEvent(ctx, gomock.Any()).Return(nil).Times(2)
+			},
+			dto:   dto,
+			txErr: txErr,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			service, repository, transactionRepository, ledgerRepository, outboxRepository, rateProvider := mockService(
+				t,
+				tt.txErr,
+			)
+
+			tt.mock(repository, transactionRepository, ledgerRepository, outboxRepository, rateProvider)
+			got, err := service.TransferBatch(ctx, tt.dto)
+			if tt.txErr != nil {
+				require.ErrorIs(t, err, tt.txErr)
+				require.Nil(t, got)
+				return
+			}
+			if tt.wantedErr != nil {
+				require.ErrorIs(t, err, tt.wantedErr)
+				require.Nil(t, got)
+
+				var batchErr *account.BatchError
+				if tt.wantItems == nil {
+					require.False(t, errors.As(err, &batchErr))
+					return
+				}
+				require.ErrorAs(t, err, &batchErr)
+				gotItems := make([]int, 0, len(batchErr.Items))
+				for _, item := range batchErr.Items {
+					gotItems = append(gotItems, item.Index)
+				}
+				require.Equal(t, tt.wantItems, gotItems)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestService_CreateAccount(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	fakeAccount := account.Account{
+		AccountID: 1,
+		Status:    account.Active,
+	}
+
+	tests := []struct {
+		name      string
+		mock      func(r *MockRepository)
+		want      account.Account
+		wantedErr error
+	}{
+		{
+			name: "success create account",
+			mock: func(repository *MockRepository) {
+				repository.EXPECT().CreateAccount(ctx, fakeAccount.AccountID).Return(fakeAccount, nil)
+			},
+			want: fakeAccount,
+		},
+		{
+			name: "account already exist",
+			mock: func(repository *MockRepository) {
+				repository.EXPECT().
+					CreateAccount(ctx, fakeAccount.AccountID).
+					Return(account.Account{}, account.ErrAlreadyExist)
+			},
+			wantedErr: account.ErrAlreadyExist,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			service, repository, _, _, _, _ := mockService(t, nil)
+
+			tt.mock(repository)
+
+			got, err := service.CreateAccount(ctx, fakeAccount.AccountID)
+			if tt.wantedErr != nil {
+				require.ErrorIs(t, err, tt.wantedErr)
+			}
+			require.True(t, reflect.DeepEqual(tt.want, got))
+		})
+	}
+}
+
+func TestService_MoveAccount(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	const accountID int64 = 1
+
+	txErr := errors.New("transaction error")
+	repositoryErr := errors.New("repository error")
+
+	type moveFunc func(s *account.Service) (account.Account, error)
+
+	freeze := func(s *account.Service) (account.Account, error) {
+		return s.FreezeAccount(ctx, accountID)
+	}
+	unfreeze := func(s *account.Service) (account.Account, error) {
+		return s.UnfreezeAccount(ctx, accountID)
+	}
+	closeAccount := func(s *account.Service) (account.Account, error) {
+		return s.CloseAccount(ctx, accountID)
+	}
+
+	withStatus := func(status account.Status) account.Account {
+		return account.Account{AccountID: accountID, Status: status}
+	}
+
+	tests := []struct {
+		name      string
+		move      moveFunc
+		mock      func(r *MockRepository)
+		want      account.Account
+		wantedErr error
+		txErr     error
+	}{
+		{
+			name: "success freeze account",
+			move: freeze,
+			mock: func(repository *MockRepository) {
+				repository.EXPECT().GetAccountForUpdate(ctx, accountID).Return(withStatus(account.Active), nil)
+				repository.EXPECT().UpdateStatus(ctx, account.UpdateStatusDTO{
+					AccountID: accountID,
+					From:      account.Active,
+					To:        account.Frozen,
+				}).Return(nil)
+			},
+			want: withStatus(account.Frozen),
+		},
+		{
+			name: "freeze frozen account",
+			move: freeze,
+			mock: func(repository *MockRepository) {
+				repository.EXPECT().GetAccountForUpdate(ctx, accountID).Return(withStatus(account.Frozen), nil)
+			},
+			wantedErr: account.ErrIllegalTransition,
+		},
+		{
+			name: "success unfreeze account",
+			move: unfreeze,
+			mock: func(repository *MockRepository) {
+				repository.EXPECT().GetAccountForUpdate(ctx, accountID).Return(withStatus(account.Frozen), nil)
+				repository.EXPECT().UpdateStatus(ctx, account.UpdateStatusDTO{
+					AccountID: accountID,
+					From:      account.Frozen,
+					To:        account.Active,
+				}).Return(nil)
+			},
+			want: withStatus(account.Active),
+		},
+		{
+			name: "unfreeze closed account",
+			move: unfreeze,
+			mock: func(repository *MockRepository) {
+				repository.EXPECT().GetAccountForUpdate(ctx, accountID).Return(withStatus(account.Closed), nil)
+			},
+			wantedErr: account.ErrIllegalTransition,
+		},
+		{
+			name: "account not found",
+			move: freeze,
+			mock: func(repository *MockRepository) {
+				repository.EXPECT().GetAccountForUpdate(ctx, accountID).Return(account.Account{}, account.ErrNotFound)
+			},
+			wantedErr: account.ErrNotFound,
+		},
+		{
+			name: "success close account",
+			move: closeAccount,
+			mock: func(repository *MockRepository) {
+				repository.EXPECT().GetAccountForUpdate(ctx, accountID).Return(withStatus(account.Frozen), nil)
+				repository.EXPECT().HasBalance(ctx, accountID).Return(false, nil)
+				repository.EXPECT().HasOpenReservations(ctx, accountID).Return(false, nil)
+				repository.EXPECT().UpdateStatus(ctx, account.UpdateStatusDTO{
+					AccountID: accountID,
+					From:      account.Frozen,
+					To:        account.Closed,
+				}).Return(nil)
+			},
+			want: withStatus(account.Closed),
+		},
+		{
+			name: "close account with balance",
+			move: closeAccount,
+			mock: func(repository *MockRepository) {
+				repository.EXPECT().GetAccountForUpdate(ctx, accountID).Return(withStatus(account.Active), nil)
+				repository.EXPECT().HasBalance(ctx, accountID).Return(true, nil)
+			},
+			wantedErr: account.ErrNonZeroBalance,
+		},
+		{
+			name: "close account with open reservations",
+			move: closeAccount,
+			mock: func(repository *MockRepository) {
+				repository.EXPECT().GetAccountForUpdate(ctx, accountID).Return(withStatus(account.Active), nil)
+				repository.EXPECT().HasBalance(ctx, accountID).Return(false, nil)
+				repository.EXPECT().HasOpenReservations(ctx, accountID).Return(true, nil)
+			},
+			wantedErr: account.ErrOpenReservations,
+		},
+		{
+			name: "close closed account",
+			move: closeAccount,
+			mock: func(repository *MockRepository) {
+				repository.EXPECT().GetAccountForUpdate(ctx, accountID).Return(withStatus(account.Closed), nil)
+			},
+			wantedErr: account.ErrIllegalTransition,
+		},
+		{
+			name: "update status error",
+			move: freeze,
+			mock: func(repository *MockRepository) {
+				repository.EXPECT().GetAccountForUpdate(ctx, accountID).Return(withStatus(account.Active), nil)
+				repository.EXPECT().UpdateStatus(ctx, gomock.Any()).Return(repositoryErr)
+			},
+			wantedErr: repositoryErr,
+		},
+		{
+			name: "transaction error",
+			move: freeze,
+			mock: func(repository *MockRepository) {
+				repository.EXPECT().GetAccountForUpdate(ctx, accountID).Return(withStatus(account.Active), nil)
+				repository.EXPECT().UpdateStatus(ctx, gomock.Any()).Return(nil)
+			},
+			txErr: txErr,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			service, repository, _, _, _, _ := mockService(t, tt.txErr)
+
+			tt.mock(repository)
+
+			got, err := tt.move(service)
+			if tt.wantedErr != nil {
+				require.ErrorIs(t, err, tt.wantedErr)
+			}
+			if tt.txErr != nil {
+				require.ErrorIs(t, err, tt.txErr)
+			}
+			require.True(t, reflect.DeepEqual(tt.want, got))
+		})
+	}
+}
+
+func TestService_SetCreditLimit(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	dto := account.SetCreditLimitDTO{
+		AccountID:   1,
+		Currency:    currency.RUB,
+		CreditLimit: 1000,
+	}
+	fakeAccount := account.Account{
+		AccountID:   dto.AccountID,
+		Currency:    dto.Currency,
+		Available:   -200,
+		CreditLimit: dto.CreditLimit,
+	}
+	txErr := errors.New("transaction error")
+
+	tests := []struct {
+		name      string
+		mock      func(r *MockRepository)
+		want      account.Account
+		wantedErr error
+		txErr     error
+	}{
+		{
+			name: "success set credit limit",
+			mock: func(repository *MockRepository) {
+				repository.EXPECT().
+					GetAccountForUpdate(ctx, dto.AccountID).
+					Return(account.Account{AccountID: dto.AccountID, Status: account.Frozen}, nil)
+				repository.EXPECT().SetCreditLimit(ctx, dto).Return(fakeAccount, nil)
+			},
+			want: account.Account{
+				AccountID:   fakeAccount.AccountID,
+				Status:      account.Frozen,
+				Currency:    fakeAccount.Currency,
+				Available:   fakeAccount.Available,
+				CreditLimit: fakeAccount.CreditLimit,
+			},
+		},
+		{
+			name: "account not found",
+			mock: func(repository *MockRepository) {
+				repository.EXPECT().GetAccountForUpdate(ctx, dto.AccountID).Return(account.Account{}, account.ErrNotFound)
+			},
+			wantedErr: account.ErrNotFound,
+		},
+		{
+			name: "account is closed",
+			mock: func(repository *MockRepository) {
+				repository.EXPECT().
+					GetAccountForUpdate(ctx, dto.AccountID).
+					Return(account.Account{AccountID: dto.AccountID, Status: account.Closed}, nil)
+			},
+			wantedErr: account.ErrClosed,
+		},
+		{
+			name: "credit limit is lower than overdraft",
+			mock: func(repository *MockRepository) {
+				repository.EXPECT().
+					GetAccountForUpdate(ctx, dto.AccountID).
+					Return(account.Account{AccountID: dto.AccountID, Status: account.Active}, nil)
+				repository.EXPECT().SetCreditLimit(ctx, dto).Return(account.Account{}, account.ErrCreditLimitTooLow)
+			},
+			wantedErr: account.ErrCreditLimitTooLow,
+		},
+		{
+			name: "transaction error",
+			mock: func(repository *MockRepository) {
+				repository.EXPECT().
+					GetAccountForUpdate(ctx, dto.AccountID).
+					Return(account.Account{AccountID: dto.AccountID, Status: account.Active}, nil)
+				repository.EXPECT().SetCreditLimit(ctx, dto).Return(fakeAccount, nil)
+			},
+			txErr: txErr,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			service, repository, _, _, _, _ := mockService(t, tt.txErr)
+
+			tt.mock(repository)
+
+			got, err := service.SetCreditLimit(ctx, dto)
+			if tt.wantedErr != nil {
+				require.ErrorIs(t, err, tt.wantedErr)
+			}
+			if tt.txErr != nil {
+				require.ErrorIs(t, err, tt.txErr)
+			}
+			require.True(t, reflect.DeepEqual(tt.want, got))
+		})
+	}
+}
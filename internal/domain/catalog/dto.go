@@ -0,0 +1,15 @@
+package catalog
+
+type CreateDTO struct {
+	ServiceID   int64
+	Name        string
+	Description string
+	Active      bool
+}
+
+type UpdateDTO struct {
+	ServiceID   int64
+	Name        string
+	Description string
+	Active      bool
+}
@@ -0,0 +1,22 @@
+package catalog
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrNotFound     = errors.New("service not found")
+	ErrAlreadyExist = errors.New("service with given id already exist")
+	ErrHasOrders    = errors.New("service has orders")
+)
+
+// Entity is a service of the catalog. Orders can only be created for active services.
+type Entity struct {
+	ServiceID   int64
+	Name        string
+	Description string
+	Active      bool
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
@@ -0,0 +1,76 @@
+package catalog
+
+import (
+	"context"
+	"fmt"
+
+	"go.uber.org/zap"
+)
+
+//go:generate mockgen -source=service.go -destination=mock_test.go -package=catalog_test
+
+type Repository interface {
+	CreateService(ctx context.Context, dto CreateDTO) (Entity, error)
+	GetServices(ctx context.Context) ([]Entity, error)
+	GetServiceByID(ctx context.Context, serviceID int64) (Entity, error)
+	UpdateService(ctx context.Context, dto UpdateDTO) (Entity, error)
+	DeleteService(ctx context.Context, serviceID int64) error
+}
+
+type Service struct {
+	repository Repository
+	logger     *zap.Logger
+}
+
+func NewService(repository Repository, logger *zap.Logger) *Service {
+	return &Service{
+		repository: repository,
+		logger:     logger,
+	}
+}
+
+func (s *Service) CreateService(ctx context.Context, dto CreateDTO) (Entity, error) {
+	entity, err := s.repository.CreateService(ctx, dto)
+	if err != nil {
+		return Entity{}, fmt.Errorf("create service: %w", err)
+	}
+
+	return entity, nil
+}
+
+func (s *Service) GetServices(ctx context.Context) ([]Entity, error) {
+	entities, err := s.repository.GetServices(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("get services: %w", err)
+	}
+
+	return entities, nil
+}
+
+func (s *Service) GetServiceByID(ctx context.Context, serviceID int64) (Entity, error) {
+	entity, err := s.repository.GetServiceByID(ctx, serviceID)
+	if err != nil {
+		return Entity{}, fmt.Errorf("get service by id: %w", err)
+	}
+
+	return entity, nil
+}
+
+func (s *Service) UpdateService(ctx context.Context, dto UpdateDTO) (Entity, error) {
+	entity, err := s.repository.UpdateService(ctx, dto)
+	if err != nil {
+		return Entity{}, fmt.Errorf("update service: %w", err)
+	}
+
+	return entity, nil
+}
+
+// DeleteService deletes a service without orders. Services with orders can only be deactivated,
+// so their names stay in the reports.
+func (s *Service) DeleteService(ctx context.Context, serviceID int64) error {
+	if err := s.repository.DeleteService(ctx, serviceID); err != nil {
+		return fmt.Errorf("delete service: %w", err)
+	}
+
+	return nil
+}
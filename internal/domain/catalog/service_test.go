@@ -0,0 +1,345 @@
+package catalog_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/maypok86/payment-api/internal/domain/catalog"
+	"github.com/maypok86/payment-api/internal/pkg/logger"
+	"github.com/stretchr/testify/require"
+)
+
+func mockService(t *testing.T) (*catalog.Service, *MockRepository) {
+	t.Helper()
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	l := logger.New(os.Stdout, "debug")
+
+	repository := NewMockRepository(mockCtrl)
+	catalog := catalog.NewService(repository, l)
+
+	return catalog, repository
+}
+
+var fakeEntity = catalog.Entity{
+	ServiceID:   1,
+	Name:        "Delivery",
+	Description: "Delivery of goods",
+	Active:      true,
+	CreatedAt:   time.Now(),
+	UpdatedAt:   time.Now(),
+}
+
+func TestService_CreateService(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	dto := catalog.CreateDTO{
+		ServiceID:   fakeEntity.ServiceID,
+		Name:        fakeEntity.Name,
+		Description: fakeEntity.Description,
+		Active:      fakeEntity.Active,
+	}
+	repositoryErr := errors.New("repository error")
+
+	type mockBehavior func(r *MockRepository)
+
+	tests := []struct {
+		name      string
+		mock      mockBehavior
+		want      catalog.Entity
+		wantedErr error
+	}{
+		{
+			name: "success create service",
+			mock: func(r *MockRepository) {
+				r.EXPECT().CreateService(ctx, dto).Return(fakeEntity, nil)
+			},
+			want: fakeEntity,
+		},
+		{
+			name: "service already exist",
+			mock: func(r *MockRepository) {
+				r.EXPECT().CreateService(ctx, dto).Return(catalog.Entity{}, catalog.ErrAlreadyExist)
+			},
+			wantedErr: catalog.ErrAlreadyExist,
+		},
+		{
+			name: "repository error",
+			mock: func(r *MockRepository) {
+				r.EXPECT().CreateService(ctx, dto).Return(catalog.Entity{}, repositoryErr)
+			},
+			wantedErr: repositoryErr,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			catalog, repository := mockService(t)
+
+			tt.mock(repository)
+
+			got, err := catalog.CreateService(ctx, dto)
+			if tt.wantedErr != nil {
+				require.ErrorIs(t, err, tt.wantedErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestService_GetServices(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	entities := []catalog.Entity{fakeEntity}
+	repositoryErr := errors.New("repository error")
+
+	type mockBehavior func(r *MockRepository)
+
+	tests := []struct {
+		name      string
+		mock      mockBehavior
+		want      []catalog.Entity
+		wantedErr error
+	}{
+		{
+			name: "success get services",
+			mock: func(r *MockRepository) {
+				r.EXPECT().GetServices(ctx).Return(entities, nil)
+			},
+			want: entities,
+		},
+		{
+			name: "repository error",
+			mock: func(r *MockRepository) {
+				r.EXPECT().GetServices(ctx).Return(nil, repositoryErr)
+			},
+			wantedErr: repositoryErr,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			catalog, repository := mockService(t)
+
+			tt.mock(repository)
+
+			got, err := catalog.GetServices(ctx)
+			if tt.wantedErr != nil {
+				require.ErrorIs(t, err, tt.wantedErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestService_GetServiceByID(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	repositoryErr := errors.New("repository error")
+
+	type mockBehavior func(r *MockRepository)
+
+	tests := []struct {
+		name      string
+		mock      mockBehavior
+		want      catalog.Entity
+		wantedErr error
+	}{
+		{
+			name: "success get service",
+			mock: func(r *MockRepository) {
+				r.EXPECT().GetServiceByID(ctx, fakeEntity.ServiceID).Return(fakeEntity, nil)
+			},
+			want: fakeEntity,
+		},
+		{
+			name: "service not found",
+			mock: func(r *MockRepository) {
+				r.EXPECT().GetServiceByID(ctx, fakeEntity.ServiceID).Return(catalog.Entity{}, catalog.ErrNotFound)
+			},
+			wantedErr: catalog.ErrNotFound,
+		},
+		{
+			name: "repository error",
+			mock: func(r *MockRepository) {
+				r.EXPECT().GetServiceByID(ctx, fakeEntity.ServiceID).Return(catalog.Entity{}, repositoryErr)
+			},
+			wantedErr: repositoryErr,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			catalog, repository := mockService(t)
+
+			tt.mock(repository)
+
+			got, err := catalog.GetServiceByID(ctx, fakeEntity.ServiceID)
+			if tt.wantedErr != nil {
+				require.ErrorIs(t, err, tt.wantedErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestService_UpdateService(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	dto := catalog.UpdateDTO{
+		ServiceID:   fakeEntity.ServiceID,
+		Name:        "Express delivery",
+		Description: fakeEntity.Description,
+		Active:      false,
+	}
+	updatedEntity := fakeEntity
+	updatedEntity.Name = dto.Name
+	updatedEntity.Active = dto.Active
+	repositoryErr := errors.New("repository error")
+
+	type mockBehavior func(r *MockRepository)
+
+	tests := []struct {
+		name      string
+		mock      mockBehavior
+		want      catalog.Entity
+		wantedErr error
+	}{
+		{
+			name: "success update service",
+			mock: func(r *MockRepository) {
+				r.EXPECT().UpdateService(ctx, dto).Return(updatedEntity, nil)
+			},
+			want: updatedEntity,
+		},
+		{
+			name: "service not found",
+			mock: func(r *MockRepository) {
+				r.EXPECT().UpdateService(ctx, dto).Return(catalog.Entity{}, catalog.ErrNotFound)
+			},
+			wantedErr: catalog.ErrNotFound,
+		},
+		{
+			name: "repository error",
+			mock: func(r *MockRepository) {
+				r.EXPECT().UpdateService(ctx, dto).Return(catalog.Entity{}, repositoryErr)
+			},
+			wantedErr: repositoryErr,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			catalog, repository := mockService(t)
+
+			tt.mock(repository)
+
+			got, err := catalog.UpdateService(ctx, dto)
+			if tt.wantedErr != nil {
+				require.ErrorIs(t, err, tt.wantedErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestService_DeleteService(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	repositoryErr := errors.New("repository error")
+
+	type mockBehavior func(r *MockRepository)
+
+	tests := []struct {
+		name      string
+		mock      mockBehavior
+		wantedErr error
+	}{
+		{
+			name: "success delete service",
+			mock: func(r *MockRepository) {
+				r.EXPECT().DeleteService(ctx, fakeEntity.ServiceID).Return(nil)
+			},
+		},
+		{
+			name: "service not found",
+			mock: func(r *MockRepository) {
+				r.EXPECT().DeleteService(ctx, fakeEntity.ServiceID).Return(catalog.ErrNotFound)
+			},
+			wantedErr: catalog.ErrNotFound,
+		},
+		{
+			name: "service has orders",
+			mock: func(r *MockRepository) {
+				r.EXPECT().DeleteService(ctx, fakeEntity.ServiceID).Return(catalog.ErrHasOrders)
+			},
+			wantedErr: catalog.ErrHasOrders,
+		},
+		{
+			name: "repository error",
+			mock: func(r *MockRepository) {
+				r.EXPECT().DeleteService(ctx, fakeEntity.ServiceID).Return(repositoryErr)
+			},
+			wantedErr: repositoryErr,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			catalog, repository := mockService(t)
+
+			tt.mock(repository)
+
+			err := catalog.DeleteService(ctx, fakeEntity.ServiceID)
+			if tt.wantedErr != nil {
+				require.ErrorIs(t, err, tt.wantedErr)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
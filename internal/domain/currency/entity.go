@@ -0,0 +1,178 @@
+package currency
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrUnsupported   = errors.New("unsupported currency")
+	ErrMismatch      = errors.New("currency mismatch")
+	ErrInvalidAmount = errors.New("amount does not fit the precision of the currency")
+)
+
+// MaxMajorUnits bounds every amount in major units, so the bound in minor units depends on the precision
+// of the currency and sums of amounts stay far from the int64 limit.
+const MaxMajorUnits = 1_000_000_000_000
+
+// Currency is an ISO-4217 currency. Every amount in the API, the domain and the database is an integer
+// number of its minor units. Fractional amounts are rejected when a request is decoded.
+type Currency struct {
+	string
+}
+
+var (
+	RUB = Currency{"RUB"}
+	USD = Currency{"USD"}
+	EUR = Currency{"EUR"}
+	KZT = Currency{"KZT"}
+	JPY = Currency{"JPY"}
+	KWD = Currency{"KWD"}
+)
+
+// Default is used when a request does not name a currency.
+var Default = RUB
+
+// minorUnits is the number of decimal digits of the minor unit of each currency.
+var minorUnits = map[Currency]int{
+	RUB: 2,
+	USD: 2,
+	EUR: 2,
+	KZT: 2,
+	JPY: 0,
+	KWD: 3,
+}
+
+var stringToCurrency = map[string]Currency{
+	"RUB": RUB,
+	"USD": USD,
+	"EUR": EUR,
+	"KZT": KZT,
+	"JPY": JPY,
+	"KWD": KWD,
+}
+
+func Parse(code string) (Currency, error) {
+	if c, ok := stringToCurrency[code]; ok {
+		return c, nil
+	}
+
+	return Currency{}, fmt.Errorf("%w: %q", ErrUnsupported, code)
+}
+
+func (c Currency) String() string {
+	return c.string
+}
+
+func (c Currency) IsZero() bool {
+	return c == Currency{}
+}
+
+// OrDefault returns Default for the zero Currency.
+func (c Currency) OrDefault() Currency {
+	if c.IsZero() {
+		return Default
+	}
+
+	return c
+}
+
+// MinorUnits returns the number of decimal digits of the minor unit.
+func (c Currency) MinorUnits() int {
+	return minorUnits[c]
+}
+
+// MaxAmount returns MaxMajorUnits in minor units of the currency.
+func (c Currency) MaxAmount() int64 {
+	amount := int64(MaxMajorUnits)
+	for i := 0; i < c.MinorUnits(); i++ {
+		amount *= 10
+	}
+
+	return amount
+}
+
+// ValidateAmount returns an error if the amount of minor units is negative or above MaxAmount.
+func (c Currency) ValidateAmount(amount int64) error {
+	if _, ok := minorUnits[c]; !ok {
+		return fmt.Errorf("%w: %q", ErrUnsupported, c.string)
+	}
+
+	if amount < 0 || amount > c.MaxAmount() {
+		return fmt.Errorf("%w: %d is not between 0 and %s", ErrInvalidAmount, amount, c.Format(c.MaxAmount()))
+	}
+
+	return nil
+}
+
+// Format renders an amount of minor units with the precision of the currency, e.g. 12345 RUB as "123.45 RUB".
+func (c Currency) Format(amount int64) string {
+	units := c.MinorUnits()
+	if units == 0 {
+		return fmt.Sprintf("%d %s", amount, c)
+	}
+
+	sign := ""
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+
+	divisor := int64(1)
+	for i := 0; i < units; i++ {
+		divisor *= 10
+	}
+
+	return fmt.Sprintf("%s%d.%0*d %s", sign, amount/divisor, units, amount%divisor, c)
+}
+
+func (c *Currency) UnmarshalJSON(data []byte) error {
+	var code string
+	if err := json.Unmarshal(data, &code); err != nil {
+		return err
+	}
+
+	if code == "" {
+		*c = Currency{}
+		return nil
+	}
+
+	parsed, err := Parse(code)
+	if err != nil {
+		return err
+	}
+
+	*c = parsed
+
+	return nil
+}
+
+func (c Currency) MarshalJSON() ([]byte, error) {
+	return json.Marshal(c.string)
+}
+
+func (c *Currency) Scan(value interface{}) error {
+	str, ok := value.(string)
+	if !ok {
+		return errors.New("scan source is not string")
+	}
+
+	parsed, err := Parse(str)
+	if err != nil {
+		return err
+	}
+
+	*c = parsed
+
+	return nil
+}
+
+func (c Currency) Value() (driver.Value, error) {
+	if _, ok := minorUnits[c]; !ok {
+		return nil, errors.New("wrong value for Currency")
+	}
+
+	return c.string, nil
+}
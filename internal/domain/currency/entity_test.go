@@ -0,0 +1,219 @@
+package currency_test
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/maypok86/payment-api/internal/domain/currency"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		code      string
+		want      currency.Currency
+		wantedErr error
+	}{
+		{
+			name: "success parse",
+			code: "USD",
+			want: currency.USD,
+		},
+		{
+			name:      "unsupported currency",
+			code:      "XXX",
+			wantedErr: currency.ErrUnsupported,
+		},
+		{
+			name:      "lower case code",
+			code:      "rub",
+			wantedErr: currency.ErrUnsupported,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := currency.Parse(tt.code)
+			if tt.wantedErr != nil {
+				require.ErrorIs(t, err, tt.wantedErr)
+			}
+			require.True(t, reflect.DeepEqual(tt.want, got))
+		})
+	}
+}
+
+func TestCurrency_Format(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		currency currency.Currency
+		amount   int64
+		want     string
+	}{
+		{
+			name:     "two minor units",
+			currency: currency.RUB,
+			amount:   12345,
+			want:     "123.45 RUB",
+		},
+		{
+			name:     "leading zeros in minor units",
+			currency: currency.USD,
+			amount:   5,
+			want:     "0.05 USD",
+		},
+		{
+			name:     "no minor units",
+			currency: currency.JPY,
+			amount:   500,
+			want:     "500 JPY",
+		},
+		{
+			name:     "three minor units",
+			currency: currency.KWD,
+			amount:   1500,
+			want:     "1.500 KWD",
+		},
+		{
+			name:     "negative amount",
+			currency: currency.EUR,
+			amount:   -150,
+			want:     "-1.50 EUR",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			require.Equal(t, tt.want, tt.currency.Format(tt.amount))
+		})
+	}
+}
+
+func TestCurrency_ValidateAmount(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		currency  currency.Currency
+		amount    int64
+		wantedErr error
+	}{
+		{
+			name:     "zero amount",
+			currency: currency.RUB,
+			amount:   0,
+		},
+		{
+			name:     "largest amount with two minor units",
+			currency: currency.RUB,
+			amount:   currency.MaxMajorUnits * 100,
+		},
+		{
+			name:      "too large amount with no minor units",
+			currency:  currency.JPY,
+			amount:    currency.MaxMajorUnits + 1,
+			wantedErr: currency.ErrInvalidAmount,
+		},
+		{
+			name:     "amount that only fits three minor units",
+			currency: currency.KWD,
+			amount:   currency.MaxMajorUnits * 1000,
+		},
+		{
+			name:      "too large amount with two minor units",
+			currency:  currency.USD,
+			amount:    currency.MaxMajorUnits * 1000,
+			wantedErr: currency.ErrInvalidAmount,
+		},
+		{
+			name:      "negative amount",
+			currency:  currency.EUR,
+			amount:    -1,
+			wantedErr: currency.ErrInvalidAmount,
+		},
+		{
+			name:      "unsupported currency",
+			currency:  currency.Currency{},
+			amount:    1,
+			wantedErr: currency.ErrUnsupported,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := tt.currency.ValidateAmount(tt.amount)
+			require.True(t, errors.Is(err, tt.wantedErr))
+		})
+	}
+}
+
+func TestCurrency_UnmarshalJSON(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		data    string
+		want    currency.Currency
+		wantErr bool
+	}{
+		{
+			name: "success unmarshal",
+			data: `"KZT"`,
+			want: currency.KZT,
+		},
+		{
+			name: "empty currency",
+			data: `""`,
+			want: currency.Currency{},
+		},
+		{
+			name:    "unsupported currency",
+			data:    `"XXX"`,
+			wantErr: true,
+		},
+		{
+			name:    "not a string",
+			data:    `643`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var got currency.Currency
+
+			err := json.Unmarshal([]byte(tt.data), &got)
+			require.True(t, (err != nil) == tt.wantErr)
+			require.True(t, reflect.DeepEqual(tt.want, got))
+		})
+	}
+}
+
+func TestCurrency_OrDefault(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, currency.Default, currency.Currency{}.OrDefault())
+	require.Equal(t, currency.USD, currency.USD.OrDefault())
+}
@@ -0,0 +1,16 @@
+package idempotency
+
+type CreateDTO struct {
+	Key         string
+	Fingerprint string
+}
+
+type SaveResponseDTO struct {
+	Key      string
+	Response Response
+}
+
+type ExecuteDTO struct {
+	Key         string
+	Fingerprint string
+}
@@ -0,0 +1,23 @@
+package idempotency
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrNotFound            = errors.New("idempotency key not found")
+	ErrFingerprintMismatch = errors.New("idempotency key is already used with another request")
+)
+
+type Response struct {
+	StatusCode int
+	Body       []byte
+}
+
+type Key struct {
+	Key         string
+	Fingerprint string
+	Response    Response
+	CreatedAt   time.Time
+}
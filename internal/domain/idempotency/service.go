@@ -0,0 +1,84 @@
+package idempotency
+
+import (
+	"context"
+	"fmt"
+
+	"go.uber.org/zap"
+)
+
+//go:generate mockgen -source=service.go -destination=mock_test.go -package=idempotency_test
+
+type Transactor interface {
+	WithTx(ctx context.Context, txFunc func(ctx context.Context) error) error
+}
+
+type Repository interface {
+	CreateKey(ctx context.Context, dto CreateDTO) (bool, error)
+	GetKey(ctx context.Context, key string) (Key, error)
+	SaveResponse(ctx context.Context, dto SaveResponseDTO) error
+}
+
+type HandleFunc func(ctx context.Context) (Response, error)
+
+type Service struct {
+	transactor Transactor
+	repository Repository
+	logger     *zap.Logger
+}
+
+func NewService(transactor Transactor, repository Repository, logger *zap.Logger) *Service {
+	return &Service{
+		transactor: transactor,
+		repository: repository,
+		logger:     logger,
+	}
+}
+
+// Execute runs handle at most once per key. The key, the fingerprint and the response
+// are stored in the same transaction as the changes made by handle, so a replay either
+// sees the stored response or runs handle from scratch.
+func (s *Service) Execute(
+	ctx context.Context,
+	dto ExecuteDTO,
+	handle HandleFunc,
+) (response Response, replayed bool, err error) {
+	err = s.transactor.WithTx(ctx, func(ctx context.Context) error {
+		created, err := s.repository.CreateKey(ctx, CreateDTO{
+			Key:         dto.Key,
+			Fingerprint: dto.Fingerprint,
+		})
+		if err != nil {
+			return err
+		}
+
+		if !created {
+			key, err := s.repository.GetKey(ctx, dto.Key)
+			if err != nil {
+				return err
+			}
+			if key.Fingerprint != dto.Fingerprint {
+				return ErrFingerprintMismatch
+			}
+
+			response, replayed = key.Response, true
+
+			return nil
+		}
+
+		response, err = handle(ctx)
+		if err != nil {
+			return err
+		}
+
+		return s.repository.SaveResponse(ctx, SaveResponseDTO{
+			Key:      dto.Key,
+			Response: response,
+		})
+	})
+	if err != nil {
+		return Response{}, false, fmt.Errorf("execute idempotent request: %w", err)
+	}
+
+	return response, replayed, nil
+}
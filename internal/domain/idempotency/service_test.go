@@ -0,0 +1,212 @@
+package idempotency_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/maypok86/payment-api/internal/domain/idempotency"
+	"github.com/maypok86/payment-api/internal/pkg/logger"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeTransactor struct {
+	txErr error
+}
+
+func newFakeTransactor(txErr error) fakeTransactor {
+	return fakeTransactor{txErr: txErr}
+}
+
+func (ft fakeTransactor) WithTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	err := fn(ctx)
+
+	if ft.txErr != nil {
+		return ft.txErr
+	}
+
+	return err
+}
+
+func mockService(t *testing.T, txErr error) (*idempotency.Service, *MockRepository) {
+	t.Helper()
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	l := logger.New(os.Stdout, "debug")
+
+	repository := NewMockRepository(mockCtrl)
+	transactor := newFakeTransactor(txErr)
+	service := idempotency.NewService(transactor, repository, l)
+
+	return service, repository
+}
+
+func TestService_Execute(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	dto := idempotency.ExecuteDTO{
+		Key:         "key",
+		Fingerprint: "fingerprint",
+	}
+	createDTO := idempotency.CreateDTO{
+		Key:         dto.Key,
+		Fingerprint: dto.Fingerprint,
+	}
+	response := idempotency.Response{
+		StatusCode: 200,
+		Body:       []byte(`{"balance":100}`),
+	}
+	saveResponseDTO := idempotency.SaveResponseDTO{
+		Key:      dto.Key,
+		Response: response,
+	}
+	txErr := errors.New("transaction error")
+	repositoryErr := errors.New("repository error")
+	handleErr := errors.New("handle error")
+
+	type mockBehavior func(r *MockRepository)
+
+	type want struct {
+		response   idempotency.Response
+		replayed   bool
+		handleCall int
+	}
+
+	tests := []struct {
+		name      string
+		mock      mockBehavior
+		handleErr error
+		want      want
+		wantedErr error
+		txErr     error
+	}{
+		{
+			name: "success first execution",
+			mock: func(repository *MockRepository) {
+				repository.EXPECT().CreateKey(ctx, createDTO).Return(true, nil)
+				repository.EXPECT().SaveResponse(ctx, saveResponseDTO).Return(nil)
+			},
+			want: want{
+				response:   response,
+				handleCall: 1,
+			},
+		},
+		{
+			name: "success replay",
+			mock: func(repository *MockRepository) {
+				repository.EXPECT().CreateKey(ctx, createDTO).Return(false, nil)
+				repository.EXPECT().GetKey(ctx, dto.Key).Return(idempotency.Key{
+					Key:         dto.Key,
+					Fingerprint: dto.Fingerprint,
+					Response:    response,
+				}, nil)
+			},
+			want: want{
+				response: response,
+				replayed: true,
+			},
+		},
+		{
+			name: "fingerprint mismatch",
+			mock: func(repository *MockRepository) {
+				repository.EXPECT().CreateKey(ctx, createDTO).Return(false, nil)
+				repository.EXPECT().GetKey(ctx, dto.Key).Return(idempotency.Key{
+					Key:         dto.Key,
+					Fingerprint: "another fingerprint",
+					Response:    response,
+				}, nil)
+			},
+			wantedErr: idempotency.ErrFingerprintMismatch,
+		},
+		{
+			name: "create key error",
+			mock: func(repository *MockRepository) {
+				repository.EXPECT().CreateKey(ctx, createDTO).Return(false, repositoryErr)
+			},
+			wantedErr: repositoryErr,
+		},
+		{
+			name: "get key error",
+			mock: func(repository *MockRepository) {
+				repository.EXPECT().CreateKey(ctx, createDTO).Return(false, nil)
+				repository.EXPECT().GetKey(ctx, dto.Key).Return(idempotency.Key{}, repositoryErr)
+			},
+			wantedErr: repositoryErr,
+		},
+		{
+			name: "handle error",
+			mock: func(repository *MockRepository) {
+				repository.EXPECT().CreateKey(ctx, createDTO).Return(true, nil)
+			},
+			handleErr: handleErr,
+			want: want{
+				handleCall: 1,
+			},
+			wantedErr: handleErr,
+		},
+		{
+			name: "save response error",
+			mock: func(repository *MockRepository) {
+				repository.EXPECT().CreateKey(ctx, createDTO).Return(true, nil)
+				repository.EXPECT().SaveResponse(ctx, saveResponseDTO).Return(repositoryErr)
+			},
+			want: want{
+				handleCall: 1,
+			},
+			wantedErr: repositoryErr,
+		},
+		{
+			name: "transaction error",
+			mock: func(repository *MockRepository) {
+				repository.EXPECT().CreateKey(ctx, createDTO).Return(true, nil)
+				repository.EXPECT().SaveResponse(ctx, saveResponseDTO).Return(nil)
+			},
+			want: want{
+				handleCall: 1,
+			},
+			txErr: txErr,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			service, repository := mockService(t, tt.txErr)
+
+			tt.mock(repository)
+
+			handleCall := 0
+			got, replayed, err := service.Execute(
+				ctx,
+				dto,
+				func(ctx context.Context) (idempotency.Response, error) {
+					handleCall++
+					if tt.handleErr != nil {
+						return idempotency.Response{}, tt.handleErr
+					}
+
+					return response, nil
+				},
+			)
+			if tt.wantedErr != nil {
+				require.ErrorIs(t, err, tt.wantedErr)
+			}
+			if tt.txErr != nil {
+				require.ErrorIs(t, err, tt.txErr)
+			}
+			require.True(t, reflect.DeepEqual(tt.want.response, got))
+			require.Equal(t, tt.want.replayed, replayed)
+			require.Equal(t, tt.want.handleCall, handleCall)
+		})
+	}
+}
@@ -0,0 +1,167 @@
+package ledger
+
+import (
+	"github.com/maypok86/payment-api/internal/domain/currency"
+	"github.com/maypok86/payment-api/internal/domain/transaction"
+)
+
+// CreatePostingDTO describes a posting in a single currency.
+type CreatePostingDTO struct {
+	Type        transaction.Type
+	Currency    currency.Currency
+	Description string
+	Lines       []Line
+}
+
+func (dto CreatePostingDTO) Validate() error {
+	if _, err := dto.Currency.Value(); err != nil {
+		return ErrInvalidCurrency
+	}
+
+	if len(dto.Lines) == 0 {
+		return ErrEmptyPosting
+	}
+
+	var debit, credit int64
+	for _, line := range dto.Lines {
+		if line.Amount <= 0 || (line.AccountID == 0) == line.SystemAccount.IsZero() {
+			return ErrInvalidLine
+		}
+
+		switch line.Side {
+		case Debit:
+			debit += line.Amount
+		case Credit:
+			credit += line.Amount
+		default:
+			return ErrInvalidLine
+		}
+	}
+
+	if debit != credit {
+		return ErrUnbalancedPosting
+	}
+
+	return nil
+}
+
+func accountLine(accountID int64, side Side, amount int64) Line {
+	return Line{
+		AccountID: accountID,
+		Side:      side,
+		Amount:    amount,
+	}
+}
+
+func systemLine(systemAccount SystemAccount, side Side, amount int64) Line {
+	return Line{
+		SystemAccount: systemAccount,
+		Side:          side,
+		Amount:        amount,
+	}
+}
+
+func NewEnrollmentDTO(accountID, amount int64, cur currency.Currency, description string) CreatePostingDTO {
+	return CreatePostingDTO{
+		Type:        transaction.Enrollment,
+		Currency:    cur,
+		Description: description,
+		Lines: []Line{
+			systemLine(ExternalFunding, Debit, amount),
+			accountLine(accountID, Credit, amount),
+		},
+	}
+}
+
+func NewTransferDTO(senderID, receiverID, amount int64, cur currency.Currency, description string) CreatePostingDTO {
+	return CreatePostingDTO{
+		Type:        transaction.Transfer,
+		Currency:    cur,
+		Description: description,
+		Lines: []Line{
+			accountLine(senderID, Debit, amount),
+			accountLine(receiverID, Credit, amount),
+		},
+	}
+}
+
+// NewExchangeDTOs moves money between balances in different currencies through the currency exchange account,
+// so each posting stays in a single currency.
+func NewExchangeDTOs(
+	senderID int64,
+	amount int64,
+	cur currency.Currency,
+	receiverID int64,
+	receiverAmount int64,
+	receiverCur currency.Currency,
+	description string,
+) []CreatePostingDTO {
+	return []CreatePostingDTO{
+		{
+			Type:        transaction.Transfer,
+			Currency:    cur,
+			Description: description,
+			Lines: []Line{
+				accountLine(senderID, Debit, amount),
+				systemLine(CurrencyExchange, Credit, amount),
+			},
+		},
+		{
+			Type:        transaction.Transfer,
+			Currency:    receiverCur,
+			Description: description,
+			Lines: []Line{
+				systemLine(CurrencyExchange, Debit, receiverAmount),
+				accountLine(receiverID, Credit, receiverAmount),
+			},
+		},
+	}
+}
+
+func NewReservationDTO(accountID, amount int64, cur currency.Currency, description string) CreatePostingDTO {
+	return CreatePostingDTO{
+		Type:        transaction.Reservation,
+		Currency:    cur,
+		Description: description,
+		Lines: []Line{
+			accountLine(accountID, Debit, amount),
+			systemLine(ReservedFunds, Credit, amount),
+		},
+	}
+}
+
+func NewCancelReservationDTO(accountID, amount int64, cur currency.Currency, description string) CreatePostingDTO {
+	return CreatePostingDTO{
+		Type:        transaction.CancelReservation,
+		Currency:    cur,
+		Description: description,
+		Lines: []Line{
+			systemLine(ReservedFunds, Debit, amount),
+			accountLine(accountID, Credit, amount),
+		},
+	}
+}
+
+func NewPaymentDTO(amount int64, cur currency.Currency, description string) CreatePostingDTO {
+	return CreatePostingDTO{
+		Type:        transaction.Payment,
+		Currency:    cur,
+		Description: description,
+		Lines: []Line{
+			systemLine(ReservedFunds, Debit, amount),
+			systemLine(ServiceRevenue, Credit, amount),
+		},
+	}
+}
+
+func NewRefundDTO(accountID, amount int64, cur currency.Currency, description string) CreatePostingDTO {
+	return CreatePostingDTO{
+		Type:        transaction.Refund,
+		Currency:    cur,
+		Description: description,
+		Lines: []Line{
+			systemLine(ServiceRevenue, Debit, amount),
+			accountLine(accountID, Credit, amount),
+		},
+	}
+}
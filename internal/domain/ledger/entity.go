@@ -0,0 +1,163 @@
+package ledger
+
+import (
+	"database/sql/driver"
+	"errors"
+	"time"
+
+	"github.com/maypok86/payment-api/internal/domain/currency"
+	"github.com/maypok86/payment-api/internal/domain/transaction"
+)
+
+var (
+	ErrEmptyPosting      = errors.New("posting has no lines")
+	ErrUnbalancedPosting = errors.New("posting debit and credit lines are not balanced")
+	ErrInvalidLine       = errors.New("posting line is not valid")
+	ErrInvalidCurrency   = errors.New("posting currency is not valid")
+)
+
+type Side struct {
+	string
+}
+
+var (
+	Debit  = Side{"debit"}
+	Credit = Side{"credit"}
+)
+
+var sideToString = map[Side]string{
+	Debit:  "debit",
+	Credit: "credit",
+}
+
+var stringToSide = map[string]Side{
+	"debit":  Debit,
+	"credit": Credit,
+}
+
+func (s Side) String() string {
+	return sideToString[s]
+}
+
+func (s *Side) Scan(value interface{}) error {
+	str, ok := value.(string)
+	if !ok {
+		return errors.New("scan source is not string")
+	}
+
+	if v, ok := stringToSide[str]; ok {
+		*s = v
+		return nil
+	}
+
+	return errors.New("wrong value for Side")
+}
+
+func (s Side) Value() (driver.Value, error) {
+	str, ok := sideToString[s]
+	if !ok {
+		return nil, errors.New("wrong value for Side")
+	}
+
+	return str, nil
+}
+
+// SystemAccount is an account of the service itself. The zero value means
+// that a posting line belongs to a user account.
+type SystemAccount struct {
+	string
+}
+
+var (
+	ExternalFunding  = SystemAccount{"external_funding"}
+	ReservedFunds    = SystemAccount{"reserved_funds"}
+	ServiceRevenue   = SystemAccount{"service_revenue"}
+	CurrencyExchange = SystemAccount{"currency_exchange"}
+)
+
+var systemAccountToString = map[SystemAccount]string{
+	ExternalFunding:  "external_funding",
+	ReservedFunds:    "reserved_funds",
+	ServiceRevenue:   "service_revenue",
+	CurrencyExchange: "currency_exchange",
+}
+
+var stringToSystemAccount = map[string]SystemAccount{
+	"external_funding":  ExternalFunding,
+	"reserved_funds":    ReservedFunds,
+	"service_revenue":   ServiceRevenue,
+	"currency_exchange": CurrencyExchange,
+}
+
+func (sa SystemAccount) String() string {
+	return systemAccountToString[sa]
+}
+
+func (sa SystemAccount) IsZero() bool {
+	return sa == SystemAccount{}
+}
+
+func (sa *SystemAccount) Scan(value interface{}) error {
+	if value == nil {
+		*sa = SystemAccount{}
+		return nil
+	}
+
+	str, ok := value.(string)
+	if !ok {
+		return errors.New("scan source is not string")
+	}
+
+	if v, ok := stringToSystemAccount[str]; ok {
+		*sa = v
+		return nil
+	}
+
+	return errors.New("wrong value for SystemAccount")
+}
+
+func (sa SystemAccount) Value() (driver.Value, error) {
+	if sa.IsZero() {
+		return nil, nil
+	}
+
+	str, ok := systemAccountToString[sa]
+	if !ok {
+		return nil, errors.New("wrong value for SystemAccount")
+	}
+
+	return str, nil
+}
+
+type Line struct {
+	AccountID     int64
+	SystemAccount SystemAccount
+	Side          Side
+	Amount        int64
+}
+
+type Posting struct {
+	PostingID   int64
+	Type        transaction.Type
+	Currency    currency.Currency
+	Description string
+	Lines       []Line
+	CreatedAt   time.Time
+}
+
+type AccountMismatch struct {
+	AccountID     int64
+	Currency      currency.Currency
+	Balance       int64
+	LedgerBalance int64
+}
+
+type Reconciliation struct {
+	TotalDebit  int64
+	TotalCredit int64
+	Mismatches  []AccountMismatch
+}
+
+func (r Reconciliation) IsBalanced() bool {
+	return r.TotalDebit == r.TotalCredit && len(r.Mismatches) == 0
+}
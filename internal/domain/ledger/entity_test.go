@@ -0,0 +1,328 @@
+package ledger_test
+
+import (
+	"database/sql/driver"
+	"reflect"
+	"testing"
+
+	"github.com/maypok86/payment-api/internal/domain/currency"
+	"github.com/maypok86/payment-api/internal/domain/ledger"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSide_Scan(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		value   interface{}
+		want    ledger.Side
+		wantErr bool
+	}{
+		{
+			name:  "success scan debit",
+			value: "debit",
+			want:  ledger.Debit,
+		},
+		{
+			name:  "success scan credit",
+			value: "credit",
+			want:  ledger.Credit,
+		},
+		{
+			name:    "wrong value for Side",
+			value:   "wrong",
+			want:    ledger.Side{},
+			wantErr: true,
+		},
+		{
+			name:    "scan source is not string",
+			value:   1,
+			want:    ledger.Side{},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var side ledger.Side
+
+			err := side.Scan(tt.value)
+			require.True(t, (err != nil) == tt.wantErr)
+			require.True(t, reflect.DeepEqual(tt.want, side))
+		})
+	}
+}
+
+func TestSystemAccount_Scan(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		value   interface{}
+		want    ledger.SystemAccount
+		wantErr bool
+	}{
+		{
+			name:  "success scan",
+			value: "reserved_funds",
+			want:  ledger.ReservedFunds,
+		},
+		{
+			name:  "scan null",
+			value: nil,
+			want:  ledger.SystemAccount{},
+		},
+		{
+			name:    "wrong value for SystemAccount",
+			value:   "wrong",
+			want:    ledger.SystemAccount{},
+			wantErr: true,
+		},
+		{
+			name:    "scan source is not string",
+			value:   1,
+			want:    ledger.SystemAccount{},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			systemAccount := ledger.ServiceRevenue
+
+			err := systemAccount.Scan(tt.value)
+			require.True(t, (err != nil) == tt.wantErr)
+			if !tt.wantErr {
+				require.True(t, reflect.DeepEqual(tt.want, systemAccount))
+			}
+		})
+	}
+}
+
+func TestSystemAccount_Value(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name          string
+		systemAccount ledger.SystemAccount
+		want          driver.Value
+	}{
+		{
+			name:          "check external_funding",
+			systemAccount: ledger.ExternalFunding,
+			want:          "external_funding",
+		},
+		{
+			name:          "check reserved_funds",
+			systemAccount: ledger.ReservedFunds,
+			want:          "reserved_funds",
+		},
+		{
+			name:          "check service_revenue",
+			systemAccount: ledger.ServiceRevenue,
+			want:          "service_revenue",
+		},
+		{
+			name:          "check user account",
+			systemAccount: ledger.SystemAccount{},
+			want:          nil,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := tt.systemAccount.Value()
+			require.NoError(t, err)
+			require.True(t, reflect.DeepEqual(tt.want, got))
+		})
+	}
+}
+
+func TestCreatePostingDTO_Validate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		dto       ledger.CreatePostingDTO
+		wantedErr error
+	}{
+		{
+			name: "enrollment",
+			dto:  ledger.NewEnrollmentDTO(1, 100, currency.RUB, "enrollment"),
+		},
+		{
+			name: "transfer",
+			dto:  ledger.NewTransferDTO(1, 2, 100, currency.RUB, "transfer"),
+		},
+		{
+			name: "reservation",
+			dto:  ledger.NewReservationDTO(1, 100, currency.RUB, "reservation"),
+		},
+		{
+			name: "cancel reservation",
+			dto:  ledger.NewCancelReservationDTO(1, 100, currency.RUB, "cancel reservation"),
+		},
+		{
+			name: "payment",
+			dto:  ledger.NewPaymentDTO(100, currency.RUB, "payment"),
+		},
+		{
+			name: "refund",
+			dto:  ledger.NewRefundDTO(1, 100, currency.RUB, "refund"),
+		},
+		{
+			name: "exchange sender posting",
+			dto:  ledger.NewExchangeDTOs(1, 100, currency.USD, 2, 6150, currency.RUB, "exchange")[0],
+		},
+		{
+			name: "exchange receiver posting",
+			dto:  ledger.NewExchangeDTOs(1, 100, currency.USD, 2, 6150, currency.RUB, "exchange")[1],
+		},
+		{
+			name:      "empty posting",
+			dto:       ledger.CreatePostingDTO{Currency: currency.RUB},
+			wantedErr: ledger.ErrEmptyPosting,
+		},
+		{
+			name: "posting without currency",
+			dto: ledger.CreatePostingDTO{
+				Lines: []ledger.Line{
+					{AccountID: 1, Side: ledger.Debit, Amount: 100},
+					{AccountID: 2, Side: ledger.Credit, Amount: 100},
+				},
+			},
+			wantedErr: ledger.ErrInvalidCurrency,
+		},
+		{
+			name: "unbalanced posting",
+			dto: ledger.CreatePostingDTO{
+				Currency: currency.RUB,
+				Lines: []ledger.Line{
+					{AccountID: 1, Side: ledger.Debit, Amount: 100},
+					{SystemAccount: ledger.ReservedFunds, Side: ledger.Credit, Amount: 90},
+				},
+			},
+			wantedErr: ledger.ErrUnbalancedPosting,
+		},
+		{
+			name: "line without account",
+			dto: ledger.CreatePostingDTO{
+				Currency: currency.RUB,
+				Lines: []ledger.Line{
+					{Side: ledger.Debit, Amount: 100},
+					{AccountID: 1, Side: ledger.Credit, Amount: 100},
+				},
+			},
+			wantedErr: ledger.ErrInvalidLine,
+		},
+		{
+			name: "line with both accounts",
+			dto: ledger.CreatePostingDTO{
+				Currency: currency.RUB,
+				Lines: []ledger.Line{
+					{AccountID: 1, SystemAccount: ledger.ExternalFunding, Side: ledger.Debit, Amount: 100},
+					{AccountID: 1, Side: ledger.Credit, Amount: 100},
+				},
+			},
+			wantedErr: ledger.ErrInvalidLine,
+		},
+		{
+			name: "line without side",
+			dto: ledger.CreatePostingDTO{
+				Currency: currency.RUB,
+				Lines: []ledger.Line{
+					{AccountID: 1, Amount: 100},
+					{AccountID: 2, Side: ledger.Credit, Amount: 100},
+				},
+			},
+			wantedErr: ledger.ErrInvalidLine,
+		},
+		{
+			name: "line with non-positive amount",
+			dto: ledger.CreatePostingDTO{
+				Currency: currency.RUB,
+				Lines: []ledger.Line{
+					{AccountID: 1, Side: ledger.Debit, Amount: 0},
+					{AccountID: 2, Side: ledger.Credit, Amount: 0},
+				},
+			},
+			wantedErr: ledger.ErrInvalidLine,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := tt.dto.Validate()
+			if tt.wantedErr == nil {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorIs(t, err, tt.wantedErr)
+		})
+	}
+}
+
+func TestReconciliation_IsBalanced(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name           string
+		reconciliation ledger.Reconciliation
+		want           bool
+	}{
+		{
+			name: "balanced",
+			reconciliation: ledger.Reconciliation{
+				TotalDebit:  100,
+				TotalCredit: 100,
+			},
+			want: true,
+		},
+		{
+			name: "debit and credit differ",
+			reconciliation: ledger.Reconciliation{
+				TotalDebit:  100,
+				TotalCredit: 90,
+			},
+			want: false,
+		},
+		{
+			name: "account balance differs",
+			reconciliation: ledger.Reconciliation{
+				TotalDebit:  100,
+				TotalCredit: 100,
+				Mismatches: []ledger.AccountMismatch{
+					{AccountID: 1, Balance: 100, LedgerBalance: 90},
+				},
+			},
+			want: false,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			require.Equal(t, tt.want, tt.reconciliation.IsBalanced())
+		})
+	}
+}
@@ -0,0 +1,44 @@
+package ledger
+
+import (
+	"context"
+	"fmt"
+
+	"go.uber.org/zap"
+)
+
+//go:generate mockgen -source=service.go -destination=mock_test.go -package=ledger_test
+
+type Repository interface {
+	GetReconciliation(ctx context.Context) (Reconciliation, error)
+}
+
+type Service struct {
+	repository Repository
+	logger     *zap.Logger
+}
+
+func NewService(repository Repository, logger *zap.Logger) *Service {
+	return &Service{
+		repository: repository,
+		logger:     logger,
+	}
+}
+
+func (s *Service) Reconcile(ctx context.Context) (Reconciliation, error) {
+	reconciliation, err := s.repository.GetReconciliation(ctx)
+	if err != nil {
+		return Reconciliation{}, fmt.Errorf("reconcile ledger: %w", err)
+	}
+
+	if !reconciliation.IsBalanced() {
+		s.logger.Warn(
+			"ledger is not reconciled",
+			zap.Int64("total_debit", reconciliation.TotalDebit),
+			zap.Int64("total_credit", reconciliation.TotalCredit),
+			zap.Int("mismatches", len(reconciliation.Mismatches)),
+		)
+	}
+
+	return reconciliation, nil
+}
@@ -0,0 +1,94 @@
+package ledger_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/maypok86/payment-api/internal/domain/ledger"
+	"github.com/maypok86/payment-api/internal/pkg/logger"
+	"github.com/stretchr/testify/require"
+)
+
+func mockService(t *testing.T) (*ledger.Service, *MockRepository) {
+	t.Helper()
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	l := logger.New(os.Stdout, "debug")
+
+	repository := NewMockRepository(mockCtrl)
+	service := ledger.NewService(repository, l)
+
+	return service, repository
+}
+
+func TestService_Reconcile(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	balanced := ledger.Reconciliation{
+		TotalDebit:  100,
+		TotalCredit: 100,
+	}
+	unbalanced := ledger.Reconciliation{
+		TotalDebit:  100,
+		TotalCredit: 100,
+		Mismatches: []ledger.AccountMismatch{
+			{AccountID: 1, Balance: 100, LedgerBalance: 50},
+		},
+	}
+
+	type mockBehavior func(r *MockRepository)
+
+	tests := []struct {
+		name    string
+		mock    mockBehavior
+		want    ledger.Reconciliation
+		wantErr bool
+	}{
+		{
+			name: "success balanced ledger",
+			mock: func(repository *MockRepository) {
+				repository.EXPECT().GetReconciliation(ctx).Return(balanced, nil)
+			},
+			want: balanced,
+		},
+		{
+			name: "success unbalanced ledger",
+			mock: func(repository *MockRepository) {
+				repository.EXPECT().GetReconciliation(ctx).Return(unbalanced, nil)
+			},
+			want: unbalanced,
+		},
+		{
+			name: "repository error",
+			mock: func(repository *MockRepository) {
+				repository.EXPECT().GetReconciliation(ctx).Return(ledger.Reconciliation{}, errors.New("repository error"))
+			},
+			want:    ledger.Reconciliation{},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			service, repository := mockService(t)
+
+			tt.mock(repository)
+
+			got, err := service.Reconcile(ctx)
+			require.True(t, (err != nil) == tt.wantErr)
+			require.True(t, reflect.DeepEqual(tt.want, got))
+		})
+	}
+}
@@ -1,22 +1,97 @@
 package order
 
+import (
+	"time"
+
+	"github.com/maypok86/payment-api/internal/domain/currency"
+)
+
+// CreateDTO uses the service default reservation TTL if ReservationTTL is zero.
 type CreateDTO struct {
+	OrderID        int64
+	AccountID      int64
+	ServiceID      int64
+	Amount         int64
+	Currency       currency.Currency
+	ReservationTTL time.Duration
+	Actor          string
+}
+
+func (dto CreateDTO) Validate() error {
+	return dto.Currency.ValidateAmount(dto.Amount)
+}
+
+type PayForDTO struct {
 	OrderID   int64
 	AccountID int64
 	ServiceID int64
 	Amount    int64
+	Currency  currency.Currency
+	Actor     string
+	Reason    string
 }
 
-type PayForDTO struct {
+func (dto PayForDTO) Validate() error {
+	return dto.Currency.ValidateAmount(dto.Amount)
+}
+
+type CancelDTO struct {
 	OrderID   int64
 	AccountID int64
 	ServiceID int64
 	Amount    int64
+	Currency  currency.Currency
+	Actor     string
+	Reason    string
 }
 
-type CancelDTO struct {
+func (dto CancelDTO) Validate() error {
+	return dto.Currency.ValidateAmount(dto.Amount)
+}
+
+type CaptureDTO struct {
+	OrderID   int64
+	AccountID int64
+	ServiceID int64
+	Amount    int64
+	Currency  currency.Currency
+	Actor     string
+	Reason    string
+}
+
+func (dto CaptureDTO) Validate() error {
+	return dto.Currency.ValidateAmount(dto.Amount)
+}
+
+type SetCapturedAmountDTO struct {
+	OrderID int64
+	Amount  int64
+}
+
+// RefundDTO refunds the whole refundable amount of the order if Amount is zero.
+type RefundDTO struct {
 	OrderID   int64
 	AccountID int64
 	ServiceID int64
 	Amount    int64
+	Currency  currency.Currency
+	Actor     string
+	Reason    string
+}
+
+func (dto RefundDTO) Validate() error {
+	return dto.Currency.ValidateAmount(dto.Amount)
+}
+
+type AddRefundDTO struct {
+	OrderID int64
+	Amount  int64
+}
+
+type UpdateStatusDTO struct {
+	OrderID int64
+	From    Status
+	To      Status
+	Actor   string
+	Reason  string
 }
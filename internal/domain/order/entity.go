@@ -1,23 +1,152 @@
 package order
 
 import (
+	"database/sql/driver"
 	"errors"
+	"fmt"
 	"time"
+
+	"github.com/maypok86/payment-api/internal/domain/currency"
 )
 
 var (
-	ErrAlreadyExist    = errors.New("order with given id already exist")
-	ErrAccountNotFound = errors.New("account with given account_id not found")
-	ErrNotFound        = errors.New("order not found")
+	ErrAlreadyExist           = errors.New("order with given id already exist")
+	ErrAccountNotFound        = errors.New("account with given account_id not found")
+	ErrServiceNotFound        = errors.New("service with given service_id not found")
+	ErrServiceInactive        = errors.New("service with given service_id is not active")
+	ErrNotFound               = errors.New("order not found")
+	ErrIllegalTransition      = errors.New("illegal order status transition")
+	ErrRefundExceedsPaid      = errors.New("refund amount exceeds paid amount")
+	ErrCaptureExceedsReserved = errors.New("capture amount exceeds reserved amount")
+)
+
+type Status struct {
+	string
+}
+
+var (
+	Created   = Status{"created"}
+	Reserved  = Status{"reserved"}
+	Paid      = Status{"paid"}
+	Cancelled = Status{"cancelled"}
+	Refunded  = Status{"refunded"}
+	Expired   = Status{"expired"}
 )
 
+var statusToString = map[Status]string{
+	Created:   "created",
+	Reserved:  "reserved",
+	Paid:      "paid",
+	Cancelled: "cancelled",
+	Refunded:  "refunded",
+	Expired:   "expired",
+}
+
+var stringToStatus = map[string]Status{
+	"created":   Created,
+	"reserved":  Reserved,
+	"paid":      Paid,
+	"cancelled": Cancelled,
+	"refunded":  Refunded,
+	"expired":   Expired,
+}
+
+// transitions lists the statuses each status can move to. Statuses without an entry are final.
+var transitions = map[Status][]Status{
+	Created:  {Reserved, Cancelled, Expired},
+	Reserved: {Paid, Cancelled, Expired},
+	Paid:     {Refunded},
+}
+
+func (s Status) String() string {
+	return statusToString[s]
+}
+
+func (s *Status) Scan(value interface{}) error {
+	str, ok := value.(string)
+	if !ok {
+		return errors.New("scan source is not string")
+	}
+
+	if v, ok := stringToStatus[str]; ok {
+		*s = v
+		return nil
+	}
+
+	return errors.New("wrong value for Status")
+}
+
+func (s Status) Value() (driver.Value, error) {
+	str, ok := statusToString[s]
+	if !ok {
+		return nil, errors.New("wrong value for Status")
+	}
+
+	return str, nil
+}
+
+func (s Status) CanTransitionTo(to Status) bool {
+	for _, status := range transitions[s] {
+		if status == to {
+			return true
+		}
+	}
+
+	return false
+}
+
+type TransitionError struct {
+	OrderID int64
+	From    Status
+	To      Status
+}
+
+func (e *TransitionError) Error() string {
+	return fmt.Sprintf("order with id = %d can not move from %s to %s", e.OrderID, e.From, e.To)
+}
+
+func (e *TransitionError) Unwrap() error {
+	return ErrIllegalTransition
+}
+
 type Order struct {
-	OrderID     int64
-	AccountID   int64
-	ServiceID   int64
-	Amount      int64
-	IsPaid      bool
-	IsCancelled bool
-	UpdatedAt   time.Time
-	CreatedAt   time.Time
+	OrderID        int64
+	AccountID      int64
+	ServiceID      int64
+	Amount         int64
+	Currency       currency.Currency
+	CapturedAmount int64
+	RefundedAmount int64
+	Status         Status
+	ExpiresAt      time.Time
+	UpdatedAt      time.Time
+	CreatedAt      time.Time
+}
+
+func (o Order) CheckTransition(to Status) error {
+	if !o.Status.CanTransitionTo(to) {
+		return &TransitionError{
+			OrderID: o.OrderID,
+			From:    o.Status,
+			To:      to,
+		}
+	}
+
+	return nil
+}
+
+func (o Order) CheckCurrency(cur currency.Currency) error {
+	if o.Currency != cur {
+		return fmt.Errorf("%w: order with id = %d is in %s, not in %s", currency.ErrMismatch, o.OrderID, o.Currency, cur)
+	}
+
+	return nil
+}
+
+func (o Order) Matches(accountID, serviceID, amount int64) bool {
+	return o.AccountID == accountID && o.ServiceID == serviceID && o.Amount == amount
+}
+
+func (o Order) RefundableAmount() int64 {
+	return o.CapturedAmount - o.RefundedAmount
 }
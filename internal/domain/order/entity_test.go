@@ -0,0 +1,173 @@
+package order_test
+
+import (
+	"testing"
+
+	"github.com/maypok86/payment-api/internal/domain/order"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStatus_Scan(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		value   interface{}
+		want    order.Status
+		wantErr bool
+	}{
+		{
+			name:  "scan reserved",
+			value: "reserved",
+			want:  order.Reserved,
+		},
+		{
+			name:  "scan refunded",
+			value: "refunded",
+			want:  order.Refunded,
+		},
+		{
+			name:    "scan unknown status",
+			value:   "unknown",
+			wantErr: true,
+		},
+		{
+			name:    "scan not string",
+			value:   1,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var got order.Status
+			err := got.Scan(tt.value)
+			require.True(t, (err != nil) == tt.wantErr)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestStatus_CanTransitionTo(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		from order.Status
+		to   order.Status
+		want bool
+	}{
+		{
+			name: "created to reserved",
+			from: order.Created,
+			to:   order.Reserved,
+			want: true,
+		},
+		{
+			name: "reserved to paid",
+			from: order.Reserved,
+			to:   order.Paid,
+			want: true,
+		},
+		{
+			name: "reserved to cancelled",
+			from: order.Reserved,
+			to:   order.Cancelled,
+			want: true,
+		},
+		{
+			name: "reserved to expired",
+			from: order.Reserved,
+			to:   order.Expired,
+			want: true,
+		},
+		{
+			name: "paid to refunded",
+			from: order.Paid,
+			to:   order.Refunded,
+			want: true,
+		},
+		{
+			name: "created to paid",
+			from: order.Created,
+			to:   order.Paid,
+			want: false,
+		},
+		{
+			name: "paid to cancelled",
+			from: order.Paid,
+			to:   order.Cancelled,
+			want: false,
+		},
+		{
+			name: "paid to paid",
+			from: order.Paid,
+			to:   order.Paid,
+			want: false,
+		},
+		{
+			name: "cancelled to paid",
+			from: order.Cancelled,
+			to:   order.Paid,
+			want: false,
+		},
+		{
+			name: "refunded is final",
+			from: order.Refunded,
+			to:   order.Reserved,
+			want: false,
+		},
+		{
+			name: "expired is final",
+			from: order.Expired,
+			to:   order.Reserved,
+			want: false,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			require.Equal(t, tt.want, tt.from.CanTransitionTo(tt.to))
+		})
+	}
+}
+
+func TestOrder_CheckTransition(t *testing.T) {
+	t.Parallel()
+
+	entity := order.Order{
+		OrderID: 1,
+		Status:  order.Paid,
+	}
+
+	require.NoError(t, entity.CheckTransition(order.Refunded))
+
+	err := entity.CheckTransition(order.Cancelled)
+	require.ErrorIs(t, err, order.ErrIllegalTransition)
+
+	var transitionErr *order.TransitionError
+	require.ErrorAs(t, err, &transitionErr)
+	require.Equal(t, order.Paid, transitionErr.From)
+	require.Equal(t, order.Cancelled, transitionErr.To)
+	require.Equal(t, "order with id = 1 can not move from paid to cancelled", err.Error())
+}
+
+func TestOrder_RefundableAmount(t *testing.T) {
+	t.Parallel()
+
+	entity := order.Order{
+		Amount:         100,
+		CapturedAmount: 80,
+		RefundedAmount: 30,
+	}
+
+	require.Equal(t, int64(50), entity.RefundableAmount())
+}
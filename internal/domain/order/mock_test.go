@@ -10,7 +10,10 @@ import (
 
 	gomock "github.com/golang/mock/gomock"
 	account "github.com/maypok86/payment-api/internal/domain/account"
+	catalog "github.com/maypok86/payment-api/internal/domain/catalog"
+	ledger "github.com/maypok86/payment-api/internal/domain/ledger"
 	order "github.com/maypok86/payment-api/internal/domain/order"
+	outbox "github.com/maypok86/payment-api/internal/domain/outbox"
 	transaction "github.com/maypok86/payment-api/internal/domain/transaction"
 )
 
@@ -74,18 +77,19 @@ func (m *MockRepository) EXPECT() *MockRepositoryMockRecorder {
 	return m.recorder
 }
 
-// CancelOrder mocks base method.
-func (m *MockRepository) CancelOrder(ctx context.Context, dto order.CancelDTO) error {
+// AddRefund mocks base method.
+func (m *MockRepository) AddRefund(ctx context.Context, dto order.AddRefundDTO) (int64, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CancelOrder", ctx, dto)
-	ret0, _ := ret[0].(error)
-	return ret0
+	ret := m.ctrl.Call(m, "AddRefund", ctx, dto)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
-// CancelOrder indicates an expected call of CancelOrder.
-func (mr *MockRepositoryMockRecorder) CancelOrder(ctx, dto interface{}) *gomock.Call {
+// AddRefund indicates an expected call of AddRefund.
+func (mr *MockRepositoryMockRecorder) AddRefund(ctx, dto interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CancelOrder", reflect.TypeOf((*MockRepository)(nil).CancelOrder), ctx, dto)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddRefund", reflect.TypeOf((*MockRepository)(nil).AddRefund), ctx, dto)
 }
 
 // CreateOrder mocks base method.
@@ -103,18 +107,62 @@ func (mr *MockRepositoryMockRecorder) CreateOrder(ctx, dto interface{}) *gomock.
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateOrder", reflect.TypeOf((*MockRepository)(nil).CreateOrder), ctx, dto)
 }
 
-// PayForOrder mocks base method.
-func (m *MockRepository) PayForOrder(ctx context.Context, dto order.PayForDTO) error {
+// GetExpiredOrdersForUpdate mocks base method.
+func (m *MockRepository) GetExpiredOrdersForUpdate(ctx context.Context, limit uint64) ([]order.Order, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetExpiredOrdersForUpdate", ctx, limit)
+	ret0, _ := ret[0].([]order.Order)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetExpiredOrdersForUpdate indicates an expected call of GetExpiredOrdersForUpdate.
+func (mr *MockRepositoryMockRecorder) GetExpiredOrdersForUpdate(ctx, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetExpiredOrdersForUpdate", reflect.TypeOf((*MockRepository)(nil).GetExpiredOrdersForUpdate), ctx, limit)
+}
+
+// GetOrderForUpdate mocks base method.
+func (m *MockRepository) GetOrderForUpdate(ctx context.Context, orderID int64) (order.Order, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOrderForUpdate", ctx, orderID)
+	ret0, _ := ret[0].(order.Order)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOrderForUpdate indicates an expected call of GetOrderForUpdate.
+func (mr *MockRepositoryMockRecorder) GetOrderForUpdate(ctx, orderID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrderForUpdate", reflect.TypeOf((*MockRepository)(nil).GetOrderForUpdate), ctx, orderID)
+}
+
+// SetCapturedAmount mocks base method.
+func (m *MockRepository) SetCapturedAmount(ctx context.Context, dto order.SetCapturedAmountDTO) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetCapturedAmount", ctx, dto)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetCapturedAmount indicates an expected call of SetCapturedAmount.
+func (mr *MockRepositoryMockRecorder) SetCapturedAmount(ctx, dto interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetCapturedAmount", reflect.TypeOf((*MockRepository)(nil).SetCapturedAmount), ctx, dto)
+}
+
+// UpdateStatus mocks base method.
+func (m *MockRepository) UpdateStatus(ctx context.Context, dto order.UpdateStatusDTO) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "PayForOrder", ctx, dto)
+	ret := m.ctrl.Call(m, "UpdateStatus", ctx, dto)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// PayForOrder indicates an expected call of PayForOrder.
-func (mr *MockRepositoryMockRecorder) PayForOrder(ctx, dto interface{}) *gomock.Call {
+// UpdateStatus indicates an expected call of UpdateStatus.
+func (mr *MockRepositoryMockRecorder) UpdateStatus(ctx, dto interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PayForOrder", reflect.TypeOf((*MockRepository)(nil).PayForOrder), ctx, dto)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateStatus", reflect.TypeOf((*MockRepository)(nil).UpdateStatus), ctx, dto)
 }
 
 // MockTransactionRepository is a mock of TransactionRepository interface.
@@ -177,6 +225,36 @@ func (m *MockAccountRepository) EXPECT() *MockAccountRepositoryMockRecorder {
 	return m.recorder
 }
 
+// CaptureBalance mocks base method.
+func (m *MockAccountRepository) CaptureBalance(ctx context.Context, dto account.CaptureBalanceDTO) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CaptureBalance", ctx, dto)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CaptureBalance indicates an expected call of CaptureBalance.
+func (mr *MockAccountRepositoryMockRecorder) CaptureBalance(ctx, dto interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CaptureBalance", reflect.TypeOf((*MockAccountRepository)(nil).CaptureBalance), ctx, dto)
+}
+
+// RefundBalance mocks base method.
+func (m *MockAccountRepository) RefundBalance(ctx context.Context, dto account.RefundBalanceDTO) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RefundBalance", ctx, dto)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RefundBalance indicates an expected call of RefundBalance.
+func (mr *MockAccountRepositoryMockRecorder) RefundBalance(ctx, dto interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RefundBalance", reflect.TypeOf((*MockAccountRepository)(nil).RefundBalance), ctx, dto)
+}
+
 // ReserveBalance mocks base method.
 func (m *MockAccountRepository) ReserveBalance(ctx context.Context, dto account.ReserveBalanceDTO) (int64, error) {
 	m.ctrl.T.Helper()
@@ -206,3 +284,115 @@ func (mr *MockAccountRepositoryMockRecorder) ReturnBalance(ctx, dto interface{})
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReturnBalance", reflect.TypeOf((*MockAccountRepository)(nil).ReturnBalance), ctx, dto)
 }
+
+// MockLedgerRepository is a mock of LedgerRepository interface.
+type MockLedgerRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockLedgerRepositoryMockRecorder
+}
+
+// MockLedgerRepositoryMockRecorder is the mock recorder for MockLedgerRepository.
+type MockLedgerRepositoryMockRecorder struct {
+	mock *MockLedgerRepository
+}
+
+// NewMockLedgerRepository creates a new mock instance.
+func NewMockLedgerRepository(ctrl *gomock.Controller) *MockLedgerRepository {
+	mock := &MockLedgerRepository{ctrl: ctrl}
+	mock.recorder = &MockLedgerRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockLedgerRepository) EXPECT() *MockLedgerRepositoryMockRecorder {
+	return m.recorder
+}
+
+// CreatePosting mocks base method.
+func (m *MockLedgerRepository) CreatePosting(ctx context.Context, dto ledger.CreatePostingDTO) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreatePosting", ctx, dto)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreatePosting indicates an expected call of CreatePosting.
+func (mr *MockLedgerRepositoryMockRecorder) CreatePosting(ctx, dto interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePosting", reflect.TypeOf((*MockLedgerRepository)(nil).CreatePosting), ctx, dto)
+}
+
+// MockOutboxRepository is a mock of OutboxRepository interface.
+type MockOutboxRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockOutboxRepositoryMockRecorder
+}
+
+// MockOutboxRepositoryMockRecorder is the mock recorder for MockOutboxRepository.
+type MockOutboxRepositoryMockRecorder struct {
+	mock *MockOutboxRepository
+}
+
+// NewMockOutboxRepository creates a new mock instance.
+func NewMockOutboxRepository(ctrl *gomock.Controller) *MockOutboxRepository {
+	mock := &MockOutboxRepository{ctrl: ctrl}
+	mock.recorder = &MockOutboxRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockOutboxRepository) EXPECT() *MockOutboxRepositoryMockRecorder {
+	return m.recorder
+}
+
+// CreateEvent mocks base method.
+func (m *MockOutboxRepository) CreateEvent(ctx context.Context, dto outbox.CreateDTO) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateEvent", ctx, dto)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateEvent indicates an expected call of CreateEvent.
+func (mr *MockOutboxRepositoryMockRecorder) CreateEvent(ctx, dto interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateEvent", reflect.TypeOf((*MockOutboxRepository)(nil).CreateEvent), ctx, dto)
+}
+
+// MockServiceRepository is a mock of ServiceRepository interface.
+type MockServiceRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockServiceRepositoryMockRecorder
+}
+
+// MockServiceRepositoryMockRecorder is the mock recorder for MockServiceRepository.
+type MockServiceRepositoryMockRecorder struct {
+	mock *MockServiceRepository
+}
+
+// NewMockServiceRepository creates a new mock instance.
+func NewMockServiceRepository(ctrl *gomock.Controller) *MockServiceRepository {
+	mock := &MockServiceRepository{ctrl: ctrl}
+	mock.recorder = &MockServiceRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockServiceRepository) EXPECT() *MockServiceRepositoryMockRecorder {
+	return m.recorder
+}
+
+// GetServiceForShare mocks base method.
+func (m *MockServiceRepository) GetServiceForShare(ctx context.Context, serviceID int64) (catalog.Entity, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetServiceForShare", ctx, serviceID)
+	ret0, _ := ret[0].(catalog.Entity)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetServiceForShare indicates an expected call of GetServiceForShare.
+func (mr *MockServiceRepositoryMockRecorder) GetServiceForShare(ctx, serviceID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetServiceForShare", reflect.TypeOf((*MockServiceRepository)(nil).GetServiceForShare), ctx, serviceID)
+}
@@ -2,13 +2,27 @@ package order
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"time"
 
 	"github.com/maypok86/payment-api/internal/domain/account"
+	"github.com/maypok86/payment-api/internal/domain/catalog"
+	"github.com/maypok86/payment-api/internal/domain/currency"
+	"github.com/maypok86/payment-api/internal/domain/ledger"
+	"github.com/maypok86/payment-api/internal/domain/outbox"
 	"github.com/maypok86/payment-api/internal/domain/transaction"
 	"go.uber.org/zap"
 )
 
+const (
+	ExpiryActor  = "expiry-worker"
+	ExpiryReason = "expired"
+
+	// MaxReservationTTL is the longest reservation a client can ask for.
+	MaxReservationTTL = 30 * 24 * time.Hour
+)
+
 //go:generate mockgen -source=service.go -destination=mock_test.go -package=order_test
 
 type Transactor interface {
@@ -17,8 +31,11 @@ type Transactor interface {
 
 type Repository interface {
 	CreateOrder(ctx context.Context, dto CreateDTO) (Order, error)
-	PayForOrder(ctx context.Context, dto PayForDTO) error
-	CancelOrder(ctx context.Context, dto CancelDTO) error
+	GetOrderForUpdate(ctx context.Context, orderID int64) (Order, error)
+	UpdateStatus(ctx context.Context, dto UpdateStatusDTO) error
+	SetCapturedAmount(ctx context.Context, dto SetCapturedAmountDTO) error
+	AddRefund(ctx context.Context, dto AddRefundDTO) (int64, error)
+	GetExpiredOrdersForUpdate(ctx context.Context, limit uint64) ([]Order, error)
 }
 
 type TransactionRepository interface {
@@ -28,6 +45,20 @@ type TransactionRepository interface {
 type AccountRepository interface {
 	ReserveBalance(ctx context.Context, dto account.ReserveBalanceDTO) (int64, error)
 	ReturnBalance(ctx context.Context, dto account.ReturnBalanceDTO) (int64, error)
+	CaptureBalance(ctx context.Context, dto account.CaptureBalanceDTO) (int64, error)
+	RefundBalance(ctx context.Context, dto account.RefundBalanceDTO) (int64, error)
+}
+
+type LedgerRepository interface {
+	CreatePosting(ctx context.Context, dto ledger.CreatePostingDTO) error
+}
+
+type OutboxRepository interface {
+	CreateEvent(ctx context.Context, dto outbox.CreateDTO) error
+}
+
+type ServiceRepository interface {
+	GetServiceForShare(ctx context.Context, serviceID int64) (catalog.Entity, error)
 }
 
 type Service struct {
@@ -35,6 +66,10 @@ type Service struct {
 	repository            Repository
 	transactionRepository TransactionRepository
 	accountRepository     AccountRepository
+	ledgerRepository      LedgerRepository
+	outboxRepository      OutboxRepository
+	serviceRepository     ServiceRepository
+	reservationTTL        time.Duration
 	logger                *zap.Logger
 }
 
@@ -43,6 +78,10 @@ func NewService(
 	repository Repository,
 	transactionRepository TransactionRepository,
 	accountRepository AccountRepository,
+	ledgerRepository LedgerRepository,
+	outboxRepository OutboxRepository,
+	serviceRepository ServiceRepository,
+	reservationTTL time.Duration,
 	logger *zap.Logger,
 ) *Service {
 	return &Service{
@@ -50,15 +89,32 @@ func NewService(
 		repository:            repository,
 		transactionRepository: transactionRepository,
 		accountRepository:     accountRepository,
+		ledgerRepository:      ledgerRepository,
+		outboxRepository:      outboxRepository,
+		serviceRepository:     serviceRepository,
+		reservationTTL:        reservationTTL,
 		logger:                logger,
 	}
 }
 
 func (s *Service) CreateOrder(ctx context.Context, dto CreateDTO) (order Order, balance int64, err error) {
+	if err := dto.Validate(); err != nil {
+		return Order{}, 0, fmt.Errorf("create order: %w", err)
+	}
+
+	if dto.ReservationTTL == 0 {
+		dto.ReservationTTL = s.reservationTTL
+	}
+
 	err = s.transactor.WithTx(ctx, func(ctx context.Context) error {
+		if err := s.checkService(ctx, dto.ServiceID); err != nil {
+			return err
+		}
+
 		balance, err = s.accountRepository.ReserveBalance(ctx, account.ReserveBalanceDTO{
 			AccountID: dto.AccountID,
 			Amount:    dto.Amount,
+			Currency:  dto.Currency,
 		})
 		if err != nil {
 			return err
@@ -69,15 +125,39 @@ func (s *Service) CreateOrder(ctx context.Context, dto CreateDTO) (order Order,
 			return err
 		}
 
+		order, err = s.moveOrder(ctx, order, Reserved, dto.Actor, "")
+		if err != nil {
+			return err
+		}
+
 		transactionDTO := transaction.CreateDTO{
 			Type:        transaction.Reservation,
 			SenderID:    dto.AccountID,
 			ReceiverID:  dto.AccountID,
 			Amount:      dto.Amount,
-			Description: fmt.Sprintf("Reserve %d kopecks for order with id = %d", dto.Amount, dto.OrderID),
+			Currency:    dto.Currency,
+			Description: fmt.Sprintf("Reserve %s for order with id = %d", dto.Currency.Format(dto.Amount), dto.OrderID),
+		}
+
+		if err := s.transactionRepository.CreateTransaction(ctx, transactionDTO); err != nil {
+			return err
+		}
+
+		if err := s.ledgerRepository.CreatePosting(
+			ctx,
+			ledger.NewReservationDTO(dto.AccountID, dto.Amount, dto.Currency, transactionDTO.Description),
+		); err != nil {
+			return err
 		}
 
-		return s.transactionRepository.CreateTransaction(ctx, transactionDTO)
+		return s.outboxRepository.CreateEvent(ctx, outbox.NewOrderReservedDTO(outbox.OrderReservedPayload{
+			OrderID:   order.OrderID,
+			AccountID: order.AccountID,
+			ServiceID: order.ServiceID,
+			Amount:    order.Amount,
+			Currency:  order.Currency,
+			ExpiresAt: order.ExpiresAt,
+		}))
 	})
 	if err != nil {
 		return Order{}, 0, fmt.Errorf("create order: %w", err)
@@ -86,37 +166,135 @@ func (s *Service) CreateOrder(ctx context.Context, dto CreateDTO) (order Order,
 	return order, balance, nil
 }
 
+// checkService locks the service against deactivation until the end of the transaction
+// and returns an error if orders can not be created for it.
+func (s *Service) checkService(ctx context.Context, serviceID int64) error {
+	entity, err := s.serviceRepository.GetServiceForShare(ctx, serviceID)
+	if err != nil {
+		if errors.Is(err, catalog.ErrNotFound) {
+			return fmt.Errorf("check service: %w", ErrServiceNotFound)
+		}
+
+		return fmt.Errorf("check service: %w", err)
+	}
+
+	if !entity.Active {
+		return fmt.Errorf("check service: %w", ErrServiceInactive)
+	}
+
+	return nil
+}
+
 func (s *Service) PayForOrder(ctx context.Context, dto PayForDTO) error {
-	if err := s.repository.PayForOrder(ctx, dto); err != nil {
+	if err := dto.Validate(); err != nil {
+		return fmt.Errorf("pay for order: %w", err)
+	}
+
+	err := s.transactor.WithTx(ctx, func(ctx context.Context) error {
+		order, err := s.getOrderForUpdate(ctx, dto.OrderID, dto.AccountID, dto.ServiceID, dto.Amount, dto.Currency)
+		if err != nil {
+			return err
+		}
+
+		_, err = s.captureOrder(ctx, order, dto.Amount, dto.Actor, dto.Reason)
+
+		return err
+	})
+	if err != nil {
 		return fmt.Errorf("pay for order: %w", err)
 	}
 
 	return nil
 }
 
+// CaptureOrder charges part of the reserved amount and returns the rest of the reservation to the account.
+func (s *Service) CaptureOrder(ctx context.Context, dto CaptureDTO) (order Order, err error) {
+	if err := dto.Validate(); err != nil {
+		return Order{}, fmt.Errorf("capture order: %w", err)
+	}
+
+	err = s.transactor.WithTx(ctx, func(ctx context.Context) error {
+		order, err = s.repository.GetOrderForUpdate(ctx, dto.OrderID)
+		if err != nil {
+			return err
+		}
+
+		if order.AccountID != dto.AccountID || order.ServiceID != dto.ServiceID {
+			return ErrNotFound
+		}
+
+		if err := order.CheckCurrency(dto.Currency); err != nil {
+			return err
+		}
+
+		if err := order.CheckTransition(Paid); err != nil {
+			return err
+		}
+
+		if dto.Amount > order.Amount {
+			return ErrCaptureExceedsReserved
+		}
+
+		order, err = s.captureOrder(ctx, order, dto.Amount, dto.Actor, dto.Reason)
+
+		return err
+	})
+	if err != nil {
+		return Order{}, fmt.Errorf("capture order: %w", err)
+	}
+
+	return order, nil
+}
+
 func (s *Service) CancelOrder(ctx context.Context, dto CancelDTO) (balance int64, err error) {
+	if err := dto.Validate(); err != nil {
+		return 0, fmt.Errorf("cancel order: %w", err)
+	}
+
 	err = s.transactor.WithTx(ctx, func(ctx context.Context) error {
-		if err := s.repository.CancelOrder(ctx, dto); err != nil {
+		order, err := s.getOrderForUpdate(ctx, dto.OrderID, dto.AccountID, dto.ServiceID, dto.Amount, dto.Currency)
+		if err != nil {
+			return err
+		}
+
+		if _, err := s.moveOrder(ctx, order, Cancelled, dto.Actor, dto.Reason); err != nil {
 			return err
 		}
 
 		balance, err = s.accountRepository.ReturnBalance(ctx, account.ReturnBalanceDTO{
 			AccountID: dto.AccountID,
 			Amount:    dto.Amount,
+			Currency:  dto.Currency,
 		})
 		if err != nil {
 			return err
 		}
 
 		transactionDTO := transaction.CreateDTO{
-			Type:        transaction.CancelReservation,
-			SenderID:    dto.AccountID,
-			ReceiverID:  dto.AccountID,
-			Amount:      dto.Amount,
-			Description: fmt.Sprintf("Cancel reservation %d kopecks for order with id = %d", dto.Amount, dto.OrderID),
+			Type:       transaction.CancelReservation,
+			SenderID:   dto.AccountID,
+			ReceiverID: dto.AccountID,
+			Amount:     dto.Amount,
+			Currency:   dto.Currency,
+			Description: fmt.Sprintf(
+				"Cancel reservation %s for order with id = %d",
+				dto.Currency.Format(dto.Amount),
+				dto.OrderID,
+			),
 		}
 
-		return s.transactionRepository.CreateTransaction(ctx, transactionDTO)
+		if err := s.transactionRepository.CreateTransaction(ctx, transactionDTO); err != nil {
+			return err
+		}
+
+		if err := s.ledgerRepository.CreatePosting(
+			ctx,
+			ledger.NewCancelReservationDTO(dto.AccountID, dto.Amount, dto.Currency, transactionDTO.Description),
+		); err != nil {
+			return err
+		}
+
+		return s.outboxRepository.CreateEvent(ctx, newOrderCancelledDTO(order, dto.Reason))
 	})
 	if err != nil {
 		return 0, fmt.Errorf("cancel order: %w", err)
@@ -124,3 +302,316 @@ func (s *Service) CancelOrder(ctx context.Context, dto CancelDTO) (balance int64
 
 	return balance, nil
 }
+
+func (s *Service) RefundOrder(ctx context.Context, dto RefundDTO) (order Order, balance int64, err error) {
+	if err := dto.Validate(); err != nil {
+		return Order{}, 0, fmt.Errorf("refund order: %w", err)
+	}
+
+	err = s.transactor.WithTx(ctx, func(ctx context.Context) error {
+		order, err = s.repository.GetOrderForUpdate(ctx, dto.OrderID)
+		if err != nil {
+			return err
+		}
+
+		if order.AccountID != dto.AccountID || order.ServiceID != dto.ServiceID {
+			return ErrNotFound
+		}
+
+		if err := order.CheckCurrency(dto.Currency); err != nil {
+			return err
+		}
+
+		if err := order.CheckTransition(Refunded); err != nil {
+			return err
+		}
+
+		amount := dto.Amount
+		if amount == 0 {
+			amount = order.RefundableAmount()
+		}
+		if amount > order.RefundableAmount() {
+			return ErrRefundExceedsPaid
+		}
+
+		order.RefundedAmount, err = s.repository.AddRefund(ctx, AddRefundDTO{
+			OrderID: order.OrderID,
+			Amount:  amount,
+		})
+		if err != nil {
+			return err
+		}
+
+		if order.RefundableAmount() == 0 {
+			order, err = s.moveOrder(ctx, order, Refunded, dto.Actor, dto.Reason)
+			if err != nil {
+				return err
+			}
+		}
+
+		balance, err = s.accountRepository.RefundBalance(ctx, account.RefundBalanceDTO{
+			AccountID: dto.AccountID,
+			Amount:    amount,
+			Currency:  dto.Currency,
+		})
+		if err != nil {
+			return err
+		}
+
+		transactionDTO := transaction.CreateDTO{
+			Type:        transaction.Refund,
+			SenderID:    dto.AccountID,
+			ReceiverID:  dto.AccountID,
+			Amount:      amount,
+			Currency:    dto.Currency,
+			Description: fmt.Sprintf("Refund %s for order with id = %d", dto.Currency.Format(amount), dto.OrderID),
+		}
+
+		if err := s.transactionRepository.CreateTransaction(ctx, transactionDTO); err != nil {
+			return err
+		}
+
+		if err := s.ledgerRepository.CreatePosting(
+			ctx,
+			ledger.NewRefundDTO(dto.AccountID, amount, dto.Currency, transactionDTO.Description),
+		); err != nil {
+			return err
+		}
+
+		return s.outboxRepository.CreateEvent(ctx, outbox.NewOrderRefundedDTO(outbox.OrderRefundedPayload{
+			OrderID:        order.OrderID,
+			AccountID:      order.AccountID,
+			ServiceID:      order.ServiceID,
+			Amount:         amount,
+			RefundedAmount: order.RefundedAmount,
+			Currency:       order.Currency,
+		}))
+	})
+	if err != nil {
+		return Order{}, 0, fmt.Errorf("refund order: %w", err)
+	}
+
+	return order, balance, nil
+}
+
+// ExpireOrders expires up to limit reserved orders whose reservation TTL has passed and returns their money.
+// Orders locked by another replica are skipped, so the method is safe to run concurrently. Every order is
+// expired in its own savepoint: an order that fails is logged and left reserved for the next run, and the
+// rest of the batch is still expired.
+func (s *Service) ExpireOrders(ctx context.Context, limit uint64) (expired int, err error) {
+	err = s.transactor.WithTx(ctx, func(ctx context.Context) error {
+		orders, err := s.repository.GetExpiredOrdersForUpdate(ctx, limit)
+		if err != nil {
+			return err
+		}
+
+		expired = 0
+		for _, order := range orders {
+			if err := s.transactor.WithTx(ctx, func(ctx context.Context) error {
+				return s.expireOrder(ctx, order)
+			}); err != nil {
+				s.logger.Error("expire order", zap.Int64("order_id", order.OrderID), zap.Error(err))
+				continue
+			}
+
+			expired++
+		}
+
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("expire orders: %w", err)
+	}
+
+	return expired, nil
+}
+
+func (s *Service) expireOrder(ctx context.Context, order Order) error {
+	if _, err := s.moveOrder(ctx, order, Expired, ExpiryActor, ExpiryReason); err != nil {
+		return err
+	}
+
+	if _, err := s.accountRepository.ReturnBalance(ctx, account.ReturnBalanceDTO{
+		AccountID: order.AccountID,
+		Amount:    order.Amount,
+		Currency:  order.Currency,
+	}); err != nil {
+		return err
+	}
+
+	transactionDTO := transaction.CreateDTO{
+		Type:       transaction.CancelReservation,
+		SenderID:   order.AccountID,
+		ReceiverID: order.AccountID,
+		Amount:     order.Amount,
+		Currency:   order.Currency,
+		Description: fmt.Sprintf(
+			"Cancel reservation %s for order with id = %d, reason: %s",
+			order.Currency.Format(order.Amount),
+			order.OrderID,
+			ExpiryReason,
+		),
+	}
+
+	if err := s.transactionRepository.CreateTransaction(ctx, transactionDTO); err != nil {
+		return err
+	}
+
+	if err := s.ledgerRepository.CreatePosting(
+		ctx,
+		ledger.NewCancelReservationDTO(order.AccountID, order.Amount, order.Currency, transactionDTO.Description),
+	); err != nil {
+		return err
+	}
+
+	return s.outboxRepository.CreateEvent(ctx, newOrderCancelledDTO(order, ExpiryReason))
+}
+
+func (s *Service) captureOrder(ctx context.Context, order Order, amount int64, actor, reason string) (Order, error) {
+	order, err := s.moveOrder(ctx, order, Paid, actor, reason)
+	if err != nil {
+		return Order{}, err
+	}
+
+	if err := s.repository.SetCapturedAmount(ctx, SetCapturedAmountDTO{
+		OrderID: order.OrderID,
+		Amount:  amount,
+	}); err != nil {
+		return Order{}, err
+	}
+
+	order.CapturedAmount = amount
+
+	if _, err := s.accountRepository.CaptureBalance(ctx, account.CaptureBalanceDTO{
+		AccountID: order.AccountID,
+		Amount:    amount,
+		Currency:  order.Currency,
+	}); err != nil {
+		return Order{}, err
+	}
+
+	paymentDTO := transaction.CreateDTO{
+		Type:        transaction.Payment,
+		SenderID:    order.AccountID,
+		ReceiverID:  order.AccountID,
+		Amount:      amount,
+		Currency:    order.Currency,
+		Description: fmt.Sprintf("Pay %s for order with id = %d", order.Currency.Format(amount), order.OrderID),
+	}
+
+	if err := s.transactionRepository.CreateTransaction(ctx, paymentDTO); err != nil {
+		return Order{}, err
+	}
+
+	if err := s.ledgerRepository.CreatePosting(
+		ctx,
+		ledger.NewPaymentDTO(amount, order.Currency, paymentDTO.Description),
+	); err != nil {
+		return Order{}, err
+	}
+
+	if err := s.outboxRepository.CreateEvent(ctx, outbox.NewOrderPaidDTO(outbox.OrderPaidPayload{
+		OrderID:        order.OrderID,
+		AccountID:      order.AccountID,
+		ServiceID:      order.ServiceID,
+		Amount:         order.Amount,
+		CapturedAmount: order.CapturedAmount,
+		Currency:       order.Currency,
+	})); err != nil {
+		return Order{}, err
+	}
+
+	rest := order.Amount - amount
+	if rest == 0 {
+		return order, nil
+	}
+
+	if _, err := s.accountRepository.ReturnBalance(ctx, account.ReturnBalanceDTO{
+		AccountID: order.AccountID,
+		Amount:    rest,
+		Currency:  order.Currency,
+	}); err != nil {
+		return Order{}, err
+	}
+
+	returnDTO := transaction.CreateDTO{
+		Type:       transaction.CancelReservation,
+		SenderID:   order.AccountID,
+		ReceiverID: order.AccountID,
+		Amount:     rest,
+		Currency:   order.Currency,
+		Description: fmt.Sprintf(
+			"Return %s uncaptured for order with id = %d",
+			order.Currency.Format(rest),
+			order.OrderID,
+		),
+	}
+
+	if err := s.transactionRepository.CreateTransaction(ctx, returnDTO); err != nil {
+		return Order{}, err
+	}
+
+	if err := s.ledgerRepository.CreatePosting(
+		ctx,
+		ledger.NewCancelReservationDTO(order.AccountID, rest, order.Currency, returnDTO.Description),
+	); err != nil {
+		return Order{}, err
+	}
+
+	return order, nil
+}
+
+func newOrderCancelledDTO(order Order, reason string) outbox.CreateDTO {
+	return outbox.NewOrderCancelledDTO(outbox.OrderCancelledPayload{
+		OrderID:   order.OrderID,
+		AccountID: order.AccountID,
+		ServiceID: order.ServiceID,
+		Amount:    order.Amount,
+		Currency:  order.Currency,
+		Reason:    reason,
+	})
+}
+
+func (s *Service) getOrderForUpdate(
+	ctx context.Context,
+	orderID int64,
+	accountID int64,
+	serviceID int64,
+	amount int64,
+	cur currency.Currency,
+) (Order, error) {
+	order, err := s.repository.GetOrderForUpdate(ctx, orderID)
+	if err != nil {
+		return Order{}, err
+	}
+
+	if !order.Matches(accountID, serviceID, amount) {
+		return Order{}, ErrNotFound
+	}
+
+	if err := order.CheckCurrency(cur); err != nil {
+		return Order{}, err
+	}
+
+	return order, nil
+}
+
+func (s *Service) moveOrder(ctx context.Context, order Order, to Status, actor, reason string) (Order, error) {
+	if err := order.CheckTransition(to); err != nil {
+		return Order{}, err
+	}
+
+	if err := s.repository.UpdateStatus(ctx, UpdateStatusDTO{
+		OrderID: order.OrderID,
+		From:    order.Status,
+		To:      to,
+		Actor:   actor,
+		Reason:  reason,
+	}); err != nil {
+		return Order{}, err
+	}
+
+	order.Status = to
+
+	return order, nil
+}
@@ -11,12 +11,22 @@ import (
 
 	"github.com/golang/mock/gomock"
 	"github.com/maypok86/payment-api/internal/domain/account"
+	"github.com/maypok86/payment-api/internal/domain/catalog"
+	"github.com/maypok86/payment-api/internal/domain/currency"
+	"github.com/maypok86/payment-api/internal/domain/ledger"
 	"github.com/maypok86/payment-api/internal/domain/order"
+	"github.com/maypok86/payment-api/internal/domain/outbox"
 	"github.com/maypok86/payment-api/internal/domain/transaction"
 	"github.com/maypok86/payment-api/internal/pkg/logger"
 	"github.com/stretchr/testify/require"
 )
 
+const (
+	reservationTTL    = time.Hour
+	inactiveServiceID = int64(2)
+	unknownServiceID  = int64(3)
+)
+
 type fakeTransactor struct {
 	txErr error
 }
@@ -35,10 +45,31 @@ func (ft fakeTransactor) WithTx(ctx context.Context, fn func(ctx context.Context
 	return err
 }
 
+// fakeServiceRepository knows an active service for every id except inactiveServiceID and unknownServiceID.
+type fakeServiceRepository struct{}
+
+func (fakeServiceRepository) GetServiceForShare(_ context.Context, serviceID int64) (catalog.Entity, error) {
+	switch serviceID {
+	case unknownServiceID:
+		return catalog.Entity{}, catalog.ErrNotFound
+	case inactiveServiceID:
+		return catalog.Entity{ServiceID: serviceID, Name: "Inactive", Active: false}, nil
+	}
+
+	return catalog.Entity{ServiceID: serviceID, Name: "Active", Active: true}, nil
+}
+
 func mockService(
 	t *testing.T,
 	txErr error,
-) (*order.Service, *MockRepository, *MockTransactionRepository, *MockAccountRepository) {
+) (
+	*order.Service,
+	*MockRepository,
+	*MockTransactionRepository,
+	*MockAccountRepository,
+	*MockLedgerRepository,
+	*MockOutboxRepository,
+) {
 	t.Helper()
 
 	mockCtrl := gomock.NewController(t)
@@ -50,9 +81,21 @@ func mockService(
 	repository := NewMockRepository(mockCtrl)
 	accountRepository := NewMockAccountRepository(mockCtrl)
 	transactionRepository := NewMockTransactionRepository(mockCtrl)
-	service := order.NewService(transactor, repository, transactionRepository, accountRepository, l)
+	ledgerRepository := NewMockLedgerRepository(mockCtrl)
+	outboxRepository := NewMockOutboxRepository(mockCtrl)
+	service := order.NewService(
+		transactor,
+		repository,
+		transactionRepository,
+		accountRepository,
+		ledgerRepository,
+		outboxRepository,
+		fakeServiceRepository{},
+		reservationTTL,
+		l,
+	)
 
-	return service, repository, transactionRepository, accountRepository
+	return service, repository, transactionRepository, accountRepository, ledgerRepository, outboxRepository
 }
 
 func TestService_CreateOrder(t *testing.T) {
@@ -60,46 +103,71 @@ func TestService_CreateOrder(t *testing.T) {
 
 	ctx := context.Background()
 
-	fakeOrder := order.Order{
-		OrderID:     1,
-		AccountID:   1,
-		ServiceID:   1,
-		Amount:      100,
-		IsPaid:      false,
-		IsCancelled: false,
-		CreatedAt:   time.Now(),
-		UpdatedAt:   time.Now(),
+	createdOrder := order.Order{
+		OrderID:   1,
+		AccountID: 1,
+		ServiceID: 1,
+		Amount:    100,
+		Currency:  currency.RUB,
+		Status:    order.Created,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
 	}
+	reservedOrder := createdOrder
+	reservedOrder.Status = order.Reserved
 	fakeBalance := int64(1000)
 	dto := order.CreateDTO{
-		OrderID:   fakeOrder.OrderID,
-		AccountID: fakeOrder.AccountID,
-		ServiceID: fakeOrder.ServiceID,
-		Amount:    fakeOrder.Amount,
+		OrderID:   createdOrder.OrderID,
+		AccountID: createdOrder.AccountID,
+		ServiceID: createdOrder.ServiceID,
+		Amount:    createdOrder.Amount,
+		Currency:  createdOrder.Currency,
+		Actor:     "api",
 	}
+	repositoryDTO := dto
+	repositoryDTO.ReservationTTL = reservationTTL
 	accountDTO := account.ReserveBalanceDTO{
-		AccountID: fakeOrder.AccountID,
-		Amount:    fakeOrder.Amount,
+		AccountID: createdOrder.AccountID,
+		Amount:    createdOrder.Amount,
+		Currency:  createdOrder.Currency,
+	}
+	statusDTO := order.UpdateStatusDTO{
+		OrderID: dto.OrderID,
+		From:    order.Created,
+		To:      order.Reserved,
+		Actor:   dto.Actor,
 	}
 	transactionDTO := transaction.CreateDTO{
 		Type:        transaction.Reservation,
 		SenderID:    dto.AccountID,
 		ReceiverID:  dto.AccountID,
 		Amount:      dto.Amount,
-		Description: fmt.Sprintf("Reserve %d kopecks for order with id = %d", dto.Amount, dto.OrderID),
+		Currency:    dto.Currency,
+		Description: fmt.Sprintf("Reserve %s for order with id = %d", dto.Currency.Format(dto.Amount), dto.OrderID),
 	}
+	postingDTO := ledger.NewReservationDTO(dto.AccountID, dto.Amount, dto.Currency, transactionDTO.Description)
+	eventDTO := outbox.NewOrderReservedDTO(outbox.OrderReservedPayload{
+		OrderID:   reservedOrder.OrderID,
+		AccountID: reservedOrder.AccountID,
+		ServiceID: reservedOrder.ServiceID,
+		Amount:    reservedOrder.Amount,
+		Currency:  reservedOrder.Currency,
+		ExpiresAt: reservedOrder.ExpiresAt,
+	})
 	txErr := errors.New("transaction error")
 	repositoryErr := errors.New("repository error")
 	accountRepositoryErr := errors.New("account repository error")
 	transactionRepositoryErr := errors.New("transaction repository error")
+	ledgerRepositoryErr := errors.New("ledger repository error")
+	outboxRepositoryErr := errors.New("outbox repository error")
 
-	type args struct {
-		dto            order.CreateDTO
-		accountDTO     account.ReserveBalanceDTO
-		transactionDTO transaction.CreateDTO
-	}
-
-	type mockBehavior func(r *MockRepository, tr *MockTransactionRepository, ar *MockAccountRepository)
+	type mockBehavior func(
+		r *MockRepository,
+		tr *MockTransactionRepository,
+		ar *MockAccountRepository,
+		lr *MockLedgerRepository,
+		or *MockOutboxRepository,
+	)
 
 	type want struct {
 		order   order.Order
@@ -108,8 +176,8 @@ func TestService_CreateOrder(t *testing.T) {
 
 	tests := []struct {
 		name      string
+		serviceID int64
 		mock      mockBehavior
-		args      args
 		want      want
 		wantedErr error
 		txErr     error
@@ -120,22 +188,20 @@ func TestService_CreateOrder(t *testing.T) {
 				repository *MockRepository,
 				transactionRepository *MockTransactionRepository,
 				accountRepository *MockAccountRepository,
+				ledgerRepository *MockLedgerRepository,
+				outboxRepository *MockOutboxRepository,
 			) {
 				accountRepository.EXPECT().ReserveBalance(ctx, accountDTO).Return(fakeBalance-accountDTO.Amount, nil)
-				repository.EXPECT().CreateOrder(ctx, dto).Return(fakeOrder, nil)
+				repository.EXPECT().CreateOrder(ctx, repositoryDTO).Return(createdOrder, nil)
+				repository.EXPECT().UpdateStatus(ctx, statusDTO).Return(nil)
 				transactionRepository.EXPECT().CreateTransaction(ctx, transactionDTO).Return(nil)
-			},
-			args: args{
-				dto:            dto,
-				accountDTO:     accountDTO,
-				transactionDTO: transactionDTO,
+				ledgerRepository.EXPECT().CreatePosting(ctx, postingDTO).Return(nil)
+				outboxRepository.EXPECT().CreateEvent(ctx, eventDTO).Return(nil)
 			},
 			want: want{
-				order:   fakeOrder,
+				order:   reservedOrder,
 				balance: fakeBalance - accountDTO.Amount,
 			},
-			wantedErr: nil,
-			txErr:     nil,
 		},
 		{
 			name: "account repository error",
@@ -143,17 +209,41 @@ func TestService_CreateOrder(t *testing.T) {
 				repository *MockRepository,
 				transactionRepository *MockTransactionRepository,
 				accountRepository *MockAccountRepository,
+				ledgerRepository *MockLedgerRepository,
+				outboxRepository *MockOutboxRepository,
 			) {
 				accountRepository.EXPECT().ReserveBalance(ctx, accountDTO).Return(int64(0), accountRepositoryErr)
 			},
-			args: args{
-				dto:            dto,
-				accountDTO:     accountDTO,
-				transactionDTO: transactionDTO,
-			},
 			want:      want{},
 			wantedErr: accountRepositoryErr,
-			txErr:     nil,
+		},
+		{
+			name:      "service not found",
+			serviceID: unknownServiceID,
+			mock: func(
+				repository *MockRepository,
+				transactionRepository *MockTransactionRepository,
+				accountRepository *MockAccountRepository,
+				ledgerRepository *MockLedgerRepository,
+				outboxRepository *MockOutboxRepository,
+			) {
+			},
+			want:      want{},
+			wantedErr: order.ErrServiceNotFound,
+		},
+		{
+			name:      "service is not active",
+			serviceID: inactiveServiceID,
+			mock: func(
+				repository *MockRepository,
+				transactionRepository *MockTransactionRepository,
+				accountRepository *MockAccountRepository,
+				ledgerRepository *MockLedgerRepository,
+				outboxRepository *MockOutboxRepository,
+			) {
+			},
+			want:      want{},
+			wantedErr: order.ErrServiceInactive,
 		},
 		{
 			name: "repository error",
@@ -161,18 +251,30 @@ func TestService_CreateOrder(t *testing.T) {
 				repository *MockRepository,
 				transactionRepository *MockTransactionRepository,
 				accountRepository *MockAccountRepository,
+				ledgerRepository *MockLedgerRepository,
+				outboxRepository *MockOutboxRepository,
 			) {
 				accountRepository.EXPECT().ReserveBalance(ctx, accountDTO).Return(fakeBalance-accountDTO.Amount, nil)
-				repository.EXPECT().CreateOrder(ctx, dto).Return(order.Order{}, repositoryErr)
+				repository.EXPECT().CreateOrder(ctx, repositoryDTO).Return(order.Order{}, repositoryErr)
 			},
-			args: args{
-				dto:            dto,
-				accountDTO:     accountDTO,
-				transactionDTO: transactionDTO,
+			want:      want{},
+			wantedErr: repositoryErr,
+		},
+		{
+			name: "update status error",
+			mock: func(
+				repository *MockRepository,
+				transactionRepository *MockTransactionRepository,
+				accountRepository *MockAccountRepository,
+				ledgerRepository *MockLedgerRepository,
+				outboxRepository *MockOutboxRepository,
+			) {
+				accountRepository.EXPECT().ReserveBalance(ctx, accountDTO).Return(fakeBalance-accountDTO.Amount, nil)
+				repository.EXPECT().CreateOrder(ctx, repositoryDTO).Return(createdOrder, nil)
+				repository.EXPECT().UpdateStatus(ctx, statusDTO).Return(repositoryErr)
 			},
 			want:      want{},
 			wantedErr: repositoryErr,
-			txErr:     nil,
 		},
 		{
 			name: "transaction repository error",
@@ -180,39 +282,71 @@ func TestService_CreateOrder(t *testing.T) {
 				repository *MockRepository,
 				transactionRepository *MockTransactionRepository,
 				accountRepository *MockAccountRepository,
+				ledgerRepository *MockLedgerRepository,
+				outboxRepository *MockOutboxRepository,
 			) {
 				accountRepository.EXPECT().ReserveBalance(ctx, accountDTO).Return(fakeBalance-accountDTO.Amount, nil)
-				repository.EXPECT().CreateOrder(ctx, dto).Return(fakeOrder, nil)
+				repository.EXPECT().CreateOrder(ctx, repositoryDTO).Return(createdOrder, nil)
+				repository.EXPECT().UpdateStatus(ctx, statusDTO).Return(nil)
 				transactionRepository.EXPECT().CreateTransaction(ctx, transactionDTO).Return(transactionRepositoryErr)
 			},
-			args: args{
-				dto:            dto,
-				accountDTO:     accountDTO,
-				transactionDTO: transactionDTO,
-			},
 			want:      want{},
 			wantedErr: transactionRepositoryErr,
-			txErr:     nil,
 		},
 		{
-			name: "transaction error",
+			name: "ledger repository error",
 			mock: func(
 				repository *MockRepository,
 				transactionRepository *MockTransactionRepository,
 				accountRepository *MockAccountRepository,
+				ledgerRepository *MockLedgerRepository,
+				outboxRepository *MockOutboxRepository,
 			) {
 				accountRepository.EXPECT().ReserveBalance(ctx, accountDTO).Return(fakeBalance-accountDTO.Amount, nil)
-				repository.EXPECT().CreateOrder(ctx, dto).Return(fakeOrder, nil)
+				repository.EXPECT().CreateOrder(ctx, repositoryDTO).Return(createdOrder, nil)
+				repository.EXPECT().UpdateStatus(ctx, statusDTO).Return(nil)
 				transactionRepository.EXPECT().CreateTransaction(ctx, transactionDTO).Return(nil)
-			},
-			args: args{
-				dto:            dto,
-				accountDTO:     accountDTO,
-				transactionDTO: transactionDTO,
+				ledgerRepository.EXPECT().CreatePosting(ctx, postingDTO).Return(ledgerRepositoryErr)
 			},
 			want:      want{},
-			wantedErr: nil,
-			txErr:     txErr,
+			wantedErr: ledgerRepositoryErr,
+		},
+		{
+			name: "outbox repository error",
+			mock: func(
+				repository *MockRepository,
+				transactionRepository *MockTransactionRepository,
+				accountRepository *MockAccountRepository,
+				ledgerRepository *MockLedgerRepository,
+				outboxRepository *MockOutboxRepository,
+			) {
+				accountRepository.EXPECT().ReserveBalance(ctx, accountDTO).Return(fakeBalance-accountDTO.Amount, nil)
+				repository.EXPECT().CreateOrder(ctx, repositoryDTO).Return(createdOrder, nil)
+				repository.EXPECT().UpdateStatus(ctx, statusDTO).Return(nil)
+				transactionRepository.EXPECT().CreateTransaction(ctx, transactionDTO).Return(nil)
+				ledgerRepository.EXPECT().CreatePosting(ctx, postingDTO).Return(nil)
+				outboxRepository.EXPECT().CreateEvent(ctx, eventDTO).Return(outboxRepositoryErr)
+			},
+			wantedErr: outboxRepositoryErr,
+		},
+		{
+			name: "transaction error",
+			mock: func(
+				repository *MockRepository,
+				transactionRepository *MockTransactionRepository,
+				accountRepository *MockAccountRepository,
+				ledgerRepository *MockLedgerRepository,
+				outboxRepository *MockOutboxRepository,
+			) {
+				accountRepository.EXPECT().ReserveBalance(ctx, accountDTO).Return(fakeBalance-accountDTO.Amount, nil)
+				repository.EXPECT().CreateOrder(ctx, repositoryDTO).Return(createdOrder, nil)
+				repository.EXPECT().UpdateStatus(ctx, statusDTO).Return(nil)
+				transactionRepository.EXPECT().CreateTransaction(ctx, transactionDTO).Return(nil)
+				ledgerRepository.EXPECT().CreatePosting(ctx, postingDTO).Return(nil)
+				outboxRepository.EXPECT().CreateEvent(ctx, eventDTO).Return(nil)
+			},
+			want:  want{},
+			txErr: txErr,
 		},
 	}
 
@@ -222,10 +356,17 @@ func TestService_CreateOrder(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			t.Parallel()
 
-			service, repository, transactionRepository, accountRepository := mockService(t, tt.txErr)
+			service, repository, transactionRepository, accountRepository, ledgerRepository, outboxRepository := mockService(
+				t,
+				tt.txErr,
+			)
 
-			tt.mock(repository, transactionRepository, accountRepository)
-			gotOrder, gotBalance, err := service.CreateOrder(ctx, tt.args.dto)
+			tt.mock(repository, transactionRepository, accountRepository, ledgerRepository, outboxRepository)
+			createDTO := dto
+			if tt.serviceID != 0 {
+				createDTO.ServiceID = tt.serviceID
+			}
+			gotOrder, gotBalance, err := service.CreateOrder(ctx, createDTO)
 			if tt.wantedErr != nil {
 				require.ErrorIs(t, err, tt.wantedErr)
 			}
@@ -248,39 +389,248 @@ func TestService_PayForOrder(t *testing.T) {
 		AccountID: 1,
 		ServiceID: 1,
 		Amount:    100,
+		Currency:  currency.RUB,
+		Actor:     "api",
+		Reason:    "service is provided",
 	}
-
-	type mockBehavior func(r *MockRepository)
-
-	type args struct {
-		dto order.PayForDTO
+	reservedOrder := order.Order{
+		OrderID:   dto.OrderID,
+		AccountID: dto.AccountID,
+		ServiceID: dto.ServiceID,
+		Amount:    dto.Amount,
+		Currency:  currency.RUB,
+		Status:    order.Reserved,
+	}
+	paidOrder := reservedOrder
+	paidOrder.Status = order.Paid
+	anotherOrder := reservedOrder
+	anotherOrder.Amount = 200
+	usdOrder := reservedOrder
+	usdOrder.Currency = currency.USD
+	statusDTO := order.UpdateStatusDTO{
+		OrderID: dto.OrderID,
+		From:    order.Reserved,
+		To:      order.Paid,
+		Actor:   dto.Actor,
+		Reason:  dto.Reason,
+	}
+	transactionDTO := transaction.CreateDTO{
+		Type:        transaction.Payment,
+		SenderID:    dto.AccountID,
+		ReceiverID:  dto.AccountID,
+		Amount:      dto.Amount,
+		Currency:    dto.Currency,
+		Description: fmt.Sprintf("Pay %s for order with id = %d", dto.Currency.Format(dto.Amount), dto.OrderID),
+	}
+	capturedDTO := order.SetCapturedAmountDTO{
+		OrderID: dto.OrderID,
+		Amount:  dto.Amount,
 	}
+	captureBalanceDTO := account.CaptureBalanceDTO{
+		AccountID: dto.AccountID,
+		Amount:    dto.Amount,
+		Currency:  dto.Currency,
+	}
+	postingDTO := ledger.NewPaymentDTO(dto.Amount, dto.Currency, transactionDTO.Description)
+	eventDTO := outbox.NewOrderPaidDTO(outbox.OrderPaidPayload{
+		OrderID:        dto.OrderID,
+		AccountID:      dto.AccountID,
+		ServiceID:      dto.ServiceID,
+		Amount:         dto.Amount,
+		CapturedAmount: dto.Amount,
+		Currency:       dto.Currency,
+	})
+	txErr := errors.New("transaction error")
+	repositoryErr := errors.New("repository error")
+	transactionRepositoryErr := errors.New("transaction repository error")
+	ledgerRepositoryErr := errors.New("ledger repository error")
+	outboxRepositoryErr := errors.New("outbox repository error")
+
+	type mockBehavior func(
+		r *MockRepository,
+		tr *MockTransactionRepository,
+		ar *MockAccountRepository,
+		lr *MockLedgerRepository,
+		or *MockOutboxRepository,
+	)
 
 	tests := []struct {
-		name    string
-		mock    mockBehavior
-		args    args
-		wantErr bool
+		name      string
+		mock      mockBehavior
+		wantedErr error
+		txErr     error
 	}{
 		{
 			name: "success pay for order",
-			mock: func(repository *MockRepository) {
-				repository.EXPECT().PayForOrder(ctx, dto).Return(nil)
+			mock: func(
+				repository *MockRepository,
+				transactionRepository *MockTransactionRepository,
+				accountRepository *MockAccountRepository,
+				ledgerRepository *MockLedgerRepository,
+				outboxRepository *MockOutboxRepository,
+			) {
+				repository.EXPECT().GetOrderForUpdate(ctx, dto.OrderID).Return(reservedOrder, nil)
+				repository.EXPECT().UpdateStatus(ctx, statusDTO).Return(nil)
+				repository.EXPECT().SetCapturedAmount(ctx, capturedDTO).Return(nil)
+				accountRepository.EXPECT().CaptureBalance(ctx, captureBalanceDTO).Return(int64(0), nil)
+				transactionRepository.EXPECT().CreateTransaction(ctx, transactionDTO).Return(nil)
+				ledgerRepository.EXPECT().CreatePosting(ctx, postingDTO).Return(nil)
+				outboxRepository.EXPECT().CreateEvent(ctx, eventDTO).Return(nil)
 			},
-			args: args{
-				dto: dto,
+		},
+		{
+			name: "order not found",
+			mock: func(
+				repository *MockRepository,
+				transactionRepository *MockTransactionRepository,
+				accountRepository *MockAccountRepository,
+				ledgerRepository *MockLedgerRepository,
+				outboxRepository *MockOutboxRepository,
+			) {
+				repository.EXPECT().GetOrderForUpdate(ctx, dto.OrderID).Return(order.Order{}, order.ErrNotFound)
 			},
-			wantErr: false,
+			wantedErr: order.ErrNotFound,
 		},
 		{
-			name: "repository error",
-			mock: func(repository *MockRepository) {
-				repository.EXPECT().PayForOrder(ctx, dto).Return(errors.New("pay for order repository error"))
+			name: "order does not match request",
+			mock: func(
+				repository *MockRepository,
+				transactionRepository *MockTransactionRepository,
+				accountRepository *MockAccountRepository,
+				ledgerRepository *MockLedgerRepository,
+				outboxRepository *MockOutboxRepository,
+			) {
+				repository.EXPECT().GetOrderForUpdate(ctx, dto.OrderID).Return(anotherOrder, nil)
+			},
+			wantedErr: order.ErrNotFound,
+		},
+		{
+			name: "order currency does not match request",
+			mock: func(
+				repository *MockRepository,
+				transactionRepository *MockTransactionRepository,
+				accountRepository *MockAccountRepository,
+				ledgerRepository *MockLedgerRepository,
+				outboxRepository *MockOutboxRepository,
+			) {
+				repository.EXPECT().GetOrderForUpdate(ctx, dto.OrderID).Return(usdOrder, nil)
+			},
+			wantedErr: currency.ErrMismatch,
+		},
+		{
+			name: "order is already paid",
+			mock: func(
+				repository *MockRepository,
+				transactionRepository *MockTransactionRepository,
+				accountRepository *MockAccountRepository,
+				ledgerRepository *MockLedgerRepository,
+				outboxRepository *MockOutboxRepository,
+			) {
+				repository.EXPECT().GetOrderForUpdate(ctx, dto.OrderID).Return(paidOrder, nil)
+			},
+			wantedErr: order.ErrIllegalTransition,
+		},
+		{
+			name: "update status error",
+			mock: func(
+				repository *MockRepository,
+				transactionRepository *MockTransactionRepository,
+				accountRepository *MockAccountRepository,
+				ledgerRepository *MockLedgerRepository,
+				outboxRepository *MockOutboxRepository,
+			) {
+				repository.EXPECT().GetOrderForUpdate(ctx, dto.OrderID).Return(reservedOrder, nil)
+				repository.EXPECT().UpdateStatus(ctx, statusDTO).Return(repositoryErr)
+			},
+			wantedErr: repositoryErr,
+		},
+		{
+			name: "set captured amount error",
+			mock: func(
+				repository *MockRepository,
+				transactionRepository *MockTransactionRepository,
+				accountRepository *MockAccountRepository,
+				ledgerRepository *MockLedgerRepository,
+				outboxRepository *MockOutboxRepository,
+			) {
+				repository.EXPECT().GetOrderForUpdate(ctx, dto.OrderID).Return(reservedOrder, nil)
+				repository.EXPECT().UpdateStatus(ctx, statusDTO).Return(nil)
+				repository.EXPECT().SetCapturedAmount(ctx, capturedDTO).Return(repositoryErr)
+			},
+			wantedErr: repositoryErr,
+		},
+		{
+			name: "transaction repository error",
+			mock: func(
+				repository *MockRepository,
+				transactionRepository *MockTransactionRepository,
+				accountRepository *MockAccountRepository,
+				ledgerRepository *MockLedgerRepository,
+				outboxRepository *MockOutboxRepository,
+			) {
+				repository.EXPECT().GetOrderForUpdate(ctx, dto.OrderID).Return(reservedOrder, nil)
+				repository.EXPECT().UpdateStatus(ctx, statusDTO).Return(nil)
+				repository.EXPECT().SetCapturedAmount(ctx, capturedDTO).Return(nil)
+				accountRepository.EXPECT().CaptureBalance(ctx, captureBalanceDTO).Return(int64(0), nil)
+				transactionRepository.EXPECT().CreateTransaction(ctx, transactionDTO).Return(transactionRepositoryErr)
+			},
+			wantedErr: transactionRepositoryErr,
+		},
+		{
+			name: "ledger repository error",
+			mock: func(
+				repository *MockRepository,
+				transactionRepository *MockTransactionRepository,
+				accountRepository *MockAccountRepository,
+				ledgerRepository *MockLedgerRepository,
+				outboxRepository *MockOutboxRepository,
+			) {
+				repository.EXPECT().GetOrderForUpdate(ctx, dto.OrderID).Return(reservedOrder, nil)
+				repository.EXPECT().UpdateStatus(ctx, statusDTO).Return(nil)
+				repository.EXPECT().SetCapturedAmount(ctx, capturedDTO).Return(nil)
+				accountRepository.EXPECT().CaptureBalance(ctx, captureBalanceDTO).Return(int64(0), nil)
+				transactionRepository.EXPECT().CreateTransaction(ctx, transactionDTO).Return(nil)
+				ledgerRepository.EXPECT().CreatePosting(ctx, postingDTO).Return(ledgerRepositoryErr)
+			},
+			wantedErr: ledgerRepositoryErr,
+		},
+		{
+			name: "outbox repository error",
+			mock: func(
+				repository *MockRepository,
+				transactionRepository *MockTransactionRepository,
+				accountRepository *MockAccountRepository,
+				ledgerRepository *MockLedgerRepository,
+				outboxRepository *MockOutboxRepository,
+			) {
+				repository.EXPECT().GetOrderForUpdate(ctx, dto.OrderID).Return(reservedOrder, nil)
+				repository.EXPECT().UpdateStatus(ctx, statusDTO).Return(nil)
+				repository.EXPECT().SetCapturedAmount(ctx, capturedDTO).Return(nil)
+				accountRepository.EXPECT().CaptureBalance(ctx, captureBalanceDTO).Return(int64(0), nil)
+				transactionRepository.EXPECT().CreateTransaction(ctx, transactionDTO).Return(nil)
+				ledgerRepository.EXPECT().CreatePosting(ctx, postingDTO).Return(nil)
+				outboxRepository.EXPECT().CreateEvent(ctx, eventDTO).Return(outboxRepositoryErr)
 			},
-			args: args{
-				dto: dto,
+			wantedErr: outboxRepositoryErr,
+		},
+		{
+			name: "transaction error",
+			mock: func(
+				repository *MockRepository,
+				transactionRepository *MockTransactionRepository,
+				accountRepository *MockAccountRepository,
+				ledgerRepository *MockLedgerRepository,
+				outboxRepository *MockOutboxRepository,
+			) {
+				repository.EXPECT().GetOrderForUpdate(ctx, dto.OrderID).Return(reservedOrder, nil)
+				repository.EXPECT().UpdateStatus(ctx, statusDTO).Return(nil)
+				repository.EXPECT().SetCapturedAmount(ctx, capturedDTO).Return(nil)
+				accountRepository.EXPECT().CaptureBalance(ctx, captureBalanceDTO).Return(int64(0), nil)
+				transactionRepository.EXPECT().CreateTransaction(ctx, transactionDTO).Return(nil)
+				ledgerRepository.EXPECT().CreatePosting(ctx, postingDTO).Return(nil)
+				outboxRepository.EXPECT().CreateEvent(ctx, eventDTO).Return(nil)
 			},
-			wantErr: true,
+			txErr: txErr,
 		},
 	}
 
@@ -290,12 +640,20 @@ func TestService_PayForOrder(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			t.Parallel()
 
-			service, repository, _, _ := mockService(t, nil)
+			service, repository, transactionRepository, accountRepository, ledgerRepository, outboxRepository := mockService(
+				t,
+				tt.txErr,
+			)
 
-			tt.mock(repository)
+			tt.mock(repository, transactionRepository, accountRepository, ledgerRepository, outboxRepository)
 
-			err := service.PayForOrder(ctx, tt.args.dto)
-			require.True(t, (err != nil) == tt.wantErr)
+			err := service.PayForOrder(ctx, dto)
+			if tt.wantedErr != nil {
+				require.ErrorIs(t, err, tt.wantedErr)
+			}
+			if tt.txErr != nil {
+				require.ErrorIs(t, err, tt.txErr)
+			}
 		})
 	}
 }
@@ -311,35 +669,73 @@ func TestService_CancelOrder(t *testing.T) {
 		AccountID: 1,
 		ServiceID: 1,
 		Amount:    100,
+		Currency:  currency.RUB,
+		Actor:     "api",
+		Reason:    "service is unavailable",
 	}
+	reservedOrder := order.Order{
+		OrderID:   dto.OrderID,
+		AccountID: dto.AccountID,
+		ServiceID: dto.ServiceID,
+		Amount:    dto.Amount,
+		Currency:  currency.RUB,
+		Status:    order.Reserved,
+	}
+	paidOrder := reservedOrder
+	paidOrder.Status = order.Paid
+	usdOrder := reservedOrder
+	usdOrder.Currency = currency.USD
 	accountDTO := account.ReturnBalanceDTO{
 		AccountID: dto.AccountID,
 		Amount:    dto.Amount,
+		Currency:  dto.Currency,
+	}
+	statusDTO := order.UpdateStatusDTO{
+		OrderID: dto.OrderID,
+		From:    order.Reserved,
+		To:      order.Cancelled,
+		Actor:   dto.Actor,
+		Reason:  dto.Reason,
 	}
 	transactionDTO := transaction.CreateDTO{
-		Type:        transaction.CancelReservation,
-		SenderID:    dto.AccountID,
-		ReceiverID:  dto.AccountID,
-		Amount:      dto.Amount,
-		Description: fmt.Sprintf("Cancel reservation %d kopecks for order with id = %d", dto.Amount, dto.OrderID),
+		Type:       transaction.CancelReservation,
+		SenderID:   dto.AccountID,
+		ReceiverID: dto.AccountID,
+		Amount:     dto.Amount,
+		Currency:   dto.Currency,
+		Description: fmt.Sprintf(
+			"Cancel reservation %s for order with id = %d",
+			dto.Currency.Format(dto.Amount),
+			dto.OrderID,
+		),
 	}
+	postingDTO := ledger.NewCancelReservationDTO(dto.AccountID, dto.Amount, dto.Currency, transactionDTO.Description)
+	eventDTO := outbox.NewOrderCancelledDTO(outbox.OrderCancelledPayload{
+		OrderID:   dto.OrderID,
+		AccountID: dto.AccountID,
+		ServiceID: dto.ServiceID,
+		Amount:    dto.Amount,
+		Currency:  dto.Currency,
+		Reason:    dto.Reason,
+	})
 	txErr := errors.New("transaction error")
 	repositoryErr := errors.New("repository error")
 	accountRepositoryErr := errors.New("account repository error")
 	transactionRepositoryErr := errors.New("transaction repository error")
+	ledgerRepositoryErr := errors.New("ledger repository error")
+	outboxRepositoryErr := errors.New("outbox repository error")
 
-	type args struct {
-		dto            order.CancelDTO
-		accountDTO     account.ReturnBalanceDTO
-		transactionDTO transaction.CreateDTO
-	}
-
-	type mockBehavior func(r *MockRepository, tr *MockTransactionRepository, ar *MockAccountRepository)
+	type mockBehavior func(
+		r *MockRepository,
+		tr *MockTransactionRepository,
+		ar *MockAccountRepository,
+		lr *MockLedgerRepository,
+		or *MockOutboxRepository,
+	)
 
 	tests := []struct {
 		name      string
 		mock      mockBehavior
-		args      args
 		want      int64
 		wantedErr error
 		txErr     error
@@ -350,37 +746,70 @@ func TestService_CancelOrder(t *testing.T) {
 				repository *MockRepository,
 				transactionRepository *MockTransactionRepository,
 				accountRepository *MockAccountRepository,
+				ledgerRepository *MockLedgerRepository,
+				outboxRepository *MockOutboxRepository,
 			) {
-				repository.EXPECT().CancelOrder(ctx, dto).Return(nil)
+				repository.EXPECT().GetOrderForUpdate(ctx, dto.OrderID).Return(reservedOrder, nil)
+				repository.EXPECT().UpdateStatus(ctx, statusDTO).Return(nil)
 				accountRepository.EXPECT().ReturnBalance(ctx, accountDTO).Return(fakeBalance+accountDTO.Amount, nil)
 				transactionRepository.EXPECT().CreateTransaction(ctx, transactionDTO).Return(nil)
+				ledgerRepository.EXPECT().CreatePosting(ctx, postingDTO).Return(nil)
+				outboxRepository.EXPECT().CreateEvent(ctx, eventDTO).Return(nil)
 			},
-			args: args{
-				dto:            dto,
-				accountDTO:     accountDTO,
-				transactionDTO: transactionDTO,
+			want: fakeBalance + accountDTO.Amount,
+		},
+		{
+			name: "order not found",
+			mock: func(
+				repository *MockRepository,
+				transactionRepository *MockTransactionRepository,
+				accountRepository *MockAccountRepository,
+				ledgerRepository *MockLedgerRepository,
+				outboxRepository *MockOutboxRepository,
+			) {
+				repository.EXPECT().GetOrderForUpdate(ctx, dto.OrderID).Return(order.Order{}, order.ErrNotFound)
 			},
-			want:      fakeBalance + accountDTO.Amount,
-			wantedErr: nil,
-			txErr:     nil,
+			wantedErr: order.ErrNotFound,
 		},
 		{
-			name: "repository error",
+			name: "order currency does not match request",
 			mock: func(
 				repository *MockRepository,
 				transactionRepository *MockTransactionRepository,
 				accountRepository *MockAccountRepository,
+				ledgerRepository *MockLedgerRepository,
+				outboxRepository *MockOutboxRepository,
 			) {
-				repository.EXPECT().CancelOrder(ctx, dto).Return(repositoryErr)
+				repository.EXPECT().GetOrderForUpdate(ctx, dto.OrderID).Return(usdOrder, nil)
 			},
-			args: args{
-				dto:            dto,
-				accountDTO:     accountDTO,
-				transactionDTO: transactionDTO,
+			wantedErr: currency.ErrMismatch,
+		},
+		{
+			name: "order is already paid",
+			mock: func(
+				repository *MockRepository,
+				transactionRepository *MockTransactionRepository,
+				accountRepository *MockAccountRepository,
+				ledgerRepository *MockLedgerRepository,
+				outboxRepository *MockOutboxRepository,
+			) {
+				repository.EXPECT().GetOrderForUpdate(ctx, dto.OrderID).Return(paidOrder, nil)
+			},
+			wantedErr: order.ErrIllegalTransition,
+		},
+		{
+			name: "update status error",
+			mock: func(
+				repository *MockRepository,
+				transactionRepository *MockTransactionRepository,
+				accountRepository *MockAccountRepository,
+				ledgerRepository *MockLedgerRepository,
+				outboxRepository *MockOutboxRepository,
+			) {
+				repository.EXPECT().GetOrderForUpdate(ctx, dto.OrderID).Return(reservedOrder, nil)
+				repository.EXPECT().UpdateStatus(ctx, statusDTO).Return(repositoryErr)
 			},
-			want:      0,
 			wantedErr: repositoryErr,
-			txErr:     nil,
 		},
 		{
 			name: "account repository error",
@@ -388,18 +817,14 @@ func TestService_CancelOrder(t *testing.T) {
 				repository *MockRepository,
 				transactionRepository *MockTransactionRepository,
 				accountRepository *MockAccountRepository,
+				ledgerRepository *MockLedgerRepository,
+				outboxRepository *MockOutboxRepository,
 			) {
-				repository.EXPECT().CancelOrder(ctx, dto).Return(nil)
+				repository.EXPECT().GetOrderForUpdate(ctx, dto.OrderID).Return(reservedOrder, nil)
+				repository.EXPECT().UpdateStatus(ctx, statusDTO).Return(nil)
 				accountRepository.EXPECT().ReturnBalance(ctx, accountDTO).Return(int64(0), accountRepositoryErr)
 			},
-			args: args{
-				dto:            dto,
-				accountDTO:     accountDTO,
-				transactionDTO: transactionDTO,
-			},
-			want:      0,
 			wantedErr: accountRepositoryErr,
-			txErr:     nil,
 		},
 		{
 			name: "transaction repository error",
@@ -407,19 +832,50 @@ func TestService_CancelOrder(t *testing.T) {
 				repository *MockRepository,
 				transactionRepository *MockTransactionRepository,
 				accountRepository *MockAccountRepository,
+				ledgerRepository *MockLedgerRepository,
+				outboxRepository *MockOutboxRepository,
 			) {
-				repository.EXPECT().CancelOrder(ctx, dto).Return(nil)
+				repository.EXPECT().GetOrderForUpdate(ctx, dto.OrderID).Return(reservedOrder, nil)
+				repository.EXPECT().UpdateStatus(ctx, statusDTO).Return(nil)
 				accountRepository.EXPECT().ReturnBalance(ctx, accountDTO).Return(fakeBalance+accountDTO.Amount, nil)
 				transactionRepository.EXPECT().CreateTransaction(ctx, transactionDTO).Return(transactionRepositoryErr)
 			},
-			args: args{
-				dto:            dto,
-				accountDTO:     accountDTO,
-				transactionDTO: transactionDTO,
-			},
-			want:      0,
 			wantedErr: transactionRepositoryErr,
-			txErr:     nil,
+		},
+		{
+			name: "ledger repository error",
+			mock: func(
+				repository *MockRepository,
+				transactionRepository *MockTransactionRepository,
+				accountRepository *MockAccountRepository,
+				ledgerRepository *MockLedgerRepository,
+				outboxRepository *MockOutboxRepository,
+			) {
+				repository.EXPECT().GetOrderForUpdate(ctx, dto.OrderID).Return(reservedOrder, nil)
+				repository.EXPECT().UpdateStatus(ctx, statusDTO).Return(nil)
+				accountRepository.EXPECT().ReturnBalance(ctx, accountDTO).Return(fakeBalance+accountDTO.Amount, nil)
+				transactionRepository.EXPECT().CreateTransaction(ctx, transactionDTO).Return(nil)
+				ledgerRepository.EXPECT().CreatePosting(ctx, postingDTO).Return(ledgerRepositoryErr)
+			},
+			wantedErr: ledgerRepositoryErr,
+		},
+		{
+			name: "outbox repository error",
+			mock: func(
+				repository *MockRepository,
+				transactionRepository *MockTransactionRepository,
+				accountRepository *MockAccountRepository,
+				ledgerRepository *MockLedgerRepository,
+				outboxRepository *MockOutboxRepository,
+			) {
+				repository.EXPECT().GetOrderForUpdate(ctx, dto.OrderID).Return(reservedOrder, nil)
+				repository.EXPECT().UpdateStatus(ctx, statusDTO).Return(nil)
+				accountRepository.EXPECT().ReturnBalance(ctx, accountDTO).Return(fakeBalance+accountDTO.Amount, nil)
+				transactionRepository.EXPECT().CreateTransaction(ctx, transactionDTO).Return(nil)
+				ledgerRepository.EXPECT().CreatePosting(ctx, postingDTO).Return(nil)
+				outboxRepository.EXPECT().CreateEvent(ctx, eventDTO).Return(outboxRepositoryErr)
+			},
+			wantedErr: outboxRepositoryErr,
 		},
 		{
 			name: "transaction error",
@@ -427,19 +883,1141 @@ func TestService_CancelOrder(t *testing.T) {
 				repository *MockRepository,
 				transactionRepository *MockTransactionRepository,
 				accountRepository *MockAccountRepository,
+				ledgerRepository *MockLedgerRepository,
+				outboxRepository *MockOutboxRepository,
 			) {
-				repository.EXPECT().CancelOrder(ctx, dto).Return(nil)
+				repository.EXPECT().GetOrderForUpdate(ctx, dto.OrderID).Return(reservedOrder, nil)
+				repository.EXPECT().UpdateStatus(ctx, statusDTO).Return(nil)
 				accountRepository.EXPECT().ReturnBalance(ctx, accountDTO).Return(fakeBalance+accountDTO.Amount, nil)
 				transactionRepository.EXPECT().CreateTransaction(ctx, transactionDTO).Return(nil)
+				ledgerRepository.EXPECT().CreatePosting(ctx, postingDTO).Return(nil)
+				outboxRepository.EXPECT().CreateEvent(ctx, eventDTO).Return(nil)
 			},
-			args: args{
-				dto:            dto,
-				accountDTO:     accountDTO,
-				transactionDTO: transactionDTO,
+			txErr: txErr,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			service, repository, transactionRepository, accountRepository, ledgerRepository, outboxRepository := mockService(
+				t,
+				tt.txErr,
+			)
+
+			tt.mock(repository, transactionRepository, accountRepository, ledgerRepository, outboxRepository)
+			got, err := service.CancelOrder(ctx, dto)
+			if tt.wantedErr != nil {
+				require.ErrorIs(t, err, tt.wantedErr)
+			}
+			if tt.txErr != nil {
+				require.ErrorIs(t, err, tt.txErr)
+			}
+			require.True(t, reflect.DeepEqual(tt.want, got))
+		})
+	}
+}
+
+func TestService_RefundOrder(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	fakeBalance := int64(1000)
+	paidOrder := order.Order{
+		OrderID:        1,
+		AccountID:      1,
+		ServiceID:      1,
+		Amount:         100,
+		Currency:       currency.RUB,
+		CapturedAmount: 100,
+		RefundedAmount: 20,
+		Status:         order.Paid,
+	}
+	partialDTO := order.RefundDTO{
+		OrderID:   paidOrder.OrderID,
+		AccountID: paidOrder.AccountID,
+		ServiceID: paidOrder.ServiceID,
+		Amount:    30,
+		Currency:  paidOrder.Currency,
+		Actor:     "api",
+		Reason:    "service is partially delivered",
+	}
+	fullDTO := partialDTO
+	fullDTO.Amount = 0
+	reservedOrder := paidOrder
+	reservedOrder.RefundedAmount = 0
+	reservedOrder.Status = order.Reserved
+	anotherOrder := paidOrder
+	anotherOrder.ServiceID = 2
+	usdOrder := paidOrder
+	usdOrder.Currency = currency.USD
+	txErr := errors.New("transaction error")
+	repositoryErr := errors.New("repository error")
+	accountRepositoryErr := errors.New("account repository error")
+	transactionRepositoryErr := errors.New("transaction repository error")
+	ledgerRepositoryErr := errors.New("ledger repository error")
+	outboxRepositoryErr := errors.New("outbox repository error")
+
+	refundTransactionDTO := func(amount int64) transaction.CreateDTO {
+		return transaction.CreateDTO{
+			Type:       transaction.Refund,
+			SenderID:   paidOrder.AccountID,
+			ReceiverID: paidOrder.AccountID,
+			Amount:     amount,
+			Currency:   paidOrder.Currency,
+			Description: fmt.Sprintf(
+				"Refund %s for order with id = %d",
+				paidOrder.Currency.Format(amount),
+				paidOrder.OrderID,
+			),
+		}
+	}
+	refundPostingDTO := func(amount int64) ledger.CreatePostingDTO {
+		return ledger.NewRefundDTO(
+			paidOrder.AccountID,
+			amount,
+			paidOrder.Currency,
+			refundTransactionDTO(amount).Description,
+		)
+	}
+	refundEventDTO := func(amount, refundedAmount int64) outbox.CreateDTO {
+		return outbox.NewOrderRefundedDTO(outbox.OrderRefundedPayload{
+			OrderID:        paidOrder.OrderID,
+			AccountID:      paidOrder.AccountID,
+			ServiceID:      paidOrder.ServiceID,
+			Amount:         amount,
+			RefundedAmount: refundedAmount,
+			Currency:       paidOrder.Currency,
+		})
+	}
+	refundedStatusDTO := order.UpdateStatusDTO{
+		OrderID: paidOrder.OrderID,
+		From:    order.Paid,
+		To:      order.Refunded,
+		Actor:   fullDTO.Actor,
+		Reason:  fullDTO.Reason,
+	}
+
+	type mockBehavior func(
+		r *MockRepository,
+		tr *MockTransactionRepository,
+		ar *MockAccountRepository,
+		lr *MockLedgerRepository,
+		or *MockOutboxRepository,
+	)
+
+	type want struct {
+		order   order.Order
+		balance int64
+	}
+
+	partialOrder := paidOrder
+	partialOrder.RefundedAmount = 50
+	fullOrder := paidOrder
+	fullOrder.RefundedAmount = 100
+	fullOrder.Status = order.Refunded
+
+	tests := []struct {
+		name      string
+		dto       order.RefundDTO
+		mock      mockBehavior
+		want      want
+		wantedErr error
+		txErr     error
+	}{
+		{
+			name: "success partial refund",
+			dto:  partialDTO,
+			mock: func(
+				repository *MockRepository,
+				transactionRepository *MockTransactionRepository,
+				accountRepository *MockAccountRepository,
+				ledgerRepository *MockLedgerRepository,
+				outboxRepository *MockOutboxRepository,
+			) {
+				repository.EXPECT().GetOrderForUpdate(ctx, paidOrder.OrderID).Return(paidOrder, nil)
+				repository.EXPECT().
+					AddRefund(ctx, order.AddRefundDTO{OrderID: paidOrder.OrderID, Amount: 30}).
+					Return(int64(50), nil)
+				accountRepository.EXPECT().
+					RefundBalance(ctx, account.RefundBalanceDTO{
+						AccountID: paidOrder.AccountID,
+						Amount:    30,
+						Currency:  paidOrder.Currency,
+					}).
+					Return(fakeBalance+30, nil)
+				transactionRepository.EXPECT().CreateTransaction(ctx, refundTransactionDTO(30)).Return(nil)
+				ledgerRepository.EXPECT().CreatePosting(ctx, refundPostingDTO(30)).Return(nil)
+				outboxRepository.EXPECT().CreateEvent(ctx, refundEventDTO(30, 50)).Return(nil)
+			},
+			want: want{
+				order:   partialOrder,
+				balance: fakeBalance + 30,
+			},
+		},
+		{
+			name: "success full refund",
+			dto:  fullDTO,
+			mock: func(
+				repository *MockRepository,
+				transactionRepository *MockTransactionRepository,
+				accountRepository *MockAccountRepository,
+				ledgerRepository *MockLedgerRepository,
+				outboxRepository *MockOutboxRepository,
+			) {
+				repository.EXPECT().GetOrderForUpdate(ctx, paidOrder.OrderID).Return(paidOrder, nil)
+				repository.EXPECT().
+					AddRefund(ctx, order.AddRefundDTO{OrderID: paidOrder.OrderID, Amount: 80}).
+					Return(int64(100), nil)
+				repository.EXPECT().UpdateStatus(ctx, refundedStatusDTO).Return(nil)
+				accountRepository.EXPECT().
+					RefundBalance(ctx, account.RefundBalanceDTO{
+						AccountID: paidOrder.AccountID,
+						Amount:    80,
+						Currency:  paidOrder.Currency,
+					}).
+					Return(fakeBalance+80, nil)
+				transactionRepository.EXPECT().CreateTransaction(ctx, refundTransactionDTO(80)).Return(nil)
+				ledgerRepository.EXPECT().CreatePosting(ctx, refundPostingDTO(80)).Return(nil)
+				outboxRepository.EXPECT().CreateEvent(ctx, refundEventDTO(80, 100)).Return(nil)
+			},
+			want: want{
+				order:   fullOrder,
+				balance: fakeBalance + 80,
+			},
+		},
+		{
+			name: "order not found",
+			dto:  partialDTO,
+			mock: func(
+				repository *MockRepository,
+				transactionRepository *MockTransactionRepository,
+				accountRepository *MockAccountRepository,
+				ledgerRepository *MockLedgerRepository,
+				outboxRepository *MockOutboxRepository,
+			) {
+				repository.EXPECT().GetOrderForUpdate(ctx, paidOrder.OrderID).Return(order.Order{}, order.ErrNotFound)
+			},
+			wantedErr: order.ErrNotFound,
+		},
+		{
+			name: "order does not match request",
+			dto:  partialDTO,
+			mock: func(
+				repository *MockRepository,
+				transactionRepository *MockTransactionRepository,
+				accountRepository *MockAccountRepository,
+				ledgerRepository *MockLedgerRepository,
+				outboxRepository *MockOutboxRepository,
+			) {
+				repository.EXPECT().GetOrderForUpdate(ctx, paidOrder.OrderID).Return(anotherOrder, nil)
+			},
+			wantedErr: order.ErrNotFound,
+		},
+		{
+			name: "order currency does not match request",
+			dto:  partialDTO,
+			mock: func(
+				repository *MockRepository,
+				transactionRepository *MockTransactionRepository,
+				accountRepository *MockAccountRepository,
+				ledgerRepository *MockLedgerRepository,
+				outboxRepository *MockOutboxRepository,
+			) {
+				repository.EXPECT().GetOrderForUpdate(ctx, paidOrder.OrderID).Return(usdOrder, nil)
+			},
+			wantedErr: currency.ErrMismatch,
+		},
+		{
+			name: "order is not paid",
+			dto:  partialDTO,
+			mock: func(
+				repository *MockRepository,
+				transactionRepository *MockTransactionRepository,
+				accountRepository *MockAccountRepository,
+				ledgerRepository *MockLedgerRepository,
+				outboxRepository *MockOutboxRepository,
+			) {
+				repository.EXPECT().GetOrderForUpdate(ctx, paidOrder.OrderID).Return(reservedOrder, nil)
+			},
+			wantedErr: order.ErrIllegalTransition,
+		},
+		{
+			name: "refund exceeds paid amount",
+			dto: order.RefundDTO{
+				OrderID:   paidOrder.OrderID,
+				AccountID: paidOrder.AccountID,
+				ServiceID: paidOrder.ServiceID,
+				Amount:    81,
+				Currency:  currency.RUB,
+			},
+			mock: func(
+				repository *MockRepository,
+				transactionRepository *MockTransactionRepository,
+				accountRepository *MockAccountRepository,
+				ledgerRepository *MockLedgerRepository,
+				outboxRepository *MockOutboxRepository,
+			) {
+				repository.EXPECT().GetOrderForUpdate(ctx, paidOrder.OrderID).Return(paidOrder, nil)
+			},
+			wantedErr: order.ErrRefundExceedsPaid,
+		},
+		{
+			name: "add refund error",
+			dto:  partialDTO,
+			mock: func(
+				repository *MockRepository,
+				transactionRepository *MockTransactionRepository,
+				accountRepository *MockAccountRepository,
+				ledgerRepository *MockLedgerRepository,
+				outboxRepository *MockOutboxRepository,
+			) {
+				repository.EXPECT().GetOrderForUpdate(ctx, paidOrder.OrderID).Return(paidOrder, nil)
+				repository.EXPECT().
+					AddRefund(ctx, order.AddRefundDTO{OrderID: paidOrder.OrderID, Amount: 30}).
+					Return(int64(0), repositoryErr)
+			},
+			wantedErr: repositoryErr,
+		},
+		{
+			name: "account repository error",
+			dto:  partialDTO,
+			mock: func(
+				repository *MockRepository,
+				transactionRepository *MockTransactionRepository,
+				accountRepository *MockAccountRepository,
+				ledgerRepository *MockLedgerRepository,
+				outboxRepository *MockOutboxRepository,
+			) {
+				repository.EXPECT().GetOrderForUpdate(ctx, paidOrder.OrderID).Return(paidOrder, nil)
+				repository.EXPECT().
+					AddRefund(ctx, order.AddRefundDTO{OrderID: paidOrder.OrderID, Amount: 30}).
+					Return(int64(50), nil)
+				accountRepository.EXPECT().
+					RefundBalance(ctx, account.RefundBalanceDTO{
+						AccountID: paidOrder.AccountID,
+						Amount:    30,
+						Currency:  paidOrder.Currency,
+					}).
+					Return(int64(0), accountRepositoryErr)
+			},
+			wantedErr: accountRepositoryErr,
+		},
+		{
+			name: "transaction repository error",
+			dto:  partialDTO,
+			mock: func(
+				repository *MockRepository,
+				transactionRepository *MockTransactionRepository,
+				accountRepository *MockAccountRepository,
+				ledgerRepository *MockLedgerRepository,
+				outboxRepository *MockOutboxRepository,
+			) {
+				repository.EXPECT().GetOrderForUpdate(ctx, paidOrder.OrderID).Return(paidOrder, nil)
+				repository.EXPECT().
+					AddRefund(ctx, order.AddRefundDTO{OrderID: paidOrder.OrderID, Amount: 30}).
+					Return(int64(50), nil)
+				accountRepository.EXPECT().
+					RefundBalance(ctx, account.RefundBalanceDTO{
+						AccountID: paidOrder.AccountID,
+						Amount:    30,
+						Currency:  paidOrder.Currency,
+					}).
+					Return(fakeBalance+30, nil)
+				transactionRepository.EXPECT().
+					CreateTransaction(ctx, refundTransactionDTO(30)).
+					Return(transactionRepositoryErr)
+			},
+			wantedErr: transactionRepositoryErr,
+		},
+		{
+			name: "ledger repository error",
+			dto:  partialDTO,
+			mock: func(
+				repository *MockRepository,
+				transactionRepository *MockTransactionRepository,
+				accountRepository *MockAccountRepository,
+				ledgerRepository *MockLedgerRepository,
+				outboxRepository *MockOutboxRepository,
+			) {
+				repository.EXPECT().GetOrderForUpdate(ctx, paidOrder.OrderID).Return(paidOrder, nil)
+				repository.EXPECT().
+					AddRefund(ctx, order.AddRefundDTO{OrderID: paidOrder.OrderID, Amount: 30}).
+					Return(int64(50), nil)
+				accountRepository.EXPECT().
+					RefundBalance(ctx, account.RefundBalanceDTO{
+						AccountID: paidOrder.AccountID,
+						Amount:    30,
+						Currency:  paidOrder.Currency,
+					}).
+					Return(fakeBalance+30, nil)
+				transactionRepository.EXPECT().CreateTransaction(ctx, refundTransactionDTO(30)).Return(nil)
+				ledgerRepository.EXPECT().CreatePosting(ctx, refundPostingDTO(30)).Return(ledgerRepositoryErr)
+			},
+			wantedErr: ledgerRepositoryErr,
+		},
+		{
+			name: "outbox repository error",
+			dto:  partialDTO,
+			mock: func(
+				repository *MockRepository,
+				transactionRepository *MockTransactionRepository,
+				accountRepository *MockAccountRepository,
+				ledgerRepository *MockLedgerRepository,
+				outboxRepository *MockOutboxRepository,
+			) {
+				repository.EXPECT().GetOrderForUpdate(ctx, paidOrder.OrderID).Return(paidOrder, nil)
+				repository.EXPECT().
+					AddRefund(ctx, order.AddRefundDTO{OrderID: paidOrder.OrderID, Amount: 30}).
+					Return(int64(50), nil)
+				accountRepository.EXPECT().
+					RefundBalance(ctx, account.RefundBalanceDTO{
+						AccountID: paidOrder.AccountID,
+						Amount:    30,
+						Currency:  paidOrder.Currency,
+					}).
+					Return(fakeBalance+30, nil)
+				transactionRepository.EXPECT().CreateTransaction(ctx, refundTransactionDTO(30)).Return(nil)
+				ledgerRepository.EXPECT().CreatePosting(ctx, refundPostingDTO(30)).Return(nil)
+				outboxRepository.EXPECT().CreateEvent(ctx, refundEventDTO(30, 50)).Return(outboxRepositoryErr)
+			},
+			wantedErr: outboxRepositoryErr,
+		},
+		{
+			name: "transaction error",
+			dto:  partialDTO,
+			mock: func(
+				repository *MockRepository,
+				transactionRepository *MockTransactionRepository,
+				accountRepository *MockAccountRepository,
+				ledgerRepository *MockLedgerRepository,
+				outboxRepository *MockOutboxRepository,
+			) {
+				repository.EXPECT().GetOrderForUpdate(ctx, paidOrder.OrderID).Return(paidOrder, nil)
+				repository.EXPECT().
+					AddRefund(ctx, order.AddRefundDTO{OrderID: paidOrder.OrderID, Amount: 30}).
+					Return(int64(50), nil)
+				accountRepository.EXPECT().
+					RefundBalance(ctx, account.RefundBalanceDTO{
+						AccountID: paidOrder.AccountID,
+						Amount:    30,
+						Currency:  paidOrder.Currency,
+					}).
+					Return(fakeBalance+30, nil)
+				transactionRepository.EXPECT().CreateTransaction(ctx, refundTransactionDTO(30)).Return(nil)
+				ledgerRepository.EXPECT().CreatePosting(ctx, refundPostingDTO(30)).Return(nil)
+				outboxRepository.EXPECT().CreateEvent(ctx, refundEventDTO(30, 50)).Return(nil)
+			},
+			txErr: txErr,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			service, repository, transactionRepository, accountRepository, ledgerRepository, outboxRepository := mockService(
+				t,
+				tt.txErr,
+			)
+
+			tt.mock(repository, transactionRepository, accountRepository, ledgerRepository, outboxRepository)
+			gotOrder, gotBalance, err := service.RefundOrder(ctx, tt.dto)
+			if tt.wantedErr != nil {
+				require.ErrorIs(t, err, tt.wantedErr)
+			}
+			if tt.txErr != nil {
+				require.ErrorIs(t, err, tt.txErr)
+			}
+			require.True(t, reflect.DeepEqual(tt.want.order, gotOrder))
+			require.True(t, reflect.DeepEqual(tt.want.balance, gotBalance))
+		})
+	}
+}
+
+func TestService_ExpireOrders(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	limit := uint64(10)
+	expiredOrders := []order.Order{
+		{
+			OrderID:   1,
+			AccountID: 1,
+			ServiceID: 1,
+			Amount:    100,
+			Currency:  currency.RUB,
+			Status:    order.Reserved,
+		},
+		{
+			OrderID:   2,
+			AccountID: 2,
+			ServiceID: 1,
+			Amount:    5000,
+			Currency:  currency.JPY,
+			Status:    order.Reserved,
+		},
+	}
+	statusDTO := func(o order.Order) order.UpdateStatusDTO {
+		return order.UpdateStatusDTO{
+			OrderID: o.OrderID,
+			From:    order.Reserved,
+			To:      order.Expired,
+			Actor:   order.ExpiryActor,
+			Reason:  order.ExpiryReason,
+		}
+	}
+	accountDTO := func(o order.Order) account.ReturnBalanceDTO {
+		return account.ReturnBalanceDTO{
+			AccountID: o.AccountID,
+			Amount:    o.Amount,
+			Currency:  o.Currency,
+		}
+	}
+	transactionDTO := func(o order.Order) transaction.CreateDTO {
+		return transaction.CreateDTO{
+			Type:       transaction.CancelReservation,
+			SenderID:   o.AccountID,
+			ReceiverID: o.AccountID,
+			Amount:     o.Amount,
+			Currency:   o.Currency,
+			Description: fmt.Sprintf(
+				"Cancel reservation %s for order with id = %d, reason: expired",
+				o.Currency.Format(o.Amount),
+				o.OrderID,
+			),
+		}
+	}
+	postingDTO := func(o order.Order) ledger.CreatePostingDTO {
+		return ledger.NewCancelReservationDTO(o.AccountID, o.Amount, o.Currency, transactionDTO(o).Description)
+	}
+	eventDTO := func(o order.Order) outbox.CreateDTO {
+		return outbox.NewOrderCancelledDTO(outbox.OrderCancelledPayload{
+			OrderID:   o.OrderID,
+			AccountID: o.AccountID,
+			ServiceID: o.ServiceID,
+			Amount:    o.Amount,
+			Currency:  o.Currency,
+			Reason:    order.ExpiryReason,
+		})
+	}
+	expectExpiry := func(
+		repository *MockRepository,
+		transactionRepository *MockTransactionRepository,
+		accountRepository *MockAccountRepository,
+		ledgerRepository *MockLedgerRepository,
+		outboxRepository *MockOutboxRepository,
+		o order.Order,
+	) {
+		repository.EXPECT().UpdateStatus(ctx, statusDTO(o)).Return(nil)
+		accountRepository.EXPECT().ReturnBalance(ctx, accountDTO(o)).Return(o.Amount, nil)
+		transactionRepository.EXPECT().CreateTransaction(ctx, transactionDTO(o)).Return(nil)
+		ledgerRepository.EXPECT().CreatePosting(ctx, postingDTO(o)).Return(nil)
+		outboxRepository.EXPECT().CreateEvent(ctx, eventDTO(o)).Return(nil)
+	}
+	txErr := errors.New("transaction error")
+	repositoryErr := errors.New("repository error")
+	accountRepositoryErr := errors.New("account repository error")
+	transactionRepositoryErr := errors.New("transaction repository error")
+	ledgerRepositoryErr := errors.New("ledger repository error")
+	outboxRepositoryErr := errors.New("outbox repository error")
+
+	type mockBehavior func(
+		r *MockRepository,
+		tr *MockTransactionRepository,
+		ar *MockAccountRepository,
+		lr *MockLedgerRepository,
+		or *MockOutboxRepository,
+	)
+
+	tests := []struct {
+		name      string
+		mock      mockBehavior
+		want      int
+		wantedErr error
+		txErr     error
+	}{
+		{
+			name: "success expire orders",
+			mock: func(
+				repository *MockRepository,
+				transactionRepository *MockTransactionRepository,
+				accountRepository *MockAccountRepository,
+				ledgerRepository *MockLedgerRepository,
+				outboxRepository *MockOutboxRepository,
+			) {
+				repository.EXPECT().GetExpiredOrdersForUpdate(ctx, limit).Return(expiredOrders, nil)
+				for _, o := range expiredOrders {
+					expectExpiry(repository, transactionRepository, accountRepository, ledgerRepository, outboxRepository, o)
+				}
+			},
+			want: len(expiredOrders),
+		},
+		{
+			name: "no expired orders",
+			mock: func(
+				repository *MockRepository,
+				transactionRepository *MockTransactionRepository,
+				accountRepository *MockAccountRepository,
+				ledgerRepository *MockLedgerRepository,
+				outboxRepository *MockOutboxRepository,
+			) {
+				repository.EXPECT().GetExpiredOrdersForUpdate(ctx, limit).Return(nil, nil)
+			},
+			want: 0,
+		},
+		{
+			name: "get expired orders error",
+			mock: func(
+				repository *MockRepository,
+				transactionRepository *MockTransactionRepository,
+				accountRepository *MockAccountRepository,
+				ledgerRepository *MockLedgerRepository,
+				outboxRepository *MockOutboxRepository,
+			) {
+				repository.EXPECT().GetExpiredOrdersForUpdate(ctx, limit).Return(nil, repositoryErr)
+			},
+			wantedErr: repositoryErr,
+		},
+		{
+			name: "skip order that fails on update status",
+			mock: func(
+				repository *MockRepository,
+				transactionRepository *MockTransactionRepository,
+				accountRepository *MockAccountRepository,
+				ledgerRepository *MockLedgerRepository,
+				outboxRepository *MockOutboxRepository,
+			) {
+				repository.EXPECT().GetExpiredOrdersForUpdate(ctx, limit).Return(expiredOrders, nil)
+				repository.EXPECT().UpdateStatus(ctx, statusDTO(expiredOrders[0])).Return(repositoryErr)
+				expectExpiry(
+					repository,
+					transactionRepository,
+					accountRepository,
+					ledgerRepository,
+					outboxRepository,
+					expiredOrders[1],
+				)
+			},
+			want: 1,
+		},
+		{
+			name: "skip order that fails on account repository",
+			mock: func(
+				repository *MockRepository,
+				transactionRepository *MockTransactionRepository,
+				accountRepository *MockAccountRepository,
+				ledgerRepository *MockLedgerRepository,
+				outboxRepository *MockOutboxRepository,
+			) {
+				repository.EXPECT().GetExpiredOrdersForUpdate(ctx, limit).Return(expiredOrders, nil)
+				repository.EXPECT().UpdateStatus(ctx, statusDTO(expiredOrders[0])).Return(nil)
+				accountRepository.EXPECT().
+					ReturnBalance(ctx, accountDTO(expiredOrders[0])).
+					Return(int64(0), accountRepositoryErr)
+				expectExpiry(
+					repository,
+					transactionRepository,
+					accountRepository,
+					ledgerRepository,
+					outboxRepository,
+					expiredOrders[1],
+				)
+			},
+			want: 1,
+		},
+		{
+			name: "skip order that fails on transaction repository",
+			mock: func(
+				repository *MockRepository,
+				transactionRepository *MockTransactionRepository,
+				accountRepository *MockAccountRepository,
+				ledgerRepository *MockLedgerRepository,
+				outboxRepository *MockOutboxRepository,
+			) {
+				o := expiredOrders[0]
+				repository.EXPECT().GetExpiredOrdersForUpdate(ctx, limit).Return(expiredOrders, nil)
+				repository.EXPECT().UpdateStatus(ctx, statusDTO(o)).Return(nil)
+				accountRepository.EXPECT().ReturnBalance(ctx, accountDTO(o)).Return(o.Amount, nil)
+				transactionRepository.EXPECT().CreateTransaction(ctx, transactionDTO(o)).Return(transactionRepositoryErr)
+				expectExpiry(
+					repository,
+					transactionRepository,
+					accountRepository,
+					ledgerRepository,
+					outboxRepository,
+					expiredOrders[1],
+				)
+			},
+			want: 1,
+		},
+		{
+			name: "skip order that fails on ledger repository",
+			mock: func(
+				repository *MockRepository,
+				transactionRepository *MockTransactionRepository,
+				accountRepository *MockAccountRepository,
+				ledgerRepository *MockLedgerRepository,
+				outboxRepository *MockOutboxRepository,
+			) {
+				o := expiredOrders[0]
+				repository.EXPECT().GetExpiredOrdersForUpdate(ctx, limit).Return(expiredOrders, nil)
+				repository.EXPECT().UpdateStatus(ctx, statusDTO(o)).Return(nil)
+				accountRepository.EXPECT().ReturnBalance(ctx, accountDTO(o)).Return(o.Amount, nil)
+				transactionRepository.EXPECT().CreateTransaction(ctx, transactionDTO(o)).Return(nil)
+				ledgerRepository.EXPECT().CreatePosting(ctx, postingDTO(o)).Return(ledgerRepositoryErr)
+				expectExpiry(
+					repository,
+					transactionRepository,
+					accountRepository,
+					ledgerRepository,
+					outboxRepository,
+					expiredOrders[1],
+				)
+			},
+			want: 1,
+		},
+		{
+			name: "skip order that fails on outbox repository",
+			mock: func(
+				repository *MockRepository,
+				transactionRepository *MockTransactionRepository,
+				accountRepository *MockAccountRepository,
+				ledgerRepository *MockLedgerRepository,
+				outboxRepository *MockOutboxRepository,
+			) {
+				o := expiredOrders[0]
+				repository.EXPECT().GetExpiredOrdersForUpdate(ctx, limit).Return(expiredOrders, nil)
+				repository.EXPECT().UpdateStatus(ctx, statusDTO(o)).Return(nil)
+				accountRepository.EXPECT().ReturnBalance(ctx, accountDTO(o)).Return(o.Amount, nil)
+				transactionRepository.EXPECT().CreateTransaction(ctx, transactionDTO(o)).Return(nil)
+				ledgerRepository.EXPECT().CreatePosting(ctx, postingDTO(o)).Return(nil)
+				outboxRepository.EXPECT().CreateEvent(ctx, eventDTO(o)).Return(outboxRepositoryErr)
+				expectExpiry(
+					repository,
+					transactionRepository,
+					accountRepository,
+					ledgerRepository,
+					outboxRepository,
+					expiredOrders[1],
+				)
+			},
+			want: 1,
+		},
+		{
+			name: "transaction error",
+			mock: func(
+				repository *MockRepository,
+				transactionRepository *MockTransactionRepository,
+				accountRepository *MockAccountRepository,
+				ledgerRepository *MockLedgerRepository,
+				outboxRepository *MockOutboxRepository,
+			) {
+				repository.EXPECT().GetExpiredOrdersForUpdate(ctx, limit).Return(nil, nil)
+			},
+			txErr: txErr,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			service, repository, transactionRepository, accountRepository, ledgerRepository, outboxRepository := mockService(
+				t,
+				tt.txErr,
+			)
+
+			tt.mock(repository, transactionRepository, accountRepository, ledgerRepository, outboxRepository)
+			got, err := service.ExpireOrders(ctx, limit)
+			if tt.wantedErr != nil {
+				require.ErrorIs(t, err, tt.wantedErr)
+			}
+			if tt.txErr != nil {
+				require.ErrorIs(t, err, tt.txErr)
+			}
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestService_CaptureOrder(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	reservedOrder := order.Order{
+		OrderID:   1,
+		AccountID: 1,
+		ServiceID: 1,
+		Amount:    100,
+		Currency:  currency.RUB,
+		Status:    order.Reserved,
+	}
+	dto := order.CaptureDTO{
+		OrderID:   reservedOrder.OrderID,
+		AccountID: reservedOrder.AccountID,
+		ServiceID: reservedOrder.ServiceID,
+		Amount:    70,
+		Currency:  reservedOrder.Currency,
+		Actor:     "api",
+		Reason:    "service is provided with discount",
+	}
+	fullDTO := dto
+	fullDTO.Amount = reservedOrder.Amount
+	paidOrder := reservedOrder
+	paidOrder.Status = order.Paid
+	anotherOrder := reservedOrder
+	anotherOrder.AccountID = 2
+	usdOrder := reservedOrder
+	usdOrder.Currency = currency.USD
+	statusDTO := order.UpdateStatusDTO{
+		OrderID: dto.OrderID,
+		From:    order.Reserved,
+		To:      order.Paid,
+		Actor:   dto.Actor,
+		Reason:  dto.Reason,
+	}
+	captureBalanceDTO := func(amount int64) account.CaptureBalanceDTO {
+		return account.CaptureBalanceDTO{
+			AccountID: dto.AccountID,
+			Amount:    amount,
+			Currency:  dto.Currency,
+		}
+	}
+	capturedDTO := func(amount int64) order.SetCapturedAmountDTO {
+		return order.SetCapturedAmountDTO{
+			OrderID: dto.OrderID,
+			Amount:  amount,
+		}
+	}
+	paymentDTO := func(amount int64) transaction.CreateDTO {
+		return transaction.CreateDTO{
+			Type:        transaction.Payment,
+			SenderID:    dto.AccountID,
+			ReceiverID:  dto.AccountID,
+			Amount:      amount,
+			Currency:    dto.Currency,
+			Description: fmt.Sprintf("Pay %s for order with id = %d", dto.Currency.Format(amount), dto.OrderID),
+		}
+	}
+	paidEventDTO := func(amount int64) outbox.CreateDTO {
+		return outbox.NewOrderPaidDTO(outbox.OrderPaidPayload{
+			OrderID:        reservedOrder.OrderID,
+			AccountID:      reservedOrder.AccountID,
+			ServiceID:      reservedOrder.ServiceID,
+			Amount:         reservedOrder.Amount,
+			CapturedAmount: amount,
+			Currency:       reservedOrder.Currency,
+		})
+	}
+	returnTransactionDTO := transaction.CreateDTO{
+		Type:        transaction.CancelReservation,
+		SenderID:    dto.AccountID,
+		ReceiverID:  dto.AccountID,
+		Amount:      30,
+		Currency:    dto.Currency,
+		Description: fmt.Sprintf("Return 0.30 RUB uncaptured for order with id = %d", dto.OrderID),
+	}
+	returnAccountDTO := account.ReturnBalanceDTO{
+		AccountID: dto.AccountID,
+		Amount:    30,
+		Currency:  dto.Currency,
+	}
+	returnPostingDTO := ledger.NewCancelReservationDTO(dto.AccountID, 30, dto.Currency, returnTransactionDTO.Description)
+	txErr := errors.New("transaction error")
+	repositoryErr := errors.New("repository error")
+	accountRepositoryErr := errors.New("account repository error")
+	transactionRepositoryErr := errors.New("transaction repository error")
+	ledgerRepositoryErr := errors.New("ledger repository error")
+	outboxRepositoryErr := errors.New("outbox repository error")
+
+	capturedOrder := paidOrder
+	capturedOrder.CapturedAmount = 70
+	fullCapturedOrder := paidOrder
+	fullCapturedOrder.CapturedAmount = 100
+
+	type mockBehavior func(
+		r *MockRepository,
+		tr *MockTransactionRepository,
+		ar *MockAccountRepository,
+		lr *MockLedgerRepository,
+		or *MockOutboxRepository,
+	)
+
+	tests := []struct {
+		name      string
+		dto       order.CaptureDTO
+		mock      mockBehavior
+		want      order.Order
+		wantedErr error
+		txErr     error
+	}{
+		{
+			name: "success partial capture",
+			dto:  dto,
+			mock: func(
+				repository *MockRepository,
+				transactionRepository *MockTransactionRepository,
+				accountRepository *MockAccountRepository,
+				ledgerRepository *MockLedgerRepository,
+				outboxRepository *MockOutboxRepository,
+			) {
+				repository.EXPECT().GetOrderForUpdate(ctx, dto.OrderID).Return(reservedOrder, nil)
+				repository.EXPECT().UpdateStatus(ctx, statusDTO).Return(nil)
+				repository.EXPECT().SetCapturedAmount(ctx, capturedDTO(70)).Return(nil)
+				accountRepository.EXPECT().CaptureBalance(ctx, captureBalanceDTO(70)).Return(int64(0), nil)
+				transactionRepository.EXPECT().CreateTransaction(ctx, paymentDTO(70)).Return(nil)
+				ledgerRepository.EXPECT().
+					CreatePosting(ctx, ledger.NewPaymentDTO(70, dto.Currency, paymentDTO(70).Description)).
+					Return(nil)
+				outboxRepository.EXPECT().CreateEvent(ctx, paidEventDTO(70)).Return(nil)
+				accountRepository.EXPECT().ReturnBalance(ctx, returnAccountDTO).Return(int64(930), nil)
+				transactionRepository.EXPECT().CreateTransaction(ctx, returnTransactionDTO).Return(nil)
+				ledgerRepository.EXPECT().CreatePosting(ctx, returnPostingDTO).Return(nil)
+			},
+			want: capturedOrder,
+		},
+		{
+			name: "success full capture",
+			dto:  fullDTO,
+			mock: func(
+				repository *MockRepository,
+				transactionRepository *MockTransactionRepository,
+				accountRepository *MockAccountRepository,
+				ledgerRepository *MockLedgerRepository,
+				outboxRepository *MockOutboxRepository,
+			) {
+				repository.EXPECT().GetOrderForUpdate(ctx, dto.OrderID).Return(reservedOrder, nil)
+				repository.EXPECT().UpdateStatus(ctx, statusDTO).Return(nil)
+				repository.EXPECT().SetCapturedAmount(ctx, capturedDTO(100)).Return(nil)
+				accountRepository.EXPECT().CaptureBalance(ctx, captureBalanceDTO(100)).Return(int64(0), nil)
+				transactionRepository.EXPECT().CreateTransaction(ctx, paymentDTO(100)).Return(nil)
+				ledgerRepository.EXPECT().
+					CreatePosting(ctx, ledger.NewPaymentDTO(100, dto.Currency, paymentDTO(100).Description)).
+					Return(nil)
+				outboxRepository.EXPECT().CreateEvent(ctx, paidEventDTO(100)).Return(nil)
+			},
+			want: fullCapturedOrder,
+		},
+		{
+			name: "order not found",
+			dto:  dto,
+			mock: func(
+				repository *MockRepository,
+				transactionRepository *MockTransactionRepository,
+				accountRepository *MockAccountRepository,
+				ledgerRepository *MockLedgerRepository,
+				outboxRepository *MockOutboxRepository,
+			) {
+				repository.EXPECT().GetOrderForUpdate(ctx, dto.OrderID).Return(order.Order{}, order.ErrNotFound)
+			},
+			wantedErr: order.ErrNotFound,
+		},
+		{
+			name: "order does not match request",
+			dto:  dto,
+			mock: func(
+				repository *MockRepository,
+				transactionRepository *MockTransactionRepository,
+				accountRepository *MockAccountRepository,
+				ledgerRepository *MockLedgerRepository,
+				outboxRepository *MockOutboxRepository,
+			) {
+				repository.EXPECT().GetOrderForUpdate(ctx, dto.OrderID).Return(anotherOrder, nil)
+			},
+			wantedErr: order.ErrNotFound,
+		},
+		{
+			name: "order currency does not match request",
+			dto:  dto,
+			mock: func(
+				repository *MockRepository,
+				transactionRepository *MockTransactionRepository,
+				accountRepository *MockAccountRepository,
+				ledgerRepository *MockLedgerRepository,
+				outboxRepository *MockOutboxRepository,
+			) {
+				repository.EXPECT().GetOrderForUpdate(ctx, dto.OrderID).Return(usdOrder, nil)
+			},
+			wantedErr: currency.ErrMismatch,
+		},
+		{
+			name: "order is already paid",
+			dto:  dto,
+			mock: func(
+				repository *MockRepository,
+				transactionRepository *MockTransactionRepository,
+				accountRepository *MockAccountRepository,
+				ledgerRepository *MockLedgerRepository,
+				outboxRepository *MockOutboxRepository,
+			) {
+				repository.EXPECT().GetOrderForUpdate(ctx, dto.OrderID).Return(paidOrder, nil)
+			},
+			wantedErr: order.ErrIllegalTransition,
+		},
+		{
+			name: "capture exceeds reserved amount",
+			dto: order.CaptureDTO{
+				OrderID:   reservedOrder.OrderID,
+				AccountID: reservedOrder.AccountID,
+				ServiceID: reservedOrder.ServiceID,
+				Amount:    101,
+				Currency:  currency.RUB,
+			},
+			mock: func(
+				repository *MockRepository,
+				transactionRepository *MockTransactionRepository,
+				accountRepository *MockAccountRepository,
+				ledgerRepository *MockLedgerRepository,
+				outboxRepository *MockOutboxRepository,
+			) {
+				repository.EXPECT().GetOrderForUpdate(ctx, dto.OrderID).Return(reservedOrder, nil)
+			},
+			wantedErr: order.ErrCaptureExceedsReserved,
+		},
+		{
+			name: "account repository error",
+			dto:  dto,
+			mock: func(
+				repository *MockRepository,
+				transactionRepository *MockTransactionRepository,
+				accountRepository *MockAccountRepository,
+				ledgerRepository *MockLedgerRepository,
+				outboxRepository *MockOutboxRepository,
+			) {
+				repository.EXPECT().GetOrderForUpdate(ctx, dto.OrderID).Return(reservedOrder, nil)
+				repository.EXPECT().UpdateStatus(ctx, statusDTO).Return(nil)
+				repository.EXPECT().SetCapturedAmount(ctx, capturedDTO(70)).Return(nil)
+				accountRepository.EXPECT().CaptureBalance(ctx, captureBalanceDTO(70)).Return(int64(0), nil)
+				transactionRepository.EXPECT().CreateTransaction(ctx, paymentDTO(70)).Return(nil)
+				ledgerRepository.EXPECT().
+					CreatePosting(ctx, ledger.NewPaymentDTO(70, dto.Currency, paymentDTO(70).Description)).
+					Return(nil)
+				outboxRepository.EXPECT().CreateEvent(ctx, paidEventDTO(70)).Return(nil)
+				accountRepository.EXPECT().ReturnBalance(ctx, returnAccountDTO).Return(int64(0), accountRepositoryErr)
+			},
+			wantedErr: accountRepositoryErr,
+		},
+		{
+			name: "return transaction error",
+			dto:  dto,
+			mock: func(
+				repository *MockRepository,
+				transactionRepository *MockTransactionRepository,
+				accountRepository *MockAccountRepository,
+				ledgerRepository *MockLedgerRepository,
+				outboxRepository *MockOutboxRepository,
+			) {
+				repository.EXPECT().GetOrderForUpdate(ctx, dto.OrderID).Return(reservedOrder, nil)
+				repository.EXPECT().UpdateStatus(ctx, statusDTO).Return(nil)
+				repository.EXPECT().SetCapturedAmount(ctx, capturedDTO(70)).Return(nil)
+				accountRepository.EXPECT().CaptureBalance(ctx, captureBalanceDTO(70)).Return(int64(0), nil)
+				transactionRepository.EXPECT().CreateTransaction(ctx, paymentDTO(70)).Return(nil)
+				ledgerRepository.EXPECT().
+					CreatePosting(ctx, ledger.NewPaymentDTO(70, dto.Currency, paymentDTO(70).Description)).
+					Return(nil)
+				outboxRepository.EXPECT().CreateEvent(ctx, paidEventDTO(70)).Return(nil)
+				accountRepository.EXPECT().ReturnBalance(ctx, returnAccountDTO).Return(int64(930), nil)
+				transactionRepository.EXPECT().
+					CreateTransaction(ctx, returnTransactionDTO).
+					Return(transactionRepositoryErr)
+			},
+			wantedErr: transactionRepositoryErr,
+		},
+		{
+			name: "return posting error",
+			dto:  dto,
+			mock: func(
+				repository *MockRepository,
+				transactionRepository *MockTransactionRepository,
+				accountRepository *MockAccountRepository,
+				ledgerRepository *MockLedgerRepository,
+				outboxRepository *MockOutboxRepository,
+			) {
+				repository.EXPECT().GetOrderForUpdate(ctx, dto.OrderID).Return(reservedOrder, nil)
+				repository.EXPECT().UpdateStatus(ctx, statusDTO).Return(nil)
+				repository.EXPECT().SetCapturedAmount(ctx, capturedDTO(70)).Return(nil)
+				accountRepository.EXPECT().CaptureBalance(ctx, captureBalanceDTO(70)).Return(int64(0), nil)
+				transactionRepository.EXPECT().CreateTransaction(ctx, paymentDTO(70)).Return(nil)
+				ledgerRepository.EXPECT().
+					CreatePosting(ctx, ledger.NewPaymentDTO(70, dto.Currency, paymentDTO(70).Description)).
+					Return(nil)
+				outboxRepository.EXPECT().CreateEvent(ctx, paidEventDTO(70)).Return(nil)
+				accountRepository.EXPECT().ReturnBalance(ctx, returnAccountDTO).Return(int64(930), nil)
+				transactionRepository.EXPECT().CreateTransaction(ctx, returnTransactionDTO).Return(nil)
+				ledgerRepository.EXPECT().CreatePosting(ctx, returnPostingDTO).Return(ledgerRepositoryErr)
+			},
+			wantedErr: ledgerRepositoryErr,
+		},
+		{
+			name: "set captured amount error",
+			dto:  dto,
+			mock: func(
+				repository *MockRepository,
+				transactionRepository *MockTransactionRepository,
+				accountRepository *MockAccountRepository,
+				ledgerRepository *MockLedgerRepository,
+				outboxRepository *MockOutboxRepository,
+			) {
+				repository.EXPECT().GetOrderForUpdate(ctx, dto.OrderID).Return(reservedOrder, nil)
+				repository.EXPECT().UpdateStatus(ctx, statusDTO).Return(nil)
+				repository.EXPECT().SetCapturedAmount(ctx, capturedDTO(70)).Return(repositoryErr)
+			},
+			wantedErr: repositoryErr,
+		},
+		{
+			name: "outbox repository error",
+			dto:  fullDTO,
+			mock: func(
+				repository *MockRepository,
+				transactionRepository *MockTransactionRepository,
+				accountRepository *MockAccountRepository,
+				ledgerRepository *MockLedgerRepository,
+				outboxRepository *MockOutboxRepository,
+			) {
+				repository.EXPECT().GetOrderForUpdate(ctx, dto.OrderID).Return(reservedOrder, nil)
+				repository.EXPECT().UpdateStatus(ctx, statusDTO).Return(nil)
+				repository.EXPECT().SetCapturedAmount(ctx, capturedDTO(100)).Return(nil)
+				accountRepository.EXPECT().CaptureBalance(ctx, captureBalanceDTO(100)).Return(int64(0), nil)
+				transactionRepository.EXPECT().CreateTransaction(ctx, paymentDTO(100)).Return(nil)
+				ledgerRepository.EXPECT().
+					CreatePosting(ctx, ledger.NewPaymentDTO(100, dto.Currency, paymentDTO(100).Description)).
+					Return(nil)
+				outboxRepository.EXPECT().CreateEvent(ctx, paidEventDTO(100)).Return(outboxRepositoryErr)
+			},
+			wantedErr: outboxRepositoryErr,
+		},
+		{
+			name: "transaction error",
+			dto:  fullDTO,
+			mock: func(
+				repository *MockRepository,
+				transactionRepository *MockTransactionRepository,
+				accountRepository *MockAccountRepository,
+				ledgerRepository *MockLedgerRepository,
+				outboxRepository *MockOutboxRepository,
+			) {
+				repository.EXPECT().GetOrderForUpdate(ctx, dto.OrderID).Return(reservedOrder, nil)
+				repository.EXPECT().UpdateStatus(ctx, statusDTO).Return(nil)
+				repository.EXPECT().SetCapturedAmount(ctx, capturedDTO(100)).Return(nil)
+				accountRepository.EXPECT().CaptureBalance(ctx, captureBalanceDTO(100)).Return(int64(0), nil)
+				transactionRepository.EXPECT().CreateTransaction(ctx, paymentDTO(100)).Return(nil)
+				ledgerRepository.EXPECT().
+					CreatePosting(ctx, ledger.NewPaymentDTO(100, dto.Currency, paymentDTO(100).Description)).
+					Return(nil)
+				outboxRepository.EXPECT().CreateEvent(ctx, paidEventDTO(100)).Return(nil)
 			},
-			want:      0,
-			wantedErr: nil,
-			txErr:     txErr,
+			txErr: txErr,
 		},
 	}
 
@@ -449,10 +2027,13 @@ func TestService_CancelOrder(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			t.Parallel()
 
-			service, repository, transactionRepository, accountRepository := mockService(t, tt.txErr)
+			service, repository, transactionRepository, accountRepository, ledgerRepository, outboxRepository := mockService(
+				t,
+				tt.txErr,
+			)
 
-			tt.mock(repository, transactionRepository, accountRepository)
-			got, err := service.CancelOrder(ctx, tt.args.dto)
+			tt.mock(repository, transactionRepository, accountRepository, ledgerRepository, outboxRepository)
+			got, err := service.CaptureOrder(ctx, tt.dto)
 			if tt.wantedErr != nil {
 				require.ErrorIs(t, err, tt.wantedErr)
 			}
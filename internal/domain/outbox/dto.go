@@ -0,0 +1,130 @@
+package outbox
+
+import (
+	"time"
+
+	"github.com/maypok86/payment-api/internal/domain/currency"
+)
+
+// CreateDTO describes an event to write to the outbox. Payload is stored as JSON.
+type CreateDTO struct {
+	Type          Type
+	AggregateType string
+	AggregateID   int64
+	Payload       interface{}
+}
+
+type MarkFailedDTO struct {
+	EventID    int64
+	Error      string
+	RetryAfter time.Duration
+}
+
+type BalanceCreditedPayload struct {
+	AccountID int64             `json:"account_id"`
+	Amount    int64             `json:"amount"`
+	Currency  currency.Currency `json:"currency"`
+	Balance   int64             `json:"balance"`
+}
+
+// TransferCompletedPayload belongs to the sender account.
+type TransferCompletedPayload struct {
+	SenderID         int64             `json:"sender_id"`
+	ReceiverID       int64             `json:"receiver_id"`
+	Amount           int64             `json:"amount"`
+	Currency         currency.Currency `json:"currency"`
+	ReceiverAmount   int64             `json:"receiver_amount"`
+	ReceiverCurrency currency.Currency `json:"receiver_currency"`
+	SenderBalance    int64             `json:"sender_balance"`
+	ReceiverBalance  int64             `json:"receiver_balance"`
+}
+
+type OrderReservedPayload struct {
+	OrderID   int64             `json:"order_id"`
+	AccountID int64             `json:"account_id"`
+	ServiceID int64             `json:"service_id"`
+	Amount    int64             `json:"amount"`
+	Currency  currency.Currency `json:"currency"`
+	ExpiresAt time.Time         `json:"expires_at"`
+}
+
+type OrderPaidPayload struct {
+	OrderID        int64             `json:"order_id"`
+	AccountID      int64             `json:"account_id"`
+	ServiceID      int64             `json:"service_id"`
+	Amount         int64             `json:"amount"`
+	CapturedAmount int64             `json:"captured_amount"`
+	Currency       currency.Currency `json:"currency"`
+}
+
+type OrderCancelledPayload struct {
+	OrderID   int64             `json:"order_id"`
+	AccountID int64             `json:"account_id"`
+	ServiceID int64             `json:"service_id"`
+	Amount    int64             `json:"amount"`
+	Currency  currency.Currency `json:"currency"`
+	Reason    string            `json:"reason,omitempty"`
+}
+
+type OrderRefundedPayload struct {
+	OrderID        int64             `json:"order_id"`
+	AccountID      int64             `json:"account_id"`
+	ServiceID      int64             `json:"service_id"`
+	Amount         int64             `json:"amount"`
+	RefundedAmount int64             `json:"refunded_amount"`
+	Currency       currency.Currency `json:"currency"`
+}
+
+func NewBalanceCreditedDTO(payload BalanceCreditedPayload) CreateDTO {
+	return CreateDTO{
+		Type:          BalanceCredited,
+		AggregateType: AccountAggregate,
+		AggregateID:   payload.AccountID,
+		Payload:       payload,
+	}
+}
+
+func NewTransferCompletedDTO(payload TransferCompletedPayload) CreateDTO {
+	return CreateDTO{
+		Type:          TransferCompleted,
+		AggregateType: AccountAggregate,
+		AggregateID:   payload.SenderID,
+		Payload:       payload,
+	}
+}
+
+func NewOrderReservedDTO(payload OrderReservedPayload) CreateDTO {
+	return CreateDTO{
+		Type:          OrderReserved,
+		AggregateType: OrderAggregate,
+		AggregateID:   payload.OrderID,
+		Payload:       payload,
+	}
+}
+
+func NewOrderPaidDTO(payload OrderPaidPayload) CreateDTO {
+	return CreateDTO{
+		Type:          OrderPaid,
+		AggregateType: OrderAggregate,
+		AggregateID:   payload.OrderID,
+		Payload:       payload,
+	}
+}
+
+func NewOrderCancelledDTO(payload OrderCancelledPayload) CreateDTO {
+	return CreateDTO{
+		Type:          OrderCancelled,
+		AggregateType: OrderAggregate,
+		AggregateID:   payload.OrderID,
+		Payload:       payload,
+	}
+}
+
+func NewOrderRefundedDTO(payload OrderRefundedPayload) CreateDTO {
+	return CreateDTO{
+		Type:          OrderRefunded,
+		AggregateType: OrderAggregate,
+		AggregateID:   payload.OrderID,
+		Payload:       payload,
+	}
+}
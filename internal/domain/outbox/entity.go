@@ -0,0 +1,159 @@
+package outbox
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+const (
+	AccountAggregate = "account"
+	OrderAggregate   = "order"
+)
+
+const (
+	minRetryDelay = time.Second
+	maxRetryDelay = 5 * time.Minute
+)
+
+type Type struct {
+	string
+}
+
+var (
+	ErrInvalidType      = errors.New("event type is not valid")
+	ErrUnknownAggregate = errors.New("event aggregate is unknown")
+)
+
+var (
+	BalanceCredited   = Type{"balance_credited"}
+	TransferCompleted = Type{"transfer_completed"}
+	OrderReserved     = Type{"order_reserved"}
+	OrderPaid         = Type{"order_paid"}
+	OrderCancelled    = Type{"order_cancelled"}
+	OrderRefunded     = Type{"order_refunded"}
+)
+
+var typeToString = map[Type]string{
+	BalanceCredited:   "balance_credited",
+	TransferCompleted: "transfer_completed",
+	OrderReserved:     "order_reserved",
+	OrderPaid:         "order_paid",
+	OrderCancelled:    "order_cancelled",
+	OrderRefunded:     "order_refunded",
+}
+
+var stringToType = map[string]Type{
+	"balance_credited":   BalanceCredited,
+	"transfer_completed": TransferCompleted,
+	"order_reserved":     OrderReserved,
+	"order_paid":         OrderPaid,
+	"order_cancelled":    OrderCancelled,
+	"order_refunded":     OrderRefunded,
+}
+
+func ParseType(s string) (Type, error) {
+	if t, ok := stringToType[s]; ok {
+		return t, nil
+	}
+
+	return Type{}, fmt.Errorf("%w: %q", ErrInvalidType, s)
+}
+
+func (t Type) String() string {
+	return typeToString[t]
+}
+
+func (t *Type) Scan(value interface{}) error {
+	str, ok := value.(string)
+	if !ok {
+		return errors.New("scan source is not string")
+	}
+
+	if v, ok := stringToType[str]; ok {
+		*t = v
+		return nil
+	}
+
+	return errors.New("wrong value for Type")
+}
+
+func (t Type) Value() (driver.Value, error) {
+	str, ok := typeToString[t]
+	if !ok {
+		return nil, errors.New("wrong value for Type")
+	}
+
+	return str, nil
+}
+
+// Event is a domain event written in the same transaction as the change it describes. Events of one aggregate
+// are delivered in the order they were written.
+type Event struct {
+	EventID       int64
+	Type          Type
+	AggregateType string
+	AggregateID   int64
+	Payload       json.RawMessage
+	Attempts      int
+	CreatedAt     time.Time
+}
+
+// Owners returns the account and the service the event belongs to. Account events belong to their account only,
+// so their service is zero, and order events belong to both the account and the service of the order.
+func (e Event) Owners() (accountID, serviceID int64, err error) {
+	switch e.AggregateType {
+	case AccountAggregate:
+		return e.AggregateID, 0, nil
+	case OrderAggregate:
+		var owners struct {
+			AccountID int64 `json:"account_id"`
+			ServiceID int64 `json:"service_id"`
+		}
+		if err := json.Unmarshal(e.Payload, &owners); err != nil {
+			return 0, 0, fmt.Errorf("unmarshal order event owners: %w", err)
+		}
+
+		return owners.AccountID, owners.ServiceID, nil
+	default:
+		return 0, 0, fmt.Errorf("%w: %q", ErrUnknownAggregate, e.AggregateType)
+	}
+}
+
+// Message is the JSON form of an event that consumers receive. Events are delivered at least once,
+// so consumers should skip event ids they have already seen.
+type Message struct {
+	EventID       int64           `json:"event_id"`
+	Type          string          `json:"type"`
+	AggregateType string          `json:"aggregate_type"`
+	AggregateID   int64           `json:"aggregate_id"`
+	Payload       json.RawMessage `json:"payload"`
+	CreatedAt     time.Time       `json:"created_at"`
+}
+
+func NewMessage(event Event) Message {
+	return Message{
+		EventID:       event.EventID,
+		Type:          event.Type.String(),
+		AggregateType: event.AggregateType,
+		AggregateID:   event.AggregateID,
+		Payload:       event.Payload,
+		CreatedAt:     event.CreatedAt,
+	}
+}
+
+// RetryDelay returns how long to wait before the next delivery of an event that failed attempts times.
+// The delay doubles with every attempt up to five minutes.
+func RetryDelay(attempts int) time.Duration {
+	delay := minRetryDelay
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= maxRetryDelay {
+			return maxRetryDelay
+		}
+	}
+
+	return delay
+}
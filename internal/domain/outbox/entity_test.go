@@ -0,0 +1,139 @@
+package outbox_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/maypok86/payment-api/internal/domain/outbox"
+	"github.com/stretchr/testify/require"
+)
+
+func TestType_Scan(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		value   interface{}
+		want    outbox.Type
+		wantErr bool
+	}{
+		{
+			name:  "success scan",
+			value: "order_paid",
+			want:  outbox.OrderPaid,
+		},
+		{
+			name:    "wrong value for Type",
+			value:   "wrong",
+			want:    outbox.Type{},
+			wantErr: true,
+		},
+		{
+			name:    "scan source is not string",
+			value:   1,
+			want:    outbox.Type{},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var eventType outbox.Type
+
+			err := eventType.Scan(tt.value)
+			require.True(t, (err != nil) == tt.wantErr)
+			require.True(t, reflect.DeepEqual(tt.want, eventType))
+		})
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{attempts: 1, want: time.Second},
+		{attempts: 2, want: 2 * time.Second},
+		{attempts: 5, want: 16 * time.Second},
+		{attempts: 9, want: 256 * time.Second},
+		{attempts: 10, want: 5 * time.Minute},
+		{attempts: 100, want: 5 * time.Minute},
+	}
+
+	for _, tt := range tests {
+		require.Equal(t, tt.want, outbox.RetryDelay(tt.attempts), "attempts = %d", tt.attempts)
+	}
+}
+
+func TestEvent_Owners(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name          string
+		event         outbox.Event
+		wantAccountID int64
+		wantServiceID int64
+		wantErr       bool
+	}{
+		{
+			name: "account event",
+			event: outbox.Event{
+				AggregateType: outbox.AccountAggregate,
+				AggregateID:   7,
+				Payload:       []byte(`{"account_id":7}`),
+			},
+			wantAccountID: 7,
+		},
+		{
+			name: "order event",
+			event: outbox.Event{
+				AggregateType: outbox.OrderAggregate,
+				AggregateID:   1,
+				Payload:       []byte(`{"order_id":1,"account_id":7,"service_id":3}`),
+			},
+			wantAccountID: 7,
+			wantServiceID: 3,
+		},
+		{
+			name: "malformed order payload",
+			event: outbox.Event{
+				AggregateType: outbox.OrderAggregate,
+				AggregateID:   1,
+				Payload:       []byte(`[]`),
+			},
+			wantErr: true,
+		},
+		{
+			name: "unknown aggregate",
+			event: outbox.Event{
+				AggregateType: "invoice",
+				AggregateID:   1,
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			accountID, serviceID, err := tt.event.Owners()
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.wantAccountID, accountID)
+			require.Equal(t, tt.wantServiceID, serviceID)
+		})
+	}
+}
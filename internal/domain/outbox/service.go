@@ -0,0 +1,81 @@
+package outbox
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+//go:generate mockgen -source=service.go -destination=mock_test.go -package=outbox_test
+
+type Repository interface {
+	ClaimEvents(ctx context.Context, limit uint64, timeout time.Duration) ([]Event, error)
+	MarkPublished(ctx context.Context, eventID int64) error
+	MarkFailed(ctx context.Context, dto MarkFailedDTO) error
+}
+
+type Publisher interface {
+	Publish(ctx context.Context, event Event) error
+}
+
+type Service struct {
+	repository   Repository
+	publisher    Publisher
+	claimTimeout time.Duration
+	logger       *zap.Logger
+}
+
+func NewService(repository Repository, publisher Publisher, claimTimeout time.Duration, logger *zap.Logger) *Service {
+	return &Service{
+		repository:   repository,
+		publisher:    publisher,
+		claimTimeout: claimTimeout,
+		logger:       logger,
+	}
+}
+
+// PublishEvents delivers up to limit pending events and returns how many of them were published. Only the oldest
+// pending event of each aggregate is claimed, so a failed event holds back the later events of its aggregate until
+// a retry succeeds.
+//
+// The events are claimed for the claim timeout in a statement of their own and published outside of any
+// transaction, so no row lock is held during network I/O. An event whose result is not recorded before its
+// claim runs out, for example because the process stopped, is claimed and published again, so every event is
+// published at least once.
+func (s *Service) PublishEvents(ctx context.Context, limit uint64) (published int, err error) {
+	events, err := s.repository.ClaimEvents(ctx, limit, s.claimTimeout)
+	if err != nil {
+		return 0, fmt.Errorf("publish events: %w", err)
+	}
+
+	for _, event := range events {
+		if err := s.publisher.Publish(ctx, event); err != nil {
+			s.logger.Warn(
+				"publish event",
+				zap.Int64("event_id", event.EventID),
+				zap.Int("attempts", event.Attempts+1),
+				zap.Error(err),
+			)
+
+			if err := s.repository.MarkFailed(ctx, MarkFailedDTO{
+				EventID:    event.EventID,
+				Error:      err.Error(),
+				RetryAfter: RetryDelay(event.Attempts + 1),
+			}); err != nil {
+				return 0, fmt.Errorf("publish events: %w", err)
+			}
+
+			continue
+		}
+
+		if err := s.repository.MarkPublished(ctx, event.EventID); err != nil {
+			return 0, fmt.Errorf("publish events: %w", err)
+		}
+
+		published++
+	}
+
+	return published, nil
+}
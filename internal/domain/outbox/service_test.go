@@ -0,0 +1,147 @@
+package outbox_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/maypok86/payment-api/internal/domain/outbox"
+	"github.com/maypok86/payment-api/internal/pkg/logger"
+	"github.com/stretchr/testify/require"
+)
+
+const claimTimeout = 10 * time.Minute
+
+func mockService(t *testing.T) (*outbox.Service, *MockRepository, *MockPublisher) {
+	t.Helper()
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	l := logger.New(os.Stdout, "debug")
+
+	repository := NewMockRepository(mockCtrl)
+	publisher := NewMockPublisher(mockCtrl)
+	service := outbox.NewService(repository, publisher, claimTimeout, l)
+
+	return service, repository, publisher
+}
+
+func TestService_PublishEvents(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	limit := uint64(10)
+	events := []outbox.Event{
+		{
+			EventID:       1,
+			Type:          outbox.OrderPaid,
+			AggregateType: outbox.OrderAggregate,
+			AggregateID:   1,
+			Payload:       []byte(`{"order_id":1}`),
+		},
+		{
+			EventID:       2,
+			Type:          outbox.BalanceCredited,
+			AggregateType: outbox.AccountAggregate,
+			AggregateID:   1,
+			Payload:       []byte(`{"account_id":1}`),
+			Attempts:      2,
+		},
+	}
+	repositoryErr := errors.New("repository error")
+	publishErr := errors.New("publish error")
+
+	type mockBehavior func(r *MockRepository, p *MockPublisher)
+
+	tests := []struct {
+		name      string
+		mock      mockBehavior
+		want      int
+		wantedErr error
+	}{
+		{
+			name: "success publish events",
+			mock: func(repository *MockRepository, publisher *MockPublisher) {
+				repository.EXPECT().ClaimEvents(ctx, limit, claimTimeout).Return(events, nil)
+				publisher.EXPECT().Publish(ctx, events[0]).Return(nil)
+				repository.EXPECT().MarkPublished(ctx, events[0].EventID).Return(nil)
+				publisher.EXPECT().Publish(ctx, events[1]).Return(nil)
+				repository.EXPECT().MarkPublished(ctx, events[1].EventID).Return(nil)
+			},
+			want: 2,
+		},
+		{
+			name: "no pending events",
+			mock: func(repository *MockRepository, publisher *MockPublisher) {
+				repository.EXPECT().ClaimEvents(ctx, limit, claimTimeout).Return(nil, nil)
+			},
+		},
+		{
+			name: "publish error is retried later",
+			mock: func(repository *MockRepository, publisher *MockPublisher) {
+				repository.EXPECT().ClaimEvents(ctx, limit, claimTimeout).Return(events, nil)
+				publisher.EXPECT().Publish(ctx, events[0]).Return(nil)
+				repository.EXPECT().MarkPublished(ctx, events[0].EventID).Return(nil)
+				publisher.EXPECT().Publish(ctx, events[1]).Return(publishErr)
+				repository.EXPECT().MarkFailed(ctx, outbox.MarkFailedDTO{
+					EventID:    events[1].EventID,
+					Error:      publishErr.Error(),
+					RetryAfter: outbox.RetryDelay(3),
+				}).Return(nil)
+			},
+			want: 1,
+		},
+		{
+			name: "claim events error",
+			mock: func(repository *MockRepository, publisher *MockPublisher) {
+				repository.EXPECT().ClaimEvents(ctx, limit, claimTimeout).Return(nil, repositoryErr)
+			},
+			wantedErr: repositoryErr,
+		},
+		{
+			name: "mark published error",
+			mock: func(repository *MockRepository, publisher *MockPublisher) {
+				repository.EXPECT().ClaimEvents(ctx, limit, claimTimeout).Return(events, nil)
+				publisher.EXPECT().Publish(ctx, events[0]).Return(nil)
+				repository.EXPECT().MarkPublished(ctx, events[0].EventID).Return(repositoryErr)
+			},
+			wantedErr: repositoryErr,
+		},
+		{
+			name: "mark failed error",
+			mock: func(repository *MockRepository, publisher *MockPublisher) {
+				repository.EXPECT().ClaimEvents(ctx, limit, claimTimeout).Return(events[:1], nil)
+				publisher.EXPECT().Publish(ctx, events[0]).Return(publishErr)
+				repository.EXPECT().MarkFailed(ctx, outbox.MarkFailedDTO{
+					EventID:    events[0].EventID,
+					Error:      publishErr.Error(),
+					RetryAfter: outbox.RetryDelay(1),
+				}).Return(repositoryErr)
+			},
+			wantedErr: repositoryErr,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			service, repository, publisher := mockService(t)
+
+			tt.mock(repository, publisher)
+
+			got, err := service.PublishEvents(ctx, limit)
+			if tt.wantedErr != nil {
+				require.ErrorIs(t, err, tt.wantedErr)
+			}
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
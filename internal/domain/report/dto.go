@@ -1,6 +1,31 @@
 package report
 
+import "time"
+
 type GetMapDTO struct {
-	Month int64
-	Year  int64
+	Month  int64
+	Year   int64
+	Format string
+}
+
+// Period returns the bounds of the month in UTC, from is included and to is not. Months are always taken in UTC,
+// so a report does not depend on the time zone of the service or of the database session.
+func (dto GetMapDTO) Period() (from, to time.Time) {
+	from = time.Date(int(dto.Year), time.Month(dto.Month), 1, 0, 0, 0, 0, time.UTC)
+
+	return from, from.AddDate(0, 1, 0)
+}
+
+// CreateJobDTO queues the report of a month for Principal, only Principal can get the job and its link.
+type CreateJobDTO struct {
+	GetMapDTO
+	Principal string
+}
+
+// SaveJobResultDTO finishes a job. Key is set for a done job, Error for a failed one.
+type SaveJobResultDTO struct {
+	JobID  int64
+	Status JobStatus
+	Key    string
+	Error  string
 }
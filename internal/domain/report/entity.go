@@ -1,8 +1,30 @@
 package report
 
-import "errors"
+import (
+	"errors"
+
+	"github.com/maypok86/payment-api/internal/domain/currency"
+)
 
 var (
-	ErrIsNotAvailable = errors.New("this report is not available yet")
-	ErrNotFound       = errors.New("report not found")
+	ErrIsNotAvailable    = errors.New("this report is not available yet")
+	ErrNotFound          = errors.New("report not found")
+	ErrUnsupportedFormat = errors.New("unsupported report format")
 )
+
+// DefaultFormat is used when a request does not choose a format.
+const DefaultFormat = "csv"
+
+// Row groups report amounts by service and currency.
+type Row struct {
+	ServiceID   int64
+	ServiceName string
+	Currency    currency.Currency
+}
+
+// Content is a report file. Extension is the format of the report.
+type Content struct {
+	Data        []byte
+	ContentType string
+	Extension   string
+}
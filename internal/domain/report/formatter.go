@@ -0,0 +1,119 @@
+package report
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/maypok86/payment-api/internal/domain/currency"
+)
+
+// Entry is the amount earned by a service in a currency. ServiceName is empty in the snapshots of the months
+// closed before the service catalog was added, so it is omitted from JSON to keep their checksums valid.
+type Entry struct {
+	ServiceID   int64             `json:"service_id"`
+	ServiceName string            `json:"service_name,omitempty"`
+	Currency    currency.Currency `json:"currency"`
+	Amount      int64             `json:"amount"`
+}
+
+// Formatter renders a report in a file format. Extension also names the format in requests.
+type Formatter interface {
+	Format(entries []Entry) ([]byte, error)
+	ContentType() string
+	Extension() string
+}
+
+// DefaultFormatters returns the formatters every service supports.
+func DefaultFormatters() []Formatter {
+	return []Formatter{
+		CSVFormatter{},
+		JSONFormatter{},
+		XLSXFormatter{},
+		ParquetFormatter{},
+	}
+}
+
+// newEntries returns the entries of the report map sorted by service and currency.
+func newEntries(reportMap map[Row]int64) []Entry {
+	entries := make([]Entry, 0, len(reportMap))
+	for row, amount := range reportMap {
+		entries = append(entries, Entry{
+			ServiceID:   row.ServiceID,
+			ServiceName: row.ServiceName,
+			Currency:    row.Currency,
+			Amount:      amount,
+		})
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].ServiceID != entries[j].ServiceID {
+			return entries[i].ServiceID < entries[j].ServiceID
+		}
+
+		return entries[i].Currency.String() < entries[j].Currency.String()
+	})
+
+	return entries
+}
+
+var entryHeader = []string{"service_id", "service_name", "currency", "amount"}
+
+type CSVFormatter struct{}
+
+func (CSVFormatter) Format(entries []Entry) ([]byte, error) {
+	var buffer bytes.Buffer
+	writer := csv.NewWriter(&buffer)
+
+	if err := writer.Write(entryHeader); err != nil {
+		return nil, fmt.Errorf("format csv report: %w", err)
+	}
+
+	for _, entry := range entries {
+		if err := writer.Write([]string{
+			strconv.FormatInt(entry.ServiceID, 10),
+			entry.ServiceName,
+			entry.Currency.String(),
+			strconv.FormatInt(entry.Amount, 10),
+		}); err != nil {
+			return nil, fmt.Errorf("format csv report: %w", err)
+		}
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return nil, fmt.Errorf("format csv report: %w", err)
+	}
+
+	return buffer.Bytes(), nil
+}
+
+func (CSVFormatter) ContentType() string {
+	return "text/csv"
+}
+
+func (CSVFormatter) Extension() string {
+	return "csv"
+}
+
+type JSONFormatter struct{}
+
+func (JSONFormatter) Format(entries []Entry) ([]byte, error) {
+	content, err := json.Marshal(entries)
+	if err != nil {
+		return nil, fmt.Errorf("format json report: %w", err)
+	}
+
+	return content, nil
+}
+
+func (JSONFormatter) ContentType() string {
+	return "application/json"
+}
+
+func (JSONFormatter) Extension() string {
+	return "json"
+}
@@ -0,0 +1,92 @@
+package report_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/maypok86/payment-api/internal/domain/currency"
+	"github.com/maypok86/payment-api/internal/domain/report"
+	"github.com/stretchr/testify/require"
+	"github.com/xitongsys/parquet-go-source/buffer"
+	"github.com/xitongsys/parquet-go/reader"
+	"github.com/xuri/excelize/v2"
+)
+
+var fakeEntries = []report.Entry{
+	{ServiceID: 1, ServiceName: "Delivery", Currency: currency.RUB, Amount: 100},
+	{ServiceID: 1, ServiceName: "Delivery", Currency: currency.USD, Amount: 5},
+	{ServiceID: 2, ServiceName: "Storage, cold", Currency: currency.RUB, Amount: 300},
+}
+
+func TestCSVFormatter_Format(t *testing.T) {
+	t.Parallel()
+
+	content, err := report.CSVFormatter{}.Format(fakeEntries)
+	require.NoError(t, err)
+	require.Equal(
+		t,
+		"service_id,service_name,currency,amount\n1,Delivery,RUB,100\n1,Delivery,USD,5\n2,\"Storage, cold\",RUB,300\n",
+		string(content),
+	)
+}
+
+func TestJSONFormatter_Format(t *testing.T) {
+	t.Parallel()
+
+	content, err := report.JSONFormatter{}.Format(fakeEntries)
+	require.NoError(t, err)
+
+	var got []report.Entry
+	require.NoError(t, json.Unmarshal(content, &got))
+	require.Equal(t, fakeEntries, got)
+}
+
+func TestXLSXFormatter_Format(t *testing.T) {
+	t.Parallel()
+
+	content, err := report.XLSXFormatter{}.Format(fakeEntries)
+	require.NoError(t, err)
+
+	file, err := excelize.OpenReader(bytes.NewReader(content))
+	require.NoError(t, err)
+	defer file.Close()
+
+	rows, err := file.GetRows("Report")
+	require.NoError(t, err)
+	require.Equal(t, [][]string{
+		{"service_id", "service_name", "currency", "amount"},
+		{"1", "Delivery", "RUB", "100"},
+		{"1", "Delivery", "USD", "5"},
+		{"2", "Storage, cold", "RUB", "300"},
+	}, rows)
+}
+
+type parquetEntry struct {
+	ServiceID   int64  `parquet:"name=service_id, type=INT64"`
+	ServiceName string `parquet:"name=service_name, type=BYTE_ARRAY, convertedtype=UTF8"`
+	Currency    string `parquet:"name=currency, type=BYTE_ARRAY, convertedtype=UTF8"`
+	Amount      int64  `parquet:"name=amount, type=INT64"`
+}
+
+func TestParquetFormatter_Format(t *testing.T) {
+	t.Parallel()
+
+	content, err := report.ParquetFormatter{}.Format(fakeEntries)
+	require.NoError(t, err)
+
+	file, err := buffer.NewBufferFile(content)
+	require.NoError(t, err)
+
+	parquetReader, err := reader.NewParquetReader(file, new(parquetEntry), 1)
+	require.NoError(t, err)
+	defer parquetReader.ReadStop()
+
+	got := make([]parquetEntry, parquetReader.GetNumRows())
+	require.NoError(t, parquetReader.Read(&got))
+	require.Equal(t, []parquetEntry{
+		{ServiceID: 1, ServiceName: "Delivery", Currency: "RUB", Amount: 100},
+		{ServiceID: 1, ServiceName: "Delivery", Currency: "USD", Amount: 5},
+		{ServiceID: 2, ServiceName: "Storage, cold", Currency: "RUB", Amount: 300},
+	}, got)
+}
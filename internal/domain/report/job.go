@@ -0,0 +1,84 @@
+package report
+
+import (
+	"database/sql/driver"
+	"errors"
+	"time"
+)
+
+var ErrJobNotFound = errors.New("report job not found")
+
+type JobStatus struct {
+	string
+}
+
+var (
+	Queued  = JobStatus{"queued"}
+	Running = JobStatus{"running"}
+	Done    = JobStatus{"done"}
+	Failed  = JobStatus{"failed"}
+)
+
+var jobStatusToString = map[JobStatus]string{
+	Queued:  "queued",
+	Running: "running",
+	Done:    "done",
+	Failed:  "failed",
+}
+
+var stringToJobStatus = map[string]JobStatus{
+	"queued":  Queued,
+	"running": Running,
+	"done":    Done,
+	"failed":  Failed,
+}
+
+func (s JobStatus) String() string {
+	return jobStatusToString[s]
+}
+
+func (s *JobStatus) Scan(value interface{}) error {
+	str, ok := value.(string)
+	if !ok {
+		return errors.New("scan source is not string")
+	}
+
+	if v, ok := stringToJobStatus[str]; ok {
+		*s = v
+		return nil
+	}
+
+	return errors.New("wrong value for JobStatus")
+}
+
+func (s JobStatus) Value() (driver.Value, error) {
+	str, ok := jobStatusToString[s]
+	if !ok {
+		return nil, errors.New("wrong value for JobStatus")
+	}
+
+	return str, nil
+}
+
+// Job generates the report of a month in a format for the principal that created it. Key is set once the job
+// is done, Error once it failed.
+type Job struct {
+	JobID     int64
+	Month     int64
+	Year      int64
+	Format    string
+	Principal string
+	Status    JobStatus
+	Key       string
+	Error     string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+func (j Job) MapDTO() GetMapDTO {
+	return GetMapDTO{
+		Month:  j.Month,
+		Year:   j.Year,
+		Format: j.Format,
+	}
+}
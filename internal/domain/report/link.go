@@ -0,0 +1,86 @@
+package report
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+var (
+	ErrInvalidLink = errors.New("invalid report link")
+	ErrLinkExpired = errors.New("report link expired")
+)
+
+// LinkClaims are signed into the token of a download link.
+type LinkClaims struct {
+	Key       string `json:"key"`
+	Principal string `json:"sub"`
+	ExpiresAt int64  `json:"exp"`
+}
+
+// LinkSigner issues and verifies download link tokens. A token is the base64 encoded JSON of its claims and
+// their HMAC-SHA256 signature, separated by a dot.
+type LinkSigner struct {
+	secret []byte
+	ttl    time.Duration
+}
+
+func NewLinkSigner(secret string, ttl time.Duration) *LinkSigner {
+	return &LinkSigner{
+		secret: []byte(secret),
+		ttl:    ttl,
+	}
+}
+
+// Sign returns a token for the report with the key requested by the principal and the time the token expires at.
+func (s *LinkSigner) Sign(key, principal string) (string, time.Time, error) {
+	expiresAt := time.Now().Add(s.ttl).Truncate(time.Second)
+
+	payload, err := json.Marshal(LinkClaims{
+		Key:       key,
+		Principal: principal,
+		ExpiresAt: expiresAt.Unix(),
+	})
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("sign report link: %w", err)
+	}
+
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+
+	return encoded + "." + s.signature(encoded), expiresAt, nil
+}
+
+func (s *LinkSigner) Verify(token string) (LinkClaims, error) {
+	encoded, signature, ok := strings.Cut(token, ".")
+	if !ok || !hmac.Equal([]byte(signature), []byte(s.signature(encoded))) {
+		return LinkClaims{}, ErrInvalidLink
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return LinkClaims{}, ErrInvalidLink
+	}
+
+	var claims LinkClaims
+	if err := json.Unmarshal(payload, &claims); err != nil || claims.Key == "" {
+		return LinkClaims{}, ErrInvalidLink
+	}
+
+	if !time.Now().Before(time.Unix(claims.ExpiresAt, 0)) {
+		return LinkClaims{}, ErrLinkExpired
+	}
+
+	return claims, nil
+}
+
+func (s *LinkSigner) signature(encoded string) string {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(encoded))
+
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
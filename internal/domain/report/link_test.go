@@ -0,0 +1,91 @@
+package report_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/maypok86/payment-api/internal/domain/report"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLinkSigner(t *testing.T) {
+	t.Parallel()
+
+	signer := report.NewLinkSigner("secret", time.Hour)
+
+	token, expiresAt, err := signer.Sign("2022-11.csv", "billing")
+	require.NoError(t, err)
+	require.WithinDuration(t, time.Now().Add(time.Hour), expiresAt, time.Second)
+
+	claims, err := signer.Verify(token)
+	require.NoError(t, err)
+	require.Equal(t, report.LinkClaims{
+		Key:       "2022-11.csv",
+		Principal: "billing",
+		ExpiresAt: expiresAt.Unix(),
+	}, claims)
+}
+
+func TestLinkSigner_Verify(t *testing.T) {
+	t.Parallel()
+
+	signer := report.NewLinkSigner("secret", time.Hour)
+	token, _, err := signer.Sign("2022-11.csv", "billing")
+	require.NoError(t, err)
+
+	otherToken, _, err := report.NewLinkSigner("other secret", time.Hour).Sign("2022-11.csv", "billing")
+	require.NoError(t, err)
+
+	expiredToken, _, err := report.NewLinkSigner("secret", -time.Minute).Sign("2022-11.csv", "billing")
+	require.NoError(t, err)
+
+	otherKeyToken, _, err := signer.Sign("2022-10.csv", "billing")
+	require.NoError(t, err)
+
+	payload, signature, _ := strings.Cut(token, ".")
+	otherKeyPayload, _, _ := strings.Cut(otherKeyToken, ".")
+
+	tests := []struct {
+		name      string
+		token     string
+		wantedErr error
+	}{
+		{
+			name:      "empty token",
+			token:     "",
+			wantedErr: report.ErrInvalidLink,
+		},
+		{
+			name:      "no signature",
+			token:     payload,
+			wantedErr: report.ErrInvalidLink,
+		},
+		{
+			name:      "signed with another secret",
+			token:     otherToken,
+			wantedErr: report.ErrInvalidLink,
+		},
+		{
+			name:      "changed claims",
+			token:     otherKeyPayload + "." + signature,
+			wantedErr: report.ErrInvalidLink,
+		},
+		{
+			name:      "expired token",
+			token:     expiredToken,
+			wantedErr: report.ErrLinkExpired,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := signer.Verify(tt.token)
+			require.ErrorIs(t, err, tt.wantedErr)
+		})
+	}
+}
@@ -7,6 +7,7 @@ package report_test
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	gomock "github.com/golang/mock/gomock"
 	report "github.com/maypok86/payment-api/internal/domain/report"
@@ -35,11 +36,56 @@ func (m *MockRepository) EXPECT() *MockRepositoryMockRecorder {
 	return m.recorder
 }
 
+// ClaimJobs mocks base method.
+func (m *MockRepository) ClaimJobs(ctx context.Context, limit uint64, timeout time.Duration) ([]report.Job, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimJobs", ctx, limit, timeout)
+	ret0, _ := ret[0].([]report.Job)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClaimJobs indicates an expected call of ClaimJobs.
+func (mr *MockRepositoryMockRecorder) ClaimJobs(ctx, limit, timeout interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimJobs", reflect.TypeOf((*MockRepository)(nil).ClaimJobs), ctx, limit, timeout)
+}
+
+// CreateJob mocks base method.
+func (m *MockRepository) CreateJob(ctx context.Context, dto report.CreateJobDTO) (report.Job, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateJob", ctx, dto)
+	ret0, _ := ret[0].(report.Job)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateJob indicates an expected call of CreateJob.
+func (mr *MockRepositoryMockRecorder) CreateJob(ctx, dto interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateJob", reflect.TypeOf((*MockRepository)(nil).CreateJob), ctx, dto)
+}
+
+// GetJobByID mocks base method.
+func (m *MockRepository) GetJobByID(ctx context.Context, jobID int64) (report.Job, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetJobByID", ctx, jobID)
+	ret0, _ := ret[0].(report.Job)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetJobByID indicates an expected call of GetJobByID.
+func (mr *MockRepositoryMockRecorder) GetJobByID(ctx, jobID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetJobByID", reflect.TypeOf((*MockRepository)(nil).GetJobByID), ctx, jobID)
+}
+
 // GetReportMap mocks base method.
-func (m *MockRepository) GetReportMap(ctx context.Context, dto report.GetMapDTO) (map[int64]int64, error) {
+func (m *MockRepository) GetReportMap(ctx context.Context, dto report.GetMapDTO) (map[report.Row]int64, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "GetReportMap", ctx, dto)
-	ret0, _ := ret[0].(map[int64]int64)
+	ret0, _ := ret[0].(map[report.Row]int64)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
@@ -50,6 +96,64 @@ func (mr *MockRepositoryMockRecorder) GetReportMap(ctx, dto interface{}) *gomock
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetReportMap", reflect.TypeOf((*MockRepository)(nil).GetReportMap), ctx, dto)
 }
 
+// GetSnapshot mocks base method.
+func (m *MockRepository) GetSnapshot(ctx context.Context, month, year int64) (report.Snapshot, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSnapshot", ctx, month, year)
+	ret0, _ := ret[0].(report.Snapshot)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSnapshot indicates an expected call of GetSnapshot.
+func (mr *MockRepositoryMockRecorder) GetSnapshot(ctx, month, year interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSnapshot", reflect.TypeOf((*MockRepository)(nil).GetSnapshot), ctx, month, year)
+}
+
+// HasUnfinishedOrders mocks base method.
+func (m *MockRepository) HasUnfinishedOrders(ctx context.Context, dto report.GetMapDTO) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "HasUnfinishedOrders", ctx, dto)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// HasUnfinishedOrders indicates an expected call of HasUnfinishedOrders.
+func (mr *MockRepositoryMockRecorder) HasUnfinishedOrders(ctx, dto interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HasUnfinishedOrders", reflect.TypeOf((*MockRepository)(nil).HasUnfinishedOrders), ctx, dto)
+}
+
+// SaveJobResult mocks base method.
+func (m *MockRepository) SaveJobResult(ctx context.Context, dto report.SaveJobResultDTO) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveJobResult", ctx, dto)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveJobResult indicates an expected call of SaveJobResult.
+func (mr *MockRepositoryMockRecorder) SaveJobResult(ctx, dto interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveJobResult", reflect.TypeOf((*MockRepository)(nil).SaveJobResult), ctx, dto)
+}
+
+// SaveSnapshot mocks base method.
+func (m *MockRepository) SaveSnapshot(ctx context.Context, snapshot report.Snapshot) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveSnapshot", ctx, snapshot)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveSnapshot indicates an expected call of SaveSnapshot.
+func (mr *MockRepositoryMockRecorder) SaveSnapshot(ctx, snapshot interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveSnapshot", reflect.TypeOf((*MockRepository)(nil).SaveSnapshot), ctx, snapshot)
+}
+
 // MockCache is a mock of Cache interface.
 type MockCache struct {
 	ctrl     *gomock.Controller
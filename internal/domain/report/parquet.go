@@ -0,0 +1,53 @@
+package report
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/xitongsys/parquet-go/parquet"
+	"github.com/xitongsys/parquet-go/writer"
+)
+
+type parquetEntry struct {
+	ServiceID   int64  `parquet:"name=service_id, type=INT64"`
+	ServiceName string `parquet:"name=service_name, type=BYTE_ARRAY, convertedtype=UTF8"`
+	Currency    string `parquet:"name=currency, type=BYTE_ARRAY, convertedtype=UTF8"`
+	Amount      int64  `parquet:"name=amount, type=INT64"`
+}
+
+type ParquetFormatter struct{}
+
+func (ParquetFormatter) Format(entries []Entry) ([]byte, error) {
+	var buffer bytes.Buffer
+
+	parquetWriter, err := writer.NewParquetWriterFromWriter(&buffer, new(parquetEntry), 1)
+	if err != nil {
+		return nil, fmt.Errorf("format parquet report: %w", err)
+	}
+	parquetWriter.CompressionType = parquet.CompressionCodec_SNAPPY
+
+	for _, entry := range entries {
+		if err := parquetWriter.Write(parquetEntry{
+			ServiceID:   entry.ServiceID,
+			ServiceName: entry.ServiceName,
+			Currency:    entry.Currency.String(),
+			Amount:      entry.Amount,
+		}); err != nil {
+			return nil, fmt.Errorf("format parquet report: %w", err)
+		}
+	}
+
+	if err := parquetWriter.WriteStop(); err != nil {
+		return nil, fmt.Errorf("format parquet report: %w", err)
+	}
+
+	return buffer.Bytes(), nil
+}
+
+func (ParquetFormatter) ContentType() string {
+	return "application/vnd.apache.parquet"
+}
+
+func (ParquetFormatter) Extension() string {
+	return "parquet"
+}
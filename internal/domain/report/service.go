@@ -1,11 +1,14 @@
 package report
 
 import (
-	"bytes"
 	"context"
-	"encoding/csv"
+	"errors"
 	"fmt"
+	"mime"
+	"path"
 	"sort"
+	"strconv"
+	"strings"
 	"time"
 
 	"go.uber.org/zap"
@@ -13,8 +16,21 @@ import (
 
 //go:generate mockgen -source=service.go -destination=mock_test.go -package=report_test
 
+// asOfLayout formats the time the report of the current month was computed at in its key.
+const asOfLayout = "20060102T150405Z"
+
+// errNotFinal means the month is over, but its orders can still be paid, so it has no snapshot yet.
+var errNotFinal = errors.New("report month is not final")
+
 type Repository interface {
-	GetReportMap(ctx context.Context, dto GetMapDTO) (map[int64]int64, error)
+	GetReportMap(ctx context.Context, dto GetMapDTO) (map[Row]int64, error)
+	HasUnfinishedOrders(ctx context.Context, dto GetMapDTO) (bool, error)
+	CreateJob(ctx context.Context, dto CreateJobDTO) (Job, error)
+	GetJobByID(ctx context.Context, jobID int64) (Job, error)
+	ClaimJobs(ctx context.Context, limit uint64, timeout time.Duration) ([]Job, error)
+	SaveJobResult(ctx context.Context, dto SaveJobResultDTO) error
+	GetSnapshot(ctx context.Context, month, year int64) (Snapshot, error)
+	SaveSnapshot(ctx context.Context, snapshot Snapshot) error
 }
 
 type Cache interface {
@@ -24,84 +40,382 @@ type Cache interface {
 }
 
 type Service struct {
-	repository Repository
-	cache      Cache
-	logger     *zap.Logger
+	repository    Repository
+	cache         Cache
+	formatters    map[string]Formatter
+	jobTimeout    time.Duration
+	snapshotGrace time.Duration
+	logger        *zap.Logger
 }
 
-func NewService(repository Repository, cache Cache, logger *zap.Logger) *Service {
-	return &Service{
-		repository: repository,
-		cache:      cache,
-		logger:     logger,
+// NewService supports the default formatters and the given ones, which replace defaults with the same extension.
+// A job running longer than jobTimeout is considered abandoned and is run again. The snapshot of a month is taken
+// once none of its orders can be paid anymore, or once snapshotGrace has passed since the end of the month.
+func NewService(
+	repository Repository,
+	cache Cache,
+	jobTimeout time.Duration,
+	snapshotGrace time.Duration,
+	logger *zap.Logger,
+	formatters ...Formatter,
+) *Service {
+	service := &Service{
+		repository:    repository,
+		cache:         cache,
+		formatters:    make(map[string]Formatter),
+		jobTimeout:    jobTimeout,
+		snapshotGrace: snapshotGrace,
+		logger:        logger,
 	}
+
+	for _, formatter := range append(DefaultFormatters(), formatters...) {
+		service.formatters[formatter.Extension()] = formatter
+	}
+
+	return service
 }
 
-func reportMapToCSV(reportMap map[int64]int64) ([]byte, error) {
-	var buffer bytes.Buffer
-	writer := csv.NewWriter(&buffer)
+func (s *Service) formatter(format string) (Formatter, error) {
+	if format == "" {
+		format = DefaultFormat
+	}
 
-	ids := make([]int64, 0, len(reportMap))
-	for id := range reportMap {
-		ids = append(ids, id)
+	formatter, ok := s.formatters[format]
+	if !ok {
+		return nil, ErrUnsupportedFormat
 	}
-	sort.Slice(ids, func(i, j int) bool {
-		return ids[i] < ids[j]
+
+	return formatter, nil
+}
+
+// NegotiateFormat returns the format of the most preferred media type in the Accept header which is supported.
+// An empty header or a wildcard means the default format.
+func (s *Service) NegotiateFormat(accept string) (string, error) {
+	if strings.TrimSpace(accept) == "" {
+		return DefaultFormat, nil
+	}
+
+	type mediaRange struct {
+		mediaType string
+		quality   float64
+	}
+
+	ranges := make([]mediaRange, 0)
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(part)
+		if err != nil {
+			continue
+		}
+
+		quality := 1.0
+		if q, ok := params["q"]; ok {
+			if quality, err = strconv.ParseFloat(q, 64); err != nil {
+				continue
+			}
+		}
+		if quality <= 0 {
+			continue
+		}
+
+		ranges = append(ranges, mediaRange{mediaType: mediaType, quality: quality})
+	}
+
+	sort.SliceStable(ranges, func(i, j int) bool {
+		return ranges[i].quality > ranges[j].quality
 	})
 
-	if err := writer.Write([]string{"service_id", "amount"}); err != nil {
-		return nil, fmt.Errorf("report map to csv: %w", err)
+	for _, r := range ranges {
+		if r.mediaType == "*/*" {
+			return DefaultFormat, nil
+		}
+
+		for format, formatter := range s.formatters {
+			if formatter.ContentType() == r.mediaType {
+				return format, nil
+			}
+		}
+	}
+
+	return "", ErrUnsupportedFormat
+}
+
+// Accepts reports whether the Accept header allows a report of the content type. An empty header accepts any
+// report, and so do the */* and type/* ranges of a matching type. Ranges with zero quality are refused.
+func Accepts(accept, contentType string) bool {
+	if strings.TrimSpace(accept) == "" {
+		return true
 	}
-	for _, serviceID := range ids {
-		if err := writer.Write([]string{fmt.Sprintf("%d", serviceID), fmt.Sprintf("%d", reportMap[serviceID])}); err != nil {
-			return nil, fmt.Errorf("report map to csv: %w", err)
+
+	mainType, _, _ := strings.Cut(contentType, "/")
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(part)
+		if err != nil {
+			continue
+		}
+
+		if q, ok := params["q"]; ok {
+			quality, err := strconv.ParseFloat(q, 64)
+			if err != nil || quality <= 0 {
+				continue
+			}
+		}
+
+		if mediaType == "*/*" || mediaType == contentType || mediaType == mainType+"/*" {
+			return true
 		}
 	}
-	writer.Flush()
 
-	if err := writer.Error(); err != nil {
-		return nil, fmt.Errorf("report map to csv: %w", err)
+	return false
+}
+
+func checkAvailability(dto GetMapDTO, now time.Time) error {
+	if from, _ := dto.Period(); now.Before(from) {
+		return ErrIsNotAvailable
+	}
+
+	return nil
+}
+
+// isClosed reports whether the month is over, so no more orders can be created in it.
+func isClosed(dto GetMapDTO, now time.Time) bool {
+	_, to := dto.Period()
+
+	return !now.Before(to)
+}
+
+// isFinal reports whether the report of a closed month cannot change anymore. Orders created in the month
+// can be paid until their reservation expires, so the month is final once none of them is unfinished,
+// or once the grace period after the end of the month has passed.
+func (s *Service) isFinal(ctx context.Context, dto GetMapDTO, now time.Time) (bool, error) {
+	if _, to := dto.Period(); !now.Before(to.Add(s.snapshotGrace)) {
+		return true, nil
 	}
 
-	return buffer.Bytes(), nil
+	hasUnfinished, err := s.repository.HasUnfinishedOrders(ctx, dto)
+	if err != nil {
+		return false, err
+	}
+
+	return !hasUnfinished, nil
 }
 
+// GetReportKey returns the same key for every report of a final month in a format, the report is made from
+// the snapshot of the month. The report of the current month and of a closed month that is not final yet is
+// recomputed on every call and its key has the time it was computed at, for example
+// 2022-11-as-of-20221117T120000Z.csv.
 func (s *Service) GetReportKey(ctx context.Context, dto GetMapDTO) (string, error) {
-	year, month, _ := time.Now().Date()
-	if dto.Year > int64(year) || (dto.Year == int64(year) && dto.Month > int64(month)) {
-		return "", fmt.Errorf("get report key: %w", ErrIsNotAvailable)
+	now := time.Now().UTC()
+	if err := checkAvailability(dto, now); err != nil {
+		return "", fmt.Errorf("get report key: %w", err)
+	}
+
+	formatter, err := s.formatter(dto.Format)
+	if err != nil {
+		return "", fmt.Errorf("get report key: %w", err)
 	}
 
-	key := fmt.Sprintf("%d-%d", dto.Year, dto.Month)
+	if !isClosed(dto, now) {
+		return s.getOpenReportKey(ctx, dto, formatter, now)
+	}
+
+	key := fmt.Sprintf("%d-%d.%s", dto.Year, dto.Month, formatter.Extension())
 	if s.cache.IsExist(key) {
 		return key, nil
 	}
 
-	reportMap, err := s.repository.GetReportMap(ctx, dto)
+	snapshot, err := s.getSnapshot(ctx, dto, now)
+	if errors.Is(err, errNotFinal) {
+		return s.getOpenReportKey(ctx, dto, formatter, now)
+	}
 	if err != nil {
 		return "", fmt.Errorf("get report key: %w", err)
 	}
-	if len(reportMap) == 0 {
-		return "", ErrNotFound
+
+	if err := s.saveReport(key, formatter, snapshot.Entries); err != nil {
+		return "", err
 	}
 
-	reportContent, err := reportMapToCSV(reportMap)
+	return key, nil
+}
+
+func (s *Service) getOpenReportKey(
+	ctx context.Context,
+	dto GetMapDTO,
+	formatter Formatter,
+	asOf time.Time,
+) (string, error) {
+	reportMap, err := s.repository.GetReportMap(ctx, dto)
 	if err != nil {
 		return "", fmt.Errorf("get report key: %w", err)
 	}
 
-	if err := s.cache.Set(key, reportContent); err != nil {
-		return "", fmt.Errorf("set report data: %w", err)
+	key := fmt.Sprintf("%d-%d-as-of-%s.%s", dto.Year, dto.Month, asOf.Format(asOfLayout), formatter.Extension())
+	if err := s.saveReport(key, formatter, newEntries(reportMap)); err != nil {
+		return "", err
 	}
 
 	return key, nil
 }
 
-func (s *Service) GetReportContent(ctx context.Context, key string) ([]byte, error) {
+func (s *Service) saveReport(key string, formatter Formatter, entries []Entry) error {
+	if len(entries) == 0 {
+		return ErrNotFound
+	}
+
+	reportContent, err := formatter.Format(entries)
+	if err != nil {
+		return fmt.Errorf("format report: %w", err)
+	}
+
+	if err := s.cache.Set(key, reportContent); err != nil {
+		return fmt.Errorf("set report data: %w", err)
+	}
+
+	return nil
+}
+
+// getSnapshot computes the snapshot of a closed month the first time it is needed once the month is final,
+// before that it returns errNotFinal. When several replicas compute it at once, the one saved first is used
+// by all of them.
+func (s *Service) getSnapshot(ctx context.Context, dto GetMapDTO, now time.Time) (Snapshot, error) {
+	snapshot, err := s.repository.GetSnapshot(ctx, dto.Month, dto.Year)
+	if err == nil {
+		return snapshot, snapshot.Verify()
+	}
+	if !errors.Is(err, ErrSnapshotNotFound) {
+		return Snapshot{}, err
+	}
+
+	final, err := s.isFinal(ctx, dto, now)
+	if err != nil {
+		return Snapshot{}, err
+	}
+	if !final {
+		return Snapshot{}, errNotFinal
+	}
+
+	reportMap, err := s.repository.GetReportMap(ctx, dto)
+	if err != nil {
+		return Snapshot{}, err
+	}
+
+	snapshot, err = NewSnapshot(dto.Month, dto.Year, newEntries(reportMap), now)
+	if err != nil {
+		return Snapshot{}, err
+	}
+
+	if err := s.repository.SaveSnapshot(ctx, snapshot); err != nil {
+		return Snapshot{}, err
+	}
+
+	snapshot, err = s.repository.GetSnapshot(ctx, dto.Month, dto.Year)
+	if err != nil {
+		return Snapshot{}, err
+	}
+
+	s.logger.Info(
+		"report snapshot",
+		zap.Int64("year", snapshot.Year),
+		zap.Int64("month", snapshot.Month),
+		zap.String("checksum", snapshot.Checksum),
+	)
+
+	return snapshot, snapshot.Verify()
+}
+
+// GetReportContent takes the format of the report from the extension of its key.
+func (s *Service) GetReportContent(ctx context.Context, key string) (Content, error) {
+	formatter, ok := s.formatters[strings.TrimPrefix(path.Ext(key), ".")]
+	if !ok {
+		return Content{}, fmt.Errorf("get report content: %w", ErrNotFound)
+	}
+
 	reportContent, err := s.cache.Get(key)
 	if err != nil {
-		return nil, fmt.Errorf("get report content: %w", err)
+		return Content{}, fmt.Errorf("get report content: %w", err)
+	}
+
+	return Content{
+		Data:        reportContent,
+		ContentType: formatter.ContentType(),
+		Extension:   formatter.Extension(),
+	}, nil
+}
+
+// CreateJob checks the report can be generated and queues its generation.
+func (s *Service) CreateJob(ctx context.Context, dto CreateJobDTO) (Job, error) {
+	if err := checkAvailability(dto.GetMapDTO, time.Now().UTC()); err != nil {
+		return Job{}, fmt.Errorf("create job: %w", err)
+	}
+
+	formatter, err := s.formatter(dto.Format)
+	if err != nil {
+		return Job{}, fmt.Errorf("create job: %w", err)
+	}
+	dto.Format = formatter.Extension()
+
+	job, err := s.repository.CreateJob(ctx, dto)
+	if err != nil {
+		return Job{}, fmt.Errorf("create job: %w", err)
+	}
+
+	return job, nil
+}
+
+// GetJob returns the job only to the principal that created it, other principals get ErrJobNotFound, so they
+// can neither get its download link nor learn that it exists.
+func (s *Service) GetJob(ctx context.Context, jobID int64, principal string) (Job, error) {
+	job, err := s.repository.GetJobByID(ctx, jobID)
+	if err != nil {
+		return Job{}, fmt.Errorf("get job: %w", err)
+	}
+
+	if job.Principal != principal {
+		return Job{}, fmt.Errorf("get job: %w", ErrJobNotFound)
+	}
+
+	return job, nil
+}
+
+// RunJobs claims up to limit queued or abandoned jobs, runs them and returns how many of them were claimed.
+// Jobs claimed by another worker are skipped, so the method is safe to run concurrently.
+func (s *Service) RunJobs(ctx context.Context, limit uint64) (int, error) {
+	jobs, err := s.repository.ClaimJobs(ctx, limit, s.jobTimeout)
+	if err != nil {
+		return 0, fmt.Errorf("run jobs: %w", err)
+	}
+
+	for _, job := range jobs {
+		result := s.runJob(ctx, job)
+
+		// a job interrupted by shutdown stays running and is run again after the timeout.
+		if err := ctx.Err(); err != nil {
+			return 0, fmt.Errorf("run jobs: %w", err)
+		}
+
+		if err := s.repository.SaveJobResult(ctx, result); err != nil {
+			return 0, fmt.Errorf("run jobs: %w", err)
+		}
+	}
+
+	return len(jobs), nil
+}
+
+func (s *Service) runJob(ctx context.Context, job Job) SaveJobResultDTO {
+	key, err := s.GetReportKey(ctx, job.MapDTO())
+	if err != nil {
+		s.logger.Warn("run report job", zap.Int64("job_id", job.JobID), zap.Error(err))
+
+		return SaveJobResultDTO{
+			JobID:  job.JobID,
+			Status: Failed,
+			Error:  err.Error(),
+		}
 	}
 
-	return reportContent, nil
+	return SaveJobResultDTO{
+		JobID:  job.JobID,
+		Status: Done,
+		Key:    key,
+	}
 }
@@ -6,15 +6,24 @@ import (
 	"fmt"
 	"os"
 	"reflect"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/bxcodec/faker/v3"
 	"github.com/golang/mock/gomock"
+	"github.com/maypok86/payment-api/internal/domain/currency"
 	"github.com/maypok86/payment-api/internal/domain/report"
 	"github.com/maypok86/payment-api/internal/pkg/logger"
 	"github.com/stretchr/testify/require"
 )
 
+const (
+	jobTimeout = 10 * time.Minute
+	// snapshotGrace is longer than a month, so the previous month is always in its grace period.
+	snapshotGrace = 40 * 24 * time.Hour
+)
+
 func mockService(t *testing.T) (*report.Service, *MockRepository, *MockCache) {
 	t.Helper()
 
@@ -25,23 +34,53 @@ func mockService(t *testing.T) (*report.Service, *MockRepository, *MockCache) {
 
 	repository := NewMockRepository(mockCtrl)
 	cache := NewMockCache(mockCtrl)
-	service := report.NewService(repository, cache, l)
+	service := report.NewService(repository, cache, jobTimeout, snapshotGrace, l)
 
 	return service, repository, cache
 }
 
-func newReportMap(t *testing.T, count int) map[int64]int64 {
+func newRow(t *testing.T, serviceID int) report.Row {
 	t.Helper()
 
-	reportMap := make(map[int64]int64, count)
+	return report.Row{
+		ServiceID:   int64(serviceID),
+		ServiceName: fmt.Sprintf("service %d", serviceID),
+		Currency:    currency.RUB,
+	}
+}
+
+func newReportMap(t *testing.T, count int) map[report.Row]int64 {
+	t.Helper()
+
+	reportMap := make(map[report.Row]int64, count)
 
 	for i := 0; i < count; i++ {
-		reportMap[int64(i)] = int64(i)
+		reportMap[newRow(t, i)] = int64(i)
 	}
 
 	return reportMap
 }
 
+func newSnapshot(t *testing.T, dto report.GetMapDTO, reportMap map[report.Row]int64) report.Snapshot {
+	t.Helper()
+
+	entries := make([]report.Entry, 0, len(reportMap))
+	for i := 0; i < len(reportMap); i++ {
+		row := newRow(t, i)
+		entries = append(entries, report.Entry{
+			ServiceID:   row.ServiceID,
+			ServiceName: row.ServiceName,
+			Currency:    row.Currency,
+			Amount:      reportMap[row],
+		})
+	}
+
+	snapshot, err := report.NewSnapshot(dto.Month, dto.Year, entries, time.Date(2021, 10, 1, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+
+	return snapshot
+}
+
 func TestService_GetReportKey(t *testing.T) {
 	t.Parallel()
 
@@ -51,8 +90,16 @@ func TestService_GetReportKey(t *testing.T) {
 		Year:  2021,
 		Month: 9,
 	}
-	fakeKey := fmt.Sprintf("%d-%d", dto.Year, dto.Month)
+	fakeKey := fmt.Sprintf("%d-%d.csv", dto.Year, dto.Month)
+	fakeCSV := "service_id,service_name,currency,amount\n0,service 0,RUB,0\n1,service 1,RUB,1\n2,service 2,RUB,2\n"
+	jsonDTO := dto
+	jsonDTO.Format = "json"
+	jsonKey := fmt.Sprintf("%d-%d.json", dto.Year, dto.Month)
 	fakeReportMap := newReportMap(t, 10)
+	fakeSnapshot := newSnapshot(t, dto, newReportMap(t, 3))
+	emptySnapshot := newSnapshot(t, dto, nil)
+	corruptedSnapshot := newSnapshot(t, dto, newReportMap(t, 3))
+	corruptedSnapshot.Entries[0].Amount++
 	repositoryErr := errors.New("repository error")
 	cacheErr := errors.New("cache error")
 
@@ -70,10 +117,39 @@ func TestService_GetReportKey(t *testing.T) {
 		wantedErr error
 	}{
 		{
-			name: "success get report key",
+			name: "success get report key from snapshot",
+			mock: func(repository *MockRepository, cache *MockCache) {
+				cache.EXPECT().IsExist(fakeKey).Return(false)
+				repository.EXPECT().GetSnapshot(ctx, dto.Month, dto.Year).Return(fakeSnapshot, nil)
+				cache.EXPECT().Set(fakeKey, []byte(fakeCSV)).Return(nil)
+			},
+			args: args{
+				dto: dto,
+			},
+			want:      fakeKey,
+			wantedErr: nil,
+		},
+		{
+			name: "success get report key with new snapshot",
 			mock: func(repository *MockRepository, cache *MockCache) {
+				var saved report.Snapshot
+
 				cache.EXPECT().IsExist(fakeKey).Return(false)
+				repository.EXPECT().GetSnapshot(ctx, dto.Month, dto.Year).Return(report.Snapshot{}, report.ErrSnapshotNotFound)
 				repository.EXPECT().GetReportMap(ctx, dto).Return(fakeReportMap, nil)
+				repository.EXPECT().SaveSnapshot(ctx, gomock.Any()).DoAndReturn(
+					func(_ context.Context, snapshot report.Snapshot) error {
+						require.NoError(t, snapshot.Verify())
+						require.Len(t, snapshot.Entries, len(fakeReportMap))
+						saved = snapshot
+						return nil
+					},
+				)
+				repository.EXPECT().GetSnapshot(ctx, dto.Month, dto.Year).DoAndReturn(
+					func(context.Context, int64, int64) (report.Snapshot, error) {
+						return saved, nil
+					},
+				)
 				cache.EXPECT().Set(fakeKey, gomock.Any()).Return(nil)
 			},
 			args: args{
@@ -82,13 +158,56 @@ func TestService_GetReportKey(t *testing.T) {
 			want:      fakeKey,
 			wantedErr: nil,
 		},
+		{
+			name: "snapshot saved by another replica",
+			mock: func(repository *MockRepository, cache *MockCache) {
+				cache.EXPECT().IsExist(fakeKey).Return(false)
+				repository.EXPECT().GetSnapshot(ctx, dto.Month, dto.Year).Return(report.Snapshot{}, report.ErrSnapshotNotFound)
+				repository.EXPECT().GetReportMap(ctx, dto).Return(fakeReportMap, nil)
+				repository.EXPECT().SaveSnapshot(ctx, gomock.Any()).Return(nil)
+				repository.EXPECT().GetSnapshot(ctx, dto.Month, dto.Year).Return(fakeSnapshot, nil)
+				cache.EXPECT().Set(fakeKey, []byte(fakeCSV)).Return(nil)
+			},
+			args: args{
+				dto: dto,
+			},
+			want:      fakeKey,
+			wantedErr: nil,
+		},
+		{
+			name: "success get json report key",
+			mock: func(repository *MockRepository, cache *MockCache) {
+				cache.EXPECT().IsExist(jsonKey).Return(false)
+				repository.EXPECT().GetSnapshot(ctx, dto.Month, dto.Year).Return(fakeSnapshot, nil)
+				cache.EXPECT().Set(jsonKey, gomock.Any()).Return(nil)
+			},
+			args: args{
+				dto: jsonDTO,
+			},
+			want:      jsonKey,
+			wantedErr: nil,
+		},
+		{
+			name: "unsupported format",
+			mock: func(repository *MockRepository, cache *MockCache) {
+			},
+			args: args{
+				dto: report.GetMapDTO{
+					Year:   2021,
+					Month:  9,
+					Format: "txt",
+				},
+			},
+			want:      "",
+			wantedErr: report.ErrUnsupportedFormat,
+		},
 		{
 			name: "report is not available",
 			mock: func(repository *MockRepository, cache *MockCache) {
 			},
 			args: args{
 				dto: report.GetMapDTO{
-					Year:  2025,
+					Year:  int64(time.Now().Year() + 1),
 					Month: 9,
 				},
 			},
@@ -106,10 +225,35 @@ func TestService_GetReportKey(t *testing.T) {
 			want:      fakeKey,
 			wantedErr: nil,
 		},
+		{
+			name: "corrupted snapshot",
+			mock: func(repository *MockRepository, cache *MockCache) {
+				cache.EXPECT().IsExist(fakeKey).Return(false)
+				repository.EXPECT().GetSnapshot(ctx, dto.Month, dto.Year).Return(corruptedSnapshot, nil)
+			},
+			args: args{
+				dto: dto,
+			},
+			want:      "",
+			wantedErr: report.ErrSnapshotCorrupted,
+		},
+		{
+			name: "get snapshot error",
+			mock: func(repository *MockRepository, cache *MockCache) {
+				cache.EXPECT().IsExist(fakeKey).Return(false)
+				repository.EXPECT().GetSnapshot(ctx, dto.Month, dto.Year).Return(report.Snapshot{}, repositoryErr)
+			},
+			args: args{
+				dto: dto,
+			},
+			want:      "",
+			wantedErr: repositoryErr,
+		},
 		{
 			name: "repository error",
 			mock: func(repository *MockRepository, cache *MockCache) {
 				cache.EXPECT().IsExist(fakeKey).Return(false)
+				repository.EXPECT().GetSnapshot(ctx, dto.Month, dto.Year).Return(report.Snapshot{}, report.ErrSnapshotNotFound)
 				repository.EXPECT().GetReportMap(ctx, dto).Return(nil, repositoryErr)
 			},
 			args: args{
@@ -118,11 +262,25 @@ func TestService_GetReportKey(t *testing.T) {
 			want:      "",
 			wantedErr: repositoryErr,
 		},
+		{
+			name: "save snapshot error",
+			mock: func(repository *MockRepository, cache *MockCache) {
+				cache.EXPECT().IsExist(fakeKey).Return(false)
+				repository.EXPECT().GetSnapshot(ctx, dto.Month, dto.Year).Return(report.Snapshot{}, report.ErrSnapshotNotFound)
+				repository.EXPECT().GetReportMap(ctx, dto).Return(fakeReportMap, nil)
+				repository.EXPECT().SaveSnapshot(ctx, gomock.Any()).Return(repositoryErr)
+			},
+			args: args{
+				dto: dto,
+			},
+			want:      "",
+			wantedErr: repositoryErr,
+		},
 		{
 			name: "empty report",
 			mock: func(repository *MockRepository, cache *MockCache) {
 				cache.EXPECT().IsExist(fakeKey).Return(false)
-				repository.EXPECT().GetReportMap(ctx, dto).Return(nil, nil)
+				repository.EXPECT().GetSnapshot(ctx, dto.Month, dto.Year).Return(emptySnapshot, nil)
 			},
 			args: args{
 				dto: dto,
@@ -134,7 +292,7 @@ func TestService_GetReportKey(t *testing.T) {
 			name: "cache set error",
 			mock: func(repository *MockRepository, cache *MockCache) {
 				cache.EXPECT().IsExist(fakeKey).Return(false)
-				repository.EXPECT().GetReportMap(ctx, dto).Return(fakeReportMap, nil)
+				repository.EXPECT().GetSnapshot(ctx, dto.Month, dto.Year).Return(fakeSnapshot, nil)
 				cache.EXPECT().Set(fakeKey, gomock.Any()).Return(cacheErr)
 			},
 			args: args{
@@ -164,47 +322,176 @@ func TestService_GetReportKey(t *testing.T) {
 	}
 }
 
+func TestService_GetReportKeyOfCurrentMonth(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	year, month, _ := time.Now().UTC().Date()
+	dto := report.GetMapDTO{
+		Year:  int64(year),
+		Month: int64(month),
+	}
+
+	service, repository, cache := mockService(t)
+
+	// the report of the current month is recomputed without the cache and the snapshot.
+	repository.EXPECT().GetReportMap(ctx, dto).Return(newReportMap(t, 2), nil).Times(2)
+	cache.EXPECT().Set(gomock.Any(), gomock.Any()).Return(nil).Times(2)
+
+	before := time.Now().UTC().Truncate(time.Second)
+	got, err := service.GetReportKey(ctx, dto)
+	require.NoError(t, err)
+
+	prefix := fmt.Sprintf("%d-%d-as-of-", year, month)
+	require.True(t, strings.HasPrefix(got, prefix), got)
+	require.True(t, strings.HasSuffix(got, ".csv"), got)
+
+	asOf, err := time.Parse("20060102T150405Z", strings.TrimSuffix(strings.TrimPrefix(got, prefix), ".csv"))
+	require.NoError(t, err)
+	require.False(t, asOf.Before(before))
+
+	_, err = service.GetReportKey(ctx, dto)
+	require.NoError(t, err)
+}
+
+func TestService_GetReportKeyOfPreviousMonth(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	now := time.Now().UTC()
+	year, month, _ := now.AddDate(0, 0, -now.Day()).Date()
+	dto := report.GetMapDTO{
+		Year:  int64(year),
+		Month: int64(month),
+	}
+	snapshotKey := fmt.Sprintf("%d-%d.csv", year, month)
+	fakeReportMap := newReportMap(t, 2)
+
+	t.Run("month with unfinished orders is recomputed", func(t *testing.T) {
+		t.Parallel()
+
+		service, repository, cache := mockService(t)
+
+		cache.EXPECT().IsExist(snapshotKey).Return(false)
+		repository.EXPECT().GetSnapshot(ctx, dto.Month, dto.Year).Return(report.Snapshot{}, report.ErrSnapshotNotFound)
+		repository.EXPECT().HasUnfinishedOrders(ctx, dto).Return(true, nil)
+		repository.EXPECT().GetReportMap(ctx, dto).Return(fakeReportMap, nil)
+		cache.EXPECT().Set(gomock.Any(), gomock.Any()).Return(nil)
+
+		got, err := service.GetReportKey(ctx, dto)
+		require.NoError(t, err)
+		require.True(t, strings.HasPrefix(got, fmt.Sprintf("%d-%d-as-of-", year, month)), got)
+	})
+
+	t.Run("month without unfinished orders is final", func(t *testing.T) {
+		t.Parallel()
+
+		service, repository, cache := mockService(t)
+
+		cache.EXPECT().IsExist(snapshotKey).Return(false)
+		repository.EXPECT().GetSnapshot(ctx, dto.Month, dto.Year).Return(report.Snapshot{}, report.ErrSnapshotNotFound)
+		repository.EXPECT().HasUnfinishedOrders(ctx, dto).Return(false, nil)
+		repository.EXPECT().GetReportMap(ctx, dto).Return(fakeReportMap, nil)
+		repository.EXPECT().SaveSnapshot(ctx, gomock.Any()).Return(nil)
+		repository.EXPECT().GetSnapshot(ctx, dto.Month, dto.Year).Return(newSnapshot(t, dto, fakeReportMap), nil)
+		cache.EXPECT().Set(snapshotKey, gomock.Any()).Return(nil)
+
+		got, err := service.GetReportKey(ctx, dto)
+		require.NoError(t, err)
+		require.Equal(t, snapshotKey, got)
+	})
+
+	t.Run("unfinished orders error", func(t *testing.T) {
+		t.Parallel()
+
+		service, repository, cache := mockService(t)
+		repositoryErr := errors.New("repository error")
+
+		cache.EXPECT().IsExist(snapshotKey).Return(false)
+		repository.EXPECT().GetSnapshot(ctx, dto.Month, dto.Year).Return(report.Snapshot{}, report.ErrSnapshotNotFound)
+		repository.EXPECT().HasUnfinishedOrders(ctx, dto).Return(false, repositoryErr)
+
+		_, err := service.GetReportKey(ctx, dto)
+		require.ErrorIs(t, err, repositoryErr)
+	})
+}
+
+func TestGetMapDTO_Period(t *testing.T) {
+	t.Parallel()
+
+	from, to := report.GetMapDTO{Year: 2022, Month: 12}.Period()
+	require.Equal(t, time.Date(2022, 12, 1, 0, 0, 0, 0, time.UTC), from)
+	require.Equal(t, time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC), to)
+}
+
 func TestService_GetReportContent(t *testing.T) {
 	t.Parallel()
 
 	ctx := context.Background()
 
-	fakeKey := faker.Word()
+	fakeKey := faker.Word() + ".xlsx"
 	data := []byte(faker.Sentence())
 
 	type args struct {
 		key string
 	}
 
-	type mockBehavior func(cache *MockCache)
+	type mockBehavior func(repository *MockRepository, cache *MockCache)
 
 	tests := []struct {
 		name    string
 		mock    mockBehavior
 		args    args
-		want    []byte
+		want    report.Content
 		wantErr bool
 	}{
 		{
 			name: "success get report content",
-			mock: func(cache *MockCache) {
+			mock: func(repository *MockRepository, cache *MockCache) {
 				cache.EXPECT().Get(fakeKey).Return(data, nil)
 			},
 			args: args{
 				key: fakeKey,
 			},
-			want:    data,
+			want: report.Content{
+				Data:        data,
+				ContentType: report.XLSXFormatter{}.ContentType(),
+				Extension:   report.XLSXFormatter{}.Extension(),
+			},
 			wantErr: false,
 		},
+		{
+			name: "unknown extension",
+			mock: func(repository *MockRepository, cache *MockCache) {
+			},
+			args: args{
+				key: faker.Word(),
+			},
+			want:    report.Content{},
+			wantErr: true,
+		},
 		{
 			name: "cache error",
-			mock: func(cache *MockCache) {
+			mock: func(repository *MockRepository, cache *MockCache) {
 				cache.EXPECT().Get(fakeKey).Return(nil, errors.New("cache error"))
 			},
 			args: args{
 				key: fakeKey,
 			},
-			want:    nil,
+			want:    report.Content{},
+			wantErr: true,
+		},
+		{
+			name: "expired report",
+			mock: func(repository *MockRepository, cache *MockCache) {
+				cache.EXPECT().Get(fakeKey).Return(nil, report.ErrNotFound)
+			},
+			args: args{
+				key: fakeKey,
+			},
+			want:    report.Content{},
 			wantErr: true,
 		},
 	}
@@ -215,9 +502,9 @@ func TestService_GetReportContent(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			t.Parallel()
 
-			service, _, cache := mockService(t)
+			service, repository, cache := mockService(t)
 
-			tt.mock(cache)
+			tt.mock(repository, cache)
 
 			got, err := service.GetReportContent(ctx, tt.args.key)
 			require.True(t, (err != nil) == tt.wantErr)
@@ -225,3 +512,390 @@ func TestService_GetReportContent(t *testing.T) {
 		})
 	}
 }
+
+func TestService_NegotiateFormat(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		accept    string
+		want      string
+		wantedErr error
+	}{
+		{
+			name:   "empty header",
+			accept: "",
+			want:   report.DefaultFormat,
+		},
+		{
+			name:   "wildcard",
+			accept: "*/*",
+			want:   report.DefaultFormat,
+		},
+		{
+			name:   "media type with params",
+			accept: "application/json; charset=utf-8",
+			want:   "json",
+		},
+		{
+			name:   "first supported media type",
+			accept: "text/html, application/vnd.apache.parquet, application/json",
+			want:   "parquet",
+		},
+		{
+			name:   "quality order",
+			accept: "application/json;q=0.5, application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+			want:   "xlsx",
+		},
+		{
+			name:   "wildcard with lower quality",
+			accept: "*/*;q=0.1, application/json",
+			want:   "json",
+		},
+		{
+			name:      "not acceptable media type",
+			accept:    "text/html, application/json;q=0",
+			wantedErr: report.ErrUnsupportedFormat,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			service, _, _ := mockService(t)
+
+			got, err := service.NegotiateFormat(tt.accept)
+			if tt.wantedErr != nil {
+				require.ErrorIs(t, err, tt.wantedErr)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestAccepts(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		accept      string
+		contentType string
+		want        bool
+	}{
+		{
+			name:        "empty header",
+			accept:      "",
+			contentType: "text/csv",
+			want:        true,
+		},
+		{
+			name:        "wildcard",
+			accept:      "*/*",
+			contentType: "application/vnd.apache.parquet",
+			want:        true,
+		},
+		{
+			name:        "media type with params",
+			accept:      "application/json; charset=utf-8",
+			contentType: "application/json",
+			want:        true,
+		},
+		{
+			name:        "one of media types",
+			accept:      "text/html, application/vnd.apache.parquet, application/json",
+			contentType: "application/vnd.apache.parquet",
+			want:        true,
+		},
+		{
+			name:        "type wildcard",
+			accept:      "text/*",
+			contentType: "text/csv",
+			want:        true,
+		},
+		{
+			name:        "another media type",
+			accept:      "application/json",
+			contentType: "text/csv",
+			want:        false,
+		},
+		{
+			name:        "zero quality",
+			accept:      "text/html, text/csv;q=0",
+			contentType: "text/csv",
+			want:        false,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			require.Equal(t, tt.want, report.Accepts(tt.accept, tt.contentType))
+		})
+	}
+}
+
+func TestService_CreateJob(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	dto := report.CreateJobDTO{
+		GetMapDTO: report.GetMapDTO{
+			Year:  2021,
+			Month: 9,
+		},
+		Principal: "billing",
+	}
+	csvDTO := dto
+	csvDTO.Format = report.DefaultFormat
+	job := report.Job{
+		JobID:     1,
+		Month:     dto.Month,
+		Year:      dto.Year,
+		Format:    report.DefaultFormat,
+		Principal: dto.Principal,
+		Status:    report.Queued,
+	}
+	repositoryErr := errors.New("repository error")
+
+	tests := []struct {
+		name      string
+		mock      func(repository *MockRepository)
+		dto       report.CreateJobDTO
+		want      report.Job
+		wantedErr error
+	}{
+		{
+			name: "success create job with default format",
+			mock: func(repository *MockRepository) {
+				repository.EXPECT().CreateJob(ctx, csvDTO).Return(job, nil)
+			},
+			dto:  dto,
+			want: job,
+		},
+		{
+			name: "report is not available",
+			mock: func(repository *MockRepository) {
+			},
+			dto: report.CreateJobDTO{
+				GetMapDTO: report.GetMapDTO{
+					Year:  int64(time.Now().Year() + 1),
+					Month: 9,
+				},
+				Principal: dto.Principal,
+			},
+			wantedErr: report.ErrIsNotAvailable,
+		},
+		{
+			name: "unsupported format",
+			mock: func(repository *MockRepository) {
+			},
+			dto: report.CreateJobDTO{
+				GetMapDTO: report.GetMapDTO{
+					Year:   2021,
+					Month:  9,
+					Format: "txt",
+				},
+				Principal: dto.Principal,
+			},
+			wantedErr: report.ErrUnsupportedFormat,
+		},
+		{
+			name: "repository error",
+			mock: func(repository *MockRepository) {
+				repository.EXPECT().CreateJob(ctx, csvDTO).Return(report.Job{}, repositoryErr)
+			},
+			dto:       dto,
+			wantedErr: repositoryErr,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			service, repository, _ := mockService(t)
+
+			tt.mock(repository)
+
+			got, err := service.CreateJob(ctx, tt.dto)
+			if tt.wantedErr != nil {
+				require.ErrorIs(t, err, tt.wantedErr)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestService_GetJob(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	job := report.Job{
+		JobID:     1,
+		Month:     9,
+		Year:      2021,
+		Format:    report.DefaultFormat,
+		Principal: "billing",
+		Status:    report.Done,
+		Key:       "2021-9.csv",
+	}
+	repositoryErr := errors.New("repository error")
+
+	tests := []struct {
+		name      string
+		mock      func(repository *MockRepository)
+		principal string
+		want      report.Job
+		wantedErr error
+	}{
+		{
+			name: "success get job",
+			mock: func(repository *MockRepository) {
+				repository.EXPECT().GetJobByID(ctx, job.JobID).Return(job, nil)
+			},
+			principal: job.Principal,
+			want:      job,
+		},
+		{
+			name: "job of another principal",
+			mock: func(repository *MockRepository) {
+				repository.EXPECT().GetJobByID(ctx, job.JobID).Return(job, nil)
+			},
+			principal: "support",
+			wantedErr: report.ErrJobNotFound,
+		},
+		{
+			name: "repository error",
+			mock: func(repository *MockRepository) {
+				repository.EXPECT().GetJobByID(ctx, job.JobID).Return(report.Job{}, repositoryErr)
+			},
+			principal: job.Principal,
+			wantedErr: repositoryErr,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			service, repository, _ := mockService(t)
+
+			tt.mock(repository)
+
+			got, err := service.GetJob(ctx, job.JobID, tt.principal)
+			if tt.wantedErr != nil {
+				require.ErrorIs(t, err, tt.wantedErr)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestService_RunJobs(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	doneJob := report.Job{JobID: 1, Month: 9, Year: 2021, Format: "json", Status: report.Running}
+	doneKey := "2021-9.json"
+	emptyJob := report.Job{JobID: 2, Month: 10, Year: 2021, Format: "csv", Status: report.Running}
+	emptyKey := "2021-10.csv"
+	repositoryErr := errors.New("repository error")
+
+	tests := []struct {
+		name      string
+		mock      func(repository *MockRepository, cache *MockCache)
+		want      int
+		wantedErr error
+	}{
+		{
+			name: "done and failed jobs",
+			mock: func(repository *MockRepository, cache *MockCache) {
+				repository.EXPECT().ClaimJobs(ctx, uint64(2), jobTimeout).Return([]report.Job{doneJob, emptyJob}, nil)
+
+				cache.EXPECT().IsExist(doneKey).Return(true)
+				repository.EXPECT().SaveJobResult(ctx, report.SaveJobResultDTO{
+					JobID:  doneJob.JobID,
+					Status: report.Done,
+					Key:    doneKey,
+				}).Return(nil)
+
+				cache.EXPECT().IsExist(emptyKey).Return(false)
+				repository.EXPECT().
+					GetSnapshot(ctx, emptyJob.Month, emptyJob.Year).
+					Return(newSnapshot(t, emptyJob.MapDTO(), nil), nil)
+				repository.EXPECT().SaveJobResult(ctx, report.SaveJobResultDTO{
+					JobID:  emptyJob.JobID,
+					Status: report.Failed,
+					Error:  report.ErrNotFound.Error(),
+				}).Return(nil)
+			},
+			want: 2,
+		},
+		{
+			name: "no jobs",
+			mock: func(repository *MockRepository, cache *MockCache) {
+				repository.EXPECT().ClaimJobs(ctx, uint64(2), jobTimeout).Return(nil, nil)
+			},
+			want: 0,
+		},
+		{
+			name: "claim error",
+			mock: func(repository *MockRepository, cache *MockCache) {
+				repository.EXPECT().ClaimJobs(ctx, uint64(2), jobTimeout).Return(nil, repositoryErr)
+			},
+			wantedErr: repositoryErr,
+		},
+		{
+			name: "save result error",
+			mock: func(repository *MockRepository, cache *MockCache) {
+				repository.EXPECT().ClaimJobs(ctx, uint64(2), jobTimeout).Return([]report.Job{doneJob}, nil)
+				cache.EXPECT().IsExist(doneKey).Return(true)
+				repository.EXPECT().SaveJobResult(ctx, gomock.Any()).Return(repositoryErr)
+			},
+			wantedErr: repositoryErr,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			service, repository, cache := mockService(t)
+
+			tt.mock(repository, cache)
+
+			got, err := service.RunJobs(ctx, 2)
+			if tt.wantedErr != nil {
+				require.ErrorIs(t, err, tt.wantedErr)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
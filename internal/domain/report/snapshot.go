@@ -0,0 +1,70 @@
+package report
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	ErrSnapshotNotFound  = errors.New("report snapshot not found")
+	ErrSnapshotCorrupted = errors.New("report snapshot checksum mismatch")
+)
+
+// Snapshot is the final report of a month. It is computed once the month is final and every report of the month
+// is made from it, so changes to the orders of the month made after that, such as refunds, are not in its report.
+type Snapshot struct {
+	Month       int64
+	Year        int64
+	Entries     []Entry
+	Checksum    string
+	GeneratedAt time.Time
+}
+
+func NewSnapshot(month, year int64, entries []Entry, generatedAt time.Time) (Snapshot, error) {
+	checksum, err := snapshotChecksum(entries)
+	if err != nil {
+		return Snapshot{}, err
+	}
+
+	return Snapshot{
+		Month:       month,
+		Year:        year,
+		Entries:     entries,
+		Checksum:    checksum,
+		GeneratedAt: generatedAt,
+	}, nil
+}
+
+// Verify checks the entries were not changed since the snapshot was computed.
+func (s Snapshot) Verify() error {
+	checksum, err := snapshotChecksum(s.Entries)
+	if err != nil {
+		return err
+	}
+
+	if checksum != s.Checksum {
+		return fmt.Errorf("verify snapshot of %d-%d: %w", s.Year, s.Month, ErrSnapshotCorrupted)
+	}
+
+	return nil
+}
+
+// snapshotChecksum is the hex encoded SHA-256 of the JSON of the entries.
+func snapshotChecksum(entries []Entry) (string, error) {
+	if entries == nil {
+		entries = []Entry{}
+	}
+
+	data, err := json.Marshal(entries)
+	if err != nil {
+		return "", fmt.Errorf("marshal snapshot entries: %w", err)
+	}
+
+	sum := sha256.Sum256(data)
+
+	return hex.EncodeToString(sum[:]), nil
+}
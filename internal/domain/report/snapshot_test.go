@@ -0,0 +1,53 @@
+package report_test
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+	"time"
+
+	"github.com/maypok86/payment-api/internal/domain/currency"
+	"github.com/maypok86/payment-api/internal/domain/report"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSnapshot_Verify(t *testing.T) {
+	t.Parallel()
+
+	generatedAt := time.Date(2022, 11, 1, 0, 0, 0, 0, time.UTC)
+	entries := []report.Entry{
+		{ServiceID: 1, Currency: currency.RUB, Amount: 100},
+		{ServiceID: 2, Currency: currency.USD, Amount: 5},
+	}
+
+	snapshot, err := report.NewSnapshot(10, 2022, entries, generatedAt)
+	require.NoError(t, err)
+	require.Len(t, snapshot.Checksum, 64)
+
+	// Entries without a service name keep the checksums of the snapshots made before the service catalog.
+	legacy := sha256.Sum256([]byte(
+		`[{"service_id":1,"currency":"RUB","amount":100},{"service_id":2,"currency":"USD","amount":5}]`,
+	))
+	require.Equal(t, hex.EncodeToString(legacy[:]), snapshot.Checksum)
+	require.Equal(t, generatedAt, snapshot.GeneratedAt)
+	require.NoError(t, snapshot.Verify())
+
+	same, err := report.NewSnapshot(10, 2022, append([]report.Entry(nil), entries...), generatedAt.Add(time.Hour))
+	require.NoError(t, err)
+	require.Equal(t, snapshot.Checksum, same.Checksum)
+
+	changed := snapshot
+	changed.Entries = append([]report.Entry(nil), entries...)
+	changed.Entries[1].Amount = 6
+	require.ErrorIs(t, changed.Verify(), report.ErrSnapshotCorrupted)
+
+	named := snapshot
+	named.Entries = append([]report.Entry(nil), entries...)
+	named.Entries[0].ServiceName = "Delivery"
+	require.ErrorIs(t, named.Verify(), report.ErrSnapshotCorrupted)
+
+	empty, err := report.NewSnapshot(10, 2022, nil, generatedAt)
+	require.NoError(t, err)
+	empty.Entries = []report.Entry{}
+	require.NoError(t, empty.Verify())
+}
@@ -0,0 +1,59 @@
+package report
+
+import (
+	"fmt"
+
+	"github.com/xuri/excelize/v2"
+)
+
+const xlsxSheet = "Report"
+
+type XLSXFormatter struct{}
+
+func (XLSXFormatter) Format(entries []Entry) ([]byte, error) {
+	file := excelize.NewFile()
+	defer file.Close()
+
+	if err := file.SetSheetName(file.GetSheetName(0), xlsxSheet); err != nil {
+		return nil, fmt.Errorf("format xlsx report: %w", err)
+	}
+
+	header := make([]interface{}, 0, len(entryHeader))
+	for _, column := range entryHeader {
+		header = append(header, column)
+	}
+	if err := file.SetSheetRow(xlsxSheet, "A1", &header); err != nil {
+		return nil, fmt.Errorf("format xlsx report: %w", err)
+	}
+
+	for i, entry := range entries {
+		cell, err := excelize.CoordinatesToCellName(1, i+2)
+		if err != nil {
+			return nil, fmt.Errorf("format xlsx report: %w", err)
+		}
+
+		if err := file.SetSheetRow(xlsxSheet, cell, &[]interface{}{
+			entry.ServiceID,
+			entry.ServiceName,
+			entry.Currency.String(),
+			entry.Amount,
+		}); err != nil {
+			return nil, fmt.Errorf("format xlsx report: %w", err)
+		}
+	}
+
+	buffer, err := file.WriteToBuffer()
+	if err != nil {
+		return nil, fmt.Errorf("format xlsx report: %w", err)
+	}
+
+	return buffer.Bytes(), nil
+}
+
+func (XLSXFormatter) ContentType() string {
+	return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+}
+
+func (XLSXFormatter) Extension() string {
+	return "xlsx"
+}
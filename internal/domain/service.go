@@ -2,12 +2,18 @@ package domain
 
 import (
 	"context"
+	"time"
 
-	"github.com/maypok86/payment-api/internal/cache"
 	"github.com/maypok86/payment-api/internal/domain/account"
+	"github.com/maypok86/payment-api/internal/domain/catalog"
+	"github.com/maypok86/payment-api/internal/domain/idempotency"
+	"github.com/maypok86/payment-api/internal/domain/ledger"
 	"github.com/maypok86/payment-api/internal/domain/order"
+	"github.com/maypok86/payment-api/internal/domain/outbox"
 	"github.com/maypok86/payment-api/internal/domain/report"
 	"github.com/maypok86/payment-api/internal/domain/transaction"
+	"github.com/maypok86/payment-api/internal/domain/webhook"
+	"github.com/maypok86/payment-api/internal/publisher"
 	"github.com/maypok86/payment-api/internal/repository/psql"
 	"go.uber.org/zap"
 )
@@ -21,24 +27,67 @@ type Services struct {
 	Transaction *transaction.Service
 	Order       *order.Service
 	Report      *report.Service
+	Idempotency *idempotency.Service
+	Ledger      *ledger.Service
+	Outbox      *outbox.Service
+	Webhook     *webhook.Service
+	Catalog     *catalog.Service
 }
 
 func NewServices(
 	transactor Transactor,
 	repositories *psql.Repositories,
-	reportCache *cache.ReportCache,
+	reportStorage report.Cache,
+	reportJobTimeout time.Duration,
+	reportSnapshotGrace time.Duration,
+	orderReservationTTL time.Duration,
+	outboxClaimTimeout time.Duration,
+	webhookClaimTimeout time.Duration,
+	rateProvider account.RateProvider,
+	eventPublisher outbox.Publisher,
+	webhookSender webhook.Sender,
 	logger *zap.Logger,
 ) *Services {
+	webhookService := webhook.NewService(repositories.Webhook, webhookSender, webhookClaimTimeout, logger)
+
 	return &Services{
-		Account:     account.NewService(transactor, repositories.Account, repositories.Transaction, logger),
+		Account: account.NewService(
+			transactor,
+			repositories.Account,
+			repositories.Transaction,
+			repositories.Ledger,
+			repositories.Outbox,
+			rateProvider,
+			logger,
+		),
 		Transaction: transaction.NewService(repositories.Transaction, logger),
 		Order: order.NewService(
 			transactor,
 			repositories.Order,
 			repositories.Transaction,
 			repositories.Account,
+			repositories.Ledger,
+			repositories.Outbox,
+			repositories.Service,
+			orderReservationTTL,
+			logger,
+		),
+		Report: report.NewService(
+			repositories.Report,
+			reportStorage,
+			reportJobTimeout,
+			reportSnapshotGrace,
+			logger,
+		),
+		Idempotency: idempotency.NewService(transactor, repositories.Idempotency, logger),
+		Ledger:      ledger.NewService(repositories.Ledger, logger),
+		Outbox: outbox.NewService(
+			repositories.Outbox,
+			publisher.NewMultiPublisher(webhookService, eventPublisher),
+			outboxClaimTimeout,
 			logger,
 		),
-		Report: report.NewService(repositories.Report, reportCache, logger),
+		Webhook: webhookService,
+		Catalog: catalog.NewService(repositories.Service, logger),
 	}
 }
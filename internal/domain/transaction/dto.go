@@ -1,9 +1,46 @@
 package transaction
 
+import (
+	"time"
+
+	"github.com/maypok86/payment-api/internal/domain/currency"
+)
+
 type CreateDTO struct {
 	Type        Type
 	SenderID    int64
 	ReceiverID  int64
 	Amount      int64
+	Currency    currency.Currency
+	Conversion  *Conversion
 	Description string
 }
+
+// StatementDTO selects the transactions of an account which change its balance in a currency. The period
+// includes From and excludes To, nil bounds are open.
+type StatementDTO struct {
+	AccountID int64
+	Currency  currency.Currency
+	From      *time.Time
+	To        *time.Time
+}
+
+func NewStatementDTO(accountID int64, cur currency.Currency, from, to string) (StatementDTO, error) {
+	dto := StatementDTO{
+		AccountID: accountID,
+		Currency:  cur,
+	}
+
+	var err error
+	if dto.From, err = parseDate(from); err != nil {
+		return StatementDTO{}, err
+	}
+	if dto.To, err = parseDate(to); err != nil {
+		return StatementDTO{}, err
+	}
+	if dto.From != nil && dto.To != nil && !dto.From.Before(*dto.To) {
+		return StatementDTO{}, ErrInvalidDateRange
+	}
+
+	return dto, nil
+}
@@ -3,8 +3,11 @@ package transaction
 import (
 	"database/sql/driver"
 	"errors"
+	"fmt"
+	"strconv"
 	"time"
 
+	"github.com/maypok86/payment-api/internal/domain/currency"
 	"github.com/maypok86/payment-api/internal/pkg/pagination"
 	"github.com/maypok86/payment-api/internal/pkg/sort"
 )
@@ -24,6 +27,8 @@ var (
 	Transfer          = Type{"transfer"}
 	Reservation       = Type{"reservation"}
 	CancelReservation = Type{"cancel_reservation"}
+	Payment           = Type{"payment"}
+	Refund            = Type{"refund"}
 )
 
 var transactionTypeToString = map[Type]string{
@@ -31,6 +36,8 @@ var transactionTypeToString = map[Type]string{
 	Transfer:          "transfer",
 	Reservation:       "reservation",
 	CancelReservation: "cancel_reservation",
+	Payment:           "payment",
+	Refund:            "refund",
 }
 
 var stringToTransactionType = map[string]Type{
@@ -38,6 +45,8 @@ var stringToTransactionType = map[string]Type{
 	"transfer":           Transfer,
 	"reservation":        Reservation,
 	"cancel_reservation": CancelReservation,
+	"payment":            Payment,
+	"refund":             Refund,
 }
 
 func (t Type) String() string {
@@ -67,12 +76,23 @@ func (t Type) Value() (driver.Value, error) {
 	return s, nil
 }
 
+// Conversion describes a transfer between balances in different currencies. Amount is received in Currency,
+// Remainder is the fraction of its minor unit which is lost when the converted amount is rounded down.
+type Conversion struct {
+	Rate      string
+	Amount    int64
+	Currency  currency.Currency
+	Remainder string
+}
+
 type Transaction struct {
 	TransactionID int64
 	Type          Type
 	SenderID      int64
 	ReceiverID    int64
 	Amount        int64
+	Currency      currency.Currency
+	Conversion    *Conversion
 	Description   string
 	CreatedAt     time.Time
 }
@@ -80,11 +100,80 @@ type Transaction struct {
 var (
 	ErrInvalidSortParam      = errors.New("invalid sort param")
 	ErrInvalidDirectionParam = errors.New("invalid direction param")
+	ErrCursorMismatch        = errors.New("cursor does not match sort param")
 )
 
+const defaultSortColumn = "created_at"
+
+// ListParams selects a page of transactions. The page is found by Cursor if it is set and by the offset otherwise,
+// WithCount makes a cursor query count all transactions, offset queries always count them.
 type ListParams struct {
 	Pagination pagination.Params
 	Sort       *sort.Sort
+	Cursor     *pagination.Cursor
+	WithCount  bool
+	Filter     Filter
+}
+
+// SortColumn returns the column transactions are sorted by, ties are always broken by transaction_id.
+func (lp ListParams) SortColumn() string {
+	if lp.Sort == nil {
+		return defaultSortColumn
+	}
+
+	return lp.Sort.Column()
+}
+
+func (lp ListParams) IsDesc() bool {
+	return lp.Sort != nil && lp.Sort.IsDesc()
+}
+
+// WithCursor returns list params which start the page at the cursor instead of the offset.
+func (lp ListParams) WithCursor(cursor pagination.Cursor, withCount bool) (ListParams, error) {
+	if cursor.Column != lp.SortColumn() {
+		return ListParams{}, ErrCursorMismatch
+	}
+
+	var err error
+	switch cursor.Column {
+	case "created_at":
+		_, err = time.Parse(time.RFC3339Nano, cursor.Value)
+	case "amount":
+		_, err = strconv.ParseInt(cursor.Value, 10, 64)
+	}
+	if err != nil {
+		return ListParams{}, fmt.Errorf("%w: %v", pagination.ErrInvalidCursor, err)
+	}
+
+	lp.Pagination.Offset = 0
+	lp.Cursor = &cursor
+	lp.WithCount = withCount
+
+	return lp, nil
+}
+
+// CursorOf returns the cursor pointing at the transaction in the list sorted by column.
+func CursorOf(t Transaction, column string, backward bool) *pagination.Cursor {
+	value := t.CreatedAt.Format(time.RFC3339Nano)
+	if column == "amount" {
+		value = strconv.FormatInt(t.Amount, 10)
+	}
+
+	return &pagination.Cursor{
+		Column:   column,
+		Value:    value,
+		ID:       t.TransactionID,
+		Backward: backward,
+	}
+}
+
+// List is a page of transactions. Count is nil if the transactions were not counted, Next and Prev are nil
+// if there is no page after or before this one.
+type List struct {
+	Transactions []Transaction
+	Count        *int
+	Next         *pagination.Cursor
+	Prev         *pagination.Cursor
 }
 
 func NewListParams(sortParam, directionParam string, params pagination.Params) (ListParams, error) {
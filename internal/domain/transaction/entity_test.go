@@ -4,6 +4,7 @@ import (
 	"database/sql/driver"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/maypok86/payment-api/internal/domain/transaction"
 	"github.com/maypok86/payment-api/internal/pkg/pagination"
@@ -39,6 +40,16 @@ func TestType_String(t *testing.T) {
 			transactionType: transaction.CancelReservation,
 			want:            "cancel_reservation",
 		},
+		{
+			name:            "check payment",
+			transactionType: transaction.Payment,
+			want:            "payment",
+		},
+		{
+			name:            "check refund",
+			transactionType: transaction.Refund,
+			want:            "refund",
+		},
 		{
 			name:            "check empty",
 			transactionType: transaction.Type{},
@@ -144,6 +155,18 @@ func TestType_Value(t *testing.T) {
 			want:            "cancel_reservation",
 			wantErr:         false,
 		},
+		{
+			name:            "check payment",
+			transactionType: transaction.Payment,
+			want:            "payment",
+			wantErr:         false,
+		},
+		{
+			name:            "check refund",
+			transactionType: transaction.Refund,
+			want:            "refund",
+			wantErr:         false,
+		},
 		{
 			name:            "check empty",
 			transactionType: transaction.Type{},
@@ -245,3 +268,65 @@ func TestListParams_NewListParams(t *testing.T) {
 		})
 	}
 }
+
+func TestListParams_WithCursor(t *testing.T) {
+	t.Parallel()
+
+	createdAt := time.Date(2022, 11, 14, 12, 0, 0, 123456000, time.UTC)
+	entity := transaction.Transaction{TransactionID: 7, Amount: 100, CreatedAt: createdAt}
+
+	tests := []struct {
+		name      string
+		sort      *sort.Sort
+		cursor    *pagination.Cursor
+		wantedErr error
+	}{
+		{
+			name:   "success default sort",
+			cursor: transaction.CursorOf(entity, "created_at", false),
+		},
+		{
+			name:   "success sort by sum",
+			sort:   sort.New("amount", "desc"),
+			cursor: transaction.CursorOf(entity, "amount", true),
+		},
+		{
+			name:      "cursor does not match sort",
+			sort:      sort.New("amount", "asc"),
+			cursor:    transaction.CursorOf(entity, "created_at", false),
+			wantedErr: transaction.ErrCursorMismatch,
+		},
+		{
+			name:      "invalid cursor value",
+			cursor:    &pagination.Cursor{Column: "created_at", Value: "yesterday", ID: 7},
+			wantedErr: pagination.ErrInvalidCursor,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			cursor, err := pagination.ParseCursor(tt.cursor.String())
+			require.NoError(t, err)
+			require.Equal(t, *tt.cursor, cursor)
+
+			listParams := transaction.ListParams{
+				Pagination: pagination.Params{Limit: 10, Offset: 20},
+				Sort:       tt.sort,
+			}
+			got, err := listParams.WithCursor(cursor, true)
+			if tt.wantedErr != nil {
+				require.ErrorIs(t, err, tt.wantedErr)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, uint64(0), got.Pagination.Offset)
+			require.Equal(t, &cursor, got.Cursor)
+			require.True(t, got.WithCount)
+		})
+	}
+}
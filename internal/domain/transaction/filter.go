@@ -0,0 +1,154 @@
+package transaction
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+var (
+	ErrInvalidTypeParam         = errors.New("invalid type param")
+	ErrInvalidFlowParam         = errors.New("invalid flow param")
+	ErrInvalidDateParam         = errors.New("invalid date param")
+	ErrInvalidDateRange         = errors.New("invalid date range")
+	ErrInvalidAmountParam       = errors.New("invalid amount param")
+	ErrInvalidAmountRange       = errors.New("invalid amount range")
+	ErrInvalidCounterpartyParam = errors.New("invalid counterparty param")
+	ErrInvalidDescriptionParam  = errors.New("invalid description param")
+)
+
+const MaxDescriptionFilterLength = 100
+
+type Flow struct {
+	string
+}
+
+var (
+	AnyFlow  = Flow{""}
+	Incoming = Flow{"incoming"}
+	Outgoing = Flow{"outgoing"}
+)
+
+func (f Flow) String() string {
+	return f.string
+}
+
+// IncomingTypes are the types of transactions of an account with itself which add money to its balance,
+// the rest of such transactions take money from it.
+var IncomingTypes = []Type{Enrollment, CancelReservation, Refund}
+
+// Filter narrows down the transactions of an account. Zero fields do not filter, the date range includes From
+// and excludes To, the amount range includes both ends.
+type Filter struct {
+	Types          []Type
+	Flow           Flow
+	From           *time.Time
+	To             *time.Time
+	MinAmount      *int64
+	MaxAmount      *int64
+	CounterpartyID int64
+	Description    string
+}
+
+// FilterParams are the raw filter params of a request, Types is a comma separated list.
+type FilterParams struct {
+	Types          string
+	Flow           string
+	From           string
+	To             string
+	MinAmount      string
+	MaxAmount      string
+	CounterpartyID string
+	Description    string
+}
+
+func NewFilter(params FilterParams) (Filter, error) {
+	var filter Filter
+
+	if params.Types != "" {
+		for _, s := range strings.Split(params.Types, ",") {
+			t, ok := stringToTransactionType[strings.TrimSpace(s)]
+			if !ok {
+				return Filter{}, ErrInvalidTypeParam
+			}
+			filter.Types = append(filter.Types, t)
+		}
+	}
+
+	switch params.Flow {
+	case AnyFlow.string:
+	case Incoming.string:
+		filter.Flow = Incoming
+	case Outgoing.string:
+		filter.Flow = Outgoing
+	default:
+		return Filter{}, ErrInvalidFlowParam
+	}
+
+	var err error
+	if filter.From, err = parseDate(params.From); err != nil {
+		return Filter{}, err
+	}
+	if filter.To, err = parseDate(params.To); err != nil {
+		return Filter{}, err
+	}
+	if filter.From != nil && filter.To != nil && !filter.From.Before(*filter.To) {
+		return Filter{}, ErrInvalidDateRange
+	}
+
+	if filter.MinAmount, err = parseAmount(params.MinAmount); err != nil {
+		return Filter{}, err
+	}
+	if filter.MaxAmount, err = parseAmount(params.MaxAmount); err != nil {
+		return Filter{}, err
+	}
+	if filter.MinAmount != nil && filter.MaxAmount != nil && *filter.MinAmount > *filter.MaxAmount {
+		return Filter{}, ErrInvalidAmountRange
+	}
+
+	if params.CounterpartyID != "" {
+		filter.CounterpartyID, err = strconv.ParseInt(params.CounterpartyID, 10, 64)
+		if err != nil || filter.CounterpartyID <= 0 {
+			return Filter{}, ErrInvalidCounterpartyParam
+		}
+	}
+
+	if utf8.RuneCountInString(params.Description) > MaxDescriptionFilterLength {
+		return Filter{}, ErrInvalidDescriptionParam
+	}
+	filter.Description = params.Description
+
+	return filter, nil
+}
+
+// parseDate accepts either a RFC 3339 time or a date, which means its midnight in UTC.
+func parseDate(s string) (*time.Time, error) {
+	if s == "" {
+		return nil, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		t, err = time.Parse("2006-01-02", s)
+		if err != nil {
+			return nil, ErrInvalidDateParam
+		}
+	}
+
+	return &t, nil
+}
+
+func parseAmount(s string) (*int64, error) {
+	if s == "" {
+		return nil, nil
+	}
+
+	amount, err := strconv.ParseInt(s, 10, 64)
+	if err != nil || amount < 0 {
+		return nil, ErrInvalidAmountParam
+	}
+
+	return &amount, nil
+}
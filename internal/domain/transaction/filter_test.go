@@ -0,0 +1,113 @@
+package transaction_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/maypok86/payment-api/internal/domain/transaction"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFilter_NewFilter(t *testing.T) {
+	t.Parallel()
+
+	from := time.Date(2022, 11, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2022, 11, 15, 12, 30, 0, 0, time.UTC)
+	minAmount := int64(100)
+	maxAmount := int64(500)
+
+	tests := []struct {
+		name      string
+		params    transaction.FilterParams
+		want      transaction.Filter
+		wantedErr error
+	}{
+		{
+			name:   "empty filter",
+			params: transaction.FilterParams{},
+			want:   transaction.Filter{},
+		},
+		{
+			name: "success full filter",
+			params: transaction.FilterParams{
+				Types:          "transfer, refund",
+				Flow:           "incoming",
+				From:           "2022-11-01",
+				To:             "2022-11-15T12:30:00Z",
+				MinAmount:      "100",
+				MaxAmount:      "500",
+				CounterpartyID: "2",
+				Description:    "order",
+			},
+			want: transaction.Filter{
+				Types:          []transaction.Type{transaction.Transfer, transaction.Refund},
+				Flow:           transaction.Incoming,
+				From:           &from,
+				To:             &to,
+				MinAmount:      &minAmount,
+				MaxAmount:      &maxAmount,
+				CounterpartyID: 2,
+				Description:    "order",
+			},
+		},
+		{
+			name:      "invalid type",
+			params:    transaction.FilterParams{Types: "transfer,gift"},
+			wantedErr: transaction.ErrInvalidTypeParam,
+		},
+		{
+			name:      "invalid flow",
+			params:    transaction.FilterParams{Flow: "inside"},
+			wantedErr: transaction.ErrInvalidFlowParam,
+		},
+		{
+			name:      "invalid date",
+			params:    transaction.FilterParams{From: "01.11.2022"},
+			wantedErr: transaction.ErrInvalidDateParam,
+		},
+		{
+			name:      "empty date range",
+			params:    transaction.FilterParams{From: "2022-11-15", To: "2022-11-15"},
+			wantedErr: transaction.ErrInvalidDateRange,
+		},
+		{
+			name:      "negative amount",
+			params:    transaction.FilterParams{MinAmount: "-1"},
+			wantedErr: transaction.ErrInvalidAmountParam,
+		},
+		{
+			name:      "invalid amount range",
+			params:    transaction.FilterParams{MinAmount: "500", MaxAmount: "100"},
+			wantedErr: transaction.ErrInvalidAmountRange,
+		},
+		{
+			name:      "invalid counterparty",
+			params:    transaction.FilterParams{CounterpartyID: "0"},
+			wantedErr: transaction.ErrInvalidCounterpartyParam,
+		},
+		{
+			name:      "too long description",
+			params:    transaction.FilterParams{Description: strings.Repeat("a", transaction.MaxDescriptionFilterLength+1)},
+			wantedErr: transaction.ErrInvalidDescriptionParam,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := transaction.NewFilter(tt.params)
+			if tt.wantedErr != nil {
+				require.ErrorIs(t, err, tt.wantedErr)
+				return
+			}
+
+			require.NoError(t, err)
+			require.True(t, reflect.DeepEqual(tt.want, got))
+		})
+	}
+}
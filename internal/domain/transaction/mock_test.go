@@ -35,14 +35,42 @@ func (m *MockRepository) EXPECT() *MockRepositoryMockRecorder {
 	return m.recorder
 }
 
+// ForEachStatementRow mocks base method.
+func (m *MockRepository) ForEachStatementRow(ctx context.Context, dto transaction.StatementDTO, fn func(transaction.StatementRow) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ForEachStatementRow", ctx, dto, fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ForEachStatementRow indicates an expected call of ForEachStatementRow.
+func (mr *MockRepositoryMockRecorder) ForEachStatementRow(ctx, dto, fn interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ForEachStatementRow", reflect.TypeOf((*MockRepository)(nil).ForEachStatementRow), ctx, dto, fn)
+}
+
+// GetOpeningBalance mocks base method.
+func (m *MockRepository) GetOpeningBalance(ctx context.Context, dto transaction.StatementDTO) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOpeningBalance", ctx, dto)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOpeningBalance indicates an expected call of GetOpeningBalance.
+func (mr *MockRepositoryMockRecorder) GetOpeningBalance(ctx, dto interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOpeningBalance", reflect.TypeOf((*MockRepository)(nil).GetOpeningBalance), ctx, dto)
+}
+
 // GetTransactionsByAccountID mocks base method.
-func (m *MockRepository) GetTransactionsByAccountID(ctx context.Context, senderID int64, listParams transaction.ListParams) ([]transaction.Transaction, int, error) {
+func (m *MockRepository) GetTransactionsByAccountID(ctx context.Context, senderID int64, listParams transaction.ListParams) (transaction.List, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "GetTransactionsByAccountID", ctx, senderID, listParams)
-	ret0, _ := ret[0].([]transaction.Transaction)
-	ret1, _ := ret[1].(int)
-	ret2, _ := ret[2].(error)
-	return ret0, ret1, ret2
+	ret0, _ := ret[0].(transaction.List)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
 // GetTransactionsByAccountID indicates an expected call of GetTransactionsByAccountID.
@@ -3,6 +3,7 @@ package transaction
 import (
 	"context"
 	"fmt"
+	"io"
 
 	"go.uber.org/zap"
 )
@@ -10,7 +11,9 @@ import (
 //go:generate mockgen -source=service.go -destination=mock_test.go -package=transaction_test
 
 type Repository interface {
-	GetTransactionsByAccountID(ctx context.Context, senderID int64, listParams ListParams) ([]Transaction, int, error)
+	GetTransactionsByAccountID(ctx context.Context, senderID int64, listParams ListParams) (List, error)
+	GetOpeningBalance(ctx context.Context, dto StatementDTO) (int64, error)
+	ForEachStatementRow(ctx context.Context, dto StatementDTO, fn func(row StatementRow) error) error
 }
 
 type Service struct {
@@ -29,11 +32,38 @@ func (s *Service) GetTransactionsByAccountID(
 	ctx context.Context,
 	accountID int64,
 	listParams ListParams,
-) ([]Transaction, int, error) {
-	transactions, count, err := s.repository.GetTransactionsByAccountID(ctx, accountID, listParams)
+) (List, error) {
+	list, err := s.repository.GetTransactionsByAccountID(ctx, accountID, listParams)
 	if err != nil {
-		return nil, 0, fmt.Errorf("get transactions by sender accountID: %w", err)
+		return List{}, fmt.Errorf("get transactions by sender accountID: %w", err)
 	}
 
-	return transactions, count, nil
+	return list, nil
+}
+
+// WriteStatement streams the statement of the account to w. Rows are encoded as they are read from
+// the repository, so nothing is written if reading the opening balance fails.
+func (s *Service) WriteStatement(ctx context.Context, dto StatementDTO, format StatementFormat, w io.Writer) error {
+	balance, err := s.repository.GetOpeningBalance(ctx, dto)
+	if err != nil {
+		return fmt.Errorf("get opening balance: %w", err)
+	}
+
+	encoder, err := newStatementEncoder(format, w)
+	if err != nil {
+		return fmt.Errorf("write statement: %w", err)
+	}
+
+	if err := encoder.Opening(dto, balance); err != nil {
+		return err
+	}
+
+	if err := s.repository.ForEachStatementRow(ctx, dto, func(row StatementRow) error {
+		balance += row.Change
+		return encoder.Row(row, balance)
+	}); err != nil {
+		return fmt.Errorf("write statement rows: %w", err)
+	}
+
+	return encoder.Closing(dto, balance)
 }
@@ -5,11 +5,13 @@ import (
 	"errors"
 	"os"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/bxcodec/faker/v3"
 	"github.com/golang/mock/gomock"
+	"github.com/maypok86/payment-api/internal/domain/currency"
 	"github.com/maypok86/payment-api/internal/domain/transaction"
 	"github.com/maypok86/payment-api/internal/pkg/logger"
 	"github.com/maypok86/payment-api/internal/pkg/pagination"
@@ -72,15 +74,10 @@ func TestService_GetTransactionsBySenderID(t *testing.T) {
 
 	type mockBehavior func(r *MockRepository)
 
-	type want struct {
-		transactions []transaction.Transaction
-		count        int
-	}
-
 	tests := []struct {
 		name    string
 		mock    mockBehavior
-		want    want
+		want    transaction.List
 		wantErr bool
 	}{
 		{
@@ -88,11 +85,11 @@ func TestService_GetTransactionsBySenderID(t *testing.T) {
 			mock: func(repository *MockRepository) {
 				repository.EXPECT().
 					GetTransactionsByAccountID(ctx, fakeSenderID, listParams).
-					Return(transactionList, fakeCount, nil)
+					Return(transaction.List{Transactions: transactionList, Count: &fakeCount}, nil)
 			},
-			want: want{
-				transactions: transactionList,
-				count:        fakeCount,
+			want: transaction.List{
+				Transactions: transactionList,
+				Count:        &fakeCount,
 			},
 			wantErr: false,
 		},
@@ -101,9 +98,148 @@ func TestService_GetTransactionsBySenderID(t *testing.T) {
 			mock: func(repository *MockRepository) {
 				repository.EXPECT().
 					GetTransactionsByAccountID(ctx, fakeSenderID, listParams).
-					Return(nil, 0, errors.New("repository error"))
+					Return(transaction.List{}, errors.New("repository error"))
+			},
+			want:    transaction.List{},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			service, repository := mockService(t)
+
+			tt.mock(repository)
+
+			got, err := service.GetTransactionsByAccountID(ctx, fakeSenderID, listParams)
+			require.True(t, (err != nil) == tt.wantErr)
+			require.True(t, reflect.DeepEqual(tt.want, got))
+		})
+	}
+}
+
+func TestService_WriteStatement(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	from := time.Date(2022, 11, 1, 0, 0, 0, 0, time.UTC)
+	dto := transaction.StatementDTO{
+		AccountID: 1,
+		Currency:  currency.RUB,
+		From:      &from,
+	}
+	rows := []transaction.StatementRow{
+		{
+			Transaction: transaction.Transaction{
+				TransactionID: 3,
+				Type:          transaction.Enrollment,
+				SenderID:      1,
+				ReceiverID:    1,
+				Amount:        500,
+				Currency:      currency.RUB,
+				Description:   "Add 5.00 RUB to account with id = 1",
+				CreatedAt:     from.Add(time.Hour),
+			},
+			Change: 500,
+		},
+		{
+			Transaction: transaction.Transaction{
+				TransactionID: 4,
+				Type:          transaction.Transfer,
+				SenderID:      1,
+				ReceiverID:    2,
+				Amount:        200,
+				Currency:      currency.RUB,
+				Description:   "Transfer 2.00 RUB from account with id = 1 to account with id = 2",
+				CreatedAt:     from.Add(2 * time.Hour),
+			},
+			Change: -200,
+		},
+	}
+
+	type mockBehavior func(r *MockRepository)
+
+	streamRows := func(r *MockRepository) {
+		r.EXPECT().GetOpeningBalance(ctx, dto).Return(int64(1000), nil)
+		r.EXPECT().
+			ForEachStatementRow(ctx, dto, gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ transaction.StatementDTO, fn func(transaction.StatementRow) error) error {
+				for _, row := range rows {
+					if err := fn(row); err != nil {
+						return err
+					}
+				}
+				return nil
+			})
+	}
+
+	tests := []struct {
+		name    string
+		format  transaction.StatementFormat
+		mock    mockBehavior
+		check   func(t *testing.T, output string)
+		wantErr bool
+	}{
+		{
+			name:   "csv",
+			format: transaction.CSV,
+			mock:   streamRows,
+			check: func(t *testing.T, output string) {
+				t.Helper()
+
+				require.Equal(t, "transaction_id,created_at,type,sender_id,receiver_id,amount,currency,change,balance,"+
+					"description\n"+
+					",2022-11-01T00:00:00Z,opening_balance,,,,RUB,,1000,\n"+
+					"3,2022-11-01T01:00:00Z,enrollment,1,1,500,RUB,500,1500,Add 5.00 RUB to account with id = 1\n"+
+					"4,2022-11-01T02:00:00Z,transfer,1,2,200,RUB,-200,1300,"+
+					"Transfer 2.00 RUB from account with id = 1 to account with id = 2\n"+
+					",,closing_balance,,,,RUB,,1300,\n", output)
+			},
+		},
+		{
+			name:   "json lines",
+			format: transaction.JSONLines,
+			mock:   streamRows,
+			check: func(t *testing.T, output string) {
+				t.Helper()
+
+				lines := strings.Split(strings.TrimSpace(output), "\n")
+				require.Len(t, lines, 4)
+				require.JSONEq(t, `{"record":"opening_balance","at":"2022-11-01T00:00:00Z","currency":"RUB","balance":1000}`,
+					lines[0])
+				require.Contains(t, lines[2], `"change":-200,"balance":1300`)
+				require.JSONEq(t, `{"record":"closing_balance","currency":"RUB","balance":1300}`, lines[3])
+			},
+		},
+		{
+			name:   "pdf",
+			format: transaction.PDF,
+			mock:   streamRows,
+			check: func(t *testing.T, output string) {
+				t.Helper()
+
+				require.True(t, strings.HasPrefix(output, "%PDF-1.4"))
+				require.True(t, strings.HasSuffix(output, "%%EOF\n"))
+				require.Contains(t, output, "(Opening balance: 10.00 RUB) '")
+				require.Contains(t, output, "(Closing balance: 13.00 RUB) '")
+			},
+		},
+		{
+			name:   "opening balance error",
+			format: transaction.CSV,
+			mock: func(r *MockRepository) {
+				r.EXPECT().GetOpeningBalance(ctx, dto).Return(int64(0), errors.New("repository error"))
+			},
+			check: func(t *testing.T, output string) {
+				t.Helper()
+
+				require.Empty(t, output)
 			},
-			want:    want{},
 			wantErr: true,
 		},
 	}
@@ -118,10 +254,10 @@ func TestService_GetTransactionsBySenderID(t *testing.T) {
 
 			tt.mock(repository)
 
-			gotTransactionList, gotCount, err := service.GetTransactionsByAccountID(ctx, fakeSenderID, listParams)
+			var output strings.Builder
+			err := service.WriteStatement(ctx, dto, tt.format, &output)
 			require.True(t, (err != nil) == tt.wantErr)
-			require.True(t, reflect.DeepEqual(tt.want.transactions, gotTransactionList))
-			require.Equal(t, tt.want.count, gotCount)
+			tt.check(t, output.String())
 		})
 	}
 }
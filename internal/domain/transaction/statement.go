@@ -0,0 +1,292 @@
+package transaction
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/maypok86/payment-api/internal/domain/currency"
+	"github.com/maypok86/payment-api/internal/pkg/pdf"
+)
+
+var ErrInvalidFormatParam = errors.New("invalid format param")
+
+type StatementFormat struct {
+	string
+}
+
+var (
+	CSV       = StatementFormat{"csv"}
+	JSONLines = StatementFormat{"jsonl"}
+	PDF       = StatementFormat{"pdf"}
+)
+
+var formatToContentType = map[StatementFormat]string{
+	CSV:       "text/csv",
+	JSONLines: "application/jsonl",
+	PDF:       "application/pdf",
+}
+
+// ParseStatementFormat returns CSV for the empty string.
+func ParseStatementFormat(s string) (StatementFormat, error) {
+	if s == "" {
+		return CSV, nil
+	}
+
+	format := StatementFormat{s}
+	if _, ok := formatToContentType[format]; !ok {
+		return StatementFormat{}, ErrInvalidFormatParam
+	}
+
+	return format, nil
+}
+
+func (f StatementFormat) String() string {
+	return f.string
+}
+
+func (f StatementFormat) ContentType() string {
+	return formatToContentType[f]
+}
+
+// StatementRow is a transaction with the change of the account balance it made.
+type StatementRow struct {
+	Transaction Transaction
+	Change      int64
+}
+
+type statementEncoder interface {
+	Opening(dto StatementDTO, balance int64) error
+	Row(row StatementRow, balance int64) error
+	Closing(dto StatementDTO, balance int64) error
+}
+
+func newStatementEncoder(format StatementFormat, w io.Writer) (statementEncoder, error) {
+	switch format {
+	case CSV:
+		return &csvStatementEncoder{writer: csv.NewWriter(w)}, nil
+	case JSONLines:
+		buffer := bufio.NewWriter(w)
+		return &jsonStatementEncoder{buffer: buffer, encoder: json.NewEncoder(buffer)}, nil
+	case PDF:
+		writer, err := pdf.NewWriter(w)
+		if err != nil {
+			return nil, err
+		}
+		return &pdfStatementEncoder{writer: writer}, nil
+	default:
+		return nil, ErrInvalidFormatParam
+	}
+}
+
+func formatBound(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+
+	return t.Format(time.RFC3339)
+}
+
+// csvStatementEncoder writes the opening and closing balances as rows of the types opening_balance and
+// closing_balance which have only created_at, currency and balance.
+type csvStatementEncoder struct {
+	writer *csv.Writer
+}
+
+func (e *csvStatementEncoder) Opening(dto StatementDTO, balance int64) error {
+	if err := e.writer.Write([]string{
+		"transaction_id", "created_at", "type", "sender_id", "receiver_id",
+		"amount", "currency", "change", "balance", "description",
+	}); err != nil {
+		return fmt.Errorf("write statement header: %w", err)
+	}
+
+	return e.balance("opening_balance", dto.From, dto.Currency, balance)
+}
+
+func (e *csvStatementEncoder) Row(row StatementRow, balance int64) error {
+	t := row.Transaction
+	if err := e.writer.Write([]string{
+		strconv.FormatInt(t.TransactionID, 10),
+		t.CreatedAt.Format(time.RFC3339Nano),
+		t.Type.String(),
+		strconv.FormatInt(t.SenderID, 10),
+		strconv.FormatInt(t.ReceiverID, 10),
+		strconv.FormatInt(t.Amount, 10),
+		t.Currency.String(),
+		strconv.FormatInt(row.Change, 10),
+		strconv.FormatInt(balance, 10),
+		t.Description,
+	}); err != nil {
+		return fmt.Errorf("write statement row: %w", err)
+	}
+
+	return nil
+}
+
+func (e *csvStatementEncoder) Closing(dto StatementDTO, balance int64) error {
+	if err := e.balance("closing_balance", dto.To, dto.Currency, balance); err != nil {
+		return err
+	}
+
+	e.writer.Flush()
+	if err := e.writer.Error(); err != nil {
+		return fmt.Errorf("flush statement: %w", err)
+	}
+
+	return nil
+}
+
+func (e *csvStatementEncoder) balance(kind string, at *time.Time, cur currency.Currency, balance int64) error {
+	if err := e.writer.Write([]string{
+		"", formatBound(at), kind, "", "", "", cur.String(), "", strconv.FormatInt(balance, 10), "",
+	}); err != nil {
+		return fmt.Errorf("write statement %s: %w", kind, err)
+	}
+
+	return nil
+}
+
+type jsonStatementEncoder struct {
+	buffer  *bufio.Writer
+	encoder *json.Encoder
+}
+
+type jsonStatementBalance struct {
+	Record   string            `json:"record"`
+	At       *time.Time        `json:"at,omitempty"`
+	Currency currency.Currency `json:"currency"`
+	Balance  int64             `json:"balance"`
+}
+
+type jsonStatementRow struct {
+	Record        string            `json:"record"`
+	TransactionID int64             `json:"transaction_id"`
+	CreatedAt     time.Time         `json:"created_at"`
+	Type          string            `json:"type"`
+	SenderID      int64             `json:"sender_id"`
+	ReceiverID    int64             `json:"receiver_id"`
+	Amount        int64             `json:"amount"`
+	Currency      currency.Currency `json:"currency"`
+	Change        int64             `json:"change"`
+	Balance       int64             `json:"balance"`
+	Description   string            `json:"description"`
+}
+
+func (e *jsonStatementEncoder) Opening(dto StatementDTO, balance int64) error {
+	return e.encode(jsonStatementBalance{
+		Record:   "opening_balance",
+		At:       dto.From,
+		Currency: dto.Currency,
+		Balance:  balance,
+	})
+}
+
+func (e *jsonStatementEncoder) Row(row StatementRow, balance int64) error {
+	t := row.Transaction
+
+	return e.encode(jsonStatementRow{
+		Record:        "transaction",
+		TransactionID: t.TransactionID,
+		CreatedAt:     t.CreatedAt,
+		Type:          t.Type.String(),
+		SenderID:      t.SenderID,
+		ReceiverID:    t.ReceiverID,
+		Amount:        t.Amount,
+		Currency:      t.Currency,
+		Change:        row.Change,
+		Balance:       balance,
+		Description:   t.Description,
+	})
+}
+
+func (e *jsonStatementEncoder) Closing(dto StatementDTO, balance int64) error {
+	if err := e.encode(jsonStatementBalance{
+		Record:   "closing_balance",
+		At:       dto.To,
+		Currency: dto.Currency,
+		Balance:  balance,
+	}); err != nil {
+		return err
+	}
+
+	if err := e.buffer.Flush(); err != nil {
+		return fmt.Errorf("flush statement: %w", err)
+	}
+
+	return nil
+}
+
+func (e *jsonStatementEncoder) encode(v interface{}) error {
+	if err := e.encoder.Encode(v); err != nil {
+		return fmt.Errorf("write statement line: %w", err)
+	}
+
+	return nil
+}
+
+const pdfRowFormat = "%-20s %-10s %-18s %16s %16s %s"
+
+type pdfStatementEncoder struct {
+	writer *pdf.Writer
+	cur    currency.Currency
+}
+
+func (e *pdfStatementEncoder) Opening(dto StatementDTO, balance int64) error {
+	e.cur = dto.Currency
+
+	period := "all time"
+	if dto.From != nil || dto.To != nil {
+		period = fmt.Sprintf("%s - %s", formatBound(dto.From), formatBound(dto.To))
+	}
+
+	return e.lines(
+		fmt.Sprintf("Statement of account %d", dto.AccountID),
+		"Currency: "+dto.Currency.String(),
+		"Period: "+period,
+		"Opening balance: "+dto.Currency.Format(balance),
+		"",
+		fmt.Sprintf(pdfRowFormat, "Date", "ID", "Type", "Change", "Balance", "Description"),
+	)
+}
+
+func (e *pdfStatementEncoder) Row(row StatementRow, balance int64) error {
+	t := row.Transaction
+
+	return e.lines(fmt.Sprintf(
+		pdfRowFormat,
+		t.CreatedAt.UTC().Format("2006-01-02 15:04:05"),
+		strconv.FormatInt(t.TransactionID, 10),
+		t.Type.String(),
+		e.cur.Format(row.Change),
+		e.cur.Format(balance),
+		t.Description,
+	))
+}
+
+func (e *pdfStatementEncoder) Closing(_ StatementDTO, balance int64) error {
+	if err := e.lines("", "Closing balance: "+e.cur.Format(balance)); err != nil {
+		return err
+	}
+
+	if err := e.writer.Close(); err != nil {
+		return fmt.Errorf("close statement: %w", err)
+	}
+
+	return nil
+}
+
+func (e *pdfStatementEncoder) lines(lines ...string) error {
+	for _, line := range lines {
+		if err := e.writer.Line(line); err != nil {
+			return fmt.Errorf("write statement line: %w", err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,69 @@
+package transaction_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/maypok86/payment-api/internal/domain/currency"
+	"github.com/maypok86/payment-api/internal/domain/transaction"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStatementFormat_ParseStatementFormat(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		format    string
+		want      transaction.StatementFormat
+		wantedErr error
+	}{
+		{
+			name: "default format",
+			want: transaction.CSV,
+		},
+		{
+			name:   "json lines",
+			format: "jsonl",
+			want:   transaction.JSONLines,
+		},
+		{
+			name:   "pdf",
+			format: "pdf",
+			want:   transaction.PDF,
+		},
+		{
+			name:      "invalid format",
+			format:    "xlsx",
+			wantedErr: transaction.ErrInvalidFormatParam,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := transaction.ParseStatementFormat(tt.format)
+			require.ErrorIs(t, err, tt.wantedErr)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestStatementDTO_NewStatementDTO(t *testing.T) {
+	t.Parallel()
+
+	from := time.Date(2022, 11, 1, 0, 0, 0, 0, time.UTC)
+
+	dto, err := transaction.NewStatementDTO(1, currency.USD, "2022-11-01", "")
+	require.NoError(t, err)
+	require.Equal(t, transaction.StatementDTO{AccountID: 1, Currency: currency.USD, From: &from}, dto)
+
+	_, err = transaction.NewStatementDTO(1, currency.RUB, "2022-11-02", "2022-11-01")
+	require.ErrorIs(t, err, transaction.ErrInvalidDateRange)
+
+	_, err = transaction.NewStatementDTO(1, currency.RUB, "", "tomorrow")
+	require.ErrorIs(t, err, transaction.ErrInvalidDateParam)
+}
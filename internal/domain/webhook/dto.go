@@ -0,0 +1,82 @@
+package webhook
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/maypok86/payment-api/internal/domain/outbox"
+)
+
+// CreateSubscriptionDTO describes a new subscription. A secret is generated when Secret is empty.
+type CreateSubscriptionDTO struct {
+	AccountID  int64
+	ServiceID  int64
+	URL        string
+	Secret     string
+	EventTypes []outbox.Type
+}
+
+func NewCreateSubscriptionDTO(
+	accountID, serviceID int64,
+	rawURL, secret string,
+	eventTypes []string,
+) (CreateSubscriptionDTO, error) {
+	if (accountID == 0) == (serviceID == 0) || accountID < 0 || serviceID < 0 {
+		return CreateSubscriptionDTO{}, ErrInvalidOwner
+	}
+
+	if err := validateURL(rawURL); err != nil {
+		return CreateSubscriptionDTO{}, err
+	}
+
+	if len(eventTypes) == 0 {
+		return CreateSubscriptionDTO{}, ErrNoEventTypes
+	}
+
+	seen := make(map[outbox.Type]struct{}, len(eventTypes))
+	types := make([]outbox.Type, 0, len(eventTypes))
+	for _, eventType := range eventTypes {
+		t, err := outbox.ParseType(eventType)
+		if err != nil {
+			return CreateSubscriptionDTO{}, err
+		}
+
+		if _, ok := seen[t]; ok {
+			continue
+		}
+		seen[t] = struct{}{}
+		types = append(types, t)
+	}
+
+	return CreateSubscriptionDTO{
+		AccountID:  accountID,
+		ServiceID:  serviceID,
+		URL:        rawURL,
+		Secret:     secret,
+		EventTypes: types,
+	}, nil
+}
+
+// CreateDeliveriesDTO enqueues an event for every subscription to its type that belongs to AccountID or
+// ServiceID. ServiceID is zero for events that do not belong to a service.
+type CreateDeliveriesDTO struct {
+	AccountID int64
+	ServiceID int64
+	EventID   int64
+	EventType outbox.Type
+	Body      []byte
+}
+
+type SaveResultDTO struct {
+	DeliveryID int64
+	Status     Status
+	StatusCode int
+	Error      string
+	RetryAfter time.Duration
+}
+
+type Request struct {
+	URL    string
+	Header http.Header
+	Body   []byte
+}
@@ -0,0 +1,224 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"database/sql/driver"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/maypok86/payment-api/internal/domain/outbox"
+	"github.com/maypok86/payment-api/internal/pkg/pagination"
+)
+
+var (
+	ErrNotFound         = errors.New("webhook not found")
+	ErrInvalidURL       = errors.New("webhook url is not valid")
+	ErrPrivateURL       = errors.New("webhook url points to a private address")
+	ErrNoEventTypes     = errors.New("webhook has no event types")
+	ErrInvalidOwner     = errors.New("webhook should belong to either an account or a service")
+	ErrOwnerNotFound    = errors.New("webhook owner not found")
+	ErrInvalidStatus    = errors.New("delivery status is not valid")
+	ErrUnexpectedStatus = errors.New("webhook responded with unexpected status")
+)
+
+const (
+	SignatureHeader = "X-Webhook-Signature"
+	TimestampHeader = "X-Webhook-Timestamp"
+	EventIDHeader   = "X-Webhook-Event-ID"
+	EventTypeHeader = "X-Webhook-Event-Type"
+
+	signaturePrefix = "sha256="
+)
+
+// sharedAddressSpace is the carrier-grade NAT range, it is not routable on the internet either.
+var sharedAddressSpace = &net.IPNet{IP: net.IPv4(100, 64, 0, 0), Mask: net.CIDRMask(10, 32)}
+
+// retrySchedule holds the delays before the second and later delivery attempts. A delivery that fails
+// after the last delay is dead and is not retried.
+var retrySchedule = []time.Duration{
+	10 * time.Second,
+	30 * time.Second,
+	time.Minute,
+	5 * time.Minute,
+	15 * time.Minute,
+	time.Hour,
+	3 * time.Hour,
+	6 * time.Hour,
+}
+
+type Status struct {
+	string
+}
+
+var (
+	Pending   = Status{"pending"}
+	Delivered = Status{"delivered"}
+	Dead      = Status{"dead"}
+)
+
+var statusToString = map[Status]string{
+	Pending:   "pending",
+	Delivered: "delivered",
+	Dead:      "dead",
+}
+
+var stringToStatus = map[string]Status{
+	"pending":   Pending,
+	"delivered": Delivered,
+	"dead":      Dead,
+}
+
+func ParseStatus(s string) (Status, error) {
+	if status, ok := stringToStatus[s]; ok {
+		return status, nil
+	}
+
+	return Status{}, fmt.Errorf("%w: %q", ErrInvalidStatus, s)
+}
+
+func (s Status) String() string {
+	return statusToString[s]
+}
+
+func (s *Status) Scan(value interface{}) error {
+	str, ok := value.(string)
+	if !ok {
+		return errors.New("scan source is not string")
+	}
+
+	if v, ok := stringToStatus[str]; ok {
+		*s = v
+		return nil
+	}
+
+	return errors.New("wrong value for Status")
+}
+
+func (s Status) Value() (driver.Value, error) {
+	str, ok := statusToString[s]
+	if !ok {
+		return nil, errors.New("wrong value for Status")
+	}
+
+	return str, nil
+}
+
+// Subscription receives the events of the listed types at URL. Every delivery is signed with Secret.
+// A subscription belongs to either an account or a service and receives only the events of its owner,
+// the other owner id is zero.
+type Subscription struct {
+	SubscriptionID int64
+	AccountID      int64
+	ServiceID      int64
+	URL            string
+	Secret         string
+	EventTypes     []outbox.Type
+	CreatedAt      time.Time
+}
+
+// Delivery is a single event sent to a single subscription. Body is sent as is on every attempt, so the
+// receiver gets the same payload and event id on retries.
+type Delivery struct {
+	DeliveryID     int64
+	SubscriptionID int64
+	EventID        int64
+	EventType      outbox.Type
+	Body           json.RawMessage
+	Status         Status
+	Attempts       int
+	LastStatusCode int
+	LastError      string
+	NextAttemptAt  time.Time
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+// PendingDelivery is a delivery due to be sent together with where and how to send it.
+type PendingDelivery struct {
+	Delivery
+	URL    string
+	Secret string
+}
+
+type DeliveryListParams struct {
+	Status     *Status
+	Pagination pagination.Params
+}
+
+func NewDeliveryListParams(status string, params pagination.Params) (DeliveryListParams, error) {
+	if status == "" {
+		return DeliveryListParams{Pagination: params}, nil
+	}
+
+	s, err := ParseStatus(status)
+	if err != nil {
+		return DeliveryListParams{}, err
+	}
+
+	return DeliveryListParams{
+		Status:     &s,
+		Pagination: params,
+	}, nil
+}
+
+// RetryDelay returns how long to wait before the next attempt of a delivery that failed attempts times.
+// It returns false when the retry schedule is exhausted.
+func RetryDelay(attempts int) (time.Duration, bool) {
+	if attempts < 1 || attempts > len(retrySchedule) {
+		return 0, false
+	}
+
+	return retrySchedule[attempts-1], true
+}
+
+// Sign returns the signature of a delivery body sent at timestamp. It is the hex encoded HMAC-SHA256 of
+// "<timestamp>.<body>" keyed with the subscription secret, so a receiver can reject replayed requests by
+// checking the timestamp.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+
+	return signaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify reports whether signature is a valid signature of body sent at timestamp.
+func Verify(secret string, timestamp int64, body []byte, signature string) bool {
+	return hmac.Equal([]byte(Sign(secret, timestamp, body)), []byte(signature))
+}
+
+// IsPublicIP reports whether ip is routable on the internet. Loopback, private, link-local (including the cloud
+// metadata address 169.254.169.254), carrier-grade NAT, unspecified and multicast addresses are not public, so
+// deliveries are never sent to the network of the service.
+func IsPublicIP(ip net.IP) bool {
+	return ip.IsGlobalUnicast() && !ip.IsPrivate() && !sharedAddressSpace.Contains(ip)
+}
+
+// validateURL rejects hosts that are private by name or by address. Host names that resolve to a private
+// address are refused by the sender when it connects.
+func validateURL(rawURL string) error {
+	u, err := url.ParseRequestURI(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("%w: %q", ErrInvalidURL, rawURL)
+	}
+
+	host := strings.ToLower(strings.TrimSuffix(u.Hostname(), "."))
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return fmt.Errorf("%w: %q", ErrPrivateURL, rawURL)
+	}
+
+	if ip := net.ParseIP(host); ip != nil && !IsPublicIP(ip) {
+		return fmt.Errorf("%w: %q", ErrPrivateURL, rawURL)
+	}
+
+	return nil
+}
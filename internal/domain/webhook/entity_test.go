@@ -0,0 +1,270 @@
+package webhook_test
+
+import (
+	"net"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/maypok86/payment-api/internal/domain/outbox"
+	"github.com/maypok86/payment-api/internal/domain/webhook"
+	"github.com/maypok86/payment-api/internal/pkg/pagination"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStatus_Scan(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		value   interface{}
+		want    webhook.Status
+		wantErr bool
+	}{
+		{
+			name:  "success scan",
+			value: "dead",
+			want:  webhook.Dead,
+		},
+		{
+			name:    "wrong value for Status",
+			value:   "wrong",
+			want:    webhook.Status{},
+			wantErr: true,
+		},
+		{
+			name:    "scan source is not string",
+			value:   1,
+			want:    webhook.Status{},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var status webhook.Status
+
+			err := status.Scan(tt.value)
+			require.True(t, (err != nil) == tt.wantErr)
+			require.True(t, reflect.DeepEqual(tt.want, status))
+		})
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		attempts int
+		want     time.Duration
+		wantOK   bool
+	}{
+		{attempts: 1, want: 10 * time.Second, wantOK: true},
+		{attempts: 2, want: 30 * time.Second, wantOK: true},
+		{attempts: 4, want: 5 * time.Minute, wantOK: true},
+		{attempts: 8, want: 6 * time.Hour, wantOK: true},
+		{attempts: 9},
+		{attempts: 0},
+	}
+
+	for _, tt := range tests {
+		got, ok := webhook.RetryDelay(tt.attempts)
+		require.Equal(t, tt.want, got, "attempts = %d", tt.attempts)
+		require.Equal(t, tt.wantOK, ok, "attempts = %d", tt.attempts)
+	}
+}
+
+func TestSign(t *testing.T) {
+	t.Parallel()
+
+	body := []byte(`{"event_id":1}`)
+	signature := webhook.Sign("secret", 1668254400, body)
+
+	// echo -n '1668254400.{"event_id":1}' | openssl dgst -sha256 -hmac secret
+	require.Equal(t, "sha256=675148a8767c63eedeb218d863968eaafd6f278611a8f5017378db78998d1d1b", signature)
+	require.True(t, webhook.Verify("secret", 1668254400, body, signature))
+	require.False(t, webhook.Verify("other", 1668254400, body, signature))
+	require.False(t, webhook.Verify("secret", 1668254401, body, signature))
+	require.False(t, webhook.Verify("secret", 1668254400, []byte(`{"event_id":2}`), signature))
+}
+
+func TestNewCreateSubscriptionDTO(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		accountID  int64
+		serviceID  int64
+		url        string
+		eventTypes []string
+		want       webhook.CreateSubscriptionDTO
+		wantedErr  error
+	}{
+		{
+			name:       "success",
+			accountID:  1,
+			url:        "https://partner.example.com/hooks",
+			eventTypes: []string{"order_paid", "order_cancelled", "order_paid"},
+			want: webhook.CreateSubscriptionDTO{
+				AccountID:  1,
+				URL:        "https://partner.example.com/hooks",
+				Secret:     "secret",
+				EventTypes: []outbox.Type{outbox.OrderPaid, outbox.OrderCancelled},
+			},
+		},
+		{
+			name:       "service owner",
+			serviceID:  2,
+			url:        "https://partner.example.com/hooks",
+			eventTypes: []string{"order_paid"},
+			want: webhook.CreateSubscriptionDTO{
+				ServiceID:  2,
+				URL:        "https://partner.example.com/hooks",
+				Secret:     "secret",
+				EventTypes: []outbox.Type{outbox.OrderPaid},
+			},
+		},
+		{
+			name:       "no owner",
+			url:        "https://partner.example.com/hooks",
+			eventTypes: []string{"order_paid"},
+			wantedErr:  webhook.ErrInvalidOwner,
+		},
+		{
+			name:       "two owners",
+			accountID:  1,
+			serviceID:  2,
+			url:        "https://partner.example.com/hooks",
+			eventTypes: []string{"order_paid"},
+			wantedErr:  webhook.ErrInvalidOwner,
+		},
+		{
+			name:       "relative url",
+			accountID:  1,
+			url:        "/hooks",
+			eventTypes: []string{"order_paid"},
+			wantedErr:  webhook.ErrInvalidURL,
+		},
+		{
+			name:       "unsupported scheme",
+			accountID:  1,
+			url:        "ftp://partner.example.com/hooks",
+			eventTypes: []string{"order_paid"},
+			wantedErr:  webhook.ErrInvalidURL,
+		},
+		{
+			name:       "loopback address",
+			accountID:  1,
+			url:        "http://127.0.0.1:8080/hooks",
+			eventTypes: []string{"order_paid"},
+			wantedErr:  webhook.ErrPrivateURL,
+		},
+		{
+			name:       "localhost",
+			accountID:  1,
+			url:        "http://LocalHost./hooks",
+			eventTypes: []string{"order_paid"},
+			wantedErr:  webhook.ErrPrivateURL,
+		},
+		{
+			name:       "cloud metadata address",
+			accountID:  1,
+			url:        "http://169.254.169.254/latest/meta-data",
+			eventTypes: []string{"order_paid"},
+			wantedErr:  webhook.ErrPrivateURL,
+		},
+		{
+			name:       "private address",
+			accountID:  1,
+			url:        "https://10.0.0.5/hooks",
+			eventTypes: []string{"order_paid"},
+			wantedErr:  webhook.ErrPrivateURL,
+		},
+		{
+			name:       "private ipv6 address",
+			accountID:  1,
+			url:        "https://[fd00:ec2::254]/hooks",
+			eventTypes: []string{"order_paid"},
+			wantedErr:  webhook.ErrPrivateURL,
+		},
+		{
+			name:       "ipv4 mapped loopback address",
+			accountID:  1,
+			url:        "https://[::ffff:127.0.0.1]/hooks",
+			eventTypes: []string{"order_paid"},
+			wantedErr:  webhook.ErrPrivateURL,
+		},
+		{
+			name:      "no event types",
+			accountID: 1,
+			url:       "https://partner.example.com/hooks",
+			wantedErr: webhook.ErrNoEventTypes,
+		},
+		{
+			name:       "unknown event type",
+			accountID:  1,
+			url:        "https://partner.example.com/hooks",
+			eventTypes: []string{"order_lost"},
+			wantedErr:  outbox.ErrInvalidType,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := webhook.NewCreateSubscriptionDTO(tt.accountID, tt.serviceID, tt.url, "secret", tt.eventTypes)
+			if tt.wantedErr != nil {
+				require.ErrorIs(t, err, tt.wantedErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestNewDeliveryListParams(t *testing.T) {
+	t.Parallel()
+
+	params := pagination.Params{Limit: 10}
+
+	got, err := webhook.NewDeliveryListParams("", params)
+	require.NoError(t, err)
+	require.Equal(t, webhook.DeliveryListParams{Pagination: params}, got)
+
+	got, err = webhook.NewDeliveryListParams("dead", params)
+	require.NoError(t, err)
+	require.Equal(t, webhook.Dead, *got.Status)
+
+	_, err = webhook.NewDeliveryListParams("lost", params)
+	require.ErrorIs(t, err, webhook.ErrInvalidStatus)
+}
+
+func TestIsPublicIP(t *testing.T) {
+	t.Parallel()
+
+	for ip, want := range map[string]bool{
+		"93.184.216.34":   true,
+		"2606:4700::1111": true,
+		"127.0.0.1":       false,
+		"::1":             false,
+		"10.1.2.3":        false,
+		"172.16.0.1":      false,
+		"192.168.1.1":     false,
+		"169.254.169.254": false,
+		"100.64.0.1":      false,
+		"0.0.0.0":         false,
+		"224.0.0.1":       false,
+		"fe80::1":         false,
+		"fd00:ec2::254":   false,
+	} {
+		require.Equal(t, want, webhook.IsPublicIP(net.ParseIP(ip)), ip)
+	}
+}
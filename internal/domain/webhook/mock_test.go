@@ -0,0 +1,194 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: service.go
+
+// Package webhook_test is a generated GoMock package.
+package webhook_test
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+	webhook "github.com/maypok86/payment-api/internal/domain/webhook"
+)
+
+// MockRepository is a mock of Repository interface.
+type MockRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockRepositoryMockRecorder
+}
+
+// MockRepositoryMockRecorder is the mock recorder for MockRepository.
+type MockRepositoryMockRecorder struct {
+	mock *MockRepository
+}
+
+// NewMockRepository creates a new mock instance.
+func NewMockRepository(ctrl *gomock.Controller) *MockRepository {
+	mock := &MockRepository{ctrl: ctrl}
+	mock.recorder = &MockRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockRepository) EXPECT() *MockRepositoryMockRecorder {
+	return m.recorder
+}
+
+// ClaimDeliveries mocks base method.
+func (m *MockRepository) ClaimDeliveries(ctx context.Context, limit uint64, timeout time.Duration) ([]webhook.PendingDelivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimDeliveries", ctx, limit, timeout)
+	ret0, _ := ret[0].([]webhook.PendingDelivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClaimDeliveries indicates an expected call of ClaimDeliveries.
+func (mr *MockRepositoryMockRecorder) ClaimDeliveries(ctx, limit, timeout interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimDeliveries", reflect.TypeOf((*MockRepository)(nil).ClaimDeliveries), ctx, limit, timeout)
+}
+
+// CreateDeliveries mocks base method.
+func (m *MockRepository) CreateDeliveries(ctx context.Context, dto webhook.CreateDeliveriesDTO) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateDeliveries", ctx, dto)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateDeliveries indicates an expected call of CreateDeliveries.
+func (mr *MockRepositoryMockRecorder) CreateDeliveries(ctx, dto interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateDeliveries", reflect.TypeOf((*MockRepository)(nil).CreateDeliveries), ctx, dto)
+}
+
+// CreateSubscription mocks base method.
+func (m *MockRepository) CreateSubscription(ctx context.Context, dto webhook.CreateSubscriptionDTO) (webhook.Subscription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateSubscription", ctx, dto)
+	ret0, _ := ret[0].(webhook.Subscription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateSubscription indicates an expected call of CreateSubscription.
+func (mr *MockRepositoryMockRecorder) CreateSubscription(ctx, dto interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSubscription", reflect.TypeOf((*MockRepository)(nil).CreateSubscription), ctx, dto)
+}
+
+// DeleteSubscription mocks base method.
+func (m *MockRepository) DeleteSubscription(ctx context.Context, subscriptionID int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteSubscription", ctx, subscriptionID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteSubscription indicates an expected call of DeleteSubscription.
+func (mr *MockRepositoryMockRecorder) DeleteSubscription(ctx, subscriptionID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteSubscription", reflect.TypeOf((*MockRepository)(nil).DeleteSubscription), ctx, subscriptionID)
+}
+
+// GetDeliveries mocks base method.
+func (m *MockRepository) GetDeliveries(ctx context.Context, subscriptionID int64, listParams webhook.DeliveryListParams) ([]webhook.Delivery, int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDeliveries", ctx, subscriptionID, listParams)
+	ret0, _ := ret[0].([]webhook.Delivery)
+	ret1, _ := ret[1].(int)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetDeliveries indicates an expected call of GetDeliveries.
+func (mr *MockRepositoryMockRecorder) GetDeliveries(ctx, subscriptionID, listParams interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDeliveries", reflect.TypeOf((*MockRepository)(nil).GetDeliveries), ctx, subscriptionID, listParams)
+}
+
+// GetSubscriptionByID mocks base method.
+func (m *MockRepository) GetSubscriptionByID(ctx context.Context, subscriptionID int64) (webhook.Subscription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSubscriptionByID", ctx, subscriptionID)
+	ret0, _ := ret[0].(webhook.Subscription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSubscriptionByID indicates an expected call of GetSubscriptionByID.
+func (mr *MockRepositoryMockRecorder) GetSubscriptionByID(ctx, subscriptionID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSubscriptionByID", reflect.TypeOf((*MockRepository)(nil).GetSubscriptionByID), ctx, subscriptionID)
+}
+
+// GetSubscriptions mocks base method.
+func (m *MockRepository) GetSubscriptions(ctx context.Context) ([]webhook.Subscription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSubscriptions", ctx)
+	ret0, _ := ret[0].([]webhook.Subscription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSubscriptions indicates an expected call of GetSubscriptions.
+func (mr *MockRepositoryMockRecorder) GetSubscriptions(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSubscriptions", reflect.TypeOf((*MockRepository)(nil).GetSubscriptions), ctx)
+}
+
+// SaveResult mocks base method.
+func (m *MockRepository) SaveResult(ctx context.Context, dto webhook.SaveResultDTO) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveResult", ctx, dto)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveResult indicates an expected call of SaveResult.
+func (mr *MockRepositoryMockRecorder) SaveResult(ctx, dto interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveResult", reflect.TypeOf((*MockRepository)(nil).SaveResult), ctx, dto)
+}
+
+// MockSender is a mock of Sender interface.
+type MockSender struct {
+	ctrl     *gomock.Controller
+	recorder *MockSenderMockRecorder
+}
+
+// MockSenderMockRecorder is the mock recorder for MockSender.
+type MockSenderMockRecorder struct {
+	mock *MockSender
+}
+
+// NewMockSender creates a new mock instance.
+func NewMockSender(ctrl *gomock.Controller) *MockSender {
+	mock := &MockSender{ctrl: ctrl}
+	mock.recorder = &MockSenderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSender) EXPECT() *MockSenderMockRecorder {
+	return m.recorder
+}
+
+// Send mocks base method.
+func (m *MockSender) Send(ctx context.Context, request webhook.Request) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Send", ctx, request)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Send indicates an expected call of Send.
+func (mr *MockSenderMockRecorder) Send(ctx, request interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Send", reflect.TypeOf((*MockSender)(nil).Send), ctx, request)
+}
@@ -0,0 +1,222 @@
+package webhook
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/maypok86/payment-api/internal/domain/outbox"
+	"go.uber.org/zap"
+)
+
+//go:generate mockgen -source=service.go -destination=mock_test.go -package=webhook_test
+
+const secretSize = 32
+
+type Repository interface {
+	CreateSubscription(ctx context.Context, dto CreateSubscriptionDTO) (Subscription, error)
+	GetSubscriptions(ctx context.Context) ([]Subscription, error)
+	GetSubscriptionByID(ctx context.Context, subscriptionID int64) (Subscription, error)
+	DeleteSubscription(ctx context.Context, subscriptionID int64) error
+	CreateDeliveries(ctx context.Context, dto CreateDeliveriesDTO) (int, error)
+	ClaimDeliveries(ctx context.Context, limit uint64, timeout time.Duration) ([]PendingDelivery, error)
+	SaveResult(ctx context.Context, dto SaveResultDTO) error
+	GetDeliveries(
+		ctx context.Context,
+		subscriptionID int64,
+		listParams DeliveryListParams,
+	) ([]Delivery, int, error)
+}
+
+// Sender sends a delivery request and returns the response status code.
+type Sender interface {
+	Send(ctx context.Context, request Request) (int, error)
+}
+
+type Service struct {
+	repository   Repository
+	sender       Sender
+	claimTimeout time.Duration
+	logger       *zap.Logger
+}
+
+func NewService(repository Repository, sender Sender, claimTimeout time.Duration, logger *zap.Logger) *Service {
+	return &Service{
+		repository:   repository,
+		sender:       sender,
+		claimTimeout: claimTimeout,
+		logger:       logger,
+	}
+}
+
+func (s *Service) CreateSubscription(ctx context.Context, dto CreateSubscriptionDTO) (Subscription, error) {
+	if dto.Secret == "" {
+		secret, err := generateSecret()
+		if err != nil {
+			return Subscription{}, fmt.Errorf("create subscription: %w", err)
+		}
+		dto.Secret = secret
+	}
+
+	subscription, err := s.repository.CreateSubscription(ctx, dto)
+	if err != nil {
+		return Subscription{}, fmt.Errorf("create subscription: %w", err)
+	}
+
+	return subscription, nil
+}
+
+func (s *Service) GetSubscriptions(ctx context.Context) ([]Subscription, error) {
+	subscriptions, err := s.repository.GetSubscriptions(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("get subscriptions: %w", err)
+	}
+
+	return subscriptions, nil
+}
+
+func (s *Service) GetSubscriptionByID(ctx context.Context, subscriptionID int64) (Subscription, error) {
+	subscription, err := s.repository.GetSubscriptionByID(ctx, subscriptionID)
+	if err != nil {
+		return Subscription{}, fmt.Errorf("get subscription by id: %w", err)
+	}
+
+	return subscription, nil
+}
+
+func (s *Service) DeleteSubscription(ctx context.Context, subscriptionID int64) error {
+	if err := s.repository.DeleteSubscription(ctx, subscriptionID); err != nil {
+		return fmt.Errorf("delete subscription: %w", err)
+	}
+
+	return nil
+}
+
+func (s *Service) GetDeliveries(
+	ctx context.Context,
+	subscriptionID int64,
+	listParams DeliveryListParams,
+) ([]Delivery, int, error) {
+	if _, err := s.repository.GetSubscriptionByID(ctx, subscriptionID); err != nil {
+		return nil, 0, fmt.Errorf("get deliveries: %w", err)
+	}
+
+	deliveries, count, err := s.repository.GetDeliveries(ctx, subscriptionID, listParams)
+	if err != nil {
+		return nil, 0, fmt.Errorf("get deliveries: %w", err)
+	}
+
+	return deliveries, count, nil
+}
+
+// Publish enqueues a delivery of the event for every subscription to its type that belongs to an owner of the
+// event. It is called by the outbox relay, and enqueuing the same event twice is a no-op, so an event published
+// again is not duplicated.
+func (s *Service) Publish(ctx context.Context, event outbox.Event) error {
+	accountID, serviceID, err := event.Owners()
+	if err != nil {
+		return fmt.Errorf("get event owners: %w", err)
+	}
+
+	body, err := json.Marshal(outbox.NewMessage(event))
+	if err != nil {
+		return fmt.Errorf("marshal event: %w", err)
+	}
+
+	if _, err := s.repository.CreateDeliveries(ctx, CreateDeliveriesDTO{
+		AccountID: accountID,
+		ServiceID: serviceID,
+		EventID:   event.EventID,
+		EventType: event.Type,
+		Body:      body,
+	}); err != nil {
+		return fmt.Errorf("enqueue webhook deliveries: %w", err)
+	}
+
+	return nil
+}
+
+// DeliverPending sends up to limit due deliveries and returns how many of them were attempted. A failed delivery
+// is retried by the retry schedule and becomes dead once the schedule is exhausted.
+//
+// The deliveries are claimed for the claim timeout in a statement of their own, sent outside of any transaction
+// and their results are saved one by one, so no row lock is held while a receiver answers. A delivery whose
+// result is not saved before its claim runs out is sent again.
+func (s *Service) DeliverPending(ctx context.Context, limit uint64) (int, error) {
+	deliveries, err := s.repository.ClaimDeliveries(ctx, limit, s.claimTimeout)
+	if err != nil {
+		return 0, fmt.Errorf("deliver pending: %w", err)
+	}
+
+	for _, delivery := range deliveries {
+		if err := s.repository.SaveResult(ctx, s.deliver(ctx, delivery)); err != nil {
+			return 0, fmt.Errorf("deliver pending: %w", err)
+		}
+	}
+
+	return len(deliveries), nil
+}
+
+func (s *Service) deliver(ctx context.Context, delivery PendingDelivery) SaveResultDTO {
+	timestamp := time.Now().Unix()
+
+	header := make(http.Header)
+	header.Set("Content-Type", "application/json")
+	header.Set(EventIDHeader, strconv.FormatInt(delivery.EventID, 10))
+	header.Set(EventTypeHeader, delivery.EventType.String())
+	header.Set(TimestampHeader, strconv.FormatInt(timestamp, 10))
+	header.Set(SignatureHeader, Sign(delivery.Secret, timestamp, delivery.Body))
+
+	statusCode, err := s.sender.Send(ctx, Request{
+		URL:    delivery.URL,
+		Header: header,
+		Body:   delivery.Body,
+	})
+	if err == nil && (statusCode < http.StatusOK || statusCode >= http.StatusMultipleChoices) {
+		err = fmt.Errorf("%w: %d", ErrUnexpectedStatus, statusCode)
+	}
+
+	result := SaveResultDTO{
+		DeliveryID: delivery.DeliveryID,
+		Status:     Delivered,
+		StatusCode: statusCode,
+	}
+	if err == nil {
+		return result
+	}
+
+	attempts := delivery.Attempts + 1
+	s.logger.Warn(
+		"deliver webhook",
+		zap.Int64("delivery_id", delivery.DeliveryID),
+		zap.Int64("subscription_id", delivery.SubscriptionID),
+		zap.Int("attempts", attempts),
+		zap.Error(err),
+	)
+
+	result.Error = err.Error()
+	retryAfter, ok := RetryDelay(attempts)
+	if !ok {
+		result.Status = Dead
+		return result
+	}
+
+	result.Status = Pending
+	result.RetryAfter = retryAfter
+
+	return result
+}
+
+func generateSecret() (string, error) {
+	b := make([]byte, secretSize)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("generate secret: %w", err)
+	}
+
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,449 @@
+package webhook_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/maypok86/payment-api/internal/domain/outbox"
+	"github.com/maypok86/payment-api/internal/domain/webhook"
+	"github.com/maypok86/payment-api/internal/pkg/logger"
+	"github.com/maypok86/payment-api/internal/pkg/pagination"
+	"github.com/maypok86/payment-api/internal/publisher"
+	"github.com/stretchr/testify/require"
+)
+
+const claimTimeout = 10 * time.Minute
+
+func mockService(t *testing.T) (*webhook.Service, *MockRepository) {
+	t.Helper()
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	l := logger.New(os.Stdout, "debug")
+
+	repository := NewMockRepository(mockCtrl)
+	sender := publisher.NewHTTPSender(time.Second, publisher.WithPrivateAddresses())
+	service := webhook.NewService(repository, sender, claimTimeout, l)
+
+	return service, repository
+}
+
+func TestService_CreateSubscription(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	dto := webhook.CreateSubscriptionDTO{
+		URL:        "https://partner.example.com/hooks",
+		Secret:     "secret",
+		EventTypes: []outbox.Type{outbox.OrderPaid},
+	}
+	subscription := webhook.Subscription{
+		SubscriptionID: 1,
+		URL:            dto.URL,
+		Secret:         dto.Secret,
+		EventTypes:     dto.EventTypes,
+		CreatedAt:      time.Now(),
+	}
+	repositoryErr := errors.New("repository error")
+
+	type mockBehavior func(r *MockRepository)
+
+	tests := []struct {
+		name      string
+		dto       webhook.CreateSubscriptionDTO
+		mock      mockBehavior
+		want      webhook.Subscription
+		wantedErr error
+	}{
+		{
+			name: "success create subscription",
+			dto:  dto,
+			mock: func(r *MockRepository) {
+				r.EXPECT().CreateSubscription(ctx, dto).Return(subscription, nil)
+			},
+			want: subscription,
+		},
+		{
+			name: "secret is generated",
+			dto: webhook.CreateSubscriptionDTO{
+				URL:        dto.URL,
+				EventTypes: dto.EventTypes,
+			},
+			mock: func(r *MockRepository) {
+				r.EXPECT().CreateSubscription(ctx, gomock.Any()).DoAndReturn(
+					func(_ context.Context, dto webhook.CreateSubscriptionDTO) (webhook.Subscription, error) {
+						require.Len(t, dto.Secret, 64)
+
+						return subscription, nil
+					},
+				)
+			},
+			want: subscription,
+		},
+		{
+			name: "repository error",
+			dto:  dto,
+			mock: func(r *MockRepository) {
+				r.EXPECT().CreateSubscription(ctx, dto).Return(webhook.Subscription{}, repositoryErr)
+			},
+			wantedErr: repositoryErr,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			service, repository := mockService(t)
+
+			tt.mock(repository)
+
+			got, err := service.CreateSubscription(ctx, tt.dto)
+			if tt.wantedErr != nil {
+				require.ErrorIs(t, err, tt.wantedErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestService_GetDeliveries(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	subscriptionID := int64(1)
+	listParams := webhook.DeliveryListParams{Pagination: pagination.Params{Limit: 10}}
+	deliveries := []webhook.Delivery{
+		{
+			DeliveryID:     1,
+			SubscriptionID: subscriptionID,
+			EventID:        1,
+			EventType:      outbox.OrderPaid,
+			Status:         webhook.Delivered,
+			Attempts:       1,
+			LastStatusCode: http.StatusOK,
+		},
+	}
+	repositoryErr := errors.New("repository error")
+
+	type mockBehavior func(r *MockRepository)
+
+	tests := []struct {
+		name      string
+		mock      mockBehavior
+		want      []webhook.Delivery
+		wantCount int
+		wantedErr error
+	}{
+		{
+			name: "success get deliveries",
+			mock: func(r *MockRepository) {
+				r.EXPECT().GetSubscriptionByID(ctx, subscriptionID).Return(webhook.Subscription{}, nil)
+				r.EXPECT().GetDeliveries(ctx, subscriptionID, listParams).Return(deliveries, 1, nil)
+			},
+			want:      deliveries,
+			wantCount: 1,
+		},
+		{
+			name: "subscription not found",
+			mock: func(r *MockRepository) {
+				r.EXPECT().GetSubscriptionByID(ctx, subscriptionID).Return(webhook.Subscription{}, webhook.ErrNotFound)
+			},
+			wantedErr: webhook.ErrNotFound,
+		},
+		{
+			name: "repository error",
+			mock: func(r *MockRepository) {
+				r.EXPECT().GetSubscriptionByID(ctx, subscriptionID).Return(webhook.Subscription{}, nil)
+				r.EXPECT().GetDeliveries(ctx, subscriptionID, listParams).Return(nil, 0, repositoryErr)
+			},
+			wantedErr: repositoryErr,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			service, repository := mockService(t)
+
+			tt.mock(repository)
+
+			got, count, err := service.GetDeliveries(ctx, subscriptionID, listParams)
+			if tt.wantedErr != nil {
+				require.ErrorIs(t, err, tt.wantedErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+			require.Equal(t, tt.wantCount, count)
+		})
+	}
+}
+
+func TestService_Publish(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	event := outbox.Event{
+		EventID:       1,
+		Type:          outbox.OrderPaid,
+		AggregateType: outbox.OrderAggregate,
+		AggregateID:   1,
+		Payload:       []byte(`{"order_id":1,"account_id":7,"service_id":3}`),
+		CreatedAt:     time.Date(2022, 11, 13, 12, 0, 0, 0, time.UTC),
+	}
+	body, err := json.Marshal(outbox.NewMessage(event))
+	require.NoError(t, err)
+
+	dto := webhook.CreateDeliveriesDTO{
+		AccountID: 7,
+		ServiceID: 3,
+		EventID:   event.EventID,
+		EventType: event.Type,
+		Body:      body,
+	}
+	repositoryErr := errors.New("repository error")
+
+	type mockBehavior func(r *MockRepository)
+
+	tests := []struct {
+		name      string
+		mock      mockBehavior
+		wantedErr error
+	}{
+		{
+			name: "success publish",
+			mock: func(r *MockRepository) {
+				r.EXPECT().CreateDeliveries(ctx, dto).Return(2, nil)
+			},
+		},
+		{
+			name: "no subscriptions",
+			mock: func(r *MockRepository) {
+				r.EXPECT().CreateDeliveries(ctx, dto).Return(0, nil)
+			},
+		},
+		{
+			name: "repository error",
+			mock: func(r *MockRepository) {
+				r.EXPECT().CreateDeliveries(ctx, dto).Return(0, repositoryErr)
+			},
+			wantedErr: repositoryErr,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			service, repository := mockService(t)
+
+			tt.mock(repository)
+
+			err := service.Publish(ctx, event)
+			if tt.wantedErr != nil {
+				require.ErrorIs(t, err, tt.wantedErr)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+type receivedRequest struct {
+	header http.Header
+	body   []byte
+}
+
+// newReceiver starts a webhook receiver that answers with statusCode and sends every request it gets to requests.
+func newReceiver(t *testing.T, statusCode int) (*httptest.Server, chan receivedRequest) {
+	t.Helper()
+
+	requests := make(chan receivedRequest, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+
+		requests <- receivedRequest{header: r.Header, body: body}
+
+		w.WriteHeader(statusCode)
+	}))
+	t.Cleanup(server.Close)
+
+	return server, requests
+}
+
+func TestService_DeliverPending(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	limit := uint64(10)
+	secret := "secret"
+	body := []byte(`{"event_id":1,"type":"order_paid"}`)
+	repositoryErr := errors.New("repository error")
+
+	newPendingDelivery := func(url string, attempts int) webhook.PendingDelivery {
+		return webhook.PendingDelivery{
+			Delivery: webhook.Delivery{
+				DeliveryID:     1,
+				SubscriptionID: 1,
+				EventID:        1,
+				EventType:      outbox.OrderPaid,
+				Body:           body,
+				Status:         webhook.Pending,
+				Attempts:       attempts,
+			},
+			URL:    url,
+			Secret: secret,
+		}
+	}
+	unexpectedStatus := fmt.Sprintf("%s: %d", webhook.ErrUnexpectedStatus, http.StatusInternalServerError)
+
+	type mockBehavior func(r *MockRepository, url string)
+
+	tests := []struct {
+		name       string
+		statusCode int
+		mock       mockBehavior
+		want       int
+		wantSent   bool
+		wantedErr  error
+	}{
+		{
+			name:       "success deliver",
+			statusCode: http.StatusOK,
+			mock: func(r *MockRepository, url string) {
+				r.EXPECT().ClaimDeliveries(ctx, limit, claimTimeout).Return(
+					[]webhook.PendingDelivery{newPendingDelivery(url, 0)},
+					nil,
+				)
+				r.EXPECT().SaveResult(ctx, webhook.SaveResultDTO{
+					DeliveryID: 1,
+					Status:     webhook.Delivered,
+					StatusCode: http.StatusOK,
+				}).Return(nil)
+			},
+			want:     1,
+			wantSent: true,
+		},
+		{
+			name:       "failed delivery is retried",
+			statusCode: http.StatusInternalServerError,
+			mock: func(r *MockRepository, url string) {
+				r.EXPECT().ClaimDeliveries(ctx, limit, claimTimeout).Return(
+					[]webhook.PendingDelivery{newPendingDelivery(url, 1)},
+					nil,
+				)
+				r.EXPECT().SaveResult(ctx, webhook.SaveResultDTO{
+					DeliveryID: 1,
+					Status:     webhook.Pending,
+					StatusCode: http.StatusInternalServerError,
+					Error:      unexpectedStatus,
+					RetryAfter: 30 * time.Second,
+				}).Return(nil)
+			},
+			want:     1,
+			wantSent: true,
+		},
+		{
+			name:       "delivery is dead after the last retry",
+			statusCode: http.StatusInternalServerError,
+			mock: func(r *MockRepository, url string) {
+				r.EXPECT().ClaimDeliveries(ctx, limit, claimTimeout).Return(
+					[]webhook.PendingDelivery{newPendingDelivery(url, 8)},
+					nil,
+				)
+				r.EXPECT().SaveResult(ctx, webhook.SaveResultDTO{
+					DeliveryID: 1,
+					Status:     webhook.Dead,
+					StatusCode: http.StatusInternalServerError,
+					Error:      unexpectedStatus,
+				}).Return(nil)
+			},
+			want:     1,
+			wantSent: true,
+		},
+		{
+			name: "no pending deliveries",
+			mock: func(r *MockRepository, url string) {
+				r.EXPECT().ClaimDeliveries(ctx, limit, claimTimeout).Return(nil, nil)
+			},
+		},
+		{
+			name: "claim deliveries error",
+			mock: func(r *MockRepository, url string) {
+				r.EXPECT().ClaimDeliveries(ctx, limit, claimTimeout).Return(nil, repositoryErr)
+			},
+			wantedErr: repositoryErr,
+		},
+		{
+			name:       "save result error",
+			statusCode: http.StatusOK,
+			mock: func(r *MockRepository, url string) {
+				r.EXPECT().ClaimDeliveries(ctx, limit, claimTimeout).Return(
+					[]webhook.PendingDelivery{newPendingDelivery(url, 0)},
+					nil,
+				)
+				r.EXPECT().SaveResult(ctx, gomock.Any()).Return(repositoryErr)
+			},
+			wantedErr: repositoryErr,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			server, requests := newReceiver(t, tt.statusCode)
+			service, repository := mockService(t)
+
+			tt.mock(repository, server.URL)
+
+			got, err := service.DeliverPending(ctx, limit)
+			if tt.wantedErr != nil {
+				require.ErrorIs(t, err, tt.wantedErr)
+			}
+			require.Equal(t, tt.want, got)
+
+			if !tt.wantSent {
+				return
+			}
+
+			request := <-requests
+			require.Equal(t, body, request.body)
+			require.Equal(t, "application/json", request.header.Get("Content-Type"))
+			require.Equal(t, "1", request.header.Get(webhook.EventIDHeader))
+			require.Equal(t, "order_paid", request.header.Get(webhook.EventTypeHeader))
+
+			timestamp, err := strconv.ParseInt(request.header.Get(webhook.TimestampHeader), 10, 64)
+			require.NoError(t, err)
+			require.True(t, webhook.Verify(secret, timestamp, request.body, request.header.Get(webhook.SignatureHeader)))
+		})
+	}
+}
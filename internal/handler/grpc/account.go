@@ -0,0 +1,128 @@
+package grpc
+
+import (
+	"context"
+
+	paymentv1 "github.com/maypok86/payment-api/api/proto/payment/v1"
+	"github.com/maypok86/payment-api/internal/domain/account"
+	"github.com/maypok86/payment-api/internal/domain/currency"
+	"go.uber.org/zap"
+)
+
+type AccountHandler struct {
+	paymentv1.UnimplementedAccountServiceServer
+	baseHandler
+	service AccountService
+}
+
+func NewAccountHandler(service AccountService, logger *zap.Logger) *AccountHandler {
+	return &AccountHandler{
+		baseHandler: baseHandler{logger: logger},
+		service:     service,
+	}
+}
+
+func (h *AccountHandler) GetBalance(
+	ctx context.Context,
+	request *paymentv1.GetBalanceRequest,
+) (*paymentv1.GetBalanceResponse, error) {
+	const message = "Get balance error"
+
+	if err := positive("account_id", request.GetAccountId()); err != nil {
+		return nil, h.error(err, message)
+	}
+
+	cur, err := parseCurrency(request.GetCurrency())
+	if err != nil {
+		return nil, h.error(err, message)
+	}
+
+	entity, err := h.service.GetBalanceByID(ctx, request.GetAccountId(), cur)
+	if err != nil {
+		return nil, h.error(err, message)
+	}
+
+	return &paymentv1.GetBalanceResponse{
+		Available:   entity.Available,
+		Reserved:    entity.Reserved,
+		Total:       entity.Total(),
+		CreditLimit: entity.CreditLimit,
+		Currency:    entity.Currency.String(),
+	}, nil
+}
+
+func (h *AccountHandler) AddBalance(
+	ctx context.Context,
+	request *paymentv1.AddBalanceRequest,
+) (*paymentv1.AddBalanceResponse, error) {
+	const message = "Add balance error"
+
+	if err := validate(
+		positive("account_id", request.GetAccountId()),
+		nonNegative("amount", request.GetAmount()),
+	); err != nil {
+		return nil, h.error(err, message)
+	}
+
+	cur, err := parseCurrency(request.GetCurrency())
+	if err != nil {
+		return nil, h.error(err, message)
+	}
+
+	balance, err := h.service.AddBalance(ctx, account.AddBalanceDTO{
+		AccountID: request.GetAccountId(),
+		Amount:    request.GetAmount(),
+		Currency:  cur,
+	})
+	if err != nil {
+		return nil, h.error(err, message)
+	}
+
+	return &paymentv1.AddBalanceResponse{
+		Balance: balance,
+	}, nil
+}
+
+func (h *AccountHandler) TransferBalance(
+	ctx context.Context,
+	request *paymentv1.TransferBalanceRequest,
+) (*paymentv1.TransferBalanceResponse, error) {
+	const message = "Transfer balance error"
+
+	if err := validate(
+		positive("sender_id", request.GetSenderId()),
+		positive("receiver_id", request.GetReceiverId()),
+		positive("amount", request.GetAmount()),
+	); err != nil {
+		return nil, h.error(err, message)
+	}
+
+	cur, err := parseCurrency(request.GetCurrency())
+	if err != nil {
+		return nil, h.error(err, message)
+	}
+
+	var receiverCurrency currency.Currency
+	if code := request.GetReceiverCurrency(); code != "" {
+		receiverCurrency, err = currency.Parse(code)
+		if err != nil {
+			return nil, h.error(err, message)
+		}
+	}
+
+	senderBalance, receiverBalance, err := h.service.TransferBalance(ctx, account.TransferBalanceDTO{
+		SenderID:         request.GetSenderId(),
+		ReceiverID:       request.GetReceiverId(),
+		Amount:           request.GetAmount(),
+		Currency:         cur,
+		ReceiverCurrency: receiverCurrency,
+	})
+	if err != nil {
+		return nil, h.error(err, message)
+	}
+
+	return &paymentv1.TransferBalanceResponse{
+		SenderBalance:   senderBalance,
+		ReceiverBalance: receiverBalance,
+	}, nil
+}
@@ -0,0 +1,178 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/maypok86/payment-api/internal/domain/account"
+	"github.com/maypok86/payment-api/internal/domain/currency"
+	"github.com/maypok86/payment-api/internal/domain/order"
+	"github.com/maypok86/payment-api/internal/domain/report"
+	"github.com/maypok86/payment-api/internal/domain/transaction"
+	"go.uber.org/zap"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	errorDomain = "payment-api"
+
+	// InsufficientFundsReason is the ErrorInfo reason of a FailedPrecondition status caused by a lack of funds.
+	// Its metadata has the available balance, the credit limit and the currency of the account.
+	InsufficientFundsReason = "INSUFFICIENT_FUNDS"
+)
+
+var errInvalidRequest = errors.New("invalid request")
+
+// Code returns the status code of a domain error. Unknown errors are internal.
+func Code(err error) codes.Code {
+	var fundsErr *account.InsufficientFundsError
+
+	switch {
+	case errors.Is(err, errInvalidRequest),
+		errors.Is(err, currency.ErrUnsupported),
+		errors.Is(err, currency.ErrInvalidAmount),
+		errors.Is(err, account.ErrRateNotFound),
+		errors.Is(err, account.ErrAmountTooSmall),
+		errors.Is(err, account.ErrEmptyBatch),
+		errors.Is(err, account.ErrBatchTooLarge),
+		errors.Is(err, report.ErrUnsupportedFormat),
+		errors.Is(err, transaction.ErrInvalidSortParam),
+		errors.Is(err, transaction.ErrInvalidDirectionParam),
+		errors.Is(err, transaction.ErrCursorMismatch),
+		errors.Is(err, transaction.ErrInvalidTypeParam),
+		errors.Is(err, transaction.ErrInvalidFlowParam),
+		errors.Is(err, transaction.ErrInvalidDateParam),
+		errors.Is(err, transaction.ErrInvalidDateRange),
+		errors.Is(err, transaction.ErrInvalidAmountParam),
+		errors.Is(err, transaction.ErrInvalidAmountRange),
+		errors.Is(err, transaction.ErrInvalidCounterpartyParam),
+		errors.Is(err, transaction.ErrInvalidDescriptionParam),
+		errors.Is(err, transaction.ErrInvalidFormatParam):
+		return codes.InvalidArgument
+	case errors.Is(err, account.ErrNotFound),
+		errors.Is(err, order.ErrNotFound),
+		errors.Is(err, order.ErrAccountNotFound),
+		errors.Is(err, order.ErrServiceNotFound),
+		errors.Is(err, transaction.ErrAccountNotFound),
+		errors.Is(err, report.ErrNotFound),
+		errors.Is(err, report.ErrJobNotFound),
+		errors.Is(err, report.ErrIsNotAvailable):
+		return codes.NotFound
+	case errors.Is(err, account.ErrAlreadyExist),
+		errors.Is(err, order.ErrAlreadyExist):
+		return codes.AlreadyExists
+	case errors.As(err, &fundsErr),
+		errors.Is(err, account.ErrFrozen),
+		errors.Is(err, account.ErrClosed),
+		errors.Is(err, account.ErrIllegalTransition),
+		errors.Is(err, account.ErrNonZeroBalance),
+		errors.Is(err, account.ErrOpenReservations),
+		errors.Is(err, account.ErrCreditLimitTooLow),
+		errors.Is(err, order.ErrServiceInactive),
+		errors.Is(err, order.ErrIllegalTransition),
+		errors.Is(err, order.ErrRefundExceedsPaid),
+		errors.Is(err, order.ErrCaptureExceedsReserved),
+		errors.Is(err, currency.ErrMismatch):
+		return codes.FailedPrecondition
+	case errors.Is(err, context.Canceled):
+		return codes.Canceled
+	case errors.Is(err, context.DeadlineExceeded):
+		return codes.DeadlineExceeded
+	}
+
+	return codes.Internal
+}
+
+type baseHandler struct {
+	logger *zap.Logger
+}
+
+// error logs err and converts it to a status. The cause is only exposed for known domain errors.
+func (bh baseHandler) error(err error, message string) error {
+	bh.logger.Error(err.Error())
+
+	code := Code(err)
+	if code == codes.Internal {
+		return status.Error(code, message)
+	}
+
+	st := status.New(code, fmt.Sprintf("%s: %s", message, err))
+
+	var fundsErr *account.InsufficientFundsError
+	if errors.As(err, &fundsErr) {
+		detailed, detailsErr := st.WithDetails(&errdetails.ErrorInfo{
+			Reason: InsufficientFundsReason,
+			Domain: errorDomain,
+			Metadata: map[string]string{
+				"available":    strconv.FormatInt(fundsErr.Available, 10),
+				"credit_limit": strconv.FormatInt(fundsErr.CreditLimit, 10),
+				"currency":     fundsErr.Currency.String(),
+			},
+		})
+		if detailsErr == nil {
+			st = detailed
+		}
+	}
+
+	return st.Err()
+}
+
+func invalidRequest(format string, args ...interface{}) error {
+	return fmt.Errorf("%w: %s", errInvalidRequest, fmt.Sprintf(format, args...))
+}
+
+// validate returns the first failed check.
+func validate(errs ...error) error {
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func positive(name string, value int64) error {
+	if value <= 0 {
+		return invalidRequest("%s must be positive", name)
+	}
+
+	return nil
+}
+
+func nonNegative(name string, value int64) error {
+	if value < 0 {
+		return invalidRequest("%s must not be negative", name)
+	}
+
+	return nil
+}
+
+func atMost(name string, value, limit int64) error {
+	if value > limit {
+		return invalidRequest("%s must be at most %d", name, limit)
+	}
+
+	return nil
+}
+
+func maxLength(name, value string, limit int) error {
+	if len(value) > limit {
+		return invalidRequest("%s must be at most %d characters", name, limit)
+	}
+
+	return nil
+}
+
+// parseCurrency returns the default currency for an empty code.
+func parseCurrency(code string) (currency.Currency, error) {
+	if code == "" {
+		return currency.Default, nil
+	}
+
+	return currency.Parse(code)
+}
@@ -0,0 +1,61 @@
+package grpc_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/maypok86/payment-api/internal/domain/account"
+	"github.com/maypok86/payment-api/internal/domain/currency"
+	"github.com/maypok86/payment-api/internal/domain/order"
+	"github.com/maypok86/payment-api/internal/domain/report"
+	"github.com/maypok86/payment-api/internal/domain/transaction"
+	handler "github.com/maypok86/payment-api/internal/handler/grpc"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+)
+
+func TestCode(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		err  error
+		want codes.Code
+	}{
+		{err: account.ErrNotFound, want: codes.NotFound},
+		{err: order.ErrNotFound, want: codes.NotFound},
+		{err: order.ErrAccountNotFound, want: codes.NotFound},
+		{err: order.ErrServiceNotFound, want: codes.NotFound},
+		{err: report.ErrIsNotAvailable, want: codes.NotFound},
+		{err: report.ErrJobNotFound, want: codes.NotFound},
+		{err: account.ErrAlreadyExist, want: codes.AlreadyExists},
+		{err: order.ErrAlreadyExist, want: codes.AlreadyExists},
+		{err: &account.InsufficientFundsError{AccountID: 1}, want: codes.FailedPrecondition},
+		{err: account.ErrFrozen, want: codes.FailedPrecondition},
+		{err: account.ErrIllegalTransition, want: codes.FailedPrecondition},
+		{err: account.ErrNonZeroBalance, want: codes.FailedPrecondition},
+		{err: account.ErrOpenReservations, want: codes.FailedPrecondition},
+		{err: account.ErrCreditLimitTooLow, want: codes.FailedPrecondition},
+		{err: order.ErrServiceInactive, want: codes.FailedPrecondition},
+		{err: order.ErrIllegalTransition, want: codes.FailedPrecondition},
+		{err: currency.ErrMismatch, want: codes.FailedPrecondition},
+		{err: currency.ErrUnsupported, want: codes.InvalidArgument},
+		{err: currency.ErrInvalidAmount, want: codes.InvalidArgument},
+		{err: account.ErrRateNotFound, want: codes.InvalidArgument},
+		{err: account.ErrEmptyBatch, want: codes.InvalidArgument},
+		{err: account.ErrBatchTooLarge, want: codes.InvalidArgument},
+		{err: report.ErrUnsupportedFormat, want: codes.InvalidArgument},
+		{err: transaction.ErrInvalidSortParam, want: codes.InvalidArgument},
+		{err: transaction.ErrCursorMismatch, want: codes.InvalidArgument},
+		{err: transaction.ErrInvalidDateRange, want: codes.InvalidArgument},
+		{err: transaction.ErrInvalidAmountParam, want: codes.InvalidArgument},
+		{err: context.DeadlineExceeded, want: codes.DeadlineExceeded},
+		{err: errors.New("connection refused"), want: codes.Internal},
+	}
+
+	for _, tt := range tests {
+		wrapped := fmt.Errorf("service: %w", tt.err)
+		require.Equal(t, tt.want, handler.Code(wrapped), "err = %v", tt.err)
+	}
+}
@@ -0,0 +1,711 @@
+package grpc_test
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	paymentv1 "github.com/maypok86/payment-api/api/proto/payment/v1"
+	"github.com/maypok86/payment-api/internal/domain/account"
+	"github.com/maypok86/payment-api/internal/domain/currency"
+	"github.com/maypok86/payment-api/internal/domain/order"
+	"github.com/maypok86/payment-api/internal/domain/report"
+	"github.com/maypok86/payment-api/internal/domain/transaction"
+	handler "github.com/maypok86/payment-api/internal/handler/grpc"
+	"github.com/maypok86/payment-api/internal/pkg/logger"
+	"github.com/maypok86/payment-api/internal/pkg/pagination"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// dial starts an in-memory server with the services registered by register and returns a connection to it.
+func dial(t *testing.T, register func(server *grpc.Server)) *grpc.ClientConn {
+	t.Helper()
+
+	listener := bufconn.Listen(1 << 20)
+	server := grpc.NewServer()
+	register(server)
+
+	go func() {
+		_ = server.Serve(listener)
+	}()
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.DialContext(
+		context.Background(),
+		"bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = conn.Close()
+	})
+
+	return conn
+}
+
+func newAccountClient(t *testing.T) (paymentv1.AccountServiceClient, *MockAccountService) {
+	t.Helper()
+
+	mockCtrl := gomock.NewController(t)
+	service := NewMockAccountService(mockCtrl)
+	accountHandler := handler.NewAccountHandler(service, logger.New(os.Stdout, "debug"))
+
+	conn := dial(t, func(server *grpc.Server) {
+		paymentv1.RegisterAccountServiceServer(server, accountHandler)
+	})
+
+	return paymentv1.NewAccountServiceClient(conn), service
+}
+
+func requireStatus(t *testing.T, err error, code codes.Code) *status.Status {
+	t.Helper()
+
+	st, ok := status.FromError(err)
+	require.True(t, ok, "err = %v", err)
+	require.Equal(t, code, st.Code(), "message = %s", st.Message())
+
+	return st
+}
+
+func TestAccountHandler_GetBalance(t *testing.T) {
+	t.Parallel()
+
+	fakeAccount := account.Account{
+		AccountID:   1,
+		Status:      account.Active,
+		Currency:    currency.USD,
+		Available:   100,
+		Reserved:    30,
+		CreditLimit: 10,
+	}
+
+	type mockBehaviour func(service *MockAccountService)
+
+	tests := []struct {
+		name     string
+		mock     mockBehaviour
+		request  *paymentv1.GetBalanceRequest
+		response *paymentv1.GetBalanceResponse
+		code     codes.Code
+	}{
+		{
+			name:    "invalid account_id",
+			mock:    func(service *MockAccountService) {},
+			request: &paymentv1.GetBalanceRequest{AccountId: 0},
+			code:    codes.InvalidArgument,
+		},
+		{
+			name:    "invalid currency",
+			mock:    func(service *MockAccountService) {},
+			request: &paymentv1.GetBalanceRequest{AccountId: 1, Currency: "XXX"},
+			code:    codes.InvalidArgument,
+		},
+		{
+			name: "account not found",
+			mock: func(service *MockAccountService) {
+				service.EXPECT().
+					GetBalanceByID(gomock.Any(), int64(1), currency.Default).
+					Return(account.Account{}, account.ErrNotFound)
+			},
+			request: &paymentv1.GetBalanceRequest{AccountId: 1},
+			code:    codes.NotFound,
+		},
+		{
+			name: "account service error",
+			mock: func(service *MockAccountService) {
+				service.EXPECT().
+					GetBalanceByID(gomock.Any(), int64(1), currency.Default).
+					Return(account.Account{}, errors.New("account service error"))
+			},
+			request: &paymentv1.GetBalanceRequest{AccountId: 1},
+			code:    codes.Internal,
+		},
+		{
+			name: "success get balance",
+			mock: func(service *MockAccountService) {
+				service.EXPECT().GetBalanceByID(gomock.Any(), int64(1), currency.USD).Return(fakeAccount, nil)
+			},
+			request: &paymentv1.GetBalanceRequest{AccountId: 1, Currency: "USD"},
+			response: &paymentv1.GetBalanceResponse{
+				Available:   100,
+				Reserved:    30,
+				Total:       130,
+				CreditLimit: 10,
+				Currency:    "USD",
+			},
+			code: codes.OK,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			client, service := newAccountClient(t)
+			tt.mock(service)
+
+			response, err := client.GetBalance(context.Background(), tt.request)
+			if tt.code != codes.OK {
+				requireStatus(t, err, tt.code)
+				return
+			}
+			require.NoError(t, err)
+			require.True(t, proto.Equal(tt.response, response))
+		})
+	}
+}
+
+func TestAccountHandler_TransferBalance(t *testing.T) {
+	t.Parallel()
+
+	fakeDTO := account.TransferBalanceDTO{
+		SenderID:         1,
+		ReceiverID:       2,
+		Amount:           100,
+		Currency:         currency.RUB,
+		ReceiverCurrency: currency.USD,
+	}
+	fakeRequest := &paymentv1.TransferBalanceRequest{
+		SenderId:         1,
+		ReceiverId:       2,
+		Amount:           100,
+		ReceiverCurrency: "USD",
+	}
+
+	type mockBehaviour func(service *MockAccountService)
+
+	tests := []struct {
+		name     string
+		mock     mockBehaviour
+		request  *paymentv1.TransferBalanceRequest
+		response *paymentv1.TransferBalanceResponse
+		code     codes.Code
+	}{
+		{
+			name:    "invalid amount",
+			mock:    func(service *MockAccountService) {},
+			request: &paymentv1.TransferBalanceRequest{SenderId: 1, ReceiverId: 2},
+			code:    codes.InvalidArgument,
+		},
+		{
+			name: "sender is frozen",
+			mock: func(service *MockAccountService) {
+				service.EXPECT().TransferBalance(gomock.Any(), fakeDTO).Return(int64(0), int64(0), account.ErrFrozen)
+			},
+			request: fakeRequest,
+			code:    codes.FailedPrecondition,
+		},
+		{
+			name: "exchange rate not found",
+			mock: func(service *MockAccountService) {
+				service.EXPECT().TransferBalance(gomock.Any(), fakeDTO).Return(int64(0), int64(0), account.ErrRateNotFound)
+			},
+			request: fakeRequest,
+			code:    codes.InvalidArgument,
+		},
+		{
+			name: "success transfer balance",
+			mock: func(service *MockAccountService) {
+				service.EXPECT().TransferBalance(gomock.Any(), fakeDTO).Return(int64(50), int64(2), nil)
+			},
+			request:  fakeRequest,
+			response: &paymentv1.TransferBalanceResponse{SenderBalance: 50, ReceiverBalance: 2},
+			code:     codes.OK,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			client, service := newAccountClient(t)
+			tt.mock(service)
+
+			response, err := client.TransferBalance(context.Background(), tt.request)
+			if tt.code != codes.OK {
+				requireStatus(t, err, tt.code)
+				return
+			}
+			require.NoError(t, err)
+			require.True(t, proto.Equal(tt.response, response))
+		})
+	}
+}
+
+func TestAccountHandler_InsufficientFunds(t *testing.T) {
+	t.Parallel()
+
+	client, service := newAccountClient(t)
+	insufficientFunds := &account.InsufficientFundsError{
+		AccountID:   1,
+		Currency:    currency.RUB,
+		Available:   20,
+		CreditLimit: 10,
+	}
+	service.EXPECT().TransferBalance(gomock.Any(), gomock.Any()).Return(int64(0), int64(0), insufficientFunds)
+
+	_, err := client.TransferBalance(context.Background(), &paymentv1.TransferBalanceRequest{
+		SenderId:   1,
+		ReceiverId: 2,
+		Amount:     100,
+	})
+	st := requireStatus(t, err, codes.FailedPrecondition)
+
+	require.Len(t, st.Details(), 1)
+	info, ok := st.Details()[0].(*errdetails.ErrorInfo)
+	require.True(t, ok)
+	require.Equal(t, handler.InsufficientFundsReason, info.GetReason())
+	require.Equal(t, map[string]string{
+		"available":    "20",
+		"credit_limit": "10",
+		"currency":     "RUB",
+	}, info.GetMetadata())
+}
+
+func TestTransactionHandler_GetTransactions(t *testing.T) {
+	t.Parallel()
+
+	mockCtrl := gomock.NewController(t)
+	service := NewMockTransactionService(mockCtrl)
+	transactionHandler := handler.NewTransactionHandler(service, logger.New(os.Stdout, "debug"))
+	client := paymentv1.NewTransactionServiceClient(dial(t, func(server *grpc.Server) {
+		paymentv1.RegisterTransactionServiceServer(server, transactionHandler)
+	}))
+
+	createdAt := time.Date(2022, 11, 13, 12, 0, 0, 0, time.UTC)
+	fakeTransactions := []transaction.Transaction{
+		{
+			TransactionID: 1,
+			Type:          transaction.Transfer,
+			SenderID:      1,
+			ReceiverID:    2,
+			Amount:        100,
+			Currency:      currency.RUB,
+			Conversion: &transaction.Conversion{
+				Rate:      "0.0162",
+				Amount:    1,
+				Currency:  currency.USD,
+				Remainder: "0.62",
+			},
+			Description: "transfer",
+			CreatedAt:   createdAt,
+		},
+	}
+	listParams, err := transaction.NewListParams("sum", "desc", pagination.Params{
+		Limit:  pagination.MaxLimit,
+		Offset: 5,
+	})
+	require.NoError(t, err)
+
+	fakeCount := 6
+	service.EXPECT().
+		GetTransactionsByAccountID(gomock.Any(), int64(1), listParams).
+		Return(transaction.List{Transactions: fakeTransactions, Count: &fakeCount}, nil)
+
+	response, err := client.GetTransactions(context.Background(), &paymentv1.GetTransactionsRequest{
+		AccountId: 1,
+		Limit:     1000,
+		Offset:    5,
+		Sort:      "sum",
+		Direction: "desc",
+	})
+	require.NoError(t, err)
+	require.True(t, proto.Equal(&paymentv1.GetTransactionsResponse{
+		Transactions: []*paymentv1.Transaction{
+			{
+				TransactionId: 1,
+				Type:          "transfer",
+				SenderId:      1,
+				ReceiverId:    2,
+				Amount:        100,
+				Currency:      "RUB",
+				Conversion: &paymentv1.Conversion{
+					Rate:              "0.0162",
+					Amount:            1,
+					Currency:          "USD",
+					RoundingRemainder: "0.62",
+				},
+				Description: "transfer",
+				CreatedAt:   timestamppb.New(createdAt),
+			},
+		},
+		Range: &paymentv1.ListRange{Limit: pagination.MaxLimit, Offset: 5, Count: 6},
+	}, response))
+
+	_, err = client.GetTransactions(context.Background(), &paymentv1.GetTransactionsRequest{
+		AccountId: 1,
+		Sort:      "invalid",
+	})
+	requireStatus(t, err, codes.InvalidArgument)
+}
+
+func TestOrderHandler_CreateOrder(t *testing.T) {
+	t.Parallel()
+
+	fakeOrder := order.Order{
+		OrderID:   1,
+		AccountID: 1,
+		ServiceID: 1,
+		Amount:    50,
+		Currency:  currency.RUB,
+		Status:    order.Reserved,
+		ExpiresAt: time.Date(2022, 11, 14, 12, 0, 0, 0, time.UTC),
+		CreatedAt: time.Date(2022, 11, 13, 12, 0, 0, 0, time.UTC),
+		UpdatedAt: time.Date(2022, 11, 13, 12, 0, 0, 0, time.UTC),
+	}
+	fakeRequest := &paymentv1.CreateOrderRequest{
+		OrderId:        1,
+		AccountId:      1,
+		ServiceId:      1,
+		Amount:         50,
+		ReservationTtl: 60,
+	}
+	newDTO := func(actor string) order.CreateDTO {
+		return order.CreateDTO{
+			OrderID:        1,
+			AccountID:      1,
+			ServiceID:      1,
+			Amount:         50,
+			Currency:       currency.RUB,
+			ReservationTTL: time.Minute,
+			Actor:          actor,
+		}
+	}
+
+	type mockBehaviour func(service *MockOrderService)
+
+	tests := []struct {
+		name     string
+		mock     mockBehaviour
+		actor    string
+		request  *paymentv1.CreateOrderRequest
+		response *paymentv1.CreateOrderResponse
+		code     codes.Code
+	}{
+		{
+			name:    "invalid service_id",
+			mock:    func(service *MockOrderService) {},
+			request: &paymentv1.CreateOrderRequest{OrderId: 1, AccountId: 1, Amount: 50},
+			code:    codes.InvalidArgument,
+		},
+		{
+			name: "too long reservation_ttl",
+			mock: func(service *MockOrderService) {},
+			request: &paymentv1.CreateOrderRequest{
+				OrderId:        1,
+				AccountId:      1,
+				ServiceId:      1,
+				Amount:         50,
+				ReservationTtl: math.MaxInt64,
+			},
+			code: codes.InvalidArgument,
+		},
+		{
+			name: "order already exist",
+			mock: func(service *MockOrderService) {
+				service.EXPECT().
+					CreateOrder(gomock.Any(), newDTO(handler.DefaultActor)).
+					Return(order.Order{}, int64(0), order.ErrAlreadyExist)
+			},
+			request: fakeRequest,
+			code:    codes.AlreadyExists,
+		},
+		{
+			name: "account not found",
+			mock: func(service *MockOrderService) {
+				service.EXPECT().
+					CreateOrder(gomock.Any(), newDTO(handler.DefaultActor)).
+					Return(order.Order{}, int64(0), account.ErrNotFound)
+			},
+			request: fakeRequest,
+			code:    codes.NotFound,
+		},
+		{
+			name: "success create order",
+			mock: func(service *MockOrderService) {
+				service.EXPECT().CreateOrder(gomock.Any(), newDTO("billing")).Return(fakeOrder, int64(50), nil)
+			},
+			actor:   "billing",
+			request: fakeRequest,
+			response: &paymentv1.CreateOrderResponse{
+				Order: &paymentv1.Order{
+					OrderId:   1,
+					AccountId: 1,
+					ServiceId: 1,
+					Amount:    50,
+					Currency:  "RUB",
+					Status:    "reserved",
+					ExpiresAt: timestamppb.New(fakeOrder.ExpiresAt),
+					CreatedAt: timestamppb.New(fakeOrder.CreatedAt),
+					UpdatedAt: timestamppb.New(fakeOrder.UpdatedAt),
+				},
+				Balance: 50,
+			},
+			code: codes.OK,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			mockCtrl := gomock.NewController(t)
+			service := NewMockOrderService(mockCtrl)
+			orderHandler := handler.NewOrderHandler(service, logger.New(os.Stdout, "debug"))
+			client := paymentv1.NewOrderServiceClient(dial(t, func(server *grpc.Server) {
+				paymentv1.RegisterOrderServiceServer(server, orderHandler)
+			}))
+
+			tt.mock(service)
+
+			ctx := context.Background()
+			if tt.actor != "" {
+				ctx = metadata.AppendToOutgoingContext(ctx, handler.ActorMetadataKey, tt.actor)
+			}
+
+			response, err := client.CreateOrder(ctx, tt.request)
+			if tt.code != codes.OK {
+				requireStatus(t, err, tt.code)
+				return
+			}
+			require.NoError(t, err)
+			require.True(t, proto.Equal(tt.response, response))
+		})
+	}
+}
+
+func TestOrderHandler_RefundOrder(t *testing.T) {
+	t.Parallel()
+
+	mockCtrl := gomock.NewController(t)
+	service := NewMockOrderService(mockCtrl)
+	orderHandler := handler.NewOrderHandler(service, logger.New(os.Stdout, "debug"))
+	client := paymentv1.NewOrderServiceClient(dial(t, func(server *grpc.Server) {
+		paymentv1.RegisterOrderServiceServer(server, orderHandler)
+	}))
+
+	// a zero amount refunds the whole refundable amount.
+	service.EXPECT().RefundOrder(gomock.Any(), order.RefundDTO{
+		OrderID:   1,
+		AccountID: 1,
+		ServiceID: 1,
+		Currency:  currency.RUB,
+		Actor:     handler.DefaultActor,
+		Reason:    "duplicate",
+	}).Return(order.Order{}, int64(0), order.ErrRefundExceedsPaid)
+
+	_, err := client.RefundOrder(context.Background(), &paymentv1.RefundOrderRequest{
+		OrderId:   1,
+		AccountId: 1,
+		ServiceId: 1,
+		Reason:    "duplicate",
+	})
+	requireStatus(t, err, codes.FailedPrecondition)
+}
+
+func TestReportHandler_GetReport(t *testing.T) {
+	t.Parallel()
+
+	mockCtrl := gomock.NewController(t)
+	service := NewMockReportService(mockCtrl)
+	reportHandler := handler.NewReportHandler(service, logger.New(os.Stdout, "debug"))
+	client := paymentv1.NewReportServiceClient(dial(t, func(server *grpc.Server) {
+		paymentv1.RegisterReportServiceServer(server, reportHandler)
+	}))
+
+	_, err := client.GetReport(context.Background(), &paymentv1.GetReportRequest{Month: 13, Year: 2022})
+	requireStatus(t, err, codes.InvalidArgument)
+
+	service.EXPECT().
+		GetReportKey(gomock.Any(), report.GetMapDTO{Month: 12, Year: 2022}).
+		Return("", report.ErrIsNotAvailable)
+
+	_, err = client.GetReport(context.Background(), &paymentv1.GetReportRequest{Month: 12, Year: 2022})
+	requireStatus(t, err, codes.NotFound)
+
+	service.EXPECT().
+		GetReportKey(gomock.Any(), report.GetMapDTO{Month: 11, Year: 2022, Format: "txt"}).
+		Return("", report.ErrUnsupportedFormat)
+
+	_, err = client.GetReport(context.Background(), &paymentv1.GetReportRequest{Month: 11, Year: 2022, Format: "txt"})
+	requireStatus(t, err, codes.InvalidArgument)
+
+	service.EXPECT().GetReportKey(gomock.Any(), report.GetMapDTO{Month: 11, Year: 2022}).Return("2022-11.csv", nil)
+	service.EXPECT().GetReportContent(gomock.Any(), "2022-11.csv").Return(report.Content{
+		Data:        []byte("service_id,amount\n1,100\n"),
+		ContentType: "text/csv",
+		Extension:   "csv",
+	}, nil)
+
+	response, err := client.GetReport(context.Background(), &paymentv1.GetReportRequest{Month: 11, Year: 2022})
+	require.NoError(t, err)
+	require.True(t, proto.Equal(&paymentv1.GetReportResponse{
+		Key:         "2022-11.csv",
+		Content:     []byte("service_id,amount\n1,100\n"),
+		ContentType: "text/csv",
+		Extension:   "csv",
+	}, response))
+
+	service.EXPECT().
+		GetReportKey(gomock.Any(), report.GetMapDTO{Month: 11, Year: 2022, Format: "json"}).
+		Return("2022-11.json", nil)
+	service.EXPECT().GetReportContent(gomock.Any(), "2022-11.json").Return(report.Content{
+		Data:        []byte("[]"),
+		ContentType: "application/json",
+		Extension:   "json",
+	}, nil)
+
+	response, err = client.GetReport(
+		context.Background(),
+		&paymentv1.GetReportRequest{Month: 11, Year: 2022, Format: "json"},
+	)
+	require.NoError(t, err)
+	require.Equal(t, "application/json", response.GetContentType())
+	require.Equal(t, "json", response.GetExtension())
+}
+
+func TestReportHandler_CreateReportJob(t *testing.T) {
+	t.Parallel()
+
+	mockCtrl := gomock.NewController(t)
+	service := NewMockReportService(mockCtrl)
+	reportHandler := handler.NewReportHandler(service, logger.New(os.Stdout, "debug"))
+	client := paymentv1.NewReportServiceClient(dial(t, func(server *grpc.Server) {
+		paymentv1.RegisterReportServiceServer(server, reportHandler)
+	}))
+
+	_, err := client.CreateReportJob(context.Background(), &paymentv1.CreateReportJobRequest{Month: 11, Year: 2021})
+	requireStatus(t, err, codes.InvalidArgument)
+
+	service.EXPECT().
+		CreateJob(gomock.Any(), report.CreateJobDTO{
+			GetMapDTO: report.GetMapDTO{Month: 11, Year: 2022, Format: "txt"},
+			Principal: handler.DefaultActor,
+		}).
+		Return(report.Job{}, report.ErrUnsupportedFormat)
+
+	_, err = client.CreateReportJob(
+		context.Background(),
+		&paymentv1.CreateReportJobRequest{Month: 11, Year: 2022, Format: "txt"},
+	)
+	requireStatus(t, err, codes.InvalidArgument)
+
+	createdAt := time.Date(2022, 12, 1, 12, 0, 0, 0, time.UTC)
+	service.EXPECT().
+		CreateJob(gomock.Any(), report.CreateJobDTO{
+			GetMapDTO: report.GetMapDTO{Month: 11, Year: 2022, Format: "xlsx"},
+			Principal: "billing",
+		}).
+		Return(report.Job{
+			JobID:     1,
+			Month:     11,
+			Year:      2022,
+			Format:    "xlsx",
+			Principal: "billing",
+			Status:    report.Queued,
+			CreatedAt: createdAt,
+			UpdatedAt: createdAt,
+		}, nil)
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), handler.ActorMetadataKey, "billing")
+	response, err := client.CreateReportJob(ctx, &paymentv1.CreateReportJobRequest{Month: 11, Year: 2022, Format: "xlsx"})
+	require.NoError(t, err)
+	require.True(t, proto.Equal(&paymentv1.CreateReportJobResponse{
+		Job: &paymentv1.ReportJob{
+			JobId:     1,
+			Month:     11,
+			Year:      2022,
+			Format:    "xlsx",
+			Status:    "queued",
+			CreatedAt: timestamppb.New(createdAt),
+			UpdatedAt: timestamppb.New(createdAt),
+		},
+	}, response))
+}
+
+func TestReportHandler_GetReportJob(t *testing.T) {
+	t.Parallel()
+
+	mockCtrl := gomock.NewController(t)
+	service := NewMockReportService(mockCtrl)
+	reportHandler := handler.NewReportHandler(service, logger.New(os.Stdout, "debug"))
+	client := paymentv1.NewReportServiceClient(dial(t, func(server *grpc.Server) {
+		paymentv1.RegisterReportServiceServer(server, reportHandler)
+	}))
+
+	_, err := client.GetReportJob(context.Background(), &paymentv1.GetReportJobRequest{})
+	requireStatus(t, err, codes.InvalidArgument)
+
+	service.EXPECT().GetJob(gomock.Any(), int64(2), handler.DefaultActor).Return(report.Job{}, report.ErrJobNotFound)
+
+	_, err = client.GetReportJob(context.Background(), &paymentv1.GetReportJobRequest{JobId: 2})
+	requireStatus(t, err, codes.NotFound)
+
+	createdAt := time.Date(2022, 12, 1, 12, 0, 0, 0, time.UTC)
+	job := report.Job{
+		JobID:     1,
+		Month:     11,
+		Year:      2022,
+		Format:    "csv",
+		Principal: handler.DefaultActor,
+		Status:    report.Running,
+		CreatedAt: createdAt,
+		UpdatedAt: createdAt,
+	}
+	service.EXPECT().GetJob(gomock.Any(), int64(1), handler.DefaultActor).Return(job, nil)
+
+	response, err := client.GetReportJob(context.Background(), &paymentv1.GetReportJobRequest{JobId: 1})
+	require.NoError(t, err)
+	require.Equal(t, "running", response.GetJob().GetStatus())
+	require.Empty(t, response.GetContent())
+
+	job.Status = report.Done
+	job.Key = "2022-11.csv"
+	service.EXPECT().GetJob(gomock.Any(), int64(1), handler.DefaultActor).Return(job, nil)
+	service.EXPECT().GetReportContent(gomock.Any(), "2022-11.csv").Return(report.Content{
+		Data:        []byte("service_id,amount\n1,100\n"),
+		ContentType: "text/csv",
+		Extension:   "csv",
+	}, nil)
+
+	response, err = client.GetReportJob(context.Background(), &paymentv1.GetReportJobRequest{JobId: 1})
+	require.NoError(t, err)
+	require.True(t, proto.Equal(&paymentv1.GetReportJobResponse{
+		Job: &paymentv1.ReportJob{
+			JobId:     1,
+			Month:     11,
+			Year:      2022,
+			Format:    "csv",
+			Status:    "done",
+			CreatedAt: timestamppb.New(createdAt),
+			UpdatedAt: timestamppb.New(createdAt),
+		},
+		Key:         "2022-11.csv",
+		Content:     []byte("service_id,amount\n1,100\n"),
+		ContentType: "text/csv",
+		Extension:   "csv",
+	}, response))
+}
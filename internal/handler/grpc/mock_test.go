@@ -0,0 +1,306 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: service.go
+
+// Package grpc_test is a generated GoMock package.
+package grpc_test
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	account "github.com/maypok86/payment-api/internal/domain/account"
+	currency "github.com/maypok86/payment-api/internal/domain/currency"
+	order "github.com/maypok86/payment-api/internal/domain/order"
+	report "github.com/maypok86/payment-api/internal/domain/report"
+	transaction "github.com/maypok86/payment-api/internal/domain/transaction"
+)
+
+// MockAccountService is a mock of AccountService interface.
+type MockAccountService struct {
+	ctrl     *gomock.Controller
+	recorder *MockAccountServiceMockRecorder
+}
+
+// MockAccountServiceMockRecorder is the mock recorder for MockAccountService.
+type MockAccountServiceMockRecorder struct {
+	mock *MockAccountService
+}
+
+// NewMockAccountService creates a new mock instance.
+func NewMockAccountService(ctrl *gomock.Controller) *MockAccountService {
+	mock := &MockAccountService{ctrl: ctrl}
+	mock.recorder = &MockAccountServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAccountService) EXPECT() *MockAccountServiceMockRecorder {
+	return m.recorder
+}
+
+// AddBalance mocks base method.
+func (m *MockAccountService) AddBalance(ctx context.Context, dto account.AddBalanceDTO) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddBalance", ctx, dto)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddBalance indicates an expected call of AddBalance.
+func (mr *MockAccountServiceMockRecorder) AddBalance(ctx, dto interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddBalance", reflect.TypeOf((*MockAccountService)(nil).AddBalance), ctx, dto)
+}
+
+// GetBalanceByID mocks base method.
+func (m *MockAccountService) GetBalanceByID(ctx context.Context, id int64, cur currency.Currency) (account.Account, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBalanceByID", ctx, id, cur)
+	ret0, _ := ret[0].(account.Account)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBalanceByID indicates an expected call of GetBalanceByID.
+func (mr *MockAccountServiceMockRecorder) GetBalanceByID(ctx, id, cur interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBalanceByID", reflect.TypeOf((*MockAccountService)(nil).GetBalanceByID), ctx, id, cur)
+}
+
+// TransferBalance mocks base method.
+func (m *MockAccountService) TransferBalance(ctx context.Context, dto account.TransferBalanceDTO) (int64, int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TransferBalance", ctx, dto)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(int64)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// TransferBalance indicates an expected call of TransferBalance.
+func (mr *MockAccountServiceMockRecorder) TransferBalance(ctx, dto interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TransferBalance", reflect.TypeOf((*MockAccountService)(nil).TransferBalance), ctx, dto)
+}
+
+// MockTransactionService is a mock of TransactionService interface.
+type MockTransactionService struct {
+	ctrl     *gomock.Controller
+	recorder *MockTransactionServiceMockRecorder
+}
+
+// MockTransactionServiceMockRecorder is the mock recorder for MockTransactionService.
+type MockTransactionServiceMockRecorder struct {
+	mock *MockTransactionService
+}
+
+// NewMockTransactionService creates a new mock instance.
+func NewMockTransactionService(ctrl *gomock.Controller) *MockTransactionService {
+	mock := &MockTransactionService{ctrl: ctrl}
+	mock.recorder = &MockTransactionServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTransactionService) EXPECT() *MockTransactionServiceMockRecorder {
+	return m.recorder
+}
+
+// GetTransactionsByAccountID mocks base method.
+func (m *MockTransactionService) GetTransactionsByAccountID(ctx context.Context, accountID int64, listParams transaction.ListParams) (transaction.List, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTransactionsByAccountID", ctx, accountID, listParams)
+	ret0, _ := ret[0].(transaction.List)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTransactionsByAccountID indicates an expected call of GetTransactionsByAccountID.
+func (mr *MockTransactionServiceMockRecorder) GetTransactionsByAccountID(ctx, accountID, listParams interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTransactionsByAccountID", reflect.TypeOf((*MockTransactionService)(nil).GetTransactionsByAccountID), ctx, accountID, listParams)
+}
+
+// MockOrderService is a mock of OrderService interface.
+type MockOrderService struct {
+	ctrl     *gomock.Controller
+	recorder *MockOrderServiceMockRecorder
+}
+
+// MockOrderServiceMockRecorder is the mock recorder for MockOrderService.
+type MockOrderServiceMockRecorder struct {
+	mock *MockOrderService
+}
+
+// NewMockOrderService creates a new mock instance.
+func NewMockOrderService(ctrl *gomock.Controller) *MockOrderService {
+	mock := &MockOrderService{ctrl: ctrl}
+	mock.recorder = &MockOrderServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockOrderService) EXPECT() *MockOrderServiceMockRecorder {
+	return m.recorder
+}
+
+// CancelOrder mocks base method.
+func (m *MockOrderService) CancelOrder(ctx context.Context, dto order.CancelDTO) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CancelOrder", ctx, dto)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CancelOrder indicates an expected call of CancelOrder.
+func (mr *MockOrderServiceMockRecorder) CancelOrder(ctx, dto interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CancelOrder", reflect.TypeOf((*MockOrderService)(nil).CancelOrder), ctx, dto)
+}
+
+// CaptureOrder mocks base method.
+func (m *MockOrderService) CaptureOrder(ctx context.Context, dto order.CaptureDTO) (order.Order, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CaptureOrder", ctx, dto)
+	ret0, _ := ret[0].(order.Order)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CaptureOrder indicates an expected call of CaptureOrder.
+func (mr *MockOrderServiceMockRecorder) CaptureOrder(ctx, dto interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CaptureOrder", reflect.TypeOf((*MockOrderService)(nil).CaptureOrder), ctx, dto)
+}
+
+// CreateOrder mocks base method.
+func (m *MockOrderService) CreateOrder(ctx context.Context, dto order.CreateDTO) (order.Order, int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateOrder", ctx, dto)
+	ret0, _ := ret[0].(order.Order)
+	ret1, _ := ret[1].(int64)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// CreateOrder indicates an expected call of CreateOrder.
+func (mr *MockOrderServiceMockRecorder) CreateOrder(ctx, dto interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateOrder", reflect.TypeOf((*MockOrderService)(nil).CreateOrder), ctx, dto)
+}
+
+// PayForOrder mocks base method.
+func (m *MockOrderService) PayForOrder(ctx context.Context, dto order.PayForDTO) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PayForOrder", ctx, dto)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PayForOrder indicates an expected call of PayForOrder.
+func (mr *MockOrderServiceMockRecorder) PayForOrder(ctx, dto interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PayForOrder", reflect.TypeOf((*MockOrderService)(nil).PayForOrder), ctx, dto)
+}
+
+// RefundOrder mocks base method.
+func (m *MockOrderService) RefundOrder(ctx context.Context, dto order.RefundDTO) (order.Order, int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RefundOrder", ctx, dto)
+	ret0, _ := ret[0].(order.Order)
+	ret1, _ := ret[1].(int64)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// RefundOrder indicates an expected call of RefundOrder.
+func (mr *MockOrderServiceMockRecorder) RefundOrder(ctx, dto interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RefundOrder", reflect.TypeOf((*MockOrderService)(nil).RefundOrder), ctx, dto)
+}
+
+// MockReportService is a mock of ReportService interface.
+type MockReportService struct {
+	ctrl     *gomock.Controller
+	recorder *MockReportServiceMockRecorder
+}
+
+// MockReportServiceMockRecorder is the mock recorder for MockReportService.
+type MockReportServiceMockRecorder struct {
+	mock *MockReportService
+}
+
+// NewMockReportService creates a new mock instance.
+func NewMockReportService(ctrl *gomock.Controller) *MockReportService {
+	mock := &MockReportService{ctrl: ctrl}
+	mock.recorder = &MockReportServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockReportService) EXPECT() *MockReportServiceMockRecorder {
+	return m.recorder
+}
+
+// CreateJob mocks base method.
+func (m *MockReportService) CreateJob(ctx context.Context, dto report.CreateJobDTO) (report.Job, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateJob", ctx, dto)
+	ret0, _ := ret[0].(report.Job)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateJob indicates an expected call of CreateJob.
+func (mr *MockReportServiceMockRecorder) CreateJob(ctx, dto interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateJob", reflect.TypeOf((*MockReportService)(nil).CreateJob), ctx, dto)
+}
+
+// GetJob mocks base method.
+func (m *MockReportService) GetJob(ctx context.Context, jobID int64, principal string) (report.Job, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetJob", ctx, jobID, principal)
+	ret0, _ := ret[0].(report.Job)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetJob indicates an expected call of GetJob.
+func (mr *MockReportServiceMockRecorder) GetJob(ctx, jobID, principal interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetJob", reflect.TypeOf((*MockReportService)(nil).GetJob), ctx, jobID, principal)
+}
+
+// GetReportContent mocks base method.
+func (m *MockReportService) GetReportContent(ctx context.Context, filename string) (report.Content, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetReportContent", ctx, filename)
+	ret0, _ := ret[0].(report.Content)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetReportContent indicates an expected call of GetReportContent.
+func (mr *MockReportServiceMockRecorder) GetReportContent(ctx, filename interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetReportContent", reflect.TypeOf((*MockReportService)(nil).GetReportContent), ctx, filename)
+}
+
+// GetReportKey mocks base method.
+func (m *MockReportService) GetReportKey(ctx context.Context, dto report.GetMapDTO) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetReportKey", ctx, dto)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetReportKey indicates an expected call of GetReportKey.
+func (mr *MockReportServiceMockRecorder) GetReportKey(ctx, dto interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetReportKey", reflect.TypeOf((*MockReportService)(nil).GetReportKey), ctx, dto)
+}
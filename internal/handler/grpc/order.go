@@ -0,0 +1,252 @@
+package grpc
+
+import (
+	"context"
+	"time"
+
+	paymentv1 "github.com/maypok86/payment-api/api/proto/payment/v1"
+	"github.com/maypok86/payment-api/internal/domain/order"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const (
+	// ActorMetadataKey names who moves the order, the same as the X-Actor header of the HTTP API.
+	ActorMetadataKey = "x-actor"
+	DefaultActor     = "grpc"
+
+	maxReasonLength = 255
+)
+
+type OrderHandler struct {
+	paymentv1.UnimplementedOrderServiceServer
+	baseHandler
+	service OrderService
+}
+
+func NewOrderHandler(service OrderService, logger *zap.Logger) *OrderHandler {
+	return &OrderHandler{
+		baseHandler: baseHandler{logger: logger},
+		service:     service,
+	}
+}
+
+func (h *OrderHandler) CreateOrder(
+	ctx context.Context,
+	request *paymentv1.CreateOrderRequest,
+) (*paymentv1.CreateOrderResponse, error) {
+	const message = "Create order error"
+
+	if err := validate(
+		positive("order_id", request.GetOrderId()),
+		positive("account_id", request.GetAccountId()),
+		positive("service_id", request.GetServiceId()),
+		positive("amount", request.GetAmount()),
+		nonNegative("reservation_ttl", request.GetReservationTtl()),
+		atMost("reservation_ttl", request.GetReservationTtl(), int64(order.MaxReservationTTL/time.Second)),
+	); err != nil {
+		return nil, h.error(err, message)
+	}
+
+	cur, err := parseCurrency(request.GetCurrency())
+	if err != nil {
+		return nil, h.error(err, message)
+	}
+
+	entity, balance, err := h.service.CreateOrder(ctx, order.CreateDTO{
+		OrderID:        request.GetOrderId(),
+		AccountID:      request.GetAccountId(),
+		ServiceID:      request.GetServiceId(),
+		Amount:         request.GetAmount(),
+		Currency:       cur,
+		ReservationTTL: time.Duration(request.GetReservationTtl()) * time.Second,
+		Actor:          actor(ctx),
+	})
+	if err != nil {
+		return nil, h.error(err, message)
+	}
+
+	return &paymentv1.CreateOrderResponse{
+		Order:   newOrder(entity),
+		Balance: balance,
+	}, nil
+}
+
+func (h *OrderHandler) PayForOrder(
+	ctx context.Context,
+	request *paymentv1.PayForOrderRequest,
+) (*paymentv1.PayForOrderResponse, error) {
+	const message = "Pay for order error"
+
+	if err := validateOrderRequest(request, true); err != nil {
+		return nil, h.error(err, message)
+	}
+
+	cur, err := parseCurrency(request.GetCurrency())
+	if err != nil {
+		return nil, h.error(err, message)
+	}
+
+	if err := h.service.PayForOrder(ctx, order.PayForDTO{
+		OrderID:   request.GetOrderId(),
+		AccountID: request.GetAccountId(),
+		ServiceID: request.GetServiceId(),
+		Amount:    request.GetAmount(),
+		Currency:  cur,
+		Actor:     actor(ctx),
+		Reason:    request.GetReason(),
+	}); err != nil {
+		return nil, h.error(err, message)
+	}
+
+	return &paymentv1.PayForOrderResponse{}, nil
+}
+
+func (h *OrderHandler) CaptureOrder(
+	ctx context.Context,
+	request *paymentv1.CaptureOrderRequest,
+) (*paymentv1.CaptureOrderResponse, error) {
+	const message = "Capture order error"
+
+	if err := validateOrderRequest(request, true); err != nil {
+		return nil, h.error(err, message)
+	}
+
+	cur, err := parseCurrency(request.GetCurrency())
+	if err != nil {
+		return nil, h.error(err, message)
+	}
+
+	entity, err := h.service.CaptureOrder(ctx, order.CaptureDTO{
+		OrderID:   request.GetOrderId(),
+		AccountID: request.GetAccountId(),
+		ServiceID: request.GetServiceId(),
+		Amount:    request.GetAmount(),
+		Currency:  cur,
+		Actor:     actor(ctx),
+		Reason:    request.GetReason(),
+	})
+	if err != nil {
+		return nil, h.error(err, message)
+	}
+
+	return &paymentv1.CaptureOrderResponse{
+		Order: newOrder(entity),
+	}, nil
+}
+
+func (h *OrderHandler) CancelOrder(
+	ctx context.Context,
+	request *paymentv1.CancelOrderRequest,
+) (*paymentv1.CancelOrderResponse, error) {
+	const message = "Cancel order error"
+
+	if err := validateOrderRequest(request, true); err != nil {
+		return nil, h.error(err, message)
+	}
+
+	cur, err := parseCurrency(request.GetCurrency())
+	if err != nil {
+		return nil, h.error(err, message)
+	}
+
+	balance, err := h.service.CancelOrder(ctx, order.CancelDTO{
+		OrderID:   request.GetOrderId(),
+		AccountID: request.GetAccountId(),
+		ServiceID: request.GetServiceId(),
+		Amount:    request.GetAmount(),
+		Currency:  cur,
+		Actor:     actor(ctx),
+		Reason:    request.GetReason(),
+	})
+	if err != nil {
+		return nil, h.error(err, message)
+	}
+
+	return &paymentv1.CancelOrderResponse{
+		Balance: balance,
+	}, nil
+}
+
+func (h *OrderHandler) RefundOrder(
+	ctx context.Context,
+	request *paymentv1.RefundOrderRequest,
+) (*paymentv1.RefundOrderResponse, error) {
+	const message = "Refund order error"
+
+	if err := validateOrderRequest(request, false); err != nil {
+		return nil, h.error(err, message)
+	}
+
+	cur, err := parseCurrency(request.GetCurrency())
+	if err != nil {
+		return nil, h.error(err, message)
+	}
+
+	entity, balance, err := h.service.RefundOrder(ctx, order.RefundDTO{
+		OrderID:   request.GetOrderId(),
+		AccountID: request.GetAccountId(),
+		ServiceID: request.GetServiceId(),
+		Amount:    request.GetAmount(),
+		Currency:  cur,
+		Actor:     actor(ctx),
+		Reason:    request.GetReason(),
+	})
+	if err != nil {
+		return nil, h.error(err, message)
+	}
+
+	return &paymentv1.RefundOrderResponse{
+		Order:   newOrder(entity),
+		Balance: balance,
+	}, nil
+}
+
+// orderRequest is implemented by the requests that move an existing order.
+type orderRequest interface {
+	GetOrderId() int64
+	GetAccountId() int64
+	GetServiceId() int64
+	GetAmount() int64
+	GetReason() string
+}
+
+func validateOrderRequest(request orderRequest, amountRequired bool) error {
+	amount := nonNegative("amount", request.GetAmount())
+	if amountRequired {
+		amount = positive("amount", request.GetAmount())
+	}
+
+	return validate(
+		positive("order_id", request.GetOrderId()),
+		positive("account_id", request.GetAccountId()),
+		positive("service_id", request.GetServiceId()),
+		amount,
+		maxLength("reason", request.GetReason(), maxReasonLength),
+	)
+}
+
+func actor(ctx context.Context) string {
+	if values := metadata.ValueFromIncomingContext(ctx, ActorMetadataKey); len(values) > 0 && values[0] != "" {
+		return values[0]
+	}
+
+	return DefaultActor
+}
+
+func newOrder(entity order.Order) *paymentv1.Order {
+	return &paymentv1.Order{
+		OrderId:        entity.OrderID,
+		AccountId:      entity.AccountID,
+		ServiceId:      entity.ServiceID,
+		Amount:         entity.Amount,
+		Currency:       entity.Currency.String(),
+		CapturedAmount: entity.CapturedAmount,
+		RefundedAmount: entity.RefundedAmount,
+		Status:         entity.Status.String(),
+		ExpiresAt:      timestamppb.New(entity.ExpiresAt),
+		CreatedAt:      timestamppb.New(entity.CreatedAt),
+		UpdatedAt:      timestamppb.New(entity.UpdatedAt),
+	}
+}
@@ -0,0 +1,146 @@
+package grpc
+
+import (
+	"context"
+
+	paymentv1 "github.com/maypok86/payment-api/api/proto/payment/v1"
+	"github.com/maypok86/payment-api/internal/domain/report"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const (
+	minReportYear = 2022
+	monthsInYear  = 12
+)
+
+type ReportHandler struct {
+	paymentv1.UnimplementedReportServiceServer
+	baseHandler
+	service ReportService
+}
+
+func NewReportHandler(service ReportService, logger *zap.Logger) *ReportHandler {
+	return &ReportHandler{
+		baseHandler: baseHandler{logger: logger},
+		service:     service,
+	}
+}
+
+// GetReport returns the report content right away instead of a download link.
+func (h *ReportHandler) GetReport(
+	ctx context.Context,
+	request *paymentv1.GetReportRequest,
+) (*paymentv1.GetReportResponse, error) {
+	const message = "Get report error"
+
+	if err := validateReportPeriod(request.GetMonth(), request.GetYear()); err != nil {
+		return nil, h.error(err, message)
+	}
+
+	key, err := h.service.GetReportKey(ctx, report.GetMapDTO{
+		Month:  request.GetMonth(),
+		Year:   request.GetYear(),
+		Format: request.GetFormat(),
+	})
+	if err != nil {
+		return nil, h.error(err, message)
+	}
+
+	content, err := h.service.GetReportContent(ctx, key)
+	if err != nil {
+		return nil, h.error(err, message)
+	}
+
+	return &paymentv1.GetReportResponse{
+		Key:         key,
+		Content:     content.Data,
+		ContentType: content.ContentType,
+		Extension:   content.Extension,
+	}, nil
+}
+
+// CreateReportJob queues the report, the actor of the call is the principal of the job.
+func (h *ReportHandler) CreateReportJob(
+	ctx context.Context,
+	request *paymentv1.CreateReportJobRequest,
+) (*paymentv1.CreateReportJobResponse, error) {
+	const message = "Create report job error"
+
+	if err := validateReportPeriod(request.GetMonth(), request.GetYear()); err != nil {
+		return nil, h.error(err, message)
+	}
+
+	job, err := h.service.CreateJob(ctx, report.CreateJobDTO{
+		GetMapDTO: report.GetMapDTO{
+			Month:  request.GetMonth(),
+			Year:   request.GetYear(),
+			Format: request.GetFormat(),
+		},
+		Principal: actor(ctx),
+	})
+	if err != nil {
+		return nil, h.error(err, message)
+	}
+
+	return &paymentv1.CreateReportJobResponse{Job: newReportJob(job)}, nil
+}
+
+// GetReportJob returns the job only to the actor that created it, and its report once it is done.
+func (h *ReportHandler) GetReportJob(
+	ctx context.Context,
+	request *paymentv1.GetReportJobRequest,
+) (*paymentv1.GetReportJobResponse, error) {
+	const message = "Get report job error"
+
+	if err := positive("job_id", request.GetJobId()); err != nil {
+		return nil, h.error(err, message)
+	}
+
+	job, err := h.service.GetJob(ctx, request.GetJobId(), actor(ctx))
+	if err != nil {
+		return nil, h.error(err, message)
+	}
+
+	response := &paymentv1.GetReportJobResponse{Job: newReportJob(job)}
+	if job.Status != report.Done {
+		return response, nil
+	}
+
+	content, err := h.service.GetReportContent(ctx, job.Key)
+	if err != nil {
+		return nil, h.error(err, message)
+	}
+
+	response.Key = job.Key
+	response.Content = content.Data
+	response.ContentType = content.ContentType
+	response.Extension = content.Extension
+
+	return response, nil
+}
+
+func validateReportPeriod(month, year int64) error {
+	if month < 1 || month > monthsInYear {
+		return invalidRequest("month must be between 1 and %d", monthsInYear)
+	}
+
+	if year < minReportYear {
+		return invalidRequest("year must be at least %d", minReportYear)
+	}
+
+	return nil
+}
+
+func newReportJob(job report.Job) *paymentv1.ReportJob {
+	return &paymentv1.ReportJob{
+		JobId:     job.JobID,
+		Month:     job.Month,
+		Year:      job.Year,
+		Format:    job.Format,
+		Status:    job.Status.String(),
+		Error:     job.Error,
+		CreatedAt: timestamppb.New(job.CreatedAt),
+		UpdatedAt: timestamppb.New(job.UpdatedAt),
+	}
+}
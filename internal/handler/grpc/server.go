@@ -0,0 +1,69 @@
+package grpc
+
+import (
+	"context"
+	"time"
+
+	paymentv1 "github.com/maypok86/payment-api/api/proto/payment/v1"
+	"github.com/maypok86/payment-api/internal/domain"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func NewServer(services *domain.Services, logger *zap.Logger) *grpc.Server {
+	server := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(
+			loggingInterceptor(logger),
+			recoveryInterceptor(logger),
+		),
+	)
+
+	paymentv1.RegisterAccountServiceServer(server, NewAccountHandler(services.Account, logger))
+	paymentv1.RegisterTransactionServiceServer(server, NewTransactionHandler(services.Transaction, logger))
+	paymentv1.RegisterOrderServiceServer(server, NewOrderHandler(services.Order, logger))
+	paymentv1.RegisterReportServiceServer(server, NewReportHandler(services.Report, logger))
+
+	return server
+}
+
+func loggingInterceptor(logger *zap.Logger) grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context,
+		request interface{},
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (interface{}, error) {
+		start := time.Now()
+
+		response, err := handler(ctx, request)
+
+		logger.Info(
+			"grpc call",
+			zap.String("method", info.FullMethod),
+			zap.String("code", status.Code(err).String()),
+			zap.Duration("duration", time.Since(start)),
+		)
+
+		return response, err
+	}
+}
+
+func recoveryInterceptor(logger *zap.Logger) grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context,
+		request interface{},
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (response interface{}, err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				logger.Error("grpc call panic", zap.String("method", info.FullMethod), zap.Any("panic", r))
+				err = status.Error(codes.Internal, "Internal server error")
+			}
+		}()
+
+		return handler(ctx, request)
+	}
+}
@@ -0,0 +1,42 @@
+package grpc
+
+import (
+	"context"
+
+	"github.com/maypok86/payment-api/internal/domain/account"
+	"github.com/maypok86/payment-api/internal/domain/currency"
+	"github.com/maypok86/payment-api/internal/domain/order"
+	"github.com/maypok86/payment-api/internal/domain/report"
+	"github.com/maypok86/payment-api/internal/domain/transaction"
+)
+
+//go:generate mockgen -source=service.go -destination=mock_test.go -package=grpc_test
+
+type AccountService interface {
+	GetBalanceByID(ctx context.Context, id int64, cur currency.Currency) (account.Account, error)
+	AddBalance(ctx context.Context, dto account.AddBalanceDTO) (int64, error)
+	TransferBalance(ctx context.Context, dto account.TransferBalanceDTO) (int64, int64, error)
+}
+
+type TransactionService interface {
+	GetTransactionsByAccountID(
+		ctx context.Context,
+		accountID int64,
+		listParams transaction.ListParams,
+	) (transaction.List, error)
+}
+
+type OrderService interface {
+	CreateOrder(ctx context.Context, dto order.CreateDTO) (order.Order, int64, error)
+	PayForOrder(ctx context.Context, dto order.PayForDTO) error
+	CaptureOrder(ctx context.Context, dto order.CaptureDTO) (order.Order, error)
+	CancelOrder(ctx context.Context, dto order.CancelDTO) (int64, error)
+	RefundOrder(ctx context.Context, dto order.RefundDTO) (order.Order, int64, error)
+}
+
+type ReportService interface {
+	GetReportKey(ctx context.Context, dto report.GetMapDTO) (string, error)
+	GetReportContent(ctx context.Context, filename string) (report.Content, error)
+	CreateJob(ctx context.Context, dto report.CreateJobDTO) (report.Job, error)
+	GetJob(ctx context.Context, jobID int64, principal string) (report.Job, error)
+}
@@ -0,0 +1,102 @@
+package grpc
+
+import (
+	"context"
+
+	paymentv1 "github.com/maypok86/payment-api/api/proto/payment/v1"
+	"github.com/maypok86/payment-api/internal/domain/transaction"
+	"github.com/maypok86/payment-api/internal/pkg/pagination"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+type TransactionHandler struct {
+	paymentv1.UnimplementedTransactionServiceServer
+	baseHandler
+	service TransactionService
+}
+
+func NewTransactionHandler(service TransactionService, logger *zap.Logger) *TransactionHandler {
+	return &TransactionHandler{
+		baseHandler: baseHandler{logger: logger},
+		service:     service,
+	}
+}
+
+func (h *TransactionHandler) GetTransactions(
+	ctx context.Context,
+	request *paymentv1.GetTransactionsRequest,
+) (*paymentv1.GetTransactionsResponse, error) {
+	const message = "Get transactions by account id error"
+
+	if err := positive("account_id", request.GetAccountId()); err != nil {
+		return nil, h.error(err, message)
+	}
+
+	params := newPaginationParams(request.GetLimit(), request.GetOffset())
+	listParams, err := transaction.NewListParams(request.GetSort(), request.GetDirection(), params)
+	if err != nil {
+		return nil, h.error(err, message)
+	}
+
+	list, err := h.service.GetTransactionsByAccountID(ctx, request.GetAccountId(), listParams)
+	if err != nil {
+		return nil, h.error(err, message)
+	}
+
+	response := &paymentv1.GetTransactionsResponse{
+		Transactions: make([]*paymentv1.Transaction, 0, len(list.Transactions)),
+		Range: &paymentv1.ListRange{
+			Limit:  params.Limit,
+			Offset: params.Offset,
+		},
+	}
+	if list.Count != nil {
+		response.Range.Count = int64(*list.Count)
+	}
+	for _, entity := range list.Transactions {
+		response.Transactions = append(response.Transactions, newTransaction(entity))
+	}
+
+	return response, nil
+}
+
+// newPaginationParams uses the default limit for zero and caps the limit at the maximum.
+func newPaginationParams(limit, offset uint64) pagination.Params {
+	if limit == 0 {
+		limit = pagination.DefaultLimit
+	}
+
+	if limit > pagination.MaxLimit {
+		limit = pagination.MaxLimit
+	}
+
+	return pagination.Params{
+		Limit:  limit,
+		Offset: offset,
+	}
+}
+
+func newTransaction(entity transaction.Transaction) *paymentv1.Transaction {
+	response := &paymentv1.Transaction{
+		TransactionId: entity.TransactionID,
+		Type:          entity.Type.String(),
+		SenderId:      entity.SenderID,
+		ReceiverId:    entity.ReceiverID,
+		Amount:        entity.Amount,
+		Currency:      entity.Currency.String(),
+		Description:   entity.Description,
+		CreatedAt:     timestamppb.New(entity.CreatedAt),
+	}
+
+	if entity.Conversion != nil {
+		response.Conversion = &paymentv1.Conversion{
+			Rate:              entity.Conversion.Rate,
+			Amount:            entity.Conversion.Amount,
+			Currency:          entity.Conversion.Currency.String(),
+			RoundingRemainder: entity.Conversion.Remainder,
+		}
+	}
+
+	return response
+}
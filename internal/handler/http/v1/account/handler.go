@@ -3,10 +3,14 @@ package account
 import (
 	"context"
 	"errors"
+	"fmt"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
 	"github.com/maypok86/payment-api/internal/domain/account"
+	"github.com/maypok86/payment-api/internal/domain/currency"
 	"github.com/maypok86/payment-api/internal/pkg/handler"
 	"go.uber.org/zap"
 )
@@ -14,9 +18,15 @@ import (
 //go:generate mockgen -source=handler.go -destination=mock_test.go -package=account_test
 
 type Service interface {
-	GetBalanceByID(ctx context.Context, id int64) (int64, error)
+	CreateAccount(ctx context.Context, accountID int64) (account.Account, error)
+	FreezeAccount(ctx context.Context, accountID int64) (account.Account, error)
+	UnfreezeAccount(ctx context.Context, accountID int64) (account.Account, error)
+	CloseAccount(ctx context.Context, accountID int64) (account.Account, error)
+	SetCreditLimit(ctx context.Context, dto account.SetCreditLimitDTO) (account.Account, error)
+	GetBalanceByID(ctx context.Context, id int64, cur currency.Currency) (account.Account, error)
 	AddBalance(ctx context.Context, dto account.AddBalanceDTO) (int64, error)
 	TransferBalance(ctx context.Context, dto account.TransferBalanceDTO) (int64, int64, error)
+	TransferBatch(ctx context.Context, dto account.TransferBatchDTO) ([]account.TransferResult, error)
 }
 
 type Handler struct {
@@ -34,12 +44,127 @@ func NewHandler(service Service, logger *zap.Logger) *Handler {
 }
 
 func (h *Handler) InitAPI(router *gin.RouterGroup) {
+	accountGroup := router.Group("/account")
+	{
+		accountGroup.POST("", h.CreateAccount)
+		accountGroup.POST("/:account_id/freeze", h.FreezeAccount)
+		accountGroup.POST("/:account_id/unfreeze", h.UnfreezeAccount)
+		accountGroup.POST("/:account_id/close", h.CloseAccount)
+		accountGroup.POST("/:account_id/credit-limit", h.SetCreditLimit)
+	}
+
 	balanceGroup := router.Group("/balance")
 	{
 		balanceGroup.GET("/:account_id", h.GetBalance)
 		balanceGroup.POST("/add", h.AddBalance)
 		balanceGroup.POST("/transfer", h.TransferBalance)
+		balanceGroup.POST("/transfer/batch", h.TransferBatch)
+	}
+}
+
+func (h *Handler) CreateAccount(c *gin.Context) {
+	var request CreateAccountRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		h.ErrorResponse(c, http.StatusBadRequest, err, "Create account error. Invalid request")
+		return
+	}
+
+	entity, err := h.service.CreateAccount(c.Request.Context(), request.AccountID)
+	if err != nil {
+		if errors.Is(err, account.ErrAlreadyExist) {
+			h.ErrorResponse(c, http.StatusConflict, err, "Create account error. Account already exist")
+			return
+		}
+
+		h.ErrorResponse(c, http.StatusInternalServerError, err, "Create account error")
+		return
+	}
+
+	c.JSON(http.StatusOK, NewAccountResponse(entity))
+}
+
+func (h *Handler) FreezeAccount(c *gin.Context) {
+	h.moveAccount(c, h.service.FreezeAccount, "Freeze account error")
+}
+
+func (h *Handler) UnfreezeAccount(c *gin.Context) {
+	h.moveAccount(c, h.service.UnfreezeAccount, "Unfreeze account error")
+}
+
+func (h *Handler) CloseAccount(c *gin.Context) {
+	h.moveAccount(c, h.service.CloseAccount, "Close account error")
+}
+
+func (h *Handler) SetCreditLimit(c *gin.Context) {
+	accountID, err := h.ParseIDFromPath(c, "account_id")
+	if err != nil {
+		h.ErrorResponse(c, http.StatusBadRequest, err, "Set credit limit error. id is not valid")
+		return
+	}
+
+	var request SetCreditLimitRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		h.ErrorResponse(c, http.StatusBadRequest, err, "Set credit limit error. Invalid request")
+		return
 	}
+
+	entity, err := h.service.SetCreditLimit(c.Request.Context(), request.ToDTO(accountID))
+	if err != nil {
+		switch {
+		case errors.Is(err, currency.ErrInvalidAmount):
+			h.ErrorResponse(c, http.StatusBadRequest, err, "Set credit limit error. Credit limit does not fit the currency")
+			return
+		case errors.Is(err, account.ErrNotFound):
+			h.ErrorResponse(c, http.StatusNotFound, err, "Set credit limit error. Account not found")
+			return
+		case errors.Is(err, account.ErrClosed):
+			h.ErrorResponse(c, http.StatusConflict, err, "Set credit limit error. Account is closed")
+			return
+		case errors.Is(err, account.ErrCreditLimitTooLow):
+			h.ErrorResponse(c, http.StatusConflict, err, "Set credit limit error. Balance is below the new credit limit")
+			return
+		}
+
+		h.ErrorResponse(c, http.StatusInternalServerError, err, "Set credit limit error")
+		return
+	}
+
+	c.JSON(http.StatusOK, NewCreditLimitResponse(entity))
+}
+
+func (h *Handler) moveAccount(
+	c *gin.Context,
+	move func(ctx context.Context, accountID int64) (account.Account, error),
+	message string,
+) {
+	accountID, err := h.ParseIDFromPath(c, "account_id")
+	if err != nil {
+		h.ErrorResponse(c, http.StatusBadRequest, err, message+". id is not valid")
+		return
+	}
+
+	entity, err := move(c.Request.Context(), accountID)
+	if err != nil {
+		switch {
+		case errors.Is(err, account.ErrNotFound):
+			h.ErrorResponse(c, http.StatusNotFound, err, message+". Account not found")
+			return
+		case errors.Is(err, account.ErrIllegalTransition):
+			h.ErrorResponse(c, http.StatusConflict, err, message+". Illegal status transition")
+			return
+		case errors.Is(err, account.ErrNonZeroBalance):
+			h.ErrorResponse(c, http.StatusConflict, err, message+". Balance is not zero")
+			return
+		case errors.Is(err, account.ErrOpenReservations):
+			h.ErrorResponse(c, http.StatusConflict, err, message+". Account has open reservations")
+			return
+		}
+
+		h.ErrorResponse(c, http.StatusInternalServerError, err, message)
+		return
+	}
+
+	c.JSON(http.StatusOK, NewAccountResponse(entity))
 }
 
 func (h *Handler) GetBalance(c *gin.Context) {
@@ -49,7 +174,16 @@ func (h *Handler) GetBalance(c *gin.Context) {
 		return
 	}
 
-	balance, err := h.service.GetBalanceByID(c.Request.Context(), accountID)
+	cur := currency.Default
+	if code := c.Query("currency"); code != "" {
+		cur, err = currency.Parse(code)
+		if err != nil {
+			h.ErrorResponse(c, http.StatusBadRequest, err, "Balance not found. currency is not valid")
+			return
+		}
+	}
+
+	entity, err := h.service.GetBalanceByID(c.Request.Context(), accountID, cur)
 	if err != nil {
 		if errors.Is(err, account.ErrNotFound) {
 			h.ErrorResponse(c, http.StatusNotFound, err, "Get balance by error. Account not found")
@@ -60,9 +194,7 @@ func (h *Handler) GetBalance(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, GetBalanceResponse{
-		Balance: balance,
-	})
+	c.JSON(http.StatusOK, NewGetBalanceResponse(entity))
 }
 
 func (h *Handler) AddBalance(c *gin.Context) {
@@ -74,6 +206,18 @@ func (h *Handler) AddBalance(c *gin.Context) {
 
 	balance, err := h.service.AddBalance(c.Request.Context(), request.ToDTO())
 	if err != nil {
+		switch {
+		case errors.Is(err, currency.ErrInvalidAmount):
+			h.ErrorResponse(c, http.StatusBadRequest, err, "Add balance error. Amount does not fit the currency")
+			return
+		case errors.Is(err, account.ErrFrozen):
+			h.ErrorResponse(c, http.StatusConflict, err, "Add balance error. Account is frozen")
+			return
+		case errors.Is(err, account.ErrClosed):
+			h.ErrorResponse(c, http.StatusConflict, err, "Add balance error. Account is closed")
+			return
+		}
+
 		h.ErrorResponse(c, http.StatusInternalServerError, err, "Add balance error")
 		return
 	}
@@ -97,6 +241,44 @@ func (h *Handler) TransferBalance(c *gin.Context) {
 			return
 		}
 
+		var fundsErr *account.InsufficientFundsError
+		if errors.As(err, &fundsErr) {
+			h.InsufficientFundsResponse(
+				c,
+				err,
+				"Transfer balance error. Insufficient funds",
+				fundsErr.Available,
+				fundsErr.CreditLimit,
+				fundsErr.Currency.String(),
+			)
+			return
+		}
+
+		if errors.Is(err, account.ErrFrozen) {
+			h.ErrorResponse(c, http.StatusConflict, err, "Transfer balance error. Sender or receiver is frozen")
+			return
+		}
+
+		if errors.Is(err, account.ErrClosed) {
+			h.ErrorResponse(c, http.StatusConflict, err, "Transfer balance error. Sender or receiver is closed")
+			return
+		}
+
+		if errors.Is(err, currency.ErrInvalidAmount) {
+			h.ErrorResponse(c, http.StatusBadRequest, err, "Transfer balance error. Amount does not fit the currency")
+			return
+		}
+
+		if errors.Is(err, account.ErrRateNotFound) {
+			h.ErrorResponse(c, http.StatusBadRequest, err, "Transfer balance error. Exchange rate not found")
+			return
+		}
+
+		if errors.Is(err, account.ErrAmountTooSmall) {
+			h.ErrorResponse(c, http.StatusBadRequest, err, "Transfer balance error. Converted amount is too small")
+			return
+		}
+
 		h.ErrorResponse(c, http.StatusInternalServerError, err, "Transfer balance error")
 		return
 	}
@@ -106,3 +288,117 @@ func (h *Handler) TransferBalance(c *gin.Context) {
 		ReceiverBalance: receiverBalance,
 	})
 }
+
+func (h *Handler) TransferBatch(c *gin.Context) {
+	var request TransferBatchRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		h.ErrorResponse(c, http.StatusBadRequest, err, "Batch not transferred. request is not valid")
+		return
+	}
+
+	var items []BatchItemResponse
+	for i, transfer := range request.Transfers {
+		if err := binding.Validator.ValidateStruct(transfer); err != nil {
+			items = append(items, BatchItemResponse{Index: i, Message: validationMessage(err)})
+		}
+	}
+	if len(items) > 0 {
+		h.batchErrorResponse(
+			c,
+			http.StatusBadRequest,
+			errors.New("transfer batch: invalid transfers"),
+			"Batch not transferred. transfers are not valid",
+			items,
+		)
+		return
+	}
+
+	results, err := h.service.TransferBatch(c.Request.Context(), request.ToDTO())
+	if err != nil {
+		switch {
+		case errors.Is(err, account.ErrEmptyBatch):
+			h.ErrorResponse(c, http.StatusBadRequest, err, "Batch not transferred. Batch is empty")
+			return
+		case errors.Is(err, account.ErrBatchTooLarge):
+			h.ErrorResponse(
+				c,
+				http.StatusBadRequest,
+				err,
+				fmt.Sprintf("Batch not transferred. Batch has more than %d transfers", account.MaxBatchSize),
+			)
+			return
+		}
+
+		var batchErr *account.BatchError
+		if errors.As(err, &batchErr) {
+			status := http.StatusInternalServerError
+			items := make([]BatchItemResponse, 0, len(batchErr.Items))
+			for i, item := range batchErr.Items {
+				itemStatus, message := batchItemError(item.Err)
+				if i == 0 {
+					status = itemStatus
+				}
+
+				items = append(items, BatchItemResponse{Index: item.Index, Message: message})
+			}
+
+			h.batchErrorResponse(c, status, err, "Batch not transferred", items)
+			return
+		}
+
+		h.ErrorResponse(c, http.StatusInternalServerError, err, "Transfer batch error")
+		return
+	}
+
+	c.JSON(http.StatusOK, NewTransferBatchResponse(results))
+}
+
+func (h *Handler) batchErrorResponse(c *gin.Context, status int, err error, message string, items []BatchItemResponse) {
+	h.logger.Error(err.Error())
+
+	c.AbortWithStatusJSON(status, BatchErrorResponse{
+		Message: message,
+		Errors:  items,
+	})
+}
+
+// batchItemError returns the status code and the message for the error of a single transfer in a batch.
+func batchItemError(err error) (int, string) {
+	var fundsErr *account.InsufficientFundsError
+	if errors.As(err, &fundsErr) {
+		return http.StatusPaymentRequired, fmt.Sprintf(
+			"Insufficient funds. Account with id = %d has %s available and %s credit limit",
+			fundsErr.AccountID,
+			fundsErr.Currency.Format(fundsErr.Available),
+			fundsErr.Currency.Format(fundsErr.CreditLimit),
+		)
+	}
+
+	switch {
+	case errors.Is(err, account.ErrNotFound):
+		return http.StatusNotFound, "Sender or receiver not found"
+	case errors.Is(err, account.ErrFrozen):
+		return http.StatusConflict, "Sender or receiver is frozen"
+	case errors.Is(err, account.ErrClosed):
+		return http.StatusConflict, "Sender or receiver is closed"
+	case errors.Is(err, account.ErrRateNotFound):
+		return http.StatusBadRequest, "Exchange rate not found"
+	case errors.Is(err, account.ErrAmountTooSmall):
+		return http.StatusBadRequest, "Converted amount is too small"
+	case errors.Is(err, currency.ErrInvalidAmount):
+		return http.StatusBadRequest, "Amount does not fit the currency"
+	}
+
+	return http.StatusInternalServerError, "Transfer error"
+}
+
+func validationMessage(err error) string {
+	var validationErrs validator.ValidationErrors
+	if !errors.As(err, &validationErrs) || len(validationErrs) == 0 {
+		return "Transfer is not valid"
+	}
+
+	fieldErr := validationErrs[0]
+
+	return fmt.Sprintf("Transfer is not valid. %s failed on the %s rule", fieldErr.Field(), fieldErr.Tag())
+}
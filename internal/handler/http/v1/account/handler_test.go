@@ -9,6 +9,7 @@ import (
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"os"
 	"reflect"
 	"testing"
@@ -16,6 +17,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/golang/mock/gomock"
 	domain "github.com/maypok86/payment-api/internal/domain/account"
+	"github.com/maypok86/payment-api/internal/domain/currency"
 	"github.com/maypok86/payment-api/internal/handler/http/v1/account"
 	"github.com/maypok86/payment-api/internal/pkg/handler"
 	"github.com/maypok86/payment-api/internal/pkg/logger"
@@ -50,12 +52,25 @@ func TestHandler_GetBalance(t *testing.T) {
 
 	fakeAccountID := int64(1)
 	fakeParam := fmt.Sprintf("%d", fakeAccountID)
-	fakeBalance := int64(100)
+	fakeAccount := domain.Account{
+		AccountID: fakeAccountID,
+		Status:    domain.Active,
+		Currency:  currency.RUB,
+		Available: 100,
+		Reserved:  30,
+	}
+	fakeResponse := account.GetBalanceResponse{
+		Balance:   100,
+		Available: 100,
+		Reserved:  30,
+		Total:     130,
+	}
 	accountServiceErr := errors.New("account service error")
 
-	setupGin := func(c *gin.Context, param string) {
+	setupGin := func(c *gin.Context, param, query string) {
 		c.Request.Method = http.MethodGet
 		c.Request.Header.Set("Content-Type", "application/json")
+		c.Request.URL = &url.URL{RawQuery: query}
 		c.Params = gin.Params{{Key: "account_id", Value: param}}
 	}
 
@@ -63,6 +78,7 @@ func TestHandler_GetBalance(t *testing.T) {
 
 	type args struct {
 		param string
+		query string
 	}
 
 	tests := []struct {
@@ -88,7 +104,7 @@ func TestHandler_GetBalance(t *testing.T) {
 		{
 			name: "account not found",
 			mock: func(service *MockService) {
-				service.EXPECT().GetBalanceByID(ctx, fakeAccountID).Return(int64(0), domain.ErrNotFound)
+				service.EXPECT().GetBalanceByID(ctx, fakeAccountID, currency.RUB).Return(domain.Account{}, domain.ErrNotFound)
 			},
 			args: args{
 				param: fakeParam,
@@ -101,7 +117,7 @@ func TestHandler_GetBalance(t *testing.T) {
 		{
 			name: "account service error",
 			mock: func(service *MockService) {
-				service.EXPECT().GetBalanceByID(ctx, fakeAccountID).Return(int64(0), accountServiceErr)
+				service.EXPECT().GetBalanceByID(ctx, fakeAccountID, currency.RUB).Return(domain.Account{}, accountServiceErr)
 			},
 			args: args{
 				param: fakeParam,
@@ -114,14 +130,37 @@ func TestHandler_GetBalance(t *testing.T) {
 		{
 			name: "success get balance",
 			mock: func(service *MockService) {
-				service.EXPECT().GetBalanceByID(ctx, fakeAccountID).Return(fakeBalance, nil)
+				service.EXPECT().GetBalanceByID(ctx, fakeAccountID, currency.RUB).Return(fakeAccount, nil)
 			},
 			args: args{
 				param: fakeParam,
 			},
-			response: account.GetBalanceResponse{
-				Balance: fakeBalance,
+			response:   fakeResponse,
+			statusCode: http.StatusOK,
+		},
+		{
+			name: "invalid currency query",
+			mock: func(service *MockService) {
+			},
+			args: args{
+				param: fakeParam,
+				query: "currency=XXX",
+			},
+			wantedErrorResponse: &handler.ErrorResponse{
+				Message: "Balance not found. currency is not valid",
 			},
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name: "success get balance in currency",
+			mock: func(service *MockService) {
+				service.EXPECT().GetBalanceByID(ctx, fakeAccountID, currency.USD).Return(fakeAccount, nil)
+			},
+			args: args{
+				param: fakeParam,
+				query: "currency=USD",
+			},
+			response:   fakeResponse,
 			statusCode: http.StatusOK,
 		},
 	}
@@ -131,7 +170,7 @@ func TestHandler_GetBalance(t *testing.T) {
 			w := httptest.NewRecorder()
 			accountHandler, accountService, c := mockHandler(t, w)
 
-			setupGin(c, tt.args.param)
+			setupGin(c, tt.args.param, tt.args.query)
 			tt.mock(accountService)
 
 			accountHandler.GetBalance(c)
@@ -212,6 +251,32 @@ func TestHandler_AddBalance(t *testing.T) {
 			},
 			statusCode: http.StatusInternalServerError,
 		},
+		{
+			name: "account is frozen",
+			mock: func(service *MockService) {
+				service.EXPECT().AddBalance(ctx, fakeRequest.ToDTO()).Return(int64(0), domain.ErrFrozen)
+			},
+			args: args{
+				request: fakeRequest,
+			},
+			wantedErrorResponse: &handler.ErrorResponse{
+				Message: "Add balance error. Account is frozen",
+			},
+			statusCode: http.StatusConflict,
+		},
+		{
+			name: "amount does not fit currency",
+			mock: func(service *MockService) {
+				service.EXPECT().AddBalance(ctx, fakeRequest.ToDTO()).Return(int64(0), currency.ErrInvalidAmount)
+			},
+			args: args{
+				request: fakeRequest,
+			},
+			wantedErrorResponse: &handler.ErrorResponse{
+				Message: "Add balance error. Amount does not fit the currency",
+			},
+			statusCode: http.StatusBadRequest,
+		},
 		{
 			name: "success add balance",
 			mock: func(service *MockService) {
@@ -259,6 +324,13 @@ func TestHandler_TransferBalance(t *testing.T) {
 		ReceiverID: 2,
 		Amount:     100,
 	}
+	conversionRequest := account.TransferBalanceRequest{
+		SenderID:         1,
+		ReceiverID:       2,
+		Amount:           100,
+		Currency:         currency.USD,
+		ReceiverCurrency: currency.RUB,
+	}
 	fakeResponse := account.TransferBalanceResponse{
 		SenderBalance:   100,
 		ReceiverBalance: 200,
@@ -320,6 +392,51 @@ func TestHandler_TransferBalance(t *testing.T) {
 			},
 			statusCode: http.StatusNotFound,
 		},
+		{
+			name: "receiver is closed",
+			mock: func(service *MockService) {
+				service.EXPECT().
+					TransferBalance(ctx, fakeRequest.ToDTO()).
+					Return(int64(0), int64(0), domain.ErrClosed)
+			},
+			args: args{
+				request: fakeRequest,
+			},
+			wantedErrorResponse: &handler.ErrorResponse{
+				Message: "Transfer balance error. Sender or receiver is closed",
+			},
+			statusCode: http.StatusConflict,
+		},
+		{
+			name: "exchange rate not found",
+			mock: func(service *MockService) {
+				service.EXPECT().
+					TransferBalance(ctx, conversionRequest.ToDTO()).
+					Return(int64(0), int64(0), domain.ErrRateNotFound)
+			},
+			args: args{
+				request: conversionRequest,
+			},
+			wantedErrorResponse: &handler.ErrorResponse{
+				Message: "Transfer balance error. Exchange rate not found",
+			},
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name: "converted amount is too small",
+			mock: func(service *MockService) {
+				service.EXPECT().
+					TransferBalance(ctx, conversionRequest.ToDTO()).
+					Return(int64(0), int64(0), domain.ErrAmountTooSmall)
+			},
+			args: args{
+				request: conversionRequest,
+			},
+			wantedErrorResponse: &handler.ErrorResponse{
+				Message: "Transfer balance error. Converted amount is too small",
+			},
+			statusCode: http.StatusBadRequest,
+		},
 		{
 			name: "account service error",
 			mock: func(service *MockService) {
@@ -371,3 +488,652 @@ func TestHandler_TransferBalance(t *testing.T) {
 		})
 	}
 }
+
+func TestHandler_TransferBatch(t *testing.T) {
+	ctx := context.Background()
+
+	fakeRequest := account.TransferBatchRequest{
+		Transfers: []account.TransferBalanceRequest{
+			{SenderID: 1, ReceiverID: 2, Amount: 100},
+			{SenderID: 1, ReceiverID: 3, Amount: 50, ReceiverCurrency: currency.USD},
+		},
+	}
+	accountServiceErr := errors.New("account service error")
+
+	setupGin := func(c *gin.Context, content interface{}) {
+		c.Request.Method = http.MethodPost
+		c.Request.Header.Set("Content-Type", "application/json")
+
+		data, err := json.Marshal(content)
+		require.NoError(t, err)
+
+		c.Request.Body = io.NopCloser(bytes.NewBuffer(data))
+	}
+
+	type mockBehaviour func(service *MockService)
+
+	tests := []struct {
+		name                string
+		mock                mockBehaviour
+		request             interface{}
+		response            account.TransferBatchResponse
+		wantedErrorResponse *handler.ErrorResponse
+		wantedBatchResponse *account.BatchErrorResponse
+		statusCode          int
+	}{
+		{
+			name: "invalid request",
+			mock: func(service *MockService) {
+			},
+			request: map[string]interface{}{},
+			wantedErrorResponse: &handler.ErrorResponse{
+				Message: "Batch not transferred. request is not valid",
+			},
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name: "invalid transfers",
+			mock: func(service *MockService) {
+			},
+			request: account.TransferBatchRequest{
+				Transfers: []account.TransferBalanceRequest{
+					{SenderID: 1, ReceiverID: 2, Amount: 100},
+					{SenderID: 1, ReceiverID: 2, Amount: -1},
+					{SenderID: 1, Amount: 100},
+				},
+			},
+			wantedBatchResponse: &account.BatchErrorResponse{
+				Message: "Batch not transferred. transfers are not valid",
+				Errors: []account.BatchItemResponse{
+					{Index: 1, Message: "Transfer is not valid. Amount failed on the gt rule"},
+					{Index: 2, Message: "Transfer is not valid. ReceiverID failed on the required rule"},
+				},
+			},
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name: "empty batch",
+			mock: func(service *MockService) {
+				service.EXPECT().
+					TransferBatch(ctx, domain.TransferBatchDTO{Transfers: []domain.TransferBalanceDTO{}}).
+					Return(nil, fmt.Errorf("transfer batch: %w", domain.ErrEmptyBatch))
+			},
+			request: account.TransferBatchRequest{Transfers: []account.TransferBalanceRequest{}},
+			wantedErrorResponse: &handler.ErrorResponse{
+				Message: "Batch not transferred. Batch is empty",
+			},
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name: "exchange rate not found",
+			mock: func(service *MockService) {
+				service.EXPECT().
+					TransferBatch(ctx, fakeRequest.ToDTO()).
+					Return(nil, fmt.Errorf("transfer batch: %w", &domain.BatchError{
+						Items: []domain.BatchItemError{{Index: 1, Err: domain.ErrRateNotFound}},
+					}))
+			},
+			request: fakeRequest,
+			wantedBatchResponse: &account.BatchErrorResponse{
+				Message: "Batch not transferred",
+				Errors:  []account.BatchItemResponse{{Index: 1, Message: "Exchange rate not found"}},
+			},
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name: "insufficient funds",
+			mock: func(service *MockService) {
+				service.EXPECT().
+					TransferBatch(ctx, fakeRequest.ToDTO()).
+					Return(nil, fmt.Errorf("transfer batch: %w", &domain.BatchError{
+						Items: []domain.BatchItemError{{Index: 1, Err: &domain.InsufficientFundsError{
+							AccountID:   1,
+							Currency:    currency.RUB,
+							Available:   40,
+							CreditLimit: 10,
+						}}},
+					}))
+			},
+			request: fakeRequest,
+			wantedBatchResponse: &account.BatchErrorResponse{
+				Message: "Batch not transferred",
+				Errors: []account.BatchItemResponse{
+					{
+						Index:   1,
+						Message: "Insufficient funds. Account with id = 1 has 0.40 RUB available and 0.10 RUB credit limit",
+					},
+				},
+			},
+			statusCode: http.StatusPaymentRequired,
+		},
+		{
+			name: "receiver is frozen",
+			mock: func(service *MockService) {
+				service.EXPECT().
+					TransferBatch(ctx, fakeRequest.ToDTO()).
+					Return(nil, &domain.BatchError{Items: []domain.BatchItemError{{Index: 0, Err: domain.ErrFrozen}}})
+			},
+			request: fakeRequest,
+			wantedBatchResponse: &account.BatchErrorResponse{
+				Message: "Batch not transferred",
+				Errors:  []account.BatchItemResponse{{Index: 0, Message: "Sender or receiver is frozen"}},
+			},
+			statusCode: http.StatusConflict,
+		},
+		{
+			name: "account service error",
+			mock: func(service *MockService) {
+				service.EXPECT().TransferBatch(ctx, fakeRequest.ToDTO()).Return(nil, accountServiceErr)
+			},
+			request: fakeRequest,
+			wantedErrorResponse: &handler.ErrorResponse{
+				Message: "Transfer batch error",
+			},
+			statusCode: http.StatusInternalServerError,
+		},
+		{
+			name: "success transfer batch",
+			mock: func(service *MockService) {
+				service.EXPECT().
+					TransferBatch(ctx, fakeRequest.ToDTO()).
+					Return([]domain.TransferResult{
+						{SenderBalance: 900, ReceiverBalance: 100},
+						{SenderBalance: 850, ReceiverBalance: 1},
+					}, nil)
+			},
+			request: fakeRequest,
+			response: account.TransferBatchResponse{
+				Transfers: []account.TransferBalanceResponse{
+					{SenderBalance: 900, ReceiverBalance: 100},
+					{SenderBalance: 850, ReceiverBalance: 1},
+				},
+			},
+			statusCode: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			accountHandler, accountService, c := mockHandler(t, w)
+
+			setupGin(c, tt.request)
+			tt.mock(accountService)
+
+			accountHandler.TransferBatch(c)
+
+			require.Equal(t, tt.statusCode, w.Code)
+			switch {
+			case tt.wantedErrorResponse != nil:
+				var response handler.ErrorResponse
+				require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+				require.True(t, reflect.DeepEqual(tt.wantedErrorResponse, &response))
+			case tt.wantedBatchResponse != nil:
+				var response account.BatchErrorResponse
+				require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+				require.True(t, reflect.DeepEqual(tt.wantedBatchResponse, &response))
+			default:
+				var response account.TransferBatchResponse
+				require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+				require.True(t, reflect.DeepEqual(tt.response, response))
+			}
+		})
+	}
+}
+
+func TestHandler_CreateAccount(t *testing.T) {
+	ctx := context.Background()
+
+	fakeAccount := domain.Account{
+		AccountID: 1,
+		Status:    domain.Active,
+	}
+	accountServiceErr := errors.New("account service error")
+
+	setupGin := func(c *gin.Context, content interface{}) {
+		c.Request.Method = http.MethodPost
+		c.Request.Header.Set("Content-Type", "application/json")
+
+		data, err := json.Marshal(content)
+		require.NoError(t, err)
+
+		c.Request.Body = io.NopCloser(bytes.NewBuffer(data))
+	}
+
+	type mockBehaviour func(service *MockService)
+
+	tests := []struct {
+		name                string
+		mock                mockBehaviour
+		request             account.CreateAccountRequest
+		response            account.AccountResponse
+		wantedErrorResponse *handler.ErrorResponse
+		statusCode          int
+	}{
+		{
+			name: "invalid request",
+			mock: func(service *MockService) {
+			},
+			request: account.CreateAccountRequest{
+				AccountID: 0,
+			},
+			wantedErrorResponse: &handler.ErrorResponse{
+				Message: "Create account error. Invalid request",
+			},
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name: "account already exist",
+			mock: func(service *MockService) {
+				service.EXPECT().CreateAccount(ctx, fakeAccount.AccountID).Return(domain.Account{}, domain.ErrAlreadyExist)
+			},
+			request: account.CreateAccountRequest{
+				AccountID: fakeAccount.AccountID,
+			},
+			wantedErrorResponse: &handler.ErrorResponse{
+				Message: "Create account error. Account already exist",
+			},
+			statusCode: http.StatusConflict,
+		},
+		{
+			name: "account service error",
+			mock: func(service *MockService) {
+				service.EXPECT().CreateAccount(ctx, fakeAccount.AccountID).Return(domain.Account{}, accountServiceErr)
+			},
+			request: account.CreateAccountRequest{
+				AccountID: fakeAccount.AccountID,
+			},
+			wantedErrorResponse: &handler.ErrorResponse{
+				Message: "Create account error",
+			},
+			statusCode: http.StatusInternalServerError,
+		},
+		{
+			name: "success create account",
+			mock: func(service *MockService) {
+				service.EXPECT().CreateAccount(ctx, fakeAccount.AccountID).Return(fakeAccount, nil)
+			},
+			request: account.CreateAccountRequest{
+				AccountID: fakeAccount.AccountID,
+			},
+			response: account.AccountResponse{
+				AccountID: fakeAccount.AccountID,
+				Status:    "active",
+			},
+			statusCode: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			accountHandler, accountService, c := mockHandler(t, w)
+
+			setupGin(c, tt.request)
+			tt.mock(accountService)
+
+			accountHandler.CreateAccount(c)
+
+			require.Equal(t, tt.statusCode, w.Code)
+			if tt.wantedErrorResponse != nil {
+				var response handler.ErrorResponse
+				require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+				require.True(t, reflect.DeepEqual(tt.wantedErrorResponse, &response))
+			} else {
+				var response account.AccountResponse
+				require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+				require.True(t, reflect.DeepEqual(tt.response, response))
+			}
+		})
+	}
+}
+
+func TestHandler_MoveAccount(t *testing.T) {
+	ctx := context.Background()
+
+	fakeAccountID := int64(1)
+	fakeParam := fmt.Sprintf("%d", fakeAccountID)
+
+	setupGin := func(c *gin.Context, param string) {
+		c.Request.Method = http.MethodPost
+		c.Request.Header.Set("Content-Type", "application/json")
+		c.Params = gin.Params{{Key: "account_id", Value: param}}
+	}
+
+	type mockBehaviour func(service *MockService)
+
+	tests := []struct {
+		name                string
+		mock                mockBehaviour
+		handle              func(h *account.Handler, c *gin.Context)
+		param               string
+		response            account.AccountResponse
+		wantedErrorResponse *handler.ErrorResponse
+		statusCode          int
+	}{
+		{
+			name: "invalid account_id param",
+			mock: func(service *MockService) {
+			},
+			handle: (*account.Handler).FreezeAccount,
+			param:  "invalid",
+			wantedErrorResponse: &handler.ErrorResponse{
+				Message: "Freeze account error. id is not valid",
+			},
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name: "success freeze account",
+			mock: func(service *MockService) {
+				service.EXPECT().
+					FreezeAccount(ctx, fakeAccountID).
+					Return(domain.Account{AccountID: fakeAccountID, Status: domain.Frozen}, nil)
+			},
+			handle: (*account.Handler).FreezeAccount,
+			param:  fakeParam,
+			response: account.AccountResponse{
+				AccountID: fakeAccountID,
+				Status:    "frozen",
+			},
+			statusCode: http.StatusOK,
+		},
+		{
+			name: "unfreeze closed account",
+			mock: func(service *MockService) {
+				service.EXPECT().
+					UnfreezeAccount(ctx, fakeAccountID).
+					Return(domain.Account{}, &domain.TransitionError{AccountID: fakeAccountID})
+			},
+			handle: (*account.Handler).UnfreezeAccount,
+			param:  fakeParam,
+			wantedErrorResponse: &handler.ErrorResponse{
+				Message: "Unfreeze account error. Illegal status transition",
+			},
+			statusCode: http.StatusConflict,
+		},
+		{
+			name: "close account not found",
+			mock: func(service *MockService) {
+				service.EXPECT().CloseAccount(ctx, fakeAccountID).Return(domain.Account{}, domain.ErrNotFound)
+			},
+			handle: (*account.Handler).CloseAccount,
+			param:  fakeParam,
+			wantedErrorResponse: &handler.ErrorResponse{
+				Message: "Close account error. Account not found",
+			},
+			statusCode: http.StatusNotFound,
+		},
+		{
+			name: "close account with balance",
+			mock: func(service *MockService) {
+				service.EXPECT().CloseAccount(ctx, fakeAccountID).Return(domain.Account{}, domain.ErrNonZeroBalance)
+			},
+			handle: (*account.Handler).CloseAccount,
+			param:  fakeParam,
+			wantedErrorResponse: &handler.ErrorResponse{
+				Message: "Close account error. Balance is not zero",
+			},
+			statusCode: http.StatusConflict,
+		},
+		{
+			name: "close account with open reservations",
+			mock: func(service *MockService) {
+				service.EXPECT().CloseAccount(ctx, fakeAccountID).Return(domain.Account{}, domain.ErrOpenReservations)
+			},
+			handle: (*account.Handler).CloseAccount,
+			param:  fakeParam,
+			wantedErrorResponse: &handler.ErrorResponse{
+				Message: "Close account error. Account has open reservations",
+			},
+			statusCode: http.StatusConflict,
+		},
+		{
+			name: "success close account",
+			mock: func(service *MockService) {
+				service.EXPECT().
+					CloseAccount(ctx, fakeAccountID).
+					Return(domain.Account{AccountID: fakeAccountID, Status: domain.Closed}, nil)
+			},
+			handle: (*account.Handler).CloseAccount,
+			param:  fakeParam,
+			response: account.AccountResponse{
+				AccountID: fakeAccountID,
+				Status:    "closed",
+			},
+			statusCode: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			accountHandler, accountService, c := mockHandler(t, w)
+
+			setupGin(c, tt.param)
+			tt.mock(accountService)
+
+			tt.handle(accountHandler, c)
+
+			require.Equal(t, tt.statusCode, w.Code)
+			if tt.wantedErrorResponse != nil {
+				var response handler.ErrorResponse
+				require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+				require.True(t, reflect.DeepEqual(tt.wantedErrorResponse, &response))
+			} else {
+				var response account.AccountResponse
+				require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+				require.True(t, reflect.DeepEqual(tt.response, response))
+			}
+		})
+	}
+}
+
+func TestHandler_TransferBalanceInsufficientFunds(t *testing.T) {
+	ctx := context.Background()
+
+	request := account.TransferBalanceRequest{
+		SenderID:   1,
+		ReceiverID: 2,
+		Amount:     100,
+		Currency:   currency.USD,
+	}
+
+	w := httptest.NewRecorder()
+	accountHandler, accountService, c := mockHandler(t, w)
+
+	c.Request.Method = http.MethodPost
+	c.Request.Header.Set("Content-Type", "application/json")
+	data, err := json.Marshal(request)
+	require.NoError(t, err)
+	c.Request.Body = io.NopCloser(bytes.NewBuffer(data))
+
+	accountService.EXPECT().
+		TransferBalance(ctx, request.ToDTO()).
+		Return(int64(0), int64(0), fmt.Errorf("transfer balance: %w", &domain.InsufficientFundsError{
+			AccountID:   1,
+			Currency:    currency.USD,
+			Available:   40,
+			CreditLimit: 10,
+		}))
+
+	accountHandler.TransferBalance(c)
+
+	require.Equal(t, http.StatusPaymentRequired, w.Code)
+
+	var response handler.InsufficientFundsResponse
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+	require.Equal(t, handler.InsufficientFundsResponse{
+		Message:     "Transfer balance error. Insufficient funds",
+		Available:   40,
+		CreditLimit: 10,
+		Currency:    "USD",
+	}, response)
+}
+
+func TestHandler_SetCreditLimit(t *testing.T) {
+	ctx := context.Background()
+
+	fakeAccountID := int64(1)
+	fakeParam := fmt.Sprintf("%d", fakeAccountID)
+	fakeRequest := account.SetCreditLimitRequest{
+		CreditLimit: 500,
+	}
+	accountServiceErr := errors.New("account service error")
+
+	setupGin := func(c *gin.Context, param string, content interface{}) {
+		c.Request.Method = http.MethodPost
+		c.Request.Header.Set("Content-Type", "application/json")
+		c.Params = gin.Params{{Key: "account_id", Value: param}}
+
+		data, err := json.Marshal(content)
+		require.NoError(t, err)
+
+		c.Request.Body = io.NopCloser(bytes.NewBuffer(data))
+	}
+
+	type mockBehaviour func(service *MockService)
+
+	tests := []struct {
+		name                string
+		mock                mockBehaviour
+		param               string
+		request             account.SetCreditLimitRequest
+		response            account.CreditLimitResponse
+		wantedErrorResponse *handler.ErrorResponse
+		statusCode          int
+	}{
+		{
+			name: "invalid account_id param",
+			mock: func(service *MockService) {
+			},
+			param:   "invalid",
+			request: fakeRequest,
+			wantedErrorResponse: &handler.ErrorResponse{
+				Message: "Set credit limit error. id is not valid",
+			},
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name: "invalid request",
+			mock: func(service *MockService) {
+			},
+			param: fakeParam,
+			request: account.SetCreditLimitRequest{
+				CreditLimit: -1,
+			},
+			wantedErrorResponse: &handler.ErrorResponse{
+				Message: "Set credit limit error. Invalid request",
+			},
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name: "account not found",
+			mock: func(service *MockService) {
+				service.EXPECT().
+					SetCreditLimit(ctx, fakeRequest.ToDTO(fakeAccountID)).
+					Return(domain.Account{}, domain.ErrNotFound)
+			},
+			param:   fakeParam,
+			request: fakeRequest,
+			wantedErrorResponse: &handler.ErrorResponse{
+				Message: "Set credit limit error. Account not found",
+			},
+			statusCode: http.StatusNotFound,
+		},
+		{
+			name: "credit limit is too low",
+			mock: func(service *MockService) {
+				service.EXPECT().
+					SetCreditLimit(ctx, fakeRequest.ToDTO(fakeAccountID)).
+					Return(domain.Account{}, domain.ErrCreditLimitTooLow)
+			},
+			param:   fakeParam,
+			request: fakeRequest,
+			wantedErrorResponse: &handler.ErrorResponse{
+				Message: "Set credit limit error. Balance is below the new credit limit",
+			},
+			statusCode: http.StatusConflict,
+		},
+		{
+			name: "account service error",
+			mock: func(service *MockService) {
+				service.EXPECT().
+					SetCreditLimit(ctx, fakeRequest.ToDTO(fakeAccountID)).
+					Return(domain.Account{}, accountServiceErr)
+			},
+			param:   fakeParam,
+			request: fakeRequest,
+			wantedErrorResponse: &handler.ErrorResponse{
+				Message: "Set credit limit error",
+			},
+			statusCode: http.StatusInternalServerError,
+		},
+		{
+			name: "success set credit limit",
+			mock: func(service *MockService) {
+				service.EXPECT().
+					SetCreditLimit(ctx, fakeRequest.ToDTO(fakeAccountID)).
+					Return(domain.Account{
+						AccountID:   fakeAccountID,
+						Status:      domain.Active,
+						Currency:    currency.RUB,
+						Available:   -100,
+						Reserved:    50,
+						CreditLimit: 500,
+					}, nil)
+			},
+			param:   fakeParam,
+			request: fakeRequest,
+			response: account.CreditLimitResponse{
+				AccountID:   fakeAccountID,
+				Status:      "active",
+				Currency:    "RUB",
+				Available:   -100,
+				Reserved:    50,
+				Total:       -50,
+				CreditLimit: 500,
+			},
+			statusCode: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			accountHandler, accountService, c := mockHandler(t, w)
+
+			setupGin(c, tt.param, tt.request)
+			tt.mock(accountService)
+
+			accountHandler.SetCreditLimit(c)
+
+			require.Equal(t, tt.statusCode, w.Code)
+			if tt.wantedErrorResponse != nil {
+				var response handler.ErrorResponse
+				require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+				require.True(t, reflect.DeepEqual(tt.wantedErrorResponse, &response))
+			} else {
+				var response account.CreditLimitResponse
+				require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+				require.True(t, reflect.DeepEqual(tt.response, response))
+			}
+		})
+	}
+}
+
+func TestHandler_AddBalanceFractionalAmount(t *testing.T) {
+	w := httptest.NewRecorder()
+	accountHandler, _, c := mockHandler(t, w)
+
+	// Amounts are integer minor units, so 1.5 cents can not be represented.
+	c.Request.Method = http.MethodPost
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Request.Body = io.NopCloser(bytes.NewBufferString(`{"account_id":1,"amount":1.5,"currency":"USD"}`))
+
+	accountHandler.AddBalance(c)
+
+	require.Equal(t, http.StatusBadRequest, w.Code)
+	var response handler.ErrorResponse
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+	require.Equal(t, "Amount not added. request is not valid", response.Message)
+}
@@ -10,6 +10,7 @@ import (
 
 	gomock "github.com/golang/mock/gomock"
 	account "github.com/maypok86/payment-api/internal/domain/account"
+	currency "github.com/maypok86/payment-api/internal/domain/currency"
 )
 
 // MockService is a mock of Service interface.
@@ -50,19 +51,79 @@ func (mr *MockServiceMockRecorder) AddBalance(ctx, dto interface{}) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddBalance", reflect.TypeOf((*MockService)(nil).AddBalance), ctx, dto)
 }
 
+// CloseAccount mocks base method.
+func (m *MockService) CloseAccount(ctx context.Context, accountID int64) (account.Account, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CloseAccount", ctx, accountID)
+	ret0, _ := ret[0].(account.Account)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CloseAccount indicates an expected call of CloseAccount.
+func (mr *MockServiceMockRecorder) CloseAccount(ctx, accountID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CloseAccount", reflect.TypeOf((*MockService)(nil).CloseAccount), ctx, accountID)
+}
+
+// CreateAccount mocks base method.
+func (m *MockService) CreateAccount(ctx context.Context, accountID int64) (account.Account, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAccount", ctx, accountID)
+	ret0, _ := ret[0].(account.Account)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateAccount indicates an expected call of CreateAccount.
+func (mr *MockServiceMockRecorder) CreateAccount(ctx, accountID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAccount", reflect.TypeOf((*MockService)(nil).CreateAccount), ctx, accountID)
+}
+
+// FreezeAccount mocks base method.
+func (m *MockService) FreezeAccount(ctx context.Context, accountID int64) (account.Account, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FreezeAccount", ctx, accountID)
+	ret0, _ := ret[0].(account.Account)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FreezeAccount indicates an expected call of FreezeAccount.
+func (mr *MockServiceMockRecorder) FreezeAccount(ctx, accountID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FreezeAccount", reflect.TypeOf((*MockService)(nil).FreezeAccount), ctx, accountID)
+}
+
 // GetBalanceByID mocks base method.
-func (m *MockService) GetBalanceByID(ctx context.Context, id int64) (int64, error) {
+func (m *MockService) GetBalanceByID(ctx context.Context, id int64, cur currency.Currency) (account.Account, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetBalanceByID", ctx, id)
-	ret0, _ := ret[0].(int64)
+	ret := m.ctrl.Call(m, "GetBalanceByID", ctx, id, cur)
+	ret0, _ := ret[0].(account.Account)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetBalanceByID indicates an expected call of GetBalanceByID.
-func (mr *MockServiceMockRecorder) GetBalanceByID(ctx, id interface{}) *gomock.Call {
+func (mr *MockServiceMockRecorder) GetBalanceByID(ctx, id, cur interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBalanceByID", reflect.TypeOf((*MockService)(nil).GetBalanceByID), ctx, id)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBalanceByID", reflect.TypeOf((*MockService)(nil).GetBalanceByID), ctx, id, cur)
+}
+
+// SetCreditLimit mocks base method.
+func (m *MockService) SetCreditLimit(ctx context.Context, dto account.SetCreditLimitDTO) (account.Account, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetCreditLimit", ctx, dto)
+	ret0, _ := ret[0].(account.Account)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetCreditLimit indicates an expected call of SetCreditLimit.
+func (mr *MockServiceMockRecorder) SetCreditLimit(ctx, dto interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetCreditLimit", reflect.TypeOf((*MockService)(nil).SetCreditLimit), ctx, dto)
 }
 
 // TransferBalance mocks base method.
@@ -80,3 +141,33 @@ func (mr *MockServiceMockRecorder) TransferBalance(ctx, dto interface{}) *gomock
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TransferBalance", reflect.TypeOf((*MockService)(nil).TransferBalance), ctx, dto)
 }
+
+// TransferBatch mocks base method.
+func (m *MockService) TransferBatch(ctx context.Context, dto account.TransferBatchDTO) ([]account.TransferResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TransferBatch", ctx, dto)
+	ret0, _ := ret[0].([]account.TransferResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// TransferBatch indicates an expected call of TransferBatch.
+func (mr *MockServiceMockRecorder) TransferBatch(ctx, dto interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TransferBatch", reflect.TypeOf((*MockService)(nil).TransferBatch), ctx, dto)
+}
+
+// UnfreezeAccount mocks base method.
+func (m *MockService) UnfreezeAccount(ctx context.Context, accountID int64) (account.Account, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UnfreezeAccount", ctx, accountID)
+	ret0, _ := ret[0].(account.Account)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UnfreezeAccount indicates an expected call of UnfreezeAccount.
+func (mr *MockServiceMockRecorder) UnfreezeAccount(ctx, accountID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnfreezeAccount", reflect.TypeOf((*MockService)(nil).UnfreezeAccount), ctx, accountID)
+}
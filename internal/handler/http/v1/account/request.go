@@ -1,29 +1,70 @@
 package account
 
-import "github.com/maypok86/payment-api/internal/domain/account"
+import (
+	"github.com/maypok86/payment-api/internal/domain/account"
+	"github.com/maypok86/payment-api/internal/domain/currency"
+)
 
-type AddBalanceRequest struct {
+type CreateAccountRequest struct {
 	AccountID int64 `json:"account_id" binding:"required,gte=1"`
-	Amount    int64 `json:"amount"     binding:"gte=0"`
+}
+
+type SetCreditLimitRequest struct {
+	CreditLimit int64             `json:"credit_limit" binding:"gte=0"`
+	Currency    currency.Currency `json:"currency"`
+}
+
+func (r SetCreditLimitRequest) ToDTO(accountID int64) account.SetCreditLimitDTO {
+	return account.SetCreditLimitDTO{
+		AccountID:   accountID,
+		Currency:    r.Currency.OrDefault(),
+		CreditLimit: r.CreditLimit,
+	}
+}
+
+type AddBalanceRequest struct {
+	AccountID int64             `json:"account_id" binding:"required,gte=1"`
+	Amount    int64             `json:"amount"     binding:"gte=0"`
+	Currency  currency.Currency `json:"currency"`
 }
 
 func (r AddBalanceRequest) ToDTO() account.AddBalanceDTO {
 	return account.AddBalanceDTO{
 		AccountID: r.AccountID,
 		Amount:    r.Amount,
+		Currency:  r.Currency.OrDefault(),
 	}
 }
 
 type TransferBalanceRequest struct {
-	SenderID   int64 `json:"sender_id"   binding:"required,gte=1"`
-	ReceiverID int64 `json:"receiver_id" binding:"required,gte=1"`
-	Amount     int64 `json:"amount"      binding:"required,gt=0"`
+	SenderID         int64             `json:"sender_id"         binding:"required,gte=1"`
+	ReceiverID       int64             `json:"receiver_id"       binding:"required,gte=1"`
+	Amount           int64             `json:"amount"            binding:"required,gt=0"`
+	Currency         currency.Currency `json:"currency"`
+	ReceiverCurrency currency.Currency `json:"receiver_currency"`
 }
 
 func (r TransferBalanceRequest) ToDTO() account.TransferBalanceDTO {
 	return account.TransferBalanceDTO{
-		SenderID:   r.SenderID,
-		ReceiverID: r.ReceiverID,
-		Amount:     r.Amount,
+		SenderID:         r.SenderID,
+		ReceiverID:       r.ReceiverID,
+		Amount:           r.Amount,
+		Currency:         r.Currency.OrDefault(),
+		ReceiverCurrency: r.ReceiverCurrency,
+	}
+}
+
+type TransferBatchRequest struct {
+	Transfers []TransferBalanceRequest `json:"transfers" binding:"required"`
+}
+
+func (r TransferBatchRequest) ToDTO() account.TransferBatchDTO {
+	transfers := make([]account.TransferBalanceDTO, 0, len(r.Transfers))
+	for _, transfer := range r.Transfers {
+		transfers = append(transfers, transfer.ToDTO())
+	}
+
+	return account.TransferBatchDTO{
+		Transfers: transfers,
 	}
 }
@@ -1,7 +1,56 @@
 package account
 
+import "github.com/maypok86/payment-api/internal/domain/account"
+
+type AccountResponse struct {
+	AccountID int64  `json:"account_id"`
+	Status    string `json:"status"`
+}
+
+func NewAccountResponse(entity account.Account) AccountResponse {
+	return AccountResponse{
+		AccountID: entity.AccountID,
+		Status:    entity.Status.String(),
+	}
+}
+
+type CreditLimitResponse struct {
+	AccountID   int64  `json:"account_id"`
+	Status      string `json:"status"`
+	Currency    string `json:"currency"`
+	Available   int64  `json:"available"`
+	Reserved    int64  `json:"reserved"`
+	Total       int64  `json:"total"`
+	CreditLimit int64  `json:"credit_limit"`
+}
+
+func NewCreditLimitResponse(entity account.Account) CreditLimitResponse {
+	return CreditLimitResponse{
+		AccountID:   entity.AccountID,
+		Status:      entity.Status.String(),
+		Currency:    entity.Currency.String(),
+		Available:   entity.Available,
+		Reserved:    entity.Reserved,
+		Total:       entity.Total(),
+		CreditLimit: entity.CreditLimit,
+	}
+}
+
+// GetBalanceResponse keeps the balance field for old clients, it is the same as available.
 type GetBalanceResponse struct {
-	Balance int64 `json:"balance"`
+	Balance   int64 `json:"balance"`
+	Available int64 `json:"available"`
+	Reserved  int64 `json:"reserved"`
+	Total     int64 `json:"total"`
+}
+
+func NewGetBalanceResponse(entity account.Account) GetBalanceResponse {
+	return GetBalanceResponse{
+		Balance:   entity.Available,
+		Available: entity.Available,
+		Reserved:  entity.Reserved,
+		Total:     entity.Total(),
+	}
 }
 
 type AddBalanceResponse struct {
@@ -12,3 +61,32 @@ type TransferBalanceResponse struct {
 	SenderBalance   int64 `json:"sender_balance"`
 	ReceiverBalance int64 `json:"receiver_balance"`
 }
+
+type TransferBatchResponse struct {
+	Transfers []TransferBalanceResponse `json:"transfers"`
+}
+
+func NewTransferBatchResponse(results []account.TransferResult) TransferBatchResponse {
+	transfers := make([]TransferBalanceResponse, 0, len(results))
+	for _, result := range results {
+		transfers = append(transfers, TransferBalanceResponse{
+			SenderBalance:   result.SenderBalance,
+			ReceiverBalance: result.ReceiverBalance,
+		})
+	}
+
+	return TransferBatchResponse{
+		Transfers: transfers,
+	}
+}
+
+// BatchErrorResponse lists the transfers that made a batch fail. Index is the position of the transfer in the request.
+type BatchErrorResponse struct {
+	Message string              `json:"message"`
+	Errors  []BatchItemResponse `json:"errors"`
+}
+
+type BatchItemResponse struct {
+	Index   int    `json:"index"`
+	Message string `json:"message"`
+}
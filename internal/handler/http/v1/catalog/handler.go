@@ -0,0 +1,150 @@
+package catalog
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/maypok86/payment-api/internal/domain/catalog"
+	"github.com/maypok86/payment-api/internal/pkg/handler"
+	"go.uber.org/zap"
+)
+
+//go:generate mockgen -source=handler.go -destination=mock_test.go -package=catalog_test
+
+type Service interface {
+	CreateService(ctx context.Context, dto catalog.CreateDTO) (catalog.Entity, error)
+	GetServices(ctx context.Context) ([]catalog.Entity, error)
+	GetServiceByID(ctx context.Context, serviceID int64) (catalog.Entity, error)
+	UpdateService(ctx context.Context, dto catalog.UpdateDTO) (catalog.Entity, error)
+	DeleteService(ctx context.Context, serviceID int64) error
+}
+
+type Handler struct {
+	*handler.BaseHandler
+	service Service
+	logger  *zap.Logger
+}
+
+func NewHandler(service Service, logger *zap.Logger) *Handler {
+	return &Handler{
+		BaseHandler: handler.NewBaseHandler(logger),
+		service:     service,
+		logger:      logger,
+	}
+}
+
+func (h *Handler) InitAPI(router *gin.RouterGroup) {
+	servicesGroup := router.Group("/services")
+	{
+		servicesGroup.POST("", h.CreateService)
+		servicesGroup.GET("", h.GetServices)
+		servicesGroup.GET("/:service_id", h.GetService)
+		servicesGroup.PUT("/:service_id", h.UpdateService)
+		servicesGroup.DELETE("/:service_id", h.DeleteService)
+	}
+}
+
+func (h *Handler) CreateService(c *gin.Context) {
+	var request CreateRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		h.ErrorResponse(c, http.StatusBadRequest, err, "Create service error. Invalid request")
+		return
+	}
+
+	entity, err := h.service.CreateService(c.Request.Context(), request.ToDTO())
+	if err != nil {
+		if errors.Is(err, catalog.ErrAlreadyExist) {
+			h.ErrorResponse(c, http.StatusConflict, err, "Create service error. Service already exist")
+			return
+		}
+
+		h.ErrorResponse(c, http.StatusInternalServerError, err, "Create service error")
+		return
+	}
+
+	c.JSON(http.StatusOK, NewResponse(entity))
+}
+
+func (h *Handler) GetServices(c *gin.Context) {
+	entities, err := h.service.GetServices(c.Request.Context())
+	if err != nil {
+		h.ErrorResponse(c, http.StatusInternalServerError, err, "Get services error")
+		return
+	}
+
+	c.JSON(http.StatusOK, NewListResponse(entities))
+}
+
+func (h *Handler) GetService(c *gin.Context) {
+	serviceID, err := h.ParseIDFromPath(c, "service_id")
+	if err != nil {
+		h.ErrorResponse(c, http.StatusBadRequest, err, "Service not found. id is not valid")
+		return
+	}
+
+	entity, err := h.service.GetServiceByID(c.Request.Context(), serviceID)
+	if err != nil {
+		if errors.Is(err, catalog.ErrNotFound) {
+			h.ErrorResponse(c, http.StatusNotFound, err, "Service not found")
+			return
+		}
+
+		h.ErrorResponse(c, http.StatusInternalServerError, err, "Get service error")
+		return
+	}
+
+	c.JSON(http.StatusOK, NewResponse(entity))
+}
+
+func (h *Handler) UpdateService(c *gin.Context) {
+	serviceID, err := h.ParseIDFromPath(c, "service_id")
+	if err != nil {
+		h.ErrorResponse(c, http.StatusBadRequest, err, "Service not found. id is not valid")
+		return
+	}
+
+	var request UpdateRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		h.ErrorResponse(c, http.StatusBadRequest, err, "Update service error. Invalid request")
+		return
+	}
+
+	entity, err := h.service.UpdateService(c.Request.Context(), request.ToDTO(serviceID))
+	if err != nil {
+		if errors.Is(err, catalog.ErrNotFound) {
+			h.ErrorResponse(c, http.StatusNotFound, err, "Service not found")
+			return
+		}
+
+		h.ErrorResponse(c, http.StatusInternalServerError, err, "Update service error")
+		return
+	}
+
+	c.JSON(http.StatusOK, NewResponse(entity))
+}
+
+func (h *Handler) DeleteService(c *gin.Context) {
+	serviceID, err := h.ParseIDFromPath(c, "service_id")
+	if err != nil {
+		h.ErrorResponse(c, http.StatusBadRequest, err, "Service not found. id is not valid")
+		return
+	}
+
+	if err := h.service.DeleteService(c.Request.Context(), serviceID); err != nil {
+		switch {
+		case errors.Is(err, catalog.ErrNotFound):
+			h.ErrorResponse(c, http.StatusNotFound, err, "Service not found")
+			return
+		case errors.Is(err, catalog.ErrHasOrders):
+			h.ErrorResponse(c, http.StatusConflict, err, "Delete service error. Service has orders")
+			return
+		}
+
+		h.ErrorResponse(c, http.StatusInternalServerError, err, "Delete service error")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,507 @@
+package catalog_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	domain "github.com/maypok86/payment-api/internal/domain/catalog"
+	"github.com/maypok86/payment-api/internal/handler/http/v1/catalog"
+	"github.com/maypok86/payment-api/internal/pkg/handler"
+	"github.com/maypok86/payment-api/internal/pkg/logger"
+	"github.com/stretchr/testify/require"
+)
+
+func mockHandler(t *testing.T, w http.ResponseWriter) (*catalog.Handler, *MockService, *gin.Context) {
+	t.Helper()
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	gin.SetMode(gin.TestMode)
+
+	c, r := gin.CreateTestContext(w)
+	c.Request = &http.Request{
+		Header: make(http.Header),
+		URL:    &url.URL{},
+	}
+
+	l := logger.New(os.Stdout, "debug")
+
+	catalogService := NewMockService(mockCtrl)
+	catalogHandler := catalog.NewHandler(catalogService, l)
+
+	catalogHandler.InitAPI(r.Group("/"))
+
+	return catalogHandler, catalogService, c
+}
+
+// for fix time.Time in json.
+func reencode(t *testing.T, from, to interface{}) {
+	t.Helper()
+
+	var buffer bytes.Buffer
+	require.NoError(t, json.NewEncoder(&buffer).Encode(from))
+	require.NoError(t, json.NewDecoder(&buffer).Decode(to))
+}
+
+func setBody(t *testing.T, c *gin.Context, content interface{}) {
+	t.Helper()
+
+	c.Request.Header.Set("Content-Type", "application/json")
+
+	data, err := json.Marshal(content)
+	require.NoError(t, err)
+
+	c.Request.Body = io.NopCloser(bytes.NewBuffer(data))
+}
+
+var fakeEntity = domain.Entity{
+	ServiceID:   1,
+	Name:        "Delivery",
+	Description: "Delivery of goods",
+	Active:      true,
+	CreatedAt:   time.Now(),
+	UpdatedAt:   time.Now(),
+}
+
+func TestHandler_CreateService(t *testing.T) {
+	ctx := context.Background()
+
+	inactive := false
+	fakeRequest := catalog.CreateRequest{
+		ServiceID:   fakeEntity.ServiceID,
+		Name:        fakeEntity.Name,
+		Description: fakeEntity.Description,
+	}
+	fakeDTO := domain.CreateDTO{
+		ServiceID:   fakeEntity.ServiceID,
+		Name:        fakeEntity.Name,
+		Description: fakeEntity.Description,
+		Active:      true,
+	}
+	inactiveDTO := fakeDTO
+	inactiveDTO.Active = false
+	inactiveEntity := fakeEntity
+	inactiveEntity.Active = false
+
+	var fakeResponse catalog.Response
+	reencode(t, catalog.NewResponse(fakeEntity), &fakeResponse)
+	var inactiveResponse catalog.Response
+	reencode(t, catalog.NewResponse(inactiveEntity), &inactiveResponse)
+
+	catalogErr := errors.New("service catalog error")
+
+	type mockBehaviour func(service *MockService)
+
+	tests := []struct {
+		name                string
+		mock                mockBehaviour
+		request             catalog.CreateRequest
+		response            catalog.Response
+		wantedErrorResponse *handler.ErrorResponse
+		statusCode          int
+	}{
+		{
+			name: "invalid request",
+			mock: func(service *MockService) {
+			},
+			request: catalog.CreateRequest{
+				ServiceID: fakeEntity.ServiceID,
+			},
+			wantedErrorResponse: &handler.ErrorResponse{
+				Message: "Create service error. Invalid request",
+			},
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name: "service already exist",
+			mock: func(service *MockService) {
+				service.EXPECT().CreateService(ctx, fakeDTO).Return(domain.Entity{}, domain.ErrAlreadyExist)
+			},
+			request: fakeRequest,
+			wantedErrorResponse: &handler.ErrorResponse{
+				Message: "Create service error. Service already exist",
+			},
+			statusCode: http.StatusConflict,
+		},
+		{
+			name: "service catalog error",
+			mock: func(service *MockService) {
+				service.EXPECT().CreateService(ctx, fakeDTO).Return(domain.Entity{}, catalogErr)
+			},
+			request: fakeRequest,
+			wantedErrorResponse: &handler.ErrorResponse{
+				Message: "Create service error",
+			},
+			statusCode: http.StatusInternalServerError,
+		},
+		{
+			name: "success create active service",
+			mock: func(service *MockService) {
+				service.EXPECT().CreateService(ctx, fakeDTO).Return(fakeEntity, nil)
+			},
+			request:    fakeRequest,
+			response:   fakeResponse,
+			statusCode: http.StatusOK,
+		},
+		{
+			name: "success create inactive service",
+			mock: func(service *MockService) {
+				service.EXPECT().CreateService(ctx, inactiveDTO).Return(inactiveEntity, nil)
+			},
+			request: catalog.CreateRequest{
+				ServiceID:   fakeEntity.ServiceID,
+				Name:        fakeEntity.Name,
+				Description: fakeEntity.Description,
+				Active:      &inactive,
+			},
+			response:   inactiveResponse,
+			statusCode: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			catalogHandler, catalogService, c := mockHandler(t, w)
+
+			c.Request.Method = http.MethodPost
+			setBody(t, c, tt.request)
+			tt.mock(catalogService)
+
+			catalogHandler.CreateService(c)
+
+			require.Equal(t, tt.statusCode, w.Code)
+			if tt.wantedErrorResponse != nil {
+				var response handler.ErrorResponse
+				require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+				require.True(t, reflect.DeepEqual(tt.wantedErrorResponse, &response))
+			} else {
+				var response catalog.Response
+				require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+				require.True(t, reflect.DeepEqual(tt.response, response))
+			}
+		})
+	}
+}
+
+func TestHandler_GetService(t *testing.T) {
+	ctx := context.Background()
+
+	var fakeResponse catalog.Response
+	reencode(t, catalog.NewResponse(fakeEntity), &fakeResponse)
+
+	catalogErr := errors.New("service catalog error")
+
+	setupGin := func(c *gin.Context, param string) {
+		c.Request.Method = http.MethodGet
+		c.Params = gin.Params{{Key: "service_id", Value: param}}
+	}
+
+	type mockBehaviour func(service *MockService)
+
+	tests := []struct {
+		name                string
+		mock                mockBehaviour
+		param               string
+		response            catalog.Response
+		wantedErrorResponse *handler.ErrorResponse
+		statusCode          int
+	}{
+		{
+			name: "invalid service_id param",
+			mock: func(service *MockService) {
+			},
+			param: "invalid",
+			wantedErrorResponse: &handler.ErrorResponse{
+				Message: "Service not found. id is not valid",
+			},
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name: "service not found",
+			mock: func(service *MockService) {
+				service.EXPECT().GetServiceByID(ctx, int64(1)).Return(domain.Entity{}, domain.ErrNotFound)
+			},
+			param: "1",
+			wantedErrorResponse: &handler.ErrorResponse{
+				Message: "Service not found",
+			},
+			statusCode: http.StatusNotFound,
+		},
+		{
+			name: "service catalog error",
+			mock: func(service *MockService) {
+				service.EXPECT().GetServiceByID(ctx, int64(1)).Return(domain.Entity{}, catalogErr)
+			},
+			param: "1",
+			wantedErrorResponse: &handler.ErrorResponse{
+				Message: "Get service error",
+			},
+			statusCode: http.StatusInternalServerError,
+		},
+		{
+			name: "success get service",
+			mock: func(service *MockService) {
+				service.EXPECT().GetServiceByID(ctx, int64(1)).Return(fakeEntity, nil)
+			},
+			param:      "1",
+			response:   fakeResponse,
+			statusCode: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			catalogHandler, catalogService, c := mockHandler(t, w)
+
+			setupGin(c, tt.param)
+			tt.mock(catalogService)
+
+			catalogHandler.GetService(c)
+
+			require.Equal(t, tt.statusCode, w.Code)
+			if tt.wantedErrorResponse != nil {
+				var response handler.ErrorResponse
+				require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+				require.True(t, reflect.DeepEqual(tt.wantedErrorResponse, &response))
+			} else {
+				var response catalog.Response
+				require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+				require.True(t, reflect.DeepEqual(tt.response, response))
+			}
+		})
+	}
+}
+
+func TestHandler_UpdateService(t *testing.T) {
+	ctx := context.Background()
+
+	inactive := false
+	fakeRequest := catalog.UpdateRequest{
+		Name:        "Express delivery",
+		Description: fakeEntity.Description,
+		Active:      &inactive,
+	}
+	fakeDTO := domain.UpdateDTO{
+		ServiceID:   fakeEntity.ServiceID,
+		Name:        fakeRequest.Name,
+		Description: fakeRequest.Description,
+		Active:      false,
+	}
+	updatedEntity := fakeEntity
+	updatedEntity.Name = fakeDTO.Name
+	updatedEntity.Active = fakeDTO.Active
+
+	var fakeResponse catalog.Response
+	reencode(t, catalog.NewResponse(updatedEntity), &fakeResponse)
+
+	catalogErr := errors.New("service catalog error")
+
+	setupGin := func(c *gin.Context, param string, content interface{}) {
+		c.Request.Method = http.MethodPut
+		c.Params = gin.Params{{Key: "service_id", Value: param}}
+		setBody(t, c, content)
+	}
+
+	type mockBehaviour func(service *MockService)
+
+	tests := []struct {
+		name                string
+		mock                mockBehaviour
+		param               string
+		request             catalog.UpdateRequest
+		response            catalog.Response
+		wantedErrorResponse *handler.ErrorResponse
+		statusCode          int
+	}{
+		{
+			name: "invalid service_id param",
+			mock: func(service *MockService) {
+			},
+			param:   "invalid",
+			request: fakeRequest,
+			wantedErrorResponse: &handler.ErrorResponse{
+				Message: "Service not found. id is not valid",
+			},
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name: "active is not set",
+			mock: func(service *MockService) {
+			},
+			param: "1",
+			request: catalog.UpdateRequest{
+				Name: fakeRequest.Name,
+			},
+			wantedErrorResponse: &handler.ErrorResponse{
+				Message: "Update service error. Invalid request",
+			},
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name: "service not found",
+			mock: func(service *MockService) {
+				service.EXPECT().UpdateService(ctx, fakeDTO).Return(domain.Entity{}, domain.ErrNotFound)
+			},
+			param:   "1",
+			request: fakeRequest,
+			wantedErrorResponse: &handler.ErrorResponse{
+				Message: "Service not found",
+			},
+			statusCode: http.StatusNotFound,
+		},
+		{
+			name: "service catalog error",
+			mock: func(service *MockService) {
+				service.EXPECT().UpdateService(ctx, fakeDTO).Return(domain.Entity{}, catalogErr)
+			},
+			param:   "1",
+			request: fakeRequest,
+			wantedErrorResponse: &handler.ErrorResponse{
+				Message: "Update service error",
+			},
+			statusCode: http.StatusInternalServerError,
+		},
+		{
+			name: "success update service",
+			mock: func(service *MockService) {
+				service.EXPECT().UpdateService(ctx, fakeDTO).Return(updatedEntity, nil)
+			},
+			param:      "1",
+			request:    fakeRequest,
+			response:   fakeResponse,
+			statusCode: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			catalogHandler, catalogService, c := mockHandler(t, w)
+
+			setupGin(c, tt.param, tt.request)
+			tt.mock(catalogService)
+
+			catalogHandler.UpdateService(c)
+
+			require.Equal(t, tt.statusCode, w.Code)
+			if tt.wantedErrorResponse != nil {
+				var response handler.ErrorResponse
+				require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+				require.True(t, reflect.DeepEqual(tt.wantedErrorResponse, &response))
+			} else {
+				var response catalog.Response
+				require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+				require.True(t, reflect.DeepEqual(tt.response, response))
+			}
+		})
+	}
+}
+
+func TestHandler_DeleteService(t *testing.T) {
+	ctx := context.Background()
+
+	catalogErr := errors.New("service catalog error")
+
+	setupGin := func(c *gin.Context, param string) {
+		c.Request.Method = http.MethodDelete
+		c.Params = gin.Params{{Key: "service_id", Value: param}}
+	}
+
+	type mockBehaviour func(service *MockService)
+
+	tests := []struct {
+		name                string
+		mock                mockBehaviour
+		param               string
+		wantedErrorResponse *handler.ErrorResponse
+		statusCode          int
+	}{
+		{
+			name: "invalid service_id param",
+			mock: func(service *MockService) {
+			},
+			param: "invalid",
+			wantedErrorResponse: &handler.ErrorResponse{
+				Message: "Service not found. id is not valid",
+			},
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name: "service not found",
+			mock: func(service *MockService) {
+				service.EXPECT().DeleteService(ctx, int64(1)).Return(domain.ErrNotFound)
+			},
+			param: "1",
+			wantedErrorResponse: &handler.ErrorResponse{
+				Message: "Service not found",
+			},
+			statusCode: http.StatusNotFound,
+		},
+		{
+			name: "service has orders",
+			mock: func(service *MockService) {
+				service.EXPECT().DeleteService(ctx, int64(1)).Return(domain.ErrHasOrders)
+			},
+			param: "1",
+			wantedErrorResponse: &handler.ErrorResponse{
+				Message: "Delete service error. Service has orders",
+			},
+			statusCode: http.StatusConflict,
+		},
+		{
+			name: "service catalog error",
+			mock: func(service *MockService) {
+				service.EXPECT().DeleteService(ctx, int64(1)).Return(catalogErr)
+			},
+			param: "1",
+			wantedErrorResponse: &handler.ErrorResponse{
+				Message: "Delete service error",
+			},
+			statusCode: http.StatusInternalServerError,
+		},
+		{
+			name: "success delete service",
+			mock: func(service *MockService) {
+				service.EXPECT().DeleteService(ctx, int64(1)).Return(nil)
+			},
+			param:      "1",
+			statusCode: http.StatusNoContent,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			catalogHandler, catalogService, c := mockHandler(t, w)
+
+			setupGin(c, tt.param)
+			tt.mock(catalogService)
+
+			catalogHandler.DeleteService(c)
+
+			require.Equal(t, tt.statusCode, c.Writer.Status())
+			if tt.wantedErrorResponse != nil {
+				var response handler.ErrorResponse
+				require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+				require.True(t, reflect.DeepEqual(tt.wantedErrorResponse, &response))
+			} else {
+				require.Zero(t, w.Body.Len())
+			}
+		})
+	}
+}
@@ -0,0 +1,40 @@
+package catalog
+
+import "github.com/maypok86/payment-api/internal/domain/catalog"
+
+// CreateRequest creates an active service if Active is not set.
+type CreateRequest struct {
+	ServiceID   int64  `json:"service_id"  binding:"required,gte=1"`
+	Name        string `json:"name"        binding:"required"`
+	Description string `json:"description"`
+	Active      *bool  `json:"active"`
+}
+
+func (r CreateRequest) ToDTO() catalog.CreateDTO {
+	active := true
+	if r.Active != nil {
+		active = *r.Active
+	}
+
+	return catalog.CreateDTO{
+		ServiceID:   r.ServiceID,
+		Name:        r.Name,
+		Description: r.Description,
+		Active:      active,
+	}
+}
+
+type UpdateRequest struct {
+	Name        string `json:"name"        binding:"required"`
+	Description string `json:"description"`
+	Active      *bool  `json:"active"      binding:"required"`
+}
+
+func (r UpdateRequest) ToDTO(serviceID int64) catalog.UpdateDTO {
+	return catalog.UpdateDTO{
+		ServiceID:   serviceID,
+		Name:        r.Name,
+		Description: r.Description,
+		Active:      *r.Active,
+	}
+}
@@ -0,0 +1,43 @@
+package catalog
+
+import (
+	"time"
+
+	"github.com/maypok86/payment-api/internal/domain/catalog"
+)
+
+type Response struct {
+	ServiceID   int64     `json:"service_id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	Active      bool      `json:"active"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+func NewResponse(entity catalog.Entity) Response {
+	return Response{
+		ServiceID:   entity.ServiceID,
+		Name:        entity.Name,
+		Description: entity.Description,
+		Active:      entity.Active,
+		CreatedAt:   entity.CreatedAt,
+		UpdatedAt:   entity.UpdatedAt,
+	}
+}
+
+type ListResponse struct {
+	Services []Response `json:"services"`
+}
+
+func NewListResponse(entities []catalog.Entity) ListResponse {
+	responses := make([]Response, 0, len(entities))
+
+	for _, entity := range entities {
+		responses = append(responses, NewResponse(entity))
+	}
+
+	return ListResponse{
+		Services: responses,
+	}
+}
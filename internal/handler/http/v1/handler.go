@@ -5,9 +5,13 @@ import (
 	"github.com/maypok86/payment-api/internal/config"
 	"github.com/maypok86/payment-api/internal/domain"
 	"github.com/maypok86/payment-api/internal/handler/http/v1/account"
+	"github.com/maypok86/payment-api/internal/handler/http/v1/catalog"
+	"github.com/maypok86/payment-api/internal/handler/http/v1/idempotency"
+	"github.com/maypok86/payment-api/internal/handler/http/v1/ledger"
 	"github.com/maypok86/payment-api/internal/handler/http/v1/order"
 	"github.com/maypok86/payment-api/internal/handler/http/v1/report"
 	"github.com/maypok86/payment-api/internal/handler/http/v1/transaction"
+	"github.com/maypok86/payment-api/internal/handler/http/v1/webhook"
 	"go.uber.org/zap"
 )
 
@@ -26,14 +30,22 @@ func NewHandler(services *domain.Services, logger *zap.Logger) *Handler {
 func (h *Handler) InitAPI(router *gin.RouterGroup) {
 	v1 := router.Group("/v1")
 	{
-		account.NewHandler(h.services.Account, h.logger).InitAPI(v1)
+		idempotent := v1.Group("", idempotency.NewMiddleware(h.services.Idempotency, h.logger).Handle)
+
+		account.NewHandler(h.services.Account, h.logger).InitAPI(idempotent)
 		transaction.NewHandler(h.services.Transaction, h.logger).InitAPI(v1)
-		order.NewHandler(h.services.Order, h.logger).InitAPI(v1)
+		order.NewHandler(h.services.Order, h.logger).InitAPI(idempotent)
+		ledger.NewHandler(h.services.Ledger, h.logger).InitAPI(v1)
+		webhook.NewHandler(h.services.Webhook, h.logger).InitAPI(v1)
+		catalog.NewHandler(h.services.Catalog, h.logger).InitAPI(v1)
 
 		cfg := config.Get()
 		reportCfg := report.Config{
 			ReportHost: cfg.Report.Host,
 			ReportPort: cfg.Report.Port,
+			LinkScheme: cfg.Report.LinkScheme,
+			LinkSecret: cfg.Report.LinkSecret,
+			LinkTTL:    cfg.Report.LinkTTL,
 		}
 		report.NewHandler(reportCfg, h.services.Report, h.logger).InitAPI(v1)
 	}
@@ -0,0 +1,197 @@
+package idempotency
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/maypok86/payment-api/internal/domain/idempotency"
+	"github.com/maypok86/payment-api/internal/pkg/handler"
+	"go.uber.org/zap"
+)
+
+//go:generate mockgen -source=middleware.go -destination=mock_test.go -package=idempotency_test
+
+const (
+	KeyHeader      = "Idempotency-Key"
+	ReplayedHeader = "Idempotent-Replayed"
+
+	maxKeyLength = 255
+)
+
+var (
+	ErrInvalidKey    = errors.New("invalid idempotency key")
+	ErrHandlerFailed = errors.New("idempotent request handler failed")
+)
+
+type Service interface {
+	Execute(
+		ctx context.Context,
+		dto idempotency.ExecuteDTO,
+		handle idempotency.HandleFunc,
+	) (idempotency.Response, bool, error)
+}
+
+type Middleware struct {
+	*handler.BaseHandler
+	service Service
+	logger  *zap.Logger
+}
+
+func NewMiddleware(service Service, logger *zap.Logger) *Middleware {
+	return &Middleware{
+		BaseHandler: handler.NewBaseHandler(logger),
+		service:     service,
+		logger:      logger,
+	}
+}
+
+// Handle makes POST requests with the Idempotency-Key header idempotent. The route handler runs
+// inside the idempotency transaction and its response is buffered until commit. The handler is run
+// again for every attempt of the transaction, so it must be the only handler after the middleware.
+func (m *Middleware) Handle(c *gin.Context) {
+	key := c.GetHeader(KeyHeader)
+	if key == "" || c.Request.Method != http.MethodPost {
+		c.Next()
+		return
+	}
+
+	if len(key) > maxKeyLength {
+		m.ErrorResponse(c, http.StatusBadRequest, ErrInvalidKey, "Idempotent request error. Idempotency key is too long")
+		return
+	}
+
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		m.ErrorResponse(c, http.StatusBadRequest, err, "Idempotent request error. Invalid request")
+		return
+	}
+
+	request := c.Request
+	handle := c.Handler()
+	writer := newBufferedWriter(c.Writer)
+	c.Writer = writer
+
+	response, replayed, err := m.service.Execute(
+		request.Context(),
+		idempotency.ExecuteDTO{
+			Key:         key,
+			Fingerprint: fingerprint(request, body),
+		},
+		func(ctx context.Context) (idempotency.Response, error) {
+			writer.reset()
+			c.Request = request.WithContext(ctx)
+			c.Request.Body = io.NopCloser(bytes.NewReader(body))
+			handle(c)
+
+			if writer.Status() >= http.StatusInternalServerError {
+				return idempotency.Response{}, ErrHandlerFailed
+			}
+
+			return idempotency.Response{
+				StatusCode: writer.Status(),
+				Body:       writer.body.Bytes(),
+			}, nil
+		},
+	)
+
+	c.Abort()
+	c.Request = request
+	c.Writer = writer.ResponseWriter
+
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrHandlerFailed):
+			writer.flush()
+			return
+		case errors.Is(err, idempotency.ErrFingerprintMismatch):
+			m.ErrorResponse(
+				c,
+				http.StatusConflict,
+				err,
+				"Idempotent request error. Idempotency key is already used with another request",
+			)
+			return
+		}
+
+		m.ErrorResponse(c, http.StatusInternalServerError, err, "Idempotent request error")
+		return
+	}
+
+	if !replayed {
+		writer.flush()
+		return
+	}
+
+	c.Header(ReplayedHeader, "true")
+	if len(response.Body) == 0 {
+		c.Status(response.StatusCode)
+		return
+	}
+	c.Data(response.StatusCode, gin.MIMEJSON+"; charset=utf-8", response.Body)
+}
+
+func fingerprint(request *http.Request, body []byte) string {
+	hash := sha256.New()
+	hash.Write([]byte(request.Method + " " + request.URL.Path + "\n"))
+	hash.Write(body)
+
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+type bufferedWriter struct {
+	gin.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func newBufferedWriter(writer gin.ResponseWriter) *bufferedWriter {
+	return &bufferedWriter{
+		ResponseWriter: writer,
+		status:         http.StatusOK,
+	}
+}
+
+func (w *bufferedWriter) WriteHeader(code int) {
+	w.status = code
+}
+
+func (w *bufferedWriter) WriteHeaderNow() {}
+
+func (w *bufferedWriter) Write(data []byte) (int, error) {
+	return w.body.Write(data)
+}
+
+func (w *bufferedWriter) WriteString(s string) (int, error) {
+	return w.body.WriteString(s)
+}
+
+func (w *bufferedWriter) Status() int {
+	return w.status
+}
+
+func (w *bufferedWriter) Size() int {
+	return w.body.Len()
+}
+
+func (w *bufferedWriter) Written() bool {
+	return w.body.Len() > 0
+}
+
+func (w *bufferedWriter) reset() {
+	w.status = http.StatusOK
+	w.body.Reset()
+}
+
+func (w *bufferedWriter) flush() {
+	w.ResponseWriter.WriteHeader(w.status)
+	w.ResponseWriter.WriteHeaderNow()
+	if w.body.Len() > 0 {
+		_, _ = w.ResponseWriter.Write(w.body.Bytes())
+	}
+}
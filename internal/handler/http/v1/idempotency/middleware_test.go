@@ -0,0 +1,270 @@
+package idempotency_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	domain "github.com/maypok86/payment-api/internal/domain/idempotency"
+	"github.com/maypok86/payment-api/internal/handler/http/v1/idempotency"
+	"github.com/maypok86/payment-api/internal/pkg/logger"
+	"github.com/stretchr/testify/require"
+)
+
+func mockRouter(t *testing.T, handlerStatus int, handlerCalls *int) (*gin.Engine, *MockService) {
+	t.Helper()
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	gin.SetMode(gin.TestMode)
+
+	l := logger.New(os.Stdout, "debug")
+
+	service := NewMockService(mockCtrl)
+	middleware := idempotency.NewMiddleware(service, l)
+
+	router := gin.New()
+	router.POST("/balance/add", middleware.Handle, func(c *gin.Context) {
+		*handlerCalls++
+		c.JSON(handlerStatus, gin.H{"balance": 100})
+	})
+
+	return router, service
+}
+
+func executeHandle(
+	ctx context.Context,
+	dto domain.ExecuteDTO,
+	handle domain.HandleFunc,
+) (domain.Response, bool, error) {
+	response, err := handle(ctx)
+
+	return response, false, err
+}
+
+func TestMiddleware_Handle(t *testing.T) {
+	fakeKey := "b4a6b0bb-0e35-4d4c-9d4a-1f1ad1d5c1f0"
+	fakeBody := `{"account_id":1,"amount":100}`
+	storedResponse := domain.Response{
+		StatusCode: http.StatusOK,
+		Body:       []byte(`{"balance":100}`),
+	}
+	serviceErr := errors.New("idempotency service error")
+
+	type mockBehaviour func(service *MockService)
+
+	type args struct {
+		key           string
+		handlerStatus int
+	}
+
+	tests := []struct {
+		name         string
+		mock         mockBehaviour
+		args         args
+		statusCode   int
+		body         string
+		replayed     bool
+		handlerCalls int
+	}{
+		{
+			name: "request without key",
+			mock: func(service *MockService) {
+			},
+			args: args{
+				handlerStatus: http.StatusOK,
+			},
+			statusCode:   http.StatusOK,
+			body:         `{"balance":100}`,
+			handlerCalls: 1,
+		},
+		{
+			name: "too long key",
+			mock: func(service *MockService) {
+			},
+			args: args{
+				key:           strings.Repeat("a", 256),
+				handlerStatus: http.StatusOK,
+			},
+			statusCode: http.StatusBadRequest,
+			body:       `{"message":"Idempotent request error. Idempotency key is too long"}`,
+		},
+		{
+			name: "success first execution",
+			mock: func(service *MockService) {
+				service.EXPECT().Execute(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(executeHandle)
+			},
+			args: args{
+				key:           fakeKey,
+				handlerStatus: http.StatusOK,
+			},
+			statusCode:   http.StatusOK,
+			body:         `{"balance":100}`,
+			handlerCalls: 1,
+		},
+		{
+			name: "success replay",
+			mock: func(service *MockService) {
+				service.EXPECT().Execute(gomock.Any(), gomock.Any(), gomock.Any()).Return(storedResponse, true, nil)
+			},
+			args: args{
+				key:           fakeKey,
+				handlerStatus: http.StatusOK,
+			},
+			statusCode: http.StatusOK,
+			body:       `{"balance":100}`,
+			replayed:   true,
+		},
+		{
+			name: "fingerprint mismatch",
+			mock: func(service *MockService) {
+				service.EXPECT().
+					Execute(gomock.Any(), gomock.Any(), gomock.Any()).
+					Return(domain.Response{}, false, domain.ErrFingerprintMismatch)
+			},
+			args: args{
+				key:           fakeKey,
+				handlerStatus: http.StatusOK,
+			},
+			statusCode: http.StatusConflict,
+			body:       `{"message":"Idempotent request error. Idempotency key is already used with another request"}`,
+		},
+		{
+			name: "handler error",
+			mock: func(service *MockService) {
+				service.EXPECT().Execute(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(executeHandle)
+			},
+			args: args{
+				key:           fakeKey,
+				handlerStatus: http.StatusInternalServerError,
+			},
+			statusCode:   http.StatusInternalServerError,
+			body:         `{"balance":100}`,
+			handlerCalls: 1,
+		},
+		{
+			name: "idempotency service error",
+			mock: func(service *MockService) {
+				service.EXPECT().
+					Execute(gomock.Any(), gomock.Any(), gomock.Any()).
+					Return(domain.Response{}, false, serviceErr)
+			},
+			args: args{
+				key:           fakeKey,
+				handlerStatus: http.StatusOK,
+			},
+			statusCode: http.StatusInternalServerError,
+			body:       `{"message":"Idempotent request error"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handlerCalls := 0
+			router, service := mockRouter(t, tt.args.handlerStatus, &handlerCalls)
+
+			tt.mock(service)
+
+			request := httptest.NewRequest(http.MethodPost, "/balance/add", bytes.NewBufferString(fakeBody))
+			request.Header.Set("Content-Type", "application/json")
+			if tt.args.key != "" {
+				request.Header.Set(idempotency.KeyHeader, tt.args.key)
+			}
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, request)
+
+			require.Equal(t, tt.statusCode, w.Code)
+			require.JSONEq(t, tt.body, w.Body.String())
+			require.Equal(t, tt.replayed, w.Header().Get(idempotency.ReplayedHeader) == "true")
+			require.Equal(t, tt.handlerCalls, handlerCalls)
+		})
+	}
+}
+
+func TestMiddleware_HandleFingerprint(t *testing.T) {
+	handlerCalls := 0
+	router, service := mockRouter(t, http.StatusOK, &handlerCalls)
+
+	fingerprints := make([]string, 0, 3)
+	service.EXPECT().
+		Execute(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, dto domain.ExecuteDTO, handle domain.HandleFunc) (
+			domain.Response, bool, error,
+		) {
+			require.Equal(t, "key", dto.Key)
+			fingerprints = append(fingerprints, dto.Fingerprint)
+
+			return executeHandle(ctx, dto, handle)
+		}).
+		Times(3)
+
+	for _, body := range []string{`{"amount":100}`, `{"amount":100}`, `{"amount":200}`} {
+		request := httptest.NewRequest(http.MethodPost, "/balance/add", bytes.NewBufferString(body))
+		request.Header.Set(idempotency.KeyHeader, "key")
+
+		router.ServeHTTP(httptest.NewRecorder(), request)
+	}
+
+	require.Equal(t, fingerprints[0], fingerprints[1])
+	require.NotEqual(t, fingerprints[0], fingerprints[2])
+	require.Equal(t, 3, handlerCalls)
+}
+
+func TestMiddleware_HandleRetry(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	gin.SetMode(gin.TestMode)
+
+	service := NewMockService(mockCtrl)
+	middleware := idempotency.NewMiddleware(service, logger.New(os.Stdout, "debug"))
+
+	// The handler hits a deadlock on the first attempt, so the transaction is run again.
+	bodies := make([]string, 0, 2)
+	router := gin.New()
+	router.POST("/balance/add", middleware.Handle, func(c *gin.Context) {
+		body, err := io.ReadAll(c.Request.Body)
+		require.NoError(t, err)
+		bodies = append(bodies, string(body))
+
+		if len(bodies) == 1 {
+			c.JSON(http.StatusInternalServerError, gin.H{"message": "Add balance error"})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"balance": 100})
+	})
+
+	service.EXPECT().
+		Execute(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, dto domain.ExecuteDTO, handle domain.HandleFunc) (
+			domain.Response, bool, error,
+		) {
+			response, err := handle(ctx)
+			require.ErrorIs(t, err, idempotency.ErrHandlerFailed)
+
+			response, err = handle(ctx)
+
+			return response, false, err
+		})
+
+	fakeBody := `{"account_id":1,"amount":100}`
+	request := httptest.NewRequest(http.MethodPost, "/balance/add", bytes.NewBufferString(fakeBody))
+	request.Header.Set(idempotency.KeyHeader, "key")
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, request)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	require.JSONEq(t, `{"balance":100}`, w.Body.String())
+	require.Equal(t, []string{fakeBody, fakeBody}, bodies)
+}
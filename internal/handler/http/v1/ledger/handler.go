@@ -0,0 +1,48 @@
+package ledger
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/maypok86/payment-api/internal/domain/ledger"
+	"github.com/maypok86/payment-api/internal/pkg/handler"
+	"go.uber.org/zap"
+)
+
+//go:generate mockgen -source=handler.go -destination=mock_test.go -package=ledger_test
+
+type Service interface {
+	Reconcile(ctx context.Context) (ledger.Reconciliation, error)
+}
+
+type Handler struct {
+	*handler.BaseHandler
+	service Service
+	logger  *zap.Logger
+}
+
+func NewHandler(service Service, logger *zap.Logger) *Handler {
+	return &Handler{
+		BaseHandler: handler.NewBaseHandler(logger),
+		service:     service,
+		logger:      logger,
+	}
+}
+
+func (h *Handler) InitAPI(router *gin.RouterGroup) {
+	ledgerGroup := router.Group("/ledger")
+	{
+		ledgerGroup.GET("/reconciliation", h.GetReconciliation)
+	}
+}
+
+func (h *Handler) GetReconciliation(c *gin.Context) {
+	reconciliation, err := h.service.Reconcile(c.Request.Context())
+	if err != nil {
+		h.ErrorResponse(c, http.StatusInternalServerError, err, "Reconcile ledger error")
+		return
+	}
+
+	c.JSON(http.StatusOK, NewReconciliationResponse(reconciliation))
+}
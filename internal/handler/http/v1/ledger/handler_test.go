@@ -0,0 +1,148 @@
+package ledger_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/maypok86/payment-api/internal/domain/currency"
+	domain "github.com/maypok86/payment-api/internal/domain/ledger"
+	"github.com/maypok86/payment-api/internal/handler/http/v1/ledger"
+	"github.com/maypok86/payment-api/internal/pkg/handler"
+	"github.com/maypok86/payment-api/internal/pkg/logger"
+	"github.com/stretchr/testify/require"
+)
+
+func mockHandler(t *testing.T, w http.ResponseWriter) (*ledger.Handler, *MockService, *gin.Context) {
+	t.Helper()
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	gin.SetMode(gin.TestMode)
+
+	c, r := gin.CreateTestContext(w)
+	c.Request = &http.Request{
+		Header: make(http.Header),
+	}
+
+	l := logger.New(os.Stdout, "debug")
+
+	ledgerService := NewMockService(mockCtrl)
+	ledgerHandler := ledger.NewHandler(ledgerService, l)
+
+	ledgerHandler.InitAPI(r.Group("/"))
+
+	return ledgerHandler, ledgerService, c
+}
+
+func TestHandler_GetReconciliation(t *testing.T) {
+	ctx := context.Background()
+
+	balanced := domain.Reconciliation{
+		TotalDebit:  1000,
+		TotalCredit: 1000,
+	}
+	unbalanced := domain.Reconciliation{
+		TotalDebit:  1000,
+		TotalCredit: 1000,
+		Mismatches: []domain.AccountMismatch{
+			{
+				AccountID:     1,
+				Currency:      currency.USD,
+				Balance:       200,
+				LedgerBalance: 100,
+			},
+		},
+	}
+	ledgerServiceErr := errors.New("ledger service error")
+
+	setupGin := func(c *gin.Context) {
+		c.Request.Method = http.MethodGet
+		c.Request.Header.Set("Content-Type", "application/json")
+	}
+
+	type mockBehaviour func(service *MockService)
+
+	tests := []struct {
+		name                string
+		mock                mockBehaviour
+		response            ledger.ReconciliationResponse
+		wantedErrorResponse *handler.ErrorResponse
+		statusCode          int
+	}{
+		{
+			name: "ledger service error",
+			mock: func(service *MockService) {
+				service.EXPECT().Reconcile(ctx).Return(domain.Reconciliation{}, ledgerServiceErr)
+			},
+			wantedErrorResponse: &handler.ErrorResponse{
+				Message: "Reconcile ledger error",
+			},
+			statusCode: http.StatusInternalServerError,
+		},
+		{
+			name: "success balanced ledger",
+			mock: func(service *MockService) {
+				service.EXPECT().Reconcile(ctx).Return(balanced, nil)
+			},
+			response: ledger.ReconciliationResponse{
+				TotalDebit:  balanced.TotalDebit,
+				TotalCredit: balanced.TotalCredit,
+				IsBalanced:  true,
+				Mismatches:  []ledger.MismatchResponse{},
+			},
+			statusCode: http.StatusOK,
+		},
+		{
+			name: "success unbalanced ledger",
+			mock: func(service *MockService) {
+				service.EXPECT().Reconcile(ctx).Return(unbalanced, nil)
+			},
+			response: ledger.ReconciliationResponse{
+				TotalDebit:  unbalanced.TotalDebit,
+				TotalCredit: unbalanced.TotalCredit,
+				IsBalanced:  false,
+				Mismatches: []ledger.MismatchResponse{
+					{
+						AccountID:     1,
+						Currency:      "USD",
+						Balance:       200,
+						LedgerBalance: 100,
+					},
+				},
+			},
+			statusCode: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			ledgerHandler, ledgerService, c := mockHandler(t, w)
+
+			setupGin(c)
+			tt.mock(ledgerService)
+
+			ledgerHandler.GetReconciliation(c)
+
+			require.Equal(t, tt.statusCode, w.Code)
+			if tt.wantedErrorResponse != nil {
+				var response handler.ErrorResponse
+				require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+				require.True(t, reflect.DeepEqual(tt.wantedErrorResponse, &response))
+			} else {
+				var response ledger.ReconciliationResponse
+				require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+				require.True(t, reflect.DeepEqual(tt.response, response))
+			}
+		})
+	}
+}
@@ -0,0 +1,37 @@
+package ledger
+
+import "github.com/maypok86/payment-api/internal/domain/ledger"
+
+type MismatchResponse struct {
+	AccountID     int64  `json:"account_id"`
+	Currency      string `json:"currency"`
+	Balance       int64  `json:"balance"`
+	LedgerBalance int64  `json:"ledger_balance"`
+}
+
+type ReconciliationResponse struct {
+	TotalDebit  int64              `json:"total_debit"`
+	TotalCredit int64              `json:"total_credit"`
+	IsBalanced  bool               `json:"is_balanced"`
+	Mismatches  []MismatchResponse `json:"mismatches"`
+}
+
+func NewReconciliationResponse(reconciliation ledger.Reconciliation) ReconciliationResponse {
+	mismatches := make([]MismatchResponse, 0, len(reconciliation.Mismatches))
+
+	for _, mismatch := range reconciliation.Mismatches {
+		mismatches = append(mismatches, MismatchResponse{
+			AccountID:     mismatch.AccountID,
+			Currency:      mismatch.Currency.String(),
+			Balance:       mismatch.Balance,
+			LedgerBalance: mismatch.LedgerBalance,
+		})
+	}
+
+	return ReconciliationResponse{
+		TotalDebit:  reconciliation.TotalDebit,
+		TotalCredit: reconciliation.TotalCredit,
+		IsBalanced:  reconciliation.IsBalanced(),
+		Mismatches:  mismatches,
+	}
+}
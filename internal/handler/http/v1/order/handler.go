@@ -7,6 +7,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/maypok86/payment-api/internal/domain/account"
+	"github.com/maypok86/payment-api/internal/domain/currency"
 	"github.com/maypok86/payment-api/internal/domain/order"
 	"github.com/maypok86/payment-api/internal/pkg/handler"
 	"go.uber.org/zap"
@@ -17,7 +18,9 @@ import (
 type Service interface {
 	CreateOrder(ctx context.Context, dto order.CreateDTO) (order.Order, int64, error)
 	PayForOrder(ctx context.Context, dto order.PayForDTO) error
+	CaptureOrder(ctx context.Context, dto order.CaptureDTO) (order.Order, error)
 	CancelOrder(ctx context.Context, dto order.CancelDTO) (int64, error)
+	RefundOrder(ctx context.Context, dto order.RefundDTO) (order.Order, int64, error)
 }
 
 type Handler struct {
@@ -39,7 +42,9 @@ func (h *Handler) InitAPI(router *gin.RouterGroup) {
 	{
 		orderGroup.POST("/create", h.CreateOrder)
 		orderGroup.POST("/pay", h.PayForOrder)
+		orderGroup.POST("/capture", h.CaptureOrder)
 		orderGroup.POST("/cancel", h.CancelOrder)
+		orderGroup.POST("/refund", h.RefundOrder)
 	}
 }
 
@@ -50,12 +55,40 @@ func (h *Handler) CreateOrder(c *gin.Context) {
 		return
 	}
 
-	entity, balance, err := h.service.CreateOrder(c.Request.Context(), request.ToDTO())
+	entity, balance, err := h.service.CreateOrder(c.Request.Context(), request.ToDTO(h.Actor(c)))
 	if err != nil {
+		var fundsErr *account.InsufficientFundsError
+		if errors.As(err, &fundsErr) {
+			h.InsufficientFundsResponse(
+				c,
+				err,
+				"Create order error. Insufficient funds",
+				fundsErr.Available,
+				fundsErr.CreditLimit,
+				fundsErr.Currency.String(),
+			)
+			return
+		}
+
 		switch {
+		case errors.Is(err, currency.ErrInvalidAmount):
+			h.ErrorResponse(c, http.StatusBadRequest, err, "Create order error. Amount does not fit the currency")
+			return
 		case errors.Is(err, account.ErrNotFound):
 			h.ErrorResponse(c, http.StatusNotFound, err, "Create order error. Account not found")
 			return
+		case errors.Is(err, order.ErrServiceNotFound):
+			h.ErrorResponse(c, http.StatusNotFound, err, "Create order error. Service not found")
+			return
+		case errors.Is(err, order.ErrServiceInactive):
+			h.ErrorResponse(c, http.StatusConflict, err, "Create order error. Service is not active")
+			return
+		case errors.Is(err, account.ErrFrozen):
+			h.ErrorResponse(c, http.StatusConflict, err, "Create order error. Account is frozen")
+			return
+		case errors.Is(err, account.ErrClosed):
+			h.ErrorResponse(c, http.StatusConflict, err, "Create order error. Account is closed")
+			return
 		case errors.Is(err, order.ErrAlreadyExist):
 			h.ErrorResponse(c, http.StatusConflict, err, "Create order error. Order already exist")
 			return
@@ -75,10 +108,20 @@ func (h *Handler) PayForOrder(c *gin.Context) {
 		return
 	}
 
-	if err := h.service.PayForOrder(c.Request.Context(), request.ToDTO()); err != nil {
-		if errors.Is(err, order.ErrNotFound) {
+	if err := h.service.PayForOrder(c.Request.Context(), request.ToDTO(h.Actor(c))); err != nil {
+		switch {
+		case errors.Is(err, currency.ErrInvalidAmount):
+			h.ErrorResponse(c, http.StatusBadRequest, err, "Pay for order error. Amount does not fit the currency")
+			return
+		case errors.Is(err, order.ErrNotFound):
 			h.ErrorResponse(c, http.StatusNotFound, err, "Pay for order error. Order not found")
 			return
+		case errors.Is(err, order.ErrIllegalTransition):
+			h.ErrorResponse(c, http.StatusConflict, err, "Pay for order error. Order can not be paid")
+			return
+		case errors.Is(err, currency.ErrMismatch):
+			h.ErrorResponse(c, http.StatusConflict, err, "Pay for order error. Currency does not match order currency")
+			return
 		}
 
 		h.ErrorResponse(c, http.StatusInternalServerError, err, "Pay for order error")
@@ -88,6 +131,43 @@ func (h *Handler) PayForOrder(c *gin.Context) {
 	c.Status(http.StatusOK)
 }
 
+func (h *Handler) CaptureOrder(c *gin.Context) {
+	var request CaptureOrderRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		h.ErrorResponse(c, http.StatusBadRequest, err, "Capture order error. Invalid request")
+		return
+	}
+
+	entity, err := h.service.CaptureOrder(c.Request.Context(), request.ToDTO(h.Actor(c)))
+	if err != nil {
+		switch {
+		case errors.Is(err, currency.ErrInvalidAmount):
+			h.ErrorResponse(c, http.StatusBadRequest, err, "Capture order error. Amount does not fit the currency")
+			return
+		case errors.Is(err, order.ErrNotFound):
+			h.ErrorResponse(c, http.StatusNotFound, err, "Capture order error. Order not found")
+			return
+		case errors.Is(err, account.ErrNotFound):
+			h.ErrorResponse(c, http.StatusNotFound, err, "Capture order error. Account not found")
+			return
+		case errors.Is(err, order.ErrIllegalTransition):
+			h.ErrorResponse(c, http.StatusConflict, err, "Capture order error. Order can not be captured")
+			return
+		case errors.Is(err, order.ErrCaptureExceedsReserved):
+			h.ErrorResponse(c, http.StatusConflict, err, "Capture order error. Capture amount exceeds reserved amount")
+			return
+		case errors.Is(err, currency.ErrMismatch):
+			h.ErrorResponse(c, http.StatusConflict, err, "Capture order error. Currency does not match order currency")
+			return
+		}
+
+		h.ErrorResponse(c, http.StatusInternalServerError, err, "Capture order error")
+		return
+	}
+
+	c.JSON(http.StatusOK, NewCaptureOrderResponse(entity))
+}
+
 func (h *Handler) CancelOrder(c *gin.Context) {
 	var request CancelOrderRequest
 	if err := c.ShouldBindJSON(&request); err != nil {
@@ -95,15 +175,24 @@ func (h *Handler) CancelOrder(c *gin.Context) {
 		return
 	}
 
-	balance, err := h.service.CancelOrder(c.Request.Context(), request.ToDTO())
+	balance, err := h.service.CancelOrder(c.Request.Context(), request.ToDTO(h.Actor(c)))
 	if err != nil {
 		switch {
+		case errors.Is(err, currency.ErrInvalidAmount):
+			h.ErrorResponse(c, http.StatusBadRequest, err, "Cancel order error. Amount does not fit the currency")
+			return
 		case errors.Is(err, order.ErrNotFound):
 			h.ErrorResponse(c, http.StatusNotFound, err, "Cancel order error. Order not found")
 			return
+		case errors.Is(err, order.ErrIllegalTransition):
+			h.ErrorResponse(c, http.StatusConflict, err, "Cancel order error. Order can not be cancelled")
+			return
 		case errors.Is(err, account.ErrNotFound):
 			h.ErrorResponse(c, http.StatusNotFound, err, "Cancel order error. Account not found")
 			return
+		case errors.Is(err, currency.ErrMismatch):
+			h.ErrorResponse(c, http.StatusConflict, err, "Cancel order error. Currency does not match order currency")
+			return
 		}
 
 		h.ErrorResponse(c, http.StatusInternalServerError, err, "Cancel order error")
@@ -114,3 +203,40 @@ func (h *Handler) CancelOrder(c *gin.Context) {
 		Balance: balance,
 	})
 }
+
+func (h *Handler) RefundOrder(c *gin.Context) {
+	var request RefundOrderRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		h.ErrorResponse(c, http.StatusBadRequest, err, "Refund order error. Invalid request")
+		return
+	}
+
+	entity, balance, err := h.service.RefundOrder(c.Request.Context(), request.ToDTO(h.Actor(c)))
+	if err != nil {
+		switch {
+		case errors.Is(err, currency.ErrInvalidAmount):
+			h.ErrorResponse(c, http.StatusBadRequest, err, "Refund order error. Amount does not fit the currency")
+			return
+		case errors.Is(err, order.ErrNotFound):
+			h.ErrorResponse(c, http.StatusNotFound, err, "Refund order error. Order not found")
+			return
+		case errors.Is(err, account.ErrNotFound):
+			h.ErrorResponse(c, http.StatusNotFound, err, "Refund order error. Account not found")
+			return
+		case errors.Is(err, order.ErrIllegalTransition):
+			h.ErrorResponse(c, http.StatusConflict, err, "Refund order error. Order can not be refunded")
+			return
+		case errors.Is(err, order.ErrRefundExceedsPaid):
+			h.ErrorResponse(c, http.StatusConflict, err, "Refund order error. Refund amount exceeds paid amount")
+			return
+		case errors.Is(err, currency.ErrMismatch):
+			h.ErrorResponse(c, http.StatusConflict, err, "Refund order error. Currency does not match order currency")
+			return
+		}
+
+		h.ErrorResponse(c, http.StatusInternalServerError, err, "Refund order error")
+		return
+	}
+
+	c.JSON(http.StatusOK, NewRefundOrderResponse(entity, balance))
+}
@@ -16,6 +16,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/golang/mock/gomock"
 	"github.com/maypok86/payment-api/internal/domain/account"
+	"github.com/maypok86/payment-api/internal/domain/currency"
 	domain "github.com/maypok86/payment-api/internal/domain/order"
 	"github.com/maypok86/payment-api/internal/handler/http/v1/order"
 	"github.com/maypok86/payment-api/internal/pkg/handler"
@@ -51,14 +52,14 @@ func newCreateOrderResponse(t *testing.T, fakeOrder domain.Order, fakeBalance in
 
 	fakeResponse := order.CreateOrderResponse{
 		Order: order.Response{
-			OrderID:     fakeOrder.OrderID,
-			AccountID:   fakeOrder.AccountID,
-			ServiceID:   fakeOrder.ServiceID,
-			Amount:      fakeOrder.Amount,
-			IsPaid:      fakeOrder.IsPaid,
-			IsCancelled: fakeOrder.IsCancelled,
-			CreatedAt:   fakeOrder.CreatedAt,
-			UpdatedAt:   fakeOrder.UpdatedAt,
+			OrderID:   fakeOrder.OrderID,
+			AccountID: fakeOrder.AccountID,
+			ServiceID: fakeOrder.ServiceID,
+			Amount:    fakeOrder.Amount,
+			Status:    fakeOrder.Status.String(),
+			ExpiresAt: fakeOrder.ExpiresAt,
+			CreatedAt: fakeOrder.CreatedAt,
+			UpdatedAt: fakeOrder.UpdatedAt,
 		},
 		Balance: fakeBalance,
 	}
@@ -75,21 +76,22 @@ func TestHandler_CreateOrder(t *testing.T) {
 	ctx := context.Background()
 
 	fakeRequest := order.CreateOrderRequest{
-		OrderID:   1,
-		AccountID: 1,
-		ServiceID: 1,
-		Amount:    100,
+		OrderID:        1,
+		AccountID:      1,
+		ServiceID:      1,
+		Amount:         100,
+		ReservationTTL: 600,
 	}
 	fakeBalance := int64(100)
 	fakeOrder := domain.Order{
-		OrderID:     fakeRequest.OrderID,
-		AccountID:   fakeRequest.AccountID,
-		ServiceID:   fakeRequest.ServiceID,
-		Amount:      fakeRequest.Amount,
-		IsPaid:      false,
-		IsCancelled: false,
-		CreatedAt:   time.Now(),
-		UpdatedAt:   time.Now(),
+		OrderID:   fakeRequest.OrderID,
+		AccountID: fakeRequest.AccountID,
+		ServiceID: fakeRequest.ServiceID,
+		Amount:    fakeRequest.Amount,
+		Status:    domain.Reserved,
+		ExpiresAt: time.Now().Add(10 * time.Minute),
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
 	}
 	fakeResponse := newCreateOrderResponse(t, fakeOrder, fakeBalance)
 	orderServiceErr := errors.New("order service error")
@@ -135,11 +137,47 @@ func TestHandler_CreateOrder(t *testing.T) {
 			},
 			statusCode: http.StatusBadRequest,
 		},
+		{
+			name: "invalid reservation ttl",
+			mock: func(service *MockService) {
+			},
+			args: args{
+				request: order.CreateOrderRequest{
+					OrderID:        1,
+					AccountID:      1,
+					ServiceID:      1,
+					Amount:         100,
+					ReservationTTL: -1,
+				},
+			},
+			wantedErrorResponse: &handler.ErrorResponse{
+				Message: "Create order error. Invalid request",
+			},
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name: "too long reservation ttl",
+			mock: func(service *MockService) {
+			},
+			args: args{
+				request: order.CreateOrderRequest{
+					OrderID:        1,
+					AccountID:      1,
+					ServiceID:      1,
+					Amount:         100,
+					ReservationTTL: int64(domain.MaxReservationTTL/time.Second) + 1,
+				},
+			},
+			wantedErrorResponse: &handler.ErrorResponse{
+				Message: "Create order error. Invalid request",
+			},
+			statusCode: http.StatusBadRequest,
+		},
 		{
 			name: "account not found",
 			mock: func(service *MockService) {
 				service.EXPECT().
-					CreateOrder(ctx, fakeRequest.ToDTO()).
+					CreateOrder(ctx, fakeRequest.ToDTO(handler.DefaultActor)).
 					Return(domain.Order{}, int64(0), account.ErrNotFound)
 			},
 			args: args{
@@ -150,11 +188,71 @@ func TestHandler_CreateOrder(t *testing.T) {
 			},
 			statusCode: http.StatusNotFound,
 		},
+		{
+			name: "service not found",
+			mock: func(service *MockService) {
+				service.EXPECT().
+					CreateOrder(ctx, fakeRequest.ToDTO(handler.DefaultActor)).
+					Return(domain.Order{}, int64(0), domain.ErrServiceNotFound)
+			},
+			args: args{
+				request: fakeRequest,
+			},
+			wantedErrorResponse: &handler.ErrorResponse{
+				Message: "Create order error. Service not found",
+			},
+			statusCode: http.StatusNotFound,
+		},
+		{
+			name: "service is not active",
+			mock: func(service *MockService) {
+				service.EXPECT().
+					CreateOrder(ctx, fakeRequest.ToDTO(handler.DefaultActor)).
+					Return(domain.Order{}, int64(0), domain.ErrServiceInactive)
+			},
+			args: args{
+				request: fakeRequest,
+			},
+			wantedErrorResponse: &handler.ErrorResponse{
+				Message: "Create order error. Service is not active",
+			},
+			statusCode: http.StatusConflict,
+		},
+		{
+			name: "insufficient funds",
+			mock: func(service *MockService) {
+				service.EXPECT().
+					CreateOrder(ctx, fakeRequest.ToDTO(handler.DefaultActor)).
+					Return(domain.Order{}, int64(0), &account.InsufficientFundsError{AccountID: 1, Available: 10})
+			},
+			args: args{
+				request: fakeRequest,
+			},
+			wantedErrorResponse: &handler.ErrorResponse{
+				Message: "Create order error. Insufficient funds",
+			},
+			statusCode: http.StatusPaymentRequired,
+		},
+		{
+			name: "account is frozen",
+			mock: func(service *MockService) {
+				service.EXPECT().
+					CreateOrder(ctx, fakeRequest.ToDTO(handler.DefaultActor)).
+					Return(domain.Order{}, int64(0), account.ErrFrozen)
+			},
+			args: args{
+				request: fakeRequest,
+			},
+			wantedErrorResponse: &handler.ErrorResponse{
+				Message: "Create order error. Account is frozen",
+			},
+			statusCode: http.StatusConflict,
+		},
 		{
 			name: "order already exists",
 			mock: func(service *MockService) {
 				service.EXPECT().
-					CreateOrder(ctx, fakeRequest.ToDTO()).
+					CreateOrder(ctx, fakeRequest.ToDTO(handler.DefaultActor)).
 					Return(domain.Order{}, int64(0), domain.ErrAlreadyExist)
 			},
 			args: args{
@@ -168,7 +266,9 @@ func TestHandler_CreateOrder(t *testing.T) {
 		{
 			name: "order service error",
 			mock: func(service *MockService) {
-				service.EXPECT().CreateOrder(ctx, fakeRequest.ToDTO()).Return(domain.Order{}, int64(0), orderServiceErr)
+				service.EXPECT().
+					CreateOrder(ctx, fakeRequest.ToDTO(handler.DefaultActor)).
+					Return(domain.Order{}, int64(0), orderServiceErr)
 			},
 			args: args{
 				request: fakeRequest,
@@ -182,7 +282,7 @@ func TestHandler_CreateOrder(t *testing.T) {
 			name: "success create order",
 			mock: func(service *MockService) {
 				service.EXPECT().
-					CreateOrder(ctx, fakeRequest.ToDTO()).
+					CreateOrder(ctx, fakeRequest.ToDTO(handler.DefaultActor)).
 					Return(fakeOrder, fakeBalance, nil)
 			},
 			args: args{
@@ -225,12 +325,18 @@ func TestHandler_PayForOrder(t *testing.T) {
 		AccountID: 1,
 		ServiceID: 1,
 		Amount:    100,
+		Currency:  currency.USD,
+		Reason:    "service is provided",
 	}
+	fakeActor := "billing"
 	orderServiceErr := errors.New("order service error")
 
-	setupGin := func(c *gin.Context, content interface{}) {
+	setupGin := func(c *gin.Context, content interface{}, actor string) {
 		c.Request.Method = http.MethodPost
 		c.Request.Header.Set("Content-Type", "application/json")
+		if actor != "" {
+			c.Request.Header.Set(handler.ActorHeader, actor)
+		}
 
 		data, err := json.Marshal(content)
 		require.NoError(t, err)
@@ -242,6 +348,7 @@ func TestHandler_PayForOrder(t *testing.T) {
 
 	type args struct {
 		request order.PayForOrderRequest
+		actor   string
 	}
 
 	tests := []struct {
@@ -272,7 +379,7 @@ func TestHandler_PayForOrder(t *testing.T) {
 			name: "order not found",
 			mock: func(service *MockService) {
 				service.EXPECT().
-					PayForOrder(ctx, fakeRequest.ToDTO()).
+					PayForOrder(ctx, fakeRequest.ToDTO(handler.DefaultActor)).
 					Return(domain.ErrNotFound)
 			},
 			args: args{
@@ -283,10 +390,40 @@ func TestHandler_PayForOrder(t *testing.T) {
 			},
 			statusCode: http.StatusNotFound,
 		},
+		{
+			name: "order is not reserved",
+			mock: func(service *MockService) {
+				service.EXPECT().
+					PayForOrder(ctx, fakeRequest.ToDTO(handler.DefaultActor)).
+					Return(&domain.TransitionError{OrderID: 1, From: domain.Paid, To: domain.Paid})
+			},
+			args: args{
+				request: fakeRequest,
+			},
+			wantedErrorResponse: &handler.ErrorResponse{
+				Message: "Pay for order error. Order can not be paid",
+			},
+			statusCode: http.StatusConflict,
+		},
+		{
+			name: "order currency does not match request",
+			mock: func(service *MockService) {
+				service.EXPECT().
+					PayForOrder(ctx, fakeRequest.ToDTO(handler.DefaultActor)).
+					Return(currency.ErrMismatch)
+			},
+			args: args{
+				request: fakeRequest,
+			},
+			wantedErrorResponse: &handler.ErrorResponse{
+				Message: "Pay for order error. Currency does not match order currency",
+			},
+			statusCode: http.StatusConflict,
+		},
 		{
 			name: "account service error",
 			mock: func(service *MockService) {
-				service.EXPECT().PayForOrder(ctx, fakeRequest.ToDTO()).Return(orderServiceErr)
+				service.EXPECT().PayForOrder(ctx, fakeRequest.ToDTO(handler.DefaultActor)).Return(orderServiceErr)
 			},
 			args: args{
 				request: fakeRequest,
@@ -300,7 +437,7 @@ func TestHandler_PayForOrder(t *testing.T) {
 			name: "success pay for order",
 			mock: func(service *MockService) {
 				service.EXPECT().
-					PayForOrder(ctx, fakeRequest.ToDTO()).
+					PayForOrder(ctx, fakeRequest.ToDTO(handler.DefaultActor)).
 					Return(nil)
 			},
 			args: args{
@@ -308,6 +445,19 @@ func TestHandler_PayForOrder(t *testing.T) {
 			},
 			statusCode: http.StatusOK,
 		},
+		{
+			name: "success pay for order with actor",
+			mock: func(service *MockService) {
+				service.EXPECT().
+					PayForOrder(ctx, fakeRequest.ToDTO(fakeActor)).
+					Return(nil)
+			},
+			args: args{
+				request: fakeRequest,
+				actor:   fakeActor,
+			},
+			statusCode: http.StatusOK,
+		},
 	}
 
 	for _, tt := range tests {
@@ -315,7 +465,7 @@ func TestHandler_PayForOrder(t *testing.T) {
 			w := httptest.NewRecorder()
 			orderHandler, orderService, c := mockHandler(t, w)
 
-			setupGin(c, tt.args.request)
+			setupGin(c, tt.args.request, tt.args.actor)
 			tt.mock(orderService)
 
 			orderHandler.PayForOrder(c)
@@ -390,7 +540,7 @@ func TestHandler_CancelOrder(t *testing.T) {
 			name: "account not found",
 			mock: func(service *MockService) {
 				service.EXPECT().
-					CancelOrder(ctx, fakeRequest.ToDTO()).
+					CancelOrder(ctx, fakeRequest.ToDTO(handler.DefaultActor)).
 					Return(int64(0), account.ErrNotFound)
 			},
 			args: args{
@@ -405,7 +555,7 @@ func TestHandler_CancelOrder(t *testing.T) {
 			name: "order not found",
 			mock: func(service *MockService) {
 				service.EXPECT().
-					CancelOrder(ctx, fakeRequest.ToDTO()).
+					CancelOrder(ctx, fakeRequest.ToDTO(handler.DefaultActor)).
 					Return(int64(0), domain.ErrNotFound)
 			},
 			args: args{
@@ -416,10 +566,25 @@ func TestHandler_CancelOrder(t *testing.T) {
 			},
 			statusCode: http.StatusNotFound,
 		},
+		{
+			name: "order is not reserved",
+			mock: func(service *MockService) {
+				service.EXPECT().
+					CancelOrder(ctx, fakeRequest.ToDTO(handler.DefaultActor)).
+					Return(int64(0), &domain.TransitionError{OrderID: 1, From: domain.Paid, To: domain.Cancelled})
+			},
+			args: args{
+				request: fakeRequest,
+			},
+			wantedErrorResponse: &handler.ErrorResponse{
+				Message: "Cancel order error. Order can not be cancelled",
+			},
+			statusCode: http.StatusConflict,
+		},
 		{
 			name: "order service error",
 			mock: func(service *MockService) {
-				service.EXPECT().CancelOrder(ctx, fakeRequest.ToDTO()).Return(int64(0), orderServiceErr)
+				service.EXPECT().CancelOrder(ctx, fakeRequest.ToDTO(handler.DefaultActor)).Return(int64(0), orderServiceErr)
 			},
 			args: args{
 				request: fakeRequest,
@@ -433,7 +598,7 @@ func TestHandler_CancelOrder(t *testing.T) {
 			name: "success cancel order",
 			mock: func(service *MockService) {
 				service.EXPECT().
-					CancelOrder(ctx, fakeRequest.ToDTO()).
+					CancelOrder(ctx, fakeRequest.ToDTO(handler.DefaultActor)).
 					Return(fakeBalance, nil)
 			},
 			args: args{
@@ -467,3 +632,347 @@ func TestHandler_CancelOrder(t *testing.T) {
 		})
 	}
 }
+
+func TestHandler_RefundOrder(t *testing.T) {
+	ctx := context.Background()
+
+	fakeRequest := order.RefundOrderRequest{
+		OrderID:   1,
+		AccountID: 1,
+		ServiceID: 1,
+		Amount:    40,
+		Reason:    "service is not delivered",
+	}
+	fakeBalance := int64(140)
+	fakeOrder := domain.Order{
+		OrderID:        fakeRequest.OrderID,
+		AccountID:      fakeRequest.AccountID,
+		ServiceID:      fakeRequest.ServiceID,
+		Amount:         100,
+		CapturedAmount: 100,
+		RefundedAmount: fakeRequest.Amount,
+		Status:         domain.Paid,
+		CreatedAt:      time.Now(),
+		UpdatedAt:      time.Now(),
+	}
+	fakeResponse := order.NewRefundOrderResponse(fakeOrder, fakeBalance)
+	orderServiceErr := errors.New("order service error")
+
+	// for fix time.Time in json
+	var buffer bytes.Buffer
+	require.NoError(t, json.NewEncoder(&buffer).Encode(fakeResponse))
+	require.NoError(t, json.NewDecoder(&buffer).Decode(&fakeResponse))
+
+	setupGin := func(c *gin.Context, content interface{}) {
+		c.Request.Method = http.MethodPost
+		c.Request.Header.Set("Content-Type", "application/json")
+
+		data, err := json.Marshal(content)
+		require.NoError(t, err)
+
+		c.Request.Body = io.NopCloser(bytes.NewBuffer(data))
+	}
+
+	type mockBehaviour func(service *MockService)
+
+	type args struct {
+		request order.RefundOrderRequest
+	}
+
+	tests := []struct {
+		name                string
+		mock                mockBehaviour
+		args                args
+		response            order.RefundOrderResponse
+		wantedErrorResponse *handler.ErrorResponse
+		statusCode          int
+	}{
+		{
+			name: "invalid request",
+			mock: func(service *MockService) {
+			},
+			args: args{
+				request: order.RefundOrderRequest{
+					OrderID:   1,
+					AccountID: 1,
+					ServiceID: 1,
+					Amount:    -1,
+				},
+			},
+			wantedErrorResponse: &handler.ErrorResponse{
+				Message: "Refund order error. Invalid request",
+			},
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name: "order not found",
+			mock: func(service *MockService) {
+				service.EXPECT().
+					RefundOrder(ctx, fakeRequest.ToDTO(handler.DefaultActor)).
+					Return(domain.Order{}, int64(0), domain.ErrNotFound)
+			},
+			args: args{
+				request: fakeRequest,
+			},
+			wantedErrorResponse: &handler.ErrorResponse{
+				Message: "Refund order error. Order not found",
+			},
+			statusCode: http.StatusNotFound,
+		},
+		{
+			name: "order is not paid",
+			mock: func(service *MockService) {
+				service.EXPECT().
+					RefundOrder(ctx, fakeRequest.ToDTO(handler.DefaultActor)).
+					Return(
+						domain.Order{},
+						int64(0),
+						&domain.TransitionError{OrderID: 1, From: domain.Reserved, To: domain.Refunded},
+					)
+			},
+			args: args{
+				request: fakeRequest,
+			},
+			wantedErrorResponse: &handler.ErrorResponse{
+				Message: "Refund order error. Order can not be refunded",
+			},
+			statusCode: http.StatusConflict,
+		},
+		{
+			name: "refund exceeds paid amount",
+			mock: func(service *MockService) {
+				service.EXPECT().
+					RefundOrder(ctx, fakeRequest.ToDTO(handler.DefaultActor)).
+					Return(domain.Order{}, int64(0), domain.ErrRefundExceedsPaid)
+			},
+			args: args{
+				request: fakeRequest,
+			},
+			wantedErrorResponse: &handler.ErrorResponse{
+				Message: "Refund order error. Refund amount exceeds paid amount",
+			},
+			statusCode: http.StatusConflict,
+		},
+		{
+			name: "order service error",
+			mock: func(service *MockService) {
+				service.EXPECT().
+					RefundOrder(ctx, fakeRequest.ToDTO(handler.DefaultActor)).
+					Return(domain.Order{}, int64(0), orderServiceErr)
+			},
+			args: args{
+				request: fakeRequest,
+			},
+			wantedErrorResponse: &handler.ErrorResponse{
+				Message: "Refund order error",
+			},
+			statusCode: http.StatusInternalServerError,
+		},
+		{
+			name: "success refund order",
+			mock: func(service *MockService) {
+				service.EXPECT().
+					RefundOrder(ctx, fakeRequest.ToDTO(handler.DefaultActor)).
+					Return(fakeOrder, fakeBalance, nil)
+			},
+			args: args{
+				request: fakeRequest,
+			},
+			response:   fakeResponse,
+			statusCode: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			orderHandler, orderService, c := mockHandler(t, w)
+
+			setupGin(c, tt.args.request)
+			tt.mock(orderService)
+
+			orderHandler.RefundOrder(c)
+
+			require.Equal(t, tt.statusCode, w.Code)
+			if tt.wantedErrorResponse != nil {
+				var response handler.ErrorResponse
+				require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+				require.True(t, reflect.DeepEqual(tt.wantedErrorResponse, &response))
+			} else {
+				var response order.RefundOrderResponse
+				require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+				require.True(t, reflect.DeepEqual(tt.response, response))
+			}
+		})
+	}
+}
+
+func TestHandler_CaptureOrder(t *testing.T) {
+	ctx := context.Background()
+
+	fakeRequest := order.CaptureOrderRequest{
+		OrderID:   1,
+		AccountID: 1,
+		ServiceID: 1,
+		Amount:    70,
+		Reason:    "service is provided with discount",
+	}
+	fakeOrder := domain.Order{
+		OrderID:        fakeRequest.OrderID,
+		AccountID:      fakeRequest.AccountID,
+		ServiceID:      fakeRequest.ServiceID,
+		Amount:         100,
+		CapturedAmount: fakeRequest.Amount,
+		Status:         domain.Paid,
+		CreatedAt:      time.Now(),
+		UpdatedAt:      time.Now(),
+	}
+	fakeResponse := order.NewCaptureOrderResponse(fakeOrder)
+	orderServiceErr := errors.New("order service error")
+
+	// for fix time.Time in json
+	var buffer bytes.Buffer
+	require.NoError(t, json.NewEncoder(&buffer).Encode(fakeResponse))
+	require.NoError(t, json.NewDecoder(&buffer).Decode(&fakeResponse))
+
+	setupGin := func(c *gin.Context, content interface{}) {
+		c.Request.Method = http.MethodPost
+		c.Request.Header.Set("Content-Type", "application/json")
+
+		data, err := json.Marshal(content)
+		require.NoError(t, err)
+
+		c.Request.Body = io.NopCloser(bytes.NewBuffer(data))
+	}
+
+	type mockBehaviour func(service *MockService)
+
+	type args struct {
+		request order.CaptureOrderRequest
+	}
+
+	tests := []struct {
+		name                string
+		mock                mockBehaviour
+		args                args
+		response            order.CaptureOrderResponse
+		wantedErrorResponse *handler.ErrorResponse
+		statusCode          int
+	}{
+		{
+			name: "invalid request",
+			mock: func(service *MockService) {
+			},
+			args: args{
+				request: order.CaptureOrderRequest{
+					OrderID:   1,
+					AccountID: 1,
+					ServiceID: 1,
+				},
+			},
+			wantedErrorResponse: &handler.ErrorResponse{
+				Message: "Capture order error. Invalid request",
+			},
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name: "order not found",
+			mock: func(service *MockService) {
+				service.EXPECT().
+					CaptureOrder(ctx, fakeRequest.ToDTO(handler.DefaultActor)).
+					Return(domain.Order{}, domain.ErrNotFound)
+			},
+			args: args{
+				request: fakeRequest,
+			},
+			wantedErrorResponse: &handler.ErrorResponse{
+				Message: "Capture order error. Order not found",
+			},
+			statusCode: http.StatusNotFound,
+		},
+		{
+			name: "order is not reserved",
+			mock: func(service *MockService) {
+				service.EXPECT().
+					CaptureOrder(ctx, fakeRequest.ToDTO(handler.DefaultActor)).
+					Return(
+						domain.Order{},
+						&domain.TransitionError{OrderID: 1, From: domain.Paid, To: domain.Paid},
+					)
+			},
+			args: args{
+				request: fakeRequest,
+			},
+			wantedErrorResponse: &handler.ErrorResponse{
+				Message: "Capture order error. Order can not be captured",
+			},
+			statusCode: http.StatusConflict,
+		},
+		{
+			name: "capture exceeds reserved amount",
+			mock: func(service *MockService) {
+				service.EXPECT().
+					CaptureOrder(ctx, fakeRequest.ToDTO(handler.DefaultActor)).
+					Return(domain.Order{}, domain.ErrCaptureExceedsReserved)
+			},
+			args: args{
+				request: fakeRequest,
+			},
+			wantedErrorResponse: &handler.ErrorResponse{
+				Message: "Capture order error. Capture amount exceeds reserved amount",
+			},
+			statusCode: http.StatusConflict,
+		},
+		{
+			name: "order service error",
+			mock: func(service *MockService) {
+				service.EXPECT().
+					CaptureOrder(ctx, fakeRequest.ToDTO(handler.DefaultActor)).
+					Return(domain.Order{}, orderServiceErr)
+			},
+			args: args{
+				request: fakeRequest,
+			},
+			wantedErrorResponse: &handler.ErrorResponse{
+				Message: "Capture order error",
+			},
+			statusCode: http.StatusInternalServerError,
+		},
+		{
+			name: "success capture order",
+			mock: func(service *MockService) {
+				service.EXPECT().
+					CaptureOrder(ctx, fakeRequest.ToDTO(handler.DefaultActor)).
+					Return(fakeOrder, nil)
+			},
+			args: args{
+				request: fakeRequest,
+			},
+			response:   fakeResponse,
+			statusCode: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			orderHandler, orderService, c := mockHandler(t, w)
+
+			setupGin(c, tt.args.request)
+			tt.mock(orderService)
+
+			orderHandler.CaptureOrder(c)
+
+			require.Equal(t, tt.statusCode, w.Code)
+			if tt.wantedErrorResponse != nil {
+				var response handler.ErrorResponse
+				require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+				require.True(t, reflect.DeepEqual(tt.wantedErrorResponse, &response))
+			} else {
+				var response order.CaptureOrderResponse
+				require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+				require.True(t, reflect.DeepEqual(tt.response, response))
+			}
+		})
+	}
+}
@@ -50,6 +50,21 @@ func (mr *MockServiceMockRecorder) CancelOrder(ctx, dto interface{}) *gomock.Cal
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CancelOrder", reflect.TypeOf((*MockService)(nil).CancelOrder), ctx, dto)
 }
 
+// CaptureOrder mocks base method.
+func (m *MockService) CaptureOrder(ctx context.Context, dto order.CaptureDTO) (order.Order, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CaptureOrder", ctx, dto)
+	ret0, _ := ret[0].(order.Order)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CaptureOrder indicates an expected call of CaptureOrder.
+func (mr *MockServiceMockRecorder) CaptureOrder(ctx, dto interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CaptureOrder", reflect.TypeOf((*MockService)(nil).CaptureOrder), ctx, dto)
+}
+
 // CreateOrder mocks base method.
 func (m *MockService) CreateOrder(ctx context.Context, dto order.CreateDTO) (order.Order, int64, error) {
 	m.ctrl.T.Helper()
@@ -79,3 +94,19 @@ func (mr *MockServiceMockRecorder) PayForOrder(ctx, dto interface{}) *gomock.Cal
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PayForOrder", reflect.TypeOf((*MockService)(nil).PayForOrder), ctx, dto)
 }
+
+// RefundOrder mocks base method.
+func (m *MockService) RefundOrder(ctx context.Context, dto order.RefundDTO) (order.Order, int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RefundOrder", ctx, dto)
+	ret0, _ := ret[0].(order.Order)
+	ret1, _ := ret[1].(int64)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// RefundOrder indicates an expected call of RefundOrder.
+func (mr *MockServiceMockRecorder) RefundOrder(ctx, dto interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RefundOrder", reflect.TypeOf((*MockService)(nil).RefundOrder), ctx, dto)
+}
@@ -1,51 +1,114 @@
 package order
 
-import "github.com/maypok86/payment-api/internal/domain/order"
+import (
+	"time"
 
+	"github.com/maypok86/payment-api/internal/domain/currency"
+	"github.com/maypok86/payment-api/internal/domain/order"
+)
+
+// CreateOrderRequest takes the reservation TTL in seconds, at most order.MaxReservationTTL.
 type CreateOrderRequest struct {
-	OrderID   int64 `json:"order_id"   binding:"required,gte=1"`
-	AccountID int64 `json:"account_id" binding:"required,gte=1"`
-	ServiceID int64 `json:"service_id" binding:"required,gte=1"`
-	Amount    int64 `json:"amount"     binding:"required,gt=0"`
+	OrderID        int64             `json:"order_id"        binding:"required,gte=1"`
+	AccountID      int64             `json:"account_id"      binding:"required,gte=1"`
+	ServiceID      int64             `json:"service_id"      binding:"required,gte=1"`
+	Amount         int64             `json:"amount"          binding:"required,gt=0"`
+	Currency       currency.Currency `json:"currency"`
+	ReservationTTL int64             `json:"reservation_ttl" binding:"omitempty,gt=0,lte=2592000"`
 }
 
-func (r CreateOrderRequest) ToDTO() order.CreateDTO {
+func (r CreateOrderRequest) ToDTO(actor string) order.CreateDTO {
 	return order.CreateDTO{
+		OrderID:        r.OrderID,
+		AccountID:      r.AccountID,
+		ServiceID:      r.ServiceID,
+		Amount:         r.Amount,
+		Currency:       r.Currency.OrDefault(),
+		ReservationTTL: time.Duration(r.ReservationTTL) * time.Second,
+		Actor:          actor,
+	}
+}
+
+type PayForOrderRequest struct {
+	OrderID   int64             `json:"order_id"   binding:"required,gte=1"`
+	AccountID int64             `json:"account_id" binding:"required,gte=1"`
+	ServiceID int64             `json:"service_id" binding:"required,gte=1"`
+	Amount    int64             `json:"amount"     binding:"required,gt=0"`
+	Currency  currency.Currency `json:"currency"`
+	Reason    string            `json:"reason"     binding:"max=255"`
+}
+
+func (r PayForOrderRequest) ToDTO(actor string) order.PayForDTO {
+	return order.PayForDTO{
 		OrderID:   r.OrderID,
 		AccountID: r.AccountID,
 		ServiceID: r.ServiceID,
 		Amount:    r.Amount,
+		Currency:  r.Currency.OrDefault(),
+		Actor:     actor,
+		Reason:    r.Reason,
 	}
 }
 
-type PayForOrderRequest struct {
-	OrderID   int64 `json:"order_id"   binding:"required,gte=1"`
-	AccountID int64 `json:"account_id" binding:"required,gte=1"`
-	ServiceID int64 `json:"service_id" binding:"required,gte=1"`
-	Amount    int64 `json:"amount"     binding:"required,gt=0"`
+type CaptureOrderRequest struct {
+	OrderID   int64             `json:"order_id"   binding:"required,gte=1"`
+	AccountID int64             `json:"account_id" binding:"required,gte=1"`
+	ServiceID int64             `json:"service_id" binding:"required,gte=1"`
+	Amount    int64             `json:"amount"     binding:"required,gt=0"`
+	Currency  currency.Currency `json:"currency"`
+	Reason    string            `json:"reason"     binding:"max=255"`
 }
 
-func (r PayForOrderRequest) ToDTO() order.PayForDTO {
-	return order.PayForDTO{
+func (r CaptureOrderRequest) ToDTO(actor string) order.CaptureDTO {
+	return order.CaptureDTO{
 		OrderID:   r.OrderID,
 		AccountID: r.AccountID,
 		ServiceID: r.ServiceID,
 		Amount:    r.Amount,
+		Currency:  r.Currency.OrDefault(),
+		Actor:     actor,
+		Reason:    r.Reason,
 	}
 }
 
 type CancelOrderRequest struct {
-	OrderID   int64 `json:"order_id"   binding:"required,gte=1"`
-	AccountID int64 `json:"account_id" binding:"required,gte=1"`
-	ServiceID int64 `json:"service_id" binding:"required,gte=1"`
-	Amount    int64 `json:"amount"     binding:"required,gt=0"`
+	OrderID   int64             `json:"order_id"   binding:"required,gte=1"`
+	AccountID int64             `json:"account_id" binding:"required,gte=1"`
+	ServiceID int64             `json:"service_id" binding:"required,gte=1"`
+	Amount    int64             `json:"amount"     binding:"required,gt=0"`
+	Currency  currency.Currency `json:"currency"`
+	Reason    string            `json:"reason"     binding:"max=255"`
 }
 
-func (r CancelOrderRequest) ToDTO() order.CancelDTO {
+func (r CancelOrderRequest) ToDTO(actor string) order.CancelDTO {
 	return order.CancelDTO{
 		OrderID:   r.OrderID,
 		AccountID: r.AccountID,
 		ServiceID: r.ServiceID,
 		Amount:    r.Amount,
+		Currency:  r.Currency.OrDefault(),
+		Actor:     actor,
+		Reason:    r.Reason,
+	}
+}
+
+type RefundOrderRequest struct {
+	OrderID   int64             `json:"order_id"   binding:"required,gte=1"`
+	AccountID int64             `json:"account_id" binding:"required,gte=1"`
+	ServiceID int64             `json:"service_id" binding:"required,gte=1"`
+	Amount    int64             `json:"amount"     binding:"omitempty,gt=0"`
+	Currency  currency.Currency `json:"currency"`
+	Reason    string            `json:"reason"     binding:"max=255"`
+}
+
+func (r RefundOrderRequest) ToDTO(actor string) order.RefundDTO {
+	return order.RefundDTO{
+		OrderID:   r.OrderID,
+		AccountID: r.AccountID,
+		ServiceID: r.ServiceID,
+		Amount:    r.Amount,
+		Currency:  r.Currency.OrDefault(),
+		Actor:     actor,
+		Reason:    r.Reason,
 	}
 }
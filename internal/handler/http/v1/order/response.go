@@ -7,26 +7,32 @@ import (
 )
 
 type Response struct {
-	OrderID     int64     `json:"order_id"`
-	AccountID   int64     `json:"account_id"`
-	ServiceID   int64     `json:"service_id"`
-	Amount      int64     `json:"amount"`
-	IsPaid      bool      `json:"is_paid"`
-	IsCancelled bool      `json:"is_cancelled"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	OrderID        int64     `json:"order_id"`
+	AccountID      int64     `json:"account_id"`
+	ServiceID      int64     `json:"service_id"`
+	Amount         int64     `json:"amount"`
+	Currency       string    `json:"currency"`
+	CapturedAmount int64     `json:"captured_amount"`
+	RefundedAmount int64     `json:"refunded_amount"`
+	Status         string    `json:"status"`
+	ExpiresAt      time.Time `json:"expires_at"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
 }
 
 func NewResponse(entity order.Order) Response {
 	return Response{
-		OrderID:     entity.OrderID,
-		AccountID:   entity.AccountID,
-		ServiceID:   entity.ServiceID,
-		Amount:      entity.Amount,
-		IsPaid:      entity.IsPaid,
-		IsCancelled: entity.IsCancelled,
-		CreatedAt:   entity.CreatedAt,
-		UpdatedAt:   entity.UpdatedAt,
+		OrderID:        entity.OrderID,
+		AccountID:      entity.AccountID,
+		ServiceID:      entity.ServiceID,
+		Amount:         entity.Amount,
+		Currency:       entity.Currency.String(),
+		CapturedAmount: entity.CapturedAmount,
+		RefundedAmount: entity.RefundedAmount,
+		Status:         entity.Status.String(),
+		ExpiresAt:      entity.ExpiresAt,
+		CreatedAt:      entity.CreatedAt,
+		UpdatedAt:      entity.UpdatedAt,
 	}
 }
 
@@ -42,6 +48,28 @@ func NewCreateOrderResponse(entity order.Order, balance int64) CreateOrderRespon
 	}
 }
 
+type CaptureOrderResponse struct {
+	Order Response `json:"order"`
+}
+
+func NewCaptureOrderResponse(entity order.Order) CaptureOrderResponse {
+	return CaptureOrderResponse{
+		Order: NewResponse(entity),
+	}
+}
+
 type CancelOrderResponse struct {
 	Balance int64 `json:"balance"`
 }
+
+type RefundOrderResponse struct {
+	Order   Response `json:"order"`
+	Balance int64    `json:"balance"`
+}
+
+func NewRefundOrderResponse(entity order.Order, balance int64) RefundOrderResponse {
+	return RefundOrderResponse{
+		Order:   NewResponse(entity),
+		Balance: balance,
+	}
+}
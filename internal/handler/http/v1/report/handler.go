@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/maypok86/payment-api/internal/domain/report"
@@ -15,18 +16,26 @@ import (
 //go:generate mockgen -source=handler.go -destination=mock_test.go -package=report_test
 
 type Service interface {
-	GetReportKey(ctx context.Context, dto report.GetMapDTO) (string, error)
-	GetReportContent(ctx context.Context, filename string) ([]byte, error)
+	CreateJob(ctx context.Context, dto report.CreateJobDTO) (report.Job, error)
+	GetJob(ctx context.Context, jobID int64, principal string) (report.Job, error)
+	GetReportContent(ctx context.Context, filename string) (report.Content, error)
+	NegotiateFormat(accept string) (string, error)
 }
 
+// Config of download links. LinkScheme is http or https, the tokens of links are signed with LinkSecret
+// and expire after LinkTTL.
 type Config struct {
 	ReportHost string
 	ReportPort string
+	LinkScheme string
+	LinkSecret string
+	LinkTTL    time.Duration
 }
 
 type Handler struct {
 	*handler.BaseHandler
 	cfg     Config
+	signer  *report.LinkSigner
 	service Service
 	logger  *zap.Logger
 }
@@ -35,6 +44,7 @@ func NewHandler(cfg Config, service Service, logger *zap.Logger) *Handler {
 	return &Handler{
 		BaseHandler: handler.NewBaseHandler(logger),
 		cfg:         cfg,
+		signer:      report.NewLinkSigner(cfg.LinkSecret, cfg.LinkTTL),
 		service:     service,
 		logger:      logger,
 	}
@@ -44,6 +54,7 @@ func (h *Handler) InitAPI(router *gin.RouterGroup) {
 	reportGroup := router.Group("/report")
 	{
 		reportGroup.POST("/link", h.GetReportLink)
+		reportGroup.GET("/jobs/:job_id", h.GetReportJob)
 		reportGroup.GET("/download", h.DownloadReport)
 	}
 }
@@ -55,11 +66,22 @@ func (h *Handler) GetReportLink(c *gin.Context) {
 		return
 	}
 
-	key, err := h.service.GetReportKey(c.Request.Context(), request.ToDTO())
+	// The format in the request takes precedence over the Accept header.
+	dto := request.ToDTO(h.Actor(c))
+	if dto.Format == "" {
+		format, err := h.service.NegotiateFormat(c.GetHeader("Accept"))
+		if err != nil {
+			h.ErrorResponse(c, http.StatusNotAcceptable, err, "Get report link error. Report format is not acceptable")
+			return
+		}
+		dto.Format = format
+	}
+
+	job, err := h.service.CreateJob(c.Request.Context(), dto)
 	if err != nil {
 		switch {
-		case errors.Is(err, report.ErrNotFound):
-			h.ErrorResponse(c, http.StatusNotFound, err, "Get report link error. Report not found")
+		case errors.Is(err, report.ErrUnsupportedFormat):
+			h.ErrorResponse(c, http.StatusBadRequest, err, "Get report link error. Report format is not supported")
 			return
 		case errors.Is(err, report.ErrIsNotAvailable):
 			h.ErrorResponse(c, http.StatusNotFound, err, "Get report link error. Report is not available")
@@ -70,25 +92,92 @@ func (h *Handler) GetReportLink(c *gin.Context) {
 		return
 	}
 
-	link := fmt.Sprintf("http://%s:%s/api/v1/report/download?key=%s", h.cfg.ReportHost, h.cfg.ReportPort, key)
+	response, err := h.newJobResponse(job)
+	if err != nil {
+		h.ErrorResponse(c, http.StatusInternalServerError, err, "Get report link error. Internal server error")
+		return
+	}
 
-	c.JSON(http.StatusOK, GetReportLinkResponse{
-		Link: link,
-	})
+	c.JSON(http.StatusAccepted, response)
+}
+
+func (h *Handler) GetReportJob(c *gin.Context) {
+	jobID, err := h.ParseIDFromPath(c, "job_id")
+	if err != nil {
+		h.ErrorResponse(c, http.StatusBadRequest, err, "Report job not found. id is not valid")
+		return
+	}
+
+	job, err := h.service.GetJob(c.Request.Context(), jobID, h.Actor(c))
+	if err != nil {
+		if errors.Is(err, report.ErrJobNotFound) {
+			h.ErrorResponse(c, http.StatusNotFound, err, "Report job not found")
+			return
+		}
+
+		h.ErrorResponse(c, http.StatusInternalServerError, err, "Get report job error")
+		return
+	}
+
+	response, err := h.newJobResponse(job)
+	if err != nil {
+		h.ErrorResponse(c, http.StatusInternalServerError, err, "Get report job error")
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// newJobResponse signs the download link of a done job for the principal that created the job.
+func (h *Handler) newJobResponse(job report.Job) (JobResponse, error) {
+	response := NewJobResponse(job)
+	if job.Status != report.Done {
+		return response, nil
+	}
+
+	token, expiresAt, err := h.signer.Sign(job.Key, job.Principal)
+	if err != nil {
+		return JobResponse{}, err
+	}
+
+	response.Link = fmt.Sprintf(
+		"%s://%s:%s/api/v1/report/download?token=%s",
+		h.cfg.LinkScheme,
+		h.cfg.ReportHost,
+		h.cfg.ReportPort,
+		token,
+	)
+	response.LinkExpiresAt = &expiresAt
+
+	return response, nil
 }
 
 func (h *Handler) DownloadReport(c *gin.Context) {
-	key := c.Query("key")
-	if key == "" {
+	token := c.Query("token")
+	if token == "" {
 		h.ErrorResponse(
 			c,
 			http.StatusBadRequest,
-			errors.New("query key is empty"),
+			errors.New("query token is empty"),
 			"Download report error. Invalid request",
 		)
 		return
 	}
 
+	claims, err := h.signer.Verify(token)
+	if err != nil {
+		if errors.Is(err, report.ErrLinkExpired) {
+			h.ErrorResponse(c, http.StatusForbidden, err, "Download report error. Link is expired")
+			return
+		}
+
+		h.ErrorResponse(c, http.StatusForbidden, err, "Download report error. Link is not valid")
+		return
+	}
+
+	key := claims.Key
+	h.logger.Info("report download", zap.String("key", key), zap.String("principal", claims.Principal))
+
 	content, err := h.service.GetReportContent(c.Request.Context(), key)
 	if err != nil {
 		if errors.Is(err, report.ErrNotFound) {
@@ -100,6 +189,16 @@ func (h *Handler) DownloadReport(c *gin.Context) {
 		return
 	}
 
-	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=report_%s.csv", key))
-	c.Data(http.StatusOK, "application/csv", content)
+	if !report.Accepts(c.GetHeader("Accept"), content.ContentType) {
+		h.ErrorResponse(
+			c,
+			http.StatusNotAcceptable,
+			fmt.Errorf("report %s is not acceptable", content.ContentType),
+			"Download report error. Report format is not acceptable",
+		)
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=report_%s", key))
+	c.Data(http.StatusOK, content.ContentType, content.Data)
 }
@@ -12,7 +12,9 @@ import (
 	"net/url"
 	"os"
 	"reflect"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/golang/mock/gomock"
@@ -27,6 +29,9 @@ func newFakeConfig() report.Config {
 	return report.Config{
 		ReportHost: "localhost",
 		ReportPort: "8080",
+		LinkScheme: "https",
+		LinkSecret: "secret",
+		LinkTTL:    time.Hour,
 	}
 }
 
@@ -61,19 +66,27 @@ func TestHandler_GetReportLink(t *testing.T) {
 		Month: 2,
 		Year:  2022,
 	}
-	fakeKey := fmt.Sprintf("%d-%d", fakeRequest.Year, fakeRequest.Month)
-	fakeCfg := newFakeConfig()
-	fakeReportLink := fmt.Sprintf(
-		"http://%s:%s/api/v1/report/download?key=%s",
-		fakeCfg.ReportHost,
-		fakeCfg.ReportPort,
-		fakeKey,
-	)
+	fakeDTO := fakeRequest.ToDTO(handler.DefaultActor)
+	fakeDTO.Format = domain.DefaultFormat
+	fakeXLSXRequest := fakeRequest
+	fakeXLSXRequest.Format = "xlsx"
+	fakeJob := domain.Job{
+		JobID:     1,
+		Month:     fakeRequest.Month,
+		Year:      fakeRequest.Year,
+		Format:    domain.DefaultFormat,
+		Status:    domain.Queued,
+		CreatedAt: time.Date(2022, 11, 16, 12, 0, 0, 0, time.UTC),
+		UpdatedAt: time.Date(2022, 11, 16, 12, 0, 0, 0, time.UTC),
+	}
+	fakeXLSXJob := fakeJob
+	fakeXLSXJob.Format = "xlsx"
 	reportServiceErr := errors.New("report service error")
 
-	setupGin := func(c *gin.Context, content interface{}) {
+	setupGin := func(c *gin.Context, content interface{}, accept string) {
 		c.Request.Method = http.MethodPost
 		c.Request.Header.Set("Content-Type", "application/json")
+		c.Request.Header.Set("Accept", accept)
 
 		data, err := json.Marshal(content)
 		require.NoError(t, err)
@@ -85,13 +98,14 @@ func TestHandler_GetReportLink(t *testing.T) {
 
 	type args struct {
 		request report.GetReportLinkRequest
+		accept  string
 	}
 
 	tests := []struct {
 		name                string
 		mock                mockBehaviour
 		args                args
-		response            report.GetReportLinkResponse
+		response            report.JobResponse
 		wantedErrorResponse *handler.ErrorResponse
 		statusCode          int
 	}{
@@ -105,22 +119,39 @@ func TestHandler_GetReportLink(t *testing.T) {
 			statusCode: http.StatusBadRequest,
 		},
 		{
-			name: "not found report",
+			name: "not acceptable format",
 			mock: func(service *MockService) {
-				service.EXPECT().GetReportKey(ctx, fakeRequest.ToDTO()).Return("", domain.ErrNotFound)
+				service.EXPECT().NegotiateFormat("text/html").Return("", domain.ErrUnsupportedFormat)
 			},
 			args: args{
 				request: fakeRequest,
+				accept:  "text/html",
 			},
 			wantedErrorResponse: &handler.ErrorResponse{
-				Message: "Get report link error. Report not found",
+				Message: "Get report link error. Report format is not acceptable",
 			},
-			statusCode: http.StatusNotFound,
+			statusCode: http.StatusNotAcceptable,
+		},
+		{
+			name: "unsupported format",
+			mock: func(service *MockService) {
+				service.EXPECT().
+					CreateJob(ctx, fakeXLSXRequest.ToDTO(handler.DefaultActor)).
+					Return(domain.Job{}, domain.ErrUnsupportedFormat)
+			},
+			args: args{
+				request: fakeXLSXRequest,
+			},
+			wantedErrorResponse: &handler.ErrorResponse{
+				Message: "Get report link error. Report format is not supported",
+			},
+			statusCode: http.StatusBadRequest,
 		},
 		{
 			name: "report is not available yet",
 			mock: func(service *MockService) {
-				service.EXPECT().GetReportKey(ctx, fakeRequest.ToDTO()).Return("", domain.ErrIsNotAvailable)
+				service.EXPECT().NegotiateFormat("").Return(domain.DefaultFormat, nil)
+				service.EXPECT().CreateJob(ctx, fakeDTO).Return(domain.Job{}, domain.ErrIsNotAvailable)
 			},
 			args: args{
 				request: fakeRequest,
@@ -133,7 +164,8 @@ func TestHandler_GetReportLink(t *testing.T) {
 		{
 			name: "report service error",
 			mock: func(service *MockService) {
-				service.EXPECT().GetReportKey(ctx, fakeRequest.ToDTO()).Return("", reportServiceErr)
+				service.EXPECT().NegotiateFormat("").Return(domain.DefaultFormat, nil)
+				service.EXPECT().CreateJob(ctx, fakeDTO).Return(domain.Job{}, reportServiceErr)
 			},
 			args: args{
 				request: fakeRequest,
@@ -144,17 +176,53 @@ func TestHandler_GetReportLink(t *testing.T) {
 			statusCode: http.StatusInternalServerError,
 		},
 		{
-			name: "success get report link",
+			name: "success create report job",
+			mock: func(service *MockService) {
+				service.EXPECT().NegotiateFormat("").Return(domain.DefaultFormat, nil)
+				service.EXPECT().CreateJob(ctx, fakeDTO).Return(fakeJob, nil)
+			},
+			args: args{
+				request: fakeRequest,
+			},
+			response:   report.NewJobResponse(fakeJob),
+			statusCode: http.StatusAccepted,
+		},
+		{
+			name: "success create report job by accept header",
 			mock: func(service *MockService) {
-				service.EXPECT().GetReportKey(ctx, fakeRequest.ToDTO()).Return(fakeKey, nil)
+				dto := fakeRequest.ToDTO(handler.DefaultActor)
+				dto.Format = "json"
+				job := fakeJob
+				job.Format = "json"
+				service.EXPECT().NegotiateFormat("application/json").Return("json", nil)
+				service.EXPECT().CreateJob(ctx, dto).Return(job, nil)
 			},
 			args: args{
 				request: fakeRequest,
+				accept:  "application/json",
 			},
-			response: report.GetReportLinkResponse{
-				Link: fakeReportLink,
+			response: report.JobResponse{
+				JobID:     fakeJob.JobID,
+				Month:     fakeJob.Month,
+				Year:      fakeJob.Year,
+				Format:    "json",
+				Status:    "queued",
+				CreatedAt: fakeJob.CreatedAt,
+				UpdatedAt: fakeJob.UpdatedAt,
 			},
-			statusCode: http.StatusOK,
+			statusCode: http.StatusAccepted,
+		},
+		{
+			name: "format in request overrides accept header",
+			mock: func(service *MockService) {
+				service.EXPECT().CreateJob(ctx, fakeXLSXRequest.ToDTO(handler.DefaultActor)).Return(fakeXLSXJob, nil)
+			},
+			args: args{
+				request: fakeXLSXRequest,
+				accept:  "application/json",
+			},
+			response:   report.NewJobResponse(fakeXLSXJob),
+			statusCode: http.StatusAccepted,
 		},
 	}
 
@@ -163,7 +231,7 @@ func TestHandler_GetReportLink(t *testing.T) {
 			w := httptest.NewRecorder()
 			reportHandler, reportService, c := mockHandler(t, w)
 
-			setupGin(c, tt.args.request)
+			setupGin(c, tt.args.request, tt.args.accept)
 			tt.mock(reportService)
 
 			reportHandler.GetReportLink(c)
@@ -174,7 +242,7 @@ func TestHandler_GetReportLink(t *testing.T) {
 				require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
 				require.True(t, reflect.DeepEqual(tt.wantedErrorResponse, &response))
 			} else {
-				var response report.GetReportLinkResponse
+				var response report.JobResponse
 				require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
 				require.True(t, reflect.DeepEqual(tt.response, response))
 			}
@@ -182,16 +250,224 @@ func TestHandler_GetReportLink(t *testing.T) {
 	}
 }
 
+func TestHandler_GetReportJob(t *testing.T) {
+	ctx := context.Background()
+
+	fakeCfg := newFakeConfig()
+	createdAt := time.Date(2022, 11, 16, 12, 0, 0, 0, time.UTC)
+	fakeDoneJob := domain.Job{
+		JobID:     1,
+		Month:     2,
+		Year:      2022,
+		Format:    "csv",
+		Principal: handler.DefaultActor,
+		Status:    domain.Done,
+		Key:       "2022-2.csv",
+		CreatedAt: createdAt,
+		UpdatedAt: createdAt.Add(time.Second),
+	}
+	fakeFailedJob := domain.Job{
+		JobID:     2,
+		Month:     3,
+		Year:      2022,
+		Format:    "csv",
+		Status:    domain.Failed,
+		Error:     domain.ErrNotFound.Error(),
+		CreatedAt: createdAt,
+		UpdatedAt: createdAt.Add(time.Second),
+	}
+	fakeActorJob := fakeDoneJob
+	fakeActorJob.Principal = "accountant"
+	reportServiceErr := errors.New("report service error")
+
+	linkPrefix := fmt.Sprintf(
+		"%s://%s:%s/api/v1/report/download?token=",
+		fakeCfg.LinkScheme,
+		fakeCfg.ReportHost,
+		fakeCfg.ReportPort,
+	)
+	signer := domain.NewLinkSigner(fakeCfg.LinkSecret, fakeCfg.LinkTTL)
+
+	setupGin := func(c *gin.Context, param, actor string) {
+		c.Request.Method = http.MethodGet
+		c.Params = gin.Params{{Key: "job_id", Value: param}}
+		if actor != "" {
+			c.Request.Header.Set(handler.ActorHeader, actor)
+		}
+	}
+
+	type mockBehaviour func(service *MockService)
+
+	tests := []struct {
+		name                string
+		mock                mockBehaviour
+		param               string
+		actor               string
+		response            report.JobResponse
+		principal           string
+		wantedErrorResponse *handler.ErrorResponse
+		statusCode          int
+	}{
+		{
+			name: "invalid job id",
+			mock: func(service *MockService) {
+			},
+			param: "job",
+			wantedErrorResponse: &handler.ErrorResponse{
+				Message: "Report job not found. id is not valid",
+			},
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name: "not found job",
+			mock: func(service *MockService) {
+				service.EXPECT().GetJob(ctx, int64(3), handler.DefaultActor).Return(domain.Job{}, domain.ErrJobNotFound)
+			},
+			param: "3",
+			wantedErrorResponse: &handler.ErrorResponse{
+				Message: "Report job not found",
+			},
+			statusCode: http.StatusNotFound,
+		},
+		{
+			name: "job of another principal",
+			mock: func(service *MockService) {
+				service.EXPECT().GetJob(ctx, fakeDoneJob.JobID, "support").Return(domain.Job{}, domain.ErrJobNotFound)
+			},
+			param: "1",
+			actor: "support",
+			wantedErrorResponse: &handler.ErrorResponse{
+				Message: "Report job not found",
+			},
+			statusCode: http.StatusNotFound,
+		},
+		{
+			name: "report service error",
+			mock: func(service *MockService) {
+				service.EXPECT().GetJob(ctx, int64(3), handler.DefaultActor).Return(domain.Job{}, reportServiceErr)
+			},
+			param: "3",
+			wantedErrorResponse: &handler.ErrorResponse{
+				Message: "Get report job error",
+			},
+			statusCode: http.StatusInternalServerError,
+		},
+		{
+			name: "done job",
+			mock: func(service *MockService) {
+				service.EXPECT().GetJob(ctx, fakeDoneJob.JobID, handler.DefaultActor).Return(fakeDoneJob, nil)
+			},
+			param: "1",
+			response: report.JobResponse{
+				JobID:     fakeDoneJob.JobID,
+				Month:     fakeDoneJob.Month,
+				Year:      fakeDoneJob.Year,
+				Format:    fakeDoneJob.Format,
+				Status:    "done",
+				CreatedAt: fakeDoneJob.CreatedAt,
+				UpdatedAt: fakeDoneJob.UpdatedAt,
+			},
+			principal:  handler.DefaultActor,
+			statusCode: http.StatusOK,
+		},
+		{
+			name: "done job with actor",
+			mock: func(service *MockService) {
+				service.EXPECT().GetJob(ctx, fakeActorJob.JobID, "accountant").Return(fakeActorJob, nil)
+			},
+			param: "1",
+			actor: "accountant",
+			response: report.JobResponse{
+				JobID:     fakeDoneJob.JobID,
+				Month:     fakeDoneJob.Month,
+				Year:      fakeDoneJob.Year,
+				Format:    fakeDoneJob.Format,
+				Status:    "done",
+				CreatedAt: fakeDoneJob.CreatedAt,
+				UpdatedAt: fakeDoneJob.UpdatedAt,
+			},
+			principal:  "accountant",
+			statusCode: http.StatusOK,
+		},
+		{
+			name: "failed job",
+			mock: func(service *MockService) {
+				service.EXPECT().GetJob(ctx, fakeFailedJob.JobID, handler.DefaultActor).Return(fakeFailedJob, nil)
+			},
+			param: "2",
+			response: report.JobResponse{
+				JobID:     fakeFailedJob.JobID,
+				Month:     fakeFailedJob.Month,
+				Year:      fakeFailedJob.Year,
+				Format:    fakeFailedJob.Format,
+				Status:    "failed",
+				Error:     "report not found",
+				CreatedAt: fakeFailedJob.CreatedAt,
+				UpdatedAt: fakeFailedJob.UpdatedAt,
+			},
+			statusCode: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			reportHandler, reportService, c := mockHandler(t, w)
+
+			setupGin(c, tt.param, tt.actor)
+			tt.mock(reportService)
+
+			reportHandler.GetReportJob(c)
+
+			require.Equal(t, tt.statusCode, w.Code)
+			if tt.wantedErrorResponse != nil {
+				var response handler.ErrorResponse
+				require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+				require.True(t, reflect.DeepEqual(tt.wantedErrorResponse, &response))
+				return
+			}
+
+			var response report.JobResponse
+			require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+
+			if tt.principal != "" {
+				require.True(t, strings.HasPrefix(response.Link, linkPrefix))
+				claims, err := signer.Verify(strings.TrimPrefix(response.Link, linkPrefix))
+				require.NoError(t, err)
+				require.Equal(t, fakeDoneJob.Key, claims.Key)
+				require.Equal(t, tt.principal, claims.Principal)
+				require.NotNil(t, response.LinkExpiresAt)
+				require.Equal(t, claims.ExpiresAt, response.LinkExpiresAt.Unix())
+
+				response.Link = ""
+				response.LinkExpiresAt = nil
+			}
+			require.True(t, reflect.DeepEqual(tt.response, response))
+		})
+	}
+}
+
 func TestHandler_DownloadReport(t *testing.T) {
 	ctx := context.Background()
 
-	fakeKey := "2022-2"
+	fakeCfg := newFakeConfig()
+	fakeKey := "2022-2.csv"
+	fakeToken, _, err := domain.NewLinkSigner(fakeCfg.LinkSecret, fakeCfg.LinkTTL).Sign(fakeKey, "accountant")
+	require.NoError(t, err)
+	expiredToken, _, err := domain.NewLinkSigner(fakeCfg.LinkSecret, -time.Minute).Sign(fakeKey, "accountant")
+	require.NoError(t, err)
+	forgedToken, _, err := domain.NewLinkSigner("forged", fakeCfg.LinkTTL).Sign(fakeKey, "accountant")
+	require.NoError(t, err)
 	reportServiceErr := errors.New("report service error")
-	fakeReport := []byte("fake report")
+	fakeReport := domain.Content{
+		Data:        []byte("fake report"),
+		ContentType: "text/csv",
+	}
 
-	setupGin := func(c *gin.Context, queryParams map[string]string) {
+	setupGin := func(c *gin.Context, queryParams map[string]string, accept string) {
 		c.Request.Method = http.MethodGet
 		c.Request.Header.Set("Content-Type", "application/json")
+		c.Request.Header.Set("Accept", accept)
 
 		query := url.Values{}
 		for k, v := range queryParams {
@@ -204,13 +480,14 @@ func TestHandler_DownloadReport(t *testing.T) {
 
 	type args struct {
 		queryParams map[string]string
+		accept      string
 	}
 
 	tests := []struct {
 		name                string
 		mock                mockBehaviour
 		args                args
-		response            []byte
+		response            domain.Content
 		wantedErrorResponse *handler.ErrorResponse
 		statusCode          int
 	}{
@@ -227,15 +504,57 @@ func TestHandler_DownloadReport(t *testing.T) {
 			statusCode: http.StatusBadRequest,
 		},
 		{
-			name: "not found report",
+			name: "key instead of token",
 			mock: func(service *MockService) {
-				service.EXPECT().GetReportContent(ctx, fakeKey).Return(nil, domain.ErrNotFound)
 			},
 			args: args{
 				queryParams: map[string]string{
 					"key": fakeKey,
 				},
 			},
+			wantedErrorResponse: &handler.ErrorResponse{
+				Message: "Download report error. Invalid request",
+			},
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name: "forged token",
+			mock: func(service *MockService) {
+			},
+			args: args{
+				queryParams: map[string]string{
+					"token": forgedToken,
+				},
+			},
+			wantedErrorResponse: &handler.ErrorResponse{
+				Message: "Download report error. Link is not valid",
+			},
+			statusCode: http.StatusForbidden,
+		},
+		{
+			name: "expired token",
+			mock: func(service *MockService) {
+			},
+			args: args{
+				queryParams: map[string]string{
+					"token": expiredToken,
+				},
+			},
+			wantedErrorResponse: &handler.ErrorResponse{
+				Message: "Download report error. Link is expired",
+			},
+			statusCode: http.StatusForbidden,
+		},
+		{
+			name: "not found report",
+			mock: func(service *MockService) {
+				service.EXPECT().GetReportContent(ctx, fakeKey).Return(domain.Content{}, domain.ErrNotFound)
+			},
+			args: args{
+				queryParams: map[string]string{
+					"token": fakeToken,
+				},
+			},
 			wantedErrorResponse: &handler.ErrorResponse{
 				Message: "Download report error. Report not found",
 			},
@@ -244,11 +563,11 @@ func TestHandler_DownloadReport(t *testing.T) {
 		{
 			name: "report service error",
 			mock: func(service *MockService) {
-				service.EXPECT().GetReportContent(ctx, fakeKey).Return(nil, reportServiceErr)
+				service.EXPECT().GetReportContent(ctx, fakeKey).Return(domain.Content{}, reportServiceErr)
 			},
 			args: args{
 				queryParams: map[string]string{
-					"key": fakeKey,
+					"token": fakeToken,
 				},
 			},
 			wantedErrorResponse: &handler.ErrorResponse{
@@ -263,12 +582,42 @@ func TestHandler_DownloadReport(t *testing.T) {
 			},
 			args: args{
 				queryParams: map[string]string{
-					"key": fakeKey,
+					"token": fakeToken,
+				},
+			},
+			response:   fakeReport,
+			statusCode: http.StatusOK,
+		},
+		{
+			name: "success download acceptable report",
+			mock: func(service *MockService) {
+				service.EXPECT().GetReportContent(ctx, fakeKey).Return(fakeReport, nil)
+			},
+			args: args{
+				queryParams: map[string]string{
+					"token": fakeToken,
 				},
+				accept: "application/json;q=0.9, text/*",
 			},
 			response:   fakeReport,
 			statusCode: http.StatusOK,
 		},
+		{
+			name: "not acceptable report",
+			mock: func(service *MockService) {
+				service.EXPECT().GetReportContent(ctx, fakeKey).Return(fakeReport, nil)
+			},
+			args: args{
+				queryParams: map[string]string{
+					"token": fakeToken,
+				},
+				accept: "application/json",
+			},
+			wantedErrorResponse: &handler.ErrorResponse{
+				Message: "Download report error. Report format is not acceptable",
+			},
+			statusCode: http.StatusNotAcceptable,
+		},
 	}
 
 	for _, tt := range tests {
@@ -276,7 +625,7 @@ func TestHandler_DownloadReport(t *testing.T) {
 			w := httptest.NewRecorder()
 			reportHandler, reportService, c := mockHandler(t, w)
 
-			setupGin(c, tt.args.queryParams)
+			setupGin(c, tt.args.queryParams, tt.args.accept)
 			tt.mock(reportService)
 
 			reportHandler.DownloadReport(c)
@@ -289,7 +638,9 @@ func TestHandler_DownloadReport(t *testing.T) {
 			} else {
 				got, err := io.ReadAll(w.Body)
 				require.NoError(t, err)
-				require.True(t, reflect.DeepEqual(tt.response, got))
+				require.True(t, reflect.DeepEqual(tt.response.Data, got))
+				require.Equal(t, tt.response.ContentType, w.Header().Get("Content-Type"))
+				require.Equal(t, "attachment; filename=report_"+fakeKey, w.Header().Get("Content-Disposition"))
 			}
 		})
 	}
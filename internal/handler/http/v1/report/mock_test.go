@@ -35,11 +35,41 @@ func (m *MockService) EXPECT() *MockServiceMockRecorder {
 	return m.recorder
 }
 
+// CreateJob mocks base method.
+func (m *MockService) CreateJob(ctx context.Context, dto report.CreateJobDTO) (report.Job, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateJob", ctx, dto)
+	ret0, _ := ret[0].(report.Job)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateJob indicates an expected call of CreateJob.
+func (mr *MockServiceMockRecorder) CreateJob(ctx, dto interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateJob", reflect.TypeOf((*MockService)(nil).CreateJob), ctx, dto)
+}
+
+// GetJob mocks base method.
+func (m *MockService) GetJob(ctx context.Context, jobID int64, principal string) (report.Job, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetJob", ctx, jobID, principal)
+	ret0, _ := ret[0].(report.Job)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetJob indicates an expected call of GetJob.
+func (mr *MockServiceMockRecorder) GetJob(ctx, jobID, principal interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetJob", reflect.TypeOf((*MockService)(nil).GetJob), ctx, jobID, principal)
+}
+
 // GetReportContent mocks base method.
-func (m *MockService) GetReportContent(ctx context.Context, filename string) ([]byte, error) {
+func (m *MockService) GetReportContent(ctx context.Context, filename string) (report.Content, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "GetReportContent", ctx, filename)
-	ret0, _ := ret[0].([]byte)
+	ret0, _ := ret[0].(report.Content)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
@@ -50,17 +80,17 @@ func (mr *MockServiceMockRecorder) GetReportContent(ctx, filename interface{}) *
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetReportContent", reflect.TypeOf((*MockService)(nil).GetReportContent), ctx, filename)
 }
 
-// GetReportKey mocks base method.
-func (m *MockService) GetReportKey(ctx context.Context, dto report.GetMapDTO) (string, error) {
+// NegotiateFormat mocks base method.
+func (m *MockService) NegotiateFormat(accept string) (string, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetReportKey", ctx, dto)
+	ret := m.ctrl.Call(m, "NegotiateFormat", accept)
 	ret0, _ := ret[0].(string)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetReportKey indicates an expected call of GetReportKey.
-func (mr *MockServiceMockRecorder) GetReportKey(ctx, dto interface{}) *gomock.Call {
+// NegotiateFormat indicates an expected call of NegotiateFormat.
+func (mr *MockServiceMockRecorder) NegotiateFormat(accept interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetReportKey", reflect.TypeOf((*MockService)(nil).GetReportKey), ctx, dto)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NegotiateFormat", reflect.TypeOf((*MockService)(nil).NegotiateFormat), accept)
 }
@@ -1,5 +1,34 @@
 package report
 
-type GetReportLinkResponse struct {
-	Link string `json:"link"`
+import (
+	"time"
+
+	"github.com/maypok86/payment-api/internal/domain/report"
+)
+
+// JobResponse has the download link once the job is done and the error once it failed.
+type JobResponse struct {
+	JobID         int64      `json:"job_id"`
+	Month         int64      `json:"month"`
+	Year          int64      `json:"year"`
+	Format        string     `json:"format"`
+	Status        string     `json:"status"`
+	Link          string     `json:"link,omitempty"`
+	LinkExpiresAt *time.Time `json:"link_expires_at,omitempty"`
+	Error         string     `json:"error,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+func NewJobResponse(job report.Job) JobResponse {
+	return JobResponse{
+		JobID:     job.JobID,
+		Month:     job.Month,
+		Year:      job.Year,
+		Format:    job.Format,
+		Status:    job.Status.String(),
+		Error:     job.Error,
+		CreatedAt: job.CreatedAt,
+		UpdatedAt: job.UpdatedAt,
+	}
 }
@@ -3,11 +3,15 @@ package transaction
 import (
 	"context"
 	"errors"
+	"io"
 	"net/http"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
+	"github.com/maypok86/payment-api/internal/domain/currency"
 	"github.com/maypok86/payment-api/internal/domain/transaction"
 	"github.com/maypok86/payment-api/internal/pkg/handler"
+	"github.com/maypok86/payment-api/internal/pkg/pagination"
 	"go.uber.org/zap"
 )
 
@@ -18,7 +22,13 @@ type Service interface {
 		ctx context.Context,
 		accountID int64,
 		listParams transaction.ListParams,
-	) ([]transaction.Transaction, int, error)
+	) (transaction.List, error)
+	WriteStatement(
+		ctx context.Context,
+		dto transaction.StatementDTO,
+		format transaction.StatementFormat,
+		w io.Writer,
+	) error
 }
 
 type Handler struct {
@@ -39,6 +49,7 @@ func (h *Handler) InitAPI(router *gin.RouterGroup) {
 	transactionsGroup := router.Group("/transaction")
 	{
 		transactionsGroup.GET("/:account_id", h.GetTransactionsByAccountID)
+		transactionsGroup.GET("/:account_id/statement", h.GetStatement)
 	}
 }
 
@@ -71,11 +82,137 @@ func (h *Handler) GetTransactionsByAccountID(c *gin.Context) {
 		return
 	}
 
-	transactions, count, err := h.service.GetTransactionsByAccountID(c.Request.Context(), accountID, listParams)
+	listParams.Filter, err = transaction.NewFilter(transaction.FilterParams{
+		Types:          c.Query("type"),
+		Flow:           c.Query("flow"),
+		From:           c.Query("from"),
+		To:             c.Query("to"),
+		MinAmount:      c.Query("min_amount"),
+		MaxAmount:      c.Query("max_amount"),
+		CounterpartyID: c.Query("counterparty_id"),
+		Description:    c.Query("description"),
+	})
+	if err != nil {
+		h.ErrorResponse(c, http.StatusBadRequest, err, filterErrorMessage(err))
+		return
+	}
+
+	if cursorParam := c.Query("cursor"); cursorParam != "" {
+		listParams, err = h.withCursor(c, listParams, cursorParam)
+		if err != nil {
+			return
+		}
+		params.Offset = 0
+	}
+
+	list, err := h.service.GetTransactionsByAccountID(c.Request.Context(), accountID, listParams)
 	if err != nil {
 		h.ErrorResponse(c, http.StatusInternalServerError, err, "Get transactions by account id error")
 		return
 	}
 
-	c.JSON(http.StatusOK, NewListResponse(transactions, params, count))
+	c.JSON(http.StatusOK, NewListResponse(list, params))
+}
+
+func filterErrorMessage(err error) string {
+	switch {
+	case errors.Is(err, transaction.ErrInvalidTypeParam):
+		return "Transactions not found. Type param is not valid"
+	case errors.Is(err, transaction.ErrInvalidFlowParam):
+		return "Transactions not found. Flow param is not valid"
+	case errors.Is(err, transaction.ErrInvalidDateParam):
+		return "Transactions not found. Date param is not valid"
+	case errors.Is(err, transaction.ErrInvalidDateRange):
+		return "Transactions not found. From must be before to"
+	case errors.Is(err, transaction.ErrInvalidAmountParam):
+		return "Transactions not found. Amount param is not valid"
+	case errors.Is(err, transaction.ErrInvalidAmountRange):
+		return "Transactions not found. Min amount must not be greater than max amount"
+	case errors.Is(err, transaction.ErrInvalidCounterpartyParam):
+		return "Transactions not found. Counterparty param is not valid"
+	case errors.Is(err, transaction.ErrInvalidDescriptionParam):
+		return "Transactions not found. Description param is too long"
+	default:
+		return "Transactions not found. Can not create filter"
+	}
+}
+
+// withCursor starts the list at the cursor. It writes the error response itself if the cursor is not valid.
+func (h *Handler) withCursor(
+	c *gin.Context,
+	listParams transaction.ListParams,
+	cursorParam string,
+) (transaction.ListParams, error) {
+	var withCount bool
+	if withCountParam := c.Query("with_count"); withCountParam != "" {
+		var err error
+		withCount, err = strconv.ParseBool(withCountParam)
+		if err != nil {
+			h.ErrorResponse(c, http.StatusBadRequest, err, "Transactions not found. With count param is not valid")
+			return transaction.ListParams{}, err
+		}
+	}
+
+	cursor, err := pagination.ParseCursor(cursorParam)
+	if err == nil {
+		listParams, err = listParams.WithCursor(cursor, withCount)
+	}
+	if err != nil {
+		if errors.Is(err, transaction.ErrCursorMismatch) {
+			h.ErrorResponse(c, http.StatusBadRequest, err, "Transactions not found. Cursor does not match sort param")
+			return transaction.ListParams{}, err
+		}
+		h.ErrorResponse(c, http.StatusBadRequest, err, "Transactions not found. Cursor is not valid")
+		return transaction.ListParams{}, err
+	}
+
+	return listParams, nil
+}
+
+// GetStatement streams the statement as it is read from the database. Errors which happen after the first
+// bytes are sent can not change the status, so the handler only logs them and the statement is cut before
+// its closing balance.
+func (h *Handler) GetStatement(c *gin.Context) {
+	accountID, err := h.ParseIDFromPath(c, "account_id")
+	if err != nil {
+		h.ErrorResponse(c, http.StatusBadRequest, err, "Statement not found. id is not valid")
+		return
+	}
+
+	cur := currency.Default
+	if code := c.Query("currency"); code != "" {
+		cur, err = currency.Parse(code)
+		if err != nil {
+			h.ErrorResponse(c, http.StatusBadRequest, err, "Statement not found. currency is not valid")
+			return
+		}
+	}
+
+	format, err := transaction.ParseStatementFormat(c.Query("format"))
+	if err != nil {
+		h.ErrorResponse(c, http.StatusBadRequest, err, "Statement not found. Format param is not valid")
+		return
+	}
+
+	dto, err := transaction.NewStatementDTO(accountID, cur, c.Query("from"), c.Query("to"))
+	if err != nil {
+		if errors.Is(err, transaction.ErrInvalidDateRange) {
+			h.ErrorResponse(c, http.StatusBadRequest, err, "Statement not found. From must be before to")
+			return
+		}
+
+		h.ErrorResponse(c, http.StatusBadRequest, err, "Statement not found. Date param is not valid")
+		return
+	}
+
+	writer := newStatementWriter(c, accountID, format)
+	if err := h.service.WriteStatement(c.Request.Context(), dto, format, writer); err != nil {
+		if !writer.started {
+			h.ErrorResponse(c, http.StatusInternalServerError, err, "Get statement error")
+			return
+		}
+
+		h.logger.Error("stream statement", zap.Int64("account_id", accountID), zap.Error(err))
+		c.Abort()
+	}
 }
@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
@@ -17,6 +18,7 @@ import (
 	"github.com/bxcodec/faker/v3"
 	"github.com/gin-gonic/gin"
 	"github.com/golang/mock/gomock"
+	"github.com/maypok86/payment-api/internal/domain/currency"
 	domain "github.com/maypok86/payment-api/internal/domain/transaction"
 	"github.com/maypok86/payment-api/internal/handler/http/v1/transaction"
 	"github.com/maypok86/payment-api/internal/pkg/handler"
@@ -75,15 +77,10 @@ func newTransactions(t *testing.T, senderID int64, count int) []domain.Transacti
 	return transactions
 }
 
-func newListResponse(
-	t *testing.T,
-	transactions []domain.Transaction,
-	params pagination.Params,
-	count int,
-) transaction.ListResponse {
+func newListResponse(t *testing.T, list domain.List, params pagination.Params) transaction.ListResponse {
 	t.Helper()
 
-	fakeResponse := transaction.NewListResponse(transactions, params, count)
+	fakeResponse := transaction.NewListResponse(list, params)
 
 	// for fix time.Time in json
 	var buffer bytes.Buffer
@@ -115,6 +112,41 @@ func TestHandler_GetTransactionByAccountID(t *testing.T) {
 	)
 	require.NoError(t, err)
 	fakeTransactions := newTransactions(t, fakeAccountID, 10)
+	fakeCount := len(fakeTransactions)
+	fakeList := domain.List{
+		Transactions: fakeTransactions,
+		Count:        &fakeCount,
+		Next:         domain.CursorOf(fakeTransactions[0], "created_at", false),
+	}
+	fakeFilterQueryParams := map[string]string{
+		"limit":      fmt.Sprintf("%d", fakePaginationParams.Limit),
+		"sort":       "date",
+		"direction":  "asc",
+		"type":       "transfer",
+		"flow":       "outgoing",
+		"min_amount": "50",
+	}
+	fakeMinAmount := int64(50)
+	fakeFilterListParams := fakeListParams
+	fakeFilterListParams.Filter = domain.Filter{
+		Types:     []domain.Type{domain.Transfer},
+		Flow:      domain.Outgoing,
+		MinAmount: &fakeMinAmount,
+	}
+	fakeCursor := domain.CursorOf(fakeTransactions[0], "created_at", false)
+	fakeCursorQueryParams := map[string]string{
+		"limit":      fmt.Sprintf("%d", fakePaginationParams.Limit),
+		"sort":       "date",
+		"cursor":     fakeCursor.String(),
+		"with_count": "true",
+	}
+	fakeCursorListParams, err := fakeListParams.WithCursor(*fakeCursor, true)
+	require.NoError(t, err)
+	fakeCursorList := domain.List{
+		Transactions: fakeTransactions[1:2],
+		Next:         domain.CursorOf(fakeTransactions[1], "created_at", false),
+		Prev:         domain.CursorOf(fakeTransactions[1], "created_at", true),
+	}
 
 	transactionServiceErr := errors.New("transaction service error")
 
@@ -203,12 +235,90 @@ func TestHandler_GetTransactionByAccountID(t *testing.T) {
 			},
 			statusCode: http.StatusBadRequest,
 		},
+		{
+			name: "invalid type param",
+			mock: func(service *MockService) {
+			},
+			args: args{
+				param: fakeParam,
+				queryParams: map[string]string{
+					"type": "gift",
+				},
+			},
+			wantedErrorResponse: &handler.ErrorResponse{
+				Message: "Transactions not found. Type param is not valid",
+			},
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name: "invalid amount range",
+			mock: func(service *MockService) {
+			},
+			args: args{
+				param: fakeParam,
+				queryParams: map[string]string{
+					"min_amount": "500",
+					"max_amount": "100",
+				},
+			},
+			wantedErrorResponse: &handler.ErrorResponse{
+				Message: "Transactions not found. Min amount must not be greater than max amount",
+			},
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name: "invalid cursor param",
+			mock: func(service *MockService) {
+			},
+			args: args{
+				param: fakeParam,
+				queryParams: map[string]string{
+					"cursor": "invalid",
+				},
+			},
+			wantedErrorResponse: &handler.ErrorResponse{
+				Message: "Transactions not found. Cursor is not valid",
+			},
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name: "cursor does not match sort param",
+			mock: func(service *MockService) {
+			},
+			args: args{
+				param: fakeParam,
+				queryParams: map[string]string{
+					"sort":   "sum",
+					"cursor": fakeCursor.String(),
+				},
+			},
+			wantedErrorResponse: &handler.ErrorResponse{
+				Message: "Transactions not found. Cursor does not match sort param",
+			},
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name: "invalid with_count param",
+			mock: func(service *MockService) {
+			},
+			args: args{
+				param: fakeParam,
+				queryParams: map[string]string{
+					"cursor":     fakeCursor.String(),
+					"with_count": "invalid",
+				},
+			},
+			wantedErrorResponse: &handler.ErrorResponse{
+				Message: "Transactions not found. With count param is not valid",
+			},
+			statusCode: http.StatusBadRequest,
+		},
 		{
 			name: "transaction service error",
 			mock: func(service *MockService) {
 				service.EXPECT().
 					GetTransactionsByAccountID(ctx, fakeAccountID, fakeListParams).
-					Return(nil, 0, transactionServiceErr)
+					Return(domain.List{}, transactionServiceErr)
 			},
 			args: args{
 				param:       fakeParam,
@@ -224,13 +334,41 @@ func TestHandler_GetTransactionByAccountID(t *testing.T) {
 			mock: func(service *MockService) {
 				service.EXPECT().
 					GetTransactionsByAccountID(ctx, fakeAccountID, fakeListParams).
-					Return(fakeTransactions, len(fakeTransactions), nil)
+					Return(fakeList, nil)
 			},
 			args: args{
 				param:       fakeParam,
 				queryParams: fakeQueryParams,
 			},
-			response:   newListResponse(t, fakeTransactions, fakePaginationParams, len(fakeTransactions)),
+			response:   newListResponse(t, fakeList, fakePaginationParams),
+			statusCode: http.StatusOK,
+		},
+		{
+			name: "success get filtered transactions",
+			mock: func(service *MockService) {
+				service.EXPECT().
+					GetTransactionsByAccountID(ctx, fakeAccountID, fakeFilterListParams).
+					Return(fakeList, nil)
+			},
+			args: args{
+				param:       fakeParam,
+				queryParams: fakeFilterQueryParams,
+			},
+			response:   newListResponse(t, fakeList, fakePaginationParams),
+			statusCode: http.StatusOK,
+		},
+		{
+			name: "success get transactions by cursor",
+			mock: func(service *MockService) {
+				service.EXPECT().
+					GetTransactionsByAccountID(ctx, fakeAccountID, fakeCursorListParams).
+					Return(fakeCursorList, nil)
+			},
+			args: args{
+				param:       fakeParam,
+				queryParams: fakeCursorQueryParams,
+			},
+			response:   newListResponse(t, fakeCursorList, fakePaginationParams),
 			statusCode: http.StatusOK,
 		},
 	}
@@ -258,3 +396,123 @@ func TestHandler_GetTransactionByAccountID(t *testing.T) {
 		})
 	}
 }
+
+func TestHandler_GetStatement(t *testing.T) {
+	ctx := context.Background()
+
+	fakeAccountID := int64(1)
+	fakeParam := fmt.Sprintf("%d", fakeAccountID)
+	fakeDTO, err := domain.NewStatementDTO(fakeAccountID, currency.RUB, "2022-11-01", "2022-12-01")
+	require.NoError(t, err)
+	fakeQueryParams := map[string]string{
+		"from":   "2022-11-01",
+		"to":     "2022-12-01",
+		"format": "jsonl",
+	}
+
+	setupGin := func(c *gin.Context, param string, queryParams map[string]string) {
+		c.Request.Method = http.MethodGet
+		c.Params = gin.Params{{Key: "account_id", Value: param}}
+
+		query := url.Values{}
+		for k, v := range queryParams {
+			query.Add(k, v)
+		}
+		c.Request.URL.RawQuery = query.Encode()
+	}
+
+	type mockBehaviour func(service *MockService)
+
+	tests := []struct {
+		name                string
+		mock                mockBehaviour
+		param               string
+		queryParams         map[string]string
+		wantedErrorResponse *handler.ErrorResponse
+		wantedBody          string
+		statusCode          int
+	}{
+		{
+			name:  "invalid account_id param",
+			mock:  func(service *MockService) {},
+			param: "invalid",
+			wantedErrorResponse: &handler.ErrorResponse{
+				Message: "Statement not found. id is not valid",
+			},
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name:        "invalid format param",
+			mock:        func(service *MockService) {},
+			param:       fakeParam,
+			queryParams: map[string]string{"format": "xlsx"},
+			wantedErrorResponse: &handler.ErrorResponse{
+				Message: "Statement not found. Format param is not valid",
+			},
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name:        "invalid date range",
+			mock:        func(service *MockService) {},
+			param:       fakeParam,
+			queryParams: map[string]string{"from": "2022-12-01", "to": "2022-11-01"},
+			wantedErrorResponse: &handler.ErrorResponse{
+				Message: "Statement not found. From must be before to",
+			},
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name: "service error before streaming",
+			mock: func(service *MockService) {
+				service.EXPECT().
+					WriteStatement(ctx, fakeDTO, domain.JSONLines, gomock.Any()).
+					Return(errors.New("transaction service error"))
+			},
+			param:       fakeParam,
+			queryParams: fakeQueryParams,
+			wantedErrorResponse: &handler.ErrorResponse{
+				Message: "Get statement error",
+			},
+			statusCode: http.StatusInternalServerError,
+		},
+		{
+			name: "success get statement",
+			mock: func(service *MockService) {
+				service.EXPECT().
+					WriteStatement(ctx, fakeDTO, domain.JSONLines, gomock.Any()).
+					DoAndReturn(func(_ context.Context, _ domain.StatementDTO, _ domain.StatementFormat, w io.Writer) error {
+						_, err := io.WriteString(w, "{\"record\":\"opening_balance\"}\n")
+						return err
+					})
+			},
+			param:       fakeParam,
+			queryParams: fakeQueryParams,
+			wantedBody:  "{\"record\":\"opening_balance\"}\n",
+			statusCode:  http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			transactionHandler, transactionService, c := mockHandler(t, w)
+
+			setupGin(c, tt.param, tt.queryParams)
+			tt.mock(transactionService)
+
+			transactionHandler.GetStatement(c)
+
+			require.Equal(t, tt.statusCode, w.Code)
+			if tt.wantedErrorResponse != nil {
+				var response handler.ErrorResponse
+				require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+				require.True(t, reflect.DeepEqual(tt.wantedErrorResponse, &response))
+				return
+			}
+
+			require.Equal(t, "application/jsonl", w.Header().Get("Content-Type"))
+			require.Equal(t, "attachment; filename=statement_1.jsonl", w.Header().Get("Content-Disposition"))
+			require.Equal(t, tt.wantedBody, w.Body.String())
+		})
+	}
+}
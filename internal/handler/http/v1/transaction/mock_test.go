@@ -6,6 +6,7 @@ package transaction_test
 
 import (
 	context "context"
+	io "io"
 	reflect "reflect"
 
 	gomock "github.com/golang/mock/gomock"
@@ -36,17 +37,30 @@ func (m *MockService) EXPECT() *MockServiceMockRecorder {
 }
 
 // GetTransactionsByAccountID mocks base method.
-func (m *MockService) GetTransactionsByAccountID(ctx context.Context, senderID int64, listParams transaction.ListParams) ([]transaction.Transaction, int, error) {
+func (m *MockService) GetTransactionsByAccountID(ctx context.Context, accountID int64, listParams transaction.ListParams) (transaction.List, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetTransactionsByAccountID", ctx, senderID, listParams)
-	ret0, _ := ret[0].([]transaction.Transaction)
-	ret1, _ := ret[1].(int)
-	ret2, _ := ret[2].(error)
-	return ret0, ret1, ret2
+	ret := m.ctrl.Call(m, "GetTransactionsByAccountID", ctx, accountID, listParams)
+	ret0, _ := ret[0].(transaction.List)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
 // GetTransactionsByAccountID indicates an expected call of GetTransactionsByAccountID.
-func (mr *MockServiceMockRecorder) GetTransactionsByAccountID(ctx, senderID, listParams interface{}) *gomock.Call {
+func (mr *MockServiceMockRecorder) GetTransactionsByAccountID(ctx, accountID, listParams interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTransactionsByAccountID", reflect.TypeOf((*MockService)(nil).GetTransactionsByAccountID), ctx, senderID, listParams)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTransactionsByAccountID", reflect.TypeOf((*MockService)(nil).GetTransactionsByAccountID), ctx, accountID, listParams)
+}
+
+// WriteStatement mocks base method.
+func (m *MockService) WriteStatement(ctx context.Context, dto transaction.StatementDTO, format transaction.StatementFormat, w io.Writer) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WriteStatement", ctx, dto, format, w)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// WriteStatement indicates an expected call of WriteStatement.
+func (mr *MockServiceMockRecorder) WriteStatement(ctx, dto, format, w interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WriteStatement", reflect.TypeOf((*MockService)(nil).WriteStatement), ctx, dto, format, w)
 }
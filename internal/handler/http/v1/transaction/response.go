@@ -1,32 +1,57 @@
 package transaction
 
 import (
+	"fmt"
+	"net/http"
 	"time"
 
+	"github.com/gin-gonic/gin"
+
 	"github.com/maypok86/payment-api/internal/domain/transaction"
 	"github.com/maypok86/payment-api/internal/pkg/pagination"
 )
 
+type ConversionResponse struct {
+	Rate              string `json:"rate"`
+	Amount            int64  `json:"amount"`
+	Currency          string `json:"currency"`
+	RoundingRemainder string `json:"rounding_remainder"`
+}
+
 type Response struct {
-	TransactionID int64     `json:"transaction_id"`
-	Type          string    `json:"type"`
-	SenderID      int64     `json:"sender_id"`
-	ReceiverID    int64     `json:"receiver_id"`
-	Amount        int64     `json:"amount"`
-	Description   string    `json:"description"`
-	CreatedAt     time.Time `json:"created_at"`
+	TransactionID int64               `json:"transaction_id"`
+	Type          string              `json:"type"`
+	SenderID      int64               `json:"sender_id"`
+	ReceiverID    int64               `json:"receiver_id"`
+	Amount        int64               `json:"amount"`
+	Currency      string              `json:"currency"`
+	Conversion    *ConversionResponse `json:"conversion,omitempty"`
+	Description   string              `json:"description"`
+	CreatedAt     time.Time           `json:"created_at"`
 }
 
 func NewResponse(transaction transaction.Transaction) Response {
-	return Response{
+	response := Response{
 		TransactionID: transaction.TransactionID,
 		Type:          transaction.Type.String(),
 		SenderID:      transaction.SenderID,
 		ReceiverID:    transaction.ReceiverID,
 		Amount:        transaction.Amount,
+		Currency:      transaction.Currency.String(),
 		Description:   transaction.Description,
 		CreatedAt:     transaction.CreatedAt,
 	}
+
+	if transaction.Conversion != nil {
+		response.Conversion = &ConversionResponse{
+			Rate:              transaction.Conversion.Rate,
+			Amount:            transaction.Conversion.Amount,
+			Currency:          transaction.Conversion.Currency.String(),
+			RoundingRemainder: transaction.Conversion.Remainder,
+		}
+	}
+
+	return response
 }
 
 type ListResponse struct {
@@ -34,19 +59,46 @@ type ListResponse struct {
 	Range        pagination.ListRange `json:"range"`
 }
 
-func NewListResponse(
-	transactions []transaction.Transaction,
-	params pagination.Params,
-	count int,
-) ListResponse {
-	responses := make([]Response, 0, len(transactions))
+func NewListResponse(list transaction.List, params pagination.Params) ListResponse {
+	responses := make([]Response, 0, len(list.Transactions))
 
-	for _, tr := range transactions {
+	for _, tr := range list.Transactions {
 		responses = append(responses, NewResponse(tr))
 	}
 
 	return ListResponse{
 		Transactions: responses,
-		Range:        pagination.NewListRange(params, count),
+		Range:        pagination.NewCursorRange(params, list.Count, list.Next, list.Prev),
 	}
 }
+
+// statementWriter sends the headers of the statement with its first bytes, so that an error which happens
+// before them can still be sent as a JSON error.
+type statementWriter struct {
+	c         *gin.Context
+	accountID int64
+	format    transaction.StatementFormat
+	started   bool
+}
+
+func newStatementWriter(c *gin.Context, accountID int64, format transaction.StatementFormat) *statementWriter {
+	return &statementWriter{
+		c:         c,
+		accountID: accountID,
+		format:    format,
+	}
+}
+
+func (sw *statementWriter) Write(p []byte) (int, error) {
+	if !sw.started {
+		sw.started = true
+		sw.c.Header("Content-Type", sw.format.ContentType())
+		sw.c.Header(
+			"Content-Disposition",
+			fmt.Sprintf("attachment; filename=statement_%d.%s", sw.accountID, sw.format),
+		)
+		sw.c.Status(http.StatusOK)
+	}
+
+	return sw.c.Writer.Write(p)
+}
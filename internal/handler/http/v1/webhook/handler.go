@@ -0,0 +1,188 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/maypok86/payment-api/internal/domain/outbox"
+	"github.com/maypok86/payment-api/internal/domain/webhook"
+	"github.com/maypok86/payment-api/internal/pkg/handler"
+	"go.uber.org/zap"
+)
+
+//go:generate mockgen -source=handler.go -destination=mock_test.go -package=webhook_test
+
+type Service interface {
+	CreateSubscription(ctx context.Context, dto webhook.CreateSubscriptionDTO) (webhook.Subscription, error)
+	GetSubscriptions(ctx context.Context) ([]webhook.Subscription, error)
+	GetSubscriptionByID(ctx context.Context, subscriptionID int64) (webhook.Subscription, error)
+	DeleteSubscription(ctx context.Context, subscriptionID int64) error
+	GetDeliveries(
+		ctx context.Context,
+		subscriptionID int64,
+		listParams webhook.DeliveryListParams,
+	) ([]webhook.Delivery, int, error)
+}
+
+type Handler struct {
+	*handler.BaseHandler
+	service Service
+	logger  *zap.Logger
+}
+
+func NewHandler(service Service, logger *zap.Logger) *Handler {
+	return &Handler{
+		BaseHandler: handler.NewBaseHandler(logger),
+		service:     service,
+		logger:      logger,
+	}
+}
+
+func (h *Handler) InitAPI(router *gin.RouterGroup) {
+	webhooksGroup := router.Group("/webhooks")
+	{
+		webhooksGroup.POST("", h.CreateWebhook)
+		webhooksGroup.GET("", h.GetWebhooks)
+		webhooksGroup.GET("/:webhook_id", h.GetWebhook)
+		webhooksGroup.DELETE("/:webhook_id", h.DeleteWebhook)
+		webhooksGroup.GET("/:webhook_id/deliveries", h.GetDeliveries)
+	}
+}
+
+func (h *Handler) CreateWebhook(c *gin.Context) {
+	var request CreateRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		h.ErrorResponse(c, http.StatusBadRequest, err, "Create webhook error. Invalid request")
+		return
+	}
+
+	dto, err := request.ToDTO()
+	if err != nil {
+		if errors.Is(err, webhook.ErrInvalidOwner) {
+			h.ErrorResponse(
+				c,
+				http.StatusBadRequest,
+				err,
+				"Create webhook error. Exactly one of account_id and service_id is required",
+			)
+			return
+		}
+
+		if errors.Is(err, webhook.ErrInvalidURL) {
+			h.ErrorResponse(c, http.StatusBadRequest, err, "Create webhook error. Url is not valid")
+			return
+		}
+
+		if errors.Is(err, webhook.ErrPrivateURL) {
+			h.ErrorResponse(c, http.StatusBadRequest, err, "Create webhook error. Url points to a private address")
+			return
+		}
+
+		if errors.Is(err, outbox.ErrInvalidType) {
+			h.ErrorResponse(c, http.StatusBadRequest, err, "Create webhook error. Event type is not valid")
+			return
+		}
+
+		h.ErrorResponse(c, http.StatusBadRequest, err, "Create webhook error. Invalid request")
+		return
+	}
+
+	subscription, err := h.service.CreateSubscription(c.Request.Context(), dto)
+	if err != nil {
+		if errors.Is(err, webhook.ErrOwnerNotFound) {
+			h.ErrorResponse(c, http.StatusNotFound, err, "Create webhook error. Owner not found")
+			return
+		}
+
+		h.ErrorResponse(c, http.StatusInternalServerError, err, "Create webhook error")
+		return
+	}
+
+	c.JSON(http.StatusOK, NewCreateResponse(subscription))
+}
+
+func (h *Handler) GetWebhooks(c *gin.Context) {
+	subscriptions, err := h.service.GetSubscriptions(c.Request.Context())
+	if err != nil {
+		h.ErrorResponse(c, http.StatusInternalServerError, err, "Get webhooks error")
+		return
+	}
+
+	c.JSON(http.StatusOK, NewListResponse(subscriptions))
+}
+
+func (h *Handler) GetWebhook(c *gin.Context) {
+	subscriptionID, err := h.ParseIDFromPath(c, "webhook_id")
+	if err != nil {
+		h.ErrorResponse(c, http.StatusBadRequest, err, "Webhook not found. id is not valid")
+		return
+	}
+
+	subscription, err := h.service.GetSubscriptionByID(c.Request.Context(), subscriptionID)
+	if err != nil {
+		if errors.Is(err, webhook.ErrNotFound) {
+			h.ErrorResponse(c, http.StatusNotFound, err, "Webhook not found")
+			return
+		}
+
+		h.ErrorResponse(c, http.StatusInternalServerError, err, "Get webhook error")
+		return
+	}
+
+	c.JSON(http.StatusOK, NewResponse(subscription))
+}
+
+func (h *Handler) DeleteWebhook(c *gin.Context) {
+	subscriptionID, err := h.ParseIDFromPath(c, "webhook_id")
+	if err != nil {
+		h.ErrorResponse(c, http.StatusBadRequest, err, "Webhook not found. id is not valid")
+		return
+	}
+
+	if err := h.service.DeleteSubscription(c.Request.Context(), subscriptionID); err != nil {
+		if errors.Is(err, webhook.ErrNotFound) {
+			h.ErrorResponse(c, http.StatusNotFound, err, "Webhook not found")
+			return
+		}
+
+		h.ErrorResponse(c, http.StatusInternalServerError, err, "Delete webhook error")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (h *Handler) GetDeliveries(c *gin.Context) {
+	subscriptionID, err := h.ParseIDFromPath(c, "webhook_id")
+	if err != nil {
+		h.ErrorResponse(c, http.StatusBadRequest, err, "Webhook not found. id is not valid")
+		return
+	}
+
+	params, err := h.ParsePaginationParams(c)
+	if err != nil {
+		h.ErrorResponse(c, http.StatusBadRequest, err, "Deliveries not found. Pagination params is not valid")
+		return
+	}
+
+	listParams, err := webhook.NewDeliveryListParams(c.Query("status"), params)
+	if err != nil {
+		h.ErrorResponse(c, http.StatusBadRequest, err, "Deliveries not found. Status param is not valid")
+		return
+	}
+
+	deliveries, count, err := h.service.GetDeliveries(c.Request.Context(), subscriptionID, listParams)
+	if err != nil {
+		if errors.Is(err, webhook.ErrNotFound) {
+			h.ErrorResponse(c, http.StatusNotFound, err, "Webhook not found")
+			return
+		}
+
+		h.ErrorResponse(c, http.StatusInternalServerError, err, "Get deliveries error")
+		return
+	}
+
+	c.JSON(http.StatusOK, NewDeliveryListResponse(deliveries, params, count))
+}
@@ -0,0 +1,555 @@
+package webhook_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/maypok86/payment-api/internal/domain/outbox"
+	domain "github.com/maypok86/payment-api/internal/domain/webhook"
+	"github.com/maypok86/payment-api/internal/handler/http/v1/webhook"
+	"github.com/maypok86/payment-api/internal/pkg/handler"
+	"github.com/maypok86/payment-api/internal/pkg/logger"
+	"github.com/maypok86/payment-api/internal/pkg/pagination"
+	"github.com/stretchr/testify/require"
+)
+
+func mockHandler(t *testing.T, w http.ResponseWriter) (*webhook.Handler, *MockService, *gin.Context) {
+	t.Helper()
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	gin.SetMode(gin.TestMode)
+
+	c, r := gin.CreateTestContext(w)
+	c.Request = &http.Request{
+		Header: make(http.Header),
+		URL:    &url.URL{},
+	}
+
+	l := logger.New(os.Stdout, "debug")
+
+	webhookService := NewMockService(mockCtrl)
+	webhookHandler := webhook.NewHandler(webhookService, l)
+
+	webhookHandler.InitAPI(r.Group("/"))
+
+	return webhookHandler, webhookService, c
+}
+
+// for fix time.Time in json.
+func reencode(t *testing.T, from, to interface{}) {
+	t.Helper()
+
+	var buffer bytes.Buffer
+	require.NoError(t, json.NewEncoder(&buffer).Encode(from))
+	require.NoError(t, json.NewDecoder(&buffer).Decode(to))
+}
+
+var fakeSubscription = domain.Subscription{
+	SubscriptionID: 1,
+	AccountID:      1,
+	URL:            "https://partner.example.com/hooks",
+	Secret:         "secret",
+	EventTypes:     []outbox.Type{outbox.OrderPaid, outbox.OrderCancelled},
+	CreatedAt:      time.Now(),
+}
+
+func TestHandler_CreateWebhook(t *testing.T) {
+	ctx := context.Background()
+
+	fakeDTO := domain.CreateSubscriptionDTO{
+		AccountID:  fakeSubscription.AccountID,
+		URL:        fakeSubscription.URL,
+		Secret:     fakeSubscription.Secret,
+		EventTypes: fakeSubscription.EventTypes,
+	}
+	fakeRequest := webhook.CreateRequest{
+		AccountID:  fakeSubscription.AccountID,
+		URL:        fakeSubscription.URL,
+		Secret:     fakeSubscription.Secret,
+		EventTypes: []string{"order_paid", "order_cancelled"},
+	}
+	var fakeResponse webhook.CreateResponse
+	reencode(t, webhook.NewCreateResponse(fakeSubscription), &fakeResponse)
+
+	webhookServiceErr := errors.New("webhook service error")
+
+	setupGin := func(c *gin.Context, content interface{}) {
+		c.Request.Method = http.MethodPost
+		c.Request.Header.Set("Content-Type", "application/json")
+
+		data, err := json.Marshal(content)
+		require.NoError(t, err)
+
+		c.Request.Body = io.NopCloser(bytes.NewBuffer(data))
+	}
+
+	type mockBehaviour func(service *MockService)
+
+	tests := []struct {
+		name                string
+		mock                mockBehaviour
+		request             webhook.CreateRequest
+		response            webhook.CreateResponse
+		wantedErrorResponse *handler.ErrorResponse
+		statusCode          int
+	}{
+		{
+			name: "invalid request",
+			mock: func(service *MockService) {
+			},
+			request: webhook.CreateRequest{
+				URL: fakeSubscription.URL,
+			},
+			wantedErrorResponse: &handler.ErrorResponse{
+				Message: "Create webhook error. Invalid request",
+			},
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name: "no owner",
+			mock: func(service *MockService) {
+			},
+			request: webhook.CreateRequest{
+				URL:        fakeSubscription.URL,
+				EventTypes: fakeRequest.EventTypes,
+			},
+			wantedErrorResponse: &handler.ErrorResponse{
+				Message: "Create webhook error. Exactly one of account_id and service_id is required",
+			},
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name: "invalid url",
+			mock: func(service *MockService) {
+			},
+			request: webhook.CreateRequest{
+				AccountID:  fakeRequest.AccountID,
+				URL:        "partner.example.com",
+				EventTypes: fakeRequest.EventTypes,
+			},
+			wantedErrorResponse: &handler.ErrorResponse{
+				Message: "Create webhook error. Url is not valid",
+			},
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name: "private url",
+			mock: func(service *MockService) {
+			},
+			request: webhook.CreateRequest{
+				AccountID:  fakeRequest.AccountID,
+				URL:        "http://169.254.169.254/latest/meta-data",
+				EventTypes: fakeRequest.EventTypes,
+			},
+			wantedErrorResponse: &handler.ErrorResponse{
+				Message: "Create webhook error. Url points to a private address",
+			},
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name: "invalid event type",
+			mock: func(service *MockService) {
+			},
+			request: webhook.CreateRequest{
+				AccountID:  fakeRequest.AccountID,
+				URL:        fakeSubscription.URL,
+				EventTypes: []string{"order_lost"},
+			},
+			wantedErrorResponse: &handler.ErrorResponse{
+				Message: "Create webhook error. Event type is not valid",
+			},
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name: "owner not found",
+			mock: func(service *MockService) {
+				service.EXPECT().CreateSubscription(ctx, fakeDTO).Return(domain.Subscription{}, domain.ErrOwnerNotFound)
+			},
+			request: fakeRequest,
+			wantedErrorResponse: &handler.ErrorResponse{
+				Message: "Create webhook error. Owner not found",
+			},
+			statusCode: http.StatusNotFound,
+		},
+		{
+			name: "webhook service error",
+			mock: func(service *MockService) {
+				service.EXPECT().CreateSubscription(ctx, fakeDTO).Return(domain.Subscription{}, webhookServiceErr)
+			},
+			request: fakeRequest,
+			wantedErrorResponse: &handler.ErrorResponse{
+				Message: "Create webhook error",
+			},
+			statusCode: http.StatusInternalServerError,
+		},
+		{
+			name: "success create webhook",
+			mock: func(service *MockService) {
+				service.EXPECT().CreateSubscription(ctx, fakeDTO).Return(fakeSubscription, nil)
+			},
+			request:    fakeRequest,
+			response:   fakeResponse,
+			statusCode: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			webhookHandler, webhookService, c := mockHandler(t, w)
+
+			setupGin(c, tt.request)
+			tt.mock(webhookService)
+
+			webhookHandler.CreateWebhook(c)
+
+			require.Equal(t, tt.statusCode, w.Code)
+			if tt.wantedErrorResponse != nil {
+				var response handler.ErrorResponse
+				require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+				require.True(t, reflect.DeepEqual(tt.wantedErrorResponse, &response))
+			} else {
+				var response webhook.CreateResponse
+				require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+				require.True(t, reflect.DeepEqual(tt.response, response))
+			}
+		})
+	}
+}
+
+func TestHandler_GetWebhook(t *testing.T) {
+	ctx := context.Background()
+
+	var fakeResponse webhook.Response
+	reencode(t, webhook.NewResponse(fakeSubscription), &fakeResponse)
+
+	webhookServiceErr := errors.New("webhook service error")
+
+	setupGin := func(c *gin.Context, param string) {
+		c.Request.Method = http.MethodGet
+		c.Params = gin.Params{{Key: "webhook_id", Value: param}}
+	}
+
+	type mockBehaviour func(service *MockService)
+
+	tests := []struct {
+		name                string
+		mock                mockBehaviour
+		param               string
+		response            webhook.Response
+		wantedErrorResponse *handler.ErrorResponse
+		statusCode          int
+	}{
+		{
+			name: "invalid webhook_id param",
+			mock: func(service *MockService) {
+			},
+			param: "invalid",
+			wantedErrorResponse: &handler.ErrorResponse{
+				Message: "Webhook not found. id is not valid",
+			},
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name: "webhook not found",
+			mock: func(service *MockService) {
+				service.EXPECT().GetSubscriptionByID(ctx, int64(1)).Return(domain.Subscription{}, domain.ErrNotFound)
+			},
+			param: "1",
+			wantedErrorResponse: &handler.ErrorResponse{
+				Message: "Webhook not found",
+			},
+			statusCode: http.StatusNotFound,
+		},
+		{
+			name: "webhook service error",
+			mock: func(service *MockService) {
+				service.EXPECT().GetSubscriptionByID(ctx, int64(1)).Return(domain.Subscription{}, webhookServiceErr)
+			},
+			param: "1",
+			wantedErrorResponse: &handler.ErrorResponse{
+				Message: "Get webhook error",
+			},
+			statusCode: http.StatusInternalServerError,
+		},
+		{
+			name: "success get webhook",
+			mock: func(service *MockService) {
+				service.EXPECT().GetSubscriptionByID(ctx, int64(1)).Return(fakeSubscription, nil)
+			},
+			param:      "1",
+			response:   fakeResponse,
+			statusCode: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			webhookHandler, webhookService, c := mockHandler(t, w)
+
+			setupGin(c, tt.param)
+			tt.mock(webhookService)
+
+			webhookHandler.GetWebhook(c)
+
+			require.Equal(t, tt.statusCode, w.Code)
+			if tt.wantedErrorResponse != nil {
+				var response handler.ErrorResponse
+				require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+				require.True(t, reflect.DeepEqual(tt.wantedErrorResponse, &response))
+			} else {
+				var response webhook.Response
+				require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+				require.True(t, reflect.DeepEqual(tt.response, response))
+				require.NotContains(t, w.Body.String(), "secret")
+			}
+		})
+	}
+}
+
+func TestHandler_DeleteWebhook(t *testing.T) {
+	ctx := context.Background()
+
+	webhookServiceErr := errors.New("webhook service error")
+
+	setupGin := func(c *gin.Context, param string) {
+		c.Request.Method = http.MethodDelete
+		c.Params = gin.Params{{Key: "webhook_id", Value: param}}
+	}
+
+	type mockBehaviour func(service *MockService)
+
+	tests := []struct {
+		name                string
+		mock                mockBehaviour
+		param               string
+		wantedErrorResponse *handler.ErrorResponse
+		statusCode          int
+	}{
+		{
+			name: "invalid webhook_id param",
+			mock: func(service *MockService) {
+			},
+			param: "invalid",
+			wantedErrorResponse: &handler.ErrorResponse{
+				Message: "Webhook not found. id is not valid",
+			},
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name: "webhook not found",
+			mock: func(service *MockService) {
+				service.EXPECT().DeleteSubscription(ctx, int64(1)).Return(domain.ErrNotFound)
+			},
+			param: "1",
+			wantedErrorResponse: &handler.ErrorResponse{
+				Message: "Webhook not found",
+			},
+			statusCode: http.StatusNotFound,
+		},
+		{
+			name: "webhook service error",
+			mock: func(service *MockService) {
+				service.EXPECT().DeleteSubscription(ctx, int64(1)).Return(webhookServiceErr)
+			},
+			param: "1",
+			wantedErrorResponse: &handler.ErrorResponse{
+				Message: "Delete webhook error",
+			},
+			statusCode: http.StatusInternalServerError,
+		},
+		{
+			name: "success delete webhook",
+			mock: func(service *MockService) {
+				service.EXPECT().DeleteSubscription(ctx, int64(1)).Return(nil)
+			},
+			param:      "1",
+			statusCode: http.StatusNoContent,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			webhookHandler, webhookService, c := mockHandler(t, w)
+
+			setupGin(c, tt.param)
+			tt.mock(webhookService)
+
+			webhookHandler.DeleteWebhook(c)
+
+			require.Equal(t, tt.statusCode, c.Writer.Status())
+			if tt.wantedErrorResponse != nil {
+				var response handler.ErrorResponse
+				require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+				require.True(t, reflect.DeepEqual(tt.wantedErrorResponse, &response))
+			} else {
+				require.Zero(t, w.Body.Len())
+			}
+		})
+	}
+}
+
+func TestHandler_GetDeliveries(t *testing.T) {
+	ctx := context.Background()
+
+	fakePaginationParams := pagination.Params{
+		Limit:  10,
+		Offset: 0,
+	}
+	fakeListParams, err := domain.NewDeliveryListParams("dead", fakePaginationParams)
+	require.NoError(t, err)
+	fakeDeliveries := []domain.Delivery{
+		{
+			DeliveryID:     1,
+			SubscriptionID: 1,
+			EventID:        1,
+			EventType:      outbox.OrderPaid,
+			Body:           []byte(`{"event_id":1}`),
+			Status:         domain.Dead,
+			Attempts:       9,
+			LastStatusCode: http.StatusInternalServerError,
+			LastError:      "webhook responded with unexpected status: 500",
+			CreatedAt:      time.Now(),
+			UpdatedAt:      time.Now(),
+		},
+	}
+	var fakeResponse webhook.DeliveryListResponse
+	reencode(t, webhook.NewDeliveryListResponse(fakeDeliveries, fakePaginationParams, 1), &fakeResponse)
+
+	webhookServiceErr := errors.New("webhook service error")
+
+	setupGin := func(c *gin.Context, param string, queryParams map[string]string) {
+		c.Request.Method = http.MethodGet
+		c.Params = gin.Params{{Key: "webhook_id", Value: param}}
+
+		query := url.Values{}
+		for k, v := range queryParams {
+			query.Add(k, v)
+		}
+		c.Request.URL.RawQuery = query.Encode()
+	}
+
+	fakeQueryParams := map[string]string{
+		"limit":  "10",
+		"offset": "0",
+		"status": "dead",
+	}
+
+	type mockBehaviour func(service *MockService)
+
+	tests := []struct {
+		name                string
+		mock                mockBehaviour
+		param               string
+		queryParams         map[string]string
+		response            webhook.DeliveryListResponse
+		wantedErrorResponse *handler.ErrorResponse
+		statusCode          int
+	}{
+		{
+			name: "invalid webhook_id param",
+			mock: func(service *MockService) {
+			},
+			param: "invalid",
+			wantedErrorResponse: &handler.ErrorResponse{
+				Message: "Webhook not found. id is not valid",
+			},
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name: "invalid pagination params",
+			mock: func(service *MockService) {
+			},
+			param:       "1",
+			queryParams: map[string]string{"limit": "invalid"},
+			wantedErrorResponse: &handler.ErrorResponse{
+				Message: "Deliveries not found. Pagination params is not valid",
+			},
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name: "invalid status param",
+			mock: func(service *MockService) {
+			},
+			param:       "1",
+			queryParams: map[string]string{"status": "lost"},
+			wantedErrorResponse: &handler.ErrorResponse{
+				Message: "Deliveries not found. Status param is not valid",
+			},
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name: "webhook not found",
+			mock: func(service *MockService) {
+				service.EXPECT().GetDeliveries(ctx, int64(1), fakeListParams).Return(nil, 0, domain.ErrNotFound)
+			},
+			param:       "1",
+			queryParams: fakeQueryParams,
+			wantedErrorResponse: &handler.ErrorResponse{
+				Message: "Webhook not found",
+			},
+			statusCode: http.StatusNotFound,
+		},
+		{
+			name: "webhook service error",
+			mock: func(service *MockService) {
+				service.EXPECT().GetDeliveries(ctx, int64(1), fakeListParams).Return(nil, 0, webhookServiceErr)
+			},
+			param:       "1",
+			queryParams: fakeQueryParams,
+			wantedErrorResponse: &handler.ErrorResponse{
+				Message: "Get deliveries error",
+			},
+			statusCode: http.StatusInternalServerError,
+		},
+		{
+			name: "success get deliveries",
+			mock: func(service *MockService) {
+				service.EXPECT().GetDeliveries(ctx, int64(1), fakeListParams).Return(fakeDeliveries, 1, nil)
+			},
+			param:       "1",
+			queryParams: fakeQueryParams,
+			response:    fakeResponse,
+			statusCode:  http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			webhookHandler, webhookService, c := mockHandler(t, w)
+
+			setupGin(c, tt.param, tt.queryParams)
+			tt.mock(webhookService)
+
+			webhookHandler.GetDeliveries(c)
+
+			require.Equal(t, tt.statusCode, w.Code)
+			if tt.wantedErrorResponse != nil {
+				var response handler.ErrorResponse
+				require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+				require.True(t, reflect.DeepEqual(tt.wantedErrorResponse, &response))
+			} else {
+				var response webhook.DeliveryListResponse
+				require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+				require.True(t, reflect.DeepEqual(tt.response, response))
+			}
+		})
+	}
+}
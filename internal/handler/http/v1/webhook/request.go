@@ -0,0 +1,16 @@
+package webhook
+
+import "github.com/maypok86/payment-api/internal/domain/webhook"
+
+// CreateRequest belongs to either AccountID or ServiceID.
+type CreateRequest struct {
+	AccountID  int64    `json:"account_id"  binding:"omitempty,gt=0"`
+	ServiceID  int64    `json:"service_id"  binding:"omitempty,gt=0"`
+	URL        string   `json:"url"         binding:"required"`
+	Secret     string   `json:"secret"`
+	EventTypes []string `json:"event_types" binding:"required,min=1"`
+}
+
+func (r CreateRequest) ToDTO() (webhook.CreateSubscriptionDTO, error) {
+	return webhook.NewCreateSubscriptionDTO(r.AccountID, r.ServiceID, r.URL, r.Secret, r.EventTypes)
+}
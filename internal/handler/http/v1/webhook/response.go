@@ -0,0 +1,123 @@
+package webhook
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/maypok86/payment-api/internal/domain/webhook"
+	"github.com/maypok86/payment-api/internal/pkg/pagination"
+)
+
+type Response struct {
+	WebhookID  int64     `json:"webhook_id"`
+	AccountID  int64     `json:"account_id,omitempty"`
+	ServiceID  int64     `json:"service_id,omitempty"`
+	URL        string    `json:"url"`
+	EventTypes []string  `json:"event_types"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+func NewResponse(subscription webhook.Subscription) Response {
+	eventTypes := make([]string, 0, len(subscription.EventTypes))
+	for _, eventType := range subscription.EventTypes {
+		eventTypes = append(eventTypes, eventType.String())
+	}
+
+	return Response{
+		WebhookID:  subscription.SubscriptionID,
+		AccountID:  subscription.AccountID,
+		ServiceID:  subscription.ServiceID,
+		URL:        subscription.URL,
+		EventTypes: eventTypes,
+		CreatedAt:  subscription.CreatedAt,
+	}
+}
+
+// CreateResponse is the only response that contains the secret.
+type CreateResponse struct {
+	Response
+	Secret string `json:"secret"`
+}
+
+func NewCreateResponse(subscription webhook.Subscription) CreateResponse {
+	return CreateResponse{
+		Response: NewResponse(subscription),
+		Secret:   subscription.Secret,
+	}
+}
+
+type ListResponse struct {
+	Webhooks []Response `json:"webhooks"`
+}
+
+func NewListResponse(subscriptions []webhook.Subscription) ListResponse {
+	responses := make([]Response, 0, len(subscriptions))
+
+	for _, subscription := range subscriptions {
+		responses = append(responses, NewResponse(subscription))
+	}
+
+	return ListResponse{
+		Webhooks: responses,
+	}
+}
+
+type DeliveryResponse struct {
+	DeliveryID     int64           `json:"delivery_id"`
+	WebhookID      int64           `json:"webhook_id"`
+	EventID        int64           `json:"event_id"`
+	EventType      string          `json:"event_type"`
+	Body           json.RawMessage `json:"body"`
+	Status         string          `json:"status"`
+	Attempts       int             `json:"attempts"`
+	LastStatusCode int             `json:"last_status_code,omitempty"`
+	LastError      string          `json:"last_error,omitempty"`
+	NextAttemptAt  *time.Time      `json:"next_attempt_at,omitempty"`
+	CreatedAt      time.Time       `json:"created_at"`
+	UpdatedAt      time.Time       `json:"updated_at"`
+}
+
+func NewDeliveryResponse(delivery webhook.Delivery) DeliveryResponse {
+	response := DeliveryResponse{
+		DeliveryID:     delivery.DeliveryID,
+		WebhookID:      delivery.SubscriptionID,
+		EventID:        delivery.EventID,
+		EventType:      delivery.EventType.String(),
+		Body:           delivery.Body,
+		Status:         delivery.Status.String(),
+		Attempts:       delivery.Attempts,
+		LastStatusCode: delivery.LastStatusCode,
+		LastError:      delivery.LastError,
+		CreatedAt:      delivery.CreatedAt,
+		UpdatedAt:      delivery.UpdatedAt,
+	}
+
+	if delivery.Status == webhook.Pending {
+		nextAttemptAt := delivery.NextAttemptAt
+		response.NextAttemptAt = &nextAttemptAt
+	}
+
+	return response
+}
+
+type DeliveryListResponse struct {
+	Deliveries []DeliveryResponse   `json:"deliveries"`
+	Range      pagination.ListRange `json:"range"`
+}
+
+func NewDeliveryListResponse(
+	deliveries []webhook.Delivery,
+	params pagination.Params,
+	count int,
+) DeliveryListResponse {
+	responses := make([]DeliveryResponse, 0, len(deliveries))
+
+	for _, delivery := range deliveries {
+		responses = append(responses, NewDeliveryResponse(delivery))
+	}
+
+	return DeliveryListResponse{
+		Deliveries: responses,
+		Range:      pagination.NewListRange(params, count),
+	}
+}
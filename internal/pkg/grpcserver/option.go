@@ -0,0 +1,15 @@
+package grpcserver
+
+type Option func(*Server)
+
+func WithHost(host string) Option {
+	return func(s *Server) {
+		s.host = host
+	}
+}
+
+func WithPort(port string) Option {
+	return func(s *Server) {
+		s.port = port
+	}
+}
@@ -0,0 +1,68 @@
+package grpcserver
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"time"
+
+	"google.golang.org/grpc"
+)
+
+const (
+	defaultHost string = "localhost"
+	defaultPort string = "9090"
+)
+
+type Server struct {
+	host       string
+	port       string
+	grpcServer *grpc.Server
+}
+
+func New(grpcServer *grpc.Server, opts ...Option) *Server {
+	server := &Server{
+		host:       defaultHost,
+		port:       defaultPort,
+		grpcServer: grpcServer,
+	}
+
+	for _, opt := range opts {
+		opt(server)
+	}
+
+	return server
+}
+
+func (s *Server) Start() error {
+	listener, err := net.Listen("tcp", net.JoinHostPort(s.host, s.port))
+	if err != nil {
+		return fmt.Errorf("listen grpc server: %w", err)
+	}
+
+	if err := s.grpcServer.Serve(listener); err != nil {
+		return fmt.Errorf("start grpc server: %w", err)
+	}
+
+	return nil
+}
+
+// Stop waits for the running calls to finish and closes all connections if they do not finish in shutdownTimeout.
+func (s *Server) Stop(ctx context.Context, shutdownTimeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, shutdownTimeout)
+	defer cancel()
+
+	stopped := make(chan struct{})
+	go func() {
+		s.grpcServer.GracefulStop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+		return nil
+	case <-ctx.Done():
+		s.grpcServer.Stop()
+		return fmt.Errorf("shutdown grpc server: %w", ctx.Err())
+	}
+}
@@ -2,6 +2,7 @@ package handler
 
 import (
 	"errors"
+	"net/http"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
@@ -9,6 +10,13 @@ import (
 	"go.uber.org/zap"
 )
 
+const (
+	// ActorHeader names who makes the request. It is stored in the order status history and report jobs are
+	// bound to it.
+	ActorHeader  = "X-Actor"
+	DefaultActor = "api"
+)
+
 type BaseHandler struct {
 	logger *zap.Logger
 }
@@ -31,6 +39,40 @@ func (bh *BaseHandler) ErrorResponse(c *gin.Context, status int, err error, mess
 	})
 }
 
+type InsufficientFundsResponse struct {
+	Message     string `json:"message"`
+	Available   int64  `json:"available"`
+	CreditLimit int64  `json:"credit_limit"`
+	Currency    string `json:"currency"`
+}
+
+func (bh *BaseHandler) InsufficientFundsResponse(
+	c *gin.Context,
+	err error,
+	message string,
+	available int64,
+	creditLimit int64,
+	currency string,
+) {
+	bh.logger.Error(err.Error())
+
+	c.AbortWithStatusJSON(http.StatusPaymentRequired, InsufficientFundsResponse{
+		Message:     message,
+		Available:   available,
+		CreditLimit: creditLimit,
+		Currency:    currency,
+	})
+}
+
+// Actor returns who makes the request, it is DefaultActor when the X-Actor header is not set.
+func (bh *BaseHandler) Actor(c *gin.Context) string {
+	if actor := c.GetHeader(ActorHeader); actor != "" {
+		return actor
+	}
+
+	return DefaultActor
+}
+
 var (
 	ErrEmptyIDParam       = errors.New("empty id param")
 	ErrInvalidID          = errors.New("invalid id param")
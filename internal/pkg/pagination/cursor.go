@@ -0,0 +1,46 @@
+package pagination
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Cursor is the position of a row in a list sorted by Column and then by id. Clients get it as an opaque string:
+// the next page starts right after the cursor and, if Backward is set, the previous page ends right before it.
+type Cursor struct {
+	Column   string `json:"c"`
+	Value    string `json:"v"`
+	ID       int64  `json:"i"`
+	Backward bool   `json:"b,omitempty"`
+}
+
+func (c Cursor) String() string {
+	data, err := json.Marshal(c)
+	if err != nil {
+		return ""
+	}
+
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func ParseCursor(s string) (Cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return Cursor{}, fmt.Errorf("%w: %v", ErrInvalidCursor, err)
+	}
+
+	var cursor Cursor
+	if err := json.Unmarshal(data, &cursor); err != nil {
+		return Cursor{}, fmt.Errorf("%w: %v", ErrInvalidCursor, err)
+	}
+
+	if cursor.Column == "" || cursor.Value == "" || cursor.ID <= 0 {
+		return Cursor{}, ErrInvalidCursor
+	}
+
+	return cursor, nil
+}
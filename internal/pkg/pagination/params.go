@@ -12,16 +12,37 @@ type Params struct {
 	Offset uint64
 }
 
+// ListRange describes a page of a list. Count is omitted when the total was not counted, the cursors are
+// omitted when there is no page in their direction.
 type ListRange struct {
-	Limit  int `json:"limit"`
-	Offset int `json:"offset"`
-	Count  int `json:"count"`
+	Limit      int    `json:"limit"`
+	Offset     int    `json:"offset"`
+	Count      *int   `json:"count,omitempty"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	PrevCursor string `json:"prev_cursor,omitempty"`
 }
 
 func NewListRange(params Params, count int) ListRange {
 	return ListRange{
+		Limit:  int(params.Limit),
+		Offset: int(params.Offset),
+		Count:  &count,
+	}
+}
+
+// NewCursorRange returns the range of a page with the given cursors, nil cursors and count are omitted.
+func NewCursorRange(params Params, count *int, next, prev *Cursor) ListRange {
+	listRange := ListRange{
 		Limit:  int(params.Limit),
 		Offset: int(params.Offset),
 		Count:  count,
 	}
+	if next != nil {
+		listRange.NextCursor = next.String()
+	}
+	if prev != nil {
+		listRange.PrevCursor = prev.String()
+	}
+
+	return listRange
 }
@@ -0,0 +1,197 @@
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+)
+
+const (
+	pageWidth  = 595
+	pageHeight = 842
+	margin     = 40
+	fontSize   = 8
+	lineHeight = 10
+
+	// MaxLineLength is the number of characters which fit on a line, longer lines are cut.
+	// Courier glyphs are 0.6 of the font size wide.
+	MaxLineLength = (pageWidth - 2*margin) * 10 / (fontSize * 6)
+	linesPerPage  = (pageHeight - 2*margin) / lineHeight
+)
+
+const (
+	catalogObject = iota + 1
+	pagesObject
+	fontObject
+	firstPageObject
+)
+
+// Writer writes a text document in PDF with lines of monospaced text. Only the current page is kept in memory,
+// every page is written out as soon as it is full.
+type Writer struct {
+	w       *countingWriter
+	offsets []int64
+	pages   []int
+	page    bytes.Buffer
+	lines   int
+}
+
+func NewWriter(w io.Writer) (*Writer, error) {
+	pw := &Writer{
+		w:       &countingWriter{w: w},
+		offsets: make([]int64, firstPageObject),
+	}
+
+	if _, err := io.WriteString(pw.w, "%PDF-1.4\n%\xe2\xe3\xcf\xd3\n"); err != nil {
+		return nil, fmt.Errorf("write pdf header: %w", err)
+	}
+
+	if err := pw.writeObject(fontObject, "<< /Type /Font /Subtype /Type1 /BaseFont /Courier >>"); err != nil {
+		return nil, err
+	}
+
+	return pw, nil
+}
+
+// Line adds a line of text. Characters outside of ASCII are replaced with '?'.
+func (pw *Writer) Line(text string) error {
+	if pw.lines == linesPerPage {
+		if err := pw.flushPage(); err != nil {
+			return err
+		}
+	}
+
+	if pw.lines == 0 {
+		fmt.Fprintf(&pw.page, "BT /F1 %d Tf %d TL %d %d Td\n", fontSize, lineHeight, margin, pageHeight-margin)
+	}
+
+	pw.page.WriteString("(")
+	pw.page.WriteString(escape(text))
+	pw.page.WriteString(") '\n")
+	pw.lines++
+
+	return nil
+}
+
+// Close writes the last page and the document trailer, it does not close the underlying writer.
+func (pw *Writer) Close() error {
+	if pw.lines > 0 || len(pw.pages) == 0 {
+		if err := pw.flushPage(); err != nil {
+			return err
+		}
+	}
+
+	kids := make([]string, 0, len(pw.pages))
+	for _, page := range pw.pages {
+		kids = append(kids, fmt.Sprintf("%d 0 R", page))
+	}
+
+	if err := pw.writeObject(pagesObject, fmt.Sprintf(
+		"<< /Type /Pages /Kids [%s] /Count %d >>",
+		strings.Join(kids, " "),
+		len(pw.pages),
+	)); err != nil {
+		return err
+	}
+
+	if err := pw.writeObject(catalogObject, fmt.Sprintf("<< /Type /Catalog /Pages %d 0 R >>", pagesObject)); err != nil {
+		return err
+	}
+
+	xref := pw.w.n
+	var trailer bytes.Buffer
+	fmt.Fprintf(&trailer, "xref\n0 %d\n0000000000 65535 f \n", len(pw.offsets))
+	for _, offset := range pw.offsets[1:] {
+		fmt.Fprintf(&trailer, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&trailer, "trailer\n<< /Size %d /Root %d 0 R >>\nstartxref\n%d\n%%%%EOF\n",
+		len(pw.offsets), catalogObject, xref)
+
+	if _, err := pw.w.Write(trailer.Bytes()); err != nil {
+		return fmt.Errorf("write pdf trailer: %w", err)
+	}
+
+	return nil
+}
+
+func (pw *Writer) flushPage() error {
+	if pw.lines > 0 {
+		pw.page.WriteString("ET\n")
+	}
+
+	content := len(pw.offsets)
+	pw.offsets = append(pw.offsets, 0, 0)
+
+	if err := pw.writeObject(content, fmt.Sprintf(
+		"<< /Length %d >>\nstream\n%sendstream",
+		pw.page.Len(),
+		pw.page.String(),
+	)); err != nil {
+		return err
+	}
+
+	if err := pw.writeObject(content+1, fmt.Sprintf(
+		"<< /Type /Page /Parent %d 0 R /MediaBox [0 0 %d %d] "+
+			"/Resources << /Font << /F1 %d 0 R >> >> /Contents %d 0 R >>",
+		pagesObject,
+		pageWidth,
+		pageHeight,
+		fontObject,
+		content,
+	)); err != nil {
+		return err
+	}
+
+	pw.pages = append(pw.pages, content+1)
+	pw.page.Reset()
+	pw.lines = 0
+
+	return nil
+}
+
+func (pw *Writer) writeObject(number int, body string) error {
+	pw.offsets[number] = pw.w.n
+
+	if _, err := fmt.Fprintf(pw.w, "%d 0 obj\n%s\nendobj\n", number, body); err != nil {
+		return fmt.Errorf("write pdf object %d: %w", number, err)
+	}
+
+	return nil
+}
+
+func escape(text string) string {
+	var builder strings.Builder
+
+	length := 0
+	for _, r := range text {
+		if length == MaxLineLength {
+			break
+		}
+		length++
+
+		switch {
+		case r == '\\' || r == '(' || r == ')':
+			builder.WriteRune('\\')
+			builder.WriteRune(r)
+		case r < ' ' || r > '~':
+			builder.WriteRune('?')
+		default:
+			builder.WriteRune(r)
+		}
+	}
+
+	return builder.String()
+}
+
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (cw *countingWriter) Write(p []byte) (int, error) {
+	n, err := cw.w.Write(p)
+	cw.n += int64(n)
+
+	return n, err
+}
@@ -8,6 +8,7 @@ import (
 	"time"
 
 	sq "github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v4"
 	"github.com/jackc/pgx/v4/pgxpool"
 )
 
@@ -15,6 +16,10 @@ const (
 	defaultMaxPoolSize  int           = 4
 	defaultConnAttempts int           = 10
 	defaultConnTimeout  time.Duration = time.Second
+	defaultTxIsoLevel                 = pgx.ReadCommitted
+	defaultTxAttempts   int           = 3
+	defaultTxBackoff    time.Duration = 10 * time.Millisecond
+	defaultTxMaxBackoff time.Duration = 200 * time.Millisecond
 )
 
 var ErrUnableToConnect = errors.New("all attempts are exceeded. Unable to connect to instance")
@@ -23,6 +28,11 @@ type Client struct {
 	maxPoolSize  int
 	connAttempts int
 	connTimeout  time.Duration
+	txIsoLevel   pgx.TxIsoLevel
+	txAttempts   int
+	txBackoff    time.Duration
+	txMaxBackoff time.Duration
+	beginner     txBeginner
 	Builder      sq.StatementBuilderType
 	Pool         *pgxpool.Pool
 }
@@ -32,6 +42,10 @@ func NewClient(ctx context.Context, config ConnectionConfig, opts ...Option) (*C
 		connAttempts: defaultConnAttempts,
 		connTimeout:  defaultConnTimeout,
 		maxPoolSize:  defaultMaxPoolSize,
+		txIsoLevel:   defaultTxIsoLevel,
+		txAttempts:   defaultTxAttempts,
+		txBackoff:    defaultTxBackoff,
+		txMaxBackoff: defaultTxMaxBackoff,
 	}
 
 	for _, opt := range opts {
@@ -63,6 +77,7 @@ func NewClient(ctx context.Context, config ConnectionConfig, opts ...Option) (*C
 	if err != nil {
 		return nil, ErrUnableToConnect
 	}
+	instance.beginner = instance.Pool
 
 	return instance, nil
 }
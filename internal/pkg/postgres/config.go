@@ -1,6 +1,14 @@
 package postgres
 
-import "fmt"
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v4"
+)
+
+var ErrInvalidIsoLevel = errors.New("invalid transaction isolation level")
 
 type ConnectionConfig struct {
 	host     string
@@ -33,3 +41,13 @@ func (cc ConnectionConfig) getDSN() string {
 		cc.sslmode,
 	)
 }
+
+// ParseIsoLevel parses an isolation level as it is written in SQL, e.g. "repeatable read".
+func ParseIsoLevel(s string) (pgx.TxIsoLevel, error) {
+	switch isoLevel := pgx.TxIsoLevel(strings.ToLower(s)); isoLevel {
+	case pgx.Serializable, pgx.RepeatableRead, pgx.ReadCommitted, pgx.ReadUncommitted:
+		return isoLevel, nil
+	}
+
+	return "", fmt.Errorf("%w: %q", ErrInvalidIsoLevel, s)
+}
@@ -0,0 +1,33 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v4"
+)
+
+// NewTxClient returns a client that starts its top-level transactions with begin instead of a pool.
+func NewTxClient(
+	begin func(f func(pgx.Tx) error) error,
+	opts ...Option,
+) *Client {
+	client := &Client{
+		txIsoLevel:   defaultTxIsoLevel,
+		txAttempts:   defaultTxAttempts,
+		txBackoff:    defaultTxBackoff,
+		txMaxBackoff: defaultTxMaxBackoff,
+		beginner:     beginnerFunc(begin),
+	}
+
+	for _, opt := range opts {
+		opt(client)
+	}
+
+	return client
+}
+
+type beginnerFunc func(f func(pgx.Tx) error) error
+
+func (b beginnerFunc) BeginTxFunc(_ context.Context, _ pgx.TxOptions, f func(pgx.Tx) error) error {
+	return b(f)
+}
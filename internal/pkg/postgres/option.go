@@ -1,6 +1,10 @@
 package postgres
 
-import "time"
+import (
+	"time"
+
+	"github.com/jackc/pgx/v4"
+)
 
 type Option func(*Client)
 
@@ -21,3 +25,26 @@ func WithConnTimeout(connTimeout time.Duration) Option {
 		c.connTimeout = connTimeout
 	}
 }
+
+func WithTxIsoLevel(isoLevel pgx.TxIsoLevel) Option {
+	return func(c *Client) {
+		c.txIsoLevel = isoLevel
+	}
+}
+
+// WithTxAttempts sets how many times a transaction is run before a serialization failure or a deadlock
+// is returned to the caller.
+func WithTxAttempts(txAttempts int) Option {
+	return func(c *Client) {
+		c.txAttempts = txAttempts
+	}
+}
+
+// WithTxBackoff sets the delay before the first retry of a transaction. The delay doubles with every retry
+// up to maxBackoff.
+func WithTxBackoff(backoff, maxBackoff time.Duration) Option {
+	return func(c *Client) {
+		c.txBackoff = backoff
+		c.txMaxBackoff = maxBackoff
+	}
+}
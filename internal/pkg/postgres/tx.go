@@ -2,50 +2,182 @@ package postgres
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"time"
 
 	"github.com/jackc/pgconn"
+	"github.com/jackc/pgerrcode"
 	"github.com/jackc/pgx/v4"
 )
 
 type txKey struct{}
 
-func injectTx(ctx context.Context, tx pgx.Tx) context.Context {
-	return context.WithValue(ctx, txKey{}, tx)
+// txBeginner starts top-level transactions. It is the pool outside of tests.
+type txBeginner interface {
+	BeginTxFunc(ctx context.Context, txOptions pgx.TxOptions, f func(pgx.Tx) error) error
 }
 
-func extractTx(ctx context.Context) pgx.Tx {
-	if tx, ok := ctx.Value(txKey{}).(pgx.Tx); ok {
-		return tx
+// txState is the transaction carried by a context. Savepoints share the conflict of their top-level
+// transaction, so a serialization failure or a deadlock still retries the transaction when a caller
+// turns the error into something else, for example an HTTP response inside an idempotent request.
+type txState struct {
+	tx       pgx.Tx
+	conflict *error
+}
+
+// record remembers the first error that makes the top-level transaction worth retrying.
+func (s txState) record(err error) {
+	if *s.conflict == nil && isRetryable(err) {
+		*s.conflict = err
 	}
-	return nil
 }
 
+func injectTx(ctx context.Context, state txState) context.Context {
+	return context.WithValue(ctx, txKey{}, state)
+}
+
+func extractTx(ctx context.Context) (txState, bool) {
+	state, ok := ctx.Value(txKey{}).(txState)
+	return state, ok
+}
+
+// WithTx runs txFunc in a transaction with the isolation level of the client. If ctx already carries
+// a transaction, txFunc runs in a savepoint of it, so the outer transaction owns the commit and the retries.
+//
+// A transaction that fails with a serialization failure or a deadlock is rolled back and run again from
+// the start after a growing delay, up to the configured number of attempts. The conflict is detected even
+// if it happened in a savepoint or a statement and txFunc returned another error. txFunc must therefore be
+// safe to run more than once.
 func (c *Client) WithTx(ctx context.Context, txFunc func(ctx context.Context) error) error {
-	return c.Pool.BeginFunc(ctx, func(tx pgx.Tx) error {
-		return txFunc(injectTx(ctx, tx))
-	})
+	if state, ok := extractTx(ctx); ok {
+		err := state.tx.BeginFunc(ctx, func(tx pgx.Tx) error {
+			return txFunc(injectTx(ctx, txState{tx: tx, conflict: state.conflict}))
+		})
+		state.record(err)
+
+		return err
+	}
+
+	txOptions := pgx.TxOptions{IsoLevel: c.txIsoLevel}
+	for attempt := 1; ; attempt++ {
+		var conflict error
+		err := c.beginner.BeginTxFunc(ctx, txOptions, func(tx pgx.Tx) error {
+			return txFunc(injectTx(ctx, txState{tx: tx, conflict: &conflict}))
+		})
+		if err == nil {
+			return nil
+		}
+		if conflict == nil && !isRetryable(err) {
+			return err
+		}
+
+		if attempt >= c.txAttempts {
+			if conflict != nil && !isRetryable(err) {
+				return fmt.Errorf("transaction failed after %d attempts with %s: %w", attempt, conflict, err)
+			}
+
+			return fmt.Errorf("transaction failed after %d attempts: %w", attempt, err)
+		}
+
+		timer := time.NewTimer(c.retryDelay(attempt))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return fmt.Errorf("wait for transaction retry: %w", ctx.Err())
+		case <-timer.C:
+		}
+	}
+}
+
+// isRetryable reports whether the transaction failed only because of concurrent transactions,
+// so running it again may succeed.
+func isRetryable(err error) bool {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return false
+	}
+
+	return pgErr.Code == pgerrcode.SerializationFailure || pgErr.Code == pgerrcode.DeadlockDetected
+}
+
+// retryDelay returns the delay before the next attempt after attempt failed attempts. The delay doubles
+// with every attempt up to txMaxBackoff and is then picked at random between its half and its full value,
+// so that the transactions which conflicted with each other do not retry at the same time again.
+func (c *Client) retryDelay(attempt int) time.Duration {
+	delay := c.txBackoff
+	for i := 1; i < attempt && delay < c.txMaxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > c.txMaxBackoff {
+		delay = c.txMaxBackoff
+	}
+
+	half := int64(delay / 2)
+	if half <= 0 {
+		return delay
+	}
+
+	//nolint:gosec // the jitter does not need a secure source.
+	return time.Duration(half + rand.Int63n(half+1))
 }
 
 func (c *Client) QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row {
-	if tx := extractTx(ctx); tx != nil {
-		return tx.QueryRow(ctx, sql, args...)
+	if state, ok := extractTx(ctx); ok {
+		return trackedRow{Row: state.tx.QueryRow(ctx, sql, args...), state: state}
 	}
 
 	return c.Pool.QueryRow(ctx, sql, args...)
 }
 
 func (c *Client) Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error) {
-	if tx := extractTx(ctx); tx != nil {
-		return tx.Query(ctx, sql, args...)
+	if state, ok := extractTx(ctx); ok {
+		rows, err := state.tx.Query(ctx, sql, args...)
+		if err != nil {
+			state.record(err)
+			return nil, err
+		}
+
+		return trackedRows{Rows: rows, state: state}, nil
 	}
 
 	return c.Pool.Query(ctx, sql, args...)
 }
 
 func (c *Client) Exec(ctx context.Context, sql string, args ...interface{}) (pgconn.CommandTag, error) {
-	if tx := extractTx(ctx); tx != nil {
-		return tx.Exec(ctx, sql, args...)
+	if state, ok := extractTx(ctx); ok {
+		tag, err := state.tx.Exec(ctx, sql, args...)
+		state.record(err)
+
+		return tag, err
 	}
 
 	return c.Pool.Exec(ctx, sql, args...)
 }
+
+// trackedRow records a conflict of a query that returns a single row.
+type trackedRow struct {
+	pgx.Row
+	state txState
+}
+
+func (r trackedRow) Scan(dest ...interface{}) error {
+	err := r.Row.Scan(dest...)
+	r.state.record(err)
+
+	return err
+}
+
+// trackedRows records a conflict that is reported while the rows are read.
+type trackedRows struct {
+	pgx.Rows
+	state txState
+}
+
+func (r trackedRows) Err() error {
+	err := r.Rows.Err()
+	r.state.record(err)
+
+	return err
+}
@@ -0,0 +1,133 @@
+package postgres_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v4"
+	"github.com/maypok86/payment-api/internal/pkg/postgres"
+	"github.com/stretchr/testify/require"
+)
+
+var errHandlerFailed = errors.New("handler failed")
+
+// fakeTx runs savepoints in place and fails the statements with execErr.
+type fakeTx struct {
+	pgx.Tx
+	execErr error
+}
+
+func (ft *fakeTx) BeginFunc(ctx context.Context, f func(pgx.Tx) error) error {
+	return f(ft)
+}
+
+func (ft *fakeTx) Exec(ctx context.Context, sql string, args ...interface{}) (pgconn.CommandTag, error) {
+	return nil, ft.execErr
+}
+
+func newClient(t *testing.T, execErrs ...error) (*postgres.Client, *int) {
+	t.Helper()
+
+	begins := 0
+	client := postgres.NewTxClient(
+		func(f func(pgx.Tx) error) error {
+			tx := &fakeTx{}
+			if begins < len(execErrs) {
+				tx.execErr = execErrs[begins]
+			}
+			begins++
+
+			return f(tx)
+		},
+		postgres.WithTxAttempts(3),
+		postgres.WithTxBackoff(time.Millisecond, time.Millisecond),
+	)
+
+	return client, &begins
+}
+
+func TestClient_WithTx(t *testing.T) {
+	t.Parallel()
+
+	deadlock := &pgconn.PgError{Code: pgerrcode.DeadlockDetected}
+	serializationFailure := &pgconn.PgError{Code: pgerrcode.SerializationFailure}
+	uniqueViolation := &pgconn.PgError{Code: pgerrcode.UniqueViolation}
+
+	tests := []struct {
+		name       string
+		execErrs   []error
+		wantBegins int
+		wantedErr  error
+	}{
+		{
+			name:       "success without conflicts",
+			wantBegins: 1,
+		},
+		{
+			name:       "conflict in savepoint is retried",
+			execErrs:   []error{deadlock},
+			wantBegins: 2,
+		},
+		{
+			name:       "conflicts in savepoint are retried until attempts are over",
+			execErrs:   []error{deadlock, serializationFailure, deadlock},
+			wantBegins: 3,
+			wantedErr:  errHandlerFailed,
+		},
+		{
+			name:       "other errors are not retried",
+			execErrs:   []error{uniqueViolation},
+			wantBegins: 1,
+			wantedErr:  errHandlerFailed,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			client, begins := newClient(t, tt.execErrs...)
+
+			// The outer function stands for an idempotent request: the handler turns the error of the
+			// savepoint into a response and the transaction only sees that the handler failed.
+			err := client.WithTx(context.Background(), func(ctx context.Context) error {
+				if err := client.WithTx(ctx, func(ctx context.Context) error {
+					_, err := client.Exec(ctx, "UPDATE account_balances SET balance = balance + 1")
+					return err
+				}); err != nil {
+					return errHandlerFailed
+				}
+
+				return nil
+			})
+			if tt.wantedErr != nil {
+				require.ErrorIs(t, err, tt.wantedErr)
+			} else {
+				require.NoError(t, err)
+			}
+			require.Equal(t, tt.wantBegins, *begins)
+		})
+	}
+}
+
+func TestClient_WithTxRetriesConflictOfStatement(t *testing.T) {
+	t.Parallel()
+
+	client, begins := newClient(t, &pgconn.PgError{Code: pgerrcode.SerializationFailure})
+
+	err := client.WithTx(context.Background(), func(ctx context.Context) error {
+		if _, err := client.Exec(ctx, "UPDATE orders SET status = 'paid'"); err != nil {
+			return errHandlerFailed
+		}
+
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, 2, *begins)
+}
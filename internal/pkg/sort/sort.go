@@ -31,3 +31,11 @@ func New(column, order string) *Sort {
 func (opt *Sort) UseSelectBuilder(builder sq.SelectBuilder) sq.SelectBuilder {
 	return builder.OrderBy(opt.column + " " + opt.order)
 }
+
+func (opt *Sort) Column() string {
+	return opt.column
+}
+
+func (opt *Sort) IsDesc() bool {
+	return opt.order == Desc
+}
@@ -0,0 +1,49 @@
+package publisher
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/maypok86/payment-api/internal/domain/outbox"
+)
+
+// FilePublisher appends events to a file as JSON lines.
+type FilePublisher struct {
+	path  string
+	mutex sync.Mutex
+}
+
+func NewFilePublisher(path string) *FilePublisher {
+	return &FilePublisher{
+		path: path,
+	}
+}
+
+func (p *FilePublisher) Publish(_ context.Context, event outbox.Event) error {
+	line, err := json.Marshal(outbox.NewMessage(event))
+	if err != nil {
+		return fmt.Errorf("marshal event: %w", err)
+	}
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	file, err := os.OpenFile(p.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("open events file: %w", err)
+	}
+
+	if _, err := file.Write(append(line, '\n')); err != nil {
+		_ = file.Close()
+		return fmt.Errorf("write event: %w", err)
+	}
+
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("close events file: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,32 @@
+package publisher
+
+import (
+	"context"
+
+	"github.com/maypok86/payment-api/internal/domain/outbox"
+	"go.uber.org/zap"
+)
+
+// LogPublisher writes events to the service log. It is used when no other publisher is configured.
+type LogPublisher struct {
+	logger *zap.Logger
+}
+
+func NewLogPublisher(logger *zap.Logger) *LogPublisher {
+	return &LogPublisher{
+		logger: logger,
+	}
+}
+
+func (p *LogPublisher) Publish(_ context.Context, event outbox.Event) error {
+	p.logger.Info(
+		"event published",
+		zap.Int64("event_id", event.EventID),
+		zap.String("type", event.Type.String()),
+		zap.String("aggregate_type", event.AggregateType),
+		zap.Int64("aggregate_id", event.AggregateID),
+		zap.ByteString("payload", event.Payload),
+	)
+
+	return nil
+}
@@ -0,0 +1,29 @@
+package publisher
+
+import (
+	"context"
+
+	"github.com/maypok86/payment-api/internal/domain/outbox"
+)
+
+// MultiPublisher passes every event to each of its publishers in order. It stops at the first error, so the event
+// is retried and publishers that already succeeded must tolerate getting it again.
+type MultiPublisher struct {
+	publishers []outbox.Publisher
+}
+
+func NewMultiPublisher(publishers ...outbox.Publisher) *MultiPublisher {
+	return &MultiPublisher{
+		publishers: publishers,
+	}
+}
+
+func (p *MultiPublisher) Publish(ctx context.Context, event outbox.Event) error {
+	for _, publisher := range p.publishers {
+		if err := publisher.Publish(ctx, event); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
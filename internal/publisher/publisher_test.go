@@ -0,0 +1,176 @@
+package publisher_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/maypok86/payment-api/internal/domain/outbox"
+	"github.com/maypok86/payment-api/internal/domain/webhook"
+	"github.com/maypok86/payment-api/internal/publisher"
+	"github.com/stretchr/testify/require"
+)
+
+var fakeEvent = outbox.Event{
+	EventID:       1,
+	Type:          outbox.BalanceCredited,
+	AggregateType: outbox.AccountAggregate,
+	AggregateID:   1,
+	Payload:       []byte(`{"account_id":1,"amount":100,"currency":"RUB","balance":100}`),
+	CreatedAt:     time.Date(2022, 11, 12, 12, 0, 0, 0, time.UTC),
+}
+
+func TestFilePublisher_Publish(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "events.jsonl")
+	filePublisher := publisher.NewFilePublisher(path)
+
+	require.NoError(t, filePublisher.Publish(context.Background(), fakeEvent))
+	require.NoError(t, filePublisher.Publish(context.Background(), fakeEvent))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	require.Len(t, lines, 2)
+
+	var message outbox.Message
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &message))
+	require.Equal(t, outbox.NewMessage(fakeEvent), message)
+}
+
+func TestWebhookPublisher_Publish(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		statusCode int
+		wantedErr  error
+	}{
+		{
+			name:       "success publish",
+			statusCode: http.StatusNoContent,
+		},
+		{
+			name:       "unexpected status",
+			statusCode: http.StatusServiceUnavailable,
+			wantedErr:  publisher.ErrUnexpectedStatus,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var message outbox.Message
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				require.Equal(t, http.MethodPost, r.Method)
+				require.Equal(t, "1", r.Header.Get("X-Event-ID"))
+				require.Equal(t, "balance_credited", r.Header.Get("X-Event-Type"))
+				require.NoError(t, json.NewDecoder(r.Body).Decode(&message))
+
+				w.WriteHeader(tt.statusCode)
+			}))
+			defer server.Close()
+
+			webhookPublisher := publisher.NewWebhookPublisher(server.URL, time.Second)
+
+			err := webhookPublisher.Publish(context.Background(), fakeEvent)
+			if tt.wantedErr != nil {
+				require.ErrorIs(t, err, tt.wantedErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, outbox.NewMessage(fakeEvent), message)
+		})
+	}
+}
+
+type fakePublisher struct {
+	err    error
+	events []outbox.Event
+}
+
+func (fp *fakePublisher) Publish(_ context.Context, event outbox.Event) error {
+	fp.events = append(fp.events, event)
+
+	return fp.err
+}
+
+func TestMultiPublisher_Publish(t *testing.T) {
+	t.Parallel()
+
+	publishErr := errors.New("publish error")
+
+	first := &fakePublisher{}
+	second := &fakePublisher{}
+	require.NoError(t, publisher.NewMultiPublisher(first, second).Publish(context.Background(), fakeEvent))
+	require.Equal(t, []outbox.Event{fakeEvent}, first.events)
+	require.Equal(t, []outbox.Event{fakeEvent}, second.events)
+
+	failing := &fakePublisher{err: publishErr}
+	skipped := &fakePublisher{}
+	err := publisher.NewMultiPublisher(failing, skipped).Publish(context.Background(), fakeEvent)
+	require.ErrorIs(t, err, publishErr)
+	require.Empty(t, skipped.events)
+}
+
+func TestHTTPSender_Send(t *testing.T) {
+	t.Parallel()
+
+	body := []byte(`{"event_id":1}`)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodPost, r.Method)
+		require.Equal(t, "sha256=abc", r.Header.Get(webhook.SignatureHeader))
+
+		got, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		require.Equal(t, body, got)
+
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	header := make(http.Header)
+	header.Set(webhook.SignatureHeader, "sha256=abc")
+
+	sender := publisher.NewHTTPSender(time.Second, publisher.WithPrivateAddresses())
+	statusCode, err := sender.Send(context.Background(), webhook.Request{
+		URL:    server.URL,
+		Header: header,
+		Body:   body,
+	})
+	require.NoError(t, err)
+	require.Equal(t, http.StatusAccepted, statusCode)
+}
+
+func TestHTTPSender_SendToPrivateAddress(t *testing.T) {
+	t.Parallel()
+
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+	}))
+	defer server.Close()
+
+	// Host names are checked once they are resolved, so localhost is refused as well.
+	for _, url := range []string{server.URL, strings.Replace(server.URL, "127.0.0.1", "localhost", 1)} {
+		_, err := publisher.NewHTTPSender(time.Second).Send(context.Background(), webhook.Request{
+			URL:  url,
+			Body: []byte(`{"event_id":1}`),
+		})
+		require.ErrorIs(t, err, publisher.ErrPrivateAddress)
+	}
+	require.Zero(t, requests)
+}
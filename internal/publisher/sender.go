@@ -0,0 +1,92 @@
+package publisher
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"syscall"
+	"time"
+
+	"github.com/maypok86/payment-api/internal/domain/webhook"
+)
+
+var ErrPrivateAddress = errors.New("webhook address is not public")
+
+// HTTPSender posts webhook deliveries. The response body is discarded. Connections to addresses that are not
+// public are refused after the host name is resolved, so a subscription can not reach the network of the service
+// through DNS or a redirect.
+type HTTPSender struct {
+	client *http.Client
+}
+
+type senderOptions struct {
+	allowPrivate bool
+}
+
+type SenderOption func(*senderOptions)
+
+// WithPrivateAddresses lets the sender connect to addresses that are not public, e.g. to a local receiver in tests.
+func WithPrivateAddresses() SenderOption {
+	return func(o *senderOptions) {
+		o.allowPrivate = true
+	}
+}
+
+func NewHTTPSender(timeout time.Duration, opts ...SenderOption) *HTTPSender {
+	var o senderOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	dialer := &net.Dialer{Timeout: timeout}
+	if !o.allowPrivate {
+		dialer.Control = refusePrivate
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	return &HTTPSender{
+		client: &http.Client{
+			Transport: transport,
+			Timeout:   timeout,
+		},
+	}
+}
+
+func (s *HTTPSender) Send(ctx context.Context, request webhook.Request) (int, error) {
+	httpRequest, err := http.NewRequestWithContext(ctx, http.MethodPost, request.URL, bytes.NewReader(request.Body))
+	if err != nil {
+		return 0, fmt.Errorf("create webhook request: %w", err)
+	}
+	httpRequest.Header = request.Header.Clone()
+
+	response, err := s.client.Do(httpRequest)
+	if err != nil {
+		return 0, fmt.Errorf("send webhook request: %w", err)
+	}
+	defer response.Body.Close()
+
+	_, _ = io.Copy(io.Discard, response.Body)
+
+	return response.StatusCode, nil
+}
+
+// refusePrivate is called with the resolved address right before every connection.
+func refusePrivate(_, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrPrivateAddress, address)
+	}
+
+	if ip := net.ParseIP(host); ip == nil || !webhook.IsPublicIP(ip) {
+		return fmt.Errorf("%w: %s", ErrPrivateAddress, address)
+	}
+
+	return nil
+}
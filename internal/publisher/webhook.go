@@ -0,0 +1,58 @@
+package publisher
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/maypok86/payment-api/internal/domain/outbox"
+)
+
+var ErrUnexpectedStatus = errors.New("webhook responded with unexpected status")
+
+// WebhookPublisher posts every event as JSON to a URL. Any status other than 2xx is treated as a failed delivery.
+type WebhookPublisher struct {
+	url    string
+	client *http.Client
+}
+
+func NewWebhookPublisher(url string, timeout time.Duration) *WebhookPublisher {
+	return &WebhookPublisher{
+		url: url,
+		client: &http.Client{
+			Timeout: timeout,
+		},
+	}
+}
+
+func (p *WebhookPublisher) Publish(ctx context.Context, event outbox.Event) error {
+	body, err := json.Marshal(outbox.NewMessage(event))
+	if err != nil {
+		return fmt.Errorf("marshal event: %w", err)
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, p.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("create webhook request: %w", err)
+	}
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("X-Event-ID", strconv.FormatInt(event.EventID, 10))
+	request.Header.Set("X-Event-Type", event.Type.String())
+
+	response, err := p.client.Do(request)
+	if err != nil {
+		return fmt.Errorf("send webhook request: %w", err)
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode < http.StatusOK || response.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("%w: %d", ErrUnexpectedStatus, response.StatusCode)
+	}
+
+	return nil
+}
@@ -0,0 +1,77 @@
+package rate
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/maypok86/payment-api/internal/domain/account"
+	"github.com/maypok86/payment-api/internal/domain/currency"
+)
+
+// FileProvider reads rates from a JSON file like {"USD/RUB": "61.5"} and rereads it when the file changes,
+// so an external job can publish new rates without restarting the service.
+type FileProvider struct {
+	path    string
+	mutex   sync.Mutex
+	modTime time.Time
+	rates   map[Pair]account.Rate
+}
+
+func NewFileProvider(path string) (*FileProvider, error) {
+	provider := &FileProvider{
+		path: path,
+	}
+
+	if _, err := provider.load(); err != nil {
+		return nil, err
+	}
+
+	return provider, nil
+}
+
+func (p *FileProvider) GetRate(_ context.Context, from, to currency.Currency) (account.Rate, error) {
+	rates, err := p.load()
+	if err != nil {
+		return account.Rate{}, err
+	}
+
+	return lookup(rates, from, to)
+}
+
+func (p *FileProvider) load() (map[Pair]account.Rate, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	info, err := os.Stat(p.path)
+	if err != nil {
+		return nil, fmt.Errorf("stat rate file: %w", err)
+	}
+
+	if p.rates != nil && info.ModTime().Equal(p.modTime) {
+		return p.rates, nil
+	}
+
+	data, err := os.ReadFile(p.path)
+	if err != nil {
+		return nil, fmt.Errorf("read rate file: %w", err)
+	}
+
+	var table map[string]string
+	if err := json.Unmarshal(data, &table); err != nil {
+		return nil, fmt.Errorf("unmarshal rate file: %w", err)
+	}
+
+	rates, err := ParseTable(table)
+	if err != nil {
+		return nil, fmt.Errorf("parse rate file: %w", err)
+	}
+
+	p.rates = rates
+	p.modTime = info.ModTime()
+
+	return rates, nil
+}
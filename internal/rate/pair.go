@@ -0,0 +1,67 @@
+package rate
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/maypok86/payment-api/internal/domain/account"
+	"github.com/maypok86/payment-api/internal/domain/currency"
+)
+
+// Pair is a currency pair written as "USD/RUB".
+type Pair struct {
+	From currency.Currency
+	To   currency.Currency
+}
+
+func ParsePair(s string) (Pair, error) {
+	from, to, ok := strings.Cut(s, "/")
+	if !ok {
+		return Pair{}, fmt.Errorf("parse pair %q: expected FROM/TO", s)
+	}
+
+	fromCurrency, err := currency.Parse(from)
+	if err != nil {
+		return Pair{}, fmt.Errorf("parse pair %q: %w", s, err)
+	}
+
+	toCurrency, err := currency.Parse(to)
+	if err != nil {
+		return Pair{}, fmt.Errorf("parse pair %q: %w", s, err)
+	}
+
+	return Pair{From: fromCurrency, To: toCurrency}, nil
+}
+
+func (p Pair) String() string {
+	return p.From.String() + "/" + p.To.String()
+}
+
+// ParseTable parses a rate table like {"USD/RUB": "61.5"}.
+func ParseTable(table map[string]string) (map[Pair]account.Rate, error) {
+	rates := make(map[Pair]account.Rate, len(table))
+	for key, value := range table {
+		pair, err := ParsePair(key)
+		if err != nil {
+			return nil, err
+		}
+
+		rate, err := account.ParseRate(value)
+		if err != nil {
+			return nil, fmt.Errorf("parse rate for %s: %w", pair, err)
+		}
+
+		rates[pair] = rate
+	}
+
+	return rates, nil
+}
+
+func lookup(rates map[Pair]account.Rate, from, to currency.Currency) (account.Rate, error) {
+	rate, ok := rates[Pair{From: from, To: to}]
+	if !ok {
+		return account.Rate{}, fmt.Errorf("%s/%s: %w", from, to, account.ErrRateNotFound)
+	}
+
+	return rate, nil
+}
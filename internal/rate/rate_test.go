@@ -0,0 +1,133 @@
+package rate_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/maypok86/payment-api/internal/domain/account"
+	"github.com/maypok86/payment-api/internal/domain/currency"
+	"github.com/maypok86/payment-api/internal/rate"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTable(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		table   map[string]string
+		want    map[rate.Pair]string
+		wantErr bool
+	}{
+		{
+			name:  "success parse",
+			table: map[string]string{"USD/RUB": "61.5", "RUB/USD": "0.0162"},
+			want: map[rate.Pair]string{
+				{From: currency.USD, To: currency.RUB}: "61.5",
+				{From: currency.RUB, To: currency.USD}: "0.0162",
+			},
+		},
+		{
+			name:  "empty table",
+			table: nil,
+			want:  map[rate.Pair]string{},
+		},
+		{
+			name:    "invalid pair",
+			table:   map[string]string{"USDRUB": "61.5"},
+			wantErr: true,
+		},
+		{
+			name:    "unsupported currency",
+			table:   map[string]string{"USD/XXX": "61.5"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid rate",
+			table:   map[string]string{"USD/RUB": "-1"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := rate.ParseTable(tt.table)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			gotRates := make(map[rate.Pair]string, len(got))
+			for pair, r := range got {
+				gotRates[pair] = r.String()
+			}
+			require.Equal(t, tt.want, gotRates)
+		})
+	}
+}
+
+func TestStaticProvider_GetRate(t *testing.T) {
+	t.Parallel()
+
+	rates, err := rate.ParseTable(map[string]string{"USD/RUB": "61.5"})
+	require.NoError(t, err)
+
+	provider := rate.NewStaticProvider(rates)
+
+	got, err := provider.GetRate(context.Background(), currency.USD, currency.RUB)
+	require.NoError(t, err)
+	require.Equal(t, "61.5", got.String())
+
+	_, err = provider.GetRate(context.Background(), currency.RUB, currency.USD)
+	require.ErrorIs(t, err, account.ErrRateNotFound)
+}
+
+func TestFileProvider_GetRate(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "rates.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"USD/RUB": "61.5"}`), 0o600))
+
+	provider, err := rate.NewFileProvider(path)
+	require.NoError(t, err)
+
+	got, err := provider.GetRate(context.Background(), currency.USD, currency.RUB)
+	require.NoError(t, err)
+	require.Equal(t, "61.5", got.String())
+
+	_, err = provider.GetRate(context.Background(), currency.EUR, currency.RUB)
+	require.ErrorIs(t, err, account.ErrRateNotFound)
+
+	require.NoError(t, os.WriteFile(path, []byte(`{"USD/RUB": "62", "EUR/RUB": "61.2"}`), 0o600))
+	modTime := time.Now().Add(time.Second)
+	require.NoError(t, os.Chtimes(path, modTime, modTime))
+
+	got, err = provider.GetRate(context.Background(), currency.USD, currency.RUB)
+	require.NoError(t, err)
+	require.Equal(t, "62", got.String())
+
+	got, err = provider.GetRate(context.Background(), currency.EUR, currency.RUB)
+	require.NoError(t, err)
+	require.Equal(t, "61.2", got.String())
+}
+
+func TestNewFileProvider_Error(t *testing.T) {
+	t.Parallel()
+
+	_, err := rate.NewFileProvider(filepath.Join(t.TempDir(), "missing.json"))
+	require.Error(t, err)
+
+	path := filepath.Join(t.TempDir(), "rates.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"USD/RUB": "abc"}`), 0o600))
+
+	_, err = rate.NewFileProvider(path)
+	require.Error(t, err)
+}
@@ -0,0 +1,22 @@
+package rate
+
+import (
+	"context"
+
+	"github.com/maypok86/payment-api/internal/domain/account"
+	"github.com/maypok86/payment-api/internal/domain/currency"
+)
+
+type StaticProvider struct {
+	rates map[Pair]account.Rate
+}
+
+func NewStaticProvider(rates map[Pair]account.Rate) *StaticProvider {
+	return &StaticProvider{
+		rates: rates,
+	}
+}
+
+func (p *StaticProvider) GetRate(_ context.Context, from, to currency.Currency) (account.Rate, error) {
+	return lookup(p.rates, from, to)
+}
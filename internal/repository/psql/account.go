@@ -10,28 +10,46 @@ import (
 	"github.com/jackc/pgerrcode"
 	"github.com/jackc/pgx/v4"
 	"github.com/maypok86/payment-api/internal/domain/account"
+	"github.com/maypok86/payment-api/internal/domain/currency"
+	"github.com/maypok86/payment-api/internal/domain/order"
 	"github.com/maypok86/payment-api/internal/pkg/postgres"
 	"go.uber.org/zap"
 )
 
 type AccountRepository struct {
-	tableName string
-	db        *postgres.Client
-	logger    *zap.Logger
+	tableName         string
+	balancesTableName string
+	ordersTableName   string
+	db                *postgres.Client
+	logger            *zap.Logger
 }
 
 func NewAccountRepository(db *postgres.Client, logger *zap.Logger) *AccountRepository {
 	return &AccountRepository{
-		tableName: "accounts",
-		db:        db,
-		logger:    logger,
+		tableName:         "accounts",
+		balancesTableName: "account_balances",
+		ordersTableName:   "orders",
+		db:                db,
+		logger:            logger,
 	}
 }
 
-func (ar *AccountRepository) GetAccountByID(ctx context.Context, accountID int64) (account.Account, error) {
-	sql, args, err := ar.db.Builder.Select("account_id", "balance").
-		From(ar.tableName).
-		Where(sq.Eq{"account_id": accountID}).
+func (ar *AccountRepository) GetAccountByID(
+	ctx context.Context,
+	accountID int64,
+	cur currency.Currency,
+) (account.Account, error) {
+	sql, args, err := ar.db.Builder.
+		Select(
+			"a.account_id",
+			"a.status",
+			"COALESCE(b.balance, 0)",
+			"COALESCE(b.reserved, 0)",
+			"COALESCE(b.credit_limit, 0)",
+		).
+		From(ar.tableName+" AS a").
+		LeftJoin(ar.balancesTableName+" AS b ON b.account_id = a.account_id AND b.currency = ?", cur).
+		Where(sq.Eq{"a.account_id": accountID}).
 		Limit(1).
 		ToSql()
 	if err != nil {
@@ -40,8 +58,15 @@ func (ar *AccountRepository) GetAccountByID(ctx context.Context, accountID int64
 
 	ar.logger.Debug("get account by id query", zap.String("sql", sql), zap.Any("args", args))
 
-	var entity account.Account
-	if err = ar.db.QueryRow(ctx, sql, args...).Scan(&entity.AccountID, &entity.Balance); err != nil {
+	entity := account.Account{Currency: cur}
+	err = ar.db.QueryRow(ctx, sql, args...).Scan(
+		&entity.AccountID,
+		&entity.Status,
+		&entity.Available,
+		&entity.Reserved,
+		&entity.CreditLimit,
+	)
+	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return account.Account{}, fmt.Errorf("get account by id: %w", account.ErrNotFound)
 		}
@@ -52,9 +77,12 @@ func (ar *AccountRepository) GetAccountByID(ctx context.Context, accountID int64
 	return entity, nil
 }
 
+// updateBalanceDTO moves amount in or out of the available balance and reserved in or out of the reserved one.
 type updateBalanceDTO struct {
 	accountID int64
 	amount    int64
+	reserved  int64
+	currency  currency.Currency
 }
 
 func (ar *AccountRepository) updateBalance(
@@ -62,10 +90,25 @@ func (ar *AccountRepository) updateBalance(
 	updateType string,
 	dto updateBalanceDTO,
 ) (int64, error) {
+	if err := ar.createBalance(ctx, dto.accountID, dto.currency); err != nil {
+		return 0, err
+	}
+
+	where := sq.And{
+		sq.Eq{
+			"account_id": dto.accountID,
+			"currency":   dto.currency,
+		},
+	}
+	if dto.amount < 0 {
+		where = append(where, sq.Expr("balance + ? >= -credit_limit", dto.amount))
+	}
+
 	var accountBalance int64
-	sql, args, err := ar.db.Builder.Update(ar.tableName).
+	sql, args, err := ar.db.Builder.Update(ar.balancesTableName).
 		Set("balance", sq.Expr("balance + ?", dto.amount)).
-		Where(sq.Eq{"account_id": dto.accountID}).
+		Set("reserved", sq.Expr("reserved + ?", dto.reserved)).
+		Where(where).
 		Suffix("RETURNING balance").
 		ToSql()
 	if err != nil {
@@ -76,7 +119,7 @@ func (ar *AccountRepository) updateBalance(
 
 	if err := ar.db.QueryRow(ctx, sql, args...).Scan(&accountBalance); err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
-			return 0, account.ErrNotFound
+			return 0, ar.insufficientFunds(ctx, dto.accountID, dto.currency)
 		}
 
 		return 0, err
@@ -85,11 +128,52 @@ func (ar *AccountRepository) updateBalance(
 	return accountBalance, nil
 }
 
-func (ar *AccountRepository) createAccount(ctx context.Context, accountID int64) (account.Account, error) {
+// insufficientFunds explains why a balance update matched no rows: either the account does not exist
+// or the update would take the balance below the credit limit.
+func (ar *AccountRepository) insufficientFunds(ctx context.Context, accountID int64, cur currency.Currency) error {
+	entity, err := ar.GetAccountByID(ctx, accountID, cur)
+	if err != nil {
+		return err
+	}
+
+	return &account.InsufficientFundsError{
+		AccountID:   accountID,
+		Currency:    cur,
+		Available:   entity.Available,
+		CreditLimit: entity.CreditLimit,
+	}
+}
+
+// createBalance makes sure that an existing account has a balance row in the currency, so it can be updated.
+func (ar *AccountRepository) createBalance(ctx context.Context, accountID int64, cur currency.Currency) error {
+	sql, args, err := ar.db.Builder.Insert(ar.balancesTableName).
+		Columns("account_id", "currency").
+		Select(
+			ar.db.Builder.Select("account_id").
+				Column(sq.Expr("?::text", cur)).
+				From(ar.tableName).
+				Where(sq.Eq{"account_id": accountID}),
+		).
+		Suffix("ON CONFLICT DO NOTHING").
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("build create account balance query: %w", err)
+	}
+
+	ar.logger.Debug("create account balance query", zap.String("sql", sql), zap.Any("args", args))
+
+	if _, err := ar.db.Exec(ctx, sql, args...); err != nil {
+		return fmt.Errorf("insert account balance: %w", err)
+	}
+
+	return nil
+}
+
+func (ar *AccountRepository) CreateAccount(ctx context.Context, accountID int64) (account.Account, error) {
 	sql, args, err := ar.db.Builder.Insert(ar.tableName).
 		Columns("account_id").
 		Values(accountID).
-		Suffix("RETURNING account_id, balance").
+		Suffix("RETURNING account_id, status").
 		ToSql()
 	if err != nil {
 		return account.Account{}, fmt.Errorf("build create account query: %w", err)
@@ -98,7 +182,7 @@ func (ar *AccountRepository) createAccount(ctx context.Context, accountID int64)
 	ar.logger.Debug("create account query", zap.String("sql", sql), zap.Any("args", args))
 
 	var entity account.Account
-	if err := ar.db.QueryRow(ctx, sql, args...).Scan(&entity.AccountID, &entity.Balance); err != nil {
+	if err := ar.db.QueryRow(ctx, sql, args...).Scan(&entity.AccountID, &entity.Status); err != nil {
 		var pgErr *pgconn.PgError
 		if errors.As(err, &pgErr) {
 			if pgErr.Code == pgerrcode.UniqueViolation {
@@ -112,42 +196,228 @@ func (ar *AccountRepository) createAccount(ctx context.Context, accountID int64)
 	return entity, nil
 }
 
+func (ar *AccountRepository) GetAccountForUpdate(ctx context.Context, accountID int64) (account.Account, error) {
+	entity, err := ar.getAccountWithLock(ctx, accountID, "FOR UPDATE")
+	if err != nil {
+		return account.Account{}, fmt.Errorf("get account for update: %w", err)
+	}
+
+	return entity, nil
+}
+
+// checkActive locks the account against status changes until the end of the transaction
+// and returns an error if money can not be moved to or from it.
+func (ar *AccountRepository) checkActive(ctx context.Context, accountID int64) error {
+	entity, err := ar.getAccountWithLock(ctx, accountID, "FOR SHARE")
+	if err != nil {
+		return err
+	}
+
+	return entity.CheckActive()
+}
+
+func (ar *AccountRepository) getAccountWithLock(
+	ctx context.Context,
+	accountID int64,
+	lock string,
+) (account.Account, error) {
+	sql, args, err := ar.db.Builder.Select("account_id", "status").
+		From(ar.tableName).
+		Where(sq.Eq{"account_id": accountID}).
+		Suffix(lock).
+		ToSql()
+	if err != nil {
+		return account.Account{}, fmt.Errorf("build get account with lock query: %w", err)
+	}
+
+	ar.logger.Debug("get account with lock query", zap.String("sql", sql), zap.Any("args", args))
+
+	var entity account.Account
+	if err := ar.db.QueryRow(ctx, sql, args...).Scan(&entity.AccountID, &entity.Status); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return account.Account{}, account.ErrNotFound
+		}
+
+		return account.Account{}, err
+	}
+
+	return entity, nil
+}
+
+func (ar *AccountRepository) SetCreditLimit(
+	ctx context.Context,
+	dto account.SetCreditLimitDTO,
+) (account.Account, error) {
+	if err := ar.createBalance(ctx, dto.AccountID, dto.Currency); err != nil {
+		return account.Account{}, fmt.Errorf("set credit limit: %w", err)
+	}
+
+	sql, args, err := ar.db.Builder.Update(ar.balancesTableName).
+		Set("credit_limit", dto.CreditLimit).
+		Where(sq.And{
+			sq.Eq{
+				"account_id": dto.AccountID,
+				"currency":   dto.Currency,
+			},
+			sq.Expr("balance >= ?", -dto.CreditLimit),
+		}).
+		Suffix("RETURNING account_id, balance, reserved, credit_limit").
+		ToSql()
+	if err != nil {
+		return account.Account{}, fmt.Errorf("build set credit limit query: %w", err)
+	}
+
+	ar.logger.Debug("set credit limit query", zap.String("sql", sql), zap.Any("args", args))
+
+	entity := account.Account{Currency: dto.Currency}
+	err = ar.db.QueryRow(ctx, sql, args...).Scan(
+		&entity.AccountID,
+		&entity.Available,
+		&entity.Reserved,
+		&entity.CreditLimit,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			if _, err := ar.GetAccountByID(ctx, dto.AccountID, dto.Currency); err != nil {
+				return account.Account{}, fmt.Errorf("set credit limit: %w", err)
+			}
+
+			return account.Account{}, fmt.Errorf("set credit limit: %w", account.ErrCreditLimitTooLow)
+		}
+
+		return account.Account{}, fmt.Errorf("set credit limit: %w", err)
+	}
+
+	return entity, nil
+}
+
+func (ar *AccountRepository) UpdateStatus(ctx context.Context, dto account.UpdateStatusDTO) error {
+	sql, args, err := ar.db.Builder.Update(ar.tableName).
+		Set("status", dto.To).
+		Where(sq.Eq{
+			"account_id": dto.AccountID,
+			"status":     dto.From,
+		}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("build update account status query: %w", err)
+	}
+
+	ar.logger.Debug("update account status query", zap.String("sql", sql), zap.Any("args", args))
+
+	result, err := ar.db.Exec(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("update account status: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("update account status: %w", account.ErrNotFound)
+	}
+
+	return nil
+}
+
+func (ar *AccountRepository) HasBalance(ctx context.Context, accountID int64) (bool, error) {
+	sql, args, err := ar.db.Builder.Select("1").
+		From(ar.balancesTableName).
+		Where(sq.And{
+			sq.Eq{"account_id": accountID},
+			sq.NotEq{"balance": 0},
+		}).
+		Prefix("SELECT EXISTS (").
+		Suffix(")").
+		ToSql()
+	if err != nil {
+		return false, fmt.Errorf("build has balance query: %w", err)
+	}
+
+	ar.logger.Debug("has balance query", zap.String("sql", sql), zap.Any("args", args))
+
+	var exists bool
+	if err := ar.db.QueryRow(ctx, sql, args...).Scan(&exists); err != nil {
+		return false, fmt.Errorf("has balance: %w", err)
+	}
+
+	return exists, nil
+}
+
+func (ar *AccountRepository) HasOpenReservations(ctx context.Context, accountID int64) (bool, error) {
+	sql, args, err := ar.db.Builder.Select("1").
+		From(ar.ordersTableName).
+		Where(sq.Eq{
+			"account_id": accountID,
+			"status":     []order.Status{order.Created, order.Reserved},
+		}).
+		Prefix("SELECT EXISTS (").
+		Suffix(")").
+		ToSql()
+	if err != nil {
+		return false, fmt.Errorf("build has open reservations query: %w", err)
+	}
+
+	ar.logger.Debug("has open reservations query", zap.String("sql", sql), zap.Any("args", args))
+
+	var exists bool
+	if err := ar.db.QueryRow(ctx, sql, args...).Scan(&exists); err != nil {
+		return false, fmt.Errorf("has open reservations: %w", err)
+	}
+
+	return exists, nil
+}
+
 func (ar *AccountRepository) AddBalance(
 	ctx context.Context,
 	dto account.AddBalanceDTO,
 ) (int64, error) {
-	accountBalance, err := ar.updateBalance(ctx, "add", updateBalanceDTO{
-		accountID: dto.AccountID,
-		amount:    dto.Amount,
-	})
-	if err == nil {
-		return accountBalance, nil
-	}
-
-	if errors.Is(err, account.ErrNotFound) {
-		if _, err := ar.createAccount(ctx, dto.AccountID); err != nil {
+	if err := ar.checkActive(ctx, dto.AccountID); err != nil {
+		if !errors.Is(err, account.ErrNotFound) {
 			return 0, fmt.Errorf("add balance: %w", err)
 		}
 
-		accountBalance, err = ar.updateBalance(ctx, "add", updateBalanceDTO{
-			accountID: dto.AccountID,
-			amount:    dto.Amount,
-		})
-		if err == nil {
-			return accountBalance, nil
+		// Accounts are still created on the first enrollment for clients that do not open them explicitly.
+		if _, err := ar.CreateAccount(ctx, dto.AccountID); err != nil {
+			return 0, fmt.Errorf("add balance: %w", err)
 		}
 	}
 
-	return 0, fmt.Errorf("add balance: %w", err)
+	accountBalance, err := ar.updateBalance(ctx, "add", updateBalanceDTO{
+		accountID: dto.AccountID,
+		amount:    dto.Amount,
+		currency:  dto.Currency,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("add balance: %w", err)
+	}
+
+	return accountBalance, nil
 }
 
 func (ar *AccountRepository) TransferBalance(
 	ctx context.Context,
 	dto account.TransferBalanceDTO,
 ) (int64, int64, error) {
+	accounts, err := ar.lockAccounts(ctx, []int64{dto.SenderID, dto.ReceiverID})
+	if err != nil {
+		return 0, 0, fmt.Errorf("transfer balance: %w", err)
+	}
+
+	expected := 2
+	if dto.SenderID == dto.ReceiverID {
+		expected = 1
+	}
+	if len(accounts) < expected {
+		return 0, 0, fmt.Errorf("transfer balance: %w", account.ErrNotFound)
+	}
+
+	for _, entity := range accounts {
+		if err := entity.CheckActive(); err != nil {
+			return 0, 0, fmt.Errorf("transfer balance: %w", err)
+		}
+	}
+
 	senderBalance, err := ar.updateBalance(ctx, "send", updateBalanceDTO{
 		accountID: dto.SenderID,
 		amount:    -dto.Amount,
+		currency:  dto.Currency,
 	})
 	if err != nil {
 		return 0, 0, fmt.Errorf("transfer balance: %w", err)
@@ -155,7 +425,8 @@ func (ar *AccountRepository) TransferBalance(
 
 	receiverBalance, err := ar.updateBalance(ctx, "receive", updateBalanceDTO{
 		accountID: dto.ReceiverID,
-		amount:    dto.Amount,
+		amount:    dto.ReceiverAmount,
+		currency:  dto.ReceiverCurrency,
 	})
 	if err != nil {
 		return 0, 0, fmt.Errorf("transfer balance: %w", err)
@@ -164,10 +435,99 @@ func (ar *AccountRepository) TransferBalance(
 	return senderBalance, receiverBalance, nil
 }
 
+// lockAccounts locks the existing accounts with the given ids in ascending id order until the end of the
+// transaction. Transfers between the same accounts take the locks in the same order whatever their direction,
+// so they wait for each other instead of deadlocking.
+func (ar *AccountRepository) lockAccounts(ctx context.Context, accountIDs []int64) ([]account.Account, error) {
+	sql, args, err := ar.db.Builder.Select("account_id", "status").
+		From(ar.tableName).
+		Where(sq.Eq{"account_id": accountIDs}).
+		OrderBy("account_id").
+		Suffix("FOR UPDATE").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build lock accounts query: %w", err)
+	}
+
+	ar.logger.Debug("lock accounts query", zap.String("sql", sql), zap.Any("args", args))
+
+	rows, err := ar.db.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("lock accounts: %w", err)
+	}
+	defer rows.Close()
+
+	accounts := make([]account.Account, 0, len(accountIDs))
+	for rows.Next() {
+		var entity account.Account
+		if err := rows.Scan(&entity.AccountID, &entity.Status); err != nil {
+			return nil, fmt.Errorf("scan locked account: %w", err)
+		}
+
+		accounts = append(accounts, entity)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("lock accounts: %w", err)
+	}
+
+	return accounts, nil
+}
+
+// LockBalances locks the accounts and the balances against other updates until the end of the transaction.
+// Missing balances are created first. Balances must be sorted, so that concurrent callers take the locks in
+// the same order.
+func (ar *AccountRepository) LockBalances(ctx context.Context, balances []account.BalanceKey) error {
+	accountIDs := make([]int64, 0, len(balances))
+	currencies := make([]string, 0, len(balances))
+	for _, balance := range balances {
+		accountIDs = append(accountIDs, balance.AccountID)
+		currencies = append(currencies, balance.Currency.String())
+	}
+
+	if _, err := ar.lockAccounts(ctx, accountIDs); err != nil {
+		return fmt.Errorf("lock balances: %w", err)
+	}
+
+	for _, balance := range balances {
+		if err := ar.createBalance(ctx, balance.AccountID, balance.Currency); err != nil {
+			return fmt.Errorf("lock balances: %w", err)
+		}
+	}
+
+	sql, args, err := ar.db.Builder.Select("account_id").
+		From(ar.balancesTableName).
+		Where(sq.Expr(
+			"(account_id, currency) IN (SELECT * FROM unnest(?::bigint[], ?::text[]))",
+			accountIDs,
+			currencies,
+		)).
+		OrderBy("account_id", "currency").
+		Suffix("FOR UPDATE").
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("build lock balances query: %w", err)
+	}
+
+	ar.logger.Debug("lock balances query", zap.String("sql", sql), zap.Any("args", args))
+
+	if _, err := ar.db.Exec(ctx, sql, args...); err != nil {
+		return fmt.Errorf("lock balances: %w", err)
+	}
+
+	return nil
+}
+
 func (ar *AccountRepository) ReserveBalance(ctx context.Context, dto account.ReserveBalanceDTO) (int64, error) {
+	if err := ar.checkActive(ctx, dto.AccountID); err != nil {
+		return 0, fmt.Errorf("reserve balance: %w", err)
+	}
+
 	balance, err := ar.updateBalance(ctx, "reserve", updateBalanceDTO{
 		accountID: dto.AccountID,
 		amount:    -dto.Amount,
+		reserved:  dto.Amount,
+		currency:  dto.Currency,
 	})
 	if err != nil {
 		return 0, fmt.Errorf("reserve balance: %w", err)
@@ -180,6 +540,8 @@ func (ar *AccountRepository) ReturnBalance(ctx context.Context, dto account.Retu
 	balance, err := ar.updateBalance(ctx, "return", updateBalanceDTO{
 		accountID: dto.AccountID,
 		amount:    dto.Amount,
+		reserved:  -dto.Amount,
+		currency:  dto.Currency,
 	})
 	if err != nil {
 		return 0, fmt.Errorf("return balance: %w", err)
@@ -187,3 +549,30 @@ func (ar *AccountRepository) ReturnBalance(ctx context.Context, dto account.Retu
 
 	return balance, nil
 }
+
+// CaptureBalance takes the captured money out of the reserved balance, the available balance does not change.
+func (ar *AccountRepository) CaptureBalance(ctx context.Context, dto account.CaptureBalanceDTO) (int64, error) {
+	balance, err := ar.updateBalance(ctx, "capture", updateBalanceDTO{
+		accountID: dto.AccountID,
+		reserved:  -dto.Amount,
+		currency:  dto.Currency,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("capture balance: %w", err)
+	}
+
+	return balance, nil
+}
+
+func (ar *AccountRepository) RefundBalance(ctx context.Context, dto account.RefundBalanceDTO) (int64, error) {
+	balance, err := ar.updateBalance(ctx, "refund", updateBalanceDTO{
+		accountID: dto.AccountID,
+		amount:    dto.Amount,
+		currency:  dto.Currency,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("refund balance: %w", err)
+	}
+
+	return balance, nil
+}
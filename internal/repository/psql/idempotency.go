@@ -0,0 +1,100 @@
+package psql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v4"
+	"github.com/maypok86/payment-api/internal/domain/idempotency"
+	"github.com/maypok86/payment-api/internal/pkg/postgres"
+	"go.uber.org/zap"
+)
+
+type IdempotencyRepository struct {
+	tableName string
+	db        *postgres.Client
+	logger    *zap.Logger
+}
+
+func NewIdempotencyRepository(db *postgres.Client, logger *zap.Logger) *IdempotencyRepository {
+	return &IdempotencyRepository{
+		tableName: "idempotency_keys",
+		db:        db,
+		logger:    logger,
+	}
+}
+
+func (ir *IdempotencyRepository) CreateKey(ctx context.Context, dto idempotency.CreateDTO) (bool, error) {
+	sql, args, err := ir.db.Builder.Insert(ir.tableName).
+		Columns("idempotency_key", "fingerprint").
+		Values(dto.Key, dto.Fingerprint).
+		Suffix("ON CONFLICT (idempotency_key) DO NOTHING").
+		ToSql()
+	if err != nil {
+		return false, fmt.Errorf("build create idempotency key query: %w", err)
+	}
+
+	ir.logger.Debug("create idempotency key query", zap.String("sql", sql), zap.Any("args", args))
+
+	result, err := ir.db.Exec(ctx, sql, args...)
+	if err != nil {
+		return false, fmt.Errorf("insert idempotency key: %w", err)
+	}
+
+	return result.RowsAffected() == 1, nil
+}
+
+func (ir *IdempotencyRepository) GetKey(ctx context.Context, key string) (idempotency.Key, error) {
+	sql, args, err := ir.db.Builder.Select("idempotency_key", "fingerprint", "status_code", "response", "created_at").
+		From(ir.tableName).
+		Where(sq.Eq{"idempotency_key": key}).
+		Limit(1).
+		ToSql()
+	if err != nil {
+		return idempotency.Key{}, fmt.Errorf("build get idempotency key query: %w", err)
+	}
+
+	ir.logger.Debug("get idempotency key query", zap.String("sql", sql), zap.Any("args", args))
+
+	var entity idempotency.Key
+	if err := ir.db.QueryRow(ctx, sql, args...).Scan(
+		&entity.Key,
+		&entity.Fingerprint,
+		&entity.Response.StatusCode,
+		&entity.Response.Body,
+		&entity.CreatedAt,
+	); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return idempotency.Key{}, fmt.Errorf("get idempotency key: %w", idempotency.ErrNotFound)
+		}
+
+		return idempotency.Key{}, fmt.Errorf("get idempotency key: %w", err)
+	}
+
+	return entity, nil
+}
+
+func (ir *IdempotencyRepository) SaveResponse(ctx context.Context, dto idempotency.SaveResponseDTO) error {
+	sql, args, err := ir.db.Builder.Update(ir.tableName).
+		Set("status_code", dto.Response.StatusCode).
+		Set("response", dto.Response.Body).
+		Where(sq.Eq{"idempotency_key": dto.Key}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("build save idempotency response query: %w", err)
+	}
+
+	ir.logger.Debug("save idempotency response query", zap.String("sql", sql), zap.Any("args", args))
+
+	result, err := ir.db.Exec(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("save idempotency response: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("save idempotency response: %w", idempotency.ErrNotFound)
+	}
+
+	return nil
+}
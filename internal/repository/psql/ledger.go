@@ -0,0 +1,169 @@
+package psql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgerrcode"
+	"github.com/maypok86/payment-api/internal/domain/account"
+	"github.com/maypok86/payment-api/internal/domain/ledger"
+	"github.com/maypok86/payment-api/internal/pkg/postgres"
+	"go.uber.org/zap"
+)
+
+type LedgerRepository struct {
+	postingsTableName string
+	linesTableName    string
+	db                *postgres.Client
+	logger            *zap.Logger
+}
+
+func NewLedgerRepository(db *postgres.Client, logger *zap.Logger) *LedgerRepository {
+	return &LedgerRepository{
+		postingsTableName: "postings",
+		linesTableName:    "posting_lines",
+		db:                db,
+		logger:            logger,
+	}
+}
+
+func (lr *LedgerRepository) CreatePosting(ctx context.Context, dto ledger.CreatePostingDTO) error {
+	if err := dto.Validate(); err != nil {
+		return fmt.Errorf("create posting: %w", err)
+	}
+
+	sql, args, err := lr.db.Builder.Insert(lr.postingsTableName).
+		Columns("type", "currency", "description").
+		Values(dto.Type, dto.Currency, dto.Description).
+		Suffix("RETURNING posting_id").
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("build create posting query: %w", err)
+	}
+
+	lr.logger.Debug("create posting query", zap.String("sql", sql), zap.Any("args", args))
+
+	var postingID int64
+	if err := lr.db.QueryRow(ctx, sql, args...).Scan(&postingID); err != nil {
+		return fmt.Errorf("insert posting: %w", err)
+	}
+
+	query := lr.db.Builder.Insert(lr.linesTableName).
+		Columns("posting_id", "account_id", "system_account", "side", "amount")
+	for _, line := range dto.Lines {
+		var accountID interface{}
+		if line.AccountID != 0 {
+			accountID = line.AccountID
+		}
+
+		query = query.Values(postingID, accountID, line.SystemAccount, line.Side, line.Amount)
+	}
+
+	sql, args, err = query.ToSql()
+	if err != nil {
+		return fmt.Errorf("build create posting lines query: %w", err)
+	}
+
+	lr.logger.Debug("create posting lines query", zap.String("sql", sql), zap.Any("args", args))
+
+	if _, err := lr.db.Exec(ctx, sql, args...); err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == pgerrcode.ForeignKeyViolation {
+			return fmt.Errorf("insert posting lines: %w", account.ErrNotFound)
+		}
+
+		return fmt.Errorf("insert posting lines: %w", err)
+	}
+
+	return nil
+}
+
+func (lr *LedgerRepository) GetReconciliation(ctx context.Context) (ledger.Reconciliation, error) {
+	sql, args, err := lr.db.Builder.Select(
+		"COALESCE(SUM(amount) FILTER (WHERE side = 'debit'), 0)",
+		"COALESCE(SUM(amount) FILTER (WHERE side = 'credit'), 0)",
+	).
+		From(lr.linesTableName).
+		ToSql()
+	if err != nil {
+		return ledger.Reconciliation{}, fmt.Errorf("build get ledger totals query: %w", err)
+	}
+
+	lr.logger.Debug("get ledger totals query", zap.String("sql", sql), zap.Any("args", args))
+
+	var reconciliation ledger.Reconciliation
+	if err := lr.db.QueryRow(ctx, sql, args...).Scan(
+		&reconciliation.TotalDebit,
+		&reconciliation.TotalCredit,
+	); err != nil {
+		return ledger.Reconciliation{}, fmt.Errorf("get ledger totals: %w", err)
+	}
+
+	reconciliation.Mismatches, err = lr.getMismatches(ctx)
+	if err != nil {
+		return ledger.Reconciliation{}, err
+	}
+
+	return reconciliation, nil
+}
+
+func (lr *LedgerRepository) getMismatches(ctx context.Context) ([]ledger.AccountMismatch, error) {
+	ledgerBalances := lr.db.Builder.Select(
+		"pl.account_id",
+		"p.currency",
+		"SUM(CASE WHEN pl.side = 'credit' THEN pl.amount ELSE -pl.amount END) AS balance",
+	).
+		From(lr.linesTableName+" AS pl").
+		Join(lr.postingsTableName+" AS p ON p.posting_id = pl.posting_id").
+		Where("pl.account_id IS NOT NULL").
+		GroupBy("pl.account_id", "p.currency")
+
+	ledgerBalancesSQL, _, err := ledgerBalances.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build get ledger balances query: %w", err)
+	}
+
+	sql, args, err := lr.db.Builder.Select("a.account_id", "a.currency", "a.balance", "COALESCE(l.balance, 0)").
+		From("account_balances AS a").
+		LeftJoin(fmt.Sprintf(
+			"(%s) AS l ON l.account_id = a.account_id AND l.currency = a.currency",
+			ledgerBalancesSQL,
+		)).
+		Where("a.balance <> COALESCE(l.balance, 0)").
+		OrderBy("a.account_id", "a.currency").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build get ledger mismatches query: %w", err)
+	}
+
+	lr.logger.Debug("get ledger mismatches query", zap.String("sql", sql), zap.Any("args", args))
+
+	rows, err := lr.db.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("run get ledger mismatches query: %w", err)
+	}
+	defer rows.Close()
+
+	var mismatches []ledger.AccountMismatch
+	for rows.Next() {
+		var mismatch ledger.AccountMismatch
+		if err := rows.Scan(
+			&mismatch.AccountID,
+			&mismatch.Currency,
+			&mismatch.Balance,
+			&mismatch.LedgerBalance,
+		); err != nil {
+			return nil, fmt.Errorf("scan ledger mismatch: %w", err)
+		}
+
+		mismatches = append(mismatches, mismatch)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("read ledger mismatches: %w", err)
+	}
+
+	return mismatches, nil
+}
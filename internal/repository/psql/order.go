@@ -8,30 +8,44 @@ import (
 	sq "github.com/Masterminds/squirrel"
 	"github.com/jackc/pgconn"
 	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v4"
 	"github.com/maypok86/payment-api/internal/domain/order"
 	"github.com/maypok86/payment-api/internal/pkg/postgres"
 	"go.uber.org/zap"
 )
 
+// orderServiceForeignKey tells an unknown service apart from an unknown account when an order is inserted.
+const orderServiceForeignKey = "orders_service_id_fkey"
+
 type OrderRepository struct {
-	tableName string
-	db        *postgres.Client
-	logger    *zap.Logger
+	tableName        string
+	historyTableName string
+	db               *postgres.Client
+	logger           *zap.Logger
 }
 
 func NewOrderRepository(db *postgres.Client, logger *zap.Logger) *OrderRepository {
 	return &OrderRepository{
-		tableName: "orders",
-		db:        db,
-		logger:    logger,
+		tableName:        "orders",
+		historyTableName: "order_status_history",
+		db:               db,
+		logger:           logger,
 	}
 }
 
 func (or *OrderRepository) CreateOrder(ctx context.Context, dto order.CreateDTO) (order.Order, error) {
 	sql, args, err := or.db.Builder.Insert(or.tableName).
-		Columns("order_id", "account_id", "service_id", "amount").
-		Values(dto.OrderID, dto.AccountID, dto.ServiceID, dto.Amount).
-		Suffix("RETURNING is_paid, is_cancelled, created_at, updated_at").
+		Columns("order_id", "account_id", "service_id", "amount", "currency", "status", "expires_at").
+		Values(
+			dto.OrderID,
+			dto.AccountID,
+			dto.ServiceID,
+			dto.Amount,
+			dto.Currency,
+			order.Created,
+			sq.Expr("now() + make_interval(secs => ?)", dto.ReservationTTL.Seconds()),
+		).
+		Suffix("RETURNING captured_amount, refunded_amount, status, expires_at, created_at, updated_at").
 		ToSql()
 	if err != nil {
 		return order.Order{}, fmt.Errorf("build create order query: %w", err)
@@ -44,10 +58,13 @@ func (or *OrderRepository) CreateOrder(ctx context.Context, dto order.CreateDTO)
 		AccountID: dto.AccountID,
 		ServiceID: dto.ServiceID,
 		Amount:    dto.Amount,
+		Currency:  dto.Currency,
 	}
 	if err := or.db.QueryRow(ctx, sql, args...).Scan(
-		&entity.IsPaid,
-		&entity.IsCancelled,
+		&entity.CapturedAmount,
+		&entity.RefundedAmount,
+		&entity.Status,
+		&entity.ExpiresAt,
 		&entity.CreatedAt,
 		&entity.UpdatedAt,
 	); err != nil {
@@ -58,6 +75,10 @@ func (or *OrderRepository) CreateOrder(ctx context.Context, dto order.CreateDTO)
 			case pgerrcode.UniqueViolation:
 				return order.Order{}, fmt.Errorf("insert order: %w", order.ErrAlreadyExist)
 			case pgerrcode.ForeignKeyViolation:
+				if pgErr.ConstraintName == orderServiceForeignKey {
+					return order.Order{}, fmt.Errorf("insert order: %w", order.ErrServiceNotFound)
+				}
+
 				return order.Order{}, fmt.Errorf("insert order: %w", order.ErrAccountNotFound)
 			}
 		}
@@ -65,62 +86,208 @@ func (or *OrderRepository) CreateOrder(ctx context.Context, dto order.CreateDTO)
 		return order.Order{}, fmt.Errorf("insert order: %w", err)
 	}
 
+	if err := or.createHistory(ctx, order.UpdateStatusDTO{
+		OrderID: entity.OrderID,
+		To:      entity.Status,
+		Actor:   dto.Actor,
+	}); err != nil {
+		return order.Order{}, err
+	}
+
+	return entity, nil
+}
+
+func (or *OrderRepository) GetOrderForUpdate(ctx context.Context, orderID int64) (order.Order, error) {
+	sql, args, err := or.db.Builder.
+		Select(orderColumns...).
+		From(or.tableName).
+		Where(sq.Eq{"order_id": orderID}).
+		Suffix("FOR UPDATE").
+		ToSql()
+	if err != nil {
+		return order.Order{}, fmt.Errorf("build get order for update query: %w", err)
+	}
+
+	or.logger.Debug("get order for update query", zap.String("sql", sql), zap.Any("args", args))
+
+	entity, err := scanOrder(or.db.QueryRow(ctx, sql, args...))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return order.Order{}, fmt.Errorf("get order for update: %w", order.ErrNotFound)
+		}
+
+		return order.Order{}, fmt.Errorf("get order for update: %w", err)
+	}
+
 	return entity, nil
 }
 
-func (or *OrderRepository) PayForOrder(ctx context.Context, dto order.PayForDTO) error { //nolint:dupl
+func (or *OrderRepository) GetExpiredOrdersForUpdate(ctx context.Context, limit uint64) ([]order.Order, error) {
+	sql, args, err := or.db.Builder.
+		Select(orderColumns...).
+		From(or.tableName).
+		Where(sq.And{
+			sq.Eq{"status": order.Reserved},
+			sq.Expr("expires_at <= now()"),
+		}).
+		OrderBy("expires_at").
+		Limit(limit).
+		Suffix("FOR UPDATE SKIP LOCKED").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build get expired orders for update query: %w", err)
+	}
+
+	or.logger.Debug("get expired orders for update query", zap.String("sql", sql), zap.Any("args", args))
+
+	rows, err := or.db.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("run get expired orders for update query: %w", err)
+	}
+	defer rows.Close()
+
+	var orders []order.Order
+	for rows.Next() {
+		entity, err := scanOrder(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan expired order: %w", err)
+		}
+
+		orders = append(orders, entity)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("read all expired orders: %w", err)
+	}
+
+	return orders, nil
+}
+
+func (or *OrderRepository) UpdateStatus(ctx context.Context, dto order.UpdateStatusDTO) error {
 	sql, args, err := or.db.Builder.Update(or.tableName).
-		Set("is_paid", true).
+		Set("status", dto.To).
 		Where(sq.And{
 			sq.Eq{"order_id": dto.OrderID},
-			sq.Eq{"account_id": dto.AccountID},
-			sq.Eq{"service_id": dto.ServiceID},
-			sq.Eq{"amount": dto.Amount},
-			sq.Eq{"is_paid": false},
-			sq.Eq{"is_cancelled": false},
+			sq.Eq{"status": dto.From},
 		}).
 		ToSql()
 	if err != nil {
-		return fmt.Errorf("build pay for order query: %w", err)
+		return fmt.Errorf("build update order status query: %w", err)
 	}
 
-	or.logger.Debug("pay for order query", zap.String("sql", sql), zap.Any("args", args))
+	or.logger.Debug("update order status query", zap.String("sql", sql), zap.Any("args", args))
 
 	result, err := or.db.Exec(ctx, sql, args...)
 	if err != nil {
-		return fmt.Errorf("pay for order: %w", err)
+		return fmt.Errorf("update order status: %w", err)
 	}
 	if result.RowsAffected() == 0 {
-		return fmt.Errorf("pay for order: %w", order.ErrNotFound)
+		return fmt.Errorf("update order status: %w", order.ErrNotFound)
+	}
+
+	return or.createHistory(ctx, dto)
+}
+
+func (or *OrderRepository) SetCapturedAmount(ctx context.Context, dto order.SetCapturedAmountDTO) error {
+	sql, args, err := or.db.Builder.Update(or.tableName).
+		Set("captured_amount", dto.Amount).
+		Where(sq.Eq{"order_id": dto.OrderID}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("build set order captured amount query: %w", err)
+	}
+
+	or.logger.Debug("set order captured amount query", zap.String("sql", sql), zap.Any("args", args))
+
+	result, err := or.db.Exec(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("set order captured amount: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("set order captured amount: %w", order.ErrNotFound)
 	}
 
 	return nil
 }
 
-func (or *OrderRepository) CancelOrder(ctx context.Context, dto order.CancelDTO) error { //nolint:dupl
+func (or *OrderRepository) AddRefund(ctx context.Context, dto order.AddRefundDTO) (int64, error) {
 	sql, args, err := or.db.Builder.Update(or.tableName).
-		Set("is_cancelled", true).
+		Set("refunded_amount", sq.Expr("refunded_amount + ?", dto.Amount)).
 		Where(sq.And{
 			sq.Eq{"order_id": dto.OrderID},
-			sq.Eq{"account_id": dto.AccountID},
-			sq.Eq{"service_id": dto.ServiceID},
-			sq.Eq{"amount": dto.Amount},
-			sq.Eq{"is_paid": false},
-			sq.Eq{"is_cancelled": false},
+			sq.Expr("refunded_amount + ? <= captured_amount", dto.Amount),
 		}).
+		Suffix("RETURNING refunded_amount").
 		ToSql()
 	if err != nil {
-		return fmt.Errorf("build cancel order query: %w", err)
+		return 0, fmt.Errorf("build add order refund query: %w", err)
 	}
 
-	or.logger.Debug("cancel order query", zap.String("sql", sql), zap.Any("args", args))
+	or.logger.Debug("add order refund query", zap.String("sql", sql), zap.Any("args", args))
 
-	result, err := or.db.Exec(ctx, sql, args...)
+	var refundedAmount int64
+	if err := or.db.QueryRow(ctx, sql, args...).Scan(&refundedAmount); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, fmt.Errorf("add order refund: %w", order.ErrRefundExceedsPaid)
+		}
+
+		return 0, fmt.Errorf("add order refund: %w", err)
+	}
+
+	return refundedAmount, nil
+}
+
+var orderColumns = []string{
+	"order_id",
+	"account_id",
+	"service_id",
+	"amount",
+	"currency",
+	"captured_amount",
+	"refunded_amount",
+	"status",
+	"expires_at",
+	"created_at",
+	"updated_at",
+}
+
+func scanOrder(row pgx.Row) (order.Order, error) {
+	var entity order.Order
+	err := row.Scan(
+		&entity.OrderID,
+		&entity.AccountID,
+		&entity.ServiceID,
+		&entity.Amount,
+		&entity.Currency,
+		&entity.CapturedAmount,
+		&entity.RefundedAmount,
+		&entity.Status,
+		&entity.ExpiresAt,
+		&entity.CreatedAt,
+		&entity.UpdatedAt,
+	)
+
+	return entity, err
+}
+
+func (or *OrderRepository) createHistory(ctx context.Context, dto order.UpdateStatusDTO) error {
+	var from interface{}
+	if dto.From != (order.Status{}) {
+		from = dto.From
+	}
+
+	sql, args, err := or.db.Builder.Insert(or.historyTableName).
+		Columns("order_id", "from_status", "to_status", "actor", "reason").
+		Values(dto.OrderID, from, dto.To, dto.Actor, dto.Reason).
+		ToSql()
 	if err != nil {
-		return fmt.Errorf("cancel order: %w", err)
+		return fmt.Errorf("build create order status history query: %w", err)
 	}
-	if result.RowsAffected() == 0 {
-		return fmt.Errorf("cancel order: %w", order.ErrNotFound)
+
+	or.logger.Debug("create order status history query", zap.String("sql", sql), zap.Any("args", args))
+
+	if _, err := or.db.Exec(ctx, sql, args...); err != nil {
+		return fmt.Errorf("insert order status history: %w", err)
 	}
 
 	return nil
@@ -0,0 +1,171 @@
+package psql
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/maypok86/payment-api/internal/domain/outbox"
+	"github.com/maypok86/payment-api/internal/pkg/postgres"
+	"go.uber.org/zap"
+)
+
+type OutboxRepository struct {
+	tableName string
+	db        *postgres.Client
+	logger    *zap.Logger
+}
+
+func NewOutboxRepository(db *postgres.Client, logger *zap.Logger) *OutboxRepository {
+	return &OutboxRepository{
+		tableName: "outbox",
+		db:        db,
+		logger:    logger,
+	}
+}
+
+func (or *OutboxRepository) CreateEvent(ctx context.Context, dto outbox.CreateDTO) error {
+	payload, err := json.Marshal(dto.Payload)
+	if err != nil {
+		return fmt.Errorf("marshal event payload: %w", err)
+	}
+
+	sql, args, err := or.db.Builder.Insert(or.tableName).
+		Columns("type", "aggregate_type", "aggregate_id", "payload").
+		Values(dto.Type, dto.AggregateType, dto.AggregateID, sq.Expr("?::jsonb", string(payload))).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("build create event query: %w", err)
+	}
+
+	or.logger.Debug("create event query", zap.String("sql", sql), zap.Any("args", args))
+
+	if _, err := or.db.Exec(ctx, sql, args...); err != nil {
+		return fmt.Errorf("insert event: %w", err)
+	}
+
+	return nil
+}
+
+// ClaimEvents takes the oldest pending event of each aggregate that is due for delivery, up to limit events, and
+// postpones their next attempt by timeout. The events are claimed in a single statement, so concurrent relays
+// never claim the same event, and no lock is held once the statement is committed.
+func (or *OutboxRepository) ClaimEvents(
+	ctx context.Context,
+	limit uint64,
+	timeout time.Duration,
+) ([]outbox.Event, error) {
+	sql, args, err := or.db.Builder.Update(or.tableName).
+		Set("next_attempt_at", sq.Expr("now() + make_interval(secs => ?)", timeout.Seconds())).
+		Where(sq.Expr(
+			"event_id IN (?)",
+			sq.Select("o.event_id").
+				From(or.tableName+" AS o").
+				Where(sq.And{
+					sq.Expr("o.published_at IS NULL"),
+					sq.Expr("o.next_attempt_at <= now()"),
+					sq.Expr(
+						"NOT EXISTS (SELECT 1 FROM " + or.tableName + " AS p WHERE p.aggregate_type = o.aggregate_type " +
+							"AND p.aggregate_id = o.aggregate_id AND p.published_at IS NULL AND p.event_id < o.event_id)",
+					),
+				}).
+				OrderBy("o.event_id").
+				Limit(limit).
+				Suffix("FOR UPDATE SKIP LOCKED"),
+		)).
+		Suffix("RETURNING event_id, type, aggregate_type, aggregate_id, payload::text, attempts, created_at").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build claim events query: %w", err)
+	}
+
+	or.logger.Debug("claim events query", zap.String("sql", sql), zap.Any("args", args))
+
+	rows, err := or.db.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("run claim events query: %w", err)
+	}
+	defer rows.Close()
+
+	var events []outbox.Event
+	for rows.Next() {
+		var (
+			event   outbox.Event
+			payload string
+		)
+		if err := rows.Scan(
+			&event.EventID,
+			&event.Type,
+			&event.AggregateType,
+			&event.AggregateID,
+			&payload,
+			&event.Attempts,
+			&event.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("scan claimed event: %w", err)
+		}
+
+		event.Payload = json.RawMessage(payload)
+		events = append(events, event)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("read all claimed events: %w", err)
+	}
+
+	// RETURNING does not keep the order of the subquery.
+	sort.Slice(events, func(i, j int) bool {
+		return events[i].EventID < events[j].EventID
+	})
+
+	return events, nil
+}
+
+func (or *OutboxRepository) MarkPublished(ctx context.Context, eventID int64) error {
+	sql, args, err := or.db.Builder.Update(or.tableName).
+		Set("published_at", sq.Expr("now()")).
+		Set("attempts", sq.Expr("attempts + 1")).
+		Set("last_error", nil).
+		Where(sq.And{
+			sq.Eq{"event_id": eventID},
+			sq.Expr("published_at IS NULL"),
+		}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("build mark event published query: %w", err)
+	}
+
+	or.logger.Debug("mark event published query", zap.String("sql", sql), zap.Any("args", args))
+
+	if _, err := or.db.Exec(ctx, sql, args...); err != nil {
+		return fmt.Errorf("mark event published: %w", err)
+	}
+
+	return nil
+}
+
+func (or *OutboxRepository) MarkFailed(ctx context.Context, dto outbox.MarkFailedDTO) error {
+	sql, args, err := or.db.Builder.Update(or.tableName).
+		Set("attempts", sq.Expr("attempts + 1")).
+		Set("last_error", dto.Error).
+		Set("next_attempt_at", sq.Expr("now() + make_interval(secs => ?)", dto.RetryAfter.Seconds())).
+		Where(sq.And{
+			sq.Eq{"event_id": dto.EventID},
+			sq.Expr("published_at IS NULL"),
+		}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("build mark event failed query: %w", err)
+	}
+
+	or.logger.Debug("mark event failed query", zap.String("sql", sql), zap.Any("args", args))
+
+	if _, err := or.db.Exec(ctx, sql, args...); err != nil {
+		return fmt.Errorf("mark event failed: %w", err)
+	}
+
+	return nil
+}
@@ -2,35 +2,66 @@ package psql
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"strings"
+	"time"
 
 	sq "github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v4"
+	"github.com/maypok86/payment-api/internal/domain/order"
 	"github.com/maypok86/payment-api/internal/domain/report"
 	"github.com/maypok86/payment-api/internal/pkg/postgres"
 	"go.uber.org/zap"
 )
 
 type ReportRepository struct {
-	tableName string
-	db        *postgres.Client
-	logger    *zap.Logger
+	tableName         string
+	serviceTableName  string
+	jobTableName      string
+	snapshotTableName string
+	db                *postgres.Client
+	logger            *zap.Logger
 }
 
 func NewReportRepository(db *postgres.Client, logger *zap.Logger) *ReportRepository {
 	return &ReportRepository{
-		tableName: "orders",
-		db:        db,
-		logger:    logger,
+		tableName:         "orders",
+		serviceTableName:  "services",
+		jobTableName:      "report_jobs",
+		snapshotTableName: "report_snapshots",
+		db:                db,
+		logger:            logger,
 	}
 }
 
-func (rr *ReportRepository) GetReportMap(ctx context.Context, dto report.GetMapDTO) (map[int64]int64, error) {
-	sql, args, err := rr.db.Builder.Select("service_id", "SUM(amount)").From(rr.tableName).Where(sq.And{
-		sq.Eq{"is_paid": true},
-		sq.Eq{"is_cancelled": false},
-		sq.Eq{"DATE_PART('year', created_at)": dto.Year},
-		sq.Eq{"DATE_PART('month', created_at)": dto.Month},
-	}).GroupBy("service_id").ToSql()
+var jobColumns = []string{
+	"job_id",
+	"month",
+	"year",
+	"format",
+	"principal",
+	"status",
+	"report_key",
+	"error",
+	"created_at",
+	"updated_at",
+}
+
+func (rr *ReportRepository) GetReportMap(ctx context.Context, dto report.GetMapDTO) (map[report.Row]int64, error) {
+	from, to := dto.Period()
+	sql, args, err := rr.db.Builder.
+		Select("o.service_id", "s.name", "o.currency", "SUM(o.captured_amount - o.refunded_amount)").
+		From(rr.tableName+" AS o").
+		Join(rr.serviceTableName+" AS s USING (service_id)").
+		Where(sq.And{
+			sq.Eq{"o.status": []order.Status{order.Paid, order.Refunded}},
+			sq.GtOrEq{"o.created_at": from},
+			sq.Lt{"o.created_at": to},
+		}).
+		GroupBy("o.service_id", "s.name", "o.currency").
+		ToSql()
 	if err != nil {
 		return nil, fmt.Errorf("build get report query: %w", err)
 	}
@@ -41,16 +72,17 @@ func (rr *ReportRepository) GetReportMap(ctx context.Context, dto report.GetMapD
 	if err != nil {
 		return nil, fmt.Errorf("run get report query: %w", err)
 	}
+	defer rows.Close()
 
-	reportMap := make(map[int64]int64)
+	reportMap := make(map[report.Row]int64)
 	for rows.Next() {
-		var serviceID int64
+		var row report.Row
 		var amount int64
-		if err := rows.Scan(&serviceID, &amount); err != nil {
+		if err := rows.Scan(&row.ServiceID, &row.ServiceName, &row.Currency, &amount); err != nil {
 			return nil, fmt.Errorf("scan report row: %w", err)
 		}
 
-		reportMap[serviceID] = amount
+		reportMap[row] = amount
 	}
 
 	if err := rows.Err(); err != nil {
@@ -59,3 +91,254 @@ func (rr *ReportRepository) GetReportMap(ctx context.Context, dto report.GetMapD
 
 	return reportMap, nil
 }
+
+// HasUnfinishedOrders reports whether an order created in the month can still be paid.
+func (rr *ReportRepository) HasUnfinishedOrders(ctx context.Context, dto report.GetMapDTO) (bool, error) {
+	from, to := dto.Period()
+	sql, args, err := rr.db.Builder.
+		Select("1").
+		From(rr.tableName).
+		Where(sq.And{
+			sq.Eq{"status": []order.Status{order.Created, order.Reserved}},
+			sq.GtOrEq{"created_at": from},
+			sq.Lt{"created_at": to},
+		}).
+		Prefix("SELECT EXISTS (").
+		Suffix(")").
+		ToSql()
+	if err != nil {
+		return false, fmt.Errorf("build has unfinished orders query: %w", err)
+	}
+
+	rr.logger.Debug("has unfinished orders query", zap.String("sql", sql), zap.Any("args", args))
+
+	var hasUnfinished bool
+	if err := rr.db.QueryRow(ctx, sql, args...).Scan(&hasUnfinished); err != nil {
+		return false, fmt.Errorf("has unfinished orders: %w", err)
+	}
+
+	return hasUnfinished, nil
+}
+
+func (rr *ReportRepository) CreateJob(ctx context.Context, dto report.CreateJobDTO) (report.Job, error) {
+	sql, args, err := rr.db.Builder.Insert(rr.jobTableName).
+		Columns("month", "year", "format", "principal").
+		Values(dto.Month, dto.Year, dto.Format, dto.Principal).
+		Suffix("RETURNING " + strings.Join(jobColumns, ", ")).
+		ToSql()
+	if err != nil {
+		return report.Job{}, fmt.Errorf("build create job query: %w", err)
+	}
+
+	rr.logger.Debug("create job query", zap.String("sql", sql), zap.Any("args", args))
+
+	job, err := scanJob(rr.db.QueryRow(ctx, sql, args...))
+	if err != nil {
+		return report.Job{}, fmt.Errorf("create job: %w", err)
+	}
+
+	return job, nil
+}
+
+func (rr *ReportRepository) GetJobByID(ctx context.Context, jobID int64) (report.Job, error) {
+	sql, args, err := rr.db.Builder.
+		Select(jobColumns...).
+		From(rr.jobTableName).
+		Where(sq.Eq{"job_id": jobID}).
+		ToSql()
+	if err != nil {
+		return report.Job{}, fmt.Errorf("build get job by id query: %w", err)
+	}
+
+	rr.logger.Debug("get job by id query", zap.String("sql", sql), zap.Any("args", args))
+
+	job, err := scanJob(rr.db.QueryRow(ctx, sql, args...))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return report.Job{}, fmt.Errorf("get job by id: %w", report.ErrJobNotFound)
+		}
+
+		return report.Job{}, fmt.Errorf("get job by id: %w", err)
+	}
+
+	return job, nil
+}
+
+// ClaimJobs marks up to limit queued jobs and jobs running longer than timeout as running and returns them.
+// The jobs are claimed in a single statement, so concurrent workers never claim the same job.
+func (rr *ReportRepository) ClaimJobs(ctx context.Context, limit uint64, timeout time.Duration) ([]report.Job, error) {
+	sql, args, err := rr.db.Builder.Update(rr.jobTableName).
+		Set("status", report.Running).
+		Set("started_at", sq.Expr("now()")).
+		Set("updated_at", sq.Expr("now()")).
+		Where(sq.Expr(
+			"job_id IN (?)",
+			sq.Select("job_id").
+				From(rr.jobTableName).
+				Where(sq.Or{
+					sq.Eq{"status": report.Queued},
+					sq.And{
+						sq.Eq{"status": report.Running},
+						sq.Expr("started_at <= now() - make_interval(secs => ?)", timeout.Seconds()),
+					},
+				}).
+				OrderBy("job_id").
+				Limit(limit).
+				Suffix("FOR UPDATE SKIP LOCKED"),
+		)).
+		Suffix("RETURNING " + strings.Join(jobColumns, ", ")).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build claim jobs query: %w", err)
+	}
+
+	rr.logger.Debug("claim jobs query", zap.String("sql", sql), zap.Any("args", args))
+
+	rows, err := rr.db.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("run claim jobs query: %w", err)
+	}
+	defer rows.Close()
+
+	var jobs []report.Job
+	for rows.Next() {
+		job, err := scanJob(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		jobs = append(jobs, job)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("read all claimed jobs: %w", err)
+	}
+
+	return jobs, nil
+}
+
+func (rr *ReportRepository) SaveJobResult(ctx context.Context, dto report.SaveJobResultDTO) error {
+	var reportKey, jobError *string
+	if dto.Key != "" {
+		reportKey = &dto.Key
+	}
+	if dto.Error != "" {
+		jobError = &dto.Error
+	}
+
+	sql, args, err := rr.db.Builder.Update(rr.jobTableName).
+		Set("status", dto.Status).
+		Set("report_key", reportKey).
+		Set("error", jobError).
+		Set("updated_at", sq.Expr("now()")).
+		Where(sq.Eq{"job_id": dto.JobID}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("build save job result query: %w", err)
+	}
+
+	rr.logger.Debug("save job result query", zap.String("sql", sql), zap.Any("args", args))
+
+	if _, err := rr.db.Exec(ctx, sql, args...); err != nil {
+		return fmt.Errorf("save job result: %w", err)
+	}
+
+	return nil
+}
+
+func (rr *ReportRepository) GetSnapshot(ctx context.Context, month, year int64) (report.Snapshot, error) {
+	sql, args, err := rr.db.Builder.
+		Select("entries::text", "checksum", "generated_at").
+		From(rr.snapshotTableName).
+		Where(sq.Eq{
+			"month": month,
+			"year":  year,
+		}).
+		ToSql()
+	if err != nil {
+		return report.Snapshot{}, fmt.Errorf("build get snapshot query: %w", err)
+	}
+
+	rr.logger.Debug("get snapshot query", zap.String("sql", sql), zap.Any("args", args))
+
+	snapshot := report.Snapshot{
+		Month: month,
+		Year:  year,
+	}
+	var entries string
+	if err := rr.db.QueryRow(ctx, sql, args...).Scan(&entries, &snapshot.Checksum, &snapshot.GeneratedAt); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return report.Snapshot{}, fmt.Errorf("get snapshot: %w", report.ErrSnapshotNotFound)
+		}
+
+		return report.Snapshot{}, fmt.Errorf("get snapshot: %w", err)
+	}
+
+	if err := json.Unmarshal([]byte(entries), &snapshot.Entries); err != nil {
+		return report.Snapshot{}, fmt.Errorf("unmarshal snapshot entries: %w", err)
+	}
+
+	return snapshot, nil
+}
+
+// SaveSnapshot keeps the snapshot already saved for the month, if there is one.
+func (rr *ReportRepository) SaveSnapshot(ctx context.Context, snapshot report.Snapshot) error {
+	entries, err := json.Marshal(snapshot.Entries)
+	if err != nil {
+		return fmt.Errorf("marshal snapshot entries: %w", err)
+	}
+
+	sql, args, err := rr.db.Builder.Insert(rr.snapshotTableName).
+		Columns("month", "year", "entries", "checksum", "generated_at").
+		Values(
+			snapshot.Month,
+			snapshot.Year,
+			sq.Expr("?::jsonb", string(entries)),
+			snapshot.Checksum,
+			snapshot.GeneratedAt,
+		).
+		Suffix("ON CONFLICT (year, month) DO NOTHING").
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("build save snapshot query: %w", err)
+	}
+
+	rr.logger.Debug("save snapshot query", zap.String("sql", sql), zap.Any("args", args))
+
+	if _, err := rr.db.Exec(ctx, sql, args...); err != nil {
+		return fmt.Errorf("save snapshot: %w", err)
+	}
+
+	return nil
+}
+
+func scanJob(row pgx.Row) (report.Job, error) {
+	var (
+		job       report.Job
+		reportKey *string
+		jobError  *string
+	)
+	if err := row.Scan(
+		&job.JobID,
+		&job.Month,
+		&job.Year,
+		&job.Format,
+		&job.Principal,
+		&job.Status,
+		&reportKey,
+		&jobError,
+		&job.CreatedAt,
+		&job.UpdatedAt,
+	); err != nil {
+		return report.Job{}, fmt.Errorf("scan job: %w", err)
+	}
+
+	if reportKey != nil {
+		job.Key = *reportKey
+	}
+	if jobError != nil {
+		job.Error = *jobError
+	}
+
+	return job, nil
+}
@@ -10,6 +10,11 @@ type Repositories struct {
 	Transaction *TransactionRepository
 	Order       *OrderRepository
 	Report      *ReportRepository
+	Idempotency *IdempotencyRepository
+	Ledger      *LedgerRepository
+	Outbox      *OutboxRepository
+	Webhook     *WebhookRepository
+	Service     *ServiceRepository
 }
 
 func NewRepositories(db *postgres.Client, logger *zap.Logger) *Repositories {
@@ -18,5 +23,10 @@ func NewRepositories(db *postgres.Client, logger *zap.Logger) *Repositories {
 		Transaction: NewTransactionRepository(db, logger),
 		Order:       NewOrderRepository(db, logger),
 		Report:      NewReportRepository(db, logger),
+		Idempotency: NewIdempotencyRepository(db, logger),
+		Ledger:      NewLedgerRepository(db, logger),
+		Outbox:      NewOutboxRepository(db, logger),
+		Webhook:     NewWebhookRepository(db, logger),
+		Service:     NewServiceRepository(db, logger),
 	}
 }
@@ -0,0 +1,211 @@
+package psql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v4"
+	"github.com/maypok86/payment-api/internal/domain/catalog"
+	"github.com/maypok86/payment-api/internal/pkg/postgres"
+	"go.uber.org/zap"
+)
+
+type ServiceRepository struct {
+	tableName string
+	db        *postgres.Client
+	logger    *zap.Logger
+}
+
+func NewServiceRepository(db *postgres.Client, logger *zap.Logger) *ServiceRepository {
+	return &ServiceRepository{
+		tableName: "services",
+		db:        db,
+		logger:    logger,
+	}
+}
+
+var serviceColumns = []string{"service_id", "name", "description", "active", "created_at", "updated_at"}
+
+func (sr *ServiceRepository) CreateService(ctx context.Context, dto catalog.CreateDTO) (catalog.Entity, error) {
+	sql, args, err := sr.db.Builder.Insert(sr.tableName).
+		Columns("service_id", "name", "description", "active").
+		Values(dto.ServiceID, dto.Name, dto.Description, dto.Active).
+		Suffix("RETURNING " + strings.Join(serviceColumns, ", ")).
+		ToSql()
+	if err != nil {
+		return catalog.Entity{}, fmt.Errorf("build create service query: %w", err)
+	}
+
+	sr.logger.Debug("create service query", zap.String("sql", sql), zap.Any("args", args))
+
+	entity, err := scanService(sr.db.QueryRow(ctx, sql, args...))
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == pgerrcode.UniqueViolation {
+			return catalog.Entity{}, fmt.Errorf("insert service: %w", catalog.ErrAlreadyExist)
+		}
+
+		return catalog.Entity{}, fmt.Errorf("insert service: %w", err)
+	}
+
+	return entity, nil
+}
+
+func (sr *ServiceRepository) GetServices(ctx context.Context) ([]catalog.Entity, error) {
+	sql, args, err := sr.db.Builder.
+		Select(serviceColumns...).
+		From(sr.tableName).
+		OrderBy("service_id").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build get services query: %w", err)
+	}
+
+	sr.logger.Debug("get services query", zap.String("sql", sql), zap.Any("args", args))
+
+	rows, err := sr.db.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("run get services query: %w", err)
+	}
+	defer rows.Close()
+
+	var entities []catalog.Entity
+	for rows.Next() {
+		entity, err := scanService(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		entities = append(entities, entity)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("read all services: %w", err)
+	}
+
+	return entities, nil
+}
+
+func (sr *ServiceRepository) GetServiceByID(ctx context.Context, serviceID int64) (catalog.Entity, error) {
+	sql, args, err := sr.db.Builder.
+		Select(serviceColumns...).
+		From(sr.tableName).
+		Where(sq.Eq{"service_id": serviceID}).
+		ToSql()
+	if err != nil {
+		return catalog.Entity{}, fmt.Errorf("build get service by id query: %w", err)
+	}
+
+	sr.logger.Debug("get service by id query", zap.String("sql", sql), zap.Any("args", args))
+
+	entity, err := scanService(sr.db.QueryRow(ctx, sql, args...))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return catalog.Entity{}, fmt.Errorf("get service by id: %w", catalog.ErrNotFound)
+		}
+
+		return catalog.Entity{}, fmt.Errorf("get service by id: %w", err)
+	}
+
+	return entity, nil
+}
+
+// GetServiceForShare locks the service against updates and deletion until the end of the transaction.
+func (sr *ServiceRepository) GetServiceForShare(ctx context.Context, serviceID int64) (catalog.Entity, error) {
+	sql, args, err := sr.db.Builder.
+		Select(serviceColumns...).
+		From(sr.tableName).
+		Where(sq.Eq{"service_id": serviceID}).
+		Suffix("FOR SHARE").
+		ToSql()
+	if err != nil {
+		return catalog.Entity{}, fmt.Errorf("build get service for share query: %w", err)
+	}
+
+	sr.logger.Debug("get service for share query", zap.String("sql", sql), zap.Any("args", args))
+
+	entity, err := scanService(sr.db.QueryRow(ctx, sql, args...))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return catalog.Entity{}, fmt.Errorf("get service for share: %w", catalog.ErrNotFound)
+		}
+
+		return catalog.Entity{}, fmt.Errorf("get service for share: %w", err)
+	}
+
+	return entity, nil
+}
+
+func (sr *ServiceRepository) UpdateService(ctx context.Context, dto catalog.UpdateDTO) (catalog.Entity, error) {
+	sql, args, err := sr.db.Builder.Update(sr.tableName).
+		Set("name", dto.Name).
+		Set("description", dto.Description).
+		Set("active", dto.Active).
+		Set("updated_at", sq.Expr("now()")).
+		Where(sq.Eq{"service_id": dto.ServiceID}).
+		Suffix("RETURNING " + strings.Join(serviceColumns, ", ")).
+		ToSql()
+	if err != nil {
+		return catalog.Entity{}, fmt.Errorf("build update service query: %w", err)
+	}
+
+	sr.logger.Debug("update service query", zap.String("sql", sql), zap.Any("args", args))
+
+	entity, err := scanService(sr.db.QueryRow(ctx, sql, args...))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return catalog.Entity{}, fmt.Errorf("update service: %w", catalog.ErrNotFound)
+		}
+
+		return catalog.Entity{}, fmt.Errorf("update service: %w", err)
+	}
+
+	return entity, nil
+}
+
+func (sr *ServiceRepository) DeleteService(ctx context.Context, serviceID int64) error {
+	sql, args, err := sr.db.Builder.Delete(sr.tableName).
+		Where(sq.Eq{"service_id": serviceID}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("build delete service query: %w", err)
+	}
+
+	sr.logger.Debug("delete service query", zap.String("sql", sql), zap.Any("args", args))
+
+	result, err := sr.db.Exec(ctx, sql, args...)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == pgerrcode.ForeignKeyViolation {
+			return fmt.Errorf("delete service: %w", catalog.ErrHasOrders)
+		}
+
+		return fmt.Errorf("delete service: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("delete service: %w", catalog.ErrNotFound)
+	}
+
+	return nil
+}
+
+func scanService(row pgx.Row) (catalog.Entity, error) {
+	var entity catalog.Entity
+	if err := row.Scan(
+		&entity.ServiceID,
+		&entity.Name,
+		&entity.Description,
+		&entity.Active,
+		&entity.CreatedAt,
+		&entity.UpdatedAt,
+	); err != nil {
+		return catalog.Entity{}, fmt.Errorf("scan service: %w", err)
+	}
+
+	return entity, nil
+}
@@ -4,12 +4,14 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
 
 	sq "github.com/Masterminds/squirrel"
 	"github.com/jackc/pgconn"
 	"github.com/jackc/pgerrcode"
 	"github.com/maypok86/payment-api/internal/domain/transaction"
 	"github.com/maypok86/payment-api/internal/pkg/postgres"
+	"github.com/maypok86/payment-api/internal/pkg/sort"
 	"go.uber.org/zap"
 )
 
@@ -28,9 +30,39 @@ func NewTransactionRepository(db *postgres.Client, logger *zap.Logger) *Transact
 }
 
 func (tr *TransactionRepository) CreateTransaction(ctx context.Context, dto transaction.CreateDTO) error {
+	var rate, convertedAmount, convertedCurrency, roundingRemainder interface{}
+	if dto.Conversion != nil {
+		rate = dto.Conversion.Rate
+		convertedAmount = dto.Conversion.Amount
+		convertedCurrency = dto.Conversion.Currency
+		roundingRemainder = dto.Conversion.Remainder
+	}
+
 	sql, args, err := tr.db.Builder.Insert(tr.tableName).
-		Columns("type", "sender_id", "receiver_id", "amount", "description").
-		Values(dto.Type, dto.SenderID, dto.ReceiverID, dto.Amount, dto.Description).
+		Columns(
+			"type",
+			"sender_id",
+			"receiver_id",
+			"amount",
+			"currency",
+			"rate",
+			"converted_amount",
+			"converted_currency",
+			"rounding_remainder",
+			"description",
+		).
+		Values(
+			dto.Type,
+			dto.SenderID,
+			dto.ReceiverID,
+			dto.Amount,
+			dto.Currency,
+			rate,
+			convertedAmount,
+			convertedCurrency,
+			roundingRemainder,
+			dto.Description,
+		).
 		ToSql()
 	if err != nil {
 		return fmt.Errorf("build create transaction query: %w", err)
@@ -61,65 +93,411 @@ func (tr *TransactionRepository) CreateTransaction(ctx context.Context, dto tran
 	return nil
 }
 
+var transactionColumns = []string{
+	"transaction_id",
+	"type",
+	"sender_id",
+	"receiver_id",
+	"amount",
+	"currency",
+	"rate::text",
+	"converted_amount",
+	"converted_currency",
+	"rounding_remainder::text",
+	"description",
+	"created_at",
+}
+
+var cursorCasts = map[string]string{
+	"created_at": "timestamptz",
+	"amount":     "bigint",
+}
+
 func (tr *TransactionRepository) GetTransactionsByAccountID(
 	ctx context.Context,
 	accountID int64,
 	listParams transaction.ListParams,
-) ([]transaction.Transaction, int, error) {
-	query := tr.db.Builder.Select(
-		"transaction_id",
-		"type",
-		"sender_id",
-		"receiver_id",
-		"amount",
-		"description",
-		"created_at", "COUNT(*) OVER () AS total").
+) (transaction.List, error) {
+	if listParams.Cursor != nil {
+		return tr.getTransactionsByCursor(ctx, accountID, listParams)
+	}
+
+	query := tr.db.Builder.Select(append(transactionColumns, "COUNT(*) OVER () AS total")...).
 		From(tr.tableName).
 		Where(sq.Or{
 			sq.Eq{"sender_id": accountID},
 			sq.Eq{"receiver_id": accountID},
-		})
+		}).
+		Where(filterPredicates(accountID, listParams.Filter)).
+		OrderBy(orderBy(listParams.SortColumn(), listParams.IsDesc())...).
+		Limit(listParams.Pagination.Limit).
+		Offset(listParams.Pagination.Offset)
 
-	if listParams.Sort != nil {
-		query = listParams.Sort.UseSelectBuilder(query)
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return transaction.List{}, fmt.Errorf("build get transactions by account id query: %w", err)
 	}
 
-	sql, args, err := query.Limit(listParams.Pagination.Limit).Offset(listParams.Pagination.Offset).ToSql()
+	tr.logger.Debug("get transactions by account id query", zap.String("sql", sql), zap.Any("args", args))
+
+	var count int
+	transactions, err := tr.queryTransactions(ctx, sql, args, &count)
 	if err != nil {
-		return nil, 0, fmt.Errorf("build get transactions by account id query: %w", err)
+		return transaction.List{}, err
 	}
 
-	tr.logger.Debug("get transactions by account id query", zap.String("sql", sql), zap.Any("args", args))
+	list := transaction.List{
+		Transactions: transactions,
+		Count:        &count,
+	}
+	if len(transactions) > 0 {
+		column := listParams.SortColumn()
+		if int(listParams.Pagination.Offset)+len(transactions) < count {
+			list.Next = transaction.CursorOf(transactions[len(transactions)-1], column, false)
+		}
+		if listParams.Pagination.Offset > 0 {
+			list.Prev = transaction.CursorOf(transactions[0], column, true)
+		}
+	}
+
+	return list, nil
+}
+
+// getTransactionsByCursor reads the page next to the cursor. Sent and received transactions are read
+// by separate branches, so each of them can walk its own index instead of scanning all the rows of the account.
+func (tr *TransactionRepository) getTransactionsByCursor(
+	ctx context.Context,
+	accountID int64,
+	listParams transaction.ListParams,
+) (transaction.List, error) {
+	cursor := listParams.Cursor
+	column := listParams.SortColumn()
+	// a backward page is read in the reversed order from the cursor and reversed back after reading.
+	desc := listParams.IsDesc() != cursor.Backward
+	operator := ">"
+	if desc {
+		operator = "<"
+	}
+	limit := listParams.Pagination.Limit + 1
+
+	after := fmt.Sprintf("(%s, transaction_id) %s (?::%s, ?)", column, operator, cursorCasts[column])
+	sent := sq.Select(transactionColumns...).
+		From(tr.tableName).
+		Where(sq.Eq{"sender_id": accountID}).
+		Where(filterPredicates(accountID, listParams.Filter)).
+		Where(after, cursor.Value, cursor.ID).
+		OrderBy(orderBy(column, desc)...).
+		Limit(limit)
+	received := sq.Select(transactionColumns...).
+		From(tr.tableName).
+		Where(sq.Eq{"receiver_id": accountID}).
+		Where(sq.NotEq{"sender_id": accountID}).
+		Where(filterPredicates(accountID, listParams.Filter)).
+		Where(after, cursor.Value, cursor.ID).
+		OrderBy(orderBy(column, desc)...).
+		Limit(limit)
+
+	sql, args, err := tr.db.Builder.Select(transactionColumns...).
+		Prefix("WITH page AS ((?) UNION ALL (?))", sent, received).
+		From("page").
+		OrderBy(orderBy(column, desc)...).
+		Limit(limit).
+		ToSql()
+	if err != nil {
+		return transaction.List{}, fmt.Errorf("build get transactions by cursor query: %w", err)
+	}
+
+	tr.logger.Debug("get transactions by cursor query", zap.String("sql", sql), zap.Any("args", args))
+
+	transactions, err := tr.queryTransactions(ctx, sql, args)
+	if err != nil {
+		return transaction.List{}, err
+	}
+
+	hasMore := len(transactions) > int(listParams.Pagination.Limit)
+	if hasMore {
+		transactions = transactions[:listParams.Pagination.Limit]
+	}
+	if cursor.Backward {
+		for i, j := 0, len(transactions)-1; i < j; i, j = i+1, j-1 {
+			transactions[i], transactions[j] = transactions[j], transactions[i]
+		}
+	}
+
+	list := transaction.List{Transactions: transactions}
+	if len(transactions) > 0 {
+		first, last := transactions[0], transactions[len(transactions)-1]
+		if !cursor.Backward || hasMore {
+			list.Prev = transaction.CursorOf(first, column, true)
+		}
+		if cursor.Backward || hasMore {
+			list.Next = transaction.CursorOf(last, column, false)
+		}
+	}
+
+	if listParams.WithCount {
+		count, err := tr.countTransactions(ctx, accountID, listParams.Filter)
+		if err != nil {
+			return transaction.List{}, err
+		}
+
+		list.Count = &count
+	}
+
+	return list, nil
+}
+
+func (tr *TransactionRepository) countTransactions(
+	ctx context.Context,
+	accountID int64,
+	filter transaction.Filter,
+) (int, error) {
+	sql, args, err := tr.db.Builder.Select("COUNT(*)").
+		From(tr.tableName).
+		Where(sq.Or{
+			sq.Eq{"sender_id": accountID},
+			sq.Eq{"receiver_id": accountID},
+		}).
+		Where(filterPredicates(accountID, filter)).
+		ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("build count transactions query: %w", err)
+	}
+
+	tr.logger.Debug("count transactions query", zap.String("sql", sql), zap.Any("args", args))
+
+	var count int
+	if err := tr.db.QueryRow(ctx, sql, args...).Scan(&count); err != nil {
+		return 0, fmt.Errorf("count transactions: %w", err)
+	}
+
+	return count, nil
+}
+
+// queryTransactions runs the query and scans its rows, extra holds the destinations of the columns after
+// the transaction columns.
+func (tr *TransactionRepository) queryTransactions(
+	ctx context.Context,
+	sql string,
+	args []interface{},
+	extra ...interface{},
+) ([]transaction.Transaction, error) {
+	var entities []transaction.Transaction
+	if err := tr.forEachTransaction(ctx, sql, args, func(entity transaction.Transaction) error {
+		entities = append(entities, entity)
+		return nil
+	}, extra...); err != nil {
+		return nil, err
+	}
 
+	return entities, nil
+}
+
+// forEachTransaction runs the query and calls fn for every row as soon as it is read, extra is scanned
+// the same way as in queryTransactions before fn is called.
+func (tr *TransactionRepository) forEachTransaction(
+	ctx context.Context,
+	sql string,
+	args []interface{},
+	fn func(entity transaction.Transaction) error,
+	extra ...interface{},
+) error {
 	rows, err := tr.db.Query(ctx, sql, args...)
 	if err != nil {
-		return nil, 0, fmt.Errorf("run get transactions by account id query: %w", err)
+		return fmt.Errorf("run get transactions query: %w", err)
 	}
 	defer rows.Close()
 
-	var entities []transaction.Transaction
-	var count int
 	for rows.Next() {
 		var entity transaction.Transaction
-		if err := rows.Scan(
+		var rate, convertedCurrency, roundingRemainder *string
+		var convertedAmount *int64
+		dest := []interface{}{
 			&entity.TransactionID,
 			&entity.Type,
 			&entity.SenderID,
 			&entity.ReceiverID,
 			&entity.Amount,
+			&entity.Currency,
+			&rate,
+			&convertedAmount,
+			&convertedCurrency,
+			&roundingRemainder,
 			&entity.Description,
 			&entity.CreatedAt,
-			&count,
-		); err != nil {
-			return nil, 0, fmt.Errorf("scan transaction: %w", err)
+		}
+		if err := rows.Scan(append(dest, extra...)...); err != nil {
+			return fmt.Errorf("scan transaction: %w", err)
 		}
 
-		entities = append(entities, entity)
+		if rate != nil && convertedAmount != nil && convertedCurrency != nil && roundingRemainder != nil {
+			conversion := transaction.Conversion{
+				Rate:      *rate,
+				Amount:    *convertedAmount,
+				Remainder: *roundingRemainder,
+			}
+			if err := conversion.Currency.Scan(*convertedCurrency); err != nil {
+				return fmt.Errorf("scan transaction converted currency: %w", err)
+			}
+
+			entity.Conversion = &conversion
+		}
+
+		if err := fn(entity); err != nil {
+			return err
+		}
 	}
 
 	if err := rows.Err(); err != nil {
-		return nil, 0, fmt.Errorf("read all transactions: %w", err)
+		return fmt.Errorf("read all transactions: %w", err)
+	}
+
+	return nil
+}
+
+// balanceChange is the change of the available balance of the account in the currency made by a transaction.
+// A transfer takes the amount from the sender and gives the converted amount to the receiver, a reservation
+// takes the amount and a payment only spends money which is already reserved. The rest give the amount back.
+const balanceChange = `CASE type
+	WHEN 'transfer' THEN
+		(CASE WHEN receiver_id = ? AND COALESCE(converted_currency, currency) = ?
+			THEN COALESCE(converted_amount, amount) ELSE 0 END) -
+		(CASE WHEN sender_id = ? AND currency = ? THEN amount ELSE 0 END)
+	WHEN 'reservation' THEN -amount
+	WHEN 'payment' THEN 0
+	ELSE amount
+END`
+
+func (tr *TransactionRepository) statementQuery(dto transaction.StatementDTO, columns ...string) sq.SelectBuilder {
+	return tr.db.Builder.Select(columns...).
+		From(tr.tableName).
+		Where(sq.Or{
+			sq.Eq{"sender_id": dto.AccountID},
+			sq.Eq{"receiver_id": dto.AccountID},
+		}).
+		Where(sq.Or{
+			sq.Eq{"currency": dto.Currency},
+			sq.Eq{"converted_currency": dto.Currency},
+		})
+}
+
+func (tr *TransactionRepository) GetOpeningBalance(ctx context.Context, dto transaction.StatementDTO) (int64, error) {
+	if dto.From == nil {
+		return 0, nil
+	}
+
+	sql, args, err := tr.statementQuery(dto).
+		Column(sq.Expr(
+			"COALESCE(SUM("+balanceChange+"), 0)",
+			dto.AccountID, dto.Currency, dto.AccountID, dto.Currency,
+		)).
+		Where(sq.Lt{"created_at": *dto.From}).
+		ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("build get opening balance query: %w", err)
+	}
+
+	tr.logger.Debug("get opening balance query", zap.String("sql", sql), zap.Any("args", args))
+
+	var balance int64
+	if err := tr.db.QueryRow(ctx, sql, args...).Scan(&balance); err != nil {
+		return 0, fmt.Errorf("get opening balance: %w", err)
+	}
+
+	return balance, nil
+}
+
+func (tr *TransactionRepository) ForEachStatementRow(
+	ctx context.Context,
+	dto transaction.StatementDTO,
+	fn func(row transaction.StatementRow) error,
+) error {
+	query := tr.statementQuery(dto, transactionColumns...).
+		Column(sq.Expr(balanceChange, dto.AccountID, dto.Currency, dto.AccountID, dto.Currency)).
+		OrderBy(orderBy("created_at", false)...)
+	if dto.From != nil {
+		query = query.Where(sq.GtOrEq{"created_at": *dto.From})
+	}
+	if dto.To != nil {
+		query = query.Where(sq.Lt{"created_at": *dto.To})
+	}
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		return fmt.Errorf("build get statement rows query: %w", err)
+	}
+
+	tr.logger.Debug("get statement rows query", zap.String("sql", sql), zap.Any("args", args))
+
+	var change int64
+	return tr.forEachTransaction(ctx, sql, args, func(entity transaction.Transaction) error {
+		return fn(transaction.StatementRow{
+			Transaction: entity,
+			Change:      change,
+		})
+	}, &change)
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// filterPredicates returns the predicates of the filter for the transactions of the account.
+func filterPredicates(accountID int64, filter transaction.Filter) sq.And {
+	predicates := sq.And{}
+
+	if len(filter.Types) > 0 {
+		predicates = append(predicates, sq.Eq{"type": filter.Types})
+	}
+
+	// a transaction of the account with itself moves money in or out depending on its type.
+	switch filter.Flow {
+	case transaction.Incoming:
+		predicates = append(predicates, sq.Or{
+			sq.And{sq.Eq{"receiver_id": accountID}, sq.NotEq{"sender_id": accountID}},
+			sq.And{
+				sq.Eq{"sender_id": accountID},
+				sq.Eq{"receiver_id": accountID},
+				sq.Eq{"type": transaction.IncomingTypes},
+			},
+		})
+	case transaction.Outgoing:
+		predicates = append(predicates, sq.Eq{"sender_id": accountID}, sq.Or{
+			sq.NotEq{"receiver_id": accountID},
+			sq.NotEq{"type": transaction.IncomingTypes},
+		})
+	}
+
+	if filter.From != nil {
+		predicates = append(predicates, sq.GtOrEq{"created_at": *filter.From})
+	}
+	if filter.To != nil {
+		predicates = append(predicates, sq.Lt{"created_at": *filter.To})
+	}
+	if filter.MinAmount != nil {
+		predicates = append(predicates, sq.GtOrEq{"amount": *filter.MinAmount})
+	}
+	if filter.MaxAmount != nil {
+		predicates = append(predicates, sq.LtOrEq{"amount": *filter.MaxAmount})
+	}
+
+	if filter.CounterpartyID != 0 {
+		predicates = append(predicates, sq.Or{
+			sq.Eq{"sender_id": accountID, "receiver_id": filter.CounterpartyID},
+			sq.Eq{"sender_id": filter.CounterpartyID, "receiver_id": accountID},
+		})
+	}
+
+	if filter.Description != "" {
+		predicates = append(predicates, sq.ILike{"description": "%" + likeEscaper.Replace(filter.Description) + "%"})
+	}
+
+	return predicates
+}
+
+func orderBy(column string, desc bool) []string {
+	order := sort.Asc
+	if desc {
+		order = sort.Desc
 	}
 
-	return entities, count, nil
+	return []string{column + " " + order, "transaction_id " + order}
 }
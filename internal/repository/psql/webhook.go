@@ -0,0 +1,434 @@
+package psql
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v4"
+	"github.com/maypok86/payment-api/internal/domain/outbox"
+	"github.com/maypok86/payment-api/internal/domain/webhook"
+	"github.com/maypok86/payment-api/internal/pkg/postgres"
+	"go.uber.org/zap"
+)
+
+type WebhookRepository struct {
+	subscriptionTableName string
+	deliveryTableName     string
+	db                    *postgres.Client
+	logger                *zap.Logger
+}
+
+func NewWebhookRepository(db *postgres.Client, logger *zap.Logger) *WebhookRepository {
+	return &WebhookRepository{
+		subscriptionTableName: "webhook_subscriptions",
+		deliveryTableName:     "webhook_deliveries",
+		db:                    db,
+		logger:                logger,
+	}
+}
+
+func (wr *WebhookRepository) CreateSubscription(
+	ctx context.Context,
+	dto webhook.CreateSubscriptionDTO,
+) (webhook.Subscription, error) {
+	sql, args, err := wr.db.Builder.Insert(wr.subscriptionTableName).
+		Columns("account_id", "service_id", "url", "secret", "event_types").
+		Values(ownerID(dto.AccountID), ownerID(dto.ServiceID), dto.URL, dto.Secret, eventTypesToStrings(dto.EventTypes)).
+		Suffix("RETURNING subscription_id, created_at").
+		ToSql()
+	if err != nil {
+		return webhook.Subscription{}, fmt.Errorf("build create subscription query: %w", err)
+	}
+
+	wr.logger.Debug("create subscription query", zap.String("sql", sql), zap.Any("args", args))
+
+	subscription := webhook.Subscription{
+		AccountID:  dto.AccountID,
+		ServiceID:  dto.ServiceID,
+		URL:        dto.URL,
+		Secret:     dto.Secret,
+		EventTypes: dto.EventTypes,
+	}
+	if err := wr.db.QueryRow(ctx, sql, args...).Scan(&subscription.SubscriptionID, &subscription.CreatedAt); err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == pgerrcode.ForeignKeyViolation {
+			return webhook.Subscription{}, fmt.Errorf("create subscription: %w", webhook.ErrOwnerNotFound)
+		}
+
+		return webhook.Subscription{}, fmt.Errorf("create subscription: %w", err)
+	}
+
+	return subscription, nil
+}
+
+func (wr *WebhookRepository) GetSubscriptions(ctx context.Context) ([]webhook.Subscription, error) {
+	sql, args, err := wr.subscriptionQuery().OrderBy("subscription_id").ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build get subscriptions query: %w", err)
+	}
+
+	wr.logger.Debug("get subscriptions query", zap.String("sql", sql), zap.Any("args", args))
+
+	rows, err := wr.db.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("run get subscriptions query: %w", err)
+	}
+	defer rows.Close()
+
+	var subscriptions []webhook.Subscription
+	for rows.Next() {
+		subscription, err := scanSubscription(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		subscriptions = append(subscriptions, subscription)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("read all subscriptions: %w", err)
+	}
+
+	return subscriptions, nil
+}
+
+func (wr *WebhookRepository) GetSubscriptionByID(
+	ctx context.Context,
+	subscriptionID int64,
+) (webhook.Subscription, error) {
+	sql, args, err := wr.subscriptionQuery().Where(sq.Eq{"subscription_id": subscriptionID}).ToSql()
+	if err != nil {
+		return webhook.Subscription{}, fmt.Errorf("build get subscription by id query: %w", err)
+	}
+
+	wr.logger.Debug("get subscription by id query", zap.String("sql", sql), zap.Any("args", args))
+
+	subscription, err := scanSubscription(wr.db.QueryRow(ctx, sql, args...))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return webhook.Subscription{}, fmt.Errorf("get subscription by id: %w", webhook.ErrNotFound)
+		}
+
+		return webhook.Subscription{}, err
+	}
+
+	return subscription, nil
+}
+
+func (wr *WebhookRepository) DeleteSubscription(ctx context.Context, subscriptionID int64) error {
+	sql, args, err := wr.db.Builder.Delete(wr.subscriptionTableName).
+		Where(sq.Eq{"subscription_id": subscriptionID}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("build delete subscription query: %w", err)
+	}
+
+	wr.logger.Debug("delete subscription query", zap.String("sql", sql), zap.Any("args", args))
+
+	result, err := wr.db.Exec(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("delete subscription: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("delete subscription: %w", webhook.ErrNotFound)
+	}
+
+	return nil
+}
+
+// CreateDeliveries enqueues the event for every subscription to its type that belongs to an owner of the event
+// and returns how many deliveries were created. Subscriptions that already have a delivery of the event are
+// skipped, and subscriptions without an owner receive nothing.
+func (wr *WebhookRepository) CreateDeliveries(ctx context.Context, dto webhook.CreateDeliveriesDTO) (int, error) {
+	owners := sq.Or{sq.Eq{"account_id": dto.AccountID}}
+	if dto.ServiceID != 0 {
+		owners = append(owners, sq.Eq{"service_id": dto.ServiceID})
+	}
+
+	sql, args, err := wr.db.Builder.Insert(wr.deliveryTableName).
+		Columns("subscription_id", "event_id", "event_type", "body").
+		Select(
+			wr.db.Builder.Select("subscription_id").
+				Column("?::bigint", dto.EventID).
+				Column("?::outbox_event_type", dto.EventType).
+				Column("?::jsonb", string(dto.Body)).
+				From(wr.subscriptionTableName).
+				Where("?::text = ANY(event_types)", dto.EventType.String()).
+				Where(owners),
+		).
+		Suffix("ON CONFLICT (subscription_id, event_id) DO NOTHING").
+		ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("build create deliveries query: %w", err)
+	}
+
+	wr.logger.Debug("create deliveries query", zap.String("sql", sql), zap.Any("args", args))
+
+	result, err := wr.db.Exec(ctx, sql, args...)
+	if err != nil {
+		return 0, fmt.Errorf("create deliveries: %w", err)
+	}
+
+	return int(result.RowsAffected()), nil
+}
+
+// ClaimDeliveries takes up to limit pending deliveries that are due, postpones their next attempt by timeout and
+// returns them together with their subscriptions. The deliveries are claimed in a single statement, so concurrent
+// workers never claim the same delivery, and no lock is held once the statement is committed.
+func (wr *WebhookRepository) ClaimDeliveries(
+	ctx context.Context,
+	limit uint64,
+	timeout time.Duration,
+) ([]webhook.PendingDelivery, error) {
+	sql, args, err := wr.db.Builder.Update(wr.deliveryTableName+" AS d").
+		Set("next_attempt_at", sq.Expr("now() + make_interval(secs => ?)", timeout.Seconds())).
+		Set("updated_at", sq.Expr("now()")).
+		Where(sq.Expr(
+			"d.delivery_id IN (?)",
+			sq.Select("delivery_id").
+				From(wr.deliveryTableName).
+				Where(sq.And{
+					sq.Eq{"status": webhook.Pending},
+					sq.Expr("next_attempt_at <= now()"),
+				}).
+				OrderBy("next_attempt_at", "delivery_id").
+				Limit(limit).
+				Suffix("FOR UPDATE SKIP LOCKED"),
+		)).
+		Suffix(
+			"RETURNING d.delivery_id, d.subscription_id, d.event_id, d.event_type, d.body::text, d.attempts, " +
+				"(SELECT s.url FROM " + wr.subscriptionTableName + " AS s WHERE s.subscription_id = d.subscription_id), " +
+				"(SELECT s.secret FROM " + wr.subscriptionTableName + " AS s WHERE s.subscription_id = d.subscription_id)",
+		).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("build claim deliveries query: %w", err)
+	}
+
+	wr.logger.Debug("claim deliveries query", zap.String("sql", sql), zap.Any("args", args))
+
+	rows, err := wr.db.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("run claim deliveries query: %w", err)
+	}
+	defer rows.Close()
+
+	var deliveries []webhook.PendingDelivery
+	for rows.Next() {
+		var (
+			delivery webhook.PendingDelivery
+			body     string
+		)
+		if err := rows.Scan(
+			&delivery.DeliveryID,
+			&delivery.SubscriptionID,
+			&delivery.EventID,
+			&delivery.EventType,
+			&body,
+			&delivery.Attempts,
+			&delivery.URL,
+			&delivery.Secret,
+		); err != nil {
+			return nil, fmt.Errorf("scan claimed delivery: %w", err)
+		}
+
+		delivery.Status = webhook.Pending
+		delivery.Body = json.RawMessage(body)
+		deliveries = append(deliveries, delivery)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("read all claimed deliveries: %w", err)
+	}
+
+	return deliveries, nil
+}
+
+func (wr *WebhookRepository) SaveResult(ctx context.Context, dto webhook.SaveResultDTO) error {
+	var statusCode *int
+	if dto.StatusCode != 0 {
+		statusCode = &dto.StatusCode
+	}
+
+	var lastError *string
+	if dto.Error != "" {
+		lastError = &dto.Error
+	}
+
+	sql, args, err := wr.db.Builder.Update(wr.deliveryTableName).
+		Set("status", dto.Status).
+		Set("attempts", sq.Expr("attempts + 1")).
+		Set("last_status_code", statusCode).
+		Set("last_error", lastError).
+		Set("next_attempt_at", sq.Expr("now() + make_interval(secs => ?)", dto.RetryAfter.Seconds())).
+		Set("updated_at", sq.Expr("now()")).
+		Where(sq.Eq{"delivery_id": dto.DeliveryID, "status": webhook.Pending}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("build save delivery result query: %w", err)
+	}
+
+	wr.logger.Debug("save delivery result query", zap.String("sql", sql), zap.Any("args", args))
+
+	if _, err := wr.db.Exec(ctx, sql, args...); err != nil {
+		return fmt.Errorf("save delivery result: %w", err)
+	}
+
+	return nil
+}
+
+func (wr *WebhookRepository) GetDeliveries(
+	ctx context.Context,
+	subscriptionID int64,
+	listParams webhook.DeliveryListParams,
+) ([]webhook.Delivery, int, error) {
+	conditions := sq.And{sq.Eq{"subscription_id": subscriptionID}}
+	if listParams.Status != nil {
+		conditions = append(conditions, sq.Eq{"status": *listParams.Status})
+	}
+
+	sql, args, err := wr.db.Builder.Select(
+		"delivery_id",
+		"subscription_id",
+		"event_id",
+		"event_type",
+		"body::text",
+		"status",
+		"attempts",
+		"last_status_code",
+		"last_error",
+		"next_attempt_at",
+		"created_at",
+		"updated_at",
+		"COUNT(*) OVER () AS total").
+		From(wr.deliveryTableName).
+		Where(conditions).
+		OrderBy("delivery_id DESC").
+		Limit(listParams.Pagination.Limit).
+		Offset(listParams.Pagination.Offset).
+		ToSql()
+	if err != nil {
+		return nil, 0, fmt.Errorf("build get deliveries query: %w", err)
+	}
+
+	wr.logger.Debug("get deliveries query", zap.String("sql", sql), zap.Any("args", args))
+
+	rows, err := wr.db.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("run get deliveries query: %w", err)
+	}
+	defer rows.Close()
+
+	var deliveries []webhook.Delivery
+	var count int
+	for rows.Next() {
+		var (
+			delivery   webhook.Delivery
+			body       string
+			statusCode *int
+			lastError  *string
+		)
+		if err := rows.Scan(
+			&delivery.DeliveryID,
+			&delivery.SubscriptionID,
+			&delivery.EventID,
+			&delivery.EventType,
+			&body,
+			&delivery.Status,
+			&delivery.Attempts,
+			&statusCode,
+			&lastError,
+			&delivery.NextAttemptAt,
+			&delivery.CreatedAt,
+			&delivery.UpdatedAt,
+			&count,
+		); err != nil {
+			return nil, 0, fmt.Errorf("scan delivery: %w", err)
+		}
+
+		delivery.Body = json.RawMessage(body)
+		if statusCode != nil {
+			delivery.LastStatusCode = *statusCode
+		}
+		if lastError != nil {
+			delivery.LastError = *lastError
+		}
+
+		deliveries = append(deliveries, delivery)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("read all deliveries: %w", err)
+	}
+
+	return deliveries, count, nil
+}
+
+func (wr *WebhookRepository) subscriptionQuery() sq.SelectBuilder {
+	return wr.db.Builder.
+		Select("subscription_id", "account_id", "service_id", "url", "secret", "event_types", "created_at").
+		From(wr.subscriptionTableName)
+}
+
+func scanSubscription(row pgx.Row) (webhook.Subscription, error) {
+	var (
+		subscription webhook.Subscription
+		accountID    *int64
+		serviceID    *int64
+		eventTypes   []string
+	)
+	if err := row.Scan(
+		&subscription.SubscriptionID,
+		&accountID,
+		&serviceID,
+		&subscription.URL,
+		&subscription.Secret,
+		&eventTypes,
+		&subscription.CreatedAt,
+	); err != nil {
+		return webhook.Subscription{}, fmt.Errorf("scan subscription: %w", err)
+	}
+
+	if accountID != nil {
+		subscription.AccountID = *accountID
+	}
+	if serviceID != nil {
+		subscription.ServiceID = *serviceID
+	}
+
+	subscription.EventTypes = make([]outbox.Type, 0, len(eventTypes))
+	for _, eventType := range eventTypes {
+		t, err := outbox.ParseType(eventType)
+		if err != nil {
+			return webhook.Subscription{}, fmt.Errorf("scan subscription: %w", err)
+		}
+
+		subscription.EventTypes = append(subscription.EventTypes, t)
+	}
+
+	return subscription, nil
+}
+
+func eventTypesToStrings(eventTypes []outbox.Type) []string {
+	result := make([]string, 0, len(eventTypes))
+	for _, eventType := range eventTypes {
+		result = append(result, eventType.String())
+	}
+
+	return result
+}
+
+// ownerID stores a zero owner id as NULL.
+func ownerID(id int64) *int64 {
+	if id == 0 {
+		return nil
+	}
+
+	return &id
+}
@@ -0,0 +1,151 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/maypok86/payment-api/internal/domain/report"
+)
+
+const (
+	dirPerm      = 0o750
+	tempFileGlob = ".tmp-*"
+)
+
+// FileStorage keeps every report in a file of a directory, which should be on a persistent volume.
+type FileStorage struct {
+	dir       string
+	retention time.Duration
+}
+
+func NewFileStorage(dir string, retention time.Duration) (*FileStorage, error) {
+	if err := os.MkdirAll(dir, dirPerm); err != nil {
+		return nil, fmt.Errorf("create report storage dir: %w", err)
+	}
+
+	return &FileStorage{
+		dir:       dir,
+		retention: retention,
+	}, nil
+}
+
+func (s *FileStorage) path(key string) (string, error) {
+	if !isValidKey(key) {
+		return "", report.ErrNotFound
+	}
+
+	return filepath.Join(s.dir, key), nil
+}
+
+func (s *FileStorage) Get(key string) ([]byte, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, report.ErrNotFound
+		}
+
+		return nil, fmt.Errorf("stat report file: %w", err)
+	}
+	if isExpired(info.ModTime(), s.retention) {
+		return nil, report.ErrNotFound
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, report.ErrNotFound
+		}
+
+		return nil, fmt.Errorf("read report file: %w", err)
+	}
+
+	return content, nil
+}
+
+// Set writes the report to a temporary file first, so a concurrent Get never reads a partly written report.
+func (s *FileStorage) Set(key string, value []byte) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.CreateTemp(s.dir, tempFileGlob)
+	if err != nil {
+		return fmt.Errorf("create report file: %w", err)
+	}
+	defer os.Remove(file.Name())
+
+	if _, err := file.Write(value); err != nil {
+		file.Close()
+		return fmt.Errorf("write report file: %w", err)
+	}
+
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("close report file: %w", err)
+	}
+
+	if err := os.Rename(file.Name(), path); err != nil {
+		return fmt.Errorf("rename report file: %w", err)
+	}
+
+	return nil
+}
+
+func (s *FileStorage) IsExist(key string) bool {
+	path, err := s.path(key)
+	if err != nil {
+		return false
+	}
+
+	info, err := os.Stat(path)
+
+	return err == nil && !isExpired(info.ModTime(), s.retention)
+}
+
+// DeleteExpired removes up to limit expired reports together with temporary files left by failed writes.
+func (s *FileStorage) DeleteExpired(ctx context.Context, limit uint64) (int, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return 0, fmt.Errorf("read report storage dir: %w", err)
+	}
+
+	deleted := 0
+	for _, entry := range entries {
+		if uint64(deleted) == limit || ctx.Err() != nil {
+			break
+		}
+
+		if entry.IsDir() {
+			continue
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+
+			return deleted, fmt.Errorf("stat report file: %w", err)
+		}
+		if !isExpired(info.ModTime(), s.retention) {
+			continue
+		}
+
+		if err := os.Remove(filepath.Join(s.dir, entry.Name())); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return deleted, fmt.Errorf("delete report file: %w", err)
+		}
+		deleted++
+	}
+
+	return deleted, nil
+}
@@ -0,0 +1,105 @@
+package storage_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/maypok86/payment-api/internal/domain/report"
+	"github.com/maypok86/payment-api/internal/storage"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileStorage(t *testing.T) {
+	t.Parallel()
+
+	dir := filepath.Join(t.TempDir(), "reports")
+	fileStorage, err := storage.NewFileStorage(dir, time.Hour)
+	require.NoError(t, err)
+
+	require.False(t, fileStorage.IsExist("2022-11.csv"))
+	_, err = fileStorage.Get("2022-11.csv")
+	require.ErrorIs(t, err, report.ErrNotFound)
+
+	require.NoError(t, fileStorage.Set("2022-11.csv", []byte("report")))
+	require.True(t, fileStorage.IsExist("2022-11.csv"))
+
+	content, err := fileStorage.Get("2022-11.csv")
+	require.NoError(t, err)
+	require.Equal(t, []byte("report"), content)
+
+	// a storage opened later on the same directory, for example after a restart, sees the report.
+	reopened, err := storage.NewFileStorage(dir, time.Hour)
+	require.NoError(t, err)
+	content, err = reopened.Get("2022-11.csv")
+	require.NoError(t, err)
+	require.Equal(t, []byte("report"), content)
+}
+
+func TestFileStorage_InvalidKey(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	fileStorage, err := storage.NewFileStorage(filepath.Join(dir, "reports"), 0)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "secret"), []byte("secret"), 0o600))
+
+	for _, key := range []string{"", "../secret", ".tmp-1", "2022/11.csv"} {
+		_, err := fileStorage.Get(key)
+		require.ErrorIs(t, err, report.ErrNotFound, key)
+		require.False(t, fileStorage.IsExist(key), key)
+		require.ErrorIs(t, fileStorage.Set(key, []byte("report")), report.ErrNotFound, key)
+	}
+}
+
+func TestFileStorage_DeleteExpired(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	dir := t.TempDir()
+	fileStorage, err := storage.NewFileStorage(dir, time.Hour)
+	require.NoError(t, err)
+
+	old := time.Now().Add(-2 * time.Hour)
+	for _, key := range []string{"2022-9.csv", "2022-10.csv", "2022-11.csv"} {
+		require.NoError(t, fileStorage.Set(key, []byte(key)))
+	}
+	require.NoError(t, os.Chtimes(filepath.Join(dir, "2022-9.csv"), old, old))
+	require.NoError(t, os.Chtimes(filepath.Join(dir, "2022-10.csv"), old, old))
+
+	require.False(t, fileStorage.IsExist("2022-9.csv"))
+	_, err = fileStorage.Get("2022-10.csv")
+	require.ErrorIs(t, err, report.ErrNotFound)
+
+	deleted, err := fileStorage.DeleteExpired(ctx, 1)
+	require.NoError(t, err)
+	require.Equal(t, 1, deleted)
+
+	deleted, err = fileStorage.DeleteExpired(ctx, 100)
+	require.NoError(t, err)
+	require.Equal(t, 1, deleted)
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	require.Equal(t, "2022-11.csv", entries[0].Name())
+}
+
+func TestFileStorage_DeleteExpiredWithoutRetention(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	fileStorage, err := storage.NewFileStorage(dir, 0)
+	require.NoError(t, err)
+
+	require.NoError(t, fileStorage.Set("2022-11.csv", []byte("report")))
+	old := time.Now().Add(-24 * 365 * time.Hour)
+	require.NoError(t, os.Chtimes(filepath.Join(dir, "2022-11.csv"), old, old))
+
+	deleted, err := fileStorage.DeleteExpired(context.Background(), 100)
+	require.NoError(t, err)
+	require.Zero(t, deleted)
+	require.True(t, fileStorage.IsExist("2022-11.csv"))
+}
@@ -0,0 +1,202 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/maypok86/payment-api/internal/domain/report"
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+)
+
+type S3Config struct {
+	Endpoint  string
+	Region    string
+	Bucket    string
+	Prefix    string
+	AccessKey string
+	SecretKey string
+	UseSSL    bool
+	// Timeout limits every request, report.Cache methods do not take a context.
+	Timeout time.Duration
+	// Transport replaces the default HTTP transport of the client.
+	Transport http.RoundTripper
+}
+
+// S3Storage keeps reports as objects of a bucket in any S3-compatible storage, for example MinIO, so every replica
+// sees the reports generated by the others.
+type S3Storage struct {
+	client    *minio.Client
+	bucket    string
+	prefix    string
+	timeout   time.Duration
+	retention time.Duration
+}
+
+// NewS3Storage creates the bucket when it does not exist.
+func NewS3Storage(ctx context.Context, cfg S3Config, retention time.Duration) (*S3Storage, error) {
+	client, err := minio.New(cfg.Endpoint, &minio.Options{
+		Creds:        credentials.NewStaticV4(cfg.AccessKey, cfg.SecretKey, ""),
+		Secure:       cfg.UseSSL,
+		Region:       cfg.Region,
+		BucketLookup: minio.BucketLookupPath,
+		Transport:    cfg.Transport,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("create s3 client: %w", err)
+	}
+
+	s := &S3Storage{
+		client:    client,
+		bucket:    cfg.Bucket,
+		prefix:    cfg.Prefix,
+		timeout:   cfg.Timeout,
+		retention: retention,
+	}
+
+	ctx, cancel := s.context(ctx)
+	defer cancel()
+
+	exists, err := client.BucketExists(ctx, cfg.Bucket)
+	if err != nil {
+		return nil, fmt.Errorf("check report bucket: %w", err)
+	}
+	if !exists {
+		if err := client.MakeBucket(ctx, cfg.Bucket, minio.MakeBucketOptions{Region: cfg.Region}); err != nil {
+			return nil, fmt.Errorf("create report bucket: %w", err)
+		}
+	}
+
+	return s, nil
+}
+
+func (s *S3Storage) context(ctx context.Context) (context.Context, context.CancelFunc) {
+	if s.timeout > 0 {
+		return context.WithTimeout(ctx, s.timeout)
+	}
+
+	return context.WithCancel(ctx)
+}
+
+func (s *S3Storage) object(key string) (string, error) {
+	if !isValidKey(key) {
+		return "", report.ErrNotFound
+	}
+
+	return s.prefix + key, nil
+}
+
+func (s *S3Storage) Get(key string) ([]byte, error) {
+	name, err := s.object(key)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := s.context(context.Background())
+	defer cancel()
+
+	object, err := s.client.GetObject(ctx, s.bucket, name, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, s.error("get report object", err)
+	}
+	defer object.Close()
+
+	info, err := object.Stat()
+	if err != nil {
+		return nil, s.error("get report object", err)
+	}
+	if isExpired(info.LastModified, s.retention) {
+		return nil, report.ErrNotFound
+	}
+
+	content, err := io.ReadAll(object)
+	if err != nil {
+		return nil, s.error("read report object", err)
+	}
+
+	return content, nil
+}
+
+func (s *S3Storage) Set(key string, value []byte) error {
+	name, err := s.object(key)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := s.context(context.Background())
+	defer cancel()
+
+	if _, err := s.client.PutObject(
+		ctx,
+		s.bucket,
+		name,
+		bytes.NewReader(value),
+		int64(len(value)),
+		minio.PutObjectOptions{},
+	); err != nil {
+		return fmt.Errorf("put report object: %w", err)
+	}
+
+	return nil
+}
+
+func (s *S3Storage) IsExist(key string) bool {
+	name, err := s.object(key)
+	if err != nil {
+		return false
+	}
+
+	ctx, cancel := s.context(context.Background())
+	defer cancel()
+
+	info, err := s.client.StatObject(ctx, s.bucket, name, minio.StatObjectOptions{})
+
+	return err == nil && !isExpired(info.LastModified, s.retention)
+}
+
+// DeleteExpired removes up to limit expired reports under the prefix.
+func (s *S3Storage) DeleteExpired(ctx context.Context, limit uint64) (int, error) {
+	if s.retention <= 0 {
+		return 0, nil
+	}
+
+	ctx, cancel := s.context(ctx)
+	defer cancel()
+
+	deleted := 0
+	for object := range s.client.ListObjects(ctx, s.bucket, minio.ListObjectsOptions{
+		Prefix:    s.prefix,
+		Recursive: true,
+	}) {
+		if object.Err != nil {
+			return deleted, fmt.Errorf("list report objects: %w", object.Err)
+		}
+
+		if !isExpired(object.LastModified, s.retention) {
+			continue
+		}
+
+		if err := s.client.RemoveObject(ctx, s.bucket, object.Key, minio.RemoveObjectOptions{}); err != nil {
+			return deleted, fmt.Errorf("delete report object: %w", err)
+		}
+
+		deleted++
+		if uint64(deleted) == limit {
+			break
+		}
+	}
+
+	return deleted, nil
+}
+
+func (s *S3Storage) error(message string, err error) error {
+	if minio.ToErrorResponse(err).StatusCode == http.StatusNotFound {
+		return report.ErrNotFound
+	}
+
+	return fmt.Errorf("%s: %w", message, err)
+}
@@ -0,0 +1,260 @@
+package storage_test
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/maypok86/payment-api/internal/domain/report"
+	"github.com/maypok86/payment-api/internal/storage"
+	"github.com/stretchr/testify/require"
+)
+
+const testBucket = "reports"
+
+type fakeObject struct {
+	data    []byte
+	modTime time.Time
+}
+
+// fakeS3 is a MinIO-style stand-in which serves the part of the S3 API used by S3Storage for one bucket.
+type fakeS3 struct {
+	mutex   sync.Mutex
+	bucket  bool
+	objects map[string]fakeObject
+}
+
+type listBucketResult struct {
+	XMLName     xml.Name          `xml:"ListBucketResult"`
+	Name        string            `xml:"Name"`
+	Prefix      string            `xml:"Prefix"`
+	KeyCount    int               `xml:"KeyCount"`
+	MaxKeys     int               `xml:"MaxKeys"`
+	IsTruncated bool              `xml:"IsTruncated"`
+	Contents    []listBucketEntry `xml:"Contents"`
+}
+
+type listBucketEntry struct {
+	Key          string `xml:"Key"`
+	LastModified string `xml:"LastModified"`
+	ETag         string `xml:"ETag"`
+	Size         int    `xml:"Size"`
+}
+
+func newFakeS3(t *testing.T) (*fakeS3, *httptest.Server) {
+	t.Helper()
+
+	fake := &fakeS3{objects: make(map[string]fakeObject)}
+	server := httptest.NewTLSServer(fake)
+	t.Cleanup(server.Close)
+
+	return fake, server
+}
+
+func (f *fakeS3) backdate(key string, modTime time.Time) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	object := f.objects[key]
+	object.modTime = modTime
+	f.objects[key] = object
+}
+
+func (f *fakeS3) keys() []string {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	keys := make([]string, 0, len(f.objects))
+	for key := range f.objects {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	bucket, key, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/")
+	if bucket != testBucket {
+		writeS3Error(w, http.StatusNotFound, "NoSuchBucket")
+		return
+	}
+
+	if key == "" {
+		f.serveBucket(w, r)
+		return
+	}
+
+	if !f.bucket {
+		writeS3Error(w, http.StatusNotFound, "NoSuchBucket")
+		return
+	}
+
+	switch r.Method {
+	case http.MethodPut:
+		data, err := io.ReadAll(r.Body)
+		if err != nil {
+			writeS3Error(w, http.StatusBadRequest, "IncompleteBody")
+			return
+		}
+		f.objects[key] = fakeObject{data: data, modTime: time.Now()}
+		w.Header().Set("ETag", etag(key))
+	case http.MethodGet, http.MethodHead:
+		object, ok := f.objects[key]
+		if !ok {
+			writeS3Error(w, http.StatusNotFound, "NoSuchKey")
+			return
+		}
+		w.Header().Set("ETag", etag(key))
+		w.Header().Set("Last-Modified", object.modTime.UTC().Format(http.TimeFormat))
+		w.Header().Set("Content-Type", "application/octet-stream")
+		w.Header().Set("Content-Length", strconv.Itoa(len(object.data)))
+		if r.Method == http.MethodGet {
+			_, _ = w.Write(object.data)
+		}
+	case http.MethodDelete:
+		delete(f.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		writeS3Error(w, http.StatusMethodNotAllowed, "MethodNotAllowed")
+	}
+}
+
+func (f *fakeS3) serveBucket(w http.ResponseWriter, r *http.Request) {
+	switch {
+	case r.Method == http.MethodPut:
+		f.bucket = true
+	case !f.bucket:
+		writeS3Error(w, http.StatusNotFound, "NoSuchBucket")
+	case r.Method == http.MethodHead:
+	case r.Method == http.MethodGet && r.URL.Query().Get("list-type") == "2":
+		f.list(w, r.URL.Query())
+	default:
+		writeS3Error(w, http.StatusNotImplemented, "NotImplemented")
+	}
+}
+
+func (f *fakeS3) list(w http.ResponseWriter, query url.Values) {
+	result := listBucketResult{
+		Name:    testBucket,
+		Prefix:  query.Get("prefix"),
+		MaxKeys: 1000,
+	}
+	for key, object := range f.objects {
+		if !strings.HasPrefix(key, result.Prefix) {
+			continue
+		}
+		result.Contents = append(result.Contents, listBucketEntry{
+			Key:          key,
+			LastModified: object.modTime.UTC().Format("2006-01-02T15:04:05.000Z"),
+			ETag:         etag(key),
+			Size:         len(object.data),
+		})
+	}
+	sort.Slice(result.Contents, func(i, j int) bool {
+		return result.Contents[i].Key < result.Contents[j].Key
+	})
+	result.KeyCount = len(result.Contents)
+
+	w.Header().Set("Content-Type", "application/xml")
+	_ = xml.NewEncoder(w).Encode(result)
+}
+
+func etag(key string) string {
+	return fmt.Sprintf("%q", fmt.Sprintf("%x", key))
+}
+
+func writeS3Error(w http.ResponseWriter, status int, code string) {
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(status)
+	_, _ = fmt.Fprintf(w, "<Error><Code>%s</Code><Message>%s</Message></Error>", code, code)
+}
+
+func newS3Storage(t *testing.T, server *httptest.Server, retention time.Duration) *storage.S3Storage {
+	t.Helper()
+
+	s3Storage, err := storage.NewS3Storage(context.Background(), storage.S3Config{
+		Endpoint:  strings.TrimPrefix(server.URL, "https://"),
+		Region:    "us-east-1",
+		Bucket:    testBucket,
+		Prefix:    "reports/",
+		AccessKey: "access",
+		SecretKey: "secret",
+		UseSSL:    true,
+		Timeout:   5 * time.Second,
+		Transport: server.Client().Transport,
+	}, retention)
+	require.NoError(t, err)
+
+	return s3Storage
+}
+
+func TestS3Storage(t *testing.T) {
+	t.Parallel()
+
+	fake, server := newFakeS3(t)
+	s3Storage := newS3Storage(t, server, time.Hour)
+	require.True(t, fake.bucket)
+
+	require.False(t, s3Storage.IsExist("2022-11.csv"))
+	_, err := s3Storage.Get("2022-11.csv")
+	require.ErrorIs(t, err, report.ErrNotFound)
+
+	require.NoError(t, s3Storage.Set("2022-11.csv", []byte("report")))
+	require.True(t, s3Storage.IsExist("2022-11.csv"))
+	require.Equal(t, []string{"reports/2022-11.csv"}, fake.keys())
+
+	content, err := s3Storage.Get("2022-11.csv")
+	require.NoError(t, err)
+	require.Equal(t, []byte("report"), content)
+
+	// another replica with the same bucket sees the report.
+	content, err = newS3Storage(t, server, time.Hour).Get("2022-11.csv")
+	require.NoError(t, err)
+	require.Equal(t, []byte("report"), content)
+
+	_, err = s3Storage.Get("../2022-11.csv")
+	require.ErrorIs(t, err, report.ErrNotFound)
+}
+
+func TestS3Storage_DeleteExpired(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	fake, server := newFakeS3(t)
+	s3Storage := newS3Storage(t, server, time.Hour)
+
+	for _, key := range []string{"2022-9.csv", "2022-10.csv", "2022-11.csv"} {
+		require.NoError(t, s3Storage.Set(key, []byte(key)))
+	}
+	old := time.Now().Add(-2 * time.Hour)
+	fake.backdate("reports/2022-9.csv", old)
+	fake.backdate("reports/2022-10.csv", old)
+
+	require.False(t, s3Storage.IsExist("2022-9.csv"))
+	_, err := s3Storage.Get("2022-10.csv")
+	require.ErrorIs(t, err, report.ErrNotFound)
+
+	deleted, err := s3Storage.DeleteExpired(ctx, 1)
+	require.NoError(t, err)
+	require.Equal(t, 1, deleted)
+
+	deleted, err = s3Storage.DeleteExpired(ctx, 100)
+	require.NoError(t, err)
+	require.Equal(t, 1, deleted)
+
+	require.Equal(t, []string{"reports/2022-11.csv"}, fake.keys())
+}
@@ -0,0 +1,26 @@
+package storage
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/maypok86/payment-api/internal/domain/report"
+)
+
+// ReportStorage keeps generated reports by their keys. A report older than the retention of the storage is expired:
+// it is not returned anymore and is removed by DeleteExpired. Zero retention keeps reports forever.
+type ReportStorage interface {
+	report.Cache
+	DeleteExpired(ctx context.Context, limit uint64) (int, error)
+}
+
+// isValidKey accepts plain file names only. Keys come from requests, so they must not point outside the storage.
+func isValidKey(key string) bool {
+	return key != "" && !strings.HasPrefix(key, ".") && filepath.Base(key) == key
+}
+
+func isExpired(modTime time.Time, retention time.Duration) bool {
+	return retention > 0 && time.Since(modTime) > retention
+}
@@ -0,0 +1,44 @@
+package worker
+
+import "time"
+
+type options struct {
+	interval    time.Duration
+	batchSize   uint64
+	concurrency int
+}
+
+func newOptions(opts []Option) options {
+	o := options{
+		interval:    defaultInterval,
+		batchSize:   defaultBatchSize,
+		concurrency: defaultConcurrency,
+	}
+
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	return o
+}
+
+type Option func(*options)
+
+func WithInterval(interval time.Duration) Option {
+	return func(o *options) {
+		o.interval = interval
+	}
+}
+
+func WithBatchSize(batchSize uint64) Option {
+	return func(o *options) {
+		o.batchSize = batchSize
+	}
+}
+
+// WithConcurrency sets the number of goroutines of a worker pool.
+func WithConcurrency(concurrency int) Option {
+	return func(o *options) {
+		o.concurrency = concurrency
+	}
+}
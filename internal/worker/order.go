@@ -0,0 +1,66 @@
+package worker
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const (
+	defaultInterval    = 1 * time.Minute
+	defaultBatchSize   = 100
+	defaultConcurrency = 1
+)
+
+type OrderExpirer interface {
+	ExpireOrders(ctx context.Context, limit uint64) (int, error)
+}
+
+// OrderExpiry periodically expires reserved orders whose reservation TTL has passed.
+type OrderExpiry struct {
+	options
+	expirer OrderExpirer
+	logger  *zap.Logger
+}
+
+func NewOrderExpiry(expirer OrderExpirer, logger *zap.Logger, opts ...Option) *OrderExpiry {
+	return &OrderExpiry{
+		options: newOptions(opts),
+		expirer: expirer,
+		logger:  logger,
+	}
+}
+
+// Run blocks until ctx is done.
+func (w *OrderExpiry) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			w.expire(ctx)
+		}
+	}
+}
+
+func (w *OrderExpiry) expire(ctx context.Context) {
+	for {
+		expired, err := w.expirer.ExpireOrders(ctx, w.batchSize)
+		if err != nil {
+			w.logger.Error("expire orders", zap.Error(err))
+			return
+		}
+
+		if expired > 0 {
+			w.logger.Info("orders expired", zap.Int("count", expired))
+		}
+
+		if uint64(expired) < w.batchSize {
+			return
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package worker_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/maypok86/payment-api/internal/pkg/logger"
+	"github.com/maypok86/payment-api/internal/worker"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeExpirer struct {
+	mutex   sync.Mutex
+	results []int
+	err     error
+	limits  []uint64
+	once    sync.Once
+	done    chan struct{}
+}
+
+func (fe *fakeExpirer) ExpireOrders(ctx context.Context, limit uint64) (int, error) {
+	fe.mutex.Lock()
+	defer fe.mutex.Unlock()
+
+	fe.limits = append(fe.limits, limit)
+	if len(fe.results) == 0 {
+		fe.once.Do(func() { close(fe.done) })
+		<-ctx.Done()
+		return 0, fe.err
+	}
+
+	expired := fe.results[0]
+	fe.results = fe.results[1:]
+
+	return expired, fe.err
+}
+
+func TestOrderExpiry_Run(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// the last batch is not full, so the worker waits for the next tick before calling the expirer again.
+	expirer := &fakeExpirer{
+		results: []int{2, 2, 1},
+		done:    make(chan struct{}),
+	}
+	orderExpiry := worker.NewOrderExpiry(
+		expirer,
+		logger.New(os.Stdout, "debug"),
+		worker.WithInterval(10*time.Millisecond),
+		worker.WithBatchSize(2),
+	)
+
+	go orderExpiry.Run(ctx)
+
+	select {
+	case <-expirer.done:
+	case <-time.After(time.Second):
+		t.Fatal("order expiry worker did not call expirer")
+	}
+
+	require.Equal(t, []uint64{2, 2, 2, 2}, expirer.limits)
+}
+
+func TestOrderExpiry_RunError(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	expirer := &fakeExpirer{
+		results: []int{2},
+		err:     errors.New("expire error"),
+		done:    make(chan struct{}),
+	}
+	orderExpiry := worker.NewOrderExpiry(
+		expirer,
+		logger.New(os.Stdout, "debug"),
+		worker.WithInterval(10*time.Millisecond),
+		worker.WithBatchSize(2),
+	)
+
+	go orderExpiry.Run(ctx)
+
+	select {
+	case <-expirer.done:
+	case <-time.After(time.Second):
+		t.Fatal("order expiry worker did not call expirer")
+	}
+
+	// the error stops the current run, so the full batch is not followed up until the next tick.
+	require.Equal(t, []uint64{2, 2}, expirer.limits)
+}
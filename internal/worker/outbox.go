@@ -0,0 +1,60 @@
+package worker
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+type EventPublisher interface {
+	PublishEvents(ctx context.Context, limit uint64) (int, error)
+}
+
+// OutboxRelay periodically delivers pending outbox events.
+type OutboxRelay struct {
+	options
+	publisher EventPublisher
+	logger    *zap.Logger
+}
+
+func NewOutboxRelay(publisher EventPublisher, logger *zap.Logger, opts ...Option) *OutboxRelay {
+	return &OutboxRelay{
+		options:   newOptions(opts),
+		publisher: publisher,
+		logger:    logger,
+	}
+}
+
+// Run blocks until ctx is done.
+func (w *OutboxRelay) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			w.relay(ctx)
+		}
+	}
+}
+
+func (w *OutboxRelay) relay(ctx context.Context) {
+	for {
+		published, err := w.publisher.PublishEvents(ctx, w.batchSize)
+		if err != nil {
+			w.logger.Error("publish events", zap.Error(err))
+			return
+		}
+
+		if published > 0 {
+			w.logger.Debug("events published", zap.Int("count", published))
+		}
+
+		if uint64(published) < w.batchSize {
+			return
+		}
+	}
+}
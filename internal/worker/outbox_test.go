@@ -0,0 +1,67 @@
+package worker_test
+
+import (
+	"context"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/maypok86/payment-api/internal/pkg/logger"
+	"github.com/maypok86/payment-api/internal/worker"
+	"github.com/stretchr/testify/require"
+)
+
+type fakePublisher struct {
+	mutex   sync.Mutex
+	results []int
+	limits  []uint64
+	once    sync.Once
+	done    chan struct{}
+}
+
+func (fp *fakePublisher) PublishEvents(ctx context.Context, limit uint64) (int, error) {
+	fp.mutex.Lock()
+	defer fp.mutex.Unlock()
+
+	fp.limits = append(fp.limits, limit)
+	if len(fp.results) == 0 {
+		fp.once.Do(func() { close(fp.done) })
+		<-ctx.Done()
+		return 0, nil
+	}
+
+	published := fp.results[0]
+	fp.results = fp.results[1:]
+
+	return published, nil
+}
+
+func TestOutboxRelay_Run(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// the second batch is not full because an event failed, so the relay waits for the next tick.
+	publisher := &fakePublisher{
+		results: []int{3, 1},
+		done:    make(chan struct{}),
+	}
+	outboxRelay := worker.NewOutboxRelay(
+		publisher,
+		logger.New(os.Stdout, "debug"),
+		worker.WithInterval(10*time.Millisecond),
+		worker.WithBatchSize(3),
+	)
+
+	go outboxRelay.Run(ctx)
+
+	select {
+	case <-publisher.done:
+	case <-time.After(time.Second):
+		t.Fatal("outbox relay did not call publisher")
+	}
+
+	require.Equal(t, []uint64{3, 3, 3}, publisher.limits)
+}
@@ -0,0 +1,78 @@
+package worker
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+type ReportJobRunner interface {
+	RunJobs(ctx context.Context, limit uint64) (int, error)
+}
+
+// ReportJobs is a pool of goroutines which periodically run queued report jobs. Every goroutine claims its own
+// batch, so jobs run in parallel up to the concurrency of the pool.
+type ReportJobs struct {
+	options
+	runner ReportJobRunner
+	logger *zap.Logger
+}
+
+func NewReportJobs(runner ReportJobRunner, logger *zap.Logger, opts ...Option) *ReportJobs {
+	return &ReportJobs{
+		options: newOptions(opts),
+		runner:  runner,
+		logger:  logger,
+	}
+}
+
+// Run blocks until ctx is done and every goroutine of the pool has returned.
+func (w *ReportJobs) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+
+	for i := 0; i < w.concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w.loop(ctx)
+		}()
+	}
+
+	wg.Wait()
+}
+
+func (w *ReportJobs) loop(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			w.run(ctx)
+		}
+	}
+}
+
+func (w *ReportJobs) run(ctx context.Context) {
+	for {
+		ran, err := w.runner.RunJobs(ctx, w.batchSize)
+		if err != nil {
+			if ctx.Err() == nil {
+				w.logger.Error("run report jobs", zap.Error(err))
+			}
+			return
+		}
+
+		if ran > 0 {
+			w.logger.Info("report jobs run", zap.Int("count", ran))
+		}
+
+		if uint64(ran) < w.batchSize {
+			return
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package worker_test
+
+import (
+	"context"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/maypok86/payment-api/internal/pkg/logger"
+	"github.com/maypok86/payment-api/internal/worker"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeRunner blocks every call until ctx is done, so the pool can only reach the expected number of calls
+// if its goroutines run jobs in parallel.
+type fakeRunner struct {
+	mutex    sync.Mutex
+	calls    int
+	expected int
+	limits   []uint64
+	ready    chan struct{}
+}
+
+func (fr *fakeRunner) RunJobs(ctx context.Context, limit uint64) (int, error) {
+	fr.mutex.Lock()
+	fr.calls++
+	fr.limits = append(fr.limits, limit)
+	if fr.calls == fr.expected {
+		close(fr.ready)
+	}
+	fr.mutex.Unlock()
+
+	<-ctx.Done()
+
+	return 0, ctx.Err()
+}
+
+func TestReportJobs_Run(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	runner := &fakeRunner{
+		expected: 3,
+		ready:    make(chan struct{}),
+	}
+	reportJobs := worker.NewReportJobs(
+		runner,
+		logger.New(os.Stdout, "debug"),
+		worker.WithInterval(10*time.Millisecond),
+		worker.WithBatchSize(1),
+		worker.WithConcurrency(3),
+	)
+
+	stopped := make(chan struct{})
+	go func() {
+		reportJobs.Run(ctx)
+		close(stopped)
+	}()
+
+	select {
+	case <-runner.ready:
+	case <-time.After(time.Second):
+		t.Fatal("report jobs pool did not run jobs in parallel")
+	}
+
+	cancel()
+
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Fatal("report jobs pool did not stop")
+	}
+
+	require.Equal(t, []uint64{1, 1, 1}, runner.limits)
+}
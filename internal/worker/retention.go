@@ -0,0 +1,60 @@
+package worker
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+type ReportDeleter interface {
+	DeleteExpired(ctx context.Context, limit uint64) (int, error)
+}
+
+// ReportRetention periodically deletes the reports whose retention has passed from the report storage.
+type ReportRetention struct {
+	options
+	deleter ReportDeleter
+	logger  *zap.Logger
+}
+
+func NewReportRetention(deleter ReportDeleter, logger *zap.Logger, opts ...Option) *ReportRetention {
+	return &ReportRetention{
+		options: newOptions(opts),
+		deleter: deleter,
+		logger:  logger,
+	}
+}
+
+// Run blocks until ctx is done.
+func (w *ReportRetention) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			w.delete(ctx)
+		}
+	}
+}
+
+func (w *ReportRetention) delete(ctx context.Context) {
+	for {
+		deleted, err := w.deleter.DeleteExpired(ctx, w.batchSize)
+		if err != nil {
+			w.logger.Error("delete expired reports", zap.Error(err))
+			return
+		}
+
+		if deleted > 0 {
+			w.logger.Info("expired reports deleted", zap.Int("count", deleted))
+		}
+
+		if uint64(deleted) < w.batchSize {
+			return
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package worker_test
+
+import (
+	"context"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/maypok86/payment-api/internal/pkg/logger"
+	"github.com/maypok86/payment-api/internal/worker"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeDeleter struct {
+	mutex   sync.Mutex
+	results []int
+	limits  []uint64
+	once    sync.Once
+	done    chan struct{}
+}
+
+func (fd *fakeDeleter) DeleteExpired(ctx context.Context, limit uint64) (int, error) {
+	fd.mutex.Lock()
+	defer fd.mutex.Unlock()
+
+	fd.limits = append(fd.limits, limit)
+	if len(fd.results) == 0 {
+		fd.once.Do(func() { close(fd.done) })
+		<-ctx.Done()
+		return 0, nil
+	}
+
+	deleted := fd.results[0]
+	fd.results = fd.results[1:]
+
+	return deleted, nil
+}
+
+func TestReportRetention_Run(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	deleter := &fakeDeleter{
+		results: []int{3, 1},
+		done:    make(chan struct{}),
+	}
+	reportRetention := worker.NewReportRetention(
+		deleter,
+		logger.New(os.Stdout, "debug"),
+		worker.WithInterval(10*time.Millisecond),
+		worker.WithBatchSize(3),
+	)
+
+	go reportRetention.Run(ctx)
+
+	select {
+	case <-deleter.done:
+	case <-time.After(time.Second):
+		t.Fatal("report retention worker did not call deleter")
+	}
+
+	require.Equal(t, []uint64{3, 3, 3}, deleter.limits)
+}
@@ -0,0 +1,60 @@
+package worker
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+type WebhookDeliverer interface {
+	DeliverPending(ctx context.Context, limit uint64) (int, error)
+}
+
+// WebhookDelivery periodically sends due webhook deliveries.
+type WebhookDelivery struct {
+	options
+	deliverer WebhookDeliverer
+	logger    *zap.Logger
+}
+
+func NewWebhookDelivery(deliverer WebhookDeliverer, logger *zap.Logger, opts ...Option) *WebhookDelivery {
+	return &WebhookDelivery{
+		options:   newOptions(opts),
+		deliverer: deliverer,
+		logger:    logger,
+	}
+}
+
+// Run blocks until ctx is done.
+func (w *WebhookDelivery) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			w.deliver(ctx)
+		}
+	}
+}
+
+func (w *WebhookDelivery) deliver(ctx context.Context) {
+	for {
+		attempted, err := w.deliverer.DeliverPending(ctx, w.batchSize)
+		if err != nil {
+			w.logger.Error("deliver webhooks", zap.Error(err))
+			return
+		}
+
+		if attempted > 0 {
+			w.logger.Debug("webhooks attempted", zap.Int("count", attempted))
+		}
+
+		if uint64(attempted) < w.batchSize {
+			return
+		}
+	}
+}
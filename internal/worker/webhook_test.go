@@ -0,0 +1,50 @@
+package worker_test
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/maypok86/payment-api/internal/pkg/logger"
+	"github.com/maypok86/payment-api/internal/worker"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeDeliverer struct {
+	fakePublisher
+}
+
+func (fd *fakeDeliverer) DeliverPending(ctx context.Context, limit uint64) (int, error) {
+	return fd.PublishEvents(ctx, limit)
+}
+
+func TestWebhookDelivery_Run(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	deliverer := &fakeDeliverer{
+		fakePublisher: fakePublisher{
+			results: []int{2, 2, 0},
+			done:    make(chan struct{}),
+		},
+	}
+	webhookDelivery := worker.NewWebhookDelivery(
+		deliverer,
+		logger.New(os.Stdout, "debug"),
+		worker.WithInterval(10*time.Millisecond),
+		worker.WithBatchSize(2),
+	)
+
+	go webhookDelivery.Run(ctx)
+
+	select {
+	case <-deliverer.done:
+	case <-time.After(time.Second):
+		t.Fatal("webhook delivery did not call deliverer")
+	}
+
+	require.Equal(t, []uint64{2, 2, 2, 2}, deliverer.limits)
+}
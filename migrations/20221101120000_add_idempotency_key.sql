@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+    idempotency_key text PRIMARY KEY CHECK (char_length(idempotency_key) <= 255),
+    fingerprint text NOT NULL,
+    status_code integer NOT NULL DEFAULT 0,
+    response bytea NOT NULL DEFAULT '',
+    created_at timestamptz NOT NULL DEFAULT now()
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS idempotency_keys;
+-- +goose StatementEnd
@@ -0,0 +1,102 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TYPE system_account AS ENUM ('external_funding', 'reserved_funds', 'service_revenue');
+
+CREATE TYPE ledger_side AS ENUM ('debit', 'credit');
+
+CREATE TABLE IF NOT EXISTS postings (
+    posting_id bigint PRIMARY KEY GENERATED ALWAYS AS IDENTITY,
+    type transaction_type NOT NULL,
+    description text NOT NULL,
+    created_at timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE TABLE IF NOT EXISTS posting_lines (
+    line_id bigint PRIMARY KEY GENERATED ALWAYS AS IDENTITY,
+    posting_id bigint NOT NULL REFERENCES postings(posting_id) ON DELETE CASCADE,
+    account_id bigint REFERENCES accounts(account_id),
+    system_account system_account,
+    side ledger_side NOT NULL,
+    amount bigint NOT NULL CHECK (amount > 0),
+    CHECK ((account_id IS NULL) <> (system_account IS NULL))
+);
+
+CREATE INDEX IF NOT EXISTS posting_lines_posting_id_idx ON posting_lines (posting_id);
+CREATE INDEX IF NOT EXISTS posting_lines_account_id_idx ON posting_lines (account_id) WHERE account_id IS NOT NULL;
+
+CREATE OR REPLACE FUNCTION trigger_check_posting_balanced()
+RETURNS TRIGGER AS
+$$
+DECLARE
+    difference bigint;
+BEGIN
+    SELECT COALESCE(SUM(CASE WHEN side = 'debit' THEN amount ELSE -amount END), 0)
+    INTO difference
+    FROM posting_lines
+    WHERE posting_id = NEW.posting_id;
+
+    IF difference <> 0 THEN
+        RAISE EXCEPTION 'posting % is not balanced', NEW.posting_id
+            USING ERRCODE = 'check_violation';
+    END IF;
+
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE CONSTRAINT TRIGGER check_posting_balanced
+AFTER INSERT OR UPDATE ON posting_lines
+DEFERRABLE INITIALLY DEFERRED
+FOR EACH ROW
+EXECUTE PROCEDURE trigger_check_posting_balanced();
+
+DO
+$$
+DECLARE
+    opening_posting_id bigint;
+    total bigint;
+BEGIN
+    INSERT INTO postings (type, description)
+    VALUES ('enrollment', 'Opening balances')
+    RETURNING posting_id INTO opening_posting_id;
+
+    INSERT INTO posting_lines (posting_id, account_id, side, amount)
+    SELECT opening_posting_id, account_id, 'credit', balance
+    FROM accounts
+    WHERE balance > 0;
+
+    INSERT INTO posting_lines (posting_id, system_account, side, amount)
+    SELECT opening_posting_id, 'reserved_funds', 'credit', SUM(amount)
+    FROM orders
+    WHERE NOT is_paid AND NOT is_cancelled
+    HAVING SUM(amount) > 0;
+
+    INSERT INTO posting_lines (posting_id, system_account, side, amount)
+    SELECT opening_posting_id, 'service_revenue', 'credit', SUM(amount)
+    FROM orders
+    WHERE is_paid
+    HAVING SUM(amount) > 0;
+
+    SELECT COALESCE(SUM(amount), 0)
+    INTO total
+    FROM posting_lines
+    WHERE posting_id = opening_posting_id;
+
+    IF total > 0 THEN
+        INSERT INTO posting_lines (posting_id, system_account, side, amount)
+        VALUES (opening_posting_id, 'external_funding', 'debit', total);
+    ELSE
+        DELETE FROM postings WHERE posting_id = opening_posting_id;
+    END IF;
+END;
+$$;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS posting_lines;
+DROP TABLE IF EXISTS postings;
+DROP FUNCTION IF EXISTS trigger_check_posting_balanced();
+DROP TYPE IF EXISTS ledger_side;
+DROP TYPE IF EXISTS system_account;
+-- +goose StatementEnd
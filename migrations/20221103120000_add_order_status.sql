@@ -0,0 +1,48 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TYPE order_status AS ENUM ('created', 'reserved', 'paid', 'cancelled', 'refunded', 'expired');
+
+ALTER TABLE orders ADD COLUMN status order_status NOT NULL DEFAULT 'created';
+
+UPDATE orders
+SET status = CASE
+    WHEN is_paid THEN 'paid'::order_status
+    WHEN is_cancelled THEN 'cancelled'::order_status
+    ELSE 'reserved'::order_status
+END;
+
+ALTER TABLE orders DROP COLUMN is_paid, DROP COLUMN is_cancelled;
+
+CREATE TABLE IF NOT EXISTS order_status_history (
+    history_id bigint PRIMARY KEY GENERATED ALWAYS AS IDENTITY,
+    order_id bigint NOT NULL REFERENCES orders(order_id) ON DELETE CASCADE,
+    from_status order_status,
+    to_status order_status NOT NULL,
+    actor text NOT NULL,
+    reason text NOT NULL DEFAULT '',
+    created_at timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS order_status_history_order_id_idx ON order_status_history (order_id);
+
+INSERT INTO order_status_history (order_id, to_status, actor, reason)
+SELECT order_id, status, 'migration', 'Converted from is_paid and is_cancelled'
+FROM orders;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS order_status_history;
+
+ALTER TABLE orders
+    ADD COLUMN is_paid boolean NOT NULL DEFAULT false,
+    ADD COLUMN is_cancelled boolean NOT NULL DEFAULT false;
+
+UPDATE orders
+SET is_paid = status IN ('paid', 'refunded'),
+    is_cancelled = status IN ('cancelled', 'expired');
+
+ALTER TABLE orders DROP COLUMN status;
+
+DROP TYPE IF EXISTS order_status;
+-- +goose StatementEnd
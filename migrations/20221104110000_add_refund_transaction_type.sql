@@ -0,0 +1,9 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TYPE transaction_type ADD VALUE IF NOT EXISTS 'refund';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+-- enum values can not be dropped without recreating the type, so refund stays in transaction_type.
+-- +goose StatementEnd
@@ -0,0 +1,22 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE orders ADD COLUMN expires_at timestamptz;
+
+-- reservations that already exist get a full day before the expiry worker returns their money.
+UPDATE orders
+SET expires_at = CASE
+    WHEN status = 'reserved' THEN now()
+    ELSE created_at
+END + interval '1 day';
+
+ALTER TABLE orders ALTER COLUMN expires_at SET NOT NULL;
+
+CREATE INDEX IF NOT EXISTS orders_reserved_expires_at_idx ON orders (expires_at) WHERE status = 'reserved';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS orders_reserved_expires_at_idx;
+
+ALTER TABLE orders DROP COLUMN expires_at;
+-- +goose StatementEnd
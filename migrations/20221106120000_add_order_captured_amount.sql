@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE orders ADD COLUMN captured_amount bigint NOT NULL DEFAULT 0;
+
+UPDATE orders SET captured_amount = amount WHERE status IN ('paid', 'refunded');
+
+ALTER TABLE orders
+    DROP CONSTRAINT orders_refunded_amount_check,
+    ADD CONSTRAINT orders_captured_amount_check CHECK (captured_amount >= 0 AND captured_amount <= amount),
+    ADD CONSTRAINT orders_refunded_amount_check CHECK (refunded_amount >= 0 AND refunded_amount <= captured_amount);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE orders
+    DROP CONSTRAINT orders_refunded_amount_check,
+    DROP CONSTRAINT orders_captured_amount_check,
+    ADD CONSTRAINT orders_refunded_amount_check CHECK (refunded_amount >= 0 AND refunded_amount <= amount);
+
+ALTER TABLE orders DROP COLUMN captured_amount;
+-- +goose StatementEnd
@@ -0,0 +1,43 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS account_balances (
+    account_id bigint NOT NULL REFERENCES accounts(account_id),
+    currency text NOT NULL CHECK (currency ~ '^[A-Z]{3}$'),
+    balance bigint NOT NULL DEFAULT 0 CHECK (balance >= 0),
+    PRIMARY KEY (account_id, currency)
+);
+
+INSERT INTO account_balances (account_id, currency, balance)
+SELECT account_id, 'RUB', balance
+FROM accounts;
+
+ALTER TABLE accounts DROP COLUMN IF EXISTS balance;
+
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS currency text NOT NULL DEFAULT 'RUB'
+    CHECK (currency ~ '^[A-Z]{3}$');
+ALTER TABLE transactions ALTER COLUMN currency DROP DEFAULT;
+
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS currency text NOT NULL DEFAULT 'RUB'
+    CHECK (currency ~ '^[A-Z]{3}$');
+ALTER TABLE orders ALTER COLUMN currency DROP DEFAULT;
+
+ALTER TABLE postings ADD COLUMN IF NOT EXISTS currency text NOT NULL DEFAULT 'RUB'
+    CHECK (currency ~ '^[A-Z]{3}$');
+ALTER TABLE postings ALTER COLUMN currency DROP DEFAULT;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE postings DROP COLUMN IF EXISTS currency;
+ALTER TABLE orders DROP COLUMN IF EXISTS currency;
+ALTER TABLE transactions DROP COLUMN IF EXISTS currency;
+
+ALTER TABLE accounts ADD COLUMN IF NOT EXISTS balance bigint NOT NULL DEFAULT 0 CHECK (balance >= 0);
+
+UPDATE accounts AS a
+SET balance = b.balance
+FROM account_balances AS b
+WHERE b.account_id = a.account_id AND b.currency = 'RUB';
+
+DROP TABLE IF EXISTS account_balances;
+-- +goose StatementEnd
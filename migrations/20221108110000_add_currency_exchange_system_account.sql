@@ -0,0 +1,9 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TYPE system_account ADD VALUE IF NOT EXISTS 'currency_exchange';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+-- enum values can not be dropped without recreating the type, so currency_exchange stays in system_account.
+-- +goose StatementEnd
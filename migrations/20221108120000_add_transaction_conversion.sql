@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE transactions
+    ADD COLUMN IF NOT EXISTS rate numeric CHECK (rate > 0),
+    ADD COLUMN IF NOT EXISTS converted_amount bigint CHECK (converted_amount > 0),
+    ADD COLUMN IF NOT EXISTS converted_currency text CHECK (converted_currency ~ '^[A-Z]{3}$'),
+    ADD COLUMN IF NOT EXISTS rounding_remainder numeric CHECK (rounding_remainder >= 0 AND rounding_remainder < 1),
+    ADD CONSTRAINT transactions_conversion_check CHECK (
+        (rate IS NULL AND converted_amount IS NULL AND converted_currency IS NULL AND rounding_remainder IS NULL)
+        OR (rate IS NOT NULL AND converted_amount IS NOT NULL AND converted_currency IS NOT NULL
+            AND rounding_remainder IS NOT NULL)
+    );
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE transactions
+    DROP CONSTRAINT IF EXISTS transactions_conversion_check,
+    DROP COLUMN IF EXISTS rounding_remainder,
+    DROP COLUMN IF EXISTS converted_currency,
+    DROP COLUMN IF EXISTS converted_amount,
+    DROP COLUMN IF EXISTS rate;
+-- +goose StatementEnd
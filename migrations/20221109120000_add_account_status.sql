@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TYPE account_status AS ENUM ('active', 'frozen', 'closed');
+
+ALTER TABLE accounts ADD COLUMN status account_status NOT NULL DEFAULT 'active';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE accounts DROP COLUMN status;
+
+DROP TYPE IF EXISTS account_status;
+-- +goose StatementEnd
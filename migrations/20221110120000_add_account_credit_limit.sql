@@ -0,0 +1,16 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE account_balances
+    ADD COLUMN credit_limit bigint NOT NULL DEFAULT 0 CHECK (credit_limit >= 0),
+    DROP CONSTRAINT IF EXISTS account_balances_balance_check,
+    ADD CONSTRAINT account_balances_balance_check CHECK (balance >= -credit_limit);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+-- Fails while any account is in overdraft, such balances have to be settled before the rollback.
+ALTER TABLE account_balances
+    DROP CONSTRAINT IF EXISTS account_balances_balance_check,
+    ADD CONSTRAINT account_balances_balance_check CHECK (balance >= 0),
+    DROP COLUMN IF EXISTS credit_limit;
+-- +goose StatementEnd
@@ -0,0 +1,19 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE account_balances ADD COLUMN reserved bigint NOT NULL DEFAULT 0 CHECK (reserved >= 0);
+
+UPDATE account_balances AS b
+SET reserved = o.reserved
+FROM (
+    SELECT account_id, currency, SUM(amount) AS reserved
+    FROM orders
+    WHERE status IN ('created', 'reserved')
+    GROUP BY account_id, currency
+) AS o
+WHERE o.account_id = b.account_id AND o.currency = b.currency;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE account_balances DROP COLUMN IF EXISTS reserved;
+-- +goose StatementEnd
@@ -0,0 +1,33 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TYPE outbox_event_type AS ENUM (
+    'balance_credited',
+    'transfer_completed',
+    'order_reserved',
+    'order_paid',
+    'order_cancelled',
+    'order_refunded'
+);
+
+CREATE TABLE IF NOT EXISTS outbox (
+    event_id bigint PRIMARY KEY GENERATED ALWAYS AS IDENTITY,
+    type outbox_event_type NOT NULL,
+    aggregate_type text NOT NULL,
+    aggregate_id bigint NOT NULL,
+    payload jsonb NOT NULL,
+    attempts integer NOT NULL DEFAULT 0,
+    last_error text,
+    next_attempt_at timestamptz NOT NULL DEFAULT now(),
+    created_at timestamptz NOT NULL DEFAULT now(),
+    published_at timestamptz
+);
+
+CREATE INDEX IF NOT EXISTS outbox_pending_idx ON outbox (aggregate_type, aggregate_id, event_id)
+    WHERE published_at IS NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS outbox;
+DROP TYPE IF EXISTS outbox_event_type;
+-- +goose StatementEnd
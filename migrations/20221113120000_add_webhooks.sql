@@ -0,0 +1,38 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TYPE webhook_delivery_status AS ENUM ('pending', 'delivered', 'dead');
+
+CREATE TABLE IF NOT EXISTS webhook_subscriptions (
+    subscription_id bigint PRIMARY KEY GENERATED ALWAYS AS IDENTITY,
+    url text NOT NULL,
+    secret text NOT NULL,
+    event_types text[] NOT NULL,
+    created_at timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    delivery_id bigint PRIMARY KEY GENERATED ALWAYS AS IDENTITY,
+    subscription_id bigint NOT NULL REFERENCES webhook_subscriptions (subscription_id) ON DELETE CASCADE,
+    event_id bigint NOT NULL REFERENCES outbox (event_id),
+    event_type outbox_event_type NOT NULL,
+    body jsonb NOT NULL,
+    status webhook_delivery_status NOT NULL DEFAULT 'pending',
+    attempts integer NOT NULL DEFAULT 0,
+    last_status_code integer,
+    last_error text,
+    next_attempt_at timestamptz NOT NULL DEFAULT now(),
+    created_at timestamptz NOT NULL DEFAULT now(),
+    updated_at timestamptz NOT NULL DEFAULT now(),
+    UNIQUE (subscription_id, event_id)
+);
+
+CREATE INDEX IF NOT EXISTS webhook_deliveries_pending_idx ON webhook_deliveries (next_attempt_at)
+    WHERE status = 'pending';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS webhook_deliveries;
+DROP TABLE IF EXISTS webhook_subscriptions;
+DROP TYPE IF EXISTS webhook_delivery_status;
+-- +goose StatementEnd
@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE INDEX IF NOT EXISTS transactions_sender_created_at_idx ON transactions (sender_id, created_at, transaction_id);
+CREATE INDEX IF NOT EXISTS transactions_receiver_created_at_idx ON transactions (receiver_id, created_at, transaction_id);
+CREATE INDEX IF NOT EXISTS transactions_sender_amount_idx ON transactions (sender_id, amount, transaction_id);
+CREATE INDEX IF NOT EXISTS transactions_receiver_amount_idx ON transactions (receiver_id, amount, transaction_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS transactions_receiver_amount_idx;
+DROP INDEX IF EXISTS transactions_sender_amount_idx;
+DROP INDEX IF EXISTS transactions_receiver_created_at_idx;
+DROP INDEX IF EXISTS transactions_sender_created_at_idx;
+-- +goose StatementEnd
@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE INDEX IF NOT EXISTS transactions_sender_receiver_idx ON transactions (sender_id, receiver_id, created_at);
+CREATE INDEX IF NOT EXISTS transactions_receiver_sender_idx ON transactions (receiver_id, sender_id, created_at);
+CREATE INDEX IF NOT EXISTS transactions_sender_type_idx ON transactions (sender_id, type, created_at);
+CREATE INDEX IF NOT EXISTS transactions_description_trgm_idx ON transactions USING gin (description gin_trgm_ops);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS transactions_description_trgm_idx;
+DROP INDEX IF EXISTS transactions_sender_type_idx;
+DROP INDEX IF EXISTS transactions_receiver_sender_idx;
+DROP INDEX IF EXISTS transactions_sender_receiver_idx;
+-- +goose StatementEnd
@@ -0,0 +1,26 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TYPE report_job_status AS ENUM ('queued', 'running', 'done', 'failed');
+
+CREATE TABLE IF NOT EXISTS report_jobs (
+    job_id bigint PRIMARY KEY GENERATED ALWAYS AS IDENTITY,
+    month bigint NOT NULL,
+    year bigint NOT NULL,
+    format text NOT NULL,
+    status report_job_status NOT NULL DEFAULT 'queued',
+    report_key text,
+    error text,
+    started_at timestamptz,
+    created_at timestamptz NOT NULL DEFAULT now(),
+    updated_at timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS report_jobs_unfinished_idx ON report_jobs (job_id)
+    WHERE status IN ('queued', 'running');
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS report_jobs;
+DROP TYPE IF EXISTS report_job_status;
+-- +goose StatementEnd
@@ -0,0 +1,16 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS report_snapshots (
+    month bigint NOT NULL,
+    year bigint NOT NULL,
+    entries jsonb NOT NULL,
+    checksum text NOT NULL,
+    generated_at timestamptz NOT NULL,
+    PRIMARY KEY (year, month)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS report_snapshots;
+-- +goose StatementEnd
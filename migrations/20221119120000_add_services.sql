@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS services (
+    service_id bigint PRIMARY KEY,
+    name text NOT NULL CHECK (name <> ''),
+    description text NOT NULL DEFAULT '',
+    active boolean NOT NULL DEFAULT true,
+    created_at timestamptz NOT NULL DEFAULT now(),
+    updated_at timestamptz NOT NULL DEFAULT now()
+);
+
+INSERT INTO services (service_id, name)
+SELECT DISTINCT service_id, 'Service ' || service_id FROM orders;
+
+ALTER TABLE orders
+    ADD CONSTRAINT orders_service_id_fkey FOREIGN KEY (service_id) REFERENCES services (service_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE orders DROP CONSTRAINT IF EXISTS orders_service_id_fkey;
+DROP TABLE IF EXISTS services;
+-- +goose StatementEnd
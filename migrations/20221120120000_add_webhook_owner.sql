@@ -0,0 +1,22 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE webhook_subscriptions
+    ADD COLUMN account_id bigint REFERENCES accounts (account_id) ON DELETE CASCADE,
+    ADD COLUMN service_id bigint REFERENCES services (service_id) ON DELETE CASCADE,
+    ADD CONSTRAINT webhook_subscriptions_owner_check CHECK (num_nonnulls(account_id, service_id) = 1) NOT VALID;
+
+CREATE INDEX IF NOT EXISTS webhook_subscriptions_account_id_idx ON webhook_subscriptions (account_id)
+    WHERE account_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS webhook_subscriptions_service_id_idx ON webhook_subscriptions (service_id)
+    WHERE service_id IS NOT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS webhook_subscriptions_service_id_idx;
+DROP INDEX IF EXISTS webhook_subscriptions_account_id_idx;
+ALTER TABLE webhook_subscriptions
+    DROP CONSTRAINT IF EXISTS webhook_subscriptions_owner_check,
+    DROP COLUMN IF EXISTS service_id,
+    DROP COLUMN IF EXISTS account_id;
+-- +goose StatementEnd
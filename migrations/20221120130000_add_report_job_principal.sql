@@ -0,0 +1,10 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE report_jobs ADD COLUMN IF NOT EXISTS principal text NOT NULL DEFAULT 'api';
+ALTER TABLE report_jobs ALTER COLUMN principal DROP DEFAULT;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE report_jobs DROP COLUMN IF EXISTS principal;
+-- +goose StatementEnd
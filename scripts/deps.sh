@@ -7,4 +7,6 @@ bash -s -- -b "$(go env GOPATH)"/bin v1.47.3
 
 go install github.com/daixiang0/gci@latest
 GO111MODULE=on go install mvdan.cc/gofumpt@latest
-go install github.com/golang/mock/mockgen@v1.6.0
\ No newline at end of file
+go install github.com/golang/mock/mockgen@v1.6.0
+go install google.golang.org/protobuf/cmd/protoc-gen-go@v1.28.1
+go install google.golang.org/grpc/cmd/protoc-gen-go-grpc@v1.2.0
@@ -0,0 +1,275 @@
+package integration
+
+import (
+	"net/http"
+
+	. "github.com/Eun/go-hit"
+)
+
+const accountPath = basePath + "/account"
+
+func (as *APISuite) TestAccountLifecycle() {
+	Test(as.T(),
+		Post(accountPath),
+		Send().Body().JSON(map[string]interface{}{
+			"account_id": 1,
+		}),
+		Expect().Status().Equal(http.StatusOK),
+		Expect().Body().JSON().Equal(map[string]interface{}{
+			"account_id": 1,
+			"status":     "active",
+		}),
+	)
+
+	Test(as.T(),
+		Post(accountPath),
+		Send().Body().JSON(map[string]interface{}{
+			"account_id": 1,
+		}),
+		Expect().Status().Equal(http.StatusConflict),
+		Expect().Body().JSON().Equal(map[string]interface{}{
+			"message": "Create account error. Account already exist",
+		}),
+	)
+
+	Test(as.T(),
+		Post(addBalancePath),
+		Send().Body().JSON(map[string]interface{}{
+			"account_id": 1,
+			"amount":     100,
+		}),
+		Expect().Status().Equal(http.StatusOK),
+	)
+
+	Test(as.T(),
+		Post(accountPath+"/1/freeze"),
+		Expect().Status().Equal(http.StatusOK),
+		Expect().Body().JSON().Equal(map[string]interface{}{
+			"account_id": 1,
+			"status":     "frozen",
+		}),
+	)
+
+	Test(as.T(),
+		Post(addBalancePath),
+		Send().Body().JSON(map[string]interface{}{
+			"account_id": 1,
+			"amount":     100,
+		}),
+		Expect().Status().Equal(http.StatusConflict),
+		Expect().Body().JSON().Equal(map[string]interface{}{
+			"message": "Add balance error. Account is frozen",
+		}),
+	)
+
+	Test(as.T(),
+		Post(createOrderPath),
+		Send().Body().JSON(map[string]interface{}{
+			"order_id":   1,
+			"account_id": 1,
+			"service_id": 1,
+			"amount":     50,
+		}),
+		Expect().Status().Equal(http.StatusConflict),
+		Expect().Body().JSON().Equal(map[string]interface{}{
+			"message": "Create order error. Account is frozen",
+		}),
+	)
+
+	Test(as.T(),
+		Post(accountPath+"/1/close"),
+		Expect().Status().Equal(http.StatusConflict),
+		Expect().Body().JSON().Equal(map[string]interface{}{
+			"message": "Close account error. Balance is not zero",
+		}),
+	)
+
+	Test(as.T(),
+		Post(accountPath+"/1/unfreeze"),
+		Expect().Status().Equal(http.StatusOK),
+		Expect().Body().JSON().Equal(map[string]interface{}{
+			"account_id": 1,
+			"status":     "active",
+		}),
+	)
+
+	Test(as.T(),
+		Post(createOrderPath),
+		Send().Body().JSON(map[string]interface{}{
+			"order_id":   1,
+			"account_id": 1,
+			"service_id": 1,
+			"amount":     100,
+		}),
+		Expect().Status().Equal(http.StatusOK),
+	)
+
+	Test(as.T(),
+		Post(accountPath+"/1/close"),
+		Expect().Status().Equal(http.StatusConflict),
+		Expect().Body().JSON().Equal(map[string]interface{}{
+			"message": "Close account error. Account has open reservations",
+		}),
+	)
+
+	Test(as.T(),
+		Post(payForOrderPath),
+		Send().Body().JSON(map[string]interface{}{
+			"order_id":   1,
+			"account_id": 1,
+			"service_id": 1,
+			"amount":     100,
+		}),
+		Expect().Status().Equal(http.StatusOK),
+	)
+
+	Test(as.T(),
+		Post(accountPath+"/1/close"),
+		Expect().Status().Equal(http.StatusOK),
+		Expect().Body().JSON().Equal(map[string]interface{}{
+			"account_id": 1,
+			"status":     "closed",
+		}),
+	)
+
+	Test(as.T(),
+		Post(accountPath+"/1/unfreeze"),
+		Expect().Status().Equal(http.StatusConflict),
+		Expect().Body().JSON().Equal(map[string]interface{}{
+			"message": "Unfreeze account error. Illegal status transition",
+		}),
+	)
+
+	Test(as.T(),
+		Post(transferBalancePath),
+		Send().Body().JSON(map[string]interface{}{
+			"sender_id":   2,
+			"receiver_id": 1,
+			"amount":      10,
+		}),
+		Expect().Status().Equal(http.StatusNotFound),
+	)
+
+	Test(as.T(),
+		Post(addBalancePath),
+		Send().Body().JSON(map[string]interface{}{
+			"account_id": 2,
+			"amount":     100,
+		}),
+		Expect().Status().Equal(http.StatusOK),
+	)
+
+	Test(as.T(),
+		Post(transferBalancePath),
+		Send().Body().JSON(map[string]interface{}{
+			"sender_id":   2,
+			"receiver_id": 1,
+			"amount":      10,
+		}),
+		Expect().Status().Equal(http.StatusConflict),
+		Expect().Body().JSON().Equal(map[string]interface{}{
+			"message": "Transfer balance error. Sender or receiver is closed",
+		}),
+	)
+}
+
+func (as *APISuite) TestAccountCreditLimit() {
+	Test(as.T(),
+		Post(addBalancePath),
+		Send().Body().JSON(map[string]interface{}{
+			"account_id": 1,
+			"amount":     100,
+		}),
+		Expect().Status().Equal(http.StatusOK),
+	)
+
+	Test(as.T(),
+		Post(addBalancePath),
+		Send().Body().JSON(map[string]interface{}{
+			"account_id": 2,
+			"amount":     0,
+		}),
+		Expect().Status().Equal(http.StatusOK),
+	)
+
+	Test(as.T(),
+		Post(transferBalancePath),
+		Send().Body().JSON(map[string]interface{}{
+			"sender_id":   1,
+			"receiver_id": 2,
+			"amount":      150,
+		}),
+		Expect().Status().Equal(http.StatusPaymentRequired),
+		Expect().Body().JSON().Equal(map[string]interface{}{
+			"message":      "Transfer balance error. Insufficient funds",
+			"available":    100,
+			"credit_limit": 0,
+			"currency":     "RUB",
+		}),
+	)
+
+	Test(as.T(),
+		Post(accountPath+"/1/credit-limit"),
+		Send().Body().JSON(map[string]interface{}{
+			"credit_limit": 100,
+		}),
+		Expect().Status().Equal(http.StatusOK),
+		Expect().Body().JSON().Equal(map[string]interface{}{
+			"account_id":   1,
+			"status":       "active",
+			"currency":     "RUB",
+			"available":    100,
+			"reserved":     0,
+			"total":        100,
+			"credit_limit": 100,
+		}),
+	)
+
+	Test(as.T(),
+		Post(transferBalancePath),
+		Send().Body().JSON(map[string]interface{}{
+			"sender_id":   1,
+			"receiver_id": 2,
+			"amount":      150,
+		}),
+		Expect().Status().Equal(http.StatusOK),
+		Expect().Body().JSON().Equal(map[string]interface{}{
+			"sender_balance":   -50,
+			"receiver_balance": 150,
+		}),
+	)
+
+	Test(as.T(),
+		Post(createOrderPath),
+		Send().Body().JSON(map[string]interface{}{
+			"order_id":   1,
+			"account_id": 1,
+			"service_id": 1,
+			"amount":     60,
+		}),
+		Expect().Status().Equal(http.StatusPaymentRequired),
+		Expect().Body().JSON().Equal(map[string]interface{}{
+			"message":      "Create order error. Insufficient funds",
+			"available":    -50,
+			"credit_limit": 100,
+			"currency":     "RUB",
+		}),
+	)
+
+	Test(as.T(),
+		Post(accountPath+"/1/credit-limit"),
+		Send().Body().JSON(map[string]interface{}{
+			"credit_limit": 10,
+		}),
+		Expect().Status().Equal(http.StatusConflict),
+		Expect().Body().JSON().Equal(map[string]interface{}{
+			"message": "Set credit limit error. Balance is below the new credit limit",
+		}),
+	)
+
+	Test(as.T(),
+		Get(reconciliationPath),
+		Expect().Status().Equal(http.StatusOK),
+		Expect().Body().JSON().JQ(".is_balanced").Equal(true),
+	)
+}
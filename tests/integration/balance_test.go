@@ -2,6 +2,8 @@ package integration
 
 import (
 	"net/http"
+	"strconv"
+	"sync"
 
 	. "github.com/Eun/go-hit"
 )
@@ -10,6 +12,7 @@ const (
 	addBalancePath      = basePath + "/balance/add"
 	getBalancePath      = basePath + "/balance/"
 	transferBalancePath = basePath + "/balance/transfer"
+	transferBatchPath   = basePath + "/balance/transfer/batch"
 )
 
 func (as *APISuite) TestAddBalance() {
@@ -79,7 +82,10 @@ func (as *APISuite) TestGetBalance() {
 		Get(getBalancePath+"1"),
 		Expect().Status().Equal(http.StatusOK),
 		Expect().Body().JSON().Equal(map[string]interface{}{
-			"balance": 100,
+			"balance":   100,
+			"available": 100,
+			"reserved":  0,
+			"total":     100,
 		}),
 	)
 
@@ -212,3 +218,322 @@ func (as *APISuite) TestTransferBalance() {
 		}),
 	)
 }
+
+func (as *APISuite) TestMultiCurrencyBalance() {
+	Test(as.T(),
+		Post(addBalancePath),
+		Send().Body().JSON(map[string]interface{}{
+			"account_id": 1,
+			"amount":     100,
+		}),
+		Expect().Status().Equal(http.StatusOK),
+		Expect().Body().JSON().Equal(map[string]interface{}{
+			"balance": 100,
+		}),
+	)
+
+	Test(as.T(),
+		Post(addBalancePath),
+		Send().Body().JSON(map[string]interface{}{
+			"account_id": 1,
+			"amount":     500,
+			"currency":   "USD",
+		}),
+		Expect().Status().Equal(http.StatusOK),
+		Expect().Body().JSON().Equal(map[string]interface{}{
+			"balance": 500,
+		}),
+	)
+
+	Test(as.T(),
+		Post(addBalancePath),
+		Send().Body().JSON(map[string]interface{}{
+			"account_id": 1,
+			"amount":     100,
+			"currency":   "XXX",
+		}),
+		Expect().Status().Equal(http.StatusBadRequest),
+		Expect().Body().JSON().Equal(map[string]interface{}{
+			"message": "Amount not added. request is not valid",
+		}),
+	)
+
+	Test(as.T(),
+		Get(getBalancePath+"1"),
+		Expect().Status().Equal(http.StatusOK),
+		Expect().Body().JSON().Equal(map[string]interface{}{
+			"balance":   100,
+			"available": 100,
+			"reserved":  0,
+			"total":     100,
+		}),
+	)
+
+	Test(as.T(),
+		Get(getBalancePath+"1?currency=USD"),
+		Expect().Status().Equal(http.StatusOK),
+		Expect().Body().JSON().Equal(map[string]interface{}{
+			"balance":   500,
+			"available": 500,
+			"reserved":  0,
+			"total":     500,
+		}),
+	)
+
+	Test(as.T(),
+		Get(getBalancePath+"1?currency=EUR"),
+		Expect().Status().Equal(http.StatusOK),
+		Expect().Body().JSON().Equal(map[string]interface{}{
+			"balance":   0,
+			"available": 0,
+			"reserved":  0,
+			"total":     0,
+		}),
+	)
+
+	Test(as.T(),
+		Get(getBalancePath+"1?currency=XXX"),
+		Expect().Status().Equal(http.StatusBadRequest),
+		Expect().Body().JSON().Equal(map[string]interface{}{
+			"message": "Balance not found. currency is not valid",
+		}),
+	)
+
+	Test(as.T(),
+		Post(addBalancePath),
+		Send().Body().JSON(map[string]interface{}{
+			"account_id": 2,
+			"amount":     100,
+		}),
+		Expect().Status().Equal(http.StatusOK),
+	)
+
+	Test(as.T(),
+		Post(transferBalancePath),
+		Send().Body().JSON(map[string]interface{}{
+			"sender_id":   1,
+			"receiver_id": 2,
+			"amount":      200,
+			"currency":    "USD",
+		}),
+		Expect().Status().Equal(http.StatusOK),
+		Expect().Body().JSON().Equal(map[string]interface{}{
+			"sender_balance":   300,
+			"receiver_balance": 200,
+		}),
+	)
+
+	Test(as.T(),
+		Get(getBalancePath+"2"),
+		Expect().Status().Equal(http.StatusOK),
+		Expect().Body().JSON().Equal(map[string]interface{}{
+			"balance":   100,
+			"available": 100,
+			"reserved":  0,
+			"total":     100,
+		}),
+	)
+}
+
+func (as *APISuite) TestCurrencyConversionTransfer() {
+	Test(as.T(),
+		Post(addBalancePath),
+		Send().Body().JSON(map[string]interface{}{
+			"account_id": 1,
+			"amount":     101,
+			"currency":   "USD",
+		}),
+		Expect().Status().Equal(http.StatusOK),
+	)
+
+	Test(as.T(),
+		Post(addBalancePath),
+		Send().Body().JSON(map[string]interface{}{
+			"account_id": 2,
+			"amount":     100,
+		}),
+		Expect().Status().Equal(http.StatusOK),
+	)
+
+	Test(as.T(),
+		Post(transferBalancePath),
+		Send().Body().JSON(map[string]interface{}{
+			"sender_id":         1,
+			"receiver_id":       2,
+			"amount":            101,
+			"currency":          "USD",
+			"receiver_currency": "RUB",
+		}),
+		Expect().Status().Equal(http.StatusOK),
+		Expect().Body().JSON().Equal(map[string]interface{}{
+			"sender_balance":   0,
+			"receiver_balance": 6311,
+		}),
+	)
+
+	Test(as.T(),
+		Get(getTransactionsByAccountIDPath+"1"),
+		Expect().Status().Equal(http.StatusOK),
+		Expect().Body().JSON().JQ(".transactions.[1]").JQ(".type").Equal("transfer"),
+		Expect().Body().JSON().JQ(".transactions.[1]").JQ(".amount").Equal(101),
+		Expect().Body().JSON().JQ(".transactions.[1]").JQ(".currency").Equal("USD"),
+		Expect().Body().
+			JSON().
+			JQ(".transactions.[1]").
+			JQ(".description").
+			Equal("Transfer 1.01 USD from account with id = 1 to account with id = 2 as 62.11 RUB at rate 61.5"),
+		Expect().Body().JSON().JQ(".transactions.[1]").JQ(".conversion").Equal(map[string]interface{}{
+			"rate":               "61.5",
+			"amount":             6211,
+			"currency":           "RUB",
+			"rounding_remainder": "0.5",
+		}),
+	)
+
+	Test(as.T(),
+		Post(transferBalancePath),
+		Send().Body().JSON(map[string]interface{}{
+			"sender_id":         2,
+			"receiver_id":       1,
+			"amount":            100,
+			"currency":          "RUB",
+			"receiver_currency": "JPY",
+		}),
+		Expect().Status().Equal(http.StatusBadRequest),
+		Expect().Body().JSON().Equal(map[string]interface{}{
+			"message": "Transfer balance error. Exchange rate not found",
+		}),
+	)
+
+	Test(as.T(),
+		Get(reconciliationPath),
+		Expect().Status().Equal(http.StatusOK),
+		Expect().Body().JSON().JQ(".is_balanced").Equal(true),
+	)
+}
+
+func (as *APISuite) TestTransferBatch() {
+	for accountID, amount := range map[int]int{1: 300, 2: 100, 3: 50} {
+		Test(as.T(),
+			Post(addBalancePath),
+			Send().Body().JSON(map[string]interface{}{
+				"account_id": accountID,
+				"amount":     amount,
+			}),
+			Expect().Status().Equal(http.StatusOK),
+		)
+	}
+
+	Test(as.T(),
+		Post(transferBatchPath),
+		Send().Body().JSON(map[string]interface{}{
+			"transfers": []map[string]interface{}{
+				{"sender_id": 1, "receiver_id": 2, "amount": 100},
+				{"sender_id": 1, "receiver_id": 3, "amount": 150},
+			},
+		}),
+		Expect().Status().Equal(http.StatusOK),
+		Expect().Body().JSON().Equal(map[string]interface{}{
+			"transfers": []map[string]interface{}{
+				{"sender_balance": 200, "receiver_balance": 200},
+				{"sender_balance": 50, "receiver_balance": 200},
+			},
+		}),
+	)
+
+	Test(as.T(),
+		Post(transferBatchPath),
+		Send().Body().JSON(map[string]interface{}{
+			"transfers": []map[string]interface{}{
+				{"sender_id": 2, "receiver_id": 1, "amount": 100},
+				{"sender_id": 1, "receiver_id": 3, "amount": 200},
+			},
+		}),
+		Expect().Status().Equal(http.StatusPaymentRequired),
+		Expect().Body().JSON().Equal(map[string]interface{}{
+			"message": "Batch not transferred",
+			"errors": []map[string]interface{}{
+				{
+					"index":   1,
+					"message": "Insufficient funds. Account with id = 1 has 1.50 RUB available and 0.00 RUB credit limit",
+				},
+			},
+		}),
+	)
+
+	// the first transfer of the failed batch is rolled back.
+	for accountID, balance := range map[int]int{1: 50, 2: 200} {
+		Test(as.T(),
+			Get(getBalancePath+strconv.Itoa(accountID)),
+			Expect().Status().Equal(http.StatusOK),
+			Expect().Body().JSON().JQ(".available").Equal(balance),
+		)
+	}
+
+	Test(as.T(),
+		Post(transferBatchPath),
+		Send().Body().JSON(map[string]interface{}{
+			"transfers": []map[string]interface{}{
+				{"sender_id": 1, "receiver_id": 2, "amount": 0},
+			},
+		}),
+		Expect().Status().Equal(http.StatusBadRequest),
+		Expect().Body().JSON().Equal(map[string]interface{}{
+			"message": "Batch not transferred. transfers are not valid",
+			"errors": []map[string]interface{}{
+				{"index": 0, "message": "Transfer is not valid. Amount failed on the required rule"},
+			},
+		}),
+	)
+}
+
+func (as *APISuite) TestConcurrentOppositeTransfers() {
+	const transfers = 20
+
+	for _, accountID := range []int{1, 2} {
+		Test(as.T(),
+			Post(addBalancePath),
+			Send().Body().JSON(map[string]interface{}{
+				"account_id": accountID,
+				"amount":     1000,
+			}),
+			Expect().Status().Equal(http.StatusOK),
+		)
+	}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 2*transfers)
+	for i := 0; i < transfers; i++ {
+		for _, pair := range [][2]int{{1, 2}, {2, 1}} {
+			wg.Add(1)
+
+			go func(senderID, receiverID int) {
+				defer wg.Done()
+
+				errs <- Do(
+					Post(transferBalancePath),
+					Send().Body().JSON(map[string]interface{}{
+						"sender_id":   senderID,
+						"receiver_id": receiverID,
+						"amount":      10,
+					}),
+					Expect().Status().Equal(http.StatusOK),
+				)
+			}(pair[0], pair[1])
+		}
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		as.Require().NoError(err)
+	}
+
+	for _, accountID := range []int{1, 2} {
+		Test(as.T(),
+			Get(getBalancePath+strconv.Itoa(accountID)),
+			Expect().Status().Equal(http.StatusOK),
+			Expect().Body().JSON().JQ(".available").Equal(1000),
+		)
+	}
+}
@@ -0,0 +1,125 @@
+package integration
+
+import (
+	"net/http"
+
+	. "github.com/Eun/go-hit"
+)
+
+const idempotencyKeyHeader = "Idempotency-Key"
+
+func (as *APISuite) TestIdempotentAddBalance() {
+	for i := 0; i < 2; i++ {
+		Test(as.T(),
+			Post(addBalancePath),
+			Send().Headers(idempotencyKeyHeader).Add("add-balance-key"),
+			Send().Body().JSON(map[string]interface{}{
+				"account_id": 1,
+				"amount":     100,
+			}),
+			Expect().Status().Equal(http.StatusOK),
+			Expect().Body().JSON().Equal(map[string]interface{}{
+				"balance": 100,
+			}),
+		)
+	}
+
+	Test(as.T(),
+		Get(getBalancePath+"1"),
+		Expect().Status().Equal(http.StatusOK),
+		Expect().Body().JSON().Equal(map[string]interface{}{
+			"balance":   100,
+			"available": 100,
+			"reserved":  0,
+			"total":     100,
+		}),
+	)
+
+	Test(as.T(),
+		Post(addBalancePath),
+		Send().Headers(idempotencyKeyHeader).Add("add-balance-key"),
+		Send().Body().JSON(map[string]interface{}{
+			"account_id": 1,
+			"amount":     50,
+		}),
+		Expect().Status().Equal(http.StatusConflict),
+		Expect().Body().JSON().Equal(map[string]interface{}{
+			"message": "Idempotent request error. Idempotency key is already used with another request",
+		}),
+	)
+}
+
+func (as *APISuite) TestIdempotentTransferBalance() {
+	Test(as.T(),
+		Post(addBalancePath),
+		Send().Body().JSON(map[string]interface{}{
+			"account_id": 1,
+			"amount":     200,
+		}),
+		Expect().Status().Equal(http.StatusOK),
+	)
+
+	Test(as.T(),
+		Post(addBalancePath),
+		Send().Body().JSON(map[string]interface{}{
+			"account_id": 2,
+			"amount":     100,
+		}),
+		Expect().Status().Equal(http.StatusOK),
+	)
+
+	for i := 0; i < 2; i++ {
+		Test(as.T(),
+			Post(transferBalancePath),
+			Send().Headers(idempotencyKeyHeader).Add("transfer-balance-key"),
+			Send().Body().JSON(map[string]interface{}{
+				"sender_id":   1,
+				"receiver_id": 2,
+				"amount":      100,
+			}),
+			Expect().Status().Equal(http.StatusOK),
+			Expect().Body().JSON().Equal(map[string]interface{}{
+				"sender_balance":   100,
+				"receiver_balance": 200,
+			}),
+		)
+	}
+
+	Test(as.T(),
+		Get(getBalancePath+"1"),
+		Expect().Status().Equal(http.StatusOK),
+		Expect().Body().JSON().Equal(map[string]interface{}{
+			"balance":   100,
+			"available": 100,
+			"reserved":  0,
+			"total":     100,
+		}),
+	)
+}
+
+func (as *APISuite) TestIdempotentCreateOrder() {
+	Test(as.T(),
+		Post(addBalancePath),
+		Send().Body().JSON(map[string]interface{}{
+			"account_id": 1,
+			"amount":     100,
+		}),
+		Expect().Status().Equal(http.StatusOK),
+	)
+
+	for i := 0; i < 2; i++ {
+		Test(as.T(),
+			Post(createOrderPath),
+			Send().Headers(idempotencyKeyHeader).Add("create-order-key"),
+			Send().Body().JSON(map[string]interface{}{
+				"order_id":   1,
+				"account_id": 1,
+				"service_id": 1,
+				"amount":     40,
+			}),
+			Expect().Status().Equal(http.StatusOK),
+			Expect().Body().JSON().JQ(".order").JQ(".order_id").Equal(1),
+			Expect().Body().JSON().JQ(".balance").Equal(60),
+		)
+	}
+}
@@ -0,0 +1,85 @@
+package integration
+
+import (
+	"net/http"
+
+	. "github.com/Eun/go-hit"
+)
+
+const reconciliationPath = basePath + "/ledger/reconciliation"
+
+func (as *APISuite) TestLedgerReconciliation() {
+	Test(as.T(),
+		Post(addBalancePath),
+		Send().Body().JSON(map[string]interface{}{
+			"account_id": 1,
+			"amount":     300,
+		}),
+		Expect().Status().Equal(http.StatusOK),
+	)
+
+	Test(as.T(),
+		Post(addBalancePath),
+		Send().Body().JSON(map[string]interface{}{
+			"account_id": 2,
+			"amount":     100,
+		}),
+		Expect().Status().Equal(http.StatusOK),
+	)
+
+	Test(as.T(),
+		Post(transferBalancePath),
+		Send().Body().JSON(map[string]interface{}{
+			"sender_id":   1,
+			"receiver_id": 2,
+			"amount":      100,
+		}),
+		Expect().Status().Equal(http.StatusOK),
+	)
+
+	for _, orderID := range []int{1, 2} {
+		Test(as.T(),
+			Post(createOrderPath),
+			Send().Body().JSON(map[string]interface{}{
+				"order_id":   orderID,
+				"account_id": 1,
+				"service_id": 1,
+				"amount":     50,
+			}),
+			Expect().Status().Equal(http.StatusOK),
+		)
+	}
+
+	Test(as.T(),
+		Post(payForOrderPath),
+		Send().Body().JSON(map[string]interface{}{
+			"order_id":   1,
+			"account_id": 1,
+			"service_id": 1,
+			"amount":     50,
+		}),
+		Expect().Status().Equal(http.StatusOK),
+	)
+
+	Test(as.T(),
+		Post(cancelOrderPath),
+		Send().Body().JSON(map[string]interface{}{
+			"order_id":   2,
+			"account_id": 1,
+			"service_id": 1,
+			"amount":     50,
+		}),
+		Expect().Status().Equal(http.StatusOK),
+	)
+
+	Test(as.T(),
+		Get(reconciliationPath),
+		Expect().Status().Equal(http.StatusOK),
+		Expect().Body().JSON().Equal(map[string]interface{}{
+			"total_debit":  700,
+			"total_credit": 700,
+			"is_balanced":  true,
+			"mismatches":   []interface{}{},
+		}),
+	)
+}
@@ -41,8 +41,18 @@ func (as *APISuite) SetupSuite() {
 	as.db = client
 }
 
+func (as *APISuite) SetupTest() {
+	for serviceID := 1; serviceID <= catalogSize; serviceID++ {
+		as.createService(serviceID)
+	}
+}
+
 func (as *APISuite) TearDownTest() {
-	_, err := as.db.Pool.Exec(context.Background(), "TRUNCATE TABLE accounts, transactions, orders CASCADE")
+	_, err := as.db.Pool.Exec(
+		context.Background(),
+		"TRUNCATE TABLE accounts, account_balances, transactions, orders, order_status_history, idempotency_keys, "+
+			"postings, posting_lines, webhook_subscriptions, report_jobs, report_snapshots, services CASCADE",
+	)
 	as.Require().NoError(err)
 }
 
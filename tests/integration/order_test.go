@@ -2,14 +2,17 @@ package integration
 
 import (
 	"net/http"
+	"time"
 
 	. "github.com/Eun/go-hit"
 )
 
 const (
-	createOrderPath = basePath + "/order/create"
-	payForOrderPath = basePath + "/order/pay"
-	cancelOrderPath = basePath + "/order/cancel"
+	createOrderPath  = basePath + "/order/create"
+	payForOrderPath  = basePath + "/order/pay"
+	cancelOrderPath  = basePath + "/order/cancel"
+	refundOrderPath  = basePath + "/order/refund"
+	captureOrderPath = basePath + "/order/capture"
 )
 
 func (as *APISuite) TestCreateOrder() {
@@ -38,8 +41,7 @@ func (as *APISuite) TestCreateOrder() {
 		Expect().Body().JSON().JQ(".order").JQ(".account_id").Equal(1),
 		Expect().Body().JSON().JQ(".order").JQ(".service_id").Equal(1),
 		Expect().Body().JSON().JQ(".order").JQ(".amount").Equal(40),
-		Expect().Body().JSON().JQ(".order").JQ(".is_paid").Equal(false),
-		Expect().Body().JSON().JQ(".order").JQ(".is_cancelled").Equal(false),
+		Expect().Body().JSON().JQ(".order").JQ(".status").Equal("reserved"),
 		Expect().Body().JSON().JQ(".balance").Equal(60),
 	)
 
@@ -154,8 +156,7 @@ func (as *APISuite) TestPayForOrder() {
 		Expect().Body().JSON().JQ(".order").JQ(".account_id").Equal(1),
 		Expect().Body().JSON().JQ(".order").JQ(".service_id").Equal(1),
 		Expect().Body().JSON().JQ(".order").JQ(".amount").Equal(40),
-		Expect().Body().JSON().JQ(".order").JQ(".is_paid").Equal(false),
-		Expect().Body().JSON().JQ(".order").JQ(".is_cancelled").Equal(false),
+		Expect().Body().JSON().JQ(".order").JQ(".status").Equal("reserved"),
 		Expect().Body().JSON().JQ(".balance").Equal(60),
 	)
 
@@ -172,8 +173,7 @@ func (as *APISuite) TestPayForOrder() {
 		Expect().Body().JSON().JQ(".order").JQ(".account_id").Equal(1),
 		Expect().Body().JSON().JQ(".order").JQ(".service_id").Equal(1),
 		Expect().Body().JSON().JQ(".order").JQ(".amount").Equal(50),
-		Expect().Body().JSON().JQ(".order").JQ(".is_paid").Equal(false),
-		Expect().Body().JSON().JQ(".order").JQ(".is_cancelled").Equal(false),
+		Expect().Body().JSON().JQ(".order").JQ(".status").Equal("reserved"),
 		Expect().Body().JSON().JQ(".balance").Equal(10),
 	)
 
@@ -196,9 +196,9 @@ func (as *APISuite) TestPayForOrder() {
 			"service_id": 1,
 			"amount":     40,
 		}),
-		Expect().Status().Equal(http.StatusNotFound),
+		Expect().Status().Equal(http.StatusConflict),
 		Expect().Body().JSON().Equal(map[string]interface{}{
-			"message": "Pay for order error. Order not found",
+			"message": "Pay for order error. Order can not be paid",
 		}),
 	)
 
@@ -299,8 +299,7 @@ func (as *APISuite) TestCancelOrder() {
 		Expect().Body().JSON().JQ(".order").JQ(".account_id").Equal(1),
 		Expect().Body().JSON().JQ(".order").JQ(".service_id").Equal(1),
 		Expect().Body().JSON().JQ(".order").JQ(".amount").Equal(40),
-		Expect().Body().JSON().JQ(".order").JQ(".is_paid").Equal(false),
-		Expect().Body().JSON().JQ(".order").JQ(".is_cancelled").Equal(false),
+		Expect().Body().JSON().JQ(".order").JQ(".status").Equal("reserved"),
 		Expect().Body().JSON().JQ(".balance").Equal(60),
 	)
 
@@ -317,8 +316,7 @@ func (as *APISuite) TestCancelOrder() {
 		Expect().Body().JSON().JQ(".order").JQ(".account_id").Equal(1),
 		Expect().Body().JSON().JQ(".order").JQ(".service_id").Equal(1),
 		Expect().Body().JSON().JQ(".order").JQ(".amount").Equal(50),
-		Expect().Body().JSON().JQ(".order").JQ(".is_paid").Equal(false),
-		Expect().Body().JSON().JQ(".order").JQ(".is_cancelled").Equal(false),
+		Expect().Body().JSON().JQ(".order").JQ(".status").Equal("reserved"),
 		Expect().Body().JSON().JQ(".balance").Equal(10),
 	)
 
@@ -344,9 +342,9 @@ func (as *APISuite) TestCancelOrder() {
 			"service_id": 1,
 			"amount":     40,
 		}),
-		Expect().Status().Equal(http.StatusNotFound),
+		Expect().Status().Equal(http.StatusConflict),
 		Expect().Body().JSON().Equal(map[string]interface{}{
-			"message": "Cancel order error. Order not found",
+			"message": "Cancel order error. Order can not be cancelled",
 		}),
 	)
 
@@ -434,3 +432,361 @@ func (as *APISuite) TestCancelOrder() {
 		}),
 	)
 }
+
+func (as *APISuite) TestRefundOrder() {
+	Test(as.T(),
+		Post(addBalancePath),
+		Send().Body().JSON(map[string]interface{}{
+			"account_id": 1,
+			"amount":     100,
+		}),
+		Expect().Status().Equal(http.StatusOK),
+	)
+
+	Test(as.T(),
+		Post(createOrderPath),
+		Send().Body().JSON(map[string]interface{}{
+			"order_id":   1,
+			"account_id": 1,
+			"service_id": 1,
+			"amount":     40,
+		}),
+		Expect().Status().Equal(http.StatusOK),
+		Expect().Body().JSON().JQ(".balance").Equal(60),
+	)
+
+	Test(as.T(),
+		Post(refundOrderPath),
+		Send().Body().JSON(map[string]interface{}{
+			"order_id":   1,
+			"account_id": 1,
+			"service_id": 1,
+		}),
+		Expect().Status().Equal(http.StatusConflict),
+		Expect().Body().JSON().Equal(map[string]interface{}{
+			"message": "Refund order error. Order can not be refunded",
+		}),
+	)
+
+	Test(as.T(),
+		Post(payForOrderPath),
+		Send().Body().JSON(map[string]interface{}{
+			"order_id":   1,
+			"account_id": 1,
+			"service_id": 1,
+			"amount":     40,
+		}),
+		Expect().Status().Equal(http.StatusOK),
+	)
+
+	Test(as.T(),
+		Post(refundOrderPath),
+		Send().Body().JSON(map[string]interface{}{
+			"order_id":   1,
+			"account_id": 1,
+			"service_id": 1,
+			"amount":     15,
+			"reason":     "Service is partially provided",
+		}),
+		Expect().Status().Equal(http.StatusOK),
+		Expect().Body().JSON().JQ(".order").JQ(".refunded_amount").Equal(15),
+		Expect().Body().JSON().JQ(".order").JQ(".status").Equal("paid"),
+		Expect().Body().JSON().JQ(".balance").Equal(75),
+	)
+
+	Test(as.T(),
+		Post(refundOrderPath),
+		Send().Body().JSON(map[string]interface{}{
+			"order_id":   1,
+			"account_id": 1,
+			"service_id": 1,
+			"amount":     30,
+		}),
+		Expect().Status().Equal(http.StatusConflict),
+		Expect().Body().JSON().Equal(map[string]interface{}{
+			"message": "Refund order error. Refund amount exceeds paid amount",
+		}),
+	)
+
+	Test(as.T(),
+		Post(refundOrderPath),
+		Send().Body().JSON(map[string]interface{}{
+			"order_id":   1,
+			"account_id": 1,
+			"service_id": 1,
+		}),
+		Expect().Status().Equal(http.StatusOK),
+		Expect().Body().JSON().JQ(".order").JQ(".refunded_amount").Equal(40),
+		Expect().Body().JSON().JQ(".order").JQ(".status").Equal("refunded"),
+		Expect().Body().JSON().JQ(".balance").Equal(100),
+	)
+
+	Test(as.T(),
+		Post(refundOrderPath),
+		Send().Body().JSON(map[string]interface{}{
+			"order_id":   2,
+			"account_id": 1,
+			"service_id": 1,
+		}),
+		Expect().Status().Equal(http.StatusNotFound),
+		Expect().Body().JSON().Equal(map[string]interface{}{
+			"message": "Refund order error. Order not found",
+		}),
+	)
+
+	Test(as.T(),
+		Post(refundOrderPath),
+		Send().Body().JSON(map[string]interface{}{
+			"order_id":   1,
+			"account_id": 1,
+			"service_id": 1,
+			"amount":     -10,
+		}),
+		Expect().Status().Equal(http.StatusBadRequest),
+		Expect().Body().JSON().Equal(map[string]interface{}{
+			"message": "Refund order error. Invalid request",
+		}),
+	)
+}
+
+func (as *APISuite) TestExpireOrder() {
+	Test(as.T(),
+		Post(addBalancePath),
+		Send().Body().JSON(map[string]interface{}{
+			"account_id": 1,
+			"amount":     100,
+		}),
+		Expect().Status().Equal(http.StatusOK),
+	)
+
+	Test(as.T(),
+		Post(createOrderPath),
+		Send().Body().JSON(map[string]interface{}{
+			"order_id":        1,
+			"account_id":      1,
+			"service_id":      1,
+			"amount":          40,
+			"reservation_ttl": 1,
+		}),
+		Expect().Status().Equal(http.StatusOK),
+		Expect().Body().JSON().JQ(".balance").Equal(60),
+	)
+
+	Test(as.T(),
+		Post(createOrderPath),
+		Send().Body().JSON(map[string]interface{}{
+			"order_id":   2,
+			"account_id": 1,
+			"service_id": 1,
+			"amount":     10,
+		}),
+		Expect().Status().Equal(http.StatusOK),
+		Expect().Body().JSON().JQ(".balance").Equal(50),
+	)
+
+	// the expiry worker runs every second in the test environment.
+	time.Sleep(3 * time.Second)
+
+	Test(as.T(),
+		Get(getBalancePath+"1"),
+		Expect().Status().Equal(http.StatusOK),
+		Expect().Body().JSON().Equal(map[string]interface{}{
+			"balance":   90,
+			"available": 90,
+			"reserved":  10,
+			"total":     100,
+		}),
+	)
+
+	Test(as.T(),
+		Post(payForOrderPath),
+		Send().Body().JSON(map[string]interface{}{
+			"order_id":   1,
+			"account_id": 1,
+			"service_id": 1,
+			"amount":     40,
+		}),
+		Expect().Status().Equal(http.StatusConflict),
+		Expect().Body().JSON().Equal(map[string]interface{}{
+			"message": "Pay for order error. Order can not be paid",
+		}),
+	)
+
+	Test(as.T(),
+		Post(payForOrderPath),
+		Send().Body().JSON(map[string]interface{}{
+			"order_id":   2,
+			"account_id": 1,
+			"service_id": 1,
+			"amount":     10,
+		}),
+		Expect().Status().Equal(http.StatusOK),
+	)
+}
+
+func (as *APISuite) TestCaptureOrder() {
+	Test(as.T(),
+		Post(addBalancePath),
+		Send().Body().JSON(map[string]interface{}{
+			"account_id": 1,
+			"amount":     100,
+		}),
+		Expect().Status().Equal(http.StatusOK),
+	)
+
+	Test(as.T(),
+		Post(createOrderPath),
+		Send().Body().JSON(map[string]interface{}{
+			"order_id":   1,
+			"account_id": 1,
+			"service_id": 1,
+			"amount":     40,
+		}),
+		Expect().Status().Equal(http.StatusOK),
+		Expect().Body().JSON().JQ(".balance").Equal(60),
+	)
+
+	Test(as.T(),
+		Post(captureOrderPath),
+		Send().Body().JSON(map[string]interface{}{
+			"order_id":   1,
+			"account_id": 1,
+			"service_id": 1,
+			"amount":     50,
+		}),
+		Expect().Status().Equal(http.StatusConflict),
+		Expect().Body().JSON().Equal(map[string]interface{}{
+			"message": "Capture order error. Capture amount exceeds reserved amount",
+		}),
+	)
+
+	Test(as.T(),
+		Post(captureOrderPath),
+		Send().Body().JSON(map[string]interface{}{
+			"order_id":   1,
+			"account_id": 1,
+			"service_id": 1,
+			"amount":     30,
+			"reason":     "Service is provided with discount",
+		}),
+		Expect().Status().Equal(http.StatusOK),
+		Expect().Body().JSON().JQ(".order").JQ(".amount").Equal(40),
+		Expect().Body().JSON().JQ(".order").JQ(".captured_amount").Equal(30),
+		Expect().Body().JSON().JQ(".order").JQ(".status").Equal("paid"),
+	)
+
+	Test(as.T(),
+		Get(getBalancePath+"1"),
+		Expect().Status().Equal(http.StatusOK),
+		Expect().Body().JSON().Equal(map[string]interface{}{
+			"balance":   70,
+			"available": 70,
+			"reserved":  0,
+			"total":     70,
+		}),
+	)
+
+	Test(as.T(),
+		Post(captureOrderPath),
+		Send().Body().JSON(map[string]interface{}{
+			"order_id":   1,
+			"account_id": 1,
+			"service_id": 1,
+			"amount":     30,
+		}),
+		Expect().Status().Equal(http.StatusConflict),
+		Expect().Body().JSON().Equal(map[string]interface{}{
+			"message": "Capture order error. Order can not be captured",
+		}),
+	)
+
+	Test(as.T(),
+		Post(refundOrderPath),
+		Send().Body().JSON(map[string]interface{}{
+			"order_id":   1,
+			"account_id": 1,
+			"service_id": 1,
+		}),
+		Expect().Status().Equal(http.StatusOK),
+		Expect().Body().JSON().JQ(".order").JQ(".refunded_amount").Equal(30),
+		Expect().Body().JSON().JQ(".order").JQ(".status").Equal("refunded"),
+		Expect().Body().JSON().JQ(".balance").Equal(100),
+	)
+
+	Test(as.T(),
+		Post(captureOrderPath),
+		Send().Body().JSON(map[string]interface{}{
+			"order_id":   2,
+			"account_id": 1,
+			"service_id": 1,
+			"amount":     30,
+		}),
+		Expect().Status().Equal(http.StatusNotFound),
+		Expect().Body().JSON().Equal(map[string]interface{}{
+			"message": "Capture order error. Order not found",
+		}),
+	)
+}
+
+func (as *APISuite) TestOrderCurrencyMismatch() {
+	Test(as.T(),
+		Post(addBalancePath),
+		Send().Body().JSON(map[string]interface{}{
+			"account_id": 1,
+			"amount":     100,
+			"currency":   "EUR",
+		}),
+		Expect().Status().Equal(http.StatusOK),
+	)
+
+	Test(as.T(),
+		Post(createOrderPath),
+		Send().Body().JSON(map[string]interface{}{
+			"order_id":   1,
+			"account_id": 1,
+			"service_id": 1,
+			"amount":     40,
+			"currency":   "EUR",
+		}),
+		Expect().Status().Equal(http.StatusOK),
+		Expect().Body().JSON().JQ(".order").JQ(".currency").Equal("EUR"),
+		Expect().Body().JSON().JQ(".balance").Equal(60),
+	)
+
+	Test(as.T(),
+		Post(payForOrderPath),
+		Send().Body().JSON(map[string]interface{}{
+			"order_id":   1,
+			"account_id": 1,
+			"service_id": 1,
+			"amount":     40,
+		}),
+		Expect().Status().Equal(http.StatusConflict),
+		Expect().Body().JSON().Equal(map[string]interface{}{
+			"message": "Pay for order error. Currency does not match order currency",
+		}),
+	)
+
+	Test(as.T(),
+		Post(payForOrderPath),
+		Send().Body().JSON(map[string]interface{}{
+			"order_id":   1,
+			"account_id": 1,
+			"service_id": 1,
+			"amount":     40,
+			"currency":   "EUR",
+		}),
+		Expect().Status().Equal(http.StatusOK),
+	)
+
+	Test(as.T(),
+		Get(getBalancePath+"1?currency=EUR"),
+		Expect().Status().Equal(http.StatusOK),
+		Expect().Body().JSON().Equal(map[string]interface{}{
+			"balance":   60,
+			"available": 60,
+			"reserved":  0,
+			"total":     60,
+		}),
+	)
+}
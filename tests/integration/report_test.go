@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"math/rand"
 	"net/http"
+	"strings"
 	"time"
 
 	. "github.com/Eun/go-hit"
@@ -13,8 +14,20 @@ import (
 
 const (
 	getReportLinkPath = basePath + "/report/link"
+	reportJobsPath    = basePath + "/report/jobs"
+
+	reportJobAttempts = 20
+	reportJobDelay    = 500 * time.Millisecond
 )
 
+type reportJob struct {
+	JobID  int64  `json:"job_id"`
+	Format string `json:"format"`
+	Status string `json:"status"`
+	Link   string `json:"link"`
+	Error  string `json:"error"`
+}
+
 func (as *APISuite) generateRandomReport(accountNumber, serviceNumber, orderNumber, initBalance int) []int {
 	rand.Seed(time.Now().UnixNano())
 
@@ -57,8 +70,7 @@ func (as *APISuite) generateRandomReport(accountNumber, serviceNumber, orderNumb
 			Expect().Body().JSON().JQ(".order").JQ(".account_id").Equal(accountID),
 			Expect().Body().JSON().JQ(".order").JQ(".service_id").Equal(serviceID),
 			Expect().Body().JSON().JQ(".order").JQ(".amount").Equal(amount),
-			Expect().Body().JSON().JQ(".order").JQ(".is_paid").Equal(false),
-			Expect().Body().JSON().JQ(".order").JQ(".is_cancelled").Equal(false),
+			Expect().Body().JSON().JQ(".order").JQ(".status").Equal("reserved"),
 			Expect().Body().JSON().JQ(".balance").Equal(accountBalances[accountID]),
 		)
 
@@ -81,11 +93,16 @@ func (as *APISuite) serviceAmountsToCSV(serviceAmounts []int) []byte {
 	var buffer bytes.Buffer
 	writer := csv.NewWriter(&buffer)
 
-	as.Require().NoError(writer.Write([]string{"service_id", "amount"}))
+	as.Require().NoError(writer.Write([]string{"service_id", "service_name", "currency", "amount"}))
 	for serviceID := 1; serviceID < len(serviceAmounts); serviceID++ {
 		amount := serviceAmounts[serviceID]
 		if amount > 0 {
-			as.Require().NoError(writer.Write([]string{fmt.Sprintf("%d", serviceID), fmt.Sprintf("%d", amount)}))
+			as.Require().NoError(writer.Write([]string{
+				fmt.Sprintf("%d", serviceID),
+				serviceName(serviceID),
+				"RUB",
+				fmt.Sprintf("%d", amount),
+			}))
 		}
 	}
 	writer.Flush()
@@ -95,6 +112,23 @@ func (as *APISuite) serviceAmountsToCSV(serviceAmounts []int) []byte {
 	return buffer.Bytes()
 }
 
+func (as *APISuite) serviceAmountsToJSON(serviceAmounts []int) []map[string]interface{} {
+	entries := make([]map[string]interface{}, 0, len(serviceAmounts))
+	for serviceID := 1; serviceID < len(serviceAmounts); serviceID++ {
+		amount := serviceAmounts[serviceID]
+		if amount > 0 {
+			entries = append(entries, map[string]interface{}{
+				"service_id":   serviceID,
+				"service_name": serviceName(serviceID),
+				"currency":     "RUB",
+				"amount":       amount,
+			})
+		}
+	}
+
+	return entries
+}
+
 func getNextMonth(month time.Month) time.Month {
 	if month == time.December {
 		return time.January
@@ -102,6 +136,37 @@ func getNextMonth(month time.Month) time.Month {
 	return month + 1
 }
 
+// createReportJob requests a report and waits until the report job is finished.
+func (as *APISuite) createReportJob(body map[string]interface{}) reportJob {
+	var job reportJob
+	Test(as.T(),
+		Post(getReportLinkPath),
+		Send().Body().JSON(body),
+		Expect().Status().Equal(http.StatusAccepted),
+		Expect().Body().JSON().JQ(".status").Equal("queued"),
+		Store().Response().Body().JSON().In(&job),
+	)
+
+	// the report job workers run every second in the test environment.
+	for attempt := 0; attempt < reportJobAttempts; attempt++ {
+		Test(as.T(),
+			Get(fmt.Sprintf("%s/%d", reportJobsPath, job.JobID)),
+			Expect().Status().Equal(http.StatusOK),
+			Store().Response().Body().JSON().In(&job),
+		)
+
+		if job.Status == "done" || job.Status == "failed" {
+			return job
+		}
+
+		time.Sleep(reportJobDelay)
+	}
+
+	as.FailNow("report job is not finished", "job %d is %s", job.JobID, job.Status)
+
+	return job
+}
+
 func (as *APISuite) TestGetReportLink() {
 	year, month, _ := time.Now().Date()
 
@@ -113,9 +178,60 @@ func (as *APISuite) TestGetReportLink() {
 			"month": month,
 			"year":  year,
 		}),
-		Expect().Status().Equal(http.StatusOK),
+		Expect().Status().Equal(http.StatusAccepted),
+		Expect().Body().JSON().JQ(".month").Equal(month),
+		Expect().Body().JSON().JQ(".year").Equal(year),
+		Expect().Body().JSON().JQ(".format").Equal("csv"),
+		Expect().Body().JSON().JQ(".status").Equal("queued"),
+		Expect().Body().JSON().JQ("has(\"link\")").Equal(false),
+	)
+
+	Test(as.T(),
+		Post(getReportLinkPath),
+		Send().Headers("Accept").Add("application/vnd.apache.parquet"),
+		Send().Body().JSON(map[string]interface{}{
+			"month": month,
+			"year":  year,
+		}),
+		Expect().Status().Equal(http.StatusAccepted),
+		Expect().Body().JSON().JQ(".format").Equal("parquet"),
+	)
+
+	Test(as.T(),
+		Post(getReportLinkPath),
+		Send().Headers("Accept").Add("application/vnd.apache.parquet"),
+		Send().Body().JSON(map[string]interface{}{
+			"month":  month,
+			"year":   year,
+			"format": "xlsx",
+		}),
+		Expect().Status().Equal(http.StatusAccepted),
+		Expect().Body().JSON().JQ(".format").Equal("xlsx"),
+	)
+
+	Test(as.T(),
+		Post(getReportLinkPath),
+		Send().Headers("Accept").Add("text/html"),
+		Send().Body().JSON(map[string]interface{}{
+			"month": month,
+			"year":  year,
+		}),
+		Expect().Status().Equal(http.StatusNotAcceptable),
 		Expect().Body().JSON().Equal(map[string]interface{}{
-			"link": basePath + fmt.Sprintf("/report/download?key=%d-%d", year, month),
+			"message": "Get report link error. Report format is not acceptable",
+		}),
+	)
+
+	Test(as.T(),
+		Post(getReportLinkPath),
+		Send().Body().JSON(map[string]interface{}{
+			"month":  month,
+			"year":   year,
+			"format": "txt",
+		}),
+		Expect().Status().Equal(http.StatusBadRequest),
+		Expect().Body().JSON().Equal(map[string]interface{}{
+			"message": "Get report link error. Report format is not supported",
 		}),
 	)
 
@@ -180,16 +296,64 @@ func (as *APISuite) TestGetReportLink() {
 	)
 }
 
+func (as *APISuite) TestGetReportJob() {
+	Test(as.T(),
+		Get(reportJobsPath+"/job"),
+		Expect().Status().Equal(http.StatusBadRequest),
+		Expect().Body().JSON().Equal(map[string]interface{}{
+			"message": "Report job not found. id is not valid",
+		}),
+	)
+
+	Test(as.T(),
+		Get(reportJobsPath+"/1000000"),
+		Expect().Status().Equal(http.StatusNotFound),
+		Expect().Body().JSON().Equal(map[string]interface{}{
+			"message": "Report job not found",
+		}),
+	)
+
+	// there were no orders in January 2022.
+	job := as.createReportJob(map[string]interface{}{
+		"month": 1,
+		"year":  2022,
+	})
+	as.Require().Equal("failed", job.Status)
+	as.Require().Equal("report not found", job.Error)
+	as.Require().Empty(job.Link)
+
+	// only the principal that created the job can see it.
+	Test(as.T(),
+		Get(fmt.Sprintf("%s/%d", reportJobsPath, job.JobID)),
+		Send().Headers("X-Actor").Add("support"),
+		Expect().Status().Equal(http.StatusNotFound),
+		Expect().Body().JSON().Equal(map[string]interface{}{
+			"message": "Report job not found",
+		}),
+	)
+}
+
 func (as *APISuite) TestDownloadReport() {
 	year, month, _ := time.Now().Date()
-	key := fmt.Sprintf("%d-%d", year, month)
-	link := basePath + fmt.Sprintf("/report/download?key=%s", key)
-	reportFilename := fmt.Sprintf("report_%s.csv", key)
+	key := fmt.Sprintf("%d-%d.csv", year, month)
+	linkPrefix := basePath + "/report/download?token="
+	// the report of the current month has the time it was computed at in its name.
+	reportFilename := fmt.Sprintf("attachment; filename=report_%d-%d-as-of-", year, month)
+
+	// the key alone does not give access to the report anymore.
 	Test(as.T(),
-		Get(link),
-		Expect().Status().Equal(http.StatusNotFound),
+		Get(basePath+fmt.Sprintf("/report/download?key=%s", key)),
+		Expect().Status().Equal(http.StatusBadRequest),
+		Expect().Body().JSON().Equal(map[string]interface{}{
+			"message": "Download report error. Invalid request",
+		}),
+	)
+
+	Test(as.T(),
+		Get(linkPrefix+"forged.token"),
+		Expect().Status().Equal(http.StatusForbidden),
 		Expect().Body().JSON().Equal(map[string]interface{}{
-			"message": "Download report error. Report not found",
+			"message": "Download report error. Link is not valid",
 		}),
 	)
 
@@ -203,22 +367,43 @@ func (as *APISuite) TestDownloadReport() {
 
 	serviceAmounts := as.generateRandomReport(10, 5, 20, 20000)
 
+	job := as.createReportJob(map[string]interface{}{
+		"month": month,
+		"year":  year,
+	})
+	as.Require().Equal("done", job.Status)
+	as.Require().True(strings.HasPrefix(job.Link, linkPrefix))
+
 	Test(as.T(),
-		Post(getReportLinkPath),
-		Send().Body().JSON(map[string]interface{}{
-			"month": month,
-			"year":  year,
-		}),
+		Get(job.Link),
 		Expect().Status().Equal(http.StatusOK),
+		Expect().Headers("Content-Disposition").First().Contains(reportFilename, ".csv"),
+		Expect().Headers("Content-Type").Equal("text/csv"),
+		Expect().Body().Bytes().Equal(as.serviceAmountsToCSV(serviceAmounts)),
+	)
+
+	Test(as.T(),
+		Get(job.Link),
+		Send().Headers("Accept").Add("application/json"),
+		Expect().Status().Equal(http.StatusNotAcceptable),
 		Expect().Body().JSON().Equal(map[string]interface{}{
-			"link": link,
+			"message": "Download report error. Report format is not acceptable",
 		}),
 	)
 
+	job = as.createReportJob(map[string]interface{}{
+		"month":  month,
+		"year":   year,
+		"format": "json",
+	})
+	as.Require().Equal("done", job.Status)
+	as.Require().True(strings.HasPrefix(job.Link, linkPrefix))
+
 	Test(as.T(),
-		Get(link),
+		Get(job.Link),
 		Expect().Status().Equal(http.StatusOK),
-		Expect().Headers("Content-Disposition").Equal(fmt.Sprintf("attachment; filename=%s", reportFilename)),
-		Expect().Body().Bytes().Equal(as.serviceAmountsToCSV(serviceAmounts)),
+		Expect().Headers("Content-Disposition").First().Contains(reportFilename, ".json"),
+		Expect().Headers("Content-Type").Equal("application/json"),
+		Expect().Body().JSON().Equal(as.serviceAmountsToJSON(serviceAmounts)),
 	)
 }
@@ -0,0 +1,179 @@
+package integration
+
+import (
+	"fmt"
+	"net/http"
+
+	. "github.com/Eun/go-hit"
+)
+
+const (
+	servicesPath = basePath + "/services"
+
+	// catalogSize is the number of services every test starts with.
+	catalogSize = 5
+)
+
+func serviceName(serviceID int) string {
+	return fmt.Sprintf("Service %d", serviceID)
+}
+
+func (as *APISuite) createService(serviceID int) {
+	Test(as.T(),
+		Post(servicesPath),
+		Send().Body().JSON(map[string]interface{}{
+			"service_id": serviceID,
+			"name":       serviceName(serviceID),
+		}),
+		Expect().Status().Equal(http.StatusOK),
+		Expect().Body().JSON().JQ(".service_id").Equal(serviceID),
+		Expect().Body().JSON().JQ(".active").Equal(true),
+	)
+}
+
+func (as *APISuite) TestServices() {
+	serviceID := catalogSize + 1
+	servicePath := fmt.Sprintf("%s/%d", servicesPath, serviceID)
+
+	Test(as.T(),
+		Post(servicesPath),
+		Send().Body().JSON(map[string]interface{}{
+			"service_id": serviceID,
+		}),
+		Expect().Status().Equal(http.StatusBadRequest),
+		Expect().Body().JSON().Equal(map[string]interface{}{
+			"message": "Create service error. Invalid request",
+		}),
+	)
+
+	Test(as.T(),
+		Post(servicesPath),
+		Send().Body().JSON(map[string]interface{}{
+			"service_id":  serviceID,
+			"name":        "Delivery",
+			"description": "Delivery of goods",
+		}),
+		Expect().Status().Equal(http.StatusOK),
+		Expect().Body().JSON().JQ(".service_id").Equal(serviceID),
+		Expect().Body().JSON().JQ(".name").Equal("Delivery"),
+		Expect().Body().JSON().JQ(".description").Equal("Delivery of goods"),
+		Expect().Body().JSON().JQ(".active").Equal(true),
+	)
+
+	Test(as.T(),
+		Post(servicesPath),
+		Send().Body().JSON(map[string]interface{}{
+			"service_id": serviceID,
+			"name":       "Delivery",
+		}),
+		Expect().Status().Equal(http.StatusConflict),
+		Expect().Body().JSON().Equal(map[string]interface{}{
+			"message": "Create service error. Service already exist",
+		}),
+	)
+
+	Test(as.T(),
+		Get(servicesPath),
+		Expect().Status().Equal(http.StatusOK),
+		Expect().Body().JSON().JQ(".services").Len().Equal(catalogSize+1),
+	)
+
+	Test(as.T(),
+		Put(servicePath),
+		Send().Body().JSON(map[string]interface{}{
+			"name":        "Express delivery",
+			"description": "Delivery of goods",
+			"active":      false,
+		}),
+		Expect().Status().Equal(http.StatusOK),
+		Expect().Body().JSON().JQ(".name").Equal("Express delivery"),
+		Expect().Body().JSON().JQ(".active").Equal(false),
+	)
+
+	Test(as.T(),
+		Get(servicePath),
+		Expect().Status().Equal(http.StatusOK),
+		Expect().Body().JSON().JQ(".name").Equal("Express delivery"),
+		Expect().Body().JSON().JQ(".active").Equal(false),
+	)
+
+	Test(as.T(),
+		Delete(servicePath),
+		Expect().Status().Equal(http.StatusNoContent),
+	)
+
+	Test(as.T(),
+		Get(servicePath),
+		Expect().Status().Equal(http.StatusNotFound),
+		Expect().Body().JSON().Equal(map[string]interface{}{
+			"message": "Service not found",
+		}),
+	)
+}
+
+func (as *APISuite) TestCreateOrderForCatalogService() {
+	Test(as.T(),
+		Post(addBalancePath),
+		Send().Body().JSON(map[string]interface{}{
+			"account_id": 1,
+			"amount":     100,
+		}),
+		Expect().Status().Equal(http.StatusOK),
+	)
+
+	Test(as.T(),
+		Post(createOrderPath),
+		Send().Body().JSON(map[string]interface{}{
+			"order_id":   1,
+			"account_id": 1,
+			"service_id": catalogSize + 1,
+			"amount":     10,
+		}),
+		Expect().Status().Equal(http.StatusNotFound),
+		Expect().Body().JSON().Equal(map[string]interface{}{
+			"message": "Create order error. Service not found",
+		}),
+	)
+
+	Test(as.T(),
+		Put(fmt.Sprintf("%s/%d", servicesPath, 2)),
+		Send().Body().JSON(map[string]interface{}{
+			"name":   serviceName(2),
+			"active": false,
+		}),
+		Expect().Status().Equal(http.StatusOK),
+	)
+
+	Test(as.T(),
+		Post(createOrderPath),
+		Send().Body().JSON(map[string]interface{}{
+			"order_id":   1,
+			"account_id": 1,
+			"service_id": 2,
+			"amount":     10,
+		}),
+		Expect().Status().Equal(http.StatusConflict),
+		Expect().Body().JSON().Equal(map[string]interface{}{
+			"message": "Create order error. Service is not active",
+		}),
+	)
+
+	Test(as.T(),
+		Post(createOrderPath),
+		Send().Body().JSON(map[string]interface{}{
+			"order_id":   1,
+			"account_id": 1,
+			"service_id": 1,
+			"amount":     10,
+		}),
+		Expect().Status().Equal(http.StatusOK),
+	)
+
+	Test(as.T(),
+		Delete(fmt.Sprintf("%s/%d", servicesPath, 1)),
+		Expect().Status().Equal(http.StatusConflict),
+		Expect().Body().JSON().Equal(map[string]interface{}{
+			"message": "Delete service error. Service has orders",
+		}),
+	)
+}
@@ -2,6 +2,7 @@ package integration
 
 import (
 	"net/http"
+	"net/url"
 
 	. "github.com/Eun/go-hit"
 )
@@ -48,8 +49,7 @@ func (as *APISuite) TestGetTransactionsByAccountID() {
 		Expect().Body().JSON().JQ(".order").JQ(".account_id").Equal(1),
 		Expect().Body().JSON().JQ(".order").JQ(".service_id").Equal(1),
 		Expect().Body().JSON().JQ(".order").JQ(".amount").Equal(40),
-		Expect().Body().JSON().JQ(".order").JQ(".is_paid").Equal(false),
-		Expect().Body().JSON().JQ(".order").JQ(".is_cancelled").Equal(false),
+		Expect().Body().JSON().JQ(".order").JQ(".status").Equal("reserved"),
 		Expect().Body().JSON().JQ(".balance").Equal(60),
 	)
 
@@ -79,7 +79,7 @@ func (as *APISuite) TestGetTransactionsByAccountID() {
 			JSON().
 			JQ(".transactions.[0]").
 			JQ(".description").
-			Equal("Add 100 kopecks to account with id = 1"),
+			Equal("Add 1.00 RUB to account with id = 1"),
 
 		Expect().Body().JSON().JQ(".transactions.[1]").JQ(".type").Equal("reservation"),
 		Expect().Body().JSON().JQ(".transactions.[1]").JQ(".sender_id").Equal(1),
@@ -89,7 +89,7 @@ func (as *APISuite) TestGetTransactionsByAccountID() {
 			JSON().
 			JQ(".transactions.[1]").
 			JQ(".description").
-			Equal("Reserve 40 kopecks for order with id = 1"),
+			Equal("Reserve 0.40 RUB for order with id = 1"),
 
 		Expect().Body().JSON().JQ(".transactions.[2]").JQ(".type").Equal("transfer"),
 		Expect().Body().JSON().JQ(".transactions.[2]").JQ(".sender_id").Equal(2),
@@ -99,7 +99,7 @@ func (as *APISuite) TestGetTransactionsByAccountID() {
 			JSON().
 			JQ(".transactions.[2]").
 			JQ(".description").
-			Equal("Transfer 30 kopecks from account with id = 2 to account with id = 1"),
+			Equal("Transfer 0.30 RUB from account with id = 2 to account with id = 1"),
 
 		Expect().Body().JSON().JQ(".range").Equal(map[string]interface{}{
 			"limit":  10,
@@ -120,7 +120,7 @@ func (as *APISuite) TestGetTransactionsByAccountID() {
 			JSON().
 			JQ(".transactions.[0]").
 			JQ(".description").
-			Equal("Add 100 kopecks to account with id = 1"),
+			Equal("Add 1.00 RUB to account with id = 1"),
 
 		Expect().Body().JSON().JQ(".transactions.[1]").JQ(".type").Equal("reservation"),
 		Expect().Body().JSON().JQ(".transactions.[1]").JQ(".sender_id").Equal(1),
@@ -130,13 +130,14 @@ func (as *APISuite) TestGetTransactionsByAccountID() {
 			JSON().
 			JQ(".transactions.[1]").
 			JQ(".description").
-			Equal("Reserve 40 kopecks for order with id = 1"),
+			Equal("Reserve 0.40 RUB for order with id = 1"),
 
-		Expect().Body().JSON().JQ(".range").Equal(map[string]interface{}{
+		Expect().Body().JSON().JQ(".range | del(.next_cursor)").Equal(map[string]interface{}{
 			"limit":  2,
 			"offset": 0,
 			"count":  3,
 		}),
+		Expect().Body().JSON().JQ(".range | has(\"next_cursor\")").Equal(true),
 	)
 
 	Test(
@@ -152,13 +153,14 @@ func (as *APISuite) TestGetTransactionsByAccountID() {
 			JSON().
 			JQ(".transactions.[0]").
 			JQ(".description").
-			Equal("Reserve 40 kopecks for order with id = 1"),
+			Equal("Reserve 0.40 RUB for order with id = 1"),
 
-		Expect().Body().JSON().JQ(".range").Equal(map[string]interface{}{
+		Expect().Body().JSON().JQ(".range | del(.prev_cursor)").Equal(map[string]interface{}{
 			"limit":  2,
 			"offset": 1,
 			"count":  3,
 		}),
+		Expect().Body().JSON().JQ(".range | has(\"prev_cursor\")").Equal(true),
 	)
 
 	Test(
@@ -173,7 +175,7 @@ func (as *APISuite) TestGetTransactionsByAccountID() {
 			JSON().
 			JQ(".transactions.[0]").
 			JQ(".description").
-			Equal("Transfer 30 kopecks from account with id = 2 to account with id = 1"),
+			Equal("Transfer 0.30 RUB from account with id = 2 to account with id = 1"),
 
 		Expect().Body().JSON().JQ(".transactions.[1]").JQ(".type").Equal("reservation"),
 		Expect().Body().JSON().JQ(".transactions.[1]").JQ(".sender_id").Equal(1),
@@ -183,13 +185,14 @@ func (as *APISuite) TestGetTransactionsByAccountID() {
 			JSON().
 			JQ(".transactions.[1]").
 			JQ(".description").
-			Equal("Reserve 40 kopecks for order with id = 1"),
+			Equal("Reserve 0.40 RUB for order with id = 1"),
 
-		Expect().Body().JSON().JQ(".range").Equal(map[string]interface{}{
+		Expect().Body().JSON().JQ(".range | del(.next_cursor)").Equal(map[string]interface{}{
 			"limit":  2,
 			"offset": 0,
 			"count":  3,
 		}),
+		Expect().Body().JSON().JQ(".range | has(\"next_cursor\")").Equal(true),
 	)
 
 	Test(
@@ -204,7 +207,7 @@ func (as *APISuite) TestGetTransactionsByAccountID() {
 			JSON().
 			JQ(".transactions.[0]").
 			JQ(".description").
-			Equal("Add 50 kopecks to account with id = 2"),
+			Equal("Add 0.50 RUB to account with id = 2"),
 
 		Expect().Body().JSON().JQ(".transactions.[1]").JQ(".type").Equal("transfer"),
 		Expect().Body().JSON().JQ(".transactions.[1]").JQ(".sender_id").Equal(2),
@@ -214,7 +217,7 @@ func (as *APISuite) TestGetTransactionsByAccountID() {
 			JSON().
 			JQ(".transactions.[1]").
 			JQ(".description").
-			Equal("Transfer 30 kopecks from account with id = 2 to account with id = 1"),
+			Equal("Transfer 0.30 RUB from account with id = 2 to account with id = 1"),
 
 		Expect().Body().JSON().JQ(".range").Equal(map[string]interface{}{
 			"limit":  10,
@@ -277,3 +280,201 @@ func (as *APISuite) TestGetTransactionsByAccountID() {
 		}),
 	)
 }
+
+func (as *APISuite) TestGetTransactionsByCursor() {
+	for _, amount := range []int{100, 40, 30} {
+		Test(as.T(),
+			Post(addBalancePath),
+			Send().Body().JSON(map[string]interface{}{
+				"account_id": 1,
+				"amount":     amount,
+			}),
+			Expect().Status().Equal(http.StatusOK),
+		)
+	}
+
+	var nextCursor string
+	Test(
+		as.T(),
+		Get(getTransactionsByAccountIDPath+"1?limit=2"),
+		Expect().Status().Equal(http.StatusOK),
+		Expect().Body().JSON().JQ(".transactions | map(.amount)").Equal([]int{100, 40}),
+		Store().Response().Body().JSON().JQ(".range.next_cursor").In(&nextCursor),
+	)
+
+	var prevCursor string
+	Test(
+		as.T(),
+		Get(getTransactionsByAccountIDPath+"1?limit=2&cursor="+url.QueryEscape(nextCursor)),
+		Expect().Status().Equal(http.StatusOK),
+		Expect().Body().JSON().JQ(".transactions | map(.amount)").Equal([]int{30}),
+		Expect().Body().JSON().JQ(".range | has(\"count\")").Equal(false),
+		Expect().Body().JSON().JQ(".range | has(\"next_cursor\")").Equal(false),
+		Store().Response().Body().JSON().JQ(".range.prev_cursor").In(&prevCursor),
+	)
+
+	Test(
+		as.T(),
+		Get(getTransactionsByAccountIDPath+"1?limit=2&with_count=true&cursor="+url.QueryEscape(prevCursor)),
+		Expect().Status().Equal(http.StatusOK),
+		Expect().Body().JSON().JQ(".transactions | map(.amount)").Equal([]int{100, 40}),
+		Expect().Body().JSON().JQ(".range.count").Equal(3),
+		Expect().Body().JSON().JQ(".range | has(\"prev_cursor\")").Equal(false),
+		Expect().Body().JSON().JQ(".range | has(\"next_cursor\")").Equal(true),
+	)
+
+	Test(
+		as.T(),
+		Get(getTransactionsByAccountIDPath+"1?sort=sum&cursor="+url.QueryEscape(nextCursor)),
+		Expect().Status().Equal(http.StatusBadRequest),
+		Expect().Body().JSON().Equal(map[string]interface{}{
+			"message": "Transactions not found. Cursor does not match sort param",
+		}),
+	)
+
+	Test(
+		as.T(),
+		Get(getTransactionsByAccountIDPath+"1?cursor=invalid"),
+		Expect().Status().Equal(http.StatusBadRequest),
+		Expect().Body().JSON().Equal(map[string]interface{}{
+			"message": "Transactions not found. Cursor is not valid",
+		}),
+	)
+}
+
+func (as *APISuite) TestGetTransactionsWithFilters() {
+	for accountID, amount := range map[int]int{1: 100, 2: 50} {
+		Test(as.T(),
+			Post(addBalancePath),
+			Send().Body().JSON(map[string]interface{}{
+				"account_id": accountID,
+				"amount":     amount,
+			}),
+			Expect().Status().Equal(http.StatusOK),
+		)
+	}
+
+	for _, transfer := range []map[string]interface{}{
+		{"sender_id": 2, "receiver_id": 1, "amount": 30},
+		{"sender_id": 1, "receiver_id": 2, "amount": 10},
+	} {
+		Test(as.T(),
+			Post(transferBalancePath),
+			Send().Body().JSON(transfer),
+			Expect().Status().Equal(http.StatusOK),
+		)
+	}
+
+	Test(
+		as.T(),
+		Get(getTransactionsByAccountIDPath+"1?flow=incoming"),
+		Expect().Status().Equal(http.StatusOK),
+		Expect().Body().JSON().JQ(".transactions | map(.amount)").Equal([]int{100, 30}),
+		Expect().Body().JSON().JQ(".range.count").Equal(2),
+	)
+
+	Test(
+		as.T(),
+		Get(getTransactionsByAccountIDPath+"1?flow=outgoing"),
+		Expect().Status().Equal(http.StatusOK),
+		Expect().Body().JSON().JQ(".transactions | map(.amount)").Equal([]int{10}),
+	)
+
+	Test(
+		as.T(),
+		Get(getTransactionsByAccountIDPath+"1?type=transfer&counterparty_id=2&sort=sum&direction=desc"),
+		Expect().Status().Equal(http.StatusOK),
+		Expect().Body().JSON().JQ(".transactions | map(.amount)").Equal([]int{30, 10}),
+	)
+
+	Test(
+		as.T(),
+		Get(getTransactionsByAccountIDPath+"1?min_amount=20&max_amount=50"),
+		Expect().Status().Equal(http.StatusOK),
+		Expect().Body().JSON().JQ(".transactions | map(.amount)").Equal([]int{30}),
+	)
+
+	Test(
+		as.T(),
+		Get(getTransactionsByAccountIDPath+"1?description=FROM%20ACCOUNT%20WITH%20ID%20%3D%202"),
+		Expect().Status().Equal(http.StatusOK),
+		Expect().Body().JSON().JQ(".transactions | map(.amount)").Equal([]int{30}),
+	)
+
+	Test(
+		as.T(),
+		Get(getTransactionsByAccountIDPath+"1?from=2000-01-01&to=2000-01-02"),
+		Expect().Status().Equal(http.StatusOK),
+		Expect().Body().JSON().JQ(".transactions").Len().Equal(0),
+	)
+
+	Test(
+		as.T(),
+		Get(getTransactionsByAccountIDPath+"1?flow=sideways"),
+		Expect().Status().Equal(http.StatusBadRequest),
+		Expect().Body().JSON().Equal(map[string]interface{}{
+			"message": "Transactions not found. Flow param is not valid",
+		}),
+	)
+}
+
+func (as *APISuite) TestGetStatement() {
+	for accountID, amount := range map[int]int{1: 100, 2: 50} {
+		Test(as.T(),
+			Post(addBalancePath),
+			Send().Body().JSON(map[string]interface{}{
+				"account_id": accountID,
+				"amount":     amount,
+			}),
+			Expect().Status().Equal(http.StatusOK),
+		)
+	}
+
+	Test(as.T(),
+		Post(transferBalancePath),
+		Send().Body().JSON(map[string]interface{}{
+			"sender_id":   1,
+			"receiver_id": 2,
+			"amount":      30,
+		}),
+		Expect().Status().Equal(http.StatusOK),
+	)
+
+	Test(
+		as.T(),
+		Get(getTransactionsByAccountIDPath+"1/statement?format=csv"),
+		Expect().Status().Equal(http.StatusOK),
+		Expect().Headers("Content-Type").Equal("text/csv"),
+		Expect().Body().String().Contains(",,opening_balance,,,,RUB,,0,\n"),
+		Expect().Body().String().Contains(",enrollment,1,1,100,RUB,100,100,"),
+		Expect().Body().String().Contains(",transfer,1,2,30,RUB,-30,70,"),
+		Expect().Body().String().Contains(",,closing_balance,,,,RUB,,70,\n"),
+	)
+
+	Test(
+		as.T(),
+		Get(getTransactionsByAccountIDPath+"2/statement?format=jsonl"),
+		Expect().Status().Equal(http.StatusOK),
+		Expect().Headers("Content-Type").Equal("application/jsonl"),
+		Expect().Body().String().Contains(`"type":"transfer","sender_id":1,"receiver_id":2,"amount":30`),
+		Expect().Body().String().Contains(`"change":30,"balance":80`),
+		Expect().Body().String().Contains(`{"record":"closing_balance","currency":"RUB","balance":80}`),
+	)
+
+	Test(
+		as.T(),
+		Get(getTransactionsByAccountIDPath+"1/statement?format=pdf&from=2000-01-01&to=2000-02-01"),
+		Expect().Status().Equal(http.StatusOK),
+		Expect().Headers("Content-Type").Equal("application/pdf"),
+		Expect().Body().String().Contains("(Closing balance: 0.00 RUB) '"),
+	)
+
+	Test(
+		as.T(),
+		Get(getTransactionsByAccountIDPath+"1/statement?format=xlsx"),
+		Expect().Status().Equal(http.StatusBadRequest),
+		Expect().Body().JSON().Equal(map[string]interface{}{
+			"message": "Statement not found. Format param is not valid",
+		}),
+	)
+}
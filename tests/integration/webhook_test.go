@@ -0,0 +1,100 @@
+package integration
+
+import (
+	"fmt"
+	"net/http"
+
+	. "github.com/Eun/go-hit"
+)
+
+const webhooksPath = basePath + "/webhooks"
+
+func (as *APISuite) TestWebhooks() {
+	Test(as.T(),
+		Post(webhooksPath),
+		Send().Body().JSON(map[string]interface{}{
+			"service_id":  1,
+			"url":         "https://partner.example.com/hooks",
+			"event_types": []string{"order_lost"},
+		}),
+		Expect().Status().Equal(http.StatusBadRequest),
+		Expect().Body().JSON().Equal(map[string]interface{}{
+			"message": "Create webhook error. Event type is not valid",
+		}),
+	)
+
+	Test(as.T(),
+		Post(webhooksPath),
+		Send().Body().JSON(map[string]interface{}{
+			"url":         "https://partner.example.com/hooks",
+			"event_types": []string{"order_paid"},
+		}),
+		Expect().Status().Equal(http.StatusBadRequest),
+		Expect().Body().JSON().Equal(map[string]interface{}{
+			"message": "Create webhook error. Exactly one of account_id and service_id is required",
+		}),
+	)
+
+	Test(as.T(),
+		Post(webhooksPath),
+		Send().Body().JSON(map[string]interface{}{
+			"account_id":  1,
+			"url":         "https://partner.example.com/hooks",
+			"event_types": []string{"order_paid"},
+		}),
+		Expect().Status().Equal(http.StatusNotFound),
+		Expect().Body().JSON().Equal(map[string]interface{}{
+			"message": "Create webhook error. Owner not found",
+		}),
+	)
+
+	var webhookID int64
+	Test(as.T(),
+		Post(webhooksPath),
+		Send().Body().JSON(map[string]interface{}{
+			"service_id":  1,
+			"url":         "https://partner.example.com/hooks",
+			"secret":      "secret",
+			"event_types": []string{"order_paid", "order_cancelled"},
+		}),
+		Expect().Status().Equal(http.StatusOK),
+		Expect().Body().JSON().JQ(".service_id").Equal(1),
+		Expect().Body().JSON().JQ(".url").Equal("https://partner.example.com/hooks"),
+		Expect().Body().JSON().JQ(".secret").Equal("secret"),
+		Expect().Body().JSON().JQ(".event_types").Equal([]string{"order_paid", "order_cancelled"}),
+		Store().Response().Body().JSON().JQ(".webhook_id").In(&webhookID),
+	)
+
+	webhookPath := fmt.Sprintf("%s/%d", webhooksPath, webhookID)
+
+	Test(as.T(),
+		Get(webhookPath),
+		Expect().Status().Equal(http.StatusOK),
+		Expect().Body().JSON().JQ(".webhook_id").Equal(webhookID),
+		Expect().Body().JSON().JQ(".secret").Equal(nil),
+	)
+
+	Test(as.T(),
+		Get(webhookPath+"/deliveries"),
+		Expect().Status().Equal(http.StatusOK),
+		Expect().Body().JSON().JQ(".deliveries").Equal([]interface{}{}),
+	)
+
+	Test(as.T(),
+		Get(webhookPath+"/deliveries?status=lost"),
+		Expect().Status().Equal(http.StatusBadRequest),
+	)
+
+	Test(as.T(),
+		Delete(webhookPath),
+		Expect().Status().Equal(http.StatusNoContent),
+	)
+
+	Test(as.T(),
+		Get(webhookPath),
+		Expect().Status().Equal(http.StatusNotFound),
+		Expect().Body().JSON().Equal(map[string]interface{}{
+			"message": "Webhook not found",
+		}),
+	)
+}